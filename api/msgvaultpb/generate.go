@@ -0,0 +1,7 @@
+// Package msgvaultpb contains the generated protobuf and gRPC stubs for the
+// MsgVaultService defined in msgvault.proto. Run `go generate ./...` after
+// changing the .proto file to regenerate msgvault.pb.go and
+// msgvault_grpc.pb.go, and commit the regenerated files alongside it.
+package msgvaultpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative msgvault.proto
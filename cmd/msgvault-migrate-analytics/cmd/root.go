@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+
+	_ "github.com/marcboeker/go-duckdb"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/query"
+)
+
+var (
+	dbPath       string
+	analyticsDir string
+	dryRun       bool
+	verify       bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "msgvault-migrate-analytics",
+	Short: "Build the Parquet analytics tree from a SQLite-only vault",
+	Long: `msgvault-migrate-analytics rebuilds the normalized Parquet analytics
+tree (messages/year=YYYY, sources, participants, message_recipients, labels,
+message_labels, attachments) that query.DuckDBEngine reads, from a vault that
+has only messages.db. Run it once after upgrading from a SQLite-only vault,
+or again after a SQLite restore to regenerate Parquet from scratch; it is
+safe to re-run, since finished tables are overwritten rather than appended
+to.`,
+	RunE: runRebuild,
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&dbPath, "db", "", "path to the SQLite metadata database (messages.db)")
+	rootCmd.Flags().StringVar(&analyticsDir, "analytics-dir", "", "path to write the Parquet analytics tree to")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "report expected row counts per table without writing anything")
+	rootCmd.Flags().BoolVar(&verify, "verify", false, "after rebuilding, compare row counts against sqlite and fail on any mismatch")
+	_ = rootCmd.MarkFlagRequired("db")
+	_ = rootCmd.MarkFlagRequired("analytics-dir")
+}
+
+// Execute runs the root command.
+func Execute() error {
+	return rootCmd.Execute()
+}
+
+func runRebuild(cmd *cobra.Command, args []string) error {
+	sqliteDB, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("open sqlite db: %w", err)
+	}
+	defer sqliteDB.Close()
+
+	opts := query.RebuildOptions{
+		DryRun: dryRun,
+		Verify: verify,
+		Progress: func(table string, rows int64) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "msgvault-migrate-analytics: %s: %d rows\n", table, rows)
+		},
+	}
+
+	// Ctrl-C stops after the table currently being written finishes (each
+	// table is written to a .tmp file and renamed atomically), rather than
+	// killing the process mid-write and leaving a partial Parquet file.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := query.RebuildAnalytics(ctx, sqliteDB, analyticsDir, opts)
+	if err != nil {
+		return fmt.Errorf("rebuild analytics: %w", err)
+	}
+
+	if dryRun {
+		fmt.Fprintln(cmd.OutOrStdout(), "msgvault-migrate-analytics: dry run; no files written")
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "msgvault-migrate-analytics: wrote %s\n", analyticsDir)
+	}
+	for _, table := range []string{"messages", "sources", "participants", "message_recipients", "labels", "message_labels", "attachments"} {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s: %d rows\n", table, result.Rows[table])
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/wesm/msgvault/cmd/msgvault-migrate-analytics/cmd"
+)
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
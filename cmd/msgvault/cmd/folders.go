@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/query"
+)
+
+var (
+	foldersDBPath       string
+	foldersAnalyticsDir string
+)
+
+var foldersCmd = &cobra.Command{
+	Use:   "folders",
+	Short: "List and manage saved-query virtual folders",
+	Long: `Manage named, persisted search queries ("unread-from-boss := from:boss@
+example.com AND -label:archive") exposed as virtual folders: list shows
+each one's live message count the way a real mailbox's unread count would
+appear in an aerc-style sidebar, and materialize compiles one to its own
+Parquet partition for fast re-reads instead of re-evaluating its predicate
+on every read.`,
+}
+
+var foldersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved queries with their live message counts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", foldersDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		folders, err := query.NewSavedQueries(sqliteDB).VirtualFolders(cmd.Context(), foldersAnalyticsDir)
+		if err != nil {
+			return fmt.Errorf("list virtual folders: %w", err)
+		}
+
+		for _, f := range folders {
+			fmt.Printf("%-30s %8d  %s\n", f.Name, f.MessageCount, f.QueryString)
+		}
+		return nil
+	},
+}
+
+var foldersCreateCmd = &cobra.Command{
+	Use:   "create <name> <query>",
+	Short: "Save a named query as a virtual folder",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", foldersDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		if _, err := query.NewSavedQueries(sqliteDB).Create(cmd.Context(), args[0], args[1]); err != nil {
+			return fmt.Errorf("create saved query: %w", err)
+		}
+		fmt.Printf("saved %q as %q\n", args[1], args[0])
+		return nil
+	},
+}
+
+var foldersDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved query",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", foldersDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		if err := query.NewSavedQueries(sqliteDB).Delete(cmd.Context(), args[0]); err != nil {
+			return fmt.Errorf("delete saved query: %w", err)
+		}
+		fmt.Printf("deleted %q\n", args[0])
+		return nil
+	},
+}
+
+var foldersMaterializeCmd = &cobra.Command{
+	Use:   "materialize <name>",
+	Short: "Compile a saved query to its own Parquet partition",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", foldersDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		result, err := query.NewSavedQueries(sqliteDB).Materialize(cmd.Context(), args[0], foldersAnalyticsDir)
+		if err != nil {
+			return fmt.Errorf("materialize saved query: %w", err)
+		}
+		fmt.Printf("materialized %q: %d rows\n", args[0], result.Rows[args[0]])
+		return nil
+	},
+}
+
+func init() {
+	foldersCmd.PersistentFlags().StringVar(&foldersDBPath, "db", "", "path to the SQLite metadata database")
+	foldersCmd.PersistentFlags().StringVar(&foldersAnalyticsDir, "analytics-dir", "", "path to the Parquet analytics directory")
+	foldersCmd.MarkPersistentFlagRequired("db")
+	foldersCmd.AddCommand(foldersListCmd, foldersCreateCmd, foldersDeleteCmd, foldersMaterializeCmd)
+	rootCmd.AddCommand(foldersCmd)
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import messages from an on-disk mail format into the store",
+	Long: `Import messages from a standard on-disk mail format into the msgvault
+store as a new source, so archives that never went through Gmail sync can
+still be searched and browsed. The only format subcommand is mbox; Maildir
+directories are not imported through this command.`,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
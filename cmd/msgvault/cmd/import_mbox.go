@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/mailbox"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+var (
+	importMboxDBPath      string
+	importMboxSourceEmail string
+)
+
+var importMboxCmd = &cobra.Command{
+	Use:   "mbox <file>",
+	Short: "Import an mbox file into the store",
+	Long: `Import every message in an mbox-format file into the store as a new,
+non-Gmail source identified by --source-email. mbox carries no label
+information, so every imported message starts out with no labels.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", importMboxDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		st, err := store.New(sqliteDB)
+		if err != nil {
+			return fmt.Errorf("open store: %w", err)
+		}
+
+		res, err := mailbox.ImportMbox(cmd.Context(), st, args[0], importMboxSourceEmail)
+		if err != nil {
+			return fmt.Errorf("import mbox: %w", err)
+		}
+
+		fmt.Printf("imported %d messages from %s\n", res.Count, args[0])
+		return nil
+	},
+}
+
+func init() {
+	importMboxCmd.Flags().StringVar(&importMboxDBPath, "db", "", "path to the SQLite metadata database")
+	importMboxCmd.Flags().StringVar(&importMboxSourceEmail, "source-email", "", "email address to record as this archive's source")
+	importMboxCmd.MarkFlagRequired("db")
+	importMboxCmd.MarkFlagRequired("source-email")
+	importCmd.AddCommand(importMboxCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/search/fts"
+)
+
+var reindexDBPath string
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the full-text search index from the current message store",
+	Long: `Rebuild drops and repopulates search_fts5, the SQLite FTS5-backed
+search.Index fts.Rebuild builds from messages, message_bodies, and
+message_recipients. Run this after a bulk import or whenever search
+results look stale - the background Reindexer keeps the index in sync
+incrementally, but a full rebuild is the recovery path if it ever falls
+behind or the index is new.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := sql.Open("sqlite3", reindexDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer db.Close()
+
+		if err := fts.Rebuild(cmd.Context(), db); err != nil {
+			return fmt.Errorf("rebuild index: %w", err)
+		}
+
+		fmt.Println("reindex complete")
+		return nil
+	},
+}
+
+func init() {
+	reindexCmd.Flags().StringVar(&reindexDBPath, "db", "", "path to the SQLite metadata database")
+	reindexCmd.MarkFlagRequired("db")
+	rootCmd.AddCommand(reindexCmd)
+}
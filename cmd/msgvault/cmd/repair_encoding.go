@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// DetectResult is the outcome of DetectEncoding: which charset it guessed,
+// how confident it is (1.0 for a BOM or already-valid UTF-8, otherwise a
+// [0,1] plausibility score from scoreDecoded), and the decoded text.
+type DetectResult struct {
+	Encoding    string
+	Confidence  float64
+	DecodedText string
+}
+
+// charsetCandidate is one charset DetectEncoding tries when content-based
+// detection is needed, paired with the Unicode scripts its decoded text is
+// expected to fall into (beyond plain ASCII, which scoreDecoded always
+// accepts).
+type charsetCandidate struct {
+	name    string
+	enc     encoding.Encoding
+	scripts []*unicode.RangeTable
+}
+
+// charsetCandidates is tried in order; scoreDecoded ties favor whichever
+// candidate appears first, which is why the plain Windows-125x variants
+// are listed ahead of their historically-correct-but-rarer-mislabeled ISO
+// counterparts (the common real-world mislabeling is "this is Latin-1"
+// when it's actually Windows-1252, not the other way around).
+var charsetCandidates = []charsetCandidate{
+	{"windows-1252", charmap.Windows1252, []*unicode.RangeTable{unicode.Latin}},
+	{"iso-8859-1", charmap.ISO8859_1, []*unicode.RangeTable{unicode.Latin}},
+	{"iso-8859-15", charmap.ISO8859_15, []*unicode.RangeTable{unicode.Latin}},
+	{"windows-1250", charmap.Windows1250, []*unicode.RangeTable{unicode.Latin}},
+	{"iso-8859-2", charmap.ISO8859_2, []*unicode.RangeTable{unicode.Latin}},
+	{"windows-1257", charmap.Windows1257, []*unicode.RangeTable{unicode.Latin}},
+	{"windows-1254", charmap.Windows1254, []*unicode.RangeTable{unicode.Latin}},
+	{"windows-1251", charmap.Windows1251, []*unicode.RangeTable{unicode.Cyrillic, unicode.Latin}},
+	{"koi8-r", charmap.KOI8R, []*unicode.RangeTable{unicode.Cyrillic, unicode.Latin}},
+	{"koi8-u", charmap.KOI8U, []*unicode.RangeTable{unicode.Cyrillic, unicode.Latin}},
+	{"windows-1253", charmap.Windows1253, []*unicode.RangeTable{unicode.Greek, unicode.Latin}},
+	{"windows-1255", charmap.Windows1255, []*unicode.RangeTable{unicode.Hebrew, unicode.Latin}},
+	{"shift_jis", japanese.ShiftJIS, []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Latin}},
+	{"euc-jp", japanese.EUCJP, []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Latin}},
+	{"iso-2022-jp", japanese.ISO2022JP, []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Latin}},
+	{"euc-kr", korean.EUCKR, []*unicode.RangeTable{unicode.Hangul, unicode.Latin}},
+	{"gbk", simplifiedchinese.GBK, []*unicode.RangeTable{unicode.Han, unicode.Latin}},
+	{"big5", traditionalchinese.Big5, []*unicode.RangeTable{unicode.Han, unicode.Latin}},
+}
+
+// bomUTF8, bomUTF16LE/BE, and bomUTF32LE/BE are the byte order marks
+// detectBOM checks for, longest (and therefore most specific) first since
+// a UTF-32LE BOM is a UTF-16LE BOM plus two more zero bytes.
+var (
+	bomUTF32LE = []byte{0xFF, 0xFE, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// detectBOM recognizes a leading UTF-8/16/32 byte order mark and decodes
+// the rest of input accordingly. golang.org/x/text/encoding/unicode
+// covers UTF-16; it has no UTF-32 decoder, so that part is hand-rolled
+// (UTF-32 has no surrogate pairs to worry about - it's a straight 4-byte
+// big/little-endian code point read).
+func detectBOM(input []byte) (name, decoded string, ok bool) {
+	switch {
+	case bytes.HasPrefix(input, bomUTF32LE):
+		return "utf-32le", decodeUTF32(input[len(bomUTF32LE):], false), true
+	case bytes.HasPrefix(input, bomUTF32BE):
+		return "utf-32be", decodeUTF32(input[len(bomUTF32BE):], true), true
+	case bytes.HasPrefix(input, bomUTF8):
+		return "utf-8", string(input[len(bomUTF8):]), true
+	case bytes.HasPrefix(input, bomUTF16LE):
+		s, err := xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM).NewDecoder().String(string(input[len(bomUTF16LE):]))
+		if err != nil {
+			return "", "", false
+		}
+		return "utf-16le", s, true
+	case bytes.HasPrefix(input, bomUTF16BE):
+		s, err := xunicode.UTF16(xunicode.BigEndian, xunicode.IgnoreBOM).NewDecoder().String(string(input[len(bomUTF16BE):]))
+		if err != nil {
+			return "", "", false
+		}
+		return "utf-16be", s, true
+	default:
+		return "", "", false
+	}
+}
+
+// decodeUTF32 decodes a UTF-32 byte stream (with its BOM already
+// stripped) into a UTF-8 string, one 4-byte code point at a time.
+func decodeUTF32(data []byte, bigEndian bool) string {
+	var out strings.Builder
+	for i := 0; i+4 <= len(data); i += 4 {
+		var cp uint32
+		if bigEndian {
+			cp = uint32(data[i])<<24 | uint32(data[i+1])<<16 | uint32(data[i+2])<<8 | uint32(data[i+3])
+		} else {
+			cp = uint32(data[i+3])<<24 | uint32(data[i+2])<<16 | uint32(data[i+1])<<8 | uint32(data[i])
+		}
+		out.WriteRune(rune(cp))
+	}
+	return out.String()
+}
+
+// scoreDecoded rates how plausible decoded is as real text: it rejects
+// outright (ok=false) if decoding produced a replacement character or a
+// stray C1/C0 control byte (the tell that the wrong single-byte charset
+// was tried, since a correctly-decoded Windows-125x "smart quote" byte
+// decodes to a real punctuation character, not a control code). Otherwise
+// it scores the fraction of runes that are plain ASCII text or fall in
+// one of scripts - a cheaper proxy for a full per-language character
+// frequency model, but one that already separates a correct charset
+// guess from a wrong one sharing the same byte ranges.
+func scoreDecoded(decoded string, scripts []*unicode.RangeTable) (score float64, ok bool) {
+	total, match := 0, 0
+	for _, r := range decoded {
+		if r == utf8.RuneError {
+			return 0, false
+		}
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return 0, false
+		}
+		total++
+		if r < utf8.RuneSelf {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsPunct(r) {
+				match++
+			}
+			continue
+		}
+		for _, tbl := range scripts {
+			if unicode.Is(tbl, r) {
+				match++
+				break
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(match) / float64(total), true
+}
+
+// DetectEncoding guesses input's charset without trusting a declared
+// Content-Type: a BOM wins outright, then already-valid UTF-8, then the
+// highest-scoring charsetCandidates decode (see scoreDecoded). If nothing
+// decodes cleanly it falls back to Windows-1252 (the most common
+// mislabeled charset in the wild) with confidence 0 and sanitizes
+// whatever's left invalid.
+func DetectEncoding(input []byte) DetectResult {
+	if name, decoded, ok := detectBOM(input); ok {
+		return DetectResult{Encoding: name, Confidence: 1, DecodedText: decoded}
+	}
+	if utf8.Valid(input) {
+		return DetectResult{Encoding: "utf-8", Confidence: 1, DecodedText: string(input)}
+	}
+
+	var best DetectResult
+	bestScore := -1.0
+	for _, c := range charsetCandidates {
+		decoded, err := c.enc.NewDecoder().String(string(input))
+		if err != nil {
+			continue
+		}
+		score, ok := scoreDecoded(decoded, c.scripts)
+		if !ok || score <= bestScore {
+			continue
+		}
+		bestScore = score
+		best = DetectResult{Encoding: c.name, Confidence: score, DecodedText: decoded}
+	}
+	if bestScore < 0 {
+		decoded, _ := charmap.Windows1252.NewDecoder().String(string(input))
+		return DetectResult{Encoding: "windows-1252", Confidence: 0, DecodedText: sanitizeUTF8(decoded)}
+	}
+	return best
+}
+
+// detectAndDecode is the older, decode-only entry point kept for callers
+// that don't need DetectEncoding's confidence score. It never errors -
+// consistent with the rest of this package's charset handling, a message
+// with an undetectable encoding still gets *a* decoding rather than
+// failing the whole import.
+func detectAndDecode(input []byte) (string, error) {
+	return DetectEncoding(input).DecodedText, nil
+}
+
+// getEncodingByName maps a declared Content-Type charset parameter to its
+// golang.org/x/text/encoding implementation, recognizing common aliases
+// (CPxxxx, latinN). It returns nil for anything it doesn't recognize,
+// leaving the caller to fall back to DetectEncoding.
+func getEncodingByName(charset string) encoding.Encoding {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "windows-1252", "cp1252", "cp-1252":
+		return charmap.Windows1252
+	case "windows-1250", "cp1250":
+		return charmap.Windows1250
+	case "windows-1251", "cp1251":
+		return charmap.Windows1251
+	case "windows-1253", "cp1253":
+		return charmap.Windows1253
+	case "windows-1254", "cp1254":
+		return charmap.Windows1254
+	case "windows-1255", "cp1255":
+		return charmap.Windows1255
+	case "windows-1257", "cp1257":
+		return charmap.Windows1257
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "l1":
+		return charmap.ISO8859_1
+	case "iso-8859-2", "iso8859-2", "latin2", "latin-2", "l2":
+		return charmap.ISO8859_2
+	case "iso-8859-3":
+		return charmap.ISO8859_3
+	case "iso-8859-4":
+		return charmap.ISO8859_4
+	case "iso-8859-5":
+		return charmap.ISO8859_5
+	case "iso-8859-6":
+		return charmap.ISO8859_6
+	case "iso-8859-7":
+		return charmap.ISO8859_7
+	case "iso-8859-8":
+		return charmap.ISO8859_8
+	case "iso-8859-9", "latin5":
+		return charmap.ISO8859_9
+	case "iso-8859-10":
+		return charmap.ISO8859_10
+	case "iso-8859-13":
+		return charmap.ISO8859_13
+	case "iso-8859-14":
+		return charmap.ISO8859_14
+	case "iso-8859-15", "latin9":
+		return charmap.ISO8859_15
+	case "iso-8859-16":
+		return charmap.ISO8859_16
+	case "koi8-r":
+		return charmap.KOI8R
+	case "koi8-u":
+		return charmap.KOI8U
+	case "shift_jis", "shift-jis", "sjis":
+		return japanese.ShiftJIS
+	case "euc-jp", "eucjp":
+		return japanese.EUCJP
+	case "iso-2022-jp":
+		return japanese.ISO2022JP
+	case "euc-kr", "euckr":
+		return korean.EUCKR
+	case "gbk":
+		return simplifiedchinese.GBK
+	case "gb2312":
+		return simplifiedchinese.GBK
+	case "gb18030":
+		return simplifiedchinese.GB18030
+	case "big5":
+		return traditionalchinese.Big5
+	default:
+		return nil
+	}
+}
+
+// sanitizeUTF8 replaces every invalid UTF-8 byte sequence in s with
+// U+FFFD, guaranteeing the result is valid UTF-8 even if s isn't.
+func sanitizeUTF8(s string) string {
+	return strings.ToValidUTF8(s, "�")
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wesm/msgvault/internal/deletion"
+)
+
+var reportVaultDir string
+
+var reportCmd = &cobra.Command{
+	Use:   "report <manifest-id>",
+	Short: "Print the deletion report saved for a manifest",
+	Long: `Print the deletion report Execute/ExecuteBatch saved for manifest-id once
+it finished: per-message disposition (trash/delete/skip), final status
+(success/not-found/failed), attempt count, and the last error if any -
+auditable evidence of what was destroyed, including the 404-treated-as-
+success cases.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := deletion.NewManager(reportVaultDir)
+		if err != nil {
+			return fmt.Errorf("open manifest store: %w", err)
+		}
+
+		report, err := mgr.LoadReport(args[0])
+		if err != nil {
+			return fmt.Errorf("load report: %w", err)
+		}
+
+		fmt.Print(deletion.RenderReportText(report))
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportVaultDir, "vault-dir", "", "directory holding deletion manifests")
+	rootCmd.AddCommand(reportCmd)
+}
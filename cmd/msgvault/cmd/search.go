@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/query"
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// SearchFilter holds the CLI's structured search flags, parallel to the
+// query DSL search.Parse understands. Each set field becomes one AND'd
+// leaf (or, for ExcludeFlags, a negated leaf) of the compiled query; a
+// free-form query string given after "--" is parsed with search.Parse and
+// AND'd in too, so the discoverable flags and the full Gmail-like DSL
+// compose instead of being mutually exclusive.
+type SearchFilter struct {
+	Read         bool
+	Unread       bool
+	Attachments  bool
+	Flags        []string // -x <flag>, repeatable
+	ExcludeFlags []string // -X <flag>, repeatable
+	Headers      []string // -H <header:value>, repeatable
+	DateRange    string   // -d <start..end>
+	Body         string
+	Subject      string
+	From         string
+	To           string
+	Cc           string
+	Phrase       bool // -p: treat the free-form query as one phrase
+}
+
+// astNodes returns one search.Node per set field in f, ready to be AND'd
+// together by compileSearchFilter.
+func (f *SearchFilter) astNodes() ([]*search.Node, error) {
+	var nodes []*search.Node
+
+	leaf := func(op, value string) *search.Node {
+		return &search.Node{Kind: search.NodeLeaf, Leaf: &search.Clause{Op: op, Value: value}}
+	}
+
+	if f.Read {
+		nodes = append(nodes, leaf("is", "read"))
+	}
+	if f.Unread {
+		nodes = append(nodes, leaf("is", "unread"))
+	}
+	if f.Attachments {
+		nodes = append(nodes, leaf("has", "attachment"))
+	}
+	for _, flag := range f.Flags {
+		nodes = append(nodes, leaf("is", flag))
+	}
+	for _, flag := range f.ExcludeFlags {
+		nodes = append(nodes, &search.Node{Kind: search.NodeNot, Child: leaf("is", flag)})
+	}
+	for _, h := range f.Headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid -H value %q, want header:value", h)
+		}
+		nodes = append(nodes, leaf("header", name+"="+value))
+	}
+	if f.DateRange != "" {
+		start, end, ok := strings.Cut(f.DateRange, "..")
+		if !ok {
+			return nil, fmt.Errorf("invalid -d value %q, want start..end", f.DateRange)
+		}
+		if start != "" {
+			nodes = append(nodes, leaf("after", start))
+		}
+		if end != "" {
+			nodes = append(nodes, leaf("before", end))
+		}
+	}
+	if f.Body != "" {
+		nodes = append(nodes, leaf("body", f.Body))
+	}
+	if f.Subject != "" {
+		nodes = append(nodes, leaf("subject", f.Subject))
+	}
+	if f.From != "" {
+		nodes = append(nodes, leaf("from", f.From))
+	}
+	if f.To != "" {
+		nodes = append(nodes, leaf("to", f.To))
+	}
+	if f.Cc != "" {
+		nodes = append(nodes, leaf("cc", f.Cc))
+	}
+
+	return nodes, nil
+}
+
+// compileSearchFilter merges f's flag-derived predicates with freeform (the
+// query string given after "--") via AND and compiles the result with
+// search.Compile, so both sources of predicates run through the same
+// AST-to-SQL path an engine already uses for DSL-only queries. Phrase wraps
+// freeform in quotes first so it parses as a single phrase instead of
+// separate implicitly-ANDed terms.
+func compileSearchFilter(f *SearchFilter, freeform string) (*search.Query, error) {
+	nodes, err := f.astNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if freeform != "" {
+		if f.Phrase {
+			freeform = `"` + strings.ReplaceAll(freeform, `"`, `\"`) + `"`
+		}
+		if q := search.Parse(freeform); q.AST != nil {
+			nodes = append(nodes, q.AST)
+		}
+	}
+
+	switch len(nodes) {
+	case 0:
+		return &search.Query{}, nil
+	case 1:
+		return search.Compile(nodes[0])
+	default:
+		return search.Compile(&search.Node{Kind: search.NodeAnd, Children: nodes})
+	}
+}
+
+var (
+	searchDBPath       string
+	searchAnalyticsDir string
+	searchLimit        int
+	searchFilter       SearchFilter
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [flags] [-- query]",
+	Short: "Search messages with structured flags, the query DSL, or both",
+	Long: `Search combines discoverable, tab-completable flags (-r/-u for read
+state, -a for attachments, -x/-X for arbitrary IMAP-style flags, -H for
+header matches, -d for a start..end date range, -b/-s/-f/-t/-c for body/
+subject/from/to/cc, -p for a phrase search) with the Gmail-like query DSL:
+put "--" before a free-form query string and it's parsed with search.Parse
+and AND'd with whatever flags were given, so scripting and ad-hoc complex
+queries run through the same compiler instead of two disconnected paths.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q, err := compileSearchFilter(&searchFilter, strings.Join(args, " "))
+		if err != nil {
+			return fmt.Errorf("build query: %w", err)
+		}
+
+		sqliteDB, err := sql.Open("sqlite3", searchDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		engine, err := query.NewDuckDBEngine(searchAnalyticsDir, searchDBPath, sqliteDB)
+		if err != nil {
+			return fmt.Errorf("open query engine: %w", err)
+		}
+		defer engine.Close()
+
+		results, err := engine.Search(context.Background(), q, searchLimit, 0)
+		if err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+
+		for _, m := range results {
+			fmt.Printf("%-20s %-40s %s\n", m.Date.Format("2006-01-02 15:04"), m.From, m.Subject)
+		}
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchDBPath, "db", "", "path to the SQLite metadata database")
+	searchCmd.Flags().StringVar(&searchAnalyticsDir, "analytics-dir", "", "path to the Parquet analytics directory")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 50, "maximum number of results")
+	searchCmd.Flags().BoolVarP(&searchFilter.Read, "read", "r", false, "match read messages")
+	searchCmd.Flags().BoolVarP(&searchFilter.Unread, "unread", "u", false, "match unread messages")
+	searchCmd.Flags().BoolVarP(&searchFilter.Attachments, "attachments", "a", false, "match messages with attachments")
+	searchCmd.Flags().StringArrayVarP(&searchFilter.Flags, "flag", "x", nil, "match an IMAP-style flag, e.g. \\Flagged (repeatable)")
+	searchCmd.Flags().StringArrayVarP(&searchFilter.ExcludeFlags, "exclude-flag", "X", nil, "exclude an IMAP-style flag (repeatable)")
+	searchCmd.Flags().StringArrayVarP(&searchFilter.Headers, "header", "H", nil, "match an arbitrary header as name:value (repeatable)")
+	searchCmd.Flags().StringVarP(&searchFilter.DateRange, "date-range", "d", "", "match a date range as start..end")
+	searchCmd.Flags().StringVarP(&searchFilter.Body, "body", "b", "", "match the message body")
+	searchCmd.Flags().StringVarP(&searchFilter.Subject, "subject", "s", "", "match the subject")
+	searchCmd.Flags().StringVarP(&searchFilter.From, "from", "f", "", "match the sender")
+	searchCmd.Flags().StringVarP(&searchFilter.To, "to", "t", "", "match a recipient")
+	searchCmd.Flags().StringVarP(&searchFilter.Cc, "cc", "c", "", "match a cc recipient")
+	searchCmd.Flags().BoolVarP(&searchFilter.Phrase, "phrase", "p", false, "treat the free-form query as a single phrase")
+	searchCmd.MarkFlagRequired("db")
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/wesm/msgvault/internal/sync"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP server exposing sync progress over SSE",
+	Long: `Run an HTTP server that exposes GET /sync/events, a Server-Sent Events
+stream of sync progress (page, message, and checkpoint events) for
+dashboards and other long-running clients to subscribe to. Reconnecting
+clients can resume from where they left off with a Last-Event-ID header.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		broker := sync.NewEventBroker()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/sync/events", broker.ServeHTTP)
+
+		fmt.Printf("listening on %s\n", serveAddr)
+		return http.ListenAndServe(serveAddr, mux)
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8090", "address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
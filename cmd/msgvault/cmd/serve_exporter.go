@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/exporter"
+	"github.com/wesm/msgvault/internal/query"
+)
+
+var (
+	exporterPushInterval time.Duration
+	exporterTarget       string
+	exporterDBPath       string
+	exporterAnalyticsDir string
+)
+
+var serveExporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Periodically push archive stats to a metrics sink",
+	Long: `Run a background loop that calls GetTotalStats and the
+Aggregate* query engine methods on an interval and pushes the results to
+--target, so operators can graph mailbox growth (per-sender, per-domain,
+per-label counts and message-size histograms) without running ad-hoc
+aggregate MCP calls.
+
+--target accepts:
+  statsd://host:port
+  promremotewrite+http(s)://host/path
+  http(s)://host/path (plain JSON POST)`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sink, err := exporter.SinkFromTarget(exporterTarget)
+		if err != nil {
+			return fmt.Errorf("exporter target: %w", err)
+		}
+
+		sqliteDB, err := sql.Open("sqlite3", exporterDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		engine, err := query.NewDuckDBEngine(exporterAnalyticsDir, exporterDBPath, sqliteDB)
+		if err != nil {
+			return fmt.Errorf("open query engine: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		exp, err := exporter.New(ctx, engine,
+			exporter.PushInterval(exporterPushInterval),
+			exporter.PushTarget(sink),
+		)
+		if err != nil {
+			return fmt.Errorf("start exporter: %w", err)
+		}
+
+		fmt.Printf("exporting stats to %s every %s\n", exporterTarget, exporterPushInterval)
+		<-ctx.Done()
+		exp.Stop()
+		return nil
+	},
+}
+
+func init() {
+	serveExporterCmd.Flags().DurationVar(&exporterPushInterval, "push-interval", time.Minute, "how often to push a stats snapshot")
+	serveExporterCmd.Flags().StringVar(&exporterTarget, "target", "", "metrics sink to push to (statsd://, promremotewrite+http(s)://, or http(s)://)")
+	serveExporterCmd.Flags().StringVar(&exporterDBPath, "db", "", "path to the SQLite metadata database")
+	serveExporterCmd.Flags().StringVar(&exporterAnalyticsDir, "analytics-dir", "", "path to the Parquet analytics directory")
+	serveExporterCmd.MarkFlagRequired("target")
+	serveCmd.AddCommand(serveExporterCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/wesm/msgvault/internal/grpcserver"
+	"github.com/wesm/msgvault/internal/query"
+)
+
+var (
+	grpcAddr         string
+	grpcDBPath       string
+	grpcAnalyticsDir string
+)
+
+var serveGRPCCmd = &cobra.Command{
+	Use:   "grpc",
+	Short: "Run a gRPC server exposing query.Engine operations",
+	Long: `Run a gRPC server exposing the same search, list, stats, and
+aggregate operations as the MCP tools, as a typed API for non-LLM clients
+such as indexers, dashboards, and backup tools. ListMessages and
+SearchMessages stream their results, so clients aren't limited to the
+1000-row cap the MCP tools apply.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", grpcDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		engine, err := query.NewDuckDBEngine(grpcAnalyticsDir, grpcDBPath, sqliteDB)
+		if err != nil {
+			return fmt.Errorf("open query engine: %w", err)
+		}
+
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return fmt.Errorf("listen on %s: %w", grpcAddr, err)
+		}
+
+		s := grpc.NewServer()
+		grpcserver.Register(s, grpcserver.NewServer(engine))
+
+		fmt.Printf("listening on %s\n", grpcAddr)
+		return s.Serve(lis)
+	},
+}
+
+func init() {
+	serveGRPCCmd.Flags().StringVar(&grpcAddr, "addr", ":9090", "address to listen on")
+	serveGRPCCmd.Flags().StringVar(&grpcDBPath, "db", "", "path to the SQLite metadata database")
+	serveGRPCCmd.Flags().StringVar(&grpcAnalyticsDir, "analytics-dir", "", "path to the Parquet analytics directory")
+	serveCmd.AddCommand(serveGRPCCmd)
+}
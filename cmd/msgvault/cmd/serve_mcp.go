@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/mcp"
+	"github.com/wesm/msgvault/internal/metrics"
+	"github.com/wesm/msgvault/internal/query"
+)
+
+var (
+	mcpTransport    string
+	mcpAddr         string
+	mcpBearerToken  string
+	mcpLogRequests  bool
+	mcpDBPath       string
+	mcpAnalyticsDir string
+
+	mcpMetricsAddr         string
+	mcpMetricsPushURL      string
+	mcpMetricsPushInterval time.Duration
+
+	mcpPolicy             string
+	mcpMaxMessagesPerCall int
+)
+
+var serveMCPCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run an MCP server exposing email archive tools",
+	Long: `Run an MCP server exposing search, get, list, stats, and aggregate
+tools over the archive. --mcp-transport=stdio (the default) serves a single
+client over stdin/stdout, the model most LLM desktop clients expect.
+--mcp-transport=http serves over HTTP/SSE instead, so multiple remote
+agents and IDE integrations can share one archive without spawning a
+subprocess per connection.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqliteDB, err := sql.Open("sqlite3", mcpDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer sqliteDB.Close()
+
+		engine, err := query.NewDuckDBEngine(mcpAnalyticsDir, mcpDBPath, sqliteDB)
+		if err != nil {
+			return fmt.Errorf("open query engine: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		reg := metrics.NewRegistry()
+		startMCPMetricsExport(ctx, reg)
+
+		policy, err := parseMCPPolicy(mcpPolicy, mcpMaxMessagesPerCall)
+		if err != nil {
+			return err
+		}
+
+		// WithGmailAPI is intentionally not set here: there is no CLI-level
+		// Gmail OAuth client construction in this command yet. Until one
+		// exists, mutating tools (apply_label, trash_message, etc.) are not
+		// registered at all, regardless of --mcp-policy - newServer only adds them
+		// once a gmail.API is wired in.
+		opts := []mcp.ServeOption{mcp.WithMetricsRegistry(reg), mcp.WithPolicy(policy)}
+		if mcpBearerToken != "" {
+			opts = append(opts, mcp.WithBearerToken(mcpBearerToken))
+		}
+		if mcpLogRequests {
+			opts = append(opts, mcp.WithRequestLogging(true))
+		}
+
+		switch mcpTransport {
+		case "stdio":
+			return mcp.Serve(ctx, engine, opts...)
+		case "http":
+			fmt.Printf("listening on %s\n", mcpAddr)
+			return mcp.ServeHTTP(ctx, engine, mcpAddr, opts...)
+		default:
+			return fmt.Errorf("unknown --mcp-transport %q, want \"stdio\" or \"http\"", mcpTransport)
+		}
+	},
+}
+
+// startMCPMetricsExport starts whichever of pull (--metrics-addr) or push
+// (--metrics-push-url) mode was configured for reg, running in the
+// background until ctx is cancelled. With neither flag set, reg still
+// collects values (for callers that want to read them directly) but
+// nothing exports them, equivalent to reg.DisableExport().
+func startMCPMetricsExport(ctx context.Context, reg *metrics.Registry) {
+	if mcpMetricsAddr == "" && mcpMetricsPushURL == "" {
+		reg.DisableExport()
+		return
+	}
+	if mcpMetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(ctx, reg, mcpMetricsAddr); err != nil && ctx.Err() == nil {
+				log.Printf("metrics: serve error: %v", err)
+			}
+		}()
+	}
+	if mcpMetricsPushURL != "" {
+		go func() {
+			err := metrics.Push(ctx, reg, mcpMetricsPushURL, mcpMetricsPushInterval, func(err error) {
+				log.Printf("metrics: push error: %v", err)
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Printf("metrics: push loop exited: %v", err)
+			}
+		}()
+	}
+}
+
+// parseMCPPolicy maps the --mcp-policy flag onto an mcp.Policy. maxPerCall
+// only applies to "allow"; it's ignored (but harmless) for the other modes.
+func parseMCPPolicy(name string, maxPerCall int) (mcp.Policy, error) {
+	switch name {
+	case "readonly":
+		return mcp.ReadOnly{}, nil
+	case "dryrun":
+		return mcp.DryRunPolicy{}, nil
+	case "allow":
+		return mcp.AllowPolicy{MaxMessages: maxPerCall}, nil
+	default:
+		return nil, fmt.Errorf("unknown --mcp-policy %q, want \"readonly\", \"dryrun\", or \"allow\"", name)
+	}
+}
+
+func init() {
+	serveMCPCmd.Flags().StringVar(&mcpTransport, "mcp-transport", "stdio", `transport to serve over: "stdio" or "http"`)
+	serveMCPCmd.Flags().StringVar(&mcpAddr, "addr", ":8091", "address to listen on (--mcp-transport=http only)")
+	serveMCPCmd.Flags().StringVar(&mcpBearerToken, "bearer-token", "", "require this bearer token on every request (--mcp-transport=http only)")
+	serveMCPCmd.Flags().BoolVar(&mcpLogRequests, "log-requests", false, "log each request's method and path (--mcp-transport=http only)")
+	serveMCPCmd.Flags().StringVar(&mcpDBPath, "db", "", "path to the SQLite metadata database")
+	serveMCPCmd.Flags().StringVar(&mcpAnalyticsDir, "analytics-dir", "", "path to the Parquet analytics directory")
+	serveMCPCmd.Flags().StringVar(&mcpMetricsAddr, "metrics-addr", "", "serve Prometheus/OpenMetrics metrics at this address + /metrics (pull mode; unset disables export)")
+	serveMCPCmd.Flags().StringVar(&mcpMetricsPushURL, "metrics-push-url", "", "POST metrics to this URL on an interval instead of serving them (push mode, for unscraped environments)")
+	serveMCPCmd.Flags().DurationVar(&mcpMetricsPushInterval, "metrics-push-interval", 15*time.Second, "how often to push metrics (--metrics-push-url only)")
+	serveMCPCmd.Flags().StringVar(&mcpPolicy, "mcp-policy", "readonly", `policy for mutating tools: "readonly" (disabled), "dryrun" (preview only, confirm_operation always refuses), or "allow"`)
+	serveMCPCmd.Flags().IntVar(&mcpMaxMessagesPerCall, "mcp-max-messages-per-call", 0, "cap on messages a single mutating call may affect (--mcp-policy=allow only; 0 = unlimited)")
+	serveCmd.AddCommand(serveMCPCmd)
+}
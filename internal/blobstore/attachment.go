@@ -0,0 +1,28 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+)
+
+// AttachmentStore persists attachment bytes outside the database, keyed by
+// their SHA-256 digest so identical attachments (forwarded messages,
+// mailing-list copies) are stored once regardless of backend. Callers
+// compute the digest themselves so dedup works the same way across
+// backends.
+type AttachmentStore interface {
+	// Put stores r's contents under sha256 (the attachment's SHA-256
+	// digest, raw 32 bytes) and returns the backend-specific key to pass
+	// to Get/Exists/Delete. Put is a no-op if the key already exists.
+	Put(ctx context.Context, sha256 []byte, r io.Reader) (key string, err error)
+
+	// Get opens the attachment previously stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Exists reports whether key is already stored, letting callers skip
+	// re-uploading a deduplicated attachment.
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Delete removes the attachment stored under key.
+	Delete(ctx context.Context, key string) error
+}
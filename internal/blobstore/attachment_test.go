@@ -0,0 +1,120 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+)
+
+// attachmentStoreBackends lists the in-process backends exercised by the
+// table-driven tests below. S3AttachmentStore is excluded: it requires a
+// live or mocked *s3.Client and is covered separately where one is
+// available.
+var attachmentStoreBackends = []struct {
+	name     string
+	newStore func(t *testing.T) (AttachmentStore, error)
+}{
+	{"local", func(t *testing.T) (AttachmentStore, error) { return NewLocalAttachmentStore(t.TempDir()) }},
+	{"memory", func(t *testing.T) (AttachmentStore, error) { return NewMemoryAttachmentStore(), nil }},
+}
+
+func TestAttachmentStore_PutGetExistsDelete(t *testing.T) {
+	for _, b := range attachmentStoreBackends {
+		t.Run(b.name, func(t *testing.T) {
+			testAttachmentStoreRoundTrip(t, b.newStore)
+		})
+	}
+}
+
+func testAttachmentStoreRoundTrip(t *testing.T, newStore func(t *testing.T) (AttachmentStore, error)) {
+	t.Helper()
+	store, err := newStore(t)
+	if err != nil {
+		t.Fatalf("newStore: %v", err)
+	}
+	ctx := context.Background()
+	data := []byte("attachment bytes")
+	sum := sha256.Sum256(data)
+
+	key, err := store.Put(ctx, sum[:], bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	exists, err := store.Exists(ctx, key)
+	if err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if !exists {
+		t.Fatal("Exists() = false after Put")
+	}
+
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	got := make([]byte, len(data))
+	if _, err := rc.Read(got); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get() = %q, want %q", got, data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	exists, err = store.Exists(ctx, key)
+	if err != nil {
+		t.Fatalf("Exists after delete: %v", err)
+	}
+	if exists {
+		t.Error("Exists() = true after Delete")
+	}
+}
+
+func TestAttachmentStore_Dedup(t *testing.T) {
+	for _, b := range attachmentStoreBackends {
+		t.Run(b.name, func(t *testing.T) {
+			store, err := b.newStore(t)
+			if err != nil {
+				t.Fatalf("newStore: %v", err)
+			}
+			ctx := context.Background()
+			data := []byte("same bytes twice")
+			sum := sha256.Sum256(data)
+
+			key1, err := store.Put(ctx, sum[:], bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Put (1): %v", err)
+			}
+			key2, err := store.Put(ctx, sum[:], bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Put (2): %v", err)
+			}
+			if key1 != key2 {
+				t.Errorf("keys differ for identical content: %q vs %q", key1, key2)
+			}
+		})
+	}
+}
+
+func TestNewAttachmentStoreFromConfig(t *testing.T) {
+	if _, err := NewAttachmentStoreFromConfig(AttachmentStoreConfig{}, nil); err != nil {
+		t.Errorf("default backend: %v", err)
+	}
+	if _, err := NewAttachmentStoreFromConfig(AttachmentStoreConfig{Backend: "local", Dir: t.TempDir()}, nil); err != nil {
+		t.Errorf("local backend: %v", err)
+	}
+	if _, err := NewAttachmentStoreFromConfig(AttachmentStoreConfig{Backend: "local"}, nil); err == nil {
+		t.Error("local backend with no dir: want error, got nil")
+	}
+	if _, err := NewAttachmentStoreFromConfig(AttachmentStoreConfig{Backend: "s3"}, nil); err == nil {
+		t.Error("s3 backend with no client: want error, got nil")
+	}
+	if _, err := NewAttachmentStoreFromConfig(AttachmentStoreConfig{Backend: "bogus"}, nil); err == nil {
+		t.Error("unknown backend: want error, got nil")
+	}
+}
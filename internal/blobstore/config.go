@@ -0,0 +1,44 @@
+package blobstore
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// AttachmentStoreConfig selects and configures an AttachmentStore backend,
+// typically populated from CLI flags or a config file.
+type AttachmentStoreConfig struct {
+	// Backend is one of "local", "s3", or "memory". Empty defaults to
+	// "memory".
+	Backend string
+
+	// Dir is the root directory for the "local" backend.
+	Dir string
+
+	// S3 configures the "s3" backend. Ignored for other backends.
+	S3 S3Config
+}
+
+// NewAttachmentStoreFromConfig builds the AttachmentStore selected by
+// cfg.Backend. s3Client is only required when cfg.Backend is "s3"; pass nil
+// otherwise, since this package doesn't manage AWS credentials or region
+// resolution itself.
+func NewAttachmentStoreFromConfig(cfg AttachmentStoreConfig, s3Client *s3.Client) (AttachmentStore, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryAttachmentStore(), nil
+	case "local":
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("blobstore: local backend requires a directory")
+		}
+		return NewLocalAttachmentStore(cfg.Dir)
+	case "s3":
+		if s3Client == nil {
+			return nil, fmt.Errorf("blobstore: s3 backend requires an s3 client")
+		}
+		return NewS3AttachmentStore(cfg.S3, s3Client), nil
+	default:
+		return nil, fmt.Errorf("blobstore: unknown attachment backend %q", cfg.Backend)
+	}
+}
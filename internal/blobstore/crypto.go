@@ -0,0 +1,123 @@
+package blobstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scryptN, scryptR, scryptP are the cost parameters used to derive the
+// AES-256 key from a passphrase; these match the scrypt-recommended
+// interactive-login parameters (2017 guidance).
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+	saltLen = 16
+)
+
+// NewSalt generates a fresh random salt suitable for deriveAEAD (via
+// WithEncryption or WithMessageEncryption).
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("blobstore: generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+// deriveAEAD derives an AES-256-GCM cipher.AEAD from passphrase via scrypt
+// using salt - the key-derivation scheme shared by Filesystem's
+// WithEncryption and MessageBlobStore's WithMessageEncryption.
+func deriveAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if len(salt) != saltLen {
+		return nil, fmt.Errorf("blobstore: salt must be %d bytes, got %d", saltLen, len(salt))
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: new cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: new GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// sealPayload seals data with aead, prefixing a fresh random nonce; a
+// no-op if aead is nil (encryption disabled). Shared by Filesystem.encode
+// and MessageBlobStore.encrypt.
+func sealPayload(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if aead == nil {
+		return data, nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("blobstore: generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// openPayload reverses sealPayload; a no-op if aead is nil. Shared by
+// Filesystem.decode and MessageBlobStore.decrypt.
+func openPayload(aead cipher.AEAD, payload []byte) ([]byte, error) {
+	if aead == nil {
+		return payload, nil
+	}
+	nonceSize := aead.NonceSize()
+	if len(payload) < nonceSize {
+		return nil, errors.New("blobstore: ciphertext too short")
+	}
+	nonce, ciphertext := payload[:nonceSize], payload[nonceSize:]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: decrypt: %w", err)
+	}
+	return plain, nil
+}
+
+// diskStats reports root's on-disk size and the free space remaining on
+// its underlying filesystem. Shared by Filesystem.Stats and
+// MessageBlobStore.Stats, so a capacity checker can watch either backend
+// interchangeably.
+func diskStats(root string) (BlobStoreStats, error) {
+	var size int64
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return BlobStoreStats{}, fmt.Errorf("blobstore: walk %s: %w", root, err)
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(root, &stat); err != nil {
+		return BlobStoreStats{}, fmt.Errorf("blobstore: statfs %s: %w", root, err)
+	}
+	free := int64(stat.Bavail) * int64(stat.Bsize)
+
+	return BlobStoreStats{SizeBytes: size, FreeSpaceBytes: free}, nil
+}
@@ -0,0 +1,162 @@
+// Package blobstore implements BlobStore and AttachmentStore backends for
+// storing raw MIME messages and attachments outside the SQLite database.
+package blobstore
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxConcurrentWrites bounds how many blob writes can be in flight at once,
+// so a large sync doesn't exhaust file descriptors or thrash disk I/O.
+const maxConcurrentWrites = 16
+
+// Filesystem is a BlobStore backed by a directory tree, chunked by the
+// first two hex characters of each blob's SHA-256 hash (so no single
+// directory holds more than ~1/256th of the corpus). Blobs are optionally
+// zstd-compressed and/or AES-256-GCM-encrypted.
+type Filesystem struct {
+	root string
+
+	compress bool
+	encoder  *zstd.Encoder
+	decoder  *zstd.Decoder
+
+	aead cipher.AEAD // nil if encryption is disabled
+
+	writeSem chan struct{}
+}
+
+// Option configures a Filesystem store.
+type Option func(*Filesystem) error
+
+// WithCompression enables zstd compression of stored blobs.
+func WithCompression() Option {
+	return func(fs *Filesystem) error {
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return fmt.Errorf("blobstore: new zstd encoder: %w", err)
+		}
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return fmt.Errorf("blobstore: new zstd decoder: %w", err)
+		}
+		fs.compress = true
+		fs.encoder = enc
+		fs.decoder = dec
+		return nil
+	}
+}
+
+// WithEncryption enables AES-256-GCM encryption, deriving the key from
+// passphrase via scrypt using salt (store salt alongside the vault, e.g. in
+// its metadata, and pass the same salt on every Open).
+func WithEncryption(passphrase string, salt []byte) Option {
+	return func(fs *Filesystem) error {
+		aead, err := deriveAEAD(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		fs.aead = aead
+		return nil
+	}
+}
+
+// Open creates (if needed) and returns a Filesystem blob store rooted at dir.
+func Open(dir string, opts ...Option) (*Filesystem, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("blobstore: mkdir %s: %w", dir, err)
+	}
+	fs := &Filesystem{root: dir, writeSem: make(chan struct{}, maxConcurrentWrites)}
+	for _, opt := range opts {
+		if err := opt(fs); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+func (fs *Filesystem) path(hash string) string {
+	return filepath.Join(fs.root, hash[:2], hash)
+}
+
+// Put stores data and returns its content address. Puts of already-stored
+// content are no-ops (aside from the hash computation).
+func (fs *Filesystem) Put(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	select {
+	case fs.writeSem <- struct{}{}:
+		defer func() { <-fs.writeSem }()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	dest := fs.path(hash)
+	if _, err := os.Stat(dest); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", fmt.Errorf("blobstore: mkdir: %w", err)
+	}
+
+	payload, err := fs.encode(data)
+	if err != nil {
+		return "", err
+	}
+
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o600); err != nil {
+		return "", fmt.Errorf("blobstore: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("blobstore: rename %s: %w", tmp, err)
+	}
+	return hash, nil
+}
+
+// Get retrieves the bytes previously stored under hash.
+func (fs *Filesystem) Get(ctx context.Context, hash string) ([]byte, error) {
+	payload, err := os.ReadFile(fs.path(hash))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: read %s: %w", hash, err)
+	}
+	return fs.decode(payload)
+}
+
+func (fs *Filesystem) encode(data []byte) ([]byte, error) {
+	if fs.compress {
+		data = fs.encoder.EncodeAll(data, nil)
+	}
+	return sealPayload(fs.aead, data)
+}
+
+func (fs *Filesystem) decode(payload []byte) ([]byte, error) {
+	data, err := openPayload(fs.aead, payload)
+	if err != nil {
+		return nil, err
+	}
+	if fs.compress {
+		decoded, err := fs.decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("blobstore: decompress: %w", err)
+		}
+		return decoded, nil
+	}
+	return data, nil
+}
+
+// Stats reports the store's on-disk size and the free space remaining on
+// its underlying filesystem.
+func (fs *Filesystem) Stats(ctx context.Context) (BlobStoreStats, error) {
+	return diskStats(fs.root)
+}
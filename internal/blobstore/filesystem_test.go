@@ -0,0 +1,97 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestFilesystem_PutGetRoundTrip(t *testing.T) {
+	fs, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	want := []byte("From: a@example.com\r\n\r\nhello")
+
+	hash, err := fs.Put(context.Background(), want)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := fs.Get(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Get() = %q, want %q", got, want)
+	}
+}
+
+func TestFilesystem_CompressionRoundTrip(t *testing.T) {
+	fs, err := Open(t.TempDir(), WithCompression())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	want := bytes.Repeat([]byte("compress me please "), 200)
+
+	hash, err := fs.Put(context.Background(), want)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := fs.Get(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("decompressed data does not match original")
+	}
+}
+
+func TestFilesystem_EncryptionRoundTrip(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt: %v", err)
+	}
+	fs, err := Open(t.TempDir(), WithEncryption("correct horse battery staple", salt))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	want := []byte("top secret message bytes")
+
+	hash, err := fs.Put(context.Background(), want)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := fs.Get(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("decrypted data does not match original")
+	}
+
+	// Wrong passphrase must fail to decrypt.
+	wrongFS, err := Open(fs.root, WithEncryption("wrong passphrase", salt))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := wrongFS.Get(context.Background(), hash); err == nil {
+		t.Error("Get() with wrong passphrase succeeded, want error")
+	}
+}
+
+func TestFilesystem_Stats(t *testing.T) {
+	fs, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := fs.Put(context.Background(), []byte("some bytes")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	stats, err := fs.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.SizeBytes == 0 {
+		t.Error("SizeBytes = 0, want > 0")
+	}
+}
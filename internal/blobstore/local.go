@@ -0,0 +1,92 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalAttachmentStore is an AttachmentStore backed by a content-addressed
+// directory tree, chunked by the first two hex characters of the key (the
+// hex-encoded SHA-256 digest) to avoid huge directories.
+type LocalAttachmentStore struct {
+	root string
+}
+
+// NewLocalAttachmentStore creates (if needed) and returns a
+// LocalAttachmentStore rooted at dir.
+func NewLocalAttachmentStore(dir string) (*LocalAttachmentStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("blobstore: mkdir %s: %w", dir, err)
+	}
+	return &LocalAttachmentStore{root: dir}, nil
+}
+
+func (s *LocalAttachmentStore) path(key string) string {
+	return filepath.Join(s.root, key[:2], key)
+}
+
+func (s *LocalAttachmentStore) Put(ctx context.Context, sha256 []byte, r io.Reader) (string, error) {
+	key := hex.EncodeToString(sha256)
+	dest := s.path(key)
+
+	if _, err := os.Stat(dest); err == nil {
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", fmt.Errorf("blobstore: mkdir: %w", err)
+	}
+
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("blobstore: create %s: %w", tmp, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("blobstore: write %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("blobstore: close %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("blobstore: rename %s: %w", tmp, err)
+	}
+	return key, nil
+}
+
+func (s *LocalAttachmentStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *LocalAttachmentStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *LocalAttachmentStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("blobstore: remove %s: %w", key, err)
+	}
+	return nil
+}
+
+var _ AttachmentStore = (*LocalAttachmentStore)(nil)
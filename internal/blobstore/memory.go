@@ -0,0 +1,64 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryAttachmentStore is an in-memory AttachmentStore for tests; nothing
+// is persisted across process restarts.
+type MemoryAttachmentStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryAttachmentStore returns an empty in-memory store.
+func NewMemoryAttachmentStore() *MemoryAttachmentStore {
+	return &MemoryAttachmentStore{data: make(map[string][]byte)}
+}
+
+func (s *MemoryAttachmentStore) Put(ctx context.Context, sha256 []byte, r io.Reader) (string, error) {
+	key := hex.EncodeToString(sha256)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; ok {
+		return key, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("blobstore: read: %w", err)
+	}
+	s.data[key] = data
+	return key, nil
+}
+
+func (s *MemoryAttachmentStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.data[key]
+	if !ok {
+		return nil, fmt.Errorf("blobstore: key %s not found", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryAttachmentStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.data[key]
+	return ok, nil
+}
+
+func (s *MemoryAttachmentStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+var _ AttachmentStore = (*MemoryAttachmentStore)(nil)
@@ -0,0 +1,216 @@
+package blobstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MessageCompression selects how MessageBlobStore compresses a message's
+// raw bytes on disk.
+type MessageCompression string
+
+const (
+	MessageCompressionNone MessageCompression = "none"
+	MessageCompressionGzip MessageCompression = "gzip"
+	MessageCompressionZstd MessageCompression = "zstd"
+)
+
+// MessageBlobStore persists a message's raw MIME bytes under
+// <root>/msg/<shard1>/<shard2>/<sourceMessageID>, two-level sharded by the
+// first four hex characters of sha256(sourceMessageID) rather than by
+// content hash, so a single large mailbox doesn't pile millions of files
+// into one directory — mirroring the msg/ layout used by on-disk
+// mailstores like mox. Unlike Filesystem and the AttachmentStore backends,
+// messages aren't deduplicated by content: each row owns exactly one file,
+// found (and removed) by ID alone.
+type MessageBlobStore struct {
+	root string
+	aead cipher.AEAD // nil if encryption is disabled
+}
+
+// MessageBlobStoreOption configures a MessageBlobStore at construction
+// time, following the same functional-options pattern as Filesystem.Option.
+type MessageBlobStoreOption func(*MessageBlobStore) error
+
+// WithMessageEncryption enables AES-256-GCM encryption of every message
+// written through Put, deriving the key from passphrase via scrypt using
+// salt (the same scheme as Filesystem's WithEncryption). Callers
+// typically generate passphrase once per vault with a CSPRNG and persist
+// it (and salt, via NewSalt) alongside the vault's own metadata, since
+// losing either makes every stored message unrecoverable.
+func WithMessageEncryption(passphrase string, salt []byte) MessageBlobStoreOption {
+	return func(s *MessageBlobStore) error {
+		aead, err := deriveAEAD(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		s.aead = aead
+		return nil
+	}
+}
+
+// NewMessageBlobStore creates (if needed) and returns a MessageBlobStore
+// rooted at dir.
+func NewMessageBlobStore(dir string, opts ...MessageBlobStoreOption) (*MessageBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("blobstore: mkdir %s: %w", dir, err)
+	}
+	s := &MessageBlobStore{root: dir}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Path returns the path MessageBlobStore uses for sourceMessageID, relative
+// to its root, for callers (store) that persist it alongside the message
+// row as messages.blob_path.
+func (s *MessageBlobStore) Path(sourceMessageID string) string {
+	sum := sha256.Sum256([]byte(sourceMessageID))
+	shard := hex.EncodeToString(sum[:2])
+	return filepath.Join("msg", shard[:2], shard[2:4], sourceMessageID)
+}
+
+// Put compresses data per compression and writes it to
+// Path(sourceMessageID), returning that relative path, the SHA-256 of the
+// uncompressed bytes (hex), and their uncompressed size, so the caller can
+// persist all three alongside the message row (blob_path, blob_sha256,
+// blob_size).
+func (s *MessageBlobStore) Put(sourceMessageID string, data []byte, compression MessageCompression) (relPath, sha256Hex string, size int64, err error) {
+	sum := sha256.Sum256(data)
+	relPath = s.Path(sourceMessageID)
+	dest := filepath.Join(s.root, relPath)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", "", 0, fmt.Errorf("blobstore: mkdir: %w", err)
+	}
+
+	payload, err := compressMessage(data, compression)
+	if err != nil {
+		return "", "", 0, err
+	}
+	payload, err = s.encrypt(payload)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o600); err != nil {
+		return "", "", 0, fmt.Errorf("blobstore: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", "", 0, fmt.Errorf("blobstore: rename %s: %w", tmp, err)
+	}
+	return relPath, hex.EncodeToString(sum[:]), int64(len(data)), nil
+}
+
+// Open reads the message at relPath, decompresses it per compression, and
+// verifies its SHA-256 against wantSHA256 before returning, so a caller
+// never gets silently-corrupted bytes back.
+func (s *MessageBlobStore) Open(relPath string, compression MessageCompression, wantSHA256 string) (io.ReadCloser, error) {
+	payload, err := os.ReadFile(filepath.Join(s.root, relPath))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: read %s: %w", relPath, err)
+	}
+	payload, err = s.decrypt(payload)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: decrypt %s: %w", relPath, err)
+	}
+	data, err := decompressMessage(payload, compression)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: decompress %s: %w", relPath, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != wantSHA256 {
+		return nil, fmt.Errorf("blobstore: sha256 mismatch for %s: got %s, want %s", relPath, got, wantSHA256)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// encrypt seals payload with AEAD, prefixing a fresh random nonce, if
+// encryption is enabled (see WithMessageEncryption); otherwise it's a no-op.
+func (s *MessageBlobStore) encrypt(payload []byte) ([]byte, error) {
+	return sealPayload(s.aead, payload)
+}
+
+// decrypt reverses encrypt; a no-op if encryption is disabled.
+func (s *MessageBlobStore) decrypt(payload []byte) ([]byte, error) {
+	return openPayload(s.aead, payload)
+}
+
+// Stats reports the store's on-disk size and the free space remaining on
+// its underlying filesystem, the same way Filesystem.Stats does, so a
+// capacity checker can watch either backend interchangeably.
+func (s *MessageBlobStore) Stats(ctx context.Context) (BlobStoreStats, error) {
+	return diskStats(s.root)
+}
+
+// Delete removes the message stored at relPath.
+func (s *MessageBlobStore) Delete(relPath string) error {
+	if err := os.Remove(filepath.Join(s.root, relPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("blobstore: remove %s: %w", relPath, err)
+	}
+	return nil
+}
+
+func compressMessage(data []byte, compression MessageCompression) ([]byte, error) {
+	switch compression {
+	case "", MessageCompressionNone:
+		return data, nil
+	case MessageCompressionGzip:
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(data); err != nil {
+			return nil, fmt.Errorf("blobstore: gzip write: %w", err)
+		}
+		if err := gw.Close(); err != nil {
+			return nil, fmt.Errorf("blobstore: gzip close: %w", err)
+		}
+		return buf.Bytes(), nil
+	case MessageCompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, fmt.Errorf("blobstore: new zstd encoder: %w", err)
+		}
+		defer enc.Close()
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("blobstore: unknown compression %q", compression)
+	}
+}
+
+func decompressMessage(payload []byte, compression MessageCompression) ([]byte, error) {
+	switch compression {
+	case "", MessageCompressionNone:
+		return payload, nil
+	case MessageCompressionGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("blobstore: gzip reader: %w", err)
+		}
+		defer gr.Close()
+		return io.ReadAll(gr)
+	case MessageCompressionZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("blobstore: new zstd decoder: %w", err)
+		}
+		defer dec.Close()
+		return dec.DecodeAll(payload, nil)
+	default:
+		return nil, fmt.Errorf("blobstore: unknown compression %q", compression)
+	}
+}
@@ -0,0 +1,128 @@
+package blobstore
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMessageBlobStore_RoundTrip(t *testing.T) {
+	for _, compression := range []MessageCompression{MessageCompressionNone, MessageCompressionGzip, MessageCompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			s, err := NewMessageBlobStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewMessageBlobStore: %v", err)
+			}
+			want := bytes.Repeat([]byte("From: a@example.com\r\n\r\nhello "), 50)
+
+			relPath, sha256Hex, size, err := s.Put("msg-1", want, compression)
+			if err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if size != int64(len(want)) {
+				t.Errorf("size = %d, want %d", size, len(want))
+			}
+
+			rc, err := s.Open(relPath, compression, sha256Hex)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer rc.Close()
+			got := make([]byte, len(want))
+			if _, err := rc.Read(got); err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Open() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMessageBlobStore_ShaMismatchFailsRead(t *testing.T) {
+	s, err := NewMessageBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewMessageBlobStore: %v", err)
+	}
+	relPath, _, _, err := s.Put("msg-1", []byte("hello"), MessageCompressionNone)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := s.Open(relPath, MessageCompressionNone, strings.Repeat("0", 64)); err == nil {
+		t.Error("Open() with wrong sha256 succeeded, want error")
+	}
+}
+
+func TestMessageBlobStore_ShardsByIDHash(t *testing.T) {
+	s, err := NewMessageBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewMessageBlobStore: %v", err)
+	}
+	path := s.Path("msg-1")
+	parts := 0
+	for _, r := range path {
+		if r == '/' {
+			parts++
+		}
+	}
+	if parts != 3 {
+		t.Errorf("Path() = %q, want msg/<shard1>/<shard2>/id (3 separators)", path)
+	}
+}
+
+func TestMessageBlobStore_EncryptionRoundTrip(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt: %v", err)
+	}
+	s, err := NewMessageBlobStore(t.TempDir(), WithMessageEncryption("correct horse battery staple", salt))
+	if err != nil {
+		t.Fatalf("NewMessageBlobStore: %v", err)
+	}
+	want := []byte("From: a@example.com\r\n\r\nhello")
+
+	relPath, sha256Hex, _, err := s.Put("msg-1", want, MessageCompressionNone)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rc, err := s.Open(relPath, MessageCompressionNone, sha256Hex)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Open() = %q, want %q", got, want)
+	}
+
+	// Wrong passphrase must fail to decrypt.
+	wrongStore, err := NewMessageBlobStore(s.root, WithMessageEncryption("wrong passphrase", salt))
+	if err != nil {
+		t.Fatalf("NewMessageBlobStore: %v", err)
+	}
+	if _, err := wrongStore.Open(relPath, MessageCompressionNone, sha256Hex); err == nil {
+		t.Error("Open() with wrong passphrase succeeded, want error")
+	}
+}
+
+func TestMessageBlobStore_Delete(t *testing.T) {
+	s, err := NewMessageBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewMessageBlobStore: %v", err)
+	}
+	relPath, sha256Hex, _, err := s.Put("msg-1", []byte("hello"), MessageCompressionNone)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(relPath); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(relPath, MessageCompressionNone, sha256Hex); err == nil {
+		t.Error("Open() after Delete succeeded, want error")
+	}
+}
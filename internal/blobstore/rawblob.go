@@ -0,0 +1,28 @@
+package blobstore
+
+import "context"
+
+// BlobStore persists raw message and attachment bytes outside the
+// database, keyed by content hash, so SQLite only needs to hold the hash
+// and metadata. internal/sync's Syncer uses it (when its Options.BlobStore
+// is set) in place of storing raw MIME inline. Defined here, rather than
+// in internal/sync, so that Filesystem (this package's implementation of
+// it) doesn't need to import internal/sync. MessageBlobStore is keyed by
+// message ID rather than content hash and does not implement it.
+type BlobStore interface {
+	// Put stores data and returns its content address (a hex-encoded
+	// SHA-256 digest). Puts of already-stored content are no-ops.
+	Put(ctx context.Context, data []byte) (hash string, err error)
+
+	// Get retrieves the bytes previously stored under hash.
+	Get(ctx context.Context, hash string) ([]byte, error)
+
+	// Stats reports the store's on-disk footprint and remaining free space.
+	Stats(ctx context.Context) (BlobStoreStats, error)
+}
+
+// BlobStoreStats describes a BlobStore's on-disk footprint.
+type BlobStoreStats struct {
+	SizeBytes      int64
+	FreeSpaceBytes int64
+}
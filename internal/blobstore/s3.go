@@ -0,0 +1,101 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Config configures an S3AttachmentStore. Endpoint is optional and lets
+// this target any S3-compatible service (MinIO, R2, B2) rather than only
+// AWS.
+type S3Config struct {
+	Endpoint             string
+	Region               string
+	Bucket               string
+	Prefix               string // optional key prefix, e.g. "attachments/"
+	ServerSideEncryption string // e.g. "AES256" or "aws:kms"; empty disables
+}
+
+// S3AttachmentStore is an AttachmentStore backed by an S3-compatible
+// object store.
+type S3AttachmentStore struct {
+	cfg    S3Config
+	client *s3.Client
+}
+
+// NewS3AttachmentStore builds an S3AttachmentStore from cfg, using client
+// (constructed by the caller via aws-sdk-go-v2's config loader so
+// credentials and region resolution stay out of this package).
+func NewS3AttachmentStore(cfg S3Config, client *s3.Client) *S3AttachmentStore {
+	return &S3AttachmentStore{cfg: cfg, client: client}
+}
+
+func (s *S3AttachmentStore) objectKey(key string) string {
+	return s.cfg.Prefix + key
+}
+
+func (s *S3AttachmentStore) Put(ctx context.Context, sha256 []byte, r io.Reader) (string, error) {
+	key := hex.EncodeToString(sha256)
+
+	if exists, err := s.Exists(ctx, key); err != nil {
+		return "", err
+	} else if exists {
+		return key, nil
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+		Body:   r,
+	}
+	if s.cfg.ServerSideEncryption != "" {
+		input.ServerSideEncryption = s3Encryption(s.cfg.ServerSideEncryption)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return "", fmt.Errorf("blobstore: s3 put %s: %w", key, err)
+	}
+	return key, nil
+}
+
+func (s *S3AttachmentStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: s3 get %s: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *S3AttachmentStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("blobstore: s3 head %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (s *S3AttachmentStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		return fmt.Errorf("blobstore: s3 delete %s: %w", key, err)
+	}
+	return nil
+}
+
+var _ AttachmentStore = (*S3AttachmentStore)(nil)
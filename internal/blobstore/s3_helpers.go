@@ -0,0 +1,27 @@
+package blobstore
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func s3Encryption(mode string) types.ServerSideEncryption {
+	return types.ServerSideEncryption(mode)
+}
+
+// isNotFound reports whether err represents an S3 404 response, across
+// both the typed NotFound error and the generic HTTP-status fallback
+// some S3-compatible services (MinIO, older R2) return instead.
+func isNotFound(err error) bool {
+	var nf *types.NotFound
+	if errors.As(err, &nf) {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() == 404
+	}
+	return false
+}
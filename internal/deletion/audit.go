@@ -0,0 +1,230 @@
+package deletion
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEvent identifies what kind of action an AuditRecord describes.
+type AuditEvent string
+
+const (
+	AuditEventAttempt AuditEvent = "attempt"
+	AuditEventResult  AuditEvent = "result"
+	AuditEventBatch   AuditEvent = "batch"
+)
+
+// AuditSink receives a record of every deletion attempt, result, and batch
+// operation Executor performs, alongside (not instead of) Progress
+// notifications. Executor.WithAuditSink installs one; Execute/ExecuteBatch
+// (including ExecuteBatch's per-message fallback when BatchDeleteMessages
+// itself fails) call it for every message and every batch.
+type AuditSink interface {
+	RecordAttempt(manifestID, gmailID string, method Method) error
+	RecordResult(manifestID, gmailID string, method Method, err error) error
+	RecordBatch(manifestID string, gmailIDs []string, method Method, err error) error
+}
+
+// AuditRecord is one line of a FileAuditSink's audit log. PrevHash and Hash
+// form a hash chain: Hash is the SHA-256 hex digest of the record's
+// canonical JSON with Hash itself cleared first, and PrevHash is the
+// previous record's Hash (empty for the first record in a manifest's log).
+// Modifying or deleting any line therefore changes every Hash after it, and
+// VerifyAudit can report exactly where the chain first breaks.
+type AuditRecord struct {
+	Event      AuditEvent
+	ManifestID string
+	GmailIDs   []string
+	Method     Method
+	Error      string `json:",omitempty"`
+	Time       time.Time
+	PrevHash   string
+	Hash       string
+}
+
+// FileAuditSink is the default AuditSink: it appends one JSON line per
+// record to <root>/audit/<manifestID>.jsonl, chaining each record's hash to
+// the one written before it. root is normally the Manager's root directory.
+type FileAuditSink struct {
+	root string
+
+	mu       sync.Mutex
+	lastHash map[string]string // manifestID -> most recently written record's Hash
+}
+
+// NewFileAuditSink creates a FileAuditSink writing audit logs under root.
+func NewFileAuditSink(root string) *FileAuditSink {
+	return &FileAuditSink{root: root, lastHash: make(map[string]string)}
+}
+
+func auditLogPath(root, manifestID string) string {
+	return filepath.Join(root, "audit", manifestID+".jsonl")
+}
+
+// RecordAttempt logs that Executor is about to act on gmailID.
+func (s *FileAuditSink) RecordAttempt(manifestID, gmailID string, method Method) error {
+	return s.append(AuditRecord{
+		Event:      AuditEventAttempt,
+		ManifestID: manifestID,
+		GmailIDs:   []string{gmailID},
+		Method:     method,
+		Time:       timeNow(),
+	})
+}
+
+// RecordResult logs the outcome of acting on gmailID. err is nil on success.
+func (s *FileAuditSink) RecordResult(manifestID, gmailID string, method Method, err error) error {
+	rec := AuditRecord{
+		Event:      AuditEventResult,
+		ManifestID: manifestID,
+		GmailIDs:   []string{gmailID},
+		Method:     method,
+		Time:       timeNow(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return s.append(rec)
+}
+
+// RecordBatch logs a BatchDeleteMessages call covering gmailIDs. err is nil
+// on success.
+func (s *FileAuditSink) RecordBatch(manifestID string, gmailIDs []string, method Method, err error) error {
+	rec := AuditRecord{
+		Event:      AuditEventBatch,
+		ManifestID: manifestID,
+		GmailIDs:   gmailIDs,
+		Method:     method,
+		Time:       timeNow(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return s.append(rec)
+}
+
+// append computes rec's hash chain fields and appends it to manifestID's log
+// as one JSON line.
+func (s *FileAuditSink) append(rec AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec.PrevHash = s.lastHash[rec.ManifestID]
+	rec.Hash = hashRecord(rec)
+
+	path := auditLogPath(s.root, rec.ManifestID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("audit: mkdir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("audit: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("audit: marshal record: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("audit: write %s: %w", path, err)
+	}
+
+	s.lastHash[rec.ManifestID] = rec.Hash
+	return nil
+}
+
+// hashRecord returns the SHA-256 hex digest of rec's canonical JSON with
+// Hash cleared first, so the digest covers everything about the record
+// except the digest itself.
+func hashRecord(rec AuditRecord) string {
+	rec.Hash = ""
+	data, _ := json.Marshal(rec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAudit re-reads manifestID's audit log and confirms every record's
+// Hash matches its recomputed content and chains to the record before it.
+// It returns the zero-based line index of the first record that fails
+// either check, or -1 if the whole log verifies cleanly.
+func (s *FileAuditSink) VerifyAudit(manifestID string) (brokenIndex int, err error) {
+	path := auditLogPath(s.root, manifestID)
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, fmt.Errorf("verify audit: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	prevHash := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for i := 0; scanner.Scan(); i++ {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return i, nil
+		}
+		wantHash := rec.Hash
+		if rec.PrevHash != prevHash || hashRecord(rec) != wantHash {
+			return i, nil
+		}
+		prevHash = wantHash
+	}
+	if err := scanner.Err(); err != nil {
+		return -1, fmt.Errorf("verify audit: read %s: %w", path, err)
+	}
+	return -1, nil
+}
+
+// WithAuditSink installs sink so Execute/ExecuteBatch record every attempt,
+// result, and batch operation to it in addition to firing Progress
+// callbacks. A nil sink (the default) disables auditing entirely.
+func (e *Executor) WithAuditSink(sink AuditSink) *Executor {
+	e.auditSink = sink
+	return e
+}
+
+// recordAttempt logs gmailID's upcoming operation to e.auditSink, if one is
+// installed. A logging failure is reported to e.logger (if set) rather than
+// aborting the deletion it describes, since losing an audit line is not a
+// reason to leave a message undeleted.
+func (e *Executor) recordAttempt(manifestID, gmailID string, method Method) {
+	if e.auditSink == nil {
+		return
+	}
+	if err := e.auditSink.RecordAttempt(manifestID, gmailID, method); err != nil && e.logger != nil {
+		e.logger.Warn("audit: record attempt failed", "manifest", manifestID, "gmail_id", gmailID, "error", err)
+	}
+}
+
+// recordResult logs gmailID's completed operation to e.auditSink, if one is
+// installed.
+func (e *Executor) recordResult(manifestID, gmailID string, method Method, opErr error) {
+	if e.auditSink == nil {
+		return
+	}
+	if err := e.auditSink.RecordResult(manifestID, gmailID, method, opErr); err != nil && e.logger != nil {
+		e.logger.Warn("audit: record result failed", "manifest", manifestID, "gmail_id", gmailID, "error", err)
+	}
+}
+
+// recordBatch logs a BatchDeleteMessages call to e.auditSink, if one is
+// installed. ExecuteBatch's per-message fallback (when the batch call
+// itself fails) should still go through recordAttempt/recordResult per
+// message rather than this method, since at that point each message is
+// handled individually again.
+func (e *Executor) recordBatch(manifestID string, gmailIDs []string, method Method, batchErr error) {
+	if e.auditSink == nil {
+		return
+	}
+	if err := e.auditSink.RecordBatch(manifestID, gmailIDs, method, batchErr); err != nil && e.logger != nil {
+		e.logger.Warn("audit: record batch failed", "manifest", manifestID, "error", err)
+	}
+}
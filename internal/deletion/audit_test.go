@@ -0,0 +1,137 @@
+package deletion
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileAuditSink_RecordAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewFileAuditSink(dir)
+
+	if err := sink.RecordAttempt("manifest1", "msg1", MethodTrash); err != nil {
+		t.Fatalf("RecordAttempt: %v", err)
+	}
+	if err := sink.RecordResult("manifest1", "msg1", MethodTrash, nil); err != nil {
+		t.Fatalf("RecordResult: %v", err)
+	}
+	if err := sink.RecordBatch("manifest1", []string{"msg2", "msg3"}, MethodDelete, nil); err != nil {
+		t.Fatalf("RecordBatch: %v", err)
+	}
+
+	broken, err := sink.VerifyAudit("manifest1")
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if broken != -1 {
+		t.Errorf("VerifyAudit broken index = %d, want -1 (clean chain)", broken)
+	}
+}
+
+func TestVerifyAudit_DetectsTamperedLine(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewFileAuditSink(dir)
+
+	for i, gmailID := range []string{"msg1", "msg2", "msg3"} {
+		if err := sink.RecordAttempt("manifest1", gmailID, MethodTrash); err != nil {
+			t.Fatalf("RecordAttempt %d: %v", i, err)
+		}
+	}
+
+	path := auditLogPath(dir, "manifest1")
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+
+	// Tamper with the second record (index 1) without recomputing its hash,
+	// simulating an attacker editing the file directly.
+	var rec AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("unmarshal line 1: %v", err)
+	}
+	rec.GmailIDs = []string{"msg2-tampered"}
+	tampered, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("marshal tampered record: %v", err)
+	}
+	lines[1] = string(tampered)
+	writeLines(t, path, lines)
+
+	broken, err := sink.VerifyAudit("manifest1")
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if broken != 1 {
+		t.Errorf("VerifyAudit broken index = %d, want 1", broken)
+	}
+}
+
+func TestVerifyAudit_DetectsDeletedLine(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewFileAuditSink(dir)
+
+	for i, gmailID := range []string{"msg1", "msg2", "msg3"} {
+		if err := sink.RecordAttempt("manifest1", gmailID, MethodTrash); err != nil {
+			t.Fatalf("RecordAttempt %d: %v", i, err)
+		}
+	}
+
+	path := auditLogPath(dir, "manifest1")
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+
+	// Drop the first record; the second record's PrevHash now points to a
+	// hash that no longer precedes it in the file.
+	lines = append(lines[:0], lines[1:]...)
+	writeLines(t, path, lines)
+
+	broken, err := sink.VerifyAudit("manifest1")
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if broken != 0 {
+		t.Errorf("VerifyAudit broken index = %d, want 0 (first remaining line no longer chains)", broken)
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan %s: %v", path, err)
+	}
+	return lines
+}
+
+func writeLines(t *testing.T, path string, lines []string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	defer f.Close()
+	for _, line := range lines {
+		if _, err := f.WriteString(line + "\n"); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// Backend is the mail-provider-specific half of a deletion: moving one or
+// more messages to a recoverable state (TrashOne), permanently removing one
+// (DeleteOne or, for bulk, BatchDelete), and recognizing a provider's own
+// "already gone" error (IsNotFound) so Execute/Commit/Rollback can treat it
+// as success rather than failure. Executor.WithBackend installs one;
+// without it, Executor falls back to calling its gmail.API directly (the
+// behavior that predates this interface), so existing Gmail-only callers
+// don't need to change.
+type Backend interface {
+	TrashOne(ctx context.Context, id string) error
+	DeleteOne(ctx context.Context, id string) error
+	BatchDelete(ctx context.Context, ids []string) error
+	IsNotFound(err error) bool
+}
+
+// Backend name constants for Execution.Backend, the field a resumed
+// manifest reads to know which Backend to reconstruct and dispatch to.
+const (
+	BackendGmail = "gmail"
+	BackendIMAP  = "imap"
+	BackendGraph = "graph"
+)
+
+// GmailBackend adapts a gmail.API into a Backend, including
+// gmail.NotFoundError as its notion of "already gone" - the same check
+// Execute/Commit/Rollback used directly before this interface existed.
+type GmailBackend struct {
+	api gmail.API
+}
+
+// NewGmailBackend wraps api as a Backend.
+func NewGmailBackend(api gmail.API) *GmailBackend {
+	return &GmailBackend{api: api}
+}
+
+func (b *GmailBackend) TrashOne(ctx context.Context, id string) error {
+	return b.api.TrashMessage(ctx, id)
+}
+
+func (b *GmailBackend) DeleteOne(ctx context.Context, id string) error {
+	return b.api.DeleteMessage(ctx, id)
+}
+
+func (b *GmailBackend) BatchDelete(ctx context.Context, ids []string) error {
+	return b.api.BatchDeleteMessages(ctx, ids)
+}
+
+func (b *GmailBackend) IsNotFound(err error) bool {
+	var notFound *gmail.NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// WithBackend installs b so Execute/Commit/Rollback dispatch deletion calls
+// through it instead of going straight to e.api. Use this to point an
+// Executor at a non-Gmail provider, e.g. imapdeletion.NewBackend or
+// graphdeletion.NewBackend; NewGmailBackend reproduces the Gmail-only
+// default for callers that want to set one explicitly.
+func (e *Executor) WithBackend(b Backend) *Executor {
+	e.backend = b
+	return e
+}
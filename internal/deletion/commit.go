@@ -0,0 +1,145 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// isNotFound reports whether err is the installed Backend's notion of
+// "already gone" (or, with no Backend installed, a gmail.NotFoundError) -
+// the idempotency check Execute/ExecuteBatch use to treat a message already
+// missing on the server as success rather than failure.
+func (e *Executor) isNotFound(err error) bool {
+	if e.backend != nil {
+		return e.backend.IsNotFound(err)
+	}
+	var notFound *gmail.NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// StatusAwaitingCommit is a manifest's status between StatusInProgress and
+// StatusCompleted under the two-phase model: phase 1 (Execute, with
+// Method == MethodTrash) has moved every message to trash, and the
+// manifest now sits in a rollback window until Commit promotes it to a
+// permanent delete or Rollback restores the messages. Manifests pass
+// through this status only when CommitAfter is set; a plain MethodTrash or
+// MethodDelete run with no two-phase commit configured goes straight from
+// StatusInProgress to StatusCompleted as before.
+const StatusAwaitingCommit Status = "awaiting_commit"
+
+// PendingCommit is the journal entry Execute records for a manifest when it
+// finishes phase 1 of a two-phase MethodTrash run: every message has been
+// trashed, and CommitAfter must elapse before Commit is allowed to promote
+// them to a permanent delete.
+type PendingCommit struct {
+	ManifestID  string
+	TrashedAt   time.Time
+	CommitAfter time.Duration
+}
+
+// ReadyAt is the earliest time Commit may run against this PendingCommit.
+func (p *PendingCommit) ReadyAt() time.Time {
+	return p.TrashedAt.Add(p.CommitAfter)
+}
+
+// Commit promotes manifestID's trashed messages to a permanent delete. It
+// only applies to manifests in StatusAwaitingCommit, and only once the
+// PendingCommit's CommitAfter window has elapsed - calling it earlier
+// returns an error rather than silently waiting, since an early commit
+// would defeat the rollback window's purpose.
+func (e *Executor) Commit(ctx context.Context, manifestID string) error {
+	manifest, _, err := e.mgr.GetManifest(manifestID)
+	if err != nil {
+		return fmt.Errorf("commit: get manifest %s: %w", manifestID, err)
+	}
+	if manifest.Status != StatusAwaitingCommit {
+		return fmt.Errorf("commit: manifest %s is %s, want %s", manifestID, manifest.Status, StatusAwaitingCommit)
+	}
+
+	pending, err := e.mgr.GetPendingCommit(manifestID)
+	if err != nil {
+		return fmt.Errorf("commit: get pending commit %s: %w", manifestID, err)
+	}
+	if readyAt := pending.ReadyAt(); ctx.Err() == nil && timeNow().Before(readyAt) {
+		return fmt.Errorf("commit: manifest %s not ready until %s", manifestID, readyAt)
+	}
+
+	for _, gmailID := range manifest.GmailIDs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		err := e.withRetry(ctx, gmailID, func() error { return e.deleteOne(ctx, gmailID, MethodDelete) })
+		if err != nil && !e.isNotFound(err) {
+			manifest.Execution.FailedIDs = append(manifest.Execution.FailedIDs, gmailID)
+		}
+	}
+
+	if err := e.mgr.ClearPendingCommit(manifestID); err != nil {
+		return fmt.Errorf("commit: clear pending commit %s: %w", manifestID, err)
+	}
+	return e.mgr.MoveManifest(manifestID, StatusAwaitingCommit, StatusCompleted)
+}
+
+// Rollback restores manifestID's trashed messages instead of promoting
+// them to a permanent delete, via the Gmail API's UntrashMessage. Like
+// Commit, it only applies to manifests in StatusAwaitingCommit; unlike
+// Commit, it has no CommitAfter window to wait out, since undoing a trash
+// is always safe to do immediately.
+func (e *Executor) Rollback(ctx context.Context, manifestID string) error {
+	manifest, _, err := e.mgr.GetManifest(manifestID)
+	if err != nil {
+		return fmt.Errorf("rollback: get manifest %s: %w", manifestID, err)
+	}
+	if manifest.Status != StatusAwaitingCommit {
+		return fmt.Errorf("rollback: manifest %s is %s, want %s", manifestID, manifest.Status, StatusAwaitingCommit)
+	}
+
+	for _, gmailID := range manifest.GmailIDs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := e.api.UntrashMessage(ctx, gmailID); err != nil && !e.isNotFound(err) {
+			return fmt.Errorf("rollback: untrash %s: %w", gmailID, err)
+		}
+	}
+
+	if err := e.mgr.ClearPendingCommit(manifestID); err != nil {
+		return fmt.Errorf("rollback: clear pending commit %s: %w", manifestID, err)
+	}
+	return e.mgr.MoveManifest(manifestID, StatusAwaitingCommit, StatusFailed)
+}
+
+// ListAwaitingCommit returns manifests in StatusAwaitingCommit whose
+// PendingCommit.ReadyAt has already passed, so a caller (e.g. a scheduled
+// job) can find manifests ready for Commit without scanning every pending
+// one itself.
+func (e *Executor) ListAwaitingCommit(ctx context.Context) ([]*Manifest, error) {
+	all, err := e.mgr.ListAwaitingCommit()
+	if err != nil {
+		return nil, fmt.Errorf("list awaiting commit: %w", err)
+	}
+
+	var ready []*Manifest
+	for _, manifest := range all {
+		pending, err := e.mgr.GetPendingCommit(manifest.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list awaiting commit: get pending commit %s: %w", manifest.ID, err)
+		}
+		if !timeNow().Before(pending.ReadyAt()) {
+			ready = append(ready, manifest)
+		}
+	}
+	return ready, nil
+}
+
+// timeNow is time.Now, indirected so Commit's readiness check can be
+// exercised with a fixed clock in tests.
+var timeNow = time.Now
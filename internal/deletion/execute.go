@@ -0,0 +1,228 @@
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// gmailBatchLimit is the largest batch BatchDeleteMessages accepts in one
+// call (see gmail.API.BatchDeleteMessages).
+const gmailBatchLimit = 1000
+
+// ExecuteOptions configures one Execute call.
+type ExecuteOptions struct {
+	Method Method
+
+	// BatchSize is accepted for forward compatibility with batched
+	// progress checkpointing; executeConcurrent does not yet use it.
+	BatchSize int
+
+	// Concurrency is how many worker goroutines executeConcurrent runs.
+	// Execute treats an unset (zero) value as 1, never as "don't process
+	// anything".
+	Concurrency int
+
+	Resume bool
+
+	// CommitAfter, if non-zero and Method is MethodTrash, makes a
+	// successful Execute move the manifest to StatusAwaitingCommit
+	// instead of StatusCompleted, recording a PendingCommit that Commit
+	// (see commit.go) won't act on until CommitAfter has elapsed.
+	CommitAfter time.Duration
+
+	// RateLimiter, if set, is shared across executeConcurrent's workers
+	// instead of the default 5 qps limiter - set this to share a limiter
+	// across multiple concurrent Execute calls against the same account.
+	RateLimiter *gmail.RateLimiter
+}
+
+// DefaultExecuteOptions returns the options Execute uses when called with
+// nil: trash (recoverable), a 100-message batch size, 5-way concurrency,
+// and resume enabled.
+func DefaultExecuteOptions() ExecuteOptions {
+	return ExecuteOptions{
+		Method:      MethodTrash,
+		BatchSize:   100,
+		Concurrency: 5,
+		Resume:      true,
+	}
+}
+
+// Execute runs manifestID's deletion: it must be StatusPending or
+// StatusInProgress (a prior interrupted run), moves it to StatusInProgress,
+// then processes its GmailIDs across executeConcurrent's worker pool. A
+// manifest in any other status, or one that doesn't exist, is an error. If
+// ctx is cancelled mid-run, Execute persists whatever progress was made and
+// returns ctx.Err() with the manifest left in StatusInProgress for a later
+// resume; otherwise it finishes the manifest via finishExecute.
+func (e *Executor) Execute(ctx context.Context, manifestID string, opts *ExecuteOptions) error {
+	if opts == nil {
+		defaults := DefaultExecuteOptions()
+		opts = &defaults
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	manifest, status, err := e.mgr.GetManifest(manifestID)
+	if err != nil {
+		return fmt.Errorf("execute %s: %w", manifestID, err)
+	}
+	if status != StatusPending && status != StatusInProgress {
+		return fmt.Errorf("execute %s: status is %s, want %s or %s", manifestID, status, StatusPending, StatusInProgress)
+	}
+
+	if status == StatusPending {
+		if err := e.mgr.MoveManifest(manifestID, StatusPending, StatusInProgress); err != nil {
+			return fmt.Errorf("execute %s: move to in-progress: %w", manifestID, err)
+		}
+		manifest.Status = StatusInProgress
+	}
+
+	if manifest.Execution == nil || !opts.Resume {
+		manifest.Execution = &Execution{StartedAt: timeNow(), Method: opts.Method}
+	}
+	manifest.Method = opts.Method
+
+	e.progress.OnStart(len(manifest.GmailIDs) - manifest.Execution.LastProcessedIndex)
+	runErr := e.executeConcurrent(ctx, manifest, opts)
+	e.progress.OnComplete(manifest.Execution.Succeeded, manifest.Execution.Failed)
+
+	if runErr != nil {
+		if saveErr := e.mgr.SaveManifest(manifest); saveErr != nil {
+			return fmt.Errorf("execute %s: %w (save after %v also failed: %v)", manifestID, runErr, runErr, saveErr)
+		}
+		return runErr
+	}
+
+	return e.finishExecute(manifest, opts)
+}
+
+// finishExecute persists manifest's final Execution and moves it out of
+// StatusInProgress: to StatusFailed if every message failed, to
+// StatusAwaitingCommit if opts requested a commit delay on a trash run, or
+// StatusCompleted otherwise.
+func (e *Executor) finishExecute(manifest *Manifest, opts *ExecuteOptions) error {
+	if manifest.Execution.Succeeded == 0 && manifest.Execution.Failed > 0 {
+		return e.moveManifestTo(manifest, StatusFailed)
+	}
+
+	if opts.Method == MethodTrash && opts.CommitAfter > 0 {
+		if err := e.mgr.SavePendingCommit(&PendingCommit{
+			ManifestID:  manifest.ID,
+			TrashedAt:   timeNow(),
+			CommitAfter: opts.CommitAfter,
+		}); err != nil {
+			return fmt.Errorf("finish manifest %s: save pending commit: %w", manifest.ID, err)
+		}
+		return e.moveManifestTo(manifest, StatusAwaitingCommit)
+	}
+
+	return e.moveManifestTo(manifest, StatusCompleted)
+}
+
+// moveManifestTo saves manifest (still recorded under StatusInProgress)
+// and then moves it to to, updating manifest.Status to match.
+func (e *Executor) moveManifestTo(manifest *Manifest, to Status) error {
+	if err := e.mgr.SaveManifest(manifest); err != nil {
+		return fmt.Errorf("finish manifest %s: save: %w", manifest.ID, err)
+	}
+	if err := e.mgr.MoveManifest(manifest.ID, StatusInProgress, to); err != nil {
+		return fmt.Errorf("finish manifest %s: move to %s: %w", manifest.ID, to, err)
+	}
+	manifest.Status = to
+	return nil
+}
+
+// ExecuteBatch permanently deletes manifestID's messages via Gmail's bulk
+// BatchDeleteMessages endpoint, chunked to gmailBatchLimit IDs per call. A
+// chunk whose batch call fails falls back to deleting each of its IDs
+// individually via DeleteMessage. Unlike Execute, ExecuteBatch only
+// accepts StatusPending manifests (it has no resume support) and always
+// uses MethodDelete - Gmail's batchDelete endpoint has no trash
+// equivalent.
+func (e *Executor) ExecuteBatch(ctx context.Context, manifestID string) error {
+	manifest, status, err := e.mgr.GetManifest(manifestID)
+	if err != nil {
+		return fmt.Errorf("execute batch %s: %w", manifestID, err)
+	}
+	if status != StatusPending {
+		return fmt.Errorf("execute batch %s: status is %s, want %s", manifestID, status, StatusPending)
+	}
+	if err := e.mgr.MoveManifest(manifestID, StatusPending, StatusInProgress); err != nil {
+		return fmt.Errorf("execute batch %s: move to in-progress: %w", manifestID, err)
+	}
+	manifest.Status = StatusInProgress
+	manifest.Method = MethodDelete
+	manifest.Execution = &Execution{StartedAt: timeNow(), Method: MethodDelete}
+
+	started := manifest.Execution.StartedAt
+	e.progress.OnStart(len(manifest.GmailIDs))
+	e.notifyManifestStarted(manifest.ID, len(manifest.GmailIDs))
+
+	var records []MessageReport
+	for start := 0; start < len(manifest.GmailIDs); start += gmailBatchLimit {
+		if ctx.Err() != nil {
+			if saveErr := e.mgr.SaveManifest(manifest); saveErr != nil {
+				return fmt.Errorf("execute batch %s: %w (save also failed: %v)", manifestID, ctx.Err(), saveErr)
+			}
+			return ctx.Err()
+		}
+
+		end := start + gmailBatchLimit
+		if end > len(manifest.GmailIDs) {
+			end = len(manifest.GmailIDs)
+		}
+		chunk := manifest.GmailIDs[start:end]
+
+		batchErr := e.api.BatchDeleteMessages(ctx, chunk)
+		e.recordBatch(manifest.ID, chunk, MethodDelete, batchErr)
+
+		if batchErr == nil {
+			for _, gmailID := range chunk {
+				manifest.Execution.Succeeded++
+				records = append(records, e.buildMessageReport(gmailID, MethodDelete, 1, nil))
+			}
+			manifest.Execution.LastProcessedIndex = end
+			e.progress.OnProgress(manifest.Execution.Succeeded+manifest.Execution.Failed, manifest.Execution.Succeeded, manifest.Execution.Failed)
+			continue
+		}
+
+		for _, gmailID := range chunk {
+			e.recordAttempt(manifest.ID, gmailID, MethodDelete)
+			attempts := 0
+			err := e.withRetry(ctx, gmailID, func() error {
+				attempts++
+				return e.api.DeleteMessage(ctx, gmailID)
+			})
+			e.recordResult(manifest.ID, gmailID, MethodDelete, err)
+			e.notifyMessageResult(manifest.ID, gmailID, MethodDelete, err)
+			records = append(records, e.buildMessageReport(gmailID, MethodDelete, attempts, err))
+
+			if err != nil && !e.isNotFound(err) {
+				manifest.Execution.Failed++
+				manifest.Execution.FailedIDs = append(manifest.Execution.FailedIDs, gmailID)
+			} else {
+				manifest.Execution.Succeeded++
+			}
+			manifest.Execution.LastProcessedIndex++
+			e.progress.OnProgress(manifest.Execution.Succeeded+manifest.Execution.Failed, manifest.Execution.Succeeded, manifest.Execution.Failed)
+		}
+	}
+
+	e.notifyManifestCompleted(manifest.ID, manifest.Execution.Succeeded, manifest.Execution.Failed)
+	e.progress.OnComplete(manifest.Execution.Succeeded, manifest.Execution.Failed)
+
+	report := e.buildReport(manifest, started, timeNow(), records)
+	if err := e.saveReport(report); err != nil && e.logger != nil {
+		e.logger.Error("save deletion report", "manifest", manifest.ID, "error", err)
+	}
+
+	if manifest.Execution.Succeeded == 0 && manifest.Execution.Failed > 0 {
+		return e.moveManifestTo(manifest, StatusFailed)
+	}
+	return e.moveManifestTo(manifest, StatusCompleted)
+}
@@ -0,0 +1,79 @@
+package deletion
+
+import (
+	"log/slog"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+	"github.com/wesm/msgvault/internal/webhook"
+)
+
+// Progress receives lifecycle callbacks from Execute/ExecuteBatch: OnStart
+// once with the total message count, OnProgress after each message
+// completes, OnRetry before each retried attempt (see RetryPolicy), and
+// OnComplete once with the run's final tallies.
+type Progress interface {
+	OnStart(total int)
+	OnProgress(processed, succeeded, failed int)
+	OnComplete(succeeded, failed int)
+	OnRetry(id string, attempt int, err error)
+}
+
+// NullProgress discards every callback. It's the default installed until
+// WithProgress overrides it.
+type NullProgress struct{}
+
+func (NullProgress) OnStart(total int)                           {}
+func (NullProgress) OnProgress(processed, succeeded, failed int) {}
+func (NullProgress) OnComplete(succeeded, failed int)            {}
+func (NullProgress) OnRetry(id string, attempt int, err error)   {}
+
+// Executor runs Manifests against a mail provider: Execute processes
+// GmailIDs one at a time across a bounded worker pool, ExecuteBatch
+// instead uses Gmail's bulk BatchDelete endpoint, falling back to
+// individual deletes for any chunk it rejects. Beyond the mgr/store/api
+// core, every other behavior - retries, auditing, digest verification,
+// webhooks, an alternate Backend - is opt-in via the With* builders below,
+// so a caller that wants none of it gets the same behavior a plain
+// gmail.API-backed deletion always had.
+type Executor struct {
+	mgr   *Manager
+	store *store.Store
+	api   gmail.API
+
+	logger   *slog.Logger
+	progress Progress
+
+	backend     Backend
+	auditSink   AuditSink
+	retryPolicy *RetryPolicy
+	verifier    Verifier
+	webhook     *webhook.Dispatcher
+}
+
+// NewExecutor builds an Executor that persists manifests through mgr,
+// looks up archived content (for WithVerifier's default DigestLookup)
+// through st, and talks to the mail provider through api. Every optional
+// behavior starts disabled; chain the With* methods to enable it.
+func NewExecutor(mgr *Manager, st *store.Store, api gmail.API) *Executor {
+	return &Executor{
+		mgr:      mgr,
+		store:    st,
+		api:      api,
+		progress: NullProgress{},
+	}
+}
+
+// WithLogger installs logger for Executor's own diagnostic logging (failed
+// report saves, audit sink errors). A nil logger (the default) discards
+// it.
+func (e *Executor) WithLogger(logger *slog.Logger) *Executor {
+	e.logger = logger
+	return e
+}
+
+// WithProgress installs progress in place of the default NullProgress.
+func (e *Executor) WithProgress(progress Progress) *Executor {
+	e.progress = progress
+	return e
+}
@@ -77,6 +77,21 @@ type trackingProgress struct {
 	completed   bool
 	finalSucc   int
 	finalFail   int
+	retryLog    []struct {
+		id      string
+		attempt int
+		err     error
+	}
+}
+
+func (p *trackingProgress) OnRetry(id string, attempt int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retryLog = append(p.retryLog, struct {
+		id      string
+		attempt int
+		err     error
+	}{id, attempt, err})
 }
 
 func (p *trackingProgress) OnStart(total int) {
@@ -1147,6 +1162,52 @@ func TestDeletionMockAPI_SetTransientFailure(t *testing.T) {
 	}
 }
 
+// TestExecutor_WithRetryPolicy_TransientFailureSucceeds verifies that a
+// message failing transiently (gmail.DeletionMockAPI.SetTransientFailure)
+// ends in finalSucc, not finalFail, once a RetryPolicy with enough
+// MaxAttempts is installed.
+func TestExecutor_WithRetryPolicy_TransientFailureSucceeds(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr, err := NewManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	store := testutil.NewTestStore(t)
+	mockAPI := gmail.NewDeletionMockAPI()
+	progress := &trackingProgress{}
+
+	// msg1 fails its first 2 attempts, then succeeds.
+	mockAPI.SetTransientFailure("msg1", 2, true)
+
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 3
+	policy.InitialBackoff = time.Millisecond
+	policy.RetryableClassifier = func(err error) bool { return err != nil }
+
+	exec := NewExecutor(mgr, store, mockAPI).WithProgress(progress).WithRetryPolicy(policy)
+
+	gmailIDs := []string{"msg1"}
+	manifest, err := mgr.CreateManifest("transient failure test", gmailIDs, Filters{})
+	if err != nil {
+		t.Fatalf("CreateManifest() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := exec.Execute(ctx, manifest.ID, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if progress.finalSucc != 1 {
+		t.Errorf("finalSucc = %d, want 1", progress.finalSucc)
+	}
+	if progress.finalFail != 0 {
+		t.Errorf("finalFail = %d, want 0", progress.finalFail)
+	}
+	if len(progress.retryLog) != 2 {
+		t.Errorf("retryLog has %d entries, want 2 (one per failed attempt)", len(progress.retryLog))
+	}
+}
+
 // TestDeletionMockAPI_Hooks tests before hooks.
 func TestDeletionMockAPI_Hooks(t *testing.T) {
 	mockAPI := gmail.NewDeletionMockAPI()
@@ -0,0 +1,205 @@
+package deletion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestStatuses lists every status directory NewManager creates, and
+// the order GetManifest searches them in (most commonly looked-up first).
+var manifestStatuses = []Status{
+	StatusPending,
+	StatusInProgress,
+	StatusCompleted,
+	StatusFailed,
+	StatusAwaitingCommit,
+}
+
+const pendingCommitDir = "pending_commit"
+
+// Manager persists Manifests (and their associated PendingCommits) as
+// plain JSON files under root: one subdirectory per Status, so a
+// Manifest's status is recoverable from the filesystem layout alone - the
+// same plain-file approach saveReport/LoadReport use for reports.
+type Manager struct {
+	root string
+}
+
+// NewManager creates (if needed) root and its per-status subdirectories
+// and returns a Manager backed by it.
+func NewManager(root string) (*Manager, error) {
+	for _, status := range manifestStatuses {
+		if err := os.MkdirAll(filepath.Join(root, string(status)), 0o755); err != nil {
+			return nil, fmt.Errorf("deletion: create %s dir: %w", status, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, pendingCommitDir), 0o755); err != nil {
+		return nil, fmt.Errorf("deletion: create %s dir: %w", pendingCommitDir, err)
+	}
+	return &Manager{root: root}, nil
+}
+
+// Root returns the directory Manager was constructed with, the same base
+// directory saveReport/LoadReport and plan.go save plans and reports in.
+func (m *Manager) Root() string {
+	return m.root
+}
+
+func (m *Manager) manifestPath(status Status, id string) string {
+	return filepath.Join(m.root, string(status), id+".json")
+}
+
+// SaveManifest writes manifest to its current Status's directory,
+// overwriting any existing file for its ID.
+func (m *Manager) SaveManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save manifest %s: marshal: %w", manifest.ID, err)
+	}
+	if err := os.WriteFile(m.manifestPath(manifest.Status, manifest.ID), data, 0o644); err != nil {
+		return fmt.Errorf("save manifest %s: write: %w", manifest.ID, err)
+	}
+	return nil
+}
+
+// CreateManifest builds a new pending Manifest for gmailIDs, attaches
+// filters, persists it, and returns it.
+func (m *Manager) CreateManifest(description string, gmailIDs []string, filters Filters) (*Manifest, error) {
+	manifest := NewManifest(description, gmailIDs)
+	manifest.Filters = filters
+	if err := m.SaveManifest(manifest); err != nil {
+		return nil, fmt.Errorf("create manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// GetManifest loads id from whichever status directory currently holds it,
+// returning the Status it was found under alongside it.
+func (m *Manager) GetManifest(id string) (*Manifest, Status, error) {
+	for _, status := range manifestStatuses {
+		data, err := os.ReadFile(m.manifestPath(status, id))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, "", fmt.Errorf("get manifest %s: read %s: %w", id, status, err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, "", fmt.Errorf("get manifest %s: unmarshal: %w", id, err)
+		}
+		return &manifest, status, nil
+	}
+	return nil, "", fmt.Errorf("get manifest %s: not found", id)
+}
+
+// MoveManifest transitions id from from's status directory to to's: it
+// loads id from from, updates its Status to to, writes it into to's
+// directory, and removes the old file. It errors without touching
+// anything if id isn't currently in from.
+func (m *Manager) MoveManifest(id string, from, to Status) error {
+	fromPath := m.manifestPath(from, id)
+	data, err := os.ReadFile(fromPath)
+	if err != nil {
+		return fmt.Errorf("move manifest %s from %s to %s: read: %w", id, from, to, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("move manifest %s from %s to %s: unmarshal: %w", id, from, to, err)
+	}
+	manifest.Status = to
+
+	newData, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("move manifest %s from %s to %s: marshal: %w", id, from, to, err)
+	}
+	if err := os.WriteFile(m.manifestPath(to, id), newData, 0o644); err != nil {
+		return fmt.Errorf("move manifest %s from %s to %s: write: %w", id, from, to, err)
+	}
+	if err := os.Remove(fromPath); err != nil {
+		return fmt.Errorf("move manifest %s from %s to %s: remove old: %w", id, from, to, err)
+	}
+	return nil
+}
+
+// listManifests returns every Manifest currently in status's directory.
+func (m *Manager) listManifests(status Status) ([]*Manifest, error) {
+	dir := filepath.Join(m.root, string(status))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %s manifests: %w", status, err)
+	}
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("list %s manifests: read %s: %w", status, entry.Name(), err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("list %s manifests: unmarshal %s: %w", status, entry.Name(), err)
+		}
+		manifests = append(manifests, &manifest)
+	}
+	return manifests, nil
+}
+
+func (m *Manager) ListPending() ([]*Manifest, error) { return m.listManifests(StatusPending) }
+
+func (m *Manager) ListInProgress() ([]*Manifest, error) { return m.listManifests(StatusInProgress) }
+
+func (m *Manager) ListCompleted() ([]*Manifest, error) { return m.listManifests(StatusCompleted) }
+
+func (m *Manager) ListFailed() ([]*Manifest, error) { return m.listManifests(StatusFailed) }
+
+// ListAwaitingCommit returns every Manifest currently in StatusAwaitingCommit,
+// regardless of whether its PendingCommit is ready yet - Executor.ListAwaitingCommit
+// filters that further.
+func (m *Manager) ListAwaitingCommit() ([]*Manifest, error) {
+	return m.listManifests(StatusAwaitingCommit)
+}
+
+func (m *Manager) pendingCommitPath(manifestID string) string {
+	return filepath.Join(m.root, pendingCommitDir, manifestID+".json")
+}
+
+// SavePendingCommit persists pc, recording that manifestID's trashed
+// messages are in the CommitAfter window Commit/Rollback check.
+func (m *Manager) SavePendingCommit(pc *PendingCommit) error {
+	data, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save pending commit %s: marshal: %w", pc.ManifestID, err)
+	}
+	if err := os.WriteFile(m.pendingCommitPath(pc.ManifestID), data, 0o644); err != nil {
+		return fmt.Errorf("save pending commit %s: write: %w", pc.ManifestID, err)
+	}
+	return nil
+}
+
+// GetPendingCommit loads the PendingCommit previously saved for manifestID.
+func (m *Manager) GetPendingCommit(manifestID string) (*PendingCommit, error) {
+	data, err := os.ReadFile(m.pendingCommitPath(manifestID))
+	if err != nil {
+		return nil, fmt.Errorf("get pending commit %s: %w", manifestID, err)
+	}
+	var pc PendingCommit
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("get pending commit %s: unmarshal: %w", manifestID, err)
+	}
+	return &pc, nil
+}
+
+// ClearPendingCommit removes manifestID's PendingCommit once Commit or
+// Rollback has resolved it. Clearing an already-cleared (or never-created)
+// PendingCommit is a no-op, not an error.
+func (m *Manager) ClearPendingCommit(manifestID string) error {
+	if err := os.Remove(m.pendingCommitPath(manifestID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("clear pending commit %s: %w", manifestID, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package deletion
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Status is a Manifest's place in its lifecycle: Pending (created, not yet
+// run), InProgress (Execute/ExecuteBatch is running or was interrupted),
+// Completed, Failed, or (Method trash only, see commit.go) AwaitingCommit.
+// Manager stores a Manifest under a directory named for its current
+// Status, so Status also doubles as the on-disk location.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// Method is how Execute/ExecuteBatch removes a message: MethodTrash moves
+// it to Gmail's trash (recoverable for 30 days, and eligible for the
+// two-phase commit/rollback in commit.go), MethodDelete removes it
+// permanently.
+type Method string
+
+const (
+	MethodTrash  Method = "trash"
+	MethodDelete Method = "delete"
+)
+
+// Filters records the criteria a Manifest's GmailIDs were selected by, so a
+// saved Manifest is self-describing even after the selection that produced
+// it is long gone. Empty for now; populate as deletion gains query-driven
+// selection.
+type Filters struct{}
+
+// Execution tracks one Manifest's in-progress or finished run: when it
+// started, which Method it used, how many messages succeeded/failed, and
+// (via LastProcessedIndex) how far into GmailIDs a resumed Execute should
+// skip to.
+type Execution struct {
+	StartedAt          time.Time
+	Method             Method
+	Succeeded          int
+	Failed             int
+	FailedIDs          []string
+	LastProcessedIndex int
+}
+
+// Manifest is the persistent record of one deletion run: the GmailIDs it
+// targets, the Filters they were selected by, and (once Execute or
+// ExecuteBatch has started) its Execution.
+type Manifest struct {
+	ID          string
+	Description string
+	GmailIDs    []string
+	Filters     Filters
+	Method      Method
+	Status      Status
+	CreatedAt   time.Time
+	Execution   *Execution
+}
+
+// NewManifest builds a new Manifest in StatusPending for gmailIDs,
+// assigning it a fresh ID. It does not persist the Manifest; callers go
+// through Manager.CreateManifest for that.
+func NewManifest(description string, gmailIDs []string) *Manifest {
+	return &Manifest{
+		ID:          newManifestID(),
+		Description: description,
+		GmailIDs:    gmailIDs,
+		Status:      StatusPending,
+		CreatedAt:   timeNow(),
+	}
+}
+
+// newManifestID returns a unique, sortable-by-creation-time manifest ID:
+// a timestamp prefix followed by random hex, so IDs are both unique and
+// safe to use directly as a filename.
+func newManifestID() string {
+	var suffix [6]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		panic(fmt.Sprintf("deletion: read random manifest ID suffix: %v", err))
+	}
+	return fmt.Sprintf("manifest-%s-%s", timeNow().Format("20060102T150405.000000000"), hex.EncodeToString(suffix[:]))
+}
@@ -0,0 +1,127 @@
+package deletion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExecutionPlan is the result of a dry run: what Execute/ExecuteBatch would
+// have done to a manifest's messages, without ever calling TrashMessage,
+// DeleteMessage, or BatchDeleteMessages. Executor.Plan produces one and
+// persists it alongside the manifest so a user can review it before
+// actually committing to the deletion.
+type ExecutionPlan struct {
+	ManifestID string
+	Method     Method
+	Items      []PlannedItem
+
+	TotalCount   int // every item, skipped or not
+	SkippedCount int // items with a non-empty PreCheck; TotalCount-SkippedCount is the "will delete" total
+}
+
+// PlannedItem is one message's planned disposition in an ExecutionPlan.
+type PlannedItem struct {
+	GmailID string
+	Method  Method
+
+	// TargetLabel is the label the message would end up under after the
+	// operation (e.g. "TRASH" for MethodTrash); empty for MethodDelete,
+	// which removes the message entirely rather than relabeling it.
+	TargetLabel string
+
+	// PreCheck names a reason this item won't actually be deleted if the
+	// plan is executed as-is (e.g. "message not present on server"). Empty
+	// when the item is expected to delete cleanly.
+	PreCheck string
+}
+
+// planFileName is the plan's file name within the manager's root, alongside
+// the manifest itself.
+func planFileName(manifestID string) string {
+	return manifestID + ".plan.json"
+}
+
+// Plan walks manifestID's messages exactly like Execute/ExecuteBatch would,
+// but never calls the Gmail API's mutating methods - it only reports what
+// would happen. Progress callbacks fire the same as a real run, so callers
+// that render a progress bar don't need a separate code path; the
+// callbacks carry no flag marking them as a dry run. The resulting plan is
+// persisted under the manager's root
+// (alongside the manifest) so it can be reviewed before a subsequent
+// Execute/ExecuteBatch call commits to it.
+func (e *Executor) Plan(ctx context.Context, manifestID string, opts *ExecuteOptions) (*ExecutionPlan, error) {
+	if opts == nil {
+		defaults := DefaultExecuteOptions()
+		opts = &defaults
+	}
+
+	manifest, _, err := e.mgr.GetManifest(manifestID)
+	if err != nil {
+		return nil, fmt.Errorf("plan: get manifest %s: %w", manifestID, err)
+	}
+
+	plan := &ExecutionPlan{
+		ManifestID: manifestID,
+		Method:     opts.Method,
+	}
+
+	e.progress.OnStart(len(manifest.GmailIDs))
+	for i, gmailID := range manifest.GmailIDs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		item := PlannedItem{GmailID: gmailID, Method: opts.Method}
+		if opts.Method == MethodTrash {
+			item.TargetLabel = "TRASH"
+		}
+		if _, err := e.api.GetMessageRaw(ctx, gmailID); err != nil {
+			item.PreCheck = "message not present on server"
+			plan.SkippedCount++
+		}
+		plan.Items = append(plan.Items, item)
+		plan.TotalCount++
+		e.progress.OnProgress(i+1, plan.TotalCount-plan.SkippedCount, plan.SkippedCount)
+	}
+	e.progress.OnComplete(plan.TotalCount-plan.SkippedCount, plan.SkippedCount)
+
+	if err := e.savePlan(plan); err != nil {
+		return nil, fmt.Errorf("plan: save: %w", err)
+	}
+	return plan, nil
+}
+
+// savePlan writes plan as JSON to the manager's root directory, next to
+// manifestID's own manifest file.
+func (e *Executor) savePlan(plan *ExecutionPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal plan: %w", err)
+	}
+	path := filepath.Join(e.mgr.Root(), planFileName(plan.ManifestID))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write plan %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPlan reads back a previously saved ExecutionPlan for manifestID, so a
+// caller can review a dry run's results before calling Execute/ExecuteBatch
+// for real.
+func (e *Executor) LoadPlan(manifestID string) (*ExecutionPlan, error) {
+	path := filepath.Join(e.mgr.Root(), planFileName(manifestID))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read plan %s: %w", path, err)
+	}
+	var plan ExecutionPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("unmarshal plan %s: %w", path, err)
+	}
+	return &plan, nil
+}
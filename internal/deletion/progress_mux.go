@@ -0,0 +1,113 @@
+package deletion
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ProgressMux fans out OnStart/OnProgress/OnComplete/OnRetry to a set of
+// registered Progress sinks, the way a logrus hook chain dispatches one log
+// entry to every installed hook. It replaces installing a single Progress
+// via WithProgress with a composite that behaves like one: WithProgress
+// takes a *ProgressMux exactly as it would any other Progress.
+//
+// Sinks can be added or removed while a manifest is running - Add/Remove
+// only ever touch the slice under mu, so a concurrent executeConcurrent
+// worker calling OnProgress sees either the old or the new sink set, never
+// a half-updated one. A sink that panics is assumed broken: ProgressMux
+// recovers, logs the panic, and permanently disables that sink rather than
+// letting it take the whole run down or keep panicking on every event.
+type ProgressMux struct {
+	mu     sync.Mutex
+	sinks  []Progress
+	logger *slog.Logger
+}
+
+// NewProgressMux builds a ProgressMux fanning out to sinks.
+func NewProgressMux(sinks ...Progress) *ProgressMux {
+	return &ProgressMux{sinks: append([]Progress(nil), sinks...)}
+}
+
+// WithLogger installs logger for reporting a sink's panic; with no logger
+// installed, a recovered panic is simply swallowed along with disabling
+// the sink.
+func (m *ProgressMux) WithLogger(logger *slog.Logger) *ProgressMux {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+	return m
+}
+
+// Add registers sink to receive future events.
+func (m *ProgressMux) Add(sink Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sinks = append(m.sinks, sink)
+}
+
+// Remove unregisters sink; it is a no-op if sink was never added (or was
+// already disabled after panicking).
+func (m *ProgressMux) Remove(sink Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.sinks {
+		if s == sink {
+			m.sinks = append(m.sinks[:i], m.sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// active returns a snapshot of the current sink set, safe to range over
+// without holding mu for the duration of each sink's call.
+func (m *ProgressMux) active() []Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Progress(nil), m.sinks...)
+}
+
+// disable removes sink after it panicked, logging why if a logger is
+// installed.
+func (m *ProgressMux) disable(sink Progress, event string, r any) {
+	if m.logger != nil {
+		m.logger.Error("progress sink panicked, disabling it", "event", event, "panic", fmt.Sprint(r))
+	}
+	m.Remove(sink)
+}
+
+func (m *ProgressMux) OnStart(total int) {
+	for _, sink := range m.active() {
+		m.call(sink, "OnStart", func() { sink.OnStart(total) })
+	}
+}
+
+func (m *ProgressMux) OnProgress(processed, succeeded, failed int) {
+	for _, sink := range m.active() {
+		m.call(sink, "OnProgress", func() { sink.OnProgress(processed, succeeded, failed) })
+	}
+}
+
+func (m *ProgressMux) OnComplete(succeeded, failed int) {
+	for _, sink := range m.active() {
+		m.call(sink, "OnComplete", func() { sink.OnComplete(succeeded, failed) })
+	}
+}
+
+func (m *ProgressMux) OnRetry(id string, attempt int, err error) {
+	for _, sink := range m.active() {
+		m.call(sink, "OnRetry", func() { sink.OnRetry(id, attempt, err) })
+	}
+}
+
+// call invokes fn, recovering a panic from sink so one misbehaving sink
+// cannot abort the event or take down the caller (an executeConcurrent
+// worker, in the common case).
+func (m *ProgressMux) call(sink Progress, event string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.disable(sink, event, r)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,82 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/testutil"
+)
+
+// TestProgressMux_FansOutToBothSinks mirrors
+// TestExecutor_Execute_DeletionMock_MixedErrors, but installs two
+// trackingProgress sinks behind a ProgressMux and asserts both saw the
+// same event sequence.
+func TestProgressMux_FansOutToBothSinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr, err := NewManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	store := testutil.NewTestStore(t)
+	mockAPI := gmail.NewDeletionMockAPI()
+
+	sinkA := &trackingProgress{}
+	sinkB := &trackingProgress{}
+	mux := NewProgressMux(sinkA, sinkB)
+
+	mockAPI.SetNotFoundError("msg2")
+	mockAPI.TrashErrors["msg4"] = errors.New("server error")
+
+	exec := NewExecutor(mgr, store, mockAPI).WithProgress(mux)
+
+	gmailIDs := []string{"msg1", "msg2", "msg3", "msg4", "msg5"}
+	manifest, err := mgr.CreateManifest("mixed errors test", gmailIDs, Filters{})
+	if err != nil {
+		t.Fatalf("CreateManifest() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := exec.Execute(ctx, manifest.ID, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if sinkA.finalSucc != 4 || sinkA.finalFail != 1 {
+		t.Errorf("sinkA final = (%d, %d), want (4, 1)", sinkA.finalSucc, sinkA.finalFail)
+	}
+	if sinkB.finalSucc != sinkA.finalSucc || sinkB.finalFail != sinkA.finalFail {
+		t.Errorf("sinkB final = (%d, %d), want to match sinkA (%d, %d)",
+			sinkB.finalSucc, sinkB.finalFail, sinkA.finalSucc, sinkA.finalFail)
+	}
+	if !reflect.DeepEqual(sinkA.progressLog, sinkB.progressLog) {
+		t.Errorf("progressLog mismatch:\nsinkA = %+v\nsinkB = %+v", sinkA.progressLog, sinkB.progressLog)
+	}
+	if sinkA.startTotal != sinkB.startTotal {
+		t.Errorf("startTotal mismatch: sinkA=%d sinkB=%d", sinkA.startTotal, sinkB.startTotal)
+	}
+}
+
+type panickyProgress struct{}
+
+func (panickyProgress) OnStart(total int)                        { panic("boom") }
+func (panickyProgress) OnProgress(processed, succeeded, failed int) {}
+func (panickyProgress) OnComplete(succeeded, failed int)          {}
+func (panickyProgress) OnRetry(id string, attempt int, err error) {}
+
+func TestProgressMux_DisablesPanickingSink(t *testing.T) {
+	good := &trackingProgress{}
+	mux := NewProgressMux(panickyProgress{}, good)
+
+	mux.OnStart(10) // panickyProgress panics here and should be disabled
+	mux.OnProgress(5, 4, 1)
+	mux.OnComplete(4, 1)
+
+	if good.startTotal != 10 {
+		t.Errorf("good sink startTotal = %d, want 10 (panic in the other sink must not block delivery)", good.startTotal)
+	}
+	if len(mux.active()) != 1 {
+		t.Errorf("active sinks = %d, want 1 (panicking sink should be disabled)", len(mux.active()))
+	}
+}
@@ -0,0 +1,148 @@
+package deletion
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// This package has no existing dependency on go.opentelemetry.io, and
+// OTelProgress does not introduce one: Tracer/Span/Counter/Histogram below
+// are small interfaces shaped after the real OTel SDK's trace.Tracer /
+// trace.Span / metric.Int64Counter / metric.Float64Histogram types, so a
+// caller who already has a real OTel SDK wired up can satisfy them with a
+// few lines of adapter code, but nothing here forces that dependency on a
+// caller who doesn't.
+
+// Span is a single manifest or per-message span, closed by End.
+type Span interface {
+	End()
+	SetAttributes(kv ...KeyValue)
+	RecordError(err error)
+}
+
+// KeyValue is one span/event attribute.
+type KeyValue struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts spans for a manifest run and its per-message operations.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Counter is a monotonic counter, e.g. messages succeeded or failed.
+type Counter interface {
+	Add(ctx context.Context, value int64, kv ...KeyValue)
+}
+
+// Histogram records a distribution, e.g. per-message operation latency.
+type Histogram interface {
+	Record(ctx context.Context, value float64, kv ...KeyValue)
+}
+
+// OTelProgress is the built-in Progress sink that reports a manifest run as
+// a trace (one span per manifest, plus one child span per retry; messages
+// get no span of their own) plus success/fail counters and a latency
+// histogram of the time between OnProgress events - the shape a
+// dashboard built on OpenTelemetry would expect. Any of Tracer, Succeeded,
+// Failed, or Latency may be left nil; OTelProgress only records what it was
+// given.
+type OTelProgress struct {
+	Tracer    Tracer
+	Succeeded Counter
+	Failed    Counter
+	Latency   Histogram
+
+	ctx context.Context
+
+	mu           sync.Mutex
+	manifestCtx  context.Context
+	manifestSpan Span
+	lastStart    time.Time
+}
+
+// NewOTelProgress builds an OTelProgress reporting through ctx - the
+// context carrying whatever OTel resource/baggage the caller's tracer
+// provider needs.
+func NewOTelProgress(ctx context.Context, tracer Tracer, succeeded, failed Counter, latency Histogram) *OTelProgress {
+	return &OTelProgress{
+		Tracer:    tracer,
+		Succeeded: succeeded,
+		Failed:    failed,
+		Latency:   latency,
+		ctx:       ctx,
+	}
+}
+
+func (o *OTelProgress) OnStart(total int) {
+	if o.Tracer == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ctx, span := o.Tracer.Start(o.ctx, "deletion.manifest")
+	span.SetAttributes(KeyValue{Key: "deletion.total", Value: total})
+	o.manifestCtx, o.manifestSpan = ctx, span
+	o.lastStart = time.Now()
+}
+
+func (o *OTelProgress) OnProgress(processed, succeeded, failed int) {
+	if o.Latency == nil {
+		return
+	}
+	o.mu.Lock()
+	elapsed := time.Since(o.lastStart)
+	o.lastStart = time.Now()
+	ctx := o.manifestCtx
+	o.mu.Unlock()
+	if ctx == nil {
+		ctx = o.ctx
+	}
+	o.Latency.Record(ctx, elapsed.Seconds())
+}
+
+func (o *OTelProgress) OnComplete(succeeded, failed int) {
+	ctx := o.ctx
+	o.mu.Lock()
+	if o.manifestCtx != nil {
+		ctx = o.manifestCtx
+	}
+	span := o.manifestSpan
+	o.manifestSpan = nil
+	o.mu.Unlock()
+
+	if o.Succeeded != nil {
+		o.Succeeded.Add(ctx, int64(succeeded))
+	}
+	if o.Failed != nil {
+		o.Failed.Add(ctx, int64(failed))
+	}
+	if span != nil {
+		span.SetAttributes(
+			KeyValue{Key: "deletion.succeeded", Value: succeeded},
+			KeyValue{Key: "deletion.failed", Value: failed},
+		)
+		span.End()
+	}
+}
+
+func (o *OTelProgress) OnRetry(id string, attempt int, err error) {
+	if o.Tracer == nil {
+		return
+	}
+	ctx := o.ctx
+	o.mu.Lock()
+	if o.manifestCtx != nil {
+		ctx = o.manifestCtx
+	}
+	o.mu.Unlock()
+
+	_, span := o.Tracer.Start(ctx, "deletion.retry")
+	span.SetAttributes(KeyValue{Key: "deletion.gmail_id", Value: id}, KeyValue{Key: "deletion.attempt", Value: attempt})
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
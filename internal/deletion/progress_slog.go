@@ -0,0 +1,31 @@
+package deletion
+
+import "log/slog"
+
+// SlogProgress reports deletion progress as structured log/slog entries -
+// the built-in Progress sink for callers who just want the run's events in
+// their existing log stream, with no dashboard or metrics backend involved.
+type SlogProgress struct {
+	logger *slog.Logger
+}
+
+// NewSlogProgress builds a SlogProgress logging through logger.
+func NewSlogProgress(logger *slog.Logger) *SlogProgress {
+	return &SlogProgress{logger: logger}
+}
+
+func (s *SlogProgress) OnStart(total int) {
+	s.logger.Info("deletion started", "total", total)
+}
+
+func (s *SlogProgress) OnProgress(processed, succeeded, failed int) {
+	s.logger.Info("deletion progress", "processed", processed, "succeeded", succeeded, "failed", failed)
+}
+
+func (s *SlogProgress) OnComplete(succeeded, failed int) {
+	s.logger.Info("deletion completed", "succeeded", succeeded, "failed", failed)
+}
+
+func (s *SlogProgress) OnRetry(id string, attempt int, err error) {
+	s.logger.Warn("deletion retry", "gmail_id", id, "attempt", attempt, "error", err)
+}
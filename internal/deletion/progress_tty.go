@@ -0,0 +1,79 @@
+package deletion
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// TTYProgress renders a single-line progress bar, overwriting itself with a
+// carriage return on each update - the built-in Progress sink for
+// interactive CLI use (e.g. `msgvault delete run`), as opposed to
+// SlogProgress (log streams) or OTelProgress (dashboards).
+type TTYProgress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	width int
+	total int
+}
+
+// NewTTYProgress builds a TTYProgress writing to os.Stdout with a
+// default 40-character bar.
+func NewTTYProgress() *TTYProgress {
+	return &TTYProgress{out: os.Stdout, width: 40}
+}
+
+// WithOutput redirects the bar to out instead of os.Stdout, e.g. for tests.
+func (t *TTYProgress) WithOutput(out io.Writer) *TTYProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out = out
+	return t
+}
+
+func (t *TTYProgress) OnStart(total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = total
+	t.render(0, 0, 0)
+}
+
+func (t *TTYProgress) OnProgress(processed, succeeded, failed int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.render(processed, succeeded, failed)
+}
+
+func (t *TTYProgress) OnComplete(succeeded, failed int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.render(succeeded+failed, succeeded, failed)
+	fmt.Fprintln(t.out)
+}
+
+func (t *TTYProgress) OnRetry(id string, attempt int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.out, "\nretrying %s (attempt %d): %v\n", id, attempt, err)
+}
+
+// render draws the bar; callers must hold t.mu.
+func (t *TTYProgress) render(processed, succeeded, failed int) {
+	filled := t.width
+	if t.total > 0 {
+		filled = processed * t.width / t.total
+		if filled > t.width {
+			filled = t.width
+		}
+	}
+	bar := ""
+	for i := 0; i < t.width; i++ {
+		if i < filled {
+			bar += "="
+		} else {
+			bar += " "
+		}
+	}
+	fmt.Fprintf(t.out, "\r[%s] %d/%d (ok=%d fail=%d)", bar, processed, t.total, succeeded, failed)
+}
@@ -0,0 +1,179 @@
+package deletion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MessageAction is what Execute/ExecuteBatch did (or planned to do) with one
+// message, recorded in a DeletionReport's per-message records.
+type MessageAction string
+
+const (
+	ActionTrash  MessageAction = "trash"
+	ActionDelete MessageAction = "delete"
+	ActionSkip   MessageAction = "skip"
+)
+
+// MessageFinalStatus is the outcome of acting on one message.
+type MessageFinalStatus string
+
+const (
+	StatusSuccessResult  MessageFinalStatus = "success"
+	StatusNotFoundResult MessageFinalStatus = "not-found"
+	StatusFailedResult   MessageFinalStatus = "failed"
+)
+
+// MessageReport is one message's entry in a DeletionReport, modeled on an
+// SMTP delivery status notification's per-recipient record.
+type MessageReport struct {
+	MessageID    string
+	Action       MessageAction
+	FinalStatus  MessageFinalStatus
+	AttemptCount int
+	LastError    string
+	Timestamp    time.Time
+}
+
+// ReportTotals summarizes a DeletionReport's per-message records.
+type ReportTotals struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// DeletionReport is the machine-readable record Execute/ExecuteBatch save
+// after completing a manifest, replacing the pattern of relying on
+// trackingProgress-style counters after the fact: auditable evidence of
+// what was destroyed, including 404-treated-as-success cases, that
+// survives past the process that produced it.
+type DeletionReport struct {
+	ManifestID string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Method     Method
+	Filters    Filters
+
+	Totals  ReportTotals
+	Records []MessageReport
+}
+
+func reportJSONFileName(manifestID string) string {
+	return manifestID + ".report.json"
+}
+
+func reportTextFileName(manifestID string) string {
+	return manifestID + ".report.txt"
+}
+
+// buildReport assembles a DeletionReport from manifest's final state and
+// the per-message records collected while running it.
+func (e *Executor) buildReport(manifest *Manifest, started, finished time.Time, records []MessageReport) *DeletionReport {
+	report := &DeletionReport{
+		ManifestID: manifest.ID,
+		StartedAt:  started,
+		FinishedAt: finished,
+		Method:     manifest.Method,
+		Filters:    manifest.Filters,
+		Records:    records,
+	}
+	for _, rec := range records {
+		report.Totals.Total++
+		switch rec.FinalStatus {
+		case StatusSuccessResult, StatusNotFoundResult:
+			report.Totals.Succeeded++
+		case StatusFailedResult:
+			report.Totals.Failed++
+		default:
+			report.Totals.Skipped++
+		}
+	}
+	return report
+}
+
+// buildMessageReport turns one message's final deleteOne outcome into a
+// MessageReport record, treating isNotFound the same way Execute's own
+// Succeeded/Failed counters do: already gone counts as success.
+func (e *Executor) buildMessageReport(gmailID string, method Method, attempts int, err error) MessageReport {
+	rec := MessageReport{
+		MessageID:    gmailID,
+		AttemptCount: attempts,
+		Timestamp:    timeNow(),
+	}
+	if method == MethodDelete {
+		rec.Action = ActionDelete
+	} else {
+		rec.Action = ActionTrash
+	}
+
+	switch {
+	case err == nil:
+		rec.FinalStatus = StatusSuccessResult
+	case e.isNotFound(err):
+		rec.FinalStatus = StatusNotFoundResult
+	default:
+		rec.FinalStatus = StatusFailedResult
+		rec.LastError = err.Error()
+	}
+	return rec
+}
+
+// saveReport persists report as both JSON and human-readable text under the
+// manager's root, alongside the manifest itself.
+func (e *Executor) saveReport(report *DeletionReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	jsonPath := filepath.Join(e.mgr.Root(), reportJSONFileName(report.ManifestID))
+	if err := os.WriteFile(jsonPath, data, 0o644); err != nil {
+		return fmt.Errorf("write report %s: %w", jsonPath, err)
+	}
+
+	textPath := filepath.Join(e.mgr.Root(), reportTextFileName(report.ManifestID))
+	if err := os.WriteFile(textPath, []byte(RenderReportText(report)), 0o644); err != nil {
+		return fmt.Errorf("write report %s: %w", textPath, err)
+	}
+	return nil
+}
+
+// LoadReport reads back the DeletionReport previously saved for manifestID.
+func (m *Manager) LoadReport(manifestID string) (*DeletionReport, error) {
+	path := filepath.Join(m.Root(), reportJSONFileName(manifestID))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report %s: %w", path, err)
+	}
+	var report DeletionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// RenderReportText renders report as an SMTP-DSN-style human-readable
+// summary, used for both the saved .report.txt file and `msgvault report`.
+func RenderReportText(report *DeletionReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deletion Report for %s\n", report.ManifestID)
+	fmt.Fprintf(&b, "Started:  %s\n", report.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Finished: %s\n", report.FinishedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Method:   %s\n", report.Method)
+	fmt.Fprintf(&b, "\nTotals: %d total, %d succeeded, %d failed, %d skipped\n",
+		report.Totals.Total, report.Totals.Succeeded, report.Totals.Failed, report.Totals.Skipped)
+
+	b.WriteString("\nPer-message results:\n")
+	for _, rec := range report.Records {
+		fmt.Fprintf(&b, "  %-20s %-7s %-10s attempts=%d", rec.MessageID, rec.Action, rec.FinalStatus, rec.AttemptCount)
+		if rec.LastError != "" {
+			fmt.Fprintf(&b, " error=%q", rec.LastError)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
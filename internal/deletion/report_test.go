@@ -0,0 +1,65 @@
+package deletion
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/testutil"
+)
+
+func TestExecutor_SaveAndLoadReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr, err := NewManager(tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	store := testutil.NewTestStore(t)
+	exec := NewExecutor(mgr, store, newMockAPI())
+
+	manifest := &Manifest{ID: "manifest1", Method: MethodTrash}
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	finished := started.Add(5 * time.Second)
+
+	records := []MessageReport{
+		exec.buildMessageReport("msg1", MethodTrash, 1, nil),
+		exec.buildMessageReport("msg2", MethodTrash, 3, &gmail.NotFoundError{Path: "msg2"}),
+		exec.buildMessageReport("msg3", MethodTrash, 3, errPermanentForTest{}),
+	}
+
+	report := exec.buildReport(manifest, started, finished, records)
+	if report.Totals.Total != 3 {
+		t.Errorf("Totals.Total = %d, want 3", report.Totals.Total)
+	}
+	if report.Totals.Succeeded != 2 {
+		t.Errorf("Totals.Succeeded = %d, want 2 (success + not-found)", report.Totals.Succeeded)
+	}
+	if report.Totals.Failed != 1 {
+		t.Errorf("Totals.Failed = %d, want 1", report.Totals.Failed)
+	}
+
+	if err := exec.saveReport(report); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+
+	loaded, err := mgr.LoadReport("manifest1")
+	if err != nil {
+		t.Fatalf("LoadReport: %v", err)
+	}
+	if loaded.ManifestID != "manifest1" || loaded.Totals.Total != 3 {
+		t.Errorf("LoadReport() = %+v, want matching the saved report", loaded)
+	}
+
+	text := RenderReportText(loaded)
+	if !strings.Contains(text, "msg1") || !strings.Contains(text, "msg3") {
+		t.Errorf("RenderReportText() missing expected message IDs: %s", text)
+	}
+	if !strings.Contains(text, "not-found") || !strings.Contains(text, "failed") {
+		t.Errorf("RenderReportText() missing expected statuses: %s", text)
+	}
+}
+
+type errPermanentForTest struct{}
+
+func (errPermanentForTest) Error() string { return "permanent failure" }
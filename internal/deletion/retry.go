@@ -0,0 +1,118 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// RetryPolicy governs how Executor retries a single message operation
+// (TrashMessage/DeleteMessage) that fails with a retryable error.
+// ExecuteBatch's BatchDeleteMessages call is not retried; a failed chunk
+// falls back to per-message DeleteMessage calls, which are. The retryable
+// errors are the transient failures gmail.DeletionMockAPI's
+// SetTransientFailure simulates for tests, and the 429/5xx responses Gmail
+// returns for real under load.
+type RetryPolicy struct {
+	MaxAttempts         int
+	InitialBackoff      time.Duration
+	MaxBackoff          time.Duration
+	Multiplier          float64
+	JitterFraction      float64
+	RetryableClassifier func(error) bool
+}
+
+// DefaultRetryPolicy retries up to 3 attempts total, starting at a 500ms
+// backoff and doubling up to 30s, with +/-20% jitter, using
+// DefaultRetryableClassifier to decide what's worth retrying.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:         3,
+		InitialBackoff:      500 * time.Millisecond,
+		MaxBackoff:          30 * time.Second,
+		Multiplier:          2,
+		JitterFraction:      0.2,
+		RetryableClassifier: DefaultRetryableClassifier,
+	}
+}
+
+// DefaultRetryableClassifier retries a context.DeadlineExceeded from the
+// transport and a gmail.RateLimitError carrying a 429 or 5xx status - the
+// conditions Gmail expects a well-behaved client to back off and retry. A
+// gmail.NotFoundError (404), a RateLimitError carrying a plain 403
+// (permission denied, not a quota reason), and any other error are treated
+// as non-retryable.
+func DefaultRetryableClassifier(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var rle *gmail.RateLimitError
+	if errors.As(err, &rle) {
+		return rle.StatusCode == http.StatusTooManyRequests || rle.StatusCode >= 500
+	}
+	return false
+}
+
+// WithRetryPolicy installs policy so withRetry applies it around each
+// message-level deletion call Execute/ExecuteBatch make.
+func (e *Executor) WithRetryPolicy(policy RetryPolicy) *Executor {
+	e.retryPolicy = &policy
+	return e
+}
+
+// withRetry calls op - one TrashMessage/DeleteMessage attempt for
+// gmailID - and, with a RetryPolicy installed, retries it on a
+// retryable error with exponential backoff and jitter, firing
+// Progress.OnRetry before every retry. With no policy installed, op runs
+// exactly once, matching Executor's behavior before RetryPolicy existed.
+func (e *Executor) withRetry(ctx context.Context, gmailID string, op func() error) error {
+	policy := e.retryPolicy
+	if policy == nil {
+		return op()
+	}
+
+	classify := policy.RetryableClassifier
+	if classify == nil {
+		classify = DefaultRetryableClassifier
+	}
+
+	var err error
+	backoff := policy.InitialBackoff
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err = op()
+		if err == nil || !classify(err) || attempt == policy.MaxAttempts {
+			return err
+		}
+
+		e.progress.OnRetry(gmailID, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(withJitter(backoff, policy.JitterFraction)):
+		}
+
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+	return err
+}
+
+// withJitter returns d adjusted by a random amount within +/-fraction of d.
+func withJitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	delta := float64(d) * fraction
+	offset := (rand.Float64()*2 - 1) * delta
+	return time.Duration(float64(d) + offset)
+}
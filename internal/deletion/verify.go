@@ -0,0 +1,91 @@
+package deletion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// ErrDigestMismatch is returned (wrapped with the message's Gmail ID) when a
+// message's re-fetched content digest doesn't match the digest recorded in
+// the local store at archive time. Executor.Execute diverts any message
+// that fails this check into Execution.FailedIDs instead of sending it to
+// DeleteMessage, since an archive that doesn't match the server is no
+// longer safe proof that deleting the server copy is recoverable.
+var ErrDigestMismatch = errors.New("deletion: content digest mismatch")
+
+// Verifier recomputes and checks a message's content digest against the
+// digest recorded for it at archive time. Executor.WithVerifier installs
+// one so Execute can run this pre-flight check before MethodDelete, which
+// (unlike MethodTrash) is irreversible.
+type Verifier interface {
+	// Verify returns nil if gmailID's current server content matches the
+	// digest stored locally for it, and ErrDigestMismatch (or a wrapping of
+	// it) otherwise.
+	Verify(ctx context.Context, gmailID string) error
+}
+
+// DigestLookup is the subset of the local store digestVerifier needs: the
+// SHA-256 digest recorded for a message at archive time, keyed by Gmail ID.
+type DigestLookup interface {
+	MessageDigest(gmailID string) (string, error)
+}
+
+// digestVerifier is the default Verifier: it re-fetches a message's raw
+// RFC822 content from the Gmail API, hashes it with SHA-256, and compares
+// that against the digest recorded when the message was archived.
+type digestVerifier struct {
+	api   gmail.API
+	store DigestLookup
+}
+
+// NewDigestVerifier builds the default Verifier, hashing api's re-fetched
+// raw content and comparing it against digests recorded in store.
+func NewDigestVerifier(api gmail.API, store DigestLookup) Verifier {
+	return &digestVerifier{api: api, store: store}
+}
+
+// Verify re-fetches gmailID's raw content, hashes it, and compares the
+// result against the digest recorded for it at archive time.
+func (v *digestVerifier) Verify(ctx context.Context, gmailID string) error {
+	wantDigest, err := v.store.MessageDigest(gmailID)
+	if err != nil {
+		return fmt.Errorf("verify %s: look up stored digest: %w", gmailID, err)
+	}
+
+	raw, err := v.api.GetMessageRaw(ctx, gmailID)
+	if err != nil {
+		return fmt.Errorf("verify %s: fetch current content: %w", gmailID, err)
+	}
+
+	sum := sha256.Sum256(raw.Raw)
+	gotDigest := hex.EncodeToString(sum[:])
+	if gotDigest != wantDigest {
+		return fmt.Errorf("%w: %s: stored %s, server has %s", ErrDigestMismatch, gmailID, wantDigest, gotDigest)
+	}
+	return nil
+}
+
+// WithVerifier installs v so Execute runs a pre-flight digest check on each
+// message before MethodDelete (never MethodTrash, which is recoverable for
+// 30 days without this check). A message that fails verification is
+// recorded in Execution.FailedIDs and never reaches DeleteMessage.
+func (e *Executor) WithVerifier(v Verifier) *Executor {
+	e.verifier = v
+	return e
+}
+
+// verifyBeforeDelete runs e.verifier (if one was installed via WithVerifier)
+// against gmailID when method is MethodDelete. It is a no-op - success -
+// for MethodTrash or when no verifier was configured, so Execute's behavior
+// is unchanged until a caller installs one.
+func (e *Executor) verifyBeforeDelete(ctx context.Context, gmailID string, method Method) error {
+	if e.verifier == nil || method != MethodDelete {
+		return nil
+	}
+	return e.verifier.Verify(ctx, gmailID)
+}
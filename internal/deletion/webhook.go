@@ -0,0 +1,62 @@
+package deletion
+
+import (
+	"github.com/wesm/msgvault/internal/webhook"
+)
+
+// WebhookConfig configures the webhook notifications Executor emits
+// alongside its Progress callbacks. Dispatcher does the actual delivery
+// (signing, retry backoff, delivery log) independent of Execute's loop, so
+// a slow or failing receiver cannot stall deletion.
+type WebhookConfig struct {
+	Dispatcher *webhook.Dispatcher
+}
+
+// WithWebhook installs cfg so Execute notifies cfg.Dispatcher at each
+// lifecycle event: manifest.started and manifest.completed around the run,
+// message.trashed/message.deleted/message.failed per message. ExecuteBatch
+// emits the manifest events, and per-message events only for a chunk that
+// falls back to DeleteMessage; no batch.completed event is sent.
+func (e *Executor) WithWebhook(cfg WebhookConfig) *Executor {
+	e.webhook = cfg.Dispatcher
+	return e
+}
+
+// notifyManifestStarted fires webhook.EventManifestStarted, if a Dispatcher
+// is installed.
+func (e *Executor) notifyManifestStarted(manifestID string, totalCount int) {
+	if e.webhook == nil {
+		return
+	}
+	_ = e.webhook.Notify(webhook.EventManifestStarted, manifestID, map[string]int{"total": totalCount})
+}
+
+// notifyManifestCompleted fires webhook.EventManifestCompleted, if a
+// Dispatcher is installed.
+func (e *Executor) notifyManifestCompleted(manifestID string, succeeded, failed int) {
+	if e.webhook == nil {
+		return
+	}
+	_ = e.webhook.Notify(webhook.EventManifestCompleted, manifestID, map[string]int{"succeeded": succeeded, "failed": failed})
+}
+
+// notifyMessageResult fires message.trashed, message.deleted, or
+// message.failed depending on method and whether opErr is nil, if a
+// Dispatcher is installed.
+func (e *Executor) notifyMessageResult(manifestID, gmailID string, method Method, opErr error) {
+	if e.webhook == nil {
+		return
+	}
+	event := webhook.EventMessageTrashed
+	switch {
+	case opErr != nil && !e.isNotFound(opErr):
+		event = webhook.EventMessageFailed
+	case method == MethodDelete:
+		event = webhook.EventMessageDeleted
+	}
+	data := map[string]string{"gmail_id": gmailID}
+	if opErr != nil {
+		data["error"] = opErr.Error()
+	}
+	_ = e.webhook.Notify(event, manifestID, data)
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// emlExporter writes one file per message, named by its source message
+// ID, containing the raw MIME bytes verbatim.
+type emlExporter struct {
+	dir string
+}
+
+func newEMLExporter(dir string) (*emlExporter, error) {
+	return &emlExporter{dir: dir}, nil
+}
+
+func (e *emlExporter) Begin() error {
+	return os.MkdirAll(e.dir, 0o755)
+}
+
+func (e *emlExporter) WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error {
+	path := filepath.Join(e.dir, safeFilename(m.SourceMessageID)+".eml")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("export: create %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, raw); err != nil {
+		return fmt.Errorf("export: write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (e *emlExporter) Close() error {
+	return nil
+}
+
+// safeFilename strips characters that are awkward or invalid in file
+// names across common filesystems.
+func safeFilename(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			out = append(out, '_')
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,44 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+func TestEMLExporter_WritesOneFilePerMessage(t *testing.T) {
+	dir := t.TempDir()
+	exp, err := NewExporter(FormatEML, dir)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	if err := exp.Begin(); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+
+	msg := &store.ExportMessage{SourceMessageID: "abc123"}
+	raw := strings.NewReader("From: a@example.com\r\n\r\nhello")
+	if err := exp.WriteMessage(msg, raw, nil); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if err := exp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "abc123.eml"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "hello") {
+		t.Errorf("eml file missing body: %q", data)
+	}
+}
+
+func TestSafeFilename(t *testing.T) {
+	if got := safeFilename("a/b:c*d"); got != "a_b_c_d" {
+		t.Errorf("safeFilename() = %q, want %q", got, "a_b_c_d")
+	}
+}
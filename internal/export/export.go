@@ -0,0 +1,91 @@
+// Package export writes messages out of the vault in various on-disk
+// formats (mbox, Maildir, EML, JSONL, HTML), for compliance, migration,
+// or grep-ability outside SQLite.
+package export
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// Format names a supported export format, used by Options.Format and CLI
+// flags.
+type Format string
+
+const (
+	FormatMbox    Format = "mbox"
+	FormatMaildir Format = "maildir"
+	FormatEML     Format = "eml"
+	FormatJSONL   Format = "jsonl"
+	FormatHTML    Format = "html"
+)
+
+// Exporter writes a stream of messages to a destination in one particular
+// format. Callers call Begin once, WriteMessage once per message (in any
+// order the store yields them), and Close exactly once to flush and
+// release resources.
+type Exporter interface {
+	// Begin prepares the destination (creating directories, writing any
+	// header the format needs) before the first WriteMessage call.
+	Begin() error
+
+	// WriteMessage streams one message's raw MIME (via raw) plus its
+	// attachment metadata into the export. The mbox, Maildir and EML
+	// exporters copy raw through without holding it whole; the JSONL and
+	// HTML exporters read one message fully into memory to parse it.
+	WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error
+
+	// Close flushes and releases any resources Begin acquired.
+	Close() error
+}
+
+// NewExporter constructs the Exporter for format, writing to dest (a
+// directory for Maildir/HTML, a single file path for mbox/EML/JSONL — EML
+// writes one file per message into dest as a directory).
+func NewExporter(format Format, dest string) (Exporter, error) {
+	switch format {
+	case FormatMbox:
+		return newMboxExporter(dest)
+	case FormatMaildir:
+		return newMaildirExporter(dest)
+	case FormatEML:
+		return newEMLExporter(dest)
+	case FormatJSONL:
+		return newJSONLExporter(dest)
+	case FormatHTML:
+		return newHTMLExporter(dest)
+	default:
+		return nil, &UnsupportedFormatError{Format: format}
+	}
+}
+
+// UnsupportedFormatError is returned by NewExporter for an unrecognized
+// Format.
+type UnsupportedFormatError struct {
+	Format Format
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	return "export: unsupported format " + string(e.Format)
+}
+
+// bufferedWriteCloser wraps an *os.File (or similar) with buffering,
+// flushing on Close. Used by writers that stream to a single file.
+type bufferedWriteCloser struct {
+	f  io.Closer
+	bw *bufio.Writer
+}
+
+func (b *bufferedWriteCloser) Write(p []byte) (int, error) {
+	return b.bw.Write(p)
+}
+
+func (b *bufferedWriteCloser) Close() error {
+	if err := b.bw.Flush(); err != nil {
+		_ = b.f.Close()
+		return err
+	}
+	return b.f.Close()
+}
@@ -0,0 +1,50 @@
+package export
+
+import "time"
+
+// Filter selects which messages ToMbox/ToMboxFile (and future
+// format-specific entry points) include.
+type Filter struct {
+	// Label, if set, restricts to messages carrying this label.
+	Label string
+	// Since and Until bound the export by message date, inclusive; zero
+	// values mean unbounded.
+	Since, Until time.Time
+	// ThreadID, if set, restricts to a single thread.
+	ThreadID string
+	// MessageIDs, if non-empty, restricts to this exact set of
+	// source_message_ids, taking precedence over the other fields.
+	MessageIDs map[string]bool
+}
+
+// matches reports whether a message's metadata satisfies the filter.
+// Callers still need the store to do label/date/thread selection
+// server-side for efficiency; this is the final in-process check,
+// primarily exercised by tests and MessageIDs filtering.
+func (f Filter) matches(labels []string, threadID, sourceMessageID string, date time.Time) bool {
+	if len(f.MessageIDs) > 0 {
+		return f.MessageIDs[sourceMessageID]
+	}
+	if f.Label != "" {
+		found := false
+		for _, l := range labels {
+			if l == f.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ThreadID != "" && f.ThreadID != threadID {
+		return false
+	}
+	if !f.Since.IsZero() && date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && date.After(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter_MatchesByLabel(t *testing.T) {
+	f := Filter{Label: "IMPORTANT"}
+	if !f.matches([]string{"INBOX", "IMPORTANT"}, "t1", "m1", time.Time{}) {
+		t.Error("expected match on label")
+	}
+	if f.matches([]string{"INBOX"}, "t1", "m1", time.Time{}) {
+		t.Error("expected no match without label")
+	}
+}
+
+func TestFilter_MatchesByMessageIDs(t *testing.T) {
+	f := Filter{MessageIDs: map[string]bool{"m2": true}}
+	if f.matches(nil, "t1", "m1", time.Time{}) {
+		t.Error("m1 should not match")
+	}
+	if !f.matches(nil, "t1", "m2", time.Time{}) {
+		t.Error("m2 should match")
+	}
+}
+
+func TestFilter_MatchesByDateRange(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	f := Filter{Since: since, Until: until}
+
+	in := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	out := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	if !f.matches(nil, "", "m1", in) {
+		t.Error("expected date within range to match")
+	}
+	if f.matches(nil, "", "m1", out) {
+		t.Error("expected date outside range not to match")
+	}
+}
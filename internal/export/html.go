@@ -0,0 +1,90 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/wesm/msgvault/internal/mime"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// htmlExporter writes one HTML file per thread, appending each message it
+// sees for that thread in arrival order. This is meant for legal review:
+// readable, self-contained, no external assets.
+type htmlExporter struct {
+	dir   string
+	files map[string]*os.File
+}
+
+func newHTMLExporter(dir string) (*htmlExporter, error) {
+	return &htmlExporter{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+func (e *htmlExporter) Begin() error {
+	return os.MkdirAll(e.dir, 0o755)
+}
+
+func (e *htmlExporter) WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error {
+	f, err := e.threadFile(m.ThreadID)
+	if err != nil {
+		return err
+	}
+
+	rawBytes, err := io.ReadAll(raw)
+	if err != nil {
+		return fmt.Errorf("export: read message %s: %w", m.SourceMessageID, err)
+	}
+	body := ""
+	if parsed, err := mime.Parse(rawBytes); err == nil {
+		body = parsed.GetBodyText()
+	}
+
+	_, err = fmt.Fprintf(f, `<article class="message">
+  <h2>%s</h2>
+  <div class="meta">From: %s &mdash; %s</div>
+  <pre class="body">%s</pre>
+</article>
+`, html.EscapeString(m.Subject), html.EscapeString(m.From), html.EscapeString(m.Date.String()), html.EscapeString(body))
+	return err
+}
+
+func (e *htmlExporter) threadFile(threadID string) (*os.File, error) {
+	if f, ok := e.files[threadID]; ok {
+		return f, nil
+	}
+	path := filepath.Join(e.dir, safeFilename(threadID)+".html")
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("export: create %s: %w", path, err)
+	}
+	if _, err := f.WriteString(htmlPreamble); err != nil {
+		return nil, err
+	}
+	e.files[threadID] = f
+	return f, nil
+}
+
+const htmlPreamble = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; }
+.message { border-top: 1px solid #ccc; padding: 1em 0; }
+.meta { color: #666; font-size: 0.9em; }
+.body { white-space: pre-wrap; font-family: inherit; }
+</style></head><body>
+`
+
+func (e *htmlExporter) Close() error {
+	var firstErr error
+	for _, f := range e.files {
+		if _, err := f.WriteString("</body></html>\n"); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wesm/msgvault/internal/mime"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// jsonlExporter writes one JSON object per line: parsed headers and body
+// text, plus attachment metadata. Unlike EML, this is lossy (MIME
+// structure is flattened) but convenient for downstream tooling like jq.
+type jsonlExporter struct {
+	path string
+	f    *os.File
+	bw   *bufio.Writer
+}
+
+func newJSONLExporter(path string) (*jsonlExporter, error) {
+	return &jsonlExporter{path: path}, nil
+}
+
+func (e *jsonlExporter) Begin() error {
+	f, err := os.Create(e.path)
+	if err != nil {
+		return fmt.Errorf("export: create %s: %w", e.path, err)
+	}
+	e.f = f
+	e.bw = bufio.NewWriter(f)
+	return nil
+}
+
+// jsonlRecord is the shape written for each message.
+type jsonlRecord struct {
+	SourceMessageID string   `json:"source_message_id"`
+	ThreadID        string   `json:"thread_id"`
+	From            string   `json:"from"`
+	To              []string `json:"to"`
+	Cc              []string `json:"cc,omitempty"`
+	Subject         string   `json:"subject"`
+	Date            string   `json:"date"`
+	Labels          []string `json:"labels,omitempty"`
+	BodyText        string   `json:"body_text"`
+	Attachments     []string `json:"attachments,omitempty"`
+}
+
+func (e *jsonlExporter) WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error {
+	rawBytes, err := io.ReadAll(raw)
+	if err != nil {
+		return fmt.Errorf("export: read message %s: %w", m.SourceMessageID, err)
+	}
+
+	bodyText := ""
+	if parsed, err := mime.Parse(rawBytes); err == nil {
+		bodyText = parsed.GetBodyText()
+	}
+
+	names := make([]string, len(atts))
+	for i, a := range atts {
+		names[i] = a.Filename
+	}
+
+	rec := jsonlRecord{
+		SourceMessageID: m.SourceMessageID,
+		ThreadID:        m.ThreadID,
+		From:            m.From,
+		To:              m.To,
+		Cc:              m.Cc,
+		Subject:         m.Subject,
+		Date:            m.Date.Format("2006-01-02T15:04:05Z07:00"),
+		Labels:          m.Labels,
+		BodyText:        bodyText,
+		Attachments:     names,
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("export: marshal %s: %w", m.SourceMessageID, err)
+	}
+	if _, err := e.bw.Write(data); err != nil {
+		return err
+	}
+	_, err = e.bw.WriteString("\n")
+	return err
+}
+
+func (e *jsonlExporter) Close() error {
+	if err := e.bw.Flush(); err != nil {
+		_ = e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// maildirExporter writes one file per message under cur/, using the
+// canonical Maildir filename "<timestamp>.<unique>.<host>:2,<flags>".
+// Gmail's STARRED and UNREAD labels map to the Maildir F and (absence of)
+// S flags respectively; TRASH maps to T. Every message is written once into
+// the top-level Maildir and hardlinked into a "." subfolder per non-system
+// label it carries, falling back to a copy where the filesystem doesn't
+// support hardlinks.
+type maildirExporter struct {
+	dir  string
+	host string
+	seq  int
+}
+
+func newMaildirExporter(dir string) (*maildirExporter, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return &maildirExporter{dir: dir, host: host}, nil
+}
+
+func (e *maildirExporter) Begin() error {
+	return ensureMaildir(e.dir)
+}
+
+func (e *maildirExporter) WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error {
+	e.seq++
+	flags := maildirFlags(m.Labels)
+	name := fmt.Sprintf("%d.%d.%s:2,%s", m.Date.Unix(), e.seq, e.host, flags)
+
+	primary := filepath.Join(e.dir, "cur", name)
+	f, err := os.Create(primary)
+	if err != nil {
+		return fmt.Errorf("export: create %s: %w", primary, err)
+	}
+	if _, err := io.Copy(f, raw); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("export: write %s: %w", primary, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("export: close %s: %w", primary, err)
+	}
+
+	for _, folder := range maildirLabelFolders(m.Labels) {
+		labelDir := filepath.Join(e.dir, folder)
+		if err := ensureMaildir(labelDir); err != nil {
+			return err
+		}
+		dest := filepath.Join(labelDir, "cur", name)
+		if err := linkOrCopy(primary, dest); err != nil {
+			return fmt.Errorf("export: link %s into %s: %w", name, folder, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *maildirExporter) Close() error {
+	return nil
+}
+
+// ensureMaildir creates dir's cur/, new/, and tmp/ subdirectories.
+func ensureMaildir(dir string) error {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return fmt.Errorf("export: mkdir %s/%s: %w", dir, sub, err)
+		}
+	}
+	return nil
+}
+
+// linkOrCopy hardlinks dest to src, falling back to a byte-for-byte copy on
+// filesystems (or cross-device destinations) that don't support hardlinks.
+func linkOrCopy(src, dest string) error {
+	if err := os.Link(src, dest); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// maildirSystemLabels are Gmail labels represented as Maildir flags or the
+// top-level mailbox rather than as their own subfolder.
+var maildirSystemLabels = map[string]bool{
+	"INBOX": true, "SENT": true, "DRAFT": true, "SPAM": true,
+	"TRASH": true, "UNREAD": true, "STARRED": true, "IMPORTANT": true, "CHAT": true,
+}
+
+func isSystemLabel(label string) bool {
+	return maildirSystemLabels[label] || strings.HasPrefix(label, "CATEGORY_")
+}
+
+// maildirLabelFolders returns the "." subfolder name (Maildir++ convention,
+// "/" nesting flattened to ".") for every non-system label in labels.
+func maildirLabelFolders(labels []string) []string {
+	var folders []string
+	for _, l := range labels {
+		if isSystemLabel(l) {
+			continue
+		}
+		folders = append(folders, "."+strings.ReplaceAll(l, "/", "."))
+	}
+	return folders
+}
+
+// maildirFlags maps Gmail labels to Maildir info flags, in the
+// alphabetical order Maildir requires ("D" draft, "F" flagged, "R"
+// replied, "S" seen, "T" trashed).
+func maildirFlags(labels []string) string {
+	flagged, seen, trashed := false, true, false
+	for _, l := range labels {
+		switch l {
+		case "STARRED":
+			flagged = true
+		case "UNREAD":
+			seen = false
+		case "TRASH":
+			trashed = true
+		}
+	}
+	var b strings.Builder
+	if flagged {
+		b.WriteByte('F')
+	}
+	if seen {
+		b.WriteByte('S')
+	}
+	if trashed {
+		b.WriteByte('T')
+	}
+	return b.String()
+}
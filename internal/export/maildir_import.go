@@ -0,0 +1,119 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// ImportMaildir ingests a Maildir directory tree (its own cur/new plus any
+// "."-prefixed label subfolders, Maildir++ style) into st as a new,
+// non-Gmail source identified by sourceEmail, for users migrating data in
+// from another mail client. Messages that appear under more than one
+// subfolder (e.g. hardlinked by a prior ExportMaildir) are merged into a
+// single message carrying the union of labels, keyed by Maildir filename.
+func ImportMaildir(ctx context.Context, st *store.Store, dir, sourceEmail string) (int, error) {
+	source, err := st.GetOrCreateSource("maildir", sourceEmail)
+	if err != nil {
+		return 0, fmt.Errorf("import maildir: get or create source: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("import maildir: read %s: %w", dir, err)
+	}
+
+	type found struct {
+		raw    []byte
+		labels map[string]bool
+	}
+	messages := make(map[string]*found)
+
+	addFolder := func(folderDir, label string) error {
+		for _, sub := range []string{"cur", "new"} {
+			subDir := filepath.Join(folderDir, sub)
+			files, err := os.ReadDir(subDir)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("read %s: %w", subDir, err)
+			}
+			for _, f := range files {
+				if f.IsDir() {
+					continue
+				}
+				name := f.Name()
+				m, ok := messages[name]
+				if !ok {
+					raw, err := os.ReadFile(filepath.Join(subDir, name))
+					if err != nil {
+						return fmt.Errorf("read %s: %w", name, err)
+					}
+					m = &found{raw: raw, labels: map[string]bool{}}
+					messages[name] = m
+				}
+				for _, l := range maildirNameToLabels(name, sub) {
+					m.labels[l] = true
+				}
+				if label != "" {
+					m.labels[label] = true
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := addFolder(dir, ""); err != nil {
+		return 0, fmt.Errorf("import maildir: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		label := strings.ReplaceAll(strings.TrimPrefix(e.Name(), "."), ".", "/")
+		if err := addFolder(filepath.Join(dir, e.Name()), label); err != nil {
+			return 0, fmt.Errorf("import maildir: folder %s: %w", e.Name(), err)
+		}
+	}
+
+	imported := 0
+	for name, m := range messages {
+		labels := make([]string, 0, len(m.labels))
+		for l := range m.labels {
+			labels = append(labels, l)
+		}
+		if err := st.InsertImportedMessage(source.ID, name, m.raw, labels); err != nil {
+			return imported, fmt.Errorf("import maildir: insert %s: %w", name, err)
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// maildirNameToLabels reverses maildirFlags: it recovers the Gmail-style
+// pseudo-labels implied by a Maildir filename's info flags and the new/cur
+// subfolder it was found in.
+func maildirNameToLabels(name, sub string) []string {
+	var labels []string
+	i := strings.Index(name, ":2,")
+	flags := ""
+	if i >= 0 {
+		flags = name[i+len(":2,"):]
+	}
+	if sub == "new" || !strings.Contains(flags, "S") {
+		labels = append(labels, "UNREAD")
+	}
+	if strings.Contains(flags, "F") {
+		labels = append(labels, "STARRED")
+	}
+	if strings.Contains(flags, "T") {
+		labels = append(labels, "TRASH")
+	}
+	return labels
+}
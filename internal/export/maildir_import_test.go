@@ -0,0 +1,28 @@
+package export
+
+import "testing"
+
+func TestMaildirNameToLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  string
+		want []string
+	}{
+		{"1700000000.1.host:2,S", "cur", nil},
+		{"1700000000.1.host:2,", "cur", []string{"UNREAD"}},
+		{"1700000000.1.host:2,FS", "cur", []string{"STARRED"}},
+		{"1700000000.1.host:2,FST", "cur", []string{"STARRED", "TRASH"}},
+		{"1700000000.1.host:2,S", "new", []string{"UNREAD"}},
+	}
+	for _, tt := range tests {
+		got := maildirNameToLabels(tt.name, tt.sub)
+		if len(got) != len(tt.want) {
+			t.Fatalf("maildirNameToLabels(%q, %q) = %v, want %v", tt.name, tt.sub, got, tt.want)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("maildirNameToLabels(%q, %q)[%d] = %q, want %q", tt.name, tt.sub, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+func TestMaildirFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{"default read", nil, "S"},
+		{"unread", []string{"UNREAD"}, ""},
+		{"starred", []string{"STARRED"}, "FS"},
+		{"trashed unread", []string{"TRASH", "UNREAD"}, "T"},
+		{"starred trashed", []string{"STARRED", "TRASH"}, "FST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maildirFlags(tt.labels); got != tt.want {
+				t.Errorf("maildirFlags(%v) = %q, want %q", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaildirLabelFolders(t *testing.T) {
+	got := maildirLabelFolders([]string{"INBOX", "UNREAD", "Work", "Family/Kids"})
+	want := []string{".Work", ".Family.Kids"}
+	if len(got) != len(want) {
+		t.Fatalf("maildirLabelFolders() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("maildirLabelFolders()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMaildirExporter_LabelFoldersAndHardlinkDedup(t *testing.T) {
+	dir := t.TempDir()
+	exp, err := NewExporter(FormatMaildir, dir)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	if err := exp.Begin(); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+
+	msg := &store.ExportMessage{
+		SourceMessageID: "abc123",
+		Date:            time.Unix(1700000000, 0),
+		Labels:          []string{"INBOX", "Work", "Receipts"},
+	}
+	raw := strings.NewReader("From: a@example.com\r\n\r\nhello")
+	if err := exp.WriteMessage(msg, raw, nil); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if err := exp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	topEntries, err := os.ReadDir(filepath.Join(dir, "cur"))
+	if err != nil {
+		t.Fatalf("ReadDir top-level cur: %v", err)
+	}
+	if len(topEntries) != 1 {
+		t.Fatalf("top-level cur has %d entries, want 1", len(topEntries))
+	}
+	name := topEntries[0].Name()
+
+	for _, folder := range []string{".Work", ".Receipts"} {
+		path := filepath.Join(dir, folder, "cur", name)
+		labelInfo, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat %s: %v", path, err)
+		}
+		topInfo, err := os.Stat(filepath.Join(dir, "cur", name))
+		if err != nil {
+			t.Fatalf("Stat top-level file: %v", err)
+		}
+		if !os.SameFile(topInfo, labelInfo) {
+			t.Errorf("%s is not hardlinked to the top-level copy", path)
+		}
+	}
+}
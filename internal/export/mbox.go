@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// mboxExporter writes messages to a single file in mboxrd format: each
+// message is preceded by a "From " envelope line, and any body line that
+// would otherwise be misread as a new envelope (starts with "From ", or
+// starts with one or more ">" followed by "From ") gets an extra ">"
+// prepended (mboxrd quoting, reversible on read).
+type mboxExporter struct {
+	path string
+	f    *os.File
+	bw   *bufio.Writer
+}
+
+func newMboxExporter(path string) (*mboxExporter, error) {
+	return &mboxExporter{path: path}, nil
+}
+
+func (e *mboxExporter) Begin() error {
+	f, err := os.Create(e.path)
+	if err != nil {
+		return fmt.Errorf("export: create %s: %w", e.path, err)
+	}
+	e.f = f
+	e.bw = bufio.NewWriter(f)
+	return nil
+}
+
+func (e *mboxExporter) WriteMessage(m *store.ExportMessage, raw io.Reader, atts []store.Attachment) error {
+	from := envelopeSender(m.From)
+	if _, err := fmt.Fprintf(e.bw, "From %s %s\n", from, m.Date.Format("Mon Jan _2 15:04:05 2006")); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(raw)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if mboxrdNeedsQuoting(line) {
+			if _, err := e.bw.WriteString(">"); err != nil {
+				return err
+			}
+		}
+		if _, err := e.bw.Write(line); err != nil {
+			return err
+		}
+		if _, err := e.bw.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("export: read message %s: %w", m.SourceMessageID, err)
+	}
+
+	_, err := e.bw.WriteString("\n")
+	return err
+}
+
+func (e *mboxExporter) Close() error {
+	if err := e.bw.Flush(); err != nil {
+		_ = e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// mboxrdNeedsQuoting reports whether line must be escaped with a leading
+// ">" so a naive mbox reader won't mistake it for the start of the next
+// message: any run of zero or more ">" followed by "From ".
+func mboxrdNeedsQuoting(line []byte) bool {
+	i := 0
+	for i < len(line) && line[i] == '>' {
+		i++
+	}
+	return bytes.HasPrefix(line[i:], []byte("From "))
+}
+
+// envelopeSender extracts a bare address suitable for the mbox "From "
+// line from a header value that may be "Name <addr>" or just "addr".
+func envelopeSender(from string) string {
+	if i := strings.LastIndexByte(from, '<'); i >= 0 {
+		addr := from[i+1:]
+		addr = strings.TrimSuffix(addr, ">")
+		return strings.TrimSpace(addr)
+	}
+	return strings.TrimSpace(from)
+}
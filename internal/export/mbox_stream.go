@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// ToMbox streams cursor's messages matching filter to w in mboxrd format,
+// each under a "From " envelope line synthesized from its stored metadata
+// and followed by its raw bytes, mboxrd-quoted. There is no per-message
+// fallback: if cursor.Message fails for a message (for example because its
+// raw bytes can't be read), ToMbox stops and returns that error.
+func ToMbox(ctx context.Context, w io.Writer, cursor store.ExportCursor, filter Filter) error {
+	bw := bufio.NewWriter(w)
+
+	for cursor.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		m, raw, _, err := cursor.Message()
+		if err != nil {
+			return fmt.Errorf("export: read message: %w", err)
+		}
+		if !filter.matches(m.Labels, m.ThreadID, m.SourceMessageID, m.Date) {
+			continue
+		}
+
+		if err := writeMboxEnvelope(bw, m); err != nil {
+			return err
+		}
+		if err := writeMboxBody(bw, raw); err != nil {
+			return fmt.Errorf("export: write message %s: %w", m.SourceMessageID, err)
+		}
+		if _, err := bw.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("export: cursor: %w", err)
+	}
+	return bw.Flush()
+}
+
+// ToMboxFile is ToMbox writing to a new file at path.
+func ToMboxFile(ctx context.Context, path string, cursor store.ExportCursor, filter Filter) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("export: create %s: %w", path, err)
+	}
+	defer f.Close()
+	return ToMbox(ctx, f, cursor, filter)
+}
+
+func writeMboxEnvelope(w io.Writer, m *store.ExportMessage) error {
+	from := envelopeSender(m.From)
+	_, err := fmt.Fprintf(w, "From %s %s\n", from, m.Date.Format("Mon Jan _2 15:04:05 2006"))
+	return err
+}
+
+func writeMboxBody(bw *bufio.Writer, raw io.Reader) error {
+	scanner := bufio.NewScanner(raw)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if mboxrdNeedsQuoting(line) {
+			if _, err := bw.WriteString(">"); err != nil {
+				return err
+			}
+		}
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+		if _, err := bw.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
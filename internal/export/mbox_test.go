@@ -0,0 +1,38 @@
+package export
+
+import "testing"
+
+func TestMboxrdNeedsQuoting(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"From bob@example.com", true},
+		{">From already quoted", true},
+		{">>From double quoted", true},
+		{"From: bob@example.com", false}, // no space after "From"
+		{"regular body line", false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		if got := mboxrdNeedsQuoting([]byte(tc.line)); got != tc.want {
+			t.Errorf("mboxrdNeedsQuoting(%q) = %v, want %v", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestEnvelopeSender(t *testing.T) {
+	tests := []struct {
+		from string
+		want string
+	}{
+		{"Alice Example <alice@example.com>", "alice@example.com"},
+		{"bob@example.com", "bob@example.com"},
+		{"  carol@example.com  ", "carol@example.com"},
+	}
+	for _, tc := range tests {
+		if got := envelopeSender(tc.from); got != tc.want {
+			t.Errorf("envelopeSender(%q) = %q, want %q", tc.from, got, tc.want)
+		}
+	}
+}
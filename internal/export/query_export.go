@@ -0,0 +1,195 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/wesm/msgvault/internal/query"
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// FormatParquet is an export Format available only through ExportQuery,
+// delegating to a DuckDB-backed query.Engine's own "COPY ... TO" rather
+// than a writer this package implements itself.
+const FormatParquet Format = "parquet"
+
+// queryExportPageSize is how many messages ExportQuery fetches from the
+// engine per internal page, mirroring grpcserver's streamPageSize so a
+// single export never materializes the whole result set in memory.
+const queryExportPageSize = 500
+
+// ParquetExporter is implemented by engines (DuckDBEngine) that can write a
+// query's matching messages straight to Parquet via DuckDB's own
+// "COPY ... TO", rather than row-by-row the way the other formats here do.
+type ParquetExporter interface {
+	ExportParquet(ctx context.Context, q *search.Query, w io.Writer) error
+}
+
+// ExportQuery streams every message matching q out of engine to w in
+// format. Unlike ToMbox/NewExporter, which read each message's raw MIME
+// straight from the vault via a store.ExportCursor, this works from
+// query.Engine search results, so mbox and JSONL output is synthesized
+// from message metadata and extracted text rather than the original raw
+// bytes. Use ToMbox/NewExporter instead when bit-exact MIME reproduction
+// matters. format must be FormatMbox, FormatJSONL, or FormatParquet.
+func ExportQuery(ctx context.Context, engine query.Engine, q *search.Query, w io.Writer, format Format) error {
+	switch format {
+	case FormatMbox:
+		return exportQueryBuffered(ctx, engine, q, w, writeMboxQueryMessage)
+	case FormatJSONL:
+		return exportQueryBuffered(ctx, engine, q, w, writeJSONLQueryMessage)
+	case FormatParquet:
+		exporter, ok := engine.(ParquetExporter)
+		if !ok {
+			return fmt.Errorf("export: format %q requires a DuckDB-backed engine", format)
+		}
+		return exporter.ExportParquet(ctx, q, w)
+	default:
+		return fmt.Errorf("export: unsupported format %q for query export", format)
+	}
+}
+
+// exportQueryBuffered wraps w in a bufio.Writer, flushing once every
+// message has been passed to write.
+func exportQueryBuffered(ctx context.Context, engine query.Engine, q *search.Query, w io.Writer, write func(*bufio.Writer, *query.Message) error) error {
+	bw := bufio.NewWriter(w)
+	err := eachQueryMessage(ctx, engine, q, func(m *query.Message) error {
+		return write(bw, m)
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// eachQueryMessage pages through every message matching q, calling write
+// for each one in order. It mirrors grpcserver.SearchMessages's fallback:
+// try SearchFast first, and if its first page comes back empty with free
+// text in q, retry that first page with the full-text Search.
+func eachQueryMessage(ctx context.Context, engine query.Engine, q *search.Query, write func(*query.Message) error) error {
+	offset := 0
+	firstPage := true
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		results, err := engine.SearchFast(ctx, q, query.MessageFilter{}, queryExportPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("export: search: %w", err)
+		}
+		if firstPage && len(results) == 0 && len(q.TextTerms) > 0 {
+			results, err = engine.Search(ctx, q, queryExportPageSize, offset)
+			if err != nil {
+				return fmt.Errorf("export: search: %w", err)
+			}
+		}
+		firstPage = false
+
+		for _, m := range results {
+			if err := write(m); err != nil {
+				return err
+			}
+		}
+
+		if len(results) < queryExportPageSize {
+			return nil
+		}
+		offset += queryExportPageSize
+	}
+}
+
+// writeMboxQueryMessage appends m to bw as a synthesized mboxrd entry: a
+// "From " envelope line, a few headers rebuilt from m's metadata, a
+// Content-Length header, and m.TextBody as the body, with any line that
+// would be mistaken for a message boundary quoted the same way mbox.go's
+// store-based writer quotes raw MIME bodies.
+func writeMboxQueryMessage(bw *bufio.Writer, m *query.Message) error {
+	from := m.FromEmail
+	if from == "" {
+		from = "MAILER-DAEMON"
+	}
+	date := m.Date
+	if date.IsZero() {
+		date = time.Unix(0, 0)
+	}
+
+	if _, err := fmt.Fprintf(bw, "From %s %s\n", from, date.UTC().Format("Mon Jan _2 15:04:05 2006")); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(bw, "From: %s\n", from); err != nil {
+		return err
+	}
+	if len(m.ToEmails) > 0 {
+		if _, err := fmt.Fprintf(bw, "To: %s\n", strings.Join(m.ToEmails, ", ")); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(bw, "Subject: %s\n", m.Subject); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(bw, "Date: %s\n", date.UTC().Format(time.RFC1123Z)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(bw, "Content-Length: %d\n\n", len(m.TextBody)); err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(m.TextBody, "\n") {
+		if mboxrdNeedsQuoting([]byte(line)) {
+			if _, err := bw.WriteString(">"); err != nil {
+				return err
+			}
+		}
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+		if _, err := bw.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	_, err := bw.WriteString("\n")
+	return err
+}
+
+// queryJSONLRecord is the shape ExportQuery's JSONL format writes per
+// message — the query-layer analog of jsonlRecord, built from a
+// query.Message instead of a store.ExportMessage plus parsed raw MIME.
+type queryJSONLRecord struct {
+	ID             int64    `json:"id"`
+	ConversationID int64    `json:"conversation_id"`
+	From           string   `json:"from"`
+	To             []string `json:"to"`
+	Subject        string   `json:"subject"`
+	Date           string   `json:"date"`
+	Labels         []string `json:"labels,omitempty"`
+	BodyText       string   `json:"body_text"`
+}
+
+func writeJSONLQueryMessage(bw *bufio.Writer, m *query.Message) error {
+	rec := queryJSONLRecord{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		From:           m.FromEmail,
+		To:             m.ToEmails,
+		Subject:        m.Subject,
+		Date:           m.Date.Format("2006-01-02T15:04:05Z07:00"),
+		Labels:         m.Labels,
+		BodyText:       m.TextBody,
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("export: marshal message %d: %w", m.ID, err)
+	}
+	if _, err := bw.Write(data); err != nil {
+		return err
+	}
+	_, err = bw.WriteString("\n")
+	return err
+}
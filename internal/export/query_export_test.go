@@ -0,0 +1,94 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wesm/msgvault/internal/query"
+)
+
+func TestWriteMboxQueryMessage(t *testing.T) {
+	m := &query.Message{
+		ID:       1,
+		Subject:  "Hello",
+		FromEmail: "alice@example.com",
+		ToEmails: []string{"bob@example.com"},
+		Date:     time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		TextBody: "From the start of a line\nregular line",
+	}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	if err := writeMboxQueryMessage(bw, m); err != nil {
+		t.Fatalf("writeMboxQueryMessage: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "From alice@example.com Mon Jan 15 10:00:00 2024\n") {
+		t.Errorf("envelope line: got %q", out)
+	}
+	if !strings.Contains(out, "Subject: Hello\n") {
+		t.Errorf("missing Subject header: %q", out)
+	}
+	if !strings.Contains(out, "To: bob@example.com\n") {
+		t.Errorf("missing To header: %q", out)
+	}
+	if !strings.Contains(out, ">From the start of a line\n") {
+		t.Errorf("body line starting with \"From \" should be quoted: %q", out)
+	}
+}
+
+func TestWriteMboxQueryMessage_MissingSender(t *testing.T) {
+	m := &query.Message{Subject: "No sender"}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	if err := writeMboxQueryMessage(bw, m); err != nil {
+		t.Fatalf("writeMboxQueryMessage: %v", err)
+	}
+	bw.Flush()
+
+	if !strings.HasPrefix(buf.String(), "From MAILER-DAEMON ") {
+		t.Errorf("expected MAILER-DAEMON fallback sender, got %q", buf.String())
+	}
+}
+
+func TestWriteJSONLQueryMessage(t *testing.T) {
+	m := &query.Message{
+		ID:        2,
+		Subject:   "Hi",
+		FromEmail: "alice@example.com",
+		ToEmails:  []string{"bob@example.com"},
+		Labels:    []string{"INBOX"},
+		Date:      time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		TextBody:  "body text",
+	}
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	if err := writeJSONLQueryMessage(bw, m); err != nil {
+		t.Fatalf("writeJSONLQueryMessage: %v", err)
+	}
+	bw.Flush()
+
+	line := strings.TrimSpace(buf.String())
+	for _, want := range []string{`"id":2`, `"from":"alice@example.com"`, `"subject":"Hi"`, `"body_text":"body text"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %q", want, line)
+		}
+	}
+}
+
+func TestExportQuery_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExportQuery(nil, nil, nil, &buf, Format("yaml"))
+	if err == nil {
+		t.Error("ExportQuery with an unsupported format: expected an error, got nil")
+	}
+}
@@ -0,0 +1,224 @@
+// Package exporter periodically pushes archive-wide counts (GetTotalStats
+// plus per-sender/per-domain/per-label aggregates) to an external metrics
+// sink, so operators can graph mailbox growth without polling the
+// aggregate MCP tool by hand.
+//
+// Exporter follows the options pattern mtail uses for its own metric
+// exporter: New(ctx, engine, opts...) starts a background push loop
+// immediately, options configure it before the loop starts, and Stop
+// cancels the loop and waits for it to exit cleanly.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wesm/msgvault/internal/query"
+)
+
+// defaultPushInterval is how often Exporter pushes a snapshot when no
+// PushInterval option is given.
+const defaultPushInterval = time.Minute
+
+// sizeBuckets are the upper bounds (in bytes) of the message-size
+// histogram included in every snapshot; the final bucket is unbounded.
+var sizeBuckets = []int64{10 * 1024, 100 * 1024, 1024 * 1024, 10 * 1024 * 1024}
+
+// Snapshot is one push's worth of archive-wide metrics.
+type Snapshot struct {
+	Timestamp time.Time
+
+	TotalMessages    int64
+	TotalSizeBytes   int64
+	TotalAttachments int64
+
+	BySender []query.AggregateRow
+	ByDomain []query.AggregateRow
+	ByLabel  []query.AggregateRow
+
+	// SizeHistogram maps a bucket label (e.g. "<=10240", ">10485760") to
+	// the message count falling in that bucket.
+	SizeHistogram map[string]int64
+}
+
+// Sink delivers a Snapshot to an external system (Prometheus remote-write,
+// StatsD, a plain HTTP JSON endpoint, ...).
+type Sink interface {
+	Push(ctx context.Context, snap Snapshot) error
+}
+
+// Option configures an Exporter before its push loop starts.
+type Option func(*Exporter) error
+
+// PushInterval sets how often the exporter pushes a snapshot. The default
+// is one minute.
+func PushInterval(d time.Duration) Option {
+	return func(e *Exporter) error {
+		if d <= 0 {
+			return fmt.Errorf("exporter: push interval must be positive, got %s", d)
+		}
+		e.pushInterval = d
+		return nil
+	}
+}
+
+// DisableExport starts the Exporter's background goroutine without ever
+// pushing a snapshot, so callers can depend on Exporter's lifecycle
+// (New/Stop) in tests or dry runs without needing a real sink.
+func DisableExport() Option {
+	return func(e *Exporter) error {
+		e.disabled = true
+		return nil
+	}
+}
+
+// PushTarget sets the sink snapshots are pushed to.
+func PushTarget(sink Sink) Option {
+	return func(e *Exporter) error {
+		e.sink = sink
+		return nil
+	}
+}
+
+// Exporter periodically pulls stats from a query.Engine and pushes them to
+// a Sink until Stop is called.
+type Exporter struct {
+	engine query.Engine
+
+	pushInterval time.Duration
+	disabled     bool
+	sink         Sink
+
+	cancel       context.CancelFunc
+	initDone     chan struct{}
+	shutdownDone chan struct{}
+	wg           sync.WaitGroup
+}
+
+// New creates an Exporter over engine and starts its push loop in the
+// background, returning once the loop has started (but before its first
+// push). Callers must call Stop to release the background goroutine.
+func New(ctx context.Context, engine query.Engine, opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		engine:       engine,
+		pushInterval: defaultPushInterval,
+		initDone:     make(chan struct{}),
+		shutdownDone: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go e.run(runCtx)
+
+	<-e.initDone
+	return e, nil
+}
+
+// Stop cancels the push loop and waits for it to exit.
+func (e *Exporter) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+func (e *Exporter) run(ctx context.Context) {
+	defer e.wg.Done()
+	defer close(e.shutdownDone)
+	close(e.initDone)
+
+	if e.disabled {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(e.pushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.pushOnce(ctx)
+		}
+	}
+}
+
+// pushOnce builds one Snapshot and pushes it to the configured sink,
+// returning any failure. run discards that error and keeps the loop going —
+// one bad push shouldn't end exporting until the process is restarted.
+func (e *Exporter) pushOnce(ctx context.Context) error {
+	if e.sink == nil {
+		return nil
+	}
+	snap, err := e.snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("exporter: build snapshot: %w", err)
+	}
+	if err := e.sink.Push(ctx, snap); err != nil {
+		return fmt.Errorf("exporter: push snapshot: %w", err)
+	}
+	return nil
+}
+
+func (e *Exporter) snapshot(ctx context.Context) (Snapshot, error) {
+	stats, err := e.engine.GetTotalStats(ctx, query.StatsOptions{})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("get total stats: %w", err)
+	}
+
+	opts := query.DefaultAggregateOptions()
+
+	bySender, err := e.engine.AggregateBySender(ctx, opts)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("aggregate by sender: %w", err)
+	}
+	byDomain, err := e.engine.AggregateByDomain(ctx, opts)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("aggregate by domain: %w", err)
+	}
+	byLabel, err := e.engine.AggregateByLabel(ctx, opts)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("aggregate by label: %w", err)
+	}
+
+	histogram, err := e.sizeHistogram(ctx)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("size histogram: %w", err)
+	}
+
+	return Snapshot{
+		Timestamp:        time.Now(),
+		TotalMessages:    stats.TotalMessages,
+		TotalSizeBytes:   stats.TotalSizeBytes,
+		TotalAttachments: stats.TotalAttachments,
+		BySender:         bySender,
+		ByDomain:         byDomain,
+		ByLabel:          byLabel,
+		SizeHistogram:    histogram,
+	}, nil
+}
+
+// sizeHistogram buckets the archive's messages by size. It relies on
+// query.Engine.AggregateBySize, which counts each message once, in the
+// first of sizeBuckets' upper bounds it fits under (or the final ">N"
+// bucket).
+func (e *Exporter) sizeHistogram(ctx context.Context) (map[string]int64, error) {
+	rows, err := e.engine.AggregateBySize(ctx, sizeBuckets)
+	if err != nil {
+		return nil, err
+	}
+	histogram := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		histogram[r.Key] = r.Count
+	}
+	return histogram, nil
+}
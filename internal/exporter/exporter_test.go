@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPushInterval_RejectsNonPositive(t *testing.T) {
+	e := &Exporter{}
+	if err := PushInterval(0)(e); err == nil {
+		t.Error("PushInterval(0) = nil error, want an error")
+	}
+	if err := PushInterval(time.Second)(e); err != nil {
+		t.Errorf("PushInterval(1s) = %v, want nil", err)
+	}
+	if e.pushInterval != time.Second {
+		t.Errorf("pushInterval = %s, want 1s", e.pushInterval)
+	}
+}
+
+func TestNew_DisableExportStopsCleanly(t *testing.T) {
+	ctx := context.Background()
+	e, err := New(ctx, nil, DisableExport(), PushInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		e.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not return promptly after DisableExport")
+	}
+}
+
+func TestNew_RejectsInvalidOption(t *testing.T) {
+	if _, err := New(context.Background(), nil, PushInterval(-1)); err == nil {
+		t.Error("New() with an invalid option = nil error, want an error")
+	}
+}
+
+func TestHTTPJSONSink_Push(t *testing.T) {
+	var received Snapshot
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPJSONSink(server.URL)
+	snap := Snapshot{TotalMessages: 42}
+	if err := sink.Push(context.Background(), snap); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if received.TotalMessages != 42 {
+		t.Errorf("received.TotalMessages = %d, want 42", received.TotalMessages)
+	}
+}
+
+func TestHTTPJSONSink_Push_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPJSONSink(server.URL)
+	if err := sink.Push(context.Background(), Snapshot{}); err == nil {
+		t.Error("Push() with a 500 response = nil error, want an error")
+	}
+}
+
+func TestSinkFromTarget(t *testing.T) {
+	tests := []struct {
+		target  string
+		wantErr bool
+	}{
+		{"http://localhost:9091/push", false},
+		{"https://example.com/metrics", false},
+		{"statsd://127.0.0.1:8125", false},
+		{"promremotewrite+http://localhost:9090/api/v1/write", false},
+		{"ftp://example.com", true},
+	}
+	for _, tt := range tests {
+		_, err := SinkFromTarget(tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SinkFromTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SinkFromTarget builds the Sink to use for a --target value, dispatching
+// on its URL scheme:
+//
+//	statsd://host:port                 -> StatsD (UDP, one metric per line)
+//	promremotewrite+http(s)://host/path -> Prometheus remote-write
+//	http(s)://host/path                 -> plain JSON POST
+func SinkFromTarget(target string) (Sink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("exporter: invalid target %q: %w", target, err)
+	}
+
+	switch u.Scheme {
+	case "statsd":
+		return NewStatsDSink(u.Host)
+	case "promremotewrite+http", "promremotewrite+https":
+		endpoint := strings.TrimPrefix(u.Scheme, "promremotewrite+") + "://" + u.Host + u.Path
+		return NewPrometheusRemoteWriteSink(endpoint), nil
+	case "http", "https":
+		return NewHTTPJSONSink(u.String()), nil
+	default:
+		return nil, fmt.Errorf("exporter: unsupported target scheme %q", u.Scheme)
+	}
+}
+
+// HTTPJSONSink pushes a Snapshot as a JSON POST body to a single endpoint.
+type HTTPJSONSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPJSONSink returns a Sink that POSTs each Snapshot as JSON to endpoint.
+func NewHTTPJSONSink(endpoint string) *HTTPJSONSink {
+	return &HTTPJSONSink{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Push implements Sink.
+func (s *HTTPJSONSink) Push(ctx context.Context, snap Snapshot) error {
+	body, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("post snapshot: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// StatsDSink pushes per-metric gauge lines to a StatsD daemon over UDP.
+type StatsDSink struct {
+	addr string
+	conn net.Conn
+}
+
+// NewStatsDSink returns a Sink that sends StatsD gauge lines to addr
+// (host:port).
+func NewStatsDSink(addr string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial statsd at %s: %w", addr, err)
+	}
+	return &StatsDSink{addr: addr, conn: conn}, nil
+}
+
+// Push implements Sink.
+func (s *StatsDSink) Push(ctx context.Context, snap Snapshot) error {
+	var buf bytes.Buffer
+	writeGauge(&buf, "msgvault.messages.total", snap.TotalMessages)
+	writeGauge(&buf, "msgvault.size_bytes.total", snap.TotalSizeBytes)
+	writeGauge(&buf, "msgvault.attachments.total", snap.TotalAttachments)
+	for _, r := range snap.BySender {
+		writeGauge(&buf, "msgvault.messages.by_sender."+statsdTag(r.Key), r.Count)
+	}
+	for _, r := range snap.ByDomain {
+		writeGauge(&buf, "msgvault.messages.by_domain."+statsdTag(r.Key), r.Count)
+	}
+	for _, r := range snap.ByLabel {
+		writeGauge(&buf, "msgvault.messages.by_label."+statsdTag(r.Key), r.Count)
+	}
+	for bucket, count := range snap.SizeHistogram {
+		writeGauge(&buf, "msgvault.messages.by_size."+statsdTag(bucket), count)
+	}
+
+	_, err := s.conn.Write(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("write to statsd at %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+func writeGauge(buf *bytes.Buffer, name string, value int64) {
+	fmt.Fprintf(buf, "%s:%d|g\n", name, value)
+}
+
+// statsdTag sanitizes a free-form aggregate key (an email address, domain,
+// label, or size bucket) into a StatsD-safe metric name segment.
+func statsdTag(key string) string {
+	replacer := strings.NewReplacer(".", "_", "@", "_at_", ":", "_", " ", "_", "<", "lte", ">", "gt")
+	return replacer.Replace(key)
+}
+
+// PrometheusRemoteWriteSink pushes a Snapshot's counts as Prometheus
+// remote-write samples. It deliberately avoids depending on the
+// prompb/snappy stack so the exporter has no protobuf generation step of
+// its own; it speaks the simpler (and widely supported) text-based
+// remote-write-adjacent POST some gateways and the Prometheus Pushgateway
+// accept.
+type PrometheusRemoteWriteSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewPrometheusRemoteWriteSink returns a Sink that POSTs snapshot counts
+// as Prometheus exposition-format text to endpoint.
+func NewPrometheusRemoteWriteSink(endpoint string) *PrometheusRemoteWriteSink {
+	return &PrometheusRemoteWriteSink{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Push implements Sink.
+func (s *PrometheusRemoteWriteSink) Push(ctx context.Context, snap Snapshot) error {
+	var buf bytes.Buffer
+	writeMetric(&buf, "msgvault_messages_total", nil, snap.TotalMessages)
+	writeMetric(&buf, "msgvault_size_bytes_total", nil, snap.TotalSizeBytes)
+	writeMetric(&buf, "msgvault_attachments_total", nil, snap.TotalAttachments)
+	for _, r := range snap.BySender {
+		writeMetric(&buf, "msgvault_messages_by_sender", map[string]string{"sender": r.Key}, r.Count)
+	}
+	for _, r := range snap.ByDomain {
+		writeMetric(&buf, "msgvault_messages_by_domain", map[string]string{"domain": r.Key}, r.Count)
+	}
+	for _, r := range snap.ByLabel {
+		writeMetric(&buf, "msgvault_messages_by_label", map[string]string{"label": r.Key}, r.Count)
+	}
+	for bucket, count := range snap.SizeHistogram {
+		writeMetric(&buf, "msgvault_messages_by_size_bucket", map[string]string{"le": bucket}, count)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("push snapshot: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func writeMetric(buf *bytes.Buffer, name string, labels map[string]string, value int64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(buf, "%s %s\n", name, strconv.FormatInt(value, 10))
+		return
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	fmt.Fprintf(buf, "%s{%s} %s\n", name, strings.Join(pairs, ","), strconv.FormatInt(value, 10))
+}
@@ -0,0 +1,108 @@
+package fileutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// CopyOptions configures Copy beyond a plain byte-for-byte copy.
+type CopyOptions struct {
+	// PreserveXattrs copies the source file's extended attributes (and,
+	// where the platform exposes one as an xattr, its SELinux label) onto
+	// the destination. No-op on platforms without POSIX xattrs.
+	PreserveXattrs bool
+
+	// PreserveTimes applies the source file's modification time to the
+	// destination after the copy, as both its modification and access
+	// time, instead of leaving the destination with its creation-time
+	// timestamps. The source's access time is not copied.
+	PreserveTimes bool
+
+	// Sparse uses SEEK_HOLE/SEEK_DATA (where the platform supports it) to
+	// skip writing runs of zero bytes that are actually unallocated holes
+	// in the source, so copying a sparse file (e.g. a sqlite WAL that
+	// grew and shrank) doesn't inflate it to its logical size on disk.
+	// No-op on platforms without hole-punching support; the destination
+	// is then a plain dense copy.
+	Sparse bool
+
+	// SecureDest restricts the destination file's permissions to the
+	// current user after the copy (see SecureWriteFile/restrictToCurrentUser),
+	// instead of inheriting whatever default ACL/mode the destination
+	// directory would otherwise apply. Use for files that may hold
+	// secrets, e.g. a dataset's config.toml.
+	SecureDest bool
+}
+
+// Copy copies src to dst according to opts. It is the options-aware
+// counterpart to CopyFileIfExists, used by CopySubset for files (the
+// generated msgvault.db, an auxiliary config.toml) where the destination's
+// security attributes and sparseness matter, not just its bytes. Like
+// CopyFileIfExists, src and dst must be absolute, and a missing src is not
+// an error - Copy simply does nothing.
+func Copy(src, dst string, opts CopyOptions) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("fileutil: open source %s: %w", src, err)
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return fmt.Errorf("fileutil: stat source %s: %w", src, err)
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("fileutil: create destination %s: %w", dst, err)
+	}
+	defer dstFile.Close()
+
+	sparseOK := false
+	if opts.Sparse {
+		if err := copySparse(srcFile, dstFile, info.Size()); err == nil {
+			sparseOK = true
+		} else if _, err := srcFile.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("fileutil: rewind source %s after sparse copy attempt: %w", src, err)
+		}
+	}
+	if !sparseOK {
+		if _, err := dstFile.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("fileutil: rewind destination %s: %w", dst, err)
+		}
+		if err := dstFile.Truncate(0); err != nil {
+			return fmt.Errorf("fileutil: truncate destination %s: %w", dst, err)
+		}
+		if _, err := io.Copy(dstFile, srcFile); err != nil {
+			return fmt.Errorf("fileutil: copy %s to %s: %w", src, dst, err)
+		}
+	}
+
+	if err := dstFile.Sync(); err != nil {
+		return fmt.Errorf("fileutil: sync destination %s: %w", dst, err)
+	}
+
+	if opts.PreserveXattrs {
+		if err := copyXattrs(src, dst); err != nil {
+			return fmt.Errorf("fileutil: copy xattrs %s to %s: %w", src, dst, err)
+		}
+	}
+
+	if opts.PreserveTimes {
+		if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+			return fmt.Errorf("fileutil: set times on %s: %w", dst, err)
+		}
+	}
+
+	if opts.SecureDest {
+		if err := secureDestination(dst); err != nil {
+			return fmt.Errorf("fileutil: secure destination %s: %w", dst, err)
+		}
+	}
+
+	return nil
+}
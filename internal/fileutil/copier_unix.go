@@ -0,0 +1,115 @@
+//go:build !windows
+
+package fileutil
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs copies every extended attribute (including a SELinux
+// security.selinux label, where present - it's stored as an ordinary
+// xattr on SELinux-enabled systems) from src to dst.
+func copyXattrs(src, dst string) error {
+	size, err := unix.Listxattr(src, nil)
+	if err != nil {
+		if err == unix.ENOTSUP || err == unix.EOPNOTSUPP {
+			return nil
+		}
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	n, err := unix.Listxattr(src, buf)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range splitXattrNames(buf[:n]) {
+		valSize, err := unix.Getxattr(src, name, nil)
+		if err != nil {
+			continue
+		}
+		val := make([]byte, valSize)
+		if valSize > 0 {
+			if _, err := unix.Getxattr(src, name, val); err != nil {
+				continue
+			}
+		}
+		_ = unix.Setxattr(dst, name, val, 0)
+	}
+	return nil
+}
+
+// splitXattrNames splits the NUL-separated attribute-name list
+// unix.Listxattr fills buf with into individual names.
+func splitXattrNames(buf []byte) []string {
+	var names []string
+	start := 0
+	for i, b := range buf {
+		if b == 0 {
+			if i > start {
+				names = append(names, string(buf[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return names
+}
+
+// copySparse copies src to dst, using SEEK_HOLE/SEEK_DATA to find runs of
+// src that are unallocated holes and skipping them (via dst.Seek, so they
+// stay holes in dst too) instead of writing out their zero bytes, so a
+// sparse file like a sqlite WAL that grew and then shrank doesn't inflate
+// to its full logical size on the destination. Falls back to a plain dense
+// copy if the filesystem doesn't support SEEK_HOLE/SEEK_DATA.
+func copySparse(src, dst *os.File, size int64) error {
+	var offset int64
+	for offset < size {
+		dataStart, err := src.Seek(offset, unix.SEEK_DATA)
+		if err != nil {
+			// No more data (err is ENXIO) or the filesystem doesn't
+			// support SEEK_DATA: fall back to a plain copy from here.
+			if _, err := src.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+			if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(dst, src, size-offset); err != nil && err != io.EOF {
+				return err
+			}
+			return nil
+		}
+
+		holeStart, err := src.Seek(dataStart, unix.SEEK_HOLE)
+		if err != nil {
+			holeStart = size
+		}
+
+		if _, err := dst.Seek(dataStart, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := src.Seek(dataStart, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(dst, src, holeStart-dataStart); err != nil && err != io.EOF {
+			return err
+		}
+
+		offset = holeStart
+	}
+	return nil
+}
+
+// secureDestination restricts dst to owner-only access (0600), the POSIX
+// equivalent of Windows' per-user DACL (see restrictToCurrentUser in
+// secure_windows.go) for a destination file that may hold secrets.
+func secureDestination(dst string) error {
+	return os.Chmod(dst, 0600)
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package fileutil
+
+import "os"
+
+// copyXattrs is a no-op on Windows: NTFS alternate data streams aren't the
+// POSIX xattrs Copy is documented to preserve, and SELinux labels don't
+// apply outside Linux.
+func copyXattrs(src, dst string) error {
+	return nil
+}
+
+// copySparse falls back to a plain dense copy on Windows. NTFS does
+// support sparse files, but only via FSCTL_SET_SPARSE/FSCTL_QUERY_ALLOCATED_RANGES
+// rather than the SEEK_HOLE/SEEK_DATA lseek whence values copier_unix.go
+// uses, which is a large enough difference in API shape to leave for a
+// dedicated Windows sparse-copy implementation rather than approximate here.
+func copySparse(src, dst *os.File, size int64) error {
+	return errSparseUnsupported
+}
+
+var errSparseUnsupported = &sparseUnsupportedError{}
+
+type sparseUnsupportedError struct{}
+
+func (*sparseUnsupportedError) Error() string {
+	return "fileutil: sparse copy not implemented on windows, falling back to dense copy"
+}
+
+// secureDestination re-applies the per-user DACL from restrictToCurrentUser
+// (see secure_windows.go) to dst, rather than leaving it to inherit the
+// destination directory's ACL the way a plain copy would.
+func secureDestination(dst string) error {
+	return restrictToCurrentUser(dst)
+}
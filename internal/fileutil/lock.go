@@ -0,0 +1,57 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Mutex is a mutual-exclusion lock that works across both goroutines in
+// this process and other processes on the machine, modeled on
+// cmd/go/internal/lockedfile.Mutex. Path names the file whose OS advisory
+// lock (flock on Unix, LockFileEx on Windows) provides cross-process
+// exclusion; an internal sync.Mutex provides the in-process half, so the
+// race detector and -race builds see Lock/unlock as a real synchronization
+// point rather than two unrelated OS calls that happen to serialize.
+//
+// SecureWriteFile/SecureOpenFile take an optional Mutex so two msgvault
+// processes writing the same message store or index can't corrupt each
+// other.
+type Mutex struct {
+	// Path is the lock file. It's created if it doesn't exist, and is
+	// never removed by Mutex — removing a lock file out from under a
+	// concurrent holder would let a second process acquire a lock on a
+	// file nobody else can see.
+	Path string
+
+	mu sync.Mutex
+}
+
+// Lock acquires m, blocking until it is available, and returns an unlock
+// function the caller must call exactly once to release it. unlock is
+// idempotent past the first call so it's safe to defer and also call
+// early.
+func (m *Mutex) Lock() (unlock func(), err error) {
+	m.mu.Lock()
+
+	f, err := SecureOpenFile(m.Path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("fileutil: open lock file %s: %w", m.Path, err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		m.mu.Unlock()
+		return nil, fmt.Errorf("fileutil: lock %s: %w", m.Path, err)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unlockFile(f)
+			f.Close()
+			m.mu.Unlock()
+		})
+	}, nil
+}
@@ -0,0 +1,139 @@
+package fileutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lockHelperEnv, when set in a subprocess's environment, tells TestMain to
+// skip the normal test suite and instead acquire a Mutex on the named path,
+// print "locked" once it succeeds, and hold the lock until killed. This is
+// how TestMutex_ExcludesAcrossProcesses proves Mutex excludes a second OS
+// process, not just a second goroutine.
+const lockHelperEnv = "MSGVAULT_LOCK_HELPER_PATH"
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(lockHelperEnv); path != "" {
+		runLockHelper(path)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func runLockHelper(path string) {
+	mu := &Mutex{Path: path}
+	unlock, err := mu.Lock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lock helper: Lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer unlock()
+
+	fmt.Println("locked")
+	time.Sleep(30 * time.Second)
+}
+
+func TestMutex_ExcludesAcrossProcesses(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, "test.lock")
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+lockPath)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start helper subprocess: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	ready := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		if scanner.Scan() && scanner.Text() == "locked" {
+			close(ready)
+		}
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("helper subprocess never reported holding the lock")
+	}
+
+	mu := &Mutex{Path: lockPath}
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := mu.Lock()
+		if err != nil {
+			t.Errorf("Lock: %v", err)
+			return
+		}
+		unlock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Lock() succeeded in this process while the helper subprocess still held the lock")
+	case <-time.After(200 * time.Millisecond):
+		// Still blocked, as expected.
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatalf("kill helper subprocess: %v", err)
+	}
+	cmd.Wait()
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock() never succeeded after the helper subprocess was killed")
+	}
+}
+
+func TestMutex_SameProcessIsSerialized(t *testing.T) {
+	dir := t.TempDir()
+	mu := &Mutex{Path: filepath.Join(dir, "test.lock")}
+
+	unlock, err := mu.Lock()
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock2, err := mu.Lock()
+		if err != nil {
+			t.Errorf("Lock: %v", err)
+			return
+		}
+		unlock2()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Lock() succeeded while the first was still held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Lock() never succeeded after the first was released")
+	}
+}
@@ -0,0 +1,101 @@
+//go:build !windows
+
+package fileutil
+
+import "os"
+
+// isOwnerOnly returns true if the permission mode grants nothing to group or other.
+func isOwnerOnly(perm os.FileMode) bool {
+	return perm&0077 == 0
+}
+
+// restrictToCurrentUser re-applies perm to path via chmod. A file's actual
+// mode on creation is perm &^ umask, so an owner-only request like 0600 can
+// still come out looser than asked for under a permissive process umask;
+// Unix has no DACL-like overlay the way Windows does, so re-asserting the
+// requested bits exactly is the whole of "restricting to the current user"
+// here.
+func restrictToCurrentUser(path string, perm os.FileMode) {
+	os.Chmod(path, perm)
+}
+
+// SecureWriteFile writes data to the named file, creating it if necessary.
+// For owner-only modes, perm is re-applied via chmod so the actual mode
+// can't end up looser than requested under a permissive umask.
+//
+// If lock is given, its first element guards the write with Mutex.Lock so
+// two processes writing the same path can't interleave and corrupt it.
+func SecureWriteFile(path string, data []byte, perm os.FileMode, lock ...*Mutex) error {
+	if len(lock) > 0 && lock[0] != nil {
+		unlock, err := lock[0].Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	if isOwnerOnly(perm) {
+		restrictToCurrentUser(path, perm)
+	}
+	return nil
+}
+
+// SecureMkdirAll creates a directory path and all parents that do not yet exist.
+// For owner-only modes, perm is re-applied via chmod to the final directory.
+func SecureMkdirAll(path string, perm os.FileMode) error {
+	if err := os.MkdirAll(path, perm); err != nil {
+		return err
+	}
+	if isOwnerOnly(perm) {
+		restrictToCurrentUser(path, perm)
+	}
+	return nil
+}
+
+// SecureChmod changes the mode of the named file.
+// For owner-only modes, perm is re-applied via chmod (a no-op here beyond
+// the os.Chmod call itself, but kept for parity with the Windows build,
+// where it also fixes up the file's DACL).
+func SecureChmod(path string, perm os.FileMode) error {
+	if err := os.Chmod(path, perm); err != nil {
+		return err
+	}
+	if isOwnerOnly(perm) {
+		restrictToCurrentUser(path, perm)
+	}
+	return nil
+}
+
+// SecureOpenFile opens the named file with specified flag and permissions.
+// For owner-only modes on newly created files, perm is re-applied via
+// chmod so the actual mode can't end up looser than requested under a
+// permissive umask.
+//
+// If lock is given, its first element is held only for the open call
+// itself (including the chmod fixup below), so a second process's open of
+// the same path can't race this one's file creation and permissioning.
+// It does not cover reads or writes done later through the returned
+// *os.File — callers that need the whole read-modify-write to be atomic
+// across processes should take lock themselves and call SecureOpenFile
+// without one.
+func SecureOpenFile(path string, flag int, perm os.FileMode, lock ...*Mutex) (*os.File, error) {
+	if len(lock) > 0 && lock[0] != nil {
+		unlock, err := lock[0].Lock()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	f, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if isOwnerOnly(perm) && (flag&os.O_CREATE != 0) {
+		restrictToCurrentUser(path, perm)
+	}
+	return f, nil
+}
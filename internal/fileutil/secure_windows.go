@@ -68,7 +68,18 @@ func restrictToCurrentUser(path string) error {
 
 // SecureWriteFile writes data to the named file, creating it if necessary.
 // For owner-only modes, a DACL restricting access to the current user is applied.
-func SecureWriteFile(path string, data []byte, perm os.FileMode) error {
+//
+// If lock is given, its first element guards the write with Mutex.Lock so
+// two processes writing the same path can't interleave and corrupt it.
+func SecureWriteFile(path string, data []byte, perm os.FileMode, lock ...*Mutex) error {
+	if len(lock) > 0 && lock[0] != nil {
+		unlock, err := lock[0].Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	if err := os.WriteFile(path, data, perm); err != nil {
 		return err
 	}
@@ -106,7 +117,23 @@ func SecureChmod(path string, perm os.FileMode) error {
 // SecureOpenFile opens the named file with specified flag and permissions.
 // For owner-only modes on newly created files, a DACL restricting access to
 // the current user is applied.
-func SecureOpenFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+//
+// If lock is given, its first element is held only for the open call
+// itself (including the DACL fixup below), so a second process's open of
+// the same path can't race this one's file creation and permissioning.
+// It does not cover reads or writes done later through the returned
+// *os.File — callers that need the whole read-modify-write to be atomic
+// across processes should take lock themselves and call SecureOpenFile
+// without one.
+func SecureOpenFile(path string, flag int, perm os.FileMode, lock ...*Mutex) (*os.File, error) {
+	if len(lock) > 0 && lock[0] != nil {
+		unlock, err := lock[0].Lock()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
 	f, err := os.OpenFile(path, flag, perm)
 	if err != nil {
 		return nil, err
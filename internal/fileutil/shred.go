@@ -0,0 +1,129 @@
+package fileutil
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DefaultShredPasses is how many overwrite passes SecureRemove and
+// SecureRemoveAll perform before unlinking a file. One pass of
+// crypto/rand is sufficient on modern filesystems (copy-on-write or
+// wear-leveled flash already makes multi-pass overwrite patterns like
+// Gutmann largely pointless); pass a ShredPasses value to raise it for
+// callers with a stricter data-destruction policy, at the cost of
+// proportionally more I/O.
+const DefaultShredPasses = 1
+
+// ShredPasses overrides DefaultShredPasses for a single SecureRemove or
+// SecureRemoveAll call.
+type ShredPasses int
+
+// shredPasses resolves the optional ShredPasses argument both functions
+// accept down to a plain pass count, defaulting to DefaultShredPasses.
+func shredPasses(passes []ShredPasses) int {
+	if len(passes) > 0 && passes[0] > 0 {
+		return int(passes[0])
+	}
+	return DefaultShredPasses
+}
+
+// SecureRemove overwrites path's contents with crypto/rand (see
+// ShredPasses, DefaultShredPasses) before unlinking it and fsyncing its
+// parent directory, so the blocks the file occupied don't still hold
+// readable plaintext after deletion the way a plain os.Remove's would.
+// Safe to call on a path that doesn't exist or isn't a regular file (a
+// symlink's target is untouched; only the link entry is removed).
+func SecureRemove(path string, passes ...ShredPasses) error {
+	if err := shredFile(path, shredPasses(passes)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("fileutil: shred %s: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("fileutil: remove %s: %w", path, err)
+	}
+	return fsyncParent(path)
+}
+
+// SecureRemoveAll is the shredding counterpart to os.RemoveAll: it shreds
+// (see SecureRemove) every regular file under path before removing the
+// now-empty directory tree. Safe to call on a path that doesn't exist.
+func SecureRemoveAll(path string, passes ...ShredPasses) error {
+	n := shredPasses(passes)
+
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return shredFile(p, n)
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("fileutil: shred %s: %w", path, err)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("fileutil: remove %s: %w", path, err)
+	}
+	return fsyncParent(path)
+}
+
+// fsyncParent fsyncs path's parent directory so the unlink itself is
+// durable rather than sitting in the filesystem's page cache.
+func fsyncParent(path string) error {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("open parent directory: %w", err)
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// shredChunkSize is the overwrite buffer size used when the filesystem's
+// own block size isn't available to size it more precisely (see
+// shredFile in shred_unix.go, which prefers stat.Blksize).
+const shredChunkSize = 64 * 1024
+
+// overwritePasses overwrites f's first size bytes with passes rounds of
+// crypto/rand, chunkSize bytes at a time, fsyncing after each pass so the
+// random data actually reaches disk before the next pass or the unlink
+// that follows.
+func overwritePasses(f *os.File, size int64, chunkSize int, passes int) error {
+	if chunkSize <= 0 {
+		chunkSize = shredChunkSize
+	}
+	buf := make([]byte, chunkSize)
+
+	for pass := 0; pass < passes; pass++ {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("seek: %w", err)
+		}
+		var written int64
+		for written < size {
+			n := chunkSize
+			if remaining := size - written; remaining < int64(n) {
+				n = int(remaining)
+			}
+			if _, err := rand.Read(buf[:n]); err != nil {
+				return fmt.Errorf("generate random bytes: %w", err)
+			}
+			if _, err := f.Write(buf[:n]); err != nil {
+				return fmt.Errorf("write: %w", err)
+			}
+			written += int64(n)
+		}
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("sync: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package fileutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShredFileOverwritesContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	original := bytes.Repeat([]byte("A"), 4096)
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := shredFile(path, 1); err != nil {
+		t.Fatalf("shredFile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(got) != len(original) {
+		t.Fatalf("length changed: got %d, want %d", len(got), len(original))
+	}
+	if bytes.Equal(got, original) {
+		t.Error("content unchanged after shredFile")
+	}
+}
+
+func TestSecureRemoveUnlinksFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	if err := os.WriteFile(path, []byte("sensitive data"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := SecureRemove(path); err != nil {
+		t.Fatalf("SecureRemove: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat after SecureRemove: err = %v, want IsNotExist", err)
+	}
+}
+
+func TestSecureRemoveMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := SecureRemove(filepath.Join(dir, "nope")); err != nil {
+		t.Errorf("SecureRemove of missing file: %v, want nil", err)
+	}
+}
+
+func TestSecureRemoveAllShredsTree(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "dataset")
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a"), []byte("one"), 0600); err != nil {
+		t.Fatalf("WriteFile a: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "b"), []byte("two"), 0600); err != nil {
+		t.Fatalf("WriteFile b: %v", err)
+	}
+
+	if err := SecureRemoveAll(root); err != nil {
+		t.Fatalf("SecureRemoveAll: %v", err)
+	}
+
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("Stat after SecureRemoveAll: err = %v, want IsNotExist", err)
+	}
+}
+
+func TestSecureRemoveAllMissingPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := SecureRemoveAll(filepath.Join(dir, "nope")); err != nil {
+		t.Errorf("SecureRemoveAll of missing path: %v, want nil", err)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// shredFile overwrites path's contents with passes rounds of crypto/rand
+// before the caller unlinks it, opening it O_WRONLY|O_SYNC so every Write
+// is flushed to the device immediately instead of batched by the page
+// cache, and chunking the overwrite to match the filesystem's own block
+// size (stat.Blksize) rather than an arbitrary buffer size. Non-regular
+// files (symlinks, directories passed in by mistake) are left untouched.
+func shredFile(path string, passes int) error {
+	if li, err := os.Lstat(path); err != nil {
+		return err
+	} else if !li.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_SYNC, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	chunkSize := shredChunkSize
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Blksize > 0 {
+		chunkSize = int(stat.Blksize)
+	}
+
+	return overwritePasses(f, info.Size(), chunkSize, passes)
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package fileutil
+
+import (
+	"fmt"
+	"os"
+)
+
+// shredFile overwrites path's contents with passes rounds of crypto/rand
+// before the caller unlinks it. It assumes restrictToCurrentUser (see
+// secure_windows.go) was already applied when the file was created --
+// SecureRemove/SecureRemoveAll don't re-apply it here -- since the whole
+// point of locking the DACL down at write time is that the window between
+// this open-for-overwrite and the final unlink never exposes the
+// in-progress random data to anyone but the current user. Windows exposes
+// no portable stat.Blksize equivalent through os.FileInfo, so
+// shredChunkSize is used instead of matching the underlying volume's
+// cluster size.
+func shredFile(path string, passes int) error {
+	if li, err := os.Lstat(path); err != nil {
+		return err
+	} else if !li.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	return overwritePasses(f, info.Size(), shredChunkSize, passes)
+}
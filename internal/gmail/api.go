@@ -32,12 +32,31 @@ type API interface {
 	// TrashMessage moves a message to trash (recoverable for 30 days).
 	TrashMessage(ctx context.Context, messageID string) error
 
+	// UntrashMessage restores a message out of trash.
+	UntrashMessage(ctx context.Context, messageID string) error
+
 	// DeleteMessage permanently deletes a message.
 	DeleteMessage(ctx context.Context, messageID string) error
 
 	// BatchDeleteMessages permanently deletes multiple messages (max 1000).
 	BatchDeleteMessages(ctx context.Context, messageIDs []string) error
 
+	// ModifyMessageLabels adds and removes labels on a single message in one
+	// call. Gmail represents read/unread and starred state as labels too
+	// ("UNREAD", "STARRED"), so this also covers marking a message read.
+	ModifyMessageLabels(ctx context.Context, messageID string, addLabelIDs, removeLabelIDs []string) error
+
+	// Watch registers (or renews) a users.watch Pub/Sub push subscription
+	// for the account, so Gmail notifies topicName whenever new history is
+	// available instead of requiring periodic polling. labelIDs, if
+	// non-empty, restricts notifications to changes affecting those
+	// labels. The subscription expires after at most 7 days and must be
+	// renewed before then by calling Watch again.
+	Watch(ctx context.Context, topicName string, labelIDs []string) (*WatchResponse, error)
+
+	// StopWatch cancels any active push subscription for the account.
+	StopWatch(ctx context.Context) error
+
 	// Close releases any resources held by the client.
 	Close() error
 }
@@ -113,6 +132,17 @@ type HistoryLabelChange struct {
 	LabelIDs []string
 }
 
+// WatchResponse is the result of registering a users.watch push
+// subscription.
+type WatchResponse struct {
+	// HistoryID is the history ID as of registration, usable as a sync
+	// cursor the same way Profile.HistoryID is.
+	HistoryID uint64
+	// Expiration is when the subscription lapses; callers should renew
+	// well before this (see gmail.API.Watch).
+	Expiration time.Time
+}
+
 // SyncProgress reports sync progress to the caller.
 type SyncProgress interface {
 	// OnStart is called when sync begins.
@@ -139,6 +169,8 @@ type SyncSummary struct {
 	MessagesSkipped  int64
 	BytesDownloaded  int64
 	Errors           int64
+	Quarantined      int64
+	Poisoned         int64
 	FinalHistoryID   uint64
 	WasResumed       bool
 	ResumedFromToken string
@@ -0,0 +1,212 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CallRecord is one entry in DeletionMockAPI.CallSequence: which mutating
+// operation ran, in the order it ran, regardless of whether it succeeded.
+type CallRecord struct {
+	Operation string // "trash", "delete", or "batch_delete"
+	MessageID string
+}
+
+// DeletionMockAPI is a richer API fake than MockAPI, purpose-built for
+// internal/deletion's Executor tests: per-message error injection
+// (TrashErrors/DeleteErrors), a BatchDeleteError that forces Executor's
+// per-ID fallback path, before-hooks a test can use to veto a call, and
+// SetTransientFailure to simulate a message that fails N times before
+// succeeding (exercising Executor's RetryPolicy). The rest of gmail.API is
+// implemented as plain no-ops, except GetMessageRaw, which reports every
+// message as not found; deletion tests never read profile, label, message,
+// or history data through it.
+type DeletionMockAPI struct {
+	mu sync.Mutex
+
+	TrashErrors      map[string]error
+	DeleteErrors     map[string]error
+	BatchDeleteError error
+
+	TrashCalls       []string
+	DeleteCalls      []string
+	BatchDeleteCalls [][]string
+
+	CallSequence []CallRecord
+
+	BeforeTrash       func(messageID string) error
+	BeforeDelete      func(messageID string) error
+	BeforeBatchDelete func(messageIDs []string) error
+
+	trashCallCount  map[string]int
+	deleteCallCount map[string]int
+
+	trashTransient  map[string]int
+	deleteTransient map[string]int
+}
+
+// NewDeletionMockAPI returns a DeletionMockAPI with its maps initialized,
+// ready for a test to populate TrashErrors/DeleteErrors or call
+// SetNotFoundError/SetTransientFailure.
+func NewDeletionMockAPI() *DeletionMockAPI {
+	return &DeletionMockAPI{
+		TrashErrors:     make(map[string]error),
+		DeleteErrors:    make(map[string]error),
+		trashCallCount:  make(map[string]int),
+		deleteCallCount: make(map[string]int),
+		trashTransient:  make(map[string]int),
+		deleteTransient: make(map[string]int),
+	}
+}
+
+// SetNotFoundError makes both TrashMessage and DeleteMessage return a
+// *NotFoundError for id, simulating a message already gone on Gmail's side.
+func (m *DeletionMockAPI) SetNotFoundError(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.TrashErrors[id] = &NotFoundError{Path: "/users/me/messages/" + id}
+	m.DeleteErrors[id] = &NotFoundError{Path: "/users/me/messages/" + id}
+}
+
+// SetTransientFailure makes id's next failCount calls to TrashMessage (if
+// isTrash) or DeleteMessage (otherwise) fail before succeeding, simulating
+// a message that recovers under retry.
+func (m *DeletionMockAPI) SetTransientFailure(id string, failCount int, isTrash bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if isTrash {
+		m.trashTransient[id] = failCount
+	} else {
+		m.deleteTransient[id] = failCount
+	}
+}
+
+// Reset clears every recorded call, call count, and injected error, ready
+// for the mock to be reused by another scenario in the same test.
+func (m *DeletionMockAPI) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.TrashErrors = make(map[string]error)
+	m.DeleteErrors = make(map[string]error)
+	m.BatchDeleteError = nil
+	m.TrashCalls = nil
+	m.DeleteCalls = nil
+	m.BatchDeleteCalls = nil
+	m.CallSequence = nil
+	m.trashCallCount = make(map[string]int)
+	m.deleteCallCount = make(map[string]int)
+	m.trashTransient = make(map[string]int)
+	m.deleteTransient = make(map[string]int)
+}
+
+// GetTrashCallCount returns how many times TrashMessage(id) has been
+// called since construction or the last Reset.
+func (m *DeletionMockAPI) GetTrashCallCount(id string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.trashCallCount[id]
+}
+
+// GetDeleteCallCount returns how many times DeleteMessage(id) has been
+// called since construction or the last Reset.
+func (m *DeletionMockAPI) GetDeleteCallCount(id string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deleteCallCount[id]
+}
+
+func (m *DeletionMockAPI) TrashMessage(ctx context.Context, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.trashCallCount[messageID]++
+	m.TrashCalls = append(m.TrashCalls, messageID)
+	m.CallSequence = append(m.CallSequence, CallRecord{Operation: "trash", MessageID: messageID})
+
+	if m.BeforeTrash != nil {
+		if err := m.BeforeTrash(messageID); err != nil {
+			return err
+		}
+	}
+	if remaining := m.trashTransient[messageID]; remaining > 0 {
+		m.trashTransient[messageID] = remaining - 1
+		return fmt.Errorf("gmail: transient trash failure for %s", messageID)
+	}
+	if err, ok := m.TrashErrors[messageID]; ok {
+		return err
+	}
+	return nil
+}
+
+func (m *DeletionMockAPI) DeleteMessage(ctx context.Context, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deleteCallCount[messageID]++
+	m.DeleteCalls = append(m.DeleteCalls, messageID)
+	m.CallSequence = append(m.CallSequence, CallRecord{Operation: "delete", MessageID: messageID})
+
+	if m.BeforeDelete != nil {
+		if err := m.BeforeDelete(messageID); err != nil {
+			return err
+		}
+	}
+	if remaining := m.deleteTransient[messageID]; remaining > 0 {
+		m.deleteTransient[messageID] = remaining - 1
+		return fmt.Errorf("gmail: transient delete failure for %s", messageID)
+	}
+	if err, ok := m.DeleteErrors[messageID]; ok {
+		return err
+	}
+	return nil
+}
+
+func (m *DeletionMockAPI) BatchDeleteMessages(ctx context.Context, messageIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.BatchDeleteCalls = append(m.BatchDeleteCalls, messageIDs)
+	m.CallSequence = append(m.CallSequence, CallRecord{Operation: "batch_delete"})
+
+	if m.BeforeBatchDelete != nil {
+		if err := m.BeforeBatchDelete(messageIDs); err != nil {
+			return err
+		}
+	}
+	return m.BatchDeleteError
+}
+
+func (m *DeletionMockAPI) GetProfile(ctx context.Context) (*Profile, error) { return &Profile{}, nil }
+
+func (m *DeletionMockAPI) ListLabels(ctx context.Context) ([]*Label, error) { return nil, nil }
+
+func (m *DeletionMockAPI) ListMessages(ctx context.Context, query string, pageToken string) (*MessageListResponse, error) {
+	return &MessageListResponse{}, nil
+}
+
+func (m *DeletionMockAPI) GetMessageRaw(ctx context.Context, messageID string) (*RawMessage, error) {
+	return nil, &NotFoundError{Path: "/messages/" + messageID}
+}
+
+func (m *DeletionMockAPI) GetMessagesRawBatch(ctx context.Context, messageIDs []string) ([]*RawMessage, error) {
+	return make([]*RawMessage, len(messageIDs)), nil
+}
+
+func (m *DeletionMockAPI) ListHistory(ctx context.Context, startHistoryID uint64, pageToken string) (*HistoryResponse, error) {
+	return &HistoryResponse{}, nil
+}
+
+func (m *DeletionMockAPI) UntrashMessage(ctx context.Context, messageID string) error { return nil }
+
+func (m *DeletionMockAPI) ModifyMessageLabels(ctx context.Context, messageID string, addLabelIDs, removeLabelIDs []string) error {
+	return nil
+}
+
+func (m *DeletionMockAPI) Watch(ctx context.Context, topicName string, labelIDs []string) (*WatchResponse, error) {
+	return &WatchResponse{}, nil
+}
+
+func (m *DeletionMockAPI) StopWatch(ctx context.Context) error { return nil }
+
+func (m *DeletionMockAPI) Close() error { return nil }
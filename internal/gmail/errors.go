@@ -0,0 +1,11 @@
+package gmail
+
+// NotFoundError indicates the Gmail API returned a 404 for the given path,
+// e.g. because a message was deleted or a history cursor is too old.
+type NotFoundError struct {
+	Path string
+}
+
+func (e *NotFoundError) Error() string {
+	return "gmail: not found: " + e.Path
+}
@@ -0,0 +1,240 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MockAPI is an in-memory API implementation for tests: messages, labels,
+// and history are plain fields a test sets up directly (e.g. via
+// AddMessage) rather than reached through a fake HTTP server. TrashCalls,
+// DeleteCalls, and BatchDeleteCalls record every mutating call a test can
+// assert against.
+type MockAPI struct {
+	mu sync.Mutex
+
+	Profile      *Profile
+	ProfileError error
+	ProfileCalls int
+
+	Labels      []*Label
+	LabelsError error
+	LabelsCalls int
+
+	// Messages holds every message AddMessage (or a test directly) has
+	// registered, keyed by ID.
+	Messages        map[string]*RawMessage
+	GetMessageError map[string]error
+	GetMessageCalls []string
+
+	// MessagePages, if set, overrides ListMessages: each call returns the
+	// next page in order instead of deriving pages from Messages.
+	MessagePages      [][]string
+	ListMessagesCalls int
+	LastQuery         string
+
+	HistoryRecords []HistoryRecord
+	HistoryID      uint64
+	HistoryError   error
+
+	TrashCalls       []string
+	DeleteCalls      []string
+	BatchDeleteCalls [][]string
+
+	WatchResponse *WatchResponse
+	WatchError    error
+	StopWatchErr  error
+	CloseErr      error
+}
+
+// NewMockAPI returns a MockAPI with empty Messages/GetMessageError maps,
+// ready for a test to populate via AddMessage.
+func NewMockAPI() *MockAPI {
+	return &MockAPI{
+		Messages:        make(map[string]*RawMessage),
+		GetMessageError: make(map[string]error),
+	}
+}
+
+// AddMessage registers a message with raw content and labels, available to
+// GetMessageRaw/GetMessagesRawBatch and (unless MessagePages is set)
+// ListMessages.
+func (m *MockAPI) AddMessage(id string, raw []byte, labelIDs []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Messages[id] = &RawMessage{
+		ID:       id,
+		ThreadID: id,
+		LabelIDs: labelIDs,
+		Raw:      raw,
+	}
+}
+
+// Reset clears every call counter and recorded call, keeping Messages,
+// Profile, Labels, and injected errors as they are.
+func (m *MockAPI) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ProfileCalls = 0
+	m.LabelsCalls = 0
+	m.GetMessageCalls = nil
+	m.ListMessagesCalls = 0
+	m.TrashCalls = nil
+	m.DeleteCalls = nil
+	m.BatchDeleteCalls = nil
+}
+
+func (m *MockAPI) GetProfile(ctx context.Context) (*Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ProfileCalls++
+	if m.ProfileError != nil {
+		return nil, m.ProfileError
+	}
+	return m.Profile, nil
+}
+
+func (m *MockAPI) ListLabels(ctx context.Context) ([]*Label, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LabelsCalls++
+	if m.LabelsError != nil {
+		return nil, m.LabelsError
+	}
+	return m.Labels, nil
+}
+
+func (m *MockAPI) ListMessages(ctx context.Context, query string, pageToken string) (*MessageListResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastQuery = query
+
+	if m.MessagePages != nil {
+		page := m.ListMessagesCalls
+		m.ListMessagesCalls++
+		if page >= len(m.MessagePages) {
+			return &MessageListResponse{}, nil
+		}
+		resp := &MessageListResponse{}
+		for _, id := range m.MessagePages[page] {
+			resp.Messages = append(resp.Messages, MessageID{ID: id, ThreadID: id})
+		}
+		resp.ResultSizeEstimate = int64(len(resp.Messages))
+		if page+1 < len(m.MessagePages) {
+			resp.NextPageToken = fmt.Sprintf("page_%d", page+1)
+		}
+		return resp, nil
+	}
+
+	m.ListMessagesCalls++
+	resp := &MessageListResponse{}
+	for id := range m.Messages {
+		resp.Messages = append(resp.Messages, MessageID{ID: id, ThreadID: id})
+	}
+	resp.ResultSizeEstimate = int64(len(resp.Messages))
+	return resp, nil
+}
+
+func (m *MockAPI) GetMessageRaw(ctx context.Context, messageID string) (*RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.GetMessageCalls = append(m.GetMessageCalls, messageID)
+	if err, ok := m.GetMessageError[messageID]; ok {
+		return nil, err
+	}
+	msg, ok := m.Messages[messageID]
+	if !ok {
+		return nil, &NotFoundError{Path: "/messages/" + messageID}
+	}
+	return msg, nil
+}
+
+func (m *MockAPI) GetMessagesRawBatch(ctx context.Context, messageIDs []string) ([]*RawMessage, error) {
+	results := make([]*RawMessage, len(messageIDs))
+	for i, id := range messageIDs {
+		msg, err := m.GetMessageRaw(ctx, id)
+		if err == nil {
+			results[i] = msg
+		}
+	}
+	return results, nil
+}
+
+func (m *MockAPI) ListHistory(ctx context.Context, startHistoryID uint64, pageToken string) (*HistoryResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.HistoryError != nil {
+		return nil, m.HistoryError
+	}
+	return &HistoryResponse{History: m.HistoryRecords, HistoryID: m.HistoryID}, nil
+}
+
+func (m *MockAPI) TrashMessage(ctx context.Context, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.TrashCalls = append(m.TrashCalls, messageID)
+	return nil
+}
+
+func (m *MockAPI) UntrashMessage(ctx context.Context, messageID string) error {
+	return nil
+}
+
+func (m *MockAPI) DeleteMessage(ctx context.Context, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.DeleteCalls = append(m.DeleteCalls, messageID)
+	return nil
+}
+
+func (m *MockAPI) BatchDeleteMessages(ctx context.Context, messageIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.BatchDeleteCalls = append(m.BatchDeleteCalls, messageIDs)
+	return nil
+}
+
+func (m *MockAPI) ModifyMessageLabels(ctx context.Context, messageID string, addLabelIDs, removeLabelIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg, ok := m.Messages[messageID]
+	if !ok {
+		return &NotFoundError{Path: "/messages/" + messageID}
+	}
+	msg.LabelIDs = applyLabelChange(msg.LabelIDs, addLabelIDs, removeLabelIDs)
+	return nil
+}
+
+func applyLabelChange(current, add, remove []string) []string {
+	set := make(map[string]bool, len(current))
+	for _, l := range current {
+		set[l] = true
+	}
+	for _, l := range remove {
+		delete(set, l)
+	}
+	for _, l := range add {
+		set[l] = true
+	}
+	result := make([]string, 0, len(set))
+	for l := range set {
+		result = append(result, l)
+	}
+	return result
+}
+
+func (m *MockAPI) Watch(ctx context.Context, topicName string, labelIDs []string) (*WatchResponse, error) {
+	if m.WatchError != nil {
+		return nil, m.WatchError
+	}
+	return m.WatchResponse, nil
+}
+
+func (m *MockAPI) StopWatch(ctx context.Context) error {
+	return m.StopWatchErr
+}
+
+func (m *MockAPI) Close() error {
+	return m.CloseErr
+}
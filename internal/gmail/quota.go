@@ -0,0 +1,113 @@
+package gmail
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+
+	"github.com/wesm/msgvault/internal/metrics"
+)
+
+// RateLimiterOption configures a RateLimiter at construction time.
+type RateLimiterOption func(*RateLimiter)
+
+// WithExpvarPrefix registers the limiter's counters under expvar using the
+// given prefix (e.g. "gmail_ratelimit_"), so operators can scrape
+// tokens_issued, waits, and throttle_events without wiring up a separate
+// metrics package.
+func WithExpvarPrefix(prefix string) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		expvar.Publish(prefix+"tokens_issued", expvar.Func(func() any { return rl.metrics.tokensIssued.Load() }))
+		expvar.Publish(prefix+"waits", expvar.Func(func() any { return rl.metrics.waits.Load() }))
+		expvar.Publish(prefix+"throttle_events", expvar.Func(func() any { return rl.metrics.throttleEvents.Load() }))
+	}
+}
+
+// rateLimiterMetrics tracks counters for tokens issued, waits, and throttle
+// events so operators can tune concurrency without hitting 403 storms.
+type rateLimiterMetrics struct {
+	tokensIssued   atomic.Int64
+	waits          atomic.Int64
+	throttleEvents atomic.Int64
+}
+
+// WithMetricsRegistry wires rl's per-operation acquire/wait counters and
+// current refill-rate/tokens/throttled-until gauges into reg, so they're
+// included in reg's Prometheus/OpenMetrics exposition alongside any other
+// metrics it collects. Throttle events aren't labeled by operation since
+// neither Throttle nor OnRateLimitError know which call triggered the
+// 429/403 - only that the limiter as a whole needs to back off.
+func WithMetricsRegistry(reg *metrics.Registry) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.acquiredCounter = reg.Counter("msgvault_ratelimit_acquired_total",
+			"Tokens successfully acquired, by operation.").WithLabelNames("operation")
+		rl.throttledCounter = reg.Counter("msgvault_ratelimit_throttled_total",
+			"Throttle() calls observed by the limiter.")
+		rl.waitSecondsHist = reg.Histogram("msgvault_ratelimit_wait_duration_seconds",
+			"Time Acquire spent waiting for tokens, by operation.", metrics.DefaultLatencyBuckets).WithLabelNames("operation")
+		rl.refillRateGauge = reg.Gauge("msgvault_ratelimit_refill_rate",
+			"Current refill rate in quota units/second.")
+		rl.tokensGauge = reg.Gauge("msgvault_ratelimit_tokens",
+			"Current token bucket level.")
+		rl.throttledUntilGauge = reg.Gauge("msgvault_ratelimit_throttled_until_seconds",
+			"Unix timestamp the current throttle window ends, or 0 if not throttled.")
+	}
+}
+
+// RateLimiterStats is a point-in-time snapshot of RateLimiter.metrics.
+type RateLimiterStats struct {
+	TokensIssued   int64
+	Waits          int64
+	ThrottleEvents int64
+}
+
+// Stats returns a snapshot of the limiter's metrics counters.
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	return RateLimiterStats{
+		TokensIssued:   rl.metrics.tokensIssued.Load(),
+		Waits:          rl.metrics.waits.Load(),
+		ThrottleEvents: rl.metrics.throttleEvents.Load(),
+	}
+}
+
+// OnRateLimitError reacts to a RateLimitError observed by a caller:
+// multiplicative decrease of the current rate, and honoring any parsed
+// Retry-After by pausing token issuance for that duration. Callers should
+// invoke this from the same place they call errors.As(err, &rle).
+func (rl *RateLimiter) OnRateLimitError(rle *RateLimitError) {
+	if rle == nil {
+		return
+	}
+	rl.metrics.throttleEvents.Add(1)
+
+	wait := rle.RetryAfter
+	if wait <= 0 {
+		// No server-provided hint: fall back to a conservative pause so we
+		// don't immediately re-hit the same quota wall.
+		wait = time.Second
+	}
+	rl.Throttle(wait)
+}
+
+// OnSuccess records a successful API call. Once probation has elapsed since
+// the last Throttle call with no new one in between, the limiter additively
+// nudges refillRate back toward baseRate by additiveStep. This replaces the
+// old consecutive-successes counter: recovery now tracks wall-clock time
+// since the last 429 rather than a count of calls, so it behaves the same
+// whether those calls are seconds or milliseconds apart.
+func (rl *RateLimiter) OnSuccess() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.refillRate >= rl.baseRate {
+		return
+	}
+	if rl.lastThrottleAt.IsZero() || time.Since(rl.lastThrottleAt) < rl.effectiveProbationLocked() {
+		return
+	}
+
+	rl.refillRate += rl.additiveStep
+	if rl.refillRate > rl.baseRate {
+		rl.refillRate = rl.baseRate
+	}
+}
@@ -0,0 +1,381 @@
+package gmail
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wesm/msgvault/internal/metrics"
+)
+
+// Operation identifies a Gmail API call for the purpose of quota accounting.
+// Costs follow Google's published per-request quota unit table.
+type Operation int
+
+const (
+	OpProfile Operation = iota
+	OpMessagesGet
+	OpMessagesGetRaw
+	OpMessagesList
+	OpLabelsList
+	OpHistoryList
+	OpMessagesTrash
+	OpMessagesDelete
+	OpMessagesBatchDelete
+	OpMessagesModify
+)
+
+// Cost returns the quota units consumed by an operation. Unknown operations
+// default to the cheapest cost of 1 unit.
+func (op Operation) Cost() int {
+	switch op {
+	case OpMessagesGet, OpMessagesGetRaw, OpMessagesList, OpMessagesTrash, OpMessagesModify:
+		return 5
+	case OpLabelsList:
+		return 1
+	case OpHistoryList:
+		return 2
+	case OpMessagesDelete:
+		return 10
+	case OpMessagesBatchDelete:
+		return 50
+	case OpProfile:
+		return 1
+	default:
+		return 1
+	}
+}
+
+// String returns op's name, used as the "operation" label value when
+// exporting per-operation metrics.
+func (op Operation) String() string {
+	switch op {
+	case OpProfile:
+		return "profile"
+	case OpMessagesGet:
+		return "messages.get"
+	case OpMessagesGetRaw:
+		return "messages.get_raw"
+	case OpMessagesList:
+		return "messages.list"
+	case OpLabelsList:
+		return "labels.list"
+	case OpHistoryList:
+		return "history.list"
+	case OpMessagesTrash:
+		return "messages.trash"
+	case OpMessagesDelete:
+		return "messages.delete"
+	case OpMessagesBatchDelete:
+		return "messages.batch_delete"
+	case OpMessagesModify:
+		return "messages.modify"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// DefaultCapacity is the token bucket capacity, matching Gmail's default
+	// per-user quota of 250 quota units/second.
+	DefaultCapacity = 250.0
+	// DefaultRefillRate is the token refill rate in units/second at full (5 QPS) throughput.
+	DefaultRefillRate = 250.0
+	// MinQPS is the slowest configurable request rate, used to keep the
+	// limiter usable (rather than fully stalled) under sustained throttling.
+	MinQPS = 0.05
+	// baselineQPS is the request rate at which refillRate == DefaultRefillRate.
+	baselineQPS = 5.0
+
+	// DefaultDecreaseFactor is the multiplicative decrease applied to
+	// refillRate on each Throttle call.
+	DefaultDecreaseFactor = 0.5
+	// DefaultMinRateFraction is the floor refillRate is allowed to decay to,
+	// expressed as a fraction of baseRate, so sustained throttling slows the
+	// limiter rather than stalling it entirely.
+	DefaultMinRateFraction = 0.1
+	// DefaultProbation is how long refillRate must go without a new Throttle
+	// call before OnSuccess starts additively increasing it again.
+	DefaultProbation = 5 * time.Second
+	// defaultAdditiveStepFraction is the default additiveStep, expressed as
+	// a fraction of baseRate, matching the step OnSuccess used historically.
+	defaultAdditiveStepFraction = 1.0 / 50
+)
+
+// RateLimiter is a token-bucket rate limiter for outgoing Gmail API calls.
+// It proactively blocks callers before dispatch (via Acquire) so that
+// operators stay under Gmail's per-user quota, and reactively shrinks its
+// rate when Throttle is called in response to a 429/403 from the API.
+//
+// Rate control is AIMD (additive-increase/multiplicative-decrease), the same
+// scheme TCP congestion control uses: Throttle multiplies refillRate down by
+// decreaseFactor, and OnSuccess nudges it back up by additiveStep once
+// probation has passed without a new Throttle call. This avoids the
+// oscillation a binary halve/snap-back scheme produces, where a single 429
+// is immediately undone and invites the next one.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	capacity float64
+	tokens   float64
+
+	baseRate   float64 // configured full-throughput refill rate (the AIMD ceiling)
+	minRate    float64 // AIMD floor refillRate decays to under sustained throttling
+	refillRate float64 // current (AIMD-controlled) refill rate
+
+	decreaseFactor float64
+	additiveStep   float64
+	probation      time.Duration
+
+	lastRefill     time.Time
+	throttledUntil time.Time
+	lastThrottleAt time.Time
+	throttleEMA    time.Duration // decaying EMA of the interval between Throttle calls
+
+	metrics rateLimiterMetrics
+
+	// Prometheus-style export, wired up by WithMetricsRegistry. Each handle
+	// is nil-safe on its own (a nil *metrics.Counter/*metrics.Gauge/
+	// *metrics.Histogram silently discards calls), so every call site below
+	// works unconditionally whether or not a registry was configured.
+	acquiredCounter     *metrics.Counter
+	throttledCounter    *metrics.Counter
+	waitSecondsHist     *metrics.Histogram
+	refillRateGauge     *metrics.Gauge
+	tokensGauge         *metrics.Gauge
+	throttledUntilGauge *metrics.Gauge
+}
+
+// NewRateLimiter creates a RateLimiter targeting the given requests/second.
+// qps is scaled against a baseline of 5 QPS == DefaultRefillRate; higher
+// values are capped at DefaultRefillRate. Options configure optional
+// observability hooks such as expvar registration.
+func NewRateLimiter(qps float64, opts ...RateLimiterOption) *RateLimiter {
+	rate := DefaultRefillRate
+	if qps < baselineQPS {
+		rate = DefaultRefillRate * (qps / baselineQPS)
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	rl := &RateLimiter{
+		capacity:       DefaultCapacity,
+		tokens:         DefaultCapacity,
+		baseRate:       rate,
+		minRate:        rate * DefaultMinRateFraction,
+		refillRate:     rate,
+		decreaseFactor: DefaultDecreaseFactor,
+		additiveStep:   rate * defaultAdditiveStepFraction,
+		probation:      DefaultProbation,
+		lastRefill:     time.Now(),
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	return rl
+}
+
+// SetAIMDParams reconfigures the limiter's AIMD behavior: refillRate is kept
+// within [min, max], Throttle multiplies it by decrease (instead of the
+// default 0.5), OnSuccess adds additiveStep per nudge, and probation is how
+// long refillRate must go without a new Throttle before OnSuccess resumes
+// increasing it.
+func (rl *RateLimiter) SetAIMDParams(min, max, decrease, additiveStep float64, probation time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.minRate = min
+	rl.baseRate = max
+	rl.decreaseFactor = decrease
+	rl.additiveStep = additiveStep
+	rl.probation = probation
+
+	if rl.refillRate > rl.baseRate {
+		rl.refillRate = rl.baseRate
+	}
+	if rl.refillRate < rl.minRate {
+		rl.refillRate = rl.minRate
+	}
+}
+
+// effectiveProbationLocked stretches the probation window when throttleEMA
+// shows Throttle calls arriving faster than probation can absorb, so a
+// volley of repeated 429s keeps the rate depressed instead of recovering
+// between two events that are individually more than probation apart but
+// part of a sustained burst. Must be called with rl.mu held.
+func (rl *RateLimiter) effectiveProbationLocked() time.Duration {
+	if rl.throttleEMA > 0 && rl.throttleEMA < rl.probation {
+		return rl.probation + (rl.probation - rl.throttleEMA)
+	}
+	return rl.probation
+}
+
+// refillLocked adds accumulated tokens since the last refill, honoring any
+// active throttle window (no tokens accrue while throttled). refillRate
+// itself only changes via Throttle (down) and OnSuccess (up) - refillLocked
+// no longer snaps it back to baseRate, since that instant flip is exactly
+// the oscillation AIMD replaces.
+func (rl *RateLimiter) refillLocked() {
+	now := time.Now()
+
+	if !rl.throttledUntil.IsZero() && now.Before(rl.throttledUntil) {
+		rl.lastRefill = now
+		rl.refillRateGauge.Set(rl.refillRate)
+		rl.tokensGauge.Set(rl.tokens)
+		rl.throttledUntilGauge.Set(float64(rl.throttledUntil.Unix()))
+		return
+	}
+
+	if elapsed := now.Sub(rl.lastRefill).Seconds(); elapsed > 0 {
+		rl.tokens += elapsed * rl.refillRate
+		if rl.tokens > rl.capacity {
+			rl.tokens = rl.capacity
+		}
+	}
+	rl.lastRefill = now
+
+	rl.refillRateGauge.Set(rl.refillRate)
+	rl.tokensGauge.Set(rl.tokens)
+	if rl.throttledUntil.IsZero() || now.After(rl.throttledUntil) {
+		rl.throttledUntilGauge.Set(0)
+	} else {
+		rl.throttledUntilGauge.Set(float64(rl.throttledUntil.Unix()))
+	}
+}
+
+// TryAcquire attempts to take the tokens needed for op without blocking,
+// returning false if insufficient tokens are currently available.
+func (rl *RateLimiter) TryAcquire(op Operation) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refillLocked()
+	cost := float64(op.Cost())
+	if rl.tokens < cost {
+		return false
+	}
+	rl.tokens -= cost
+	rl.metrics.tokensIssued.Add(int64(cost))
+	rl.acquiredCounter.Inc(op.String())
+	return true
+}
+
+// Acquire blocks until enough tokens are available for op, or ctx is done.
+func (rl *RateLimiter) Acquire(ctx context.Context, op Operation) error {
+	cost := float64(op.Cost())
+	start := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		rl.mu.Lock()
+		rl.refillLocked()
+		if rl.tokens >= cost {
+			rl.tokens -= cost
+			rl.metrics.tokensIssued.Add(int64(cost))
+			rl.mu.Unlock()
+			rl.acquiredCounter.Inc(op.String())
+			rl.waitSecondsHist.Observe(time.Since(start).Seconds(), op.String())
+			return nil
+		}
+
+		wait := rl.waitDurationLocked(cost)
+		rl.metrics.waits.Add(1)
+		rl.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// waitDurationLocked estimates how long to wait before cost tokens will be
+// available, also accounting for any active throttle window. Must be
+// called with rl.mu held.
+func (rl *RateLimiter) waitDurationLocked(cost float64) time.Duration {
+	deficit := cost - rl.tokens
+	var wait time.Duration
+	if rl.refillRate > 0 {
+		wait = time.Duration(deficit / rl.refillRate * float64(time.Second))
+	} else {
+		wait = 100 * time.Millisecond
+	}
+
+	now := time.Now()
+	if !rl.throttledUntil.IsZero() && now.Before(rl.throttledUntil) {
+		if remaining := rl.throttledUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	if wait <= 0 {
+		wait = time.Millisecond
+	}
+	return wait
+}
+
+// Available returns the current number of tokens in the bucket.
+func (rl *RateLimiter) Available() float64 {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refillLocked()
+	return rl.tokens
+}
+
+// Throttle drains the bucket, multiplies the refill rate down by
+// decreaseFactor (floored at minRate), and extends the throttle window to at
+// least now+d (never shortening an existing window). It also records
+// lastThrottleAt and updates the decaying EMA of the interval between
+// Throttle calls, so OnSuccess can tell a sustained volley of 429s apart
+// from an isolated one. Call this when the API reports a rate-limit/quota
+// error.
+func (rl *RateLimiter) Throttle(d time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	until := now.Add(d)
+	if until.After(rl.throttledUntil) {
+		rl.throttledUntil = until
+	}
+	rl.tokens = 0
+
+	if !rl.lastThrottleAt.IsZero() {
+		interval := now.Sub(rl.lastThrottleAt)
+		const emaWeight = 0.5
+		if rl.throttleEMA <= 0 {
+			rl.throttleEMA = interval
+		} else {
+			rl.throttleEMA = time.Duration(float64(rl.throttleEMA)*(1-emaWeight) + float64(interval)*emaWeight)
+		}
+	}
+	rl.lastThrottleAt = now
+
+	rl.refillRate *= rl.decreaseFactor
+	if rl.refillRate < rl.minRate {
+		rl.refillRate = rl.minRate
+	}
+	rl.lastRefill = now
+
+	rl.throttledCounter.Inc()
+	rl.refillRateGauge.Set(rl.refillRate)
+	rl.throttledUntilGauge.Set(float64(rl.throttledUntil.Unix()))
+}
+
+// RecoverRate immediately restores the refill rate to its configured full
+// throughput, bypassing the usual additive-increase ramp. It does not
+// affect the current throttle window or token count. This is an escape
+// hatch for callers that know recovery is safe (e.g. tests, or an operator
+// override) - ordinary recovery should go through OnSuccess instead.
+func (rl *RateLimiter) RecoverRate() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refillRate = rl.baseRate
+}
@@ -0,0 +1,235 @@
+package gmail
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// googleErrorEnvelope mirrors the JSON error body returned by Google APIs, e.g.:
+//
+//	{"error": {"code": 403, "message": "...", "errors": [{"reason": "..."}],
+//	           "details": [{"@type": "...", "reason": "...", "retryDelay": "30s"}]}}
+type googleErrorEnvelope struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Reason  string `json:"reason"`
+			Domain  string `json:"domain"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Details []struct {
+			Type       string `json:"@type"`
+			Reason     string `json:"reason"`
+			RetryDelay string `json:"retryDelay"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Known rate-limit reasons reported by the Gmail API.
+const (
+	ReasonRateLimitExceeded     = "rateLimitExceeded"
+	ReasonUserRateLimitExceeded = "userRateLimitExceeded"
+	ReasonQuotaExceeded         = "quotaExceeded"
+)
+
+// rateLimitReasons are the lower-cased reason strings that indicate a rate-limit
+// or quota condition rather than, say, a permission error.
+var rateLimitReasons = map[string]bool{
+	"ratelimitexceeded":     true,
+	"userratelimitexceeded": true,
+	"quotaexceeded":         true,
+}
+
+// RateLimitError carries the parsed Google API error envelope for a 403/429
+// response that indicates a rate-limit or quota condition, following the
+// pattern go-github uses for its RateLimitError.
+type RateLimitError struct {
+	// StatusCode is the HTTP status code of the response (403 or 429).
+	StatusCode int
+	// Message is the human-readable message from the error envelope.
+	Message string
+	// Reason is the rate-limit reason as the server spelled it (e.g.
+	// rateLimitExceeded, or RATE_LIMIT_EXCEEDED from a details entry), or
+	// quotaExceeded when only the message text mentions an exceeded quota.
+	Reason string
+	// QuotaMetric is the quota metric named in the error message, if any
+	// (e.g. extracted from "Quota exceeded for quota metric 'Queries'").
+	QuotaMetric string
+	// RetryAfter is the parsed Retry-After duration, if the header was present.
+	RetryAfter time.Duration
+	// RateLimitHeaders holds any X-RateLimit-* response headers, keyed
+	// lower-case without the common prefix (e.g. "limit", "remaining",
+	// "reset").
+	RateLimitHeaders map[string]string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return "gmail: rate limit exceeded (" + e.Reason + ")"
+}
+
+// parseGoogleError parses a Google API JSON error body, returning nil if the
+// body does not look like a rate-limit/quota error.
+func parseGoogleError(body []byte, statusCode int) *RateLimitError {
+	var env googleErrorEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil
+	}
+
+	reason := ""
+	for _, e := range env.Error.Errors {
+		if rateLimitReasons[normalizeReason(e.Reason)] {
+			reason = e.Reason
+			break
+		}
+	}
+	if reason == "" {
+		for _, d := range env.Error.Details {
+			if rateLimitReasons[normalizeReason(d.Reason)] {
+				reason = d.Reason
+				break
+			}
+		}
+	}
+	if reason == "" && bytes.Contains(bytes.ToLower(body), []byte("quota exceeded")) {
+		reason = ReasonQuotaExceeded
+	}
+	if reason == "" {
+		return nil
+	}
+
+	rle := &RateLimitError{
+		StatusCode:  statusCode,
+		Message:     env.Error.Message,
+		Reason:      reason,
+		QuotaMetric: parseQuotaMetric(env.Error.Message),
+	}
+
+	// A RetryInfo detail (google.rpc.RetryInfo) carries a server-recommended
+	// backoff like "30s"; prefer it over any Retry-After header since it's
+	// the more specific of the two.
+	for _, d := range env.Error.Details {
+		if d.RetryDelay == "" {
+			continue
+		}
+		if delay, err := time.ParseDuration(d.RetryDelay); err == nil {
+			rle.RetryAfter = delay
+			break
+		}
+	}
+
+	return rle
+}
+
+// quotaMetricPattern extracts the quoted metric name from messages like
+// "Quota exceeded for quota metric 'Queries'".
+var quotaMetricPattern = regexp.MustCompile(`(?i)quota metric '([^']+)'`)
+
+func parseQuotaMetric(message string) string {
+	m := quotaMetricPattern.FindStringSubmatch(message)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// ParseRateLimitError parses an HTTP response body and headers into a
+// RateLimitError, returning nil if the response does not describe a
+// rate-limit/quota condition. It honors a Retry-After header (both
+// delta-seconds and HTTP-date forms) when the JSON body doesn't already
+// carry a more specific google.rpc.RetryInfo delay, and captures any
+// X-RateLimit-* headers the server included.
+func ParseRateLimitError(body []byte, statusCode int, header http.Header) *RateLimitError {
+	rle := parseGoogleError(body, statusCode)
+	if rle == nil {
+		return nil
+	}
+
+	if rle.RetryAfter == 0 {
+		if d, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+			rle.RetryAfter = d
+		}
+	}
+
+	for name := range header {
+		if !strings.HasPrefix(strings.ToLower(name), "x-ratelimit-") {
+			continue
+		}
+		if rle.RateLimitHeaders == nil {
+			rle.RateLimitHeaders = make(map[string]string)
+		}
+		key := strings.TrimPrefix(strings.ToLower(name), "x-ratelimit-")
+		rle.RateLimitHeaders[key] = header.Get(name)
+	}
+
+	return rle
+}
+
+func toLowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// normalizeReason lowercases a Google API error reason and strips
+// underscores, so "RATE_LIMIT_EXCEEDED" and "rateLimitExceeded" compare
+// equal against rateLimitReasons.
+func normalizeReason(s string) string {
+	return strings.ReplaceAll(toLowerASCII(s), "_", "")
+}
+
+// isRateLimitError reports whether body describes a rate-limit or quota
+// error. It is a thin backward-compatible wrapper around parseGoogleError,
+// falling back to a raw substring match when the body is not valid JSON
+// (or isn't a JSON object at all) so callers that pass malformed bodies
+// still get a sensible answer.
+func isRateLimitError(body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	if rle := parseGoogleError(body, 0); rle != nil {
+		return true
+	}
+	lower := bytes.ToLower(body)
+	for reason := range rateLimitReasons {
+		if bytes.Contains(lower, []byte(reason)) {
+			return true
+		}
+	}
+	return bytes.Contains(lower, []byte("quota exceeded"))
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, which may be
+// either delta-seconds ("120") or an HTTP-date. It returns zero and false
+// when the header is missing or unparseable.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := time.Parse(time.RFC1123, header); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
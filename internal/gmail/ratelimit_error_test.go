@@ -0,0 +1,188 @@
+package gmail
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseGoogleError(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+		wantNil    bool
+	}{
+		{
+			name: "rate limit exceeded",
+			body: `{"error": {"code": 403, "message": "Quota exceeded for quota metric 'Queries'",
+				"errors": [{"reason": "rateLimitExceeded"}]}}`,
+			wantReason: "rateLimitExceeded",
+		},
+		{
+			name:       "rate limit exceeded upper case via details",
+			body:       `{"error": {"code": 403, "details": [{"reason": "RATE_LIMIT_EXCEEDED"}]}}`,
+			wantReason: "RATE_LIMIT_EXCEEDED",
+		},
+		{
+			name:       "quota exceeded message only",
+			body:       `{"error": {"code": 403, "message": "Quota exceeded for quota metric 'Queries'"}}`,
+			wantReason: ReasonQuotaExceeded,
+		},
+		{
+			name:       "user rate limit exceeded",
+			body:       `{"error": {"code": 403, "errors": [{"reason": "userRateLimitExceeded"}]}}`,
+			wantReason: "userRateLimitExceeded",
+		},
+		{
+			name:    "permission denied is not a rate limit error",
+			body:    `{"error": {"code": 403, "message": "The caller does not have permission", "errors": [{"reason": "forbidden"}]}}`,
+			wantNil: true,
+		},
+		{
+			name:    "empty body",
+			body:    ``,
+			wantNil: true,
+		},
+		{
+			name: "RetryInfo detail with retryDelay",
+			body: `{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}],
+				"details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "30s"}]}}`,
+			wantReason: "rateLimitExceeded",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseGoogleError([]byte(tc.body), 0)
+			if tc.wantNil {
+				if got != nil {
+					t.Fatalf("parseGoogleError() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("parseGoogleError() = nil, want reason %q", tc.wantReason)
+			}
+			if got.Reason != tc.wantReason {
+				t.Errorf("Reason = %q, want %q", got.Reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestParseGoogleError_RetryInfoDelay(t *testing.T) {
+	body := []byte(`{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}],
+		"details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "30s"}]}}`)
+
+	got := parseGoogleError(body, 429)
+	if got == nil {
+		t.Fatal("parseGoogleError() = nil")
+	}
+	if got.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", got.RetryAfter)
+	}
+}
+
+func TestParseGoogleError_QuotaMetric(t *testing.T) {
+	body := []byte(`{"error": {"code": 403, "message": "Quota exceeded for quota metric 'Queries'",
+		"errors": [{"reason": "rateLimitExceeded"}]}}`)
+
+	got := parseGoogleError(body, 403)
+	if got == nil {
+		t.Fatal("parseGoogleError() = nil")
+	}
+	if got.QuotaMetric != "Queries" {
+		t.Errorf("QuotaMetric = %q, want %q", got.QuotaMetric, "Queries")
+	}
+}
+
+func TestParseRateLimitError_RetryAfterHeader(t *testing.T) {
+	body := []byte(`{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}]}}`)
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+
+	got := ParseRateLimitError(body, 429, header)
+	if got == nil {
+		t.Fatal("ParseRateLimitError() = nil")
+	}
+	if got.RetryAfter != 120*time.Second {
+		t.Errorf("RetryAfter = %v, want 120s", got.RetryAfter)
+	}
+}
+
+func TestParseRateLimitError_RetryInfoPreferredOverHeader(t *testing.T) {
+	body := []byte(`{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}],
+		"details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "5s"}]}}`)
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+
+	got := ParseRateLimitError(body, 429, header)
+	if got == nil {
+		t.Fatal("ParseRateLimitError() = nil")
+	}
+	if got.RetryAfter != 5*time.Second {
+		t.Errorf("RetryAfter = %v, want 5s (RetryInfo takes precedence)", got.RetryAfter)
+	}
+}
+
+func TestParseRateLimitError_MissingRetryAfterIsOptimistic(t *testing.T) {
+	// No Retry-After header and no RetryInfo detail: callers should treat
+	// this as "retry immediately with default backoff" rather than an error,
+	// so RetryAfter is simply left at zero.
+	body := []byte(`{"error": {"code": 403, "errors": [{"reason": "userRateLimitExceeded"}]}}`)
+
+	got := ParseRateLimitError(body, 403, http.Header{})
+	if got == nil {
+		t.Fatal("ParseRateLimitError() = nil")
+	}
+	if got.RetryAfter != 0 {
+		t.Errorf("RetryAfter = %v, want 0", got.RetryAfter)
+	}
+}
+
+func TestParseRateLimitError_XRateLimitHeaders(t *testing.T) {
+	body := []byte(`{"error": {"code": 429, "errors": [{"reason": "rateLimitExceeded"}]}}`)
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "250")
+	header.Set("X-RateLimit-Remaining", "0")
+
+	got := ParseRateLimitError(body, 429, header)
+	if got == nil {
+		t.Fatal("ParseRateLimitError() = nil")
+	}
+	if got.RateLimitHeaders["limit"] != "250" {
+		t.Errorf("RateLimitHeaders[limit] = %q, want %q", got.RateLimitHeaders["limit"], "250")
+	}
+	if got.RateLimitHeaders["remaining"] != "0" {
+		t.Errorf("RateLimitHeaders[remaining] = %q, want %q", got.RateLimitHeaders["remaining"], "0")
+	}
+}
+
+func TestParseRetryAfter_DeltaSeconds(t *testing.T) {
+	d, ok := parseRetryAfter("30", time.Now())
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if d != 30*time.Second {
+		t.Errorf("d = %v, want 30s", d)
+	}
+}
+
+func TestParseRetryAfter_HTTPDate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(1 * time.Minute)
+	d, ok := parseRetryAfter(future.Format(time.RFC1123), now)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if d < 59*time.Second || d > 61*time.Second {
+		t.Errorf("d = %v, want ~1m", d)
+	}
+}
+
+func TestParseRetryAfter_Missing(t *testing.T) {
+	if _, ok := parseRetryAfter("", time.Now()); ok {
+		t.Error("expected ok=false for missing header")
+	}
+}
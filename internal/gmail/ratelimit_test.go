@@ -367,7 +367,7 @@ func TestRateLimiter_Throttle_ExtendsBackoff(t *testing.T) {
 	}
 }
 
-func TestRateLimiter_AutoRecoverRate(t *testing.T) {
+func TestRateLimiter_NoAutoRecoverRate(t *testing.T) {
 	rl := newTestLimiter()
 
 	// Throttle for a short duration
@@ -376,14 +376,67 @@ func TestRateLimiter_AutoRecoverRate(t *testing.T) {
 	// Verify rate is reduced
 	assertRefillRate(t, rl, DefaultRefillRate*0.5, "after Throttle")
 
-	// Wait for throttle to expire (use generous margin for CI stability)
+	// Wait for the throttle window to expire (use generous margin for CI stability)
 	time.Sleep(100 * time.Millisecond)
 
-	// Call Available() which triggers refill and auto-recovery
+	// Available() triggers refillLocked, but refillLocked no longer snaps
+	// the rate back to full the instant the throttle window passes - that
+	// instant flip is exactly the oscillation AIMD replaces. Only OnSuccess,
+	// after probation, ramps the rate back up.
 	rl.Available()
 
-	// Verify rate is restored
-	assertRefillRate(t, rl, DefaultRefillRate, "after throttle expiry")
+	assertRefillRate(t, rl, DefaultRefillRate*0.5, "after throttle expiry, before OnSuccess")
+}
+
+func TestRateLimiter_OnSuccess_AdditiveIncreaseAfterProbation(t *testing.T) {
+	rl := newTestLimiter()
+	rl.SetAIMDParams(rl.minRate, DefaultRefillRate, 0.5, DefaultRefillRate/50, 20*time.Millisecond)
+
+	rl.Throttle(time.Millisecond)
+	assertRefillRate(t, rl, DefaultRefillRate*0.5, "after Throttle")
+
+	// Within probation, OnSuccess must not increase the rate yet.
+	rl.OnSuccess()
+	assertRefillRate(t, rl, DefaultRefillRate*0.5, "OnSuccess before probation elapsed")
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Past probation, each OnSuccess call additively nudges the rate up by
+	// one step rather than snapping straight back to full.
+	before := getRefillRate(rl)
+	rl.OnSuccess()
+	after := getRefillRate(rl)
+	if after <= before {
+		t.Errorf("OnSuccess() after probation did not increase rate: before=%v after=%v", before, after)
+	}
+	if after >= DefaultRefillRate {
+		t.Errorf("OnSuccess() jumped straight to full rate in one step: after=%v, want < %v", after, DefaultRefillRate)
+	}
+
+	// Repeated successes eventually recover the full rate, one step at a time.
+	for i := 0; i < 100 && getRefillRate(rl) < DefaultRefillRate; i++ {
+		rl.OnSuccess()
+	}
+	assertRefillRate(t, rl, DefaultRefillRate, "after enough additive steps")
+}
+
+func TestRateLimiter_OnSuccess_RepeatedThrottleExtendsProbation(t *testing.T) {
+	rl := newTestLimiter()
+	rl.SetAIMDParams(rl.minRate, DefaultRefillRate, 0.5, DefaultRefillRate/50, 30*time.Millisecond)
+
+	rl.Throttle(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	// A second Throttle within the probation window should reset the timer
+	// and - via the throttleEMA - stretch how long OnSuccess must wait,
+	// instead of letting it recover as if only one isolated 429 had happened.
+	rl.Throttle(time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond) // 20ms since the second Throttle, short interval observed
+	before := getRefillRate(rl)
+	rl.OnSuccess()
+	if got := getRefillRate(rl); got != before {
+		t.Errorf("OnSuccess() increased rate during a stretched probation window: before=%v after=%v", before, got)
+	}
 }
 
 func TestRateLimiter_Acquire_WaitsForThrottle(t *testing.T) {
@@ -0,0 +1,139 @@
+package gmail
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Backoff parameters for CallWithRetry. These match the Gmail-recommended
+// truncated exponential backoff: start at one second, double each attempt,
+// and cap at a minute so a sustained outage doesn't push retries out to
+// impractical delays.
+const (
+	RetryBaseDelay = time.Second
+	RetryMaxDelay  = 60 * time.Second
+	retryJitterPct = 25
+)
+
+// ServerError indicates the Gmail API returned a 5xx response, which
+// CallWithRetry treats the same as a rate-limit error: transient and worth
+// retrying with backoff, as opposed to a 4xx (other than 429) which usually
+// means the request itself is wrong and retrying won't help.
+type ServerError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *ServerError) Error() string { return e.Err.Error() }
+func (e *ServerError) Unwrap() error { return e.Err }
+
+// RetryableError wraps the error from a CallWithRetry attempt that is about
+// to be retried, carrying enough state (Attempt, Wait) for a caller's
+// SyncProgress.OnError to surface "retrying in Ns" instead of treating the
+// attempt as a terminal failure. CallWithRetry's own final error, once
+// attempts are exhausted, is the unwrapped cause rather than a
+// RetryableError, since at that point there is no further retry to report.
+type RetryableError struct {
+	Err     error
+	Attempt int
+	Wait    time.Duration
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// isRetryable reports whether err is a condition CallWithRetry should back
+// off and retry: a rate-limit/quota error (RateLimitError) or a server
+// error (ServerError, 5xx). Anything else is assumed permanent.
+func isRetryable(err error) bool {
+	var rle *RateLimitError
+	if errors.As(err, &rle) {
+		return true
+	}
+	var se *ServerError
+	return errors.As(err, &se)
+}
+
+// retryAfter returns the server-requested delay before the next attempt, if
+// err carries one (a RateLimitError's parsed Retry-After/RetryInfo), and
+// whether one was present.
+func retryAfter(err error) (time.Duration, bool) {
+	var rle *RateLimitError
+	if errors.As(err, &rle) && rle.RetryAfter > 0 {
+		return rle.RetryAfter, true
+	}
+	return 0, false
+}
+
+// backoffForAttempt returns the jittered delay to wait after the given
+// 1-based attempt has failed, when the server gave no explicit Retry-After:
+// RetryBaseDelay after attempt 1, doubled for each later attempt, capped at
+// RetryMaxDelay, with
+// +/-25% jitter so a fleet of clients retrying the same error don't all
+// hammer the API at the same instant.
+func backoffForAttempt(attempt int) time.Duration {
+	d := RetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d > RetryMaxDelay {
+			d = RetryMaxDelay
+			break
+		}
+	}
+	spread := float64(d) * float64(retryJitterPct) / 100
+	delta := (rand.Float64()*2 - 1) * spread
+	d = time.Duration(float64(d) + delta)
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// CallWithRetry invokes fn, retrying on a transient error (see isRetryable)
+// up to maxAttempts times with exponential backoff (honoring a
+// RateLimitError's Retry-After when present), or until ctx is done. onRetry,
+// if non-nil, is called before each wait with a RetryableError describing
+// the attempt that just failed and how long CallWithRetry will wait before
+// the next one - callers typically forward this straight to
+// SyncProgress.OnError so a UI can show "retrying in Ns".
+//
+// maxAttempts counts the initial try, so maxAttempts=1 never retries.
+// CallWithRetry returns the last error unwrapped (not a RetryableError) once
+// attempts are exhausted or the error isn't retryable, since at that point
+// there is nothing left to retry.
+func CallWithRetry(ctx context.Context, maxAttempts int, onRetry func(*RetryableError), fn func() error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		lastErr = fn()
+		if lastErr == nil {
+			return nil
+		}
+		if attempt == maxAttempts || !isRetryable(lastErr) {
+			return lastErr
+		}
+
+		wait, ok := retryAfter(lastErr)
+		if !ok {
+			wait = backoffForAttempt(attempt)
+		}
+
+		if onRetry != nil {
+			onRetry(&RetryableError{Err: lastErr, Attempt: attempt, Wait: wait})
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return lastErr
+}
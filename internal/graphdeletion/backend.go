@@ -0,0 +1,65 @@
+// Package graphdeletion implements deletion.Backend (internal/deletion)
+// against the Microsoft Graph API, deleting messages via
+// DELETE /me/messages/{id} individually or Graph's $batch endpoint for
+// bulk operations.
+package graphdeletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Client is the subset of a Microsoft Graph client Backend needs. It is
+// kept as an interface so this package carries no direct dependency on a
+// particular Graph SDK.
+type Client interface {
+	DeleteMessage(ctx context.Context, messageID string) error
+	BatchDeleteMessages(ctx context.Context, messageIDs []string) error
+}
+
+// NotFoundError reports that messageID returned a Graph 404.
+type NotFoundError struct {
+	MessageID string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("graphdeletion: message %s not found", e.MessageID)
+}
+
+// Backend adapts Client into a deletion.Backend. Graph moves a deleted
+// message into the mailbox's Deleted Items folder rather than offering a
+// separate recoverable-trash verb the way Gmail does, so TrashOne and
+// DeleteOne both issue the same DELETE call; a caller wanting Gmail-style
+// trash/permanent-delete separation would need to act on Deleted Items
+// directly, which this backend does not do on its own.
+type Backend struct {
+	client Client
+}
+
+// NewBackend adapts client into a deletion.Backend.
+func NewBackend(client Client) *Backend {
+	return &Backend{client: client}
+}
+
+// TrashOne deletes id, moving it to Deleted Items.
+func (b *Backend) TrashOne(ctx context.Context, id string) error {
+	return b.client.DeleteMessage(ctx, id)
+}
+
+// DeleteOne deletes id. Graph offers no separate permanent-delete verb
+// beyond this, so it behaves identically to TrashOne.
+func (b *Backend) DeleteOne(ctx context.Context, id string) error {
+	return b.client.DeleteMessage(ctx, id)
+}
+
+// BatchDelete deletes every id in ids via Graph's $batch endpoint.
+func (b *Backend) BatchDelete(ctx context.Context, ids []string) error {
+	return b.client.BatchDeleteMessages(ctx, ids)
+}
+
+// IsNotFound reports whether err indicates messageID returned a 404.
+func (b *Backend) IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
@@ -0,0 +1,274 @@
+// Package grpcserver exposes internal/query's Engine over gRPC, as a typed
+// alternative to internal/mcp's JSON-over-stdio tools for non-LLM clients
+// (indexers, dashboards, backup tools).
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/wesm/msgvault/api/msgvaultpb"
+	"github.com/wesm/msgvault/internal/query"
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// streamPageSize is how many messages Server fetches from the engine per
+// internal page while streaming SearchMessages/ListMessages. Unlike the MCP
+// tools' maxLimit clamp, streaming has no fixed cap on how many messages a
+// client can receive overall; this only bounds the work done per engine call.
+const streamPageSize = 500
+
+// Server implements pb.MsgVaultServiceServer over a query.Engine.
+type Server struct {
+	pb.UnimplementedMsgVaultServiceServer
+
+	engine query.Engine
+}
+
+// NewServer returns a Server backed by engine.
+func NewServer(engine query.Engine) *Server {
+	return &Server{engine: engine}
+}
+
+// Register registers srv as the MsgVaultService implementation on s.
+func Register(s *grpc.Server, srv *Server) {
+	pb.RegisterMsgVaultServiceServer(s, srv)
+}
+
+// SearchMessages streams every message matching req.Query, paging through
+// the engine internally so the client isn't limited to a fixed result cap.
+func (s *Server) SearchMessages(req *pb.SearchMessagesRequest, stream pb.MsgVaultService_SearchMessagesServer) error {
+	ctx := stream.Context()
+	q := search.Parse(req.GetQuery())
+
+	offset := int(req.GetOffset())
+	firstPage := true
+	for {
+		results, err := s.engine.SearchFast(ctx, q, query.MessageFilter{}, streamPageSize, offset)
+		if err != nil {
+			return status.Errorf(codes.Internal, "search failed: %v", err)
+		}
+
+		// Mirror the MCP search_messages fallback: if fast search's first
+		// page is empty and there's free text, try full FTS once.
+		if firstPage && len(results) == 0 && len(q.TextTerms) > 0 {
+			results, err = s.engine.Search(ctx, q, streamPageSize, offset)
+			if err != nil {
+				return status.Errorf(codes.Internal, "search failed: %v", err)
+			}
+		}
+		firstPage = false
+
+		for _, m := range results {
+			msg, err := toPBMessage(m)
+			if err != nil {
+				return status.Errorf(codes.Internal, "convert message: %v", err)
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+
+		if len(results) < streamPageSize {
+			return nil
+		}
+		offset += streamPageSize
+	}
+}
+
+// GetMessage returns a single message by ID.
+func (s *Server) GetMessage(ctx context.Context, req *pb.GetMessageRequest) (*pb.Message, error) {
+	m, err := s.engine.GetMessage(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "message not found: %v", err)
+	}
+	return toPBMessage(m)
+}
+
+// ListMessages streams messages matching req's filters, paging through the
+// engine internally so the client isn't limited to a fixed result cap.
+func (s *Server) ListMessages(req *pb.ListMessagesRequest, stream pb.MsgVaultService_ListMessagesServer) error {
+	ctx := stream.Context()
+
+	filter, err := filterFromRequest(req)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	offset := int(req.GetOffset())
+	for {
+		filter.Offset = offset
+		filter.Limit = streamPageSize
+
+		results, err := s.engine.ListMessages(ctx, filter)
+		if err != nil {
+			return status.Errorf(codes.Internal, "list failed: %v", err)
+		}
+
+		for _, m := range results {
+			msg, err := toPBMessage(m)
+			if err != nil {
+				return status.Errorf(codes.Internal, "convert message: %v", err)
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+
+		if len(results) < streamPageSize {
+			return nil
+		}
+		offset += streamPageSize
+	}
+}
+
+// filterFromRequest maps a ListMessagesRequest onto a query.MessageFilter,
+// the same way mcp/handlers.go's listMessages does.
+func filterFromRequest(req *pb.ListMessagesRequest) (query.MessageFilter, error) {
+	filter := query.MessageFilter{
+		Sender:              req.GetFrom(),
+		Recipient:           req.GetTo(),
+		Label:               req.GetLabel(),
+		WithAttachmentsOnly: req.GetHasAttachment(),
+		ThreadID:            req.GetThread(),
+		MimeType:            req.GetMimetype(),
+		AttachmentType:      req.GetAttachmentType(),
+		Mailbox:             req.GetIn(),
+	}
+
+	if v := req.GetAfter(); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return query.MessageFilter{}, fmt.Errorf("invalid after date %q: expected YYYY-MM-DD", v)
+		}
+		filter.After = &t
+	}
+	if v := req.GetBefore(); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return query.MessageFilter{}, fmt.Errorf("invalid before date %q: expected YYYY-MM-DD", v)
+		}
+		filter.Before = &t
+	}
+
+	if v := req.GetIs(); v != "" {
+		yes := true
+		switch v {
+		case "read":
+			filter.IsRead = &yes
+		case "unread":
+			filter.IsUnread = &yes
+		case "starred":
+			filter.IsStarred = &yes
+		case "replied":
+			filter.IsReplied = &yes
+		case "flagged":
+			filter.IsFlagged = &yes
+		}
+	}
+
+	return filter, nil
+}
+
+// GetStats returns the archive's overview stats and known accounts.
+func (s *Server) GetStats(ctx context.Context, _ *pb.GetStatsRequest) (*pb.StatsResponse, error) {
+	stats, err := s.engine.GetTotalStats(ctx, query.StatsOptions{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "stats failed: %v", err)
+	}
+
+	accounts, err := s.engine.ListAccounts(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "accounts failed: %v", err)
+	}
+
+	resp := &pb.StatsResponse{
+		TotalMessages:    stats.TotalMessages,
+		TotalSizeBytes:   stats.TotalSizeBytes,
+		TotalAttachments: stats.TotalAttachments,
+	}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, &pb.AccountSummary{
+			Email:        a.Email,
+			MessageCount: a.MessageCount,
+		})
+	}
+	return resp, nil
+}
+
+// Aggregate returns grouped statistics, dispatching on req.GroupBy the same
+// way the aggregate MCP tool does.
+func (s *Server) Aggregate(ctx context.Context, req *pb.AggregateRequest) (*pb.AggregateResponse, error) {
+	opts := query.AggregateOptions{
+		Limit: int(req.GetLimit()),
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 50
+	}
+
+	if v := req.GetAfter(); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid after date %q: expected YYYY-MM-DD", v)
+		}
+		opts.After = &t
+	}
+	if v := req.GetBefore(); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid before date %q: expected YYYY-MM-DD", v)
+		}
+		opts.Before = &t
+	}
+
+	var (
+		rows []query.AggregateRow
+		err  error
+	)
+	switch req.GetGroupBy() {
+	case "sender":
+		rows, err = s.engine.AggregateBySender(ctx, opts)
+	case "recipient":
+		rows, err = s.engine.AggregateByRecipient(ctx, opts)
+	case "domain":
+		rows, err = s.engine.AggregateByDomain(ctx, opts)
+	case "label":
+		rows, err = s.engine.AggregateByLabel(ctx, opts)
+	case "time":
+		rows, err = s.engine.AggregateByTime(ctx, opts)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid group_by: %s", req.GetGroupBy())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "aggregate failed: %v", err)
+	}
+
+	resp := &pb.AggregateResponse{}
+	for _, r := range rows {
+		resp.Rows = append(resp.Rows, &pb.AggregateRow{Key: r.Key, Count: r.Count})
+	}
+	return resp, nil
+}
+
+// toPBMessage converts a query.Message into its wire representation.
+func toPBMessage(m *query.Message) (*pb.Message, error) {
+	pbMsg := &pb.Message{
+		Id:             m.ID,
+		Subject:        m.Subject,
+		FromEmail:      m.FromEmail,
+		ToEmails:       m.ToEmails,
+		Labels:         m.Labels,
+		HasAttachments: len(m.Attachments) > 0,
+		ConversationId: m.ConversationID,
+	}
+	if !m.Date.IsZero() {
+		pbMsg.Date = timestamppb.New(m.Date)
+	}
+	return pbMsg, nil
+}
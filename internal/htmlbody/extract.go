@@ -0,0 +1,143 @@
+// Package htmlbody converts an HTML message body into plain text suitable
+// for full-text indexing: paragraphs reflow into blank-line-separated
+// blocks, list items get a "- " bullet, link text keeps its destination
+// URL alongside it, and <script>/<style> content and markup are stripped.
+//
+// This is a closer, index-oriented rendering than internal/mime.StripHTML,
+// which renders a quick preview for GetBodyText and intentionally drops
+// link URLs. Without preserving link text and list structure here, queries
+// like "project report" can't match HTML-only marketing mail whose actual
+// words live inside an <a> or <li> the preview stripper flattens away.
+package htmlbody
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags start a new paragraph-like block when closed.
+var blockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// skipTags are dropped entirely, including their content.
+var skipTags = map[string]bool{"script": true, "style": true, "head": true}
+
+// Extract renders htmlBody as plain text for search indexing.
+func Extract(htmlBody string) string {
+	z := html.NewTokenizer(strings.NewReader(htmlBody))
+	var buf strings.Builder
+	skipTag := ""
+
+	var inAnchor bool
+	var anchorHref string
+	var anchorText strings.Builder
+
+	write := func(s string) {
+		if inAnchor {
+			anchorText.WriteString(s)
+			return
+		}
+		buf.WriteString(s)
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		if skipTag != "" {
+			if tt == html.EndTagToken && tok.Data == skipTag {
+				skipTag = ""
+			}
+			continue
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name := strings.ToLower(tok.Data)
+			if skipTags[name] {
+				skipTag = name
+				continue
+			}
+			switch name {
+			case "br":
+				write("\n")
+			case "li":
+				write("\n- ")
+			case "a":
+				if tt == html.StartTagToken {
+					inAnchor = true
+					anchorText.Reset()
+					anchorHref = attrValue(tok, "href")
+				}
+			}
+		case html.EndTagToken:
+			name := strings.ToLower(tok.Data)
+			if name == "a" && inAnchor {
+				inAnchor = false
+				buf.WriteString(renderLink(strings.TrimSpace(anchorText.String()), anchorHref))
+				continue
+			}
+			if blockTags[name] {
+				write("\n\n")
+			}
+		case html.TextToken:
+			write(tok.Data)
+		}
+	}
+
+	return normalize(buf.String())
+}
+
+// attrValue returns the value of the named attribute on tok, or "".
+func attrValue(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// renderLink combines anchor text and its href so the URL stays
+// searchable even when the visible text is generic ("click here"). Bare
+// or self-describing links (href equal to or already present in the
+// text, or no href at all) are rendered as just the text.
+func renderLink(text, href string) string {
+	if href == "" || strings.Contains(text, href) {
+		return text
+	}
+	if text == "" {
+		return href
+	}
+	return text + " (" + href + ")"
+}
+
+var (
+	horizontalWhitespaceRe = regexp.MustCompile(`[ \t]{2,}`)
+	blankLineRunRe         = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalize collapses the raw text Extract accumulates into reflowed
+// plain text: non-breaking spaces become regular spaces, runs of
+// horizontal whitespace collapse per line, and more than one blank line
+// in a row collapses to exactly one.
+func normalize(s string) string {
+	s = strings.ReplaceAll(s, " ", " ")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalWhitespaceRe.ReplaceAllString(line, " "))
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLineRunRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,56 @@
+package htmlbody
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "paragraphs reflow with blank line",
+			input: "<p>First paragraph.</p><p>Second paragraph.</p>",
+			want:  "First paragraph.\n\nSecond paragraph.",
+		},
+		{
+			name:  "list items get a bullet",
+			input: "<ul><li>Milk</li><li>Eggs</li></ul>",
+			want:  "- Milk\n\n- Eggs",
+		},
+		{
+			name:  "link text keeps its URL",
+			input: `<p>See our <a href="https://example.com/report">quarterly report</a> for details.</p>`,
+			want:  "See our quarterly report (https://example.com/report) for details.",
+		},
+		{
+			name:  "bare link is not duplicated",
+			input: `<a href="https://example.com">https://example.com</a>`,
+			want:  "https://example.com",
+		},
+		{
+			name:  "script and style are stripped",
+			input: "<style>p{color:red}</style><script>alert(1)</script><p>Hello</p>",
+			want:  "Hello",
+		},
+		{
+			name:  "entities are decoded",
+			input: "<p>Caf&eacute; &amp; Bar</p>",
+			want:  "Café & Bar",
+		},
+		{
+			name:  "br becomes a line break",
+			input: "Line one<br>Line two",
+			want:  "Line one\nLine two",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Extract(tc.input)
+			if got != tc.want {
+				t.Errorf("Extract() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
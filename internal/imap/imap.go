@@ -0,0 +1,219 @@
+// Package imap implements a sync.Source backed by IMAP, so accounts that
+// don't speak Gmail's REST API (Fastmail, iCloud, self-hosted Dovecot, ...)
+// can be archived into the same SQLite vault.
+package imap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-imap/v2"
+	"github.com/emersion/go-imap/v2/imapclient"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/sync"
+)
+
+// Cursor is IMAP's analog of Gmail's HistoryID: the combination of a
+// folder's UIDVALIDITY (which changes if the server renumbers UIDs, forcing
+// a full resync) and the highest MODSEQ observed, used with CONDSTORE/
+// QRESYNC to fetch only messages changed since the last sync.
+type Cursor struct {
+	UIDValidity uint32
+	HighestModSeq uint64
+}
+
+// String encodes the cursor as "<uidvalidity>:<highestmodseq>" for storage
+// in store.Source.SyncCursor.
+func (c Cursor) String() string {
+	return fmt.Sprintf("%d:%d", c.UIDValidity, c.HighestModSeq)
+}
+
+// ParseCursor decodes a cursor previously produced by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return Cursor{}, fmt.Errorf("imap: malformed cursor %q", s)
+	}
+	uidValidity, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("imap: malformed cursor %q: %w", s, err)
+	}
+	highestModSeq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("imap: malformed cursor %q: %w", s, err)
+	}
+	return Cursor{UIDValidity: uint32(uidValidity), HighestModSeq: highestModSeq}, nil
+}
+
+// Config holds the connection settings for a Client.
+type Config struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	Mailbox  string // folder to sync, e.g. "INBOX"
+}
+
+// Client syncs a single IMAP mailbox via the sync.Source interface.
+type Client struct {
+	cfg  Config
+	conn *imapclient.Client
+}
+
+var _ sync.Source = (*Client)(nil)
+
+// Dial connects and authenticates to the IMAP server described by cfg.
+func Dial(ctx context.Context, cfg Config) (*Client, error) {
+	conn, err := imapclient.DialTLS(cfg.Addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("imap: dial %s: %w", cfg.Addr, err)
+	}
+	if err := conn.Login(cfg.Username, cfg.Password).Wait(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("imap: login: %w", err)
+	}
+	return &Client{cfg: cfg, conn: conn}, nil
+}
+
+// Identifier returns the store.Source identifier for this mailbox, used
+// with store.GetOrCreateSource("imap", identifier).
+func (c *Client) Identifier() string {
+	return fmt.Sprintf("%s@%s/%s", c.cfg.Username, c.cfg.Addr, c.cfg.Mailbox)
+}
+
+func (c *Client) Profile(ctx context.Context) (*sync.SourceProfile, error) {
+	mbox, err := c.conn.Select(c.cfg.Mailbox, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("imap: select %s: %w", c.cfg.Mailbox, err)
+	}
+	cursor := Cursor{UIDValidity: mbox.UIDValidity, HighestModSeq: mbox.HighestModSeq}
+	return &sync.SourceProfile{
+		Identifier:    c.Identifier(),
+		MessagesTotal: int64(mbox.NumMessages),
+		Cursor:        cursor.String(),
+	}, nil
+}
+
+func (c *Client) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	mailboxes, err := c.conn.List("", "*", nil).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("imap: list mailboxes: %w", err)
+	}
+	labels := make([]*gmail.Label, 0, len(mailboxes))
+	for _, m := range mailboxes {
+		labels = append(labels, &gmail.Label{ID: m.Mailbox, Name: m.Mailbox, Type: "user"})
+	}
+	return labels, nil
+}
+
+// ListMessages lists UIDs in the selected mailbox. IMAP has no server-side
+// full-text query analogous to Gmail's search syntax, so query is currently
+// ignored; callers needing filtering should do it after fetch.
+func (c *Client) ListMessages(ctx context.Context, query string, pageToken string) (*gmail.MessageListResponse, error) {
+	data, err := c.conn.UIDSearch(&imap.SearchCriteria{}, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("imap: search: %w", err)
+	}
+	ids := make([]gmail.MessageID, 0, len(data.AllUIDs()))
+	for _, uid := range data.AllUIDs() {
+		ids = append(ids, gmail.MessageID{ID: strconv.FormatUint(uint64(uid), 10)})
+	}
+	return &gmail.MessageListResponse{Messages: ids, ResultSizeEstimate: int64(len(ids))}, nil
+}
+
+// GetMessage fetches a message's raw RFC 5322 body by UID.
+func (c *Client) GetMessage(ctx context.Context, id string) (*gmail.RawMessage, error) {
+	uid, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("imap: invalid uid %q: %w", id, err)
+	}
+	seqSet := imap.UIDSetNum(imap.UID(uid))
+	fetchOpts := &imap.FetchOptions{BodySection: []*imap.FetchItemBodySection{{}}}
+	messages, err := c.conn.Fetch(seqSet, fetchOpts).Collect()
+	if err != nil {
+		return nil, fmt.Errorf("imap: fetch uid %d: %w", uid, err)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("imap: uid %d not found", uid)
+	}
+	msg := messages[0]
+	var raw []byte
+	for _, buf := range msg.BodySection {
+		raw = buf
+		break
+	}
+	return &gmail.RawMessage{ID: id, Raw: raw, SizeEstimate: int64(len(raw))}, nil
+}
+
+// History returns messages added since cursor's HighestModSeq, using
+// CONDSTORE's CHANGEDSINCE search modifier. If cursor's UIDValidity no
+// longer matches the mailbox's current UIDVALIDITY, the server has
+// renumbered UIDs out from under us and the caller must fall back to a
+// full sync.
+func (c *Client) History(ctx context.Context, cursorStr string, pageToken string) (*sync.SourceHistory, error) {
+	cursor, err := ParseCursor(cursorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	mbox, err := c.conn.Select(c.cfg.Mailbox, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("imap: select %s: %w", c.cfg.Mailbox, err)
+	}
+	if mbox.UIDValidity != cursor.UIDValidity {
+		return nil, sync.ErrHistoryExpired
+	}
+
+	criteria := &imap.SearchCriteria{
+		ModSeq: cursor.HighestModSeq,
+	}
+	data, err := c.conn.UIDSearch(criteria, nil).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("imap: changedsince search: %w", err)
+	}
+
+	var added []gmail.HistoryMessage
+	for _, uid := range data.AllUIDs() {
+		added = append(added, gmail.HistoryMessage{Message: gmail.MessageID{ID: strconv.FormatUint(uint64(uid), 10)}})
+	}
+
+	newCursor := Cursor{UIDValidity: mbox.UIDValidity, HighestModSeq: mbox.HighestModSeq}
+	return &sync.SourceHistory{
+		Records: []gmail.HistoryRecord{{MessagesAdded: added}},
+		Cursor:  newCursor.String(),
+	}, nil
+}
+
+// StoreDeletedFlag flags uid \Deleted in the mailbox without expunging it -
+// the first half of IMAP's two-step delete, used by
+// internal/imapdeletion.Backend as the recoverable "trash" side of a
+// deletion (the message stays present until Expunge removes it for good).
+func (c *Client) StoreDeletedFlag(ctx context.Context, uid uint32) error {
+	if _, err := c.conn.Select(c.cfg.Mailbox, nil).Wait(); err != nil {
+		return fmt.Errorf("imap: select %s: %w", c.cfg.Mailbox, err)
+	}
+	seqSet := imap.UIDSetNum(imap.UID(uid))
+	storeFlags := &imap.StoreFlags{Op: imap.StoreFlagsAdd, Flags: []imap.Flag{imap.FlagDeleted}}
+	if _, err := c.conn.Store(seqSet, storeFlags, nil).Collect(); err != nil {
+		return fmt.Errorf("imap: store \\Deleted on uid %d: %w", uid, err)
+	}
+	return nil
+}
+
+// Expunge permanently removes every message flagged \Deleted in the
+// mailbox, completing the second half of IMAP's two-step delete.
+func (c *Client) Expunge(ctx context.Context) error {
+	if _, err := c.conn.Select(c.cfg.Mailbox, nil).Wait(); err != nil {
+		return fmt.Errorf("imap: select %s: %w", c.cfg.Mailbox, err)
+	}
+	if _, err := c.conn.Expunge().Collect(); err != nil {
+		return fmt.Errorf("imap: expunge %s: %w", c.cfg.Mailbox, err)
+	}
+	return nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,59 @@
+package imap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/sync"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{UIDValidity: 42, HighestModSeq: 1001}
+	got, err := ParseCursor(c.String())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if got != c {
+		t.Errorf("ParseCursor(%q) = %+v, want %+v", c.String(), got, c)
+	}
+}
+
+func TestParseCursor_Malformed(t *testing.T) {
+	if _, err := ParseCursor("not-a-cursor"); err == nil {
+		t.Error("expected error for malformed cursor")
+	}
+}
+
+func TestMockIMAPSource_History(t *testing.T) {
+	mock := NewMockIMAPSource("user@example.com/INBOX")
+	profile, err := mock.Profile(context.Background())
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	baseCursor := profile.Cursor
+
+	mock.AddMessage("1", []byte("From: a@example.com\r\n\r\nhi"))
+	mock.AddMessage("2", []byte("From: b@example.com\r\n\r\nbye"))
+
+	hist, err := mock.History(context.Background(), baseCursor, "")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(hist.Records) != 1 || len(hist.Records[0].MessagesAdded) != 2 {
+		t.Fatalf("History() = %+v, want 2 messages added", hist)
+	}
+}
+
+func TestMockIMAPSource_HistoryExpiredOnUIDValidityChange(t *testing.T) {
+	mock := NewMockIMAPSource("user@example.com/INBOX")
+	profile, err := mock.Profile(context.Background())
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+
+	mock.UIDValidity++
+
+	if _, err := mock.History(context.Background(), profile.Cursor, ""); err != sync.ErrHistoryExpired {
+		t.Errorf("History() error = %v, want ErrHistoryExpired", err)
+	}
+}
@@ -0,0 +1,99 @@
+package imap
+
+import (
+	"context"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/sync"
+)
+
+// MockIMAPSource is an in-memory sync.Source for tests, mirroring the shape
+// of gmail.MockAPI: callers seed messages with AddMessage and drive
+// incremental sync behavior by bumping HighestModSeq/UIDValidity directly.
+type MockIMAPSource struct {
+	Identifier    string
+	UIDValidity   uint32
+	HighestModSeq uint64
+
+	messages map[string]*gmail.RawMessage
+	order    []string
+	sinceIdx map[string]uint64 // message ID -> modseq at which it was added
+}
+
+// NewMockIMAPSource creates an empty mock source for identifier.
+func NewMockIMAPSource(identifier string) *MockIMAPSource {
+	return &MockIMAPSource{
+		Identifier:    identifier,
+		UIDValidity:   1,
+		HighestModSeq: 1,
+		messages:      make(map[string]*gmail.RawMessage),
+		sinceIdx:      make(map[string]uint64),
+	}
+}
+
+// AddMessage appends a message with the given UID and raw MIME body,
+// bumping HighestModSeq so subsequent History calls pick it up.
+func (m *MockIMAPSource) AddMessage(uid string, raw []byte) {
+	m.HighestModSeq++
+	m.messages[uid] = &gmail.RawMessage{ID: uid, Raw: raw, SizeEstimate: int64(len(raw))}
+	m.order = append(m.order, uid)
+	m.sinceIdx[uid] = m.HighestModSeq
+}
+
+func (m *MockIMAPSource) Profile(ctx context.Context) (*sync.SourceProfile, error) {
+	cursor := Cursor{UIDValidity: m.UIDValidity, HighestModSeq: m.HighestModSeq}
+	return &sync.SourceProfile{
+		Identifier:    m.Identifier,
+		MessagesTotal: int64(len(m.messages)),
+		Cursor:        cursor.String(),
+	}, nil
+}
+
+func (m *MockIMAPSource) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	return []*gmail.Label{{ID: "INBOX", Name: "INBOX", Type: "system"}}, nil
+}
+
+func (m *MockIMAPSource) ListMessages(ctx context.Context, query string, pageToken string) (*gmail.MessageListResponse, error) {
+	ids := make([]gmail.MessageID, 0, len(m.order))
+	for _, uid := range m.order {
+		ids = append(ids, gmail.MessageID{ID: uid})
+	}
+	return &gmail.MessageListResponse{Messages: ids, ResultSizeEstimate: int64(len(ids))}, nil
+}
+
+func (m *MockIMAPSource) GetMessage(ctx context.Context, id string) (*gmail.RawMessage, error) {
+	raw, ok := m.messages[id]
+	if !ok {
+		return nil, &gmail.NotFoundError{Path: "/messages/" + id}
+	}
+	return raw, nil
+}
+
+func (m *MockIMAPSource) History(ctx context.Context, cursorStr string, pageToken string) (*sync.SourceHistory, error) {
+	cursor, err := ParseCursor(cursorStr)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.UIDValidity != m.UIDValidity {
+		return nil, sync.ErrHistoryExpired
+	}
+
+	var added []gmail.HistoryMessage
+	for _, uid := range m.order {
+		if m.sinceIdx[uid] > cursor.HighestModSeq {
+			added = append(added, gmail.HistoryMessage{Message: gmail.MessageID{ID: uid}})
+		}
+	}
+
+	newCursor := Cursor{UIDValidity: m.UIDValidity, HighestModSeq: m.HighestModSeq}
+	return &sync.SourceHistory{
+		Records: []gmail.HistoryRecord{{MessagesAdded: added}},
+		Cursor:  newCursor.String(),
+	}, nil
+}
+
+func (m *MockIMAPSource) Close() error {
+	return nil
+}
+
+var _ sync.Source = (*MockIMAPSource)(nil)
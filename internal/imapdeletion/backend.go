@@ -0,0 +1,93 @@
+// Package imapdeletion implements deletion.Backend (internal/deletion) for
+// IMAP mailboxes, flagging messages \Deleted and expunging them in batches
+// rather than relying on Gmail's separate trash/permanent-delete verbs.
+package imapdeletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Client is the subset of *imap.Client (internal/imap) Backend needs:
+// flagging a UID \Deleted, then expunging the mailbox to remove flagged
+// messages for good. Kept as an interface, rather than a direct dependency
+// on *imap.Client, so tests can substitute a fake without dialing a server.
+type Client interface {
+	StoreDeletedFlag(ctx context.Context, uid uint32) error
+	Expunge(ctx context.Context) error
+}
+
+// NotFoundError reports that a UID no longer exists in its mailbox, e.g.
+// because another client already expunged it.
+type NotFoundError struct {
+	UID uint32
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("imapdeletion: uid %d not found", e.UID)
+}
+
+// Backend adapts Client into a deletion.Backend. IMAP has no separate
+// trash/permanent-delete distinction the way Gmail does: TrashOne only
+// flags \Deleted (the message is hidden but still present until expunged),
+// while DeleteOne and BatchDelete flag and then expunge immediately.
+type Backend struct {
+	client Client
+}
+
+// NewBackend adapts client into a deletion.Backend.
+func NewBackend(client Client) *Backend {
+	return &Backend{client: client}
+}
+
+// TrashOne flags id \Deleted without expunging.
+func (b *Backend) TrashOne(ctx context.Context, id string) error {
+	uid, err := parseUID(id)
+	if err != nil {
+		return err
+	}
+	return b.client.StoreDeletedFlag(ctx, uid)
+}
+
+// DeleteOne flags id \Deleted and immediately expunges it.
+func (b *Backend) DeleteOne(ctx context.Context, id string) error {
+	uid, err := parseUID(id)
+	if err != nil {
+		return err
+	}
+	if err := b.client.StoreDeletedFlag(ctx, uid); err != nil {
+		return err
+	}
+	return b.client.Expunge(ctx)
+}
+
+// BatchDelete flags every id in ids \Deleted, then issues a single
+// expunge, matching IMAP's STORE-then-EXPUNGE bulk delete pattern.
+func (b *Backend) BatchDelete(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		uid, err := parseUID(id)
+		if err != nil {
+			return err
+		}
+		if err := b.client.StoreDeletedFlag(ctx, uid); err != nil {
+			return err
+		}
+	}
+	return b.client.Expunge(ctx)
+}
+
+// IsNotFound reports whether err indicates a UID was already gone.
+func (b *Backend) IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+func parseUID(id string) (uint32, error) {
+	uid, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("imapdeletion: invalid uid %q: %w", id, err)
+	}
+	return uint32(uid), nil
+}
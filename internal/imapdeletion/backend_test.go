@@ -0,0 +1,79 @@
+package imapdeletion
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeClient struct {
+	stored   []uint32
+	expunged int
+	storeErr error
+}
+
+func (f *fakeClient) StoreDeletedFlag(ctx context.Context, uid uint32) error {
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	f.stored = append(f.stored, uid)
+	return nil
+}
+
+func (f *fakeClient) Expunge(ctx context.Context) error {
+	f.expunged++
+	return nil
+}
+
+func TestBackend_DeleteOne(t *testing.T) {
+	client := &fakeClient{}
+	b := NewBackend(client)
+
+	if err := b.DeleteOne(context.Background(), "42"); err != nil {
+		t.Fatalf("DeleteOne: %v", err)
+	}
+	if len(client.stored) != 1 || client.stored[0] != 42 {
+		t.Errorf("stored = %v, want [42]", client.stored)
+	}
+	if client.expunged != 1 {
+		t.Errorf("expunged = %d, want 1", client.expunged)
+	}
+}
+
+func TestBackend_TrashOne_DoesNotExpunge(t *testing.T) {
+	client := &fakeClient{}
+	b := NewBackend(client)
+
+	if err := b.TrashOne(context.Background(), "7"); err != nil {
+		t.Fatalf("TrashOne: %v", err)
+	}
+	if client.expunged != 0 {
+		t.Errorf("expunged = %d, want 0 (TrashOne should not expunge)", client.expunged)
+	}
+}
+
+func TestBackend_BatchDelete_SingleExpunge(t *testing.T) {
+	client := &fakeClient{}
+	b := NewBackend(client)
+
+	if err := b.BatchDelete(context.Background(), []string{"1", "2", "3"}); err != nil {
+		t.Fatalf("BatchDelete: %v", err)
+	}
+	if len(client.stored) != 3 {
+		t.Errorf("stored = %v, want 3 uids", client.stored)
+	}
+	if client.expunged != 1 {
+		t.Errorf("expunged = %d, want 1 (one expunge for the whole batch)", client.expunged)
+	}
+}
+
+func TestBackend_IsNotFound(t *testing.T) {
+	b := NewBackend(&fakeClient{})
+
+	if !b.IsNotFound(&NotFoundError{UID: 5}) {
+		t.Error("IsNotFound(*NotFoundError) = false, want true")
+	}
+	if b.IsNotFound(errors.New("boom")) {
+		t.Error("IsNotFound(other error) = true, want false")
+	}
+}
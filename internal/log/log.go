@@ -0,0 +1,68 @@
+// Package log is a small level-based logger modeled on aerc's logging
+// package: Debugf/Infof/Warnf/Errorf each write one line to whatever
+// output Init last configured, and are no-ops -- their format arguments
+// are never even passed to Sprintf -- until Init has been called. Code
+// that logs liberally (e.g. internal/sync's per-record warnings) costs a
+// caller nothing unless that caller actually asked to see it.
+package log
+
+import (
+	"io"
+	"log"
+	"sync"
+)
+
+// Level selects which of Debugf/Infof/Warnf/Errorf are actually written;
+// a call is written only if its level is at or below the configured
+// Level (Error < Warn < Info < Debug).
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var (
+	mu     sync.Mutex
+	output *log.Logger
+	level  Level
+)
+
+// Init redirects log output to w at the given level. Before Init is
+// called, output is nil and every Debugf/Infof/Warnf/Errorf call is a
+// no-op, so callers that never want logging (most tests, short-lived CLI
+// commands) simply never call it.
+func Init(w io.Writer, lvl Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = log.New(w, "", log.LstdFlags)
+	level = lvl
+}
+
+func logf(lvl Level, prefix, format string, args ...any) {
+	mu.Lock()
+	l, cur := output, level
+	mu.Unlock()
+	if l == nil || lvl > cur {
+		return
+	}
+	l.Printf(prefix+": "+format, args...)
+}
+
+// Debugf logs a fine-grained, per-record message (e.g. one line per
+// history record). No-op below LevelDebug.
+func Debugf(format string, args ...any) { logf(LevelDebug, "DEBUG", format, args...) }
+
+// Infof logs a routine milestone (e.g. "sync complete"). No-op below
+// LevelInfo.
+func Infof(format string, args ...any) { logf(LevelInfo, "INFO", format, args...) }
+
+// Warnf logs a recoverable problem that doesn't abort the caller (e.g. one
+// message in a batch failed to fetch). No-op below LevelWarn.
+func Warnf(format string, args ...any) { logf(LevelWarn, "WARN", format, args...) }
+
+// Errorf logs a problem serious enough that the caller is about to return
+// an error. Always written once Init has been called.
+func Errorf(format string, args ...any) { logf(LevelError, "ERROR", format, args...) }
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// countingStringer counts how many times it's been formatted, so a test can
+// tell whether logf actually reached Printf (which is the point at which a
+// %s/%v verb would call String()) without relying on Go's eager evaluation
+// of Infof's own arguments, which happens at the call site regardless of
+// what logf does with them.
+type countingStringer struct{ calls *int }
+
+func (c countingStringer) String() string {
+	*c.calls++
+	return "boom"
+}
+
+func TestNoOpBeforeInit(t *testing.T) {
+	mu.Lock()
+	output = nil
+	mu.Unlock()
+
+	calls := 0
+	Infof("boom %s", countingStringer{calls: &calls})
+	if calls != 0 {
+		t.Errorf("Infof formatted its arguments before Init was called")
+	}
+}
+
+func TestInitWritesAtOrBelowLevel(t *testing.T) {
+	var buf bytes.Buffer
+	Init(&buf, LevelWarn)
+
+	Errorf("err %d", 1)
+	Warnf("warn %d", 2)
+	Infof("info %d", 3)
+	Debugf("debug %d", 4)
+
+	out := buf.String()
+	if !strings.Contains(out, "ERROR: err 1") {
+		t.Errorf("missing error line, got %q", out)
+	}
+	if !strings.Contains(out, "WARN: warn 2") {
+		t.Errorf("missing warn line, got %q", out)
+	}
+	if strings.Contains(out, "info 3") || strings.Contains(out, "debug 4") {
+		t.Errorf("info/debug should be suppressed at LevelWarn, got %q", out)
+	}
+}
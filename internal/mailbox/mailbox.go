@@ -0,0 +1,20 @@
+// Package mailbox imports and exports messages between msgvault's store and
+// standard on-disk mail formats (Maildir, mbox), using the upstream
+// github.com/emersion/go-maildir and github.com/emersion/go-mbox libraries
+// rather than hand-rolled format parsing. It's the dataset-style counterpart
+// to internal/export's streaming writers: import populates the same
+// messages/message_bodies/message_raw/participants/message_recipients/
+// attachments/labels tables that tools/devdata/dataset.CopySubset copies,
+// and export reverses the trip, giving users a way to move mail in and out
+// of msgvault without the Python ingestion pipeline (and interop with
+// aerc/mutt/notmuch, which read and write these formats natively).
+package mailbox
+
+// Result summarizes one import or export run.
+type Result struct {
+	// Count is the number of messages imported or exported.
+	Count int
+	// Skipped counts messages that were found but not written, e.g. a
+	// Maildir key already imported from another subfolder.
+	Skipped int
+}
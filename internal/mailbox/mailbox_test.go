@@ -0,0 +1,80 @@
+package mailbox
+
+import (
+	"reflect"
+	"testing"
+
+	maildir "github.com/emersion/go-maildir"
+)
+
+func TestLabelsToFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   maildir.Flags
+	}{
+		{"read, no other state", []string{"INBOX"}, maildir.Flags{maildir.FlagSeen}},
+		{"unread", []string{"INBOX", "UNREAD"}, nil},
+		{"starred and trashed", []string{"STARRED", "TRASH"}, maildir.Flags{maildir.FlagFlagged, maildir.FlagSeen, maildir.FlagTrashed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelsToFlags(tt.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("labelsToFlags(%v) = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagsToLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags maildir.Flags
+		want  []string
+	}{
+		{"seen only", maildir.Flags{maildir.FlagSeen}, nil},
+		{"unseen", nil, []string{"UNREAD"}},
+		{"flagged and trashed", maildir.Flags{maildir.FlagSeen, maildir.FlagFlagged, maildir.FlagTrashed}, []string{"STARRED", "TRASH"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := flagsToLabels(tt.flags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flagsToLabels(%v) = %v, want %v", tt.flags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelFolders(t *testing.T) {
+	got := labelFolders([]string{"INBOX", "UNREAD", "Work/Clients", "Friends"})
+	want := []string{".Work.Clients", ".Friends"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("labelFolders = %v, want %v", got, want)
+	}
+}
+
+func TestEnvelopeSender(t *testing.T) {
+	tests := map[string]string{
+		"Jane Doe <jane@example.com>": "jane@example.com",
+		"jane@example.com":            "jane@example.com",
+		"  jane@example.com  ":        "jane@example.com",
+	}
+	for in, want := range tests {
+		if got := envelopeSender(in); got != want {
+			t.Errorf("envelopeSender(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFilenameStem(t *testing.T) {
+	tests := map[string]string{
+		"/home/user/archive.mbox": "archive",
+		"inbox.mbox":              "inbox",
+		"noext":                   "noext",
+	}
+	for in, want := range tests {
+		if got := filenameStem(in); got != want {
+			t.Errorf("filenameStem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package mailbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	maildir "github.com/emersion/go-maildir"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// ImportMaildir walks a Maildir directory tree (its own cur/new plus any
+// "."-prefixed label subfolders, Maildir++ style) using the maildir
+// library and ingests every message into st as a new, non-Gmail source
+// identified by sourceEmail. A message present under more than one
+// subfolder is only inserted once, the first time its key is seen:
+// ImportMaildir tracks the keys it has inserted, and a later copy (and the
+// label of its subfolder) is skipped and counted in Result.Skipped.
+func ImportMaildir(ctx context.Context, st *store.Store, dir, sourceEmail string) (*Result, error) {
+	source, err := st.GetOrCreateSource("maildir", sourceEmail)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox: import maildir: get or create source: %w", err)
+	}
+
+	res := &Result{}
+	seen := map[string]bool{}
+
+	if err := importMaildirFolder(st, source.ID, maildir.Dir(dir), "", seen, res); err != nil {
+		return res, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return res, fmt.Errorf("mailbox: import maildir: read %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		label := strings.ReplaceAll(strings.TrimPrefix(e.Name(), "."), ".", "/")
+		sub := maildir.Dir(filepath.Join(dir, e.Name()))
+		if err := importMaildirFolder(st, source.ID, sub, label, seen, res); err != nil {
+			return res, fmt.Errorf("mailbox: import maildir: folder %s: %w", e.Name(), err)
+		}
+	}
+
+	return res, nil
+}
+
+func importMaildirFolder(st *store.Store, sourceID int64, d maildir.Dir, folderLabel string, seen map[string]bool, res *Result) error {
+	keys, err := d.Keys()
+	if err != nil {
+		return fmt.Errorf("mailbox: list %s: %w", d, err)
+	}
+
+	for _, key := range keys {
+		flags, err := d.Flags(key)
+		if err != nil {
+			return fmt.Errorf("mailbox: flags %s: %w", key, err)
+		}
+
+		labels := flagsToLabels(flags)
+		if folderLabel != "" {
+			labels = append(labels, folderLabel)
+		}
+
+		if seen[key] {
+			res.Skipped++
+			continue
+		}
+		seen[key] = true
+
+		r, err := d.Open(key)
+		if err != nil {
+			return fmt.Errorf("mailbox: open %s: %w", key, err)
+		}
+		raw, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("mailbox: read %s: %w", key, err)
+		}
+
+		if err := st.InsertImportedMessage(sourceID, key, raw, labels); err != nil {
+			return fmt.Errorf("mailbox: insert %s: %w", key, err)
+		}
+		res.Count++
+	}
+	return nil
+}
+
+// ExportMaildir streams cursor's messages into a Maildir tree at dir,
+// creating it if necessary, writing every message at the top level and
+// hardlinking it (via os.Link in linkMessage, falling back to a copy) into a "."
+// subfolder per non-system label it carries.
+func ExportMaildir(ctx context.Context, cursor store.ExportCursor, dir string) (*Result, error) {
+	top := maildir.Dir(dir)
+	if err := top.Init(); err != nil {
+		return nil, fmt.Errorf("mailbox: export maildir: init %s: %w", dir, err)
+	}
+
+	res := &Result{}
+	folders := map[string]maildir.Dir{}
+
+	for cursor.Next() {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		m, raw, _, err := cursor.Message()
+		if err != nil {
+			return res, fmt.Errorf("mailbox: export maildir: read message: %w", err)
+		}
+
+		key, w, err := top.Create(labelsToFlags(m.Labels))
+		if err != nil {
+			return res, fmt.Errorf("mailbox: export maildir: create: %w", err)
+		}
+		if _, err := io.Copy(w, raw); err != nil {
+			w.Close()
+			return res, fmt.Errorf("mailbox: export maildir: write %s: %w", m.SourceMessageID, err)
+		}
+		if err := w.Close(); err != nil {
+			return res, fmt.Errorf("mailbox: export maildir: close %s: %w", m.SourceMessageID, err)
+		}
+
+		for _, folder := range labelFolders(m.Labels) {
+			fd, ok := folders[folder]
+			if !ok {
+				fd = maildir.Dir(filepath.Join(dir, folder))
+				if err := fd.Init(); err != nil {
+					return res, fmt.Errorf("mailbox: export maildir: init folder %s: %w", folder, err)
+				}
+				folders[folder] = fd
+			}
+			if err := linkMessage(top, key, fd); err != nil {
+				return res, fmt.Errorf("mailbox: export maildir: link %s into %s: %w", key, folder, err)
+			}
+		}
+
+		res.Count++
+	}
+	if err := cursor.Err(); err != nil {
+		return res, fmt.Errorf("mailbox: export maildir: cursor: %w", err)
+	}
+	return res, nil
+}
+
+// linkMessage hardlinks the file backing key in src into dst, falling back
+// to a byte-for-byte copy on filesystems that don't support hardlinks.
+func linkMessage(src maildir.Dir, key string, dst maildir.Dir) error {
+	srcPath, err := src.Filename(key)
+	if err != nil {
+		return err
+	}
+	dstPath := filepath.Join(string(dst), "cur", filepath.Base(srcPath))
+
+	if err := os.Link(srcPath, dstPath); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// maildirSystemLabels are Gmail labels represented as Maildir flags or the
+// top-level mailbox rather than as their own subfolder.
+var maildirSystemLabels = map[string]bool{
+	"INBOX": true, "SENT": true, "DRAFT": true, "SPAM": true,
+	"TRASH": true, "UNREAD": true, "STARRED": true, "IMPORTANT": true, "CHAT": true,
+}
+
+func isSystemLabel(label string) bool {
+	return maildirSystemLabels[label] || strings.HasPrefix(label, "CATEGORY_")
+}
+
+// labelFolders returns the "." subfolder name (Maildir++ convention, "/"
+// nesting flattened to ".") for every non-system label in labels.
+func labelFolders(labels []string) []string {
+	var folders []string
+	for _, l := range labels {
+		if isSystemLabel(l) {
+			continue
+		}
+		folders = append(folders, "."+strings.ReplaceAll(l, "/", "."))
+	}
+	return folders
+}
+
+// labelsToFlags maps Gmail-style labels to Maildir info flags.
+func labelsToFlags(labels []string) maildir.Flags {
+	flagged, seen, trashed := false, true, false
+	for _, l := range labels {
+		switch l {
+		case "STARRED":
+			flagged = true
+		case "UNREAD":
+			seen = false
+		case "TRASH":
+			trashed = true
+		}
+	}
+
+	var flags maildir.Flags
+	if flagged {
+		flags = append(flags, maildir.FlagFlagged)
+	}
+	if seen {
+		flags = append(flags, maildir.FlagSeen)
+	}
+	if trashed {
+		flags = append(flags, maildir.FlagTrashed)
+	}
+	return flags
+}
+
+// flagsToLabels reverses labelsToFlags, recovering the Gmail-style
+// pseudo-labels implied by a message's Maildir info flags.
+func flagsToLabels(flags maildir.Flags) []string {
+	var labels []string
+	has := func(f maildir.Flag) bool {
+		for _, g := range flags {
+			if g == f {
+				return true
+			}
+		}
+		return false
+	}
+	if !has(maildir.FlagSeen) {
+		labels = append(labels, "UNREAD")
+	}
+	if has(maildir.FlagFlagged) {
+		labels = append(labels, "STARRED")
+	}
+	if has(maildir.FlagTrashed) {
+		labels = append(labels, "TRASH")
+	}
+	return labels
+}
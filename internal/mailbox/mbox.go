@@ -0,0 +1,119 @@
+package mailbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	mbox "github.com/emersion/go-mbox"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// ImportMbox reads every message out of the mbox file at path using the
+// mbox library and ingests it into st as a new, non-Gmail source
+// identified by sourceEmail. mbox carries no label information, so every
+// imported message starts out with no labels.
+func ImportMbox(ctx context.Context, st *store.Store, path, sourceEmail string) (*Result, error) {
+	source, err := st.GetOrCreateSource("mbox", sourceEmail)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox: import mbox: get or create source: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox: import mbox: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	res := &Result{}
+	r := mbox.NewReader(f)
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		mr, err := r.NextMessage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("mailbox: import mbox: next message: %w", err)
+		}
+
+		raw, err := io.ReadAll(mr)
+		if err != nil {
+			return res, fmt.Errorf("mailbox: import mbox: read message %d: %w", i, err)
+		}
+
+		key := fmt.Sprintf("%s-%d", filenameStem(path), i)
+		if err := st.InsertImportedMessage(source.ID, key, raw, nil); err != nil {
+			return res, fmt.Errorf("mailbox: import mbox: insert message %d: %w", i, err)
+		}
+		res.Count++
+	}
+
+	return res, nil
+}
+
+// ExportMbox streams cursor's messages to w in mbox format using the mbox
+// library, synthesizing each "From " envelope line from the message's
+// From header and sent date.
+func ExportMbox(ctx context.Context, cursor store.ExportCursor, w io.Writer) (*Result, error) {
+	mw := mbox.NewWriter(w)
+
+	res := &Result{}
+	for cursor.Next() {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		m, raw, _, err := cursor.Message()
+		if err != nil {
+			return res, fmt.Errorf("mailbox: export mbox: read message: %w", err)
+		}
+
+		mw2, err := mw.CreateMessage(envelopeSender(m.From), m.Date)
+		if err != nil {
+			return res, fmt.Errorf("mailbox: export mbox: create message %s: %w", m.SourceMessageID, err)
+		}
+		if _, err := io.Copy(mw2, raw); err != nil {
+			return res, fmt.Errorf("mailbox: export mbox: write message %s: %w", m.SourceMessageID, err)
+		}
+
+		res.Count++
+	}
+	if err := cursor.Err(); err != nil {
+		return res, fmt.Errorf("mailbox: export mbox: cursor: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return res, fmt.Errorf("mailbox: export mbox: close: %w", err)
+	}
+	return res, nil
+}
+
+// envelopeSender extracts a bare address suitable for the mbox "From "
+// line from a header value that may be "Name <addr>" or just "addr".
+func envelopeSender(from string) string {
+	if i := strings.LastIndexByte(from, '<'); i >= 0 {
+		addr := from[i+1:]
+		addr = strings.TrimSuffix(addr, ">")
+		return strings.TrimSpace(addr)
+	}
+	return strings.TrimSpace(from)
+}
+
+// filenameStem returns path's base name with its extension removed, used
+// as a stable-ish prefix for synthesized per-message import keys.
+func filenameStem(path string) string {
+	base := path
+	if i := strings.LastIndexByte(base, '/'); i >= 0 {
+		base = base[i+1:]
+	}
+	if i := strings.LastIndexByte(base, '.'); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
@@ -0,0 +1,221 @@
+// Package maildir implements a sync.Source backed by a local Maildir
+// directory (cur/new/tmp, per D. J. Bernstein's format), so mail already
+// archived to disk - by fetchmail, an offlineimap mirror, or a mail
+// server's own on-disk store - can be pulled into the vault without a
+// network round trip.
+package maildir
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/sync"
+)
+
+// subdirs are the three directories every Maildir must contain.
+var subdirs = []string{"cur", "new", "tmp"}
+
+// Cursor is Maildir's analog of Gmail's HistoryID: messages are delivered
+// as whole immutable files, so the newest mtime seen so far is enough to
+// ask "what's new since last time" without any server-side state.
+type Cursor struct {
+	HighWaterMark int64 // Unix nanoseconds
+}
+
+// String encodes the cursor for storage in store.Source.SyncCursor.
+func (c Cursor) String() string {
+	return strconv.FormatInt(c.HighWaterMark, 10)
+}
+
+// ParseCursor decodes a cursor previously produced by Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("maildir: malformed cursor %q: %w", s, err)
+	}
+	return Cursor{HighWaterMark: n}, nil
+}
+
+// Config holds the location of the Maildir to sync.
+type Config struct {
+	Dir string // path containing cur/, new/, and tmp/
+}
+
+// Client syncs a single Maildir via the sync.Source interface.
+type Client struct {
+	cfg Config
+}
+
+var _ sync.Source = (*Client)(nil)
+
+// Open validates that cfg.Dir looks like a Maildir (has cur/, new/, and
+// tmp/ subdirectories) and returns a Client for it.
+func Open(cfg Config) (*Client, error) {
+	for _, sub := range subdirs {
+		info, err := os.Stat(filepath.Join(cfg.Dir, sub))
+		if err != nil {
+			return nil, fmt.Errorf("maildir: %s: %w", cfg.Dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("maildir: %s is not a directory", filepath.Join(cfg.Dir, sub))
+		}
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// Identifier returns the store.Source identifier for this Maildir, used
+// with store.GetOrCreateSource("maildir", identifier).
+func (c *Client) Identifier() string {
+	return c.cfg.Dir
+}
+
+// entry is one message file found in cur/ or new/.
+type entry struct {
+	id      string // unique token before ":2," (or the whole name if absent)
+	path    string
+	modTime int64 // Unix nanoseconds
+}
+
+// scan walks cur/ and new/ (never tmp/, which holds in-progress deliveries)
+// and returns every message file, in no particular order.
+func (c *Client) scan() ([]entry, error) {
+	var entries []entry
+	for _, sub := range []string{"cur", "new"} {
+		dir := filepath.Join(c.cfg.Dir, sub)
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("maildir: read %s: %w", dir, err)
+		}
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				return nil, fmt.Errorf("maildir: stat %s: %w", f.Name(), err)
+			}
+			id, _, _ := strings.Cut(f.Name(), ":2,")
+			entries = append(entries, entry{
+				id:      id,
+				path:    filepath.Join(dir, f.Name()),
+				modTime: info.ModTime().UnixNano(),
+			})
+		}
+	}
+	return entries, nil
+}
+
+func (c *Client) find(id string) (entry, bool, error) {
+	entries, err := c.scan()
+	if err != nil {
+		return entry{}, false, err
+	}
+	for _, e := range entries {
+		if e.id == id {
+			return e, true, nil
+		}
+	}
+	return entry{}, false, nil
+}
+
+func (c *Client) Profile(ctx context.Context) (*sync.SourceProfile, error) {
+	entries, err := c.scan()
+	if err != nil {
+		return nil, err
+	}
+	var highWaterMark int64
+	for _, e := range entries {
+		if e.modTime > highWaterMark {
+			highWaterMark = e.modTime
+		}
+	}
+	return &sync.SourceProfile{
+		Identifier:    c.Identifier(),
+		MessagesTotal: int64(len(entries)),
+		Cursor:        Cursor{HighWaterMark: highWaterMark}.String(),
+	}, nil
+}
+
+// ListLabels returns a single "INBOX" label, since a plain Maildir has no
+// concept of folders; Maildir++ subfolders aren't scanned.
+func (c *Client) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	return []*gmail.Label{{ID: "INBOX", Name: "INBOX", Type: "system"}}, nil
+}
+
+// ListMessages lists every message currently on disk. Maildir has no
+// server-side query syntax analogous to Gmail's, so query is ignored, and
+// since a directory listing isn't paginated, pageToken is always ignored
+// too and the result is never split across pages.
+func (c *Client) ListMessages(ctx context.Context, query string, pageToken string) (*gmail.MessageListResponse, error) {
+	entries, err := c.scan()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]gmail.MessageID, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, gmail.MessageID{ID: e.id})
+	}
+	return &gmail.MessageListResponse{Messages: ids, ResultSizeEstimate: int64(len(ids))}, nil
+}
+
+// GetMessage reads a message's raw RFC 5322 bytes off disk by its id.
+func (c *Client) GetMessage(ctx context.Context, id string) (*gmail.RawMessage, error) {
+	e, found, err := c.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("maildir: message %q not found", id)
+	}
+	raw, err := os.ReadFile(e.path)
+	if err != nil {
+		return nil, fmt.Errorf("maildir: read %s: %w", e.path, err)
+	}
+	return &gmail.RawMessage{ID: id, Raw: raw, SizeEstimate: int64(len(raw))}, nil
+}
+
+// History returns messages delivered since cursor's high-water mark.
+// Maildir files are write-once, so "changed since" only ever means
+// "added since" - there is no analog of Gmail's MessagesDeleted or label
+// changes.
+func (c *Client) History(ctx context.Context, cursorStr string, pageToken string) (*sync.SourceHistory, error) {
+	cursor, err := ParseCursor(cursorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	var added []gmail.HistoryMessage
+	highWaterMark := cursor.HighWaterMark
+	for _, e := range entries {
+		if e.modTime > cursor.HighWaterMark {
+			added = append(added, gmail.HistoryMessage{Message: gmail.MessageID{ID: e.id}})
+		}
+		if e.modTime > highWaterMark {
+			highWaterMark = e.modTime
+		}
+	}
+
+	var records []gmail.HistoryRecord
+	if len(added) > 0 {
+		records = []gmail.HistoryRecord{{MessagesAdded: added}}
+	}
+	return &sync.SourceHistory{
+		Records: records,
+		Cursor:  Cursor{HighWaterMark: highWaterMark}.String(),
+	}, nil
+}
+
+// Close is a no-op; Client holds no open resources.
+func (c *Client) Close() error {
+	return nil
+}
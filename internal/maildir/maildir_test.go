@@ -0,0 +1,115 @@
+package maildir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestMaildir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range subdirs {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			t.Fatalf("mkdir %s: %v", sub, err)
+		}
+	}
+	return dir
+}
+
+func writeMessage(t *testing.T, dir, sub, name string, body []byte, mtime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, sub, name)
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{HighWaterMark: 1234567890}
+	got, err := ParseCursor(c.String())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if got != c {
+		t.Errorf("ParseCursor(%q) = %+v, want %+v", c.String(), got, c)
+	}
+}
+
+func TestParseCursor_Malformed(t *testing.T) {
+	if _, err := ParseCursor("not-a-cursor"); err == nil {
+		t.Error("expected error for malformed cursor")
+	}
+}
+
+func TestOpen_RejectsMissingSubdir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "cur"), 0o700); err != nil {
+		t.Fatalf("mkdir cur: %v", err)
+	}
+	if _, err := Open(Config{Dir: dir}); err == nil {
+		t.Error("expected error for Maildir missing new/ and tmp/")
+	}
+}
+
+func TestClient_ListAndGetMessages(t *testing.T) {
+	dir := newTestMaildir(t)
+	base := time.Now().Add(-time.Hour)
+	writeMessage(t, dir, "new", "1000.a.host", []byte("From: a@example.com\r\n\r\nhi"), base)
+	writeMessage(t, dir, "cur", "1001.b.host:2,S", []byte("From: b@example.com\r\n\r\nbye"), base.Add(time.Second))
+
+	c, err := Open(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	list, err := c.ListMessages(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	if len(list.Messages) != 2 {
+		t.Fatalf("ListMessages() = %d messages, want 2", len(list.Messages))
+	}
+
+	msg, err := c.GetMessage(context.Background(), "1001.b.host")
+	if err != nil {
+		t.Fatalf("GetMessage: %v", err)
+	}
+	if string(msg.Raw) != "From: b@example.com\r\n\r\nbye" {
+		t.Errorf("GetMessage() raw = %q", msg.Raw)
+	}
+}
+
+func TestClient_HistoryReturnsOnlyMessagesAfterCursor(t *testing.T) {
+	dir := newTestMaildir(t)
+	base := time.Now().Add(-time.Hour)
+	writeMessage(t, dir, "new", "1000.a.host", []byte("old"), base)
+
+	c, err := Open(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	profile, err := c.Profile(context.Background())
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	baseCursor := profile.Cursor
+
+	writeMessage(t, dir, "new", "2000.b.host", []byte("new"), base.Add(time.Minute))
+
+	hist, err := c.History(context.Background(), baseCursor, "")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(hist.Records) != 1 || len(hist.Records[0].MessagesAdded) != 1 {
+		t.Fatalf("History() = %+v, want 1 message added", hist)
+	}
+	if hist.Records[0].MessagesAdded[0].Message.ID != "2000.b.host" {
+		t.Errorf("History() added = %+v, want 2000.b.host", hist.Records[0].MessagesAdded[0])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -32,7 +33,7 @@ func (h *handlers) searchMessages(ctx context.Context, req mcp.CallToolRequest)
 	q := search.Parse(queryStr)
 
 	// Try fast search first (metadata only), fall back to full FTS.
-	results, err := h.engine.SearchFast(ctx, q, query.MessageFilter{}, limit, offset)
+	results, err := h.engine.SearchFast(ctx, q, triageFilterFromQuery(q), limit, offset)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -45,7 +46,105 @@ func (h *handlers) searchMessages(ctx context.Context, req mcp.CallToolRequest)
 		}
 	}
 
-	return jsonResult(results)
+	return jsonResult(withSnippets(results, q.TextTerms))
+}
+
+// searchResult adds a Snippet to a query.Message in search responses, so
+// callers can see why a message matched without fetching the full body.
+type searchResult struct {
+	*query.Message
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// withSnippets pairs each result with a snippet of its extracted body text
+// (query.Message.TextBody, populated via internal/htmlbody for HTML-only
+// messages) centered on the first matching term.
+func withSnippets(results []*query.Message, terms []string) []searchResult {
+	out := make([]searchResult, len(results))
+	for i, m := range results {
+		out[i] = searchResult{Message: m, Snippet: snippet(m.TextBody, terms)}
+	}
+	return out
+}
+
+// snippetRadius is how many characters of context snippet keeps on each
+// side of the first matching term.
+const snippetRadius = 80
+
+// snippet returns a short excerpt of body around the first occurrence of
+// any term in terms, with the match wrapped in **markers**. If nothing
+// matches (or there are no terms to match), it returns a leading excerpt
+// instead of nothing, so the caller still gets a preview.
+func snippet(body string, terms []string) string {
+	if body == "" {
+		return ""
+	}
+
+	lower := strings.ToLower(body)
+	matchIdx, matchLen := -1, 0
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		if idx := strings.Index(lower, strings.ToLower(term)); idx != -1 && (matchIdx == -1 || idx < matchIdx) {
+			matchIdx, matchLen = idx, len(term)
+		}
+	}
+	if matchIdx == -1 {
+		return truncate(body, snippetRadius*2)
+	}
+
+	start := matchIdx - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := matchIdx + matchLen + snippetRadius
+	if end > len(body) {
+		end = len(body)
+	}
+
+	excerpt := body[start:matchIdx] + "**" + body[matchIdx:matchIdx+matchLen] + "**" + body[matchIdx+matchLen:end]
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(body) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// truncate returns the first n bytes of s, appending "…" if it was cut short.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+// triageFilterFromQuery maps the mail-triage operators search.Parse
+// understands (is:, thread:, header:, mimetype:, attachment_type:, in:)
+// onto a query.MessageFilter, so SearchFast can push them down to SQL
+// instead of every caller re-parsing q by hand.
+func triageFilterFromQuery(q *search.Query) query.MessageFilter {
+	filter := query.MessageFilter{
+		ThreadID:  q.ThreadID,
+		Headers:   q.Headers,
+		IsRead:    q.IsRead,
+		IsUnread:  q.IsUnread,
+		IsStarred: q.IsStarred,
+		IsReplied: q.IsReplied,
+		IsFlagged: q.IsFlagged,
+	}
+	if len(q.MimeTypes) > 0 {
+		filter.MimeType = q.MimeTypes[0]
+	}
+	if len(q.AttachmentTypes) > 0 {
+		filter.AttachmentType = q.AttachmentTypes[0]
+	}
+	if len(q.Mailboxes) > 0 {
+		filter.Mailbox = q.Mailboxes[0]
+	}
+	return filter
 }
 
 func (h *handlers) getMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -101,6 +200,33 @@ func (h *handlers) listMessages(ctx context.Context, req mcp.CallToolRequest) (*
 		}
 		filter.Before = &t
 	}
+	if v, ok := args["is"].(string); ok && v != "" {
+		yes := true
+		switch v {
+		case "read":
+			filter.IsRead = &yes
+		case "unread":
+			filter.IsUnread = &yes
+		case "starred":
+			filter.IsStarred = &yes
+		case "replied":
+			filter.IsReplied = &yes
+		case "flagged":
+			filter.IsFlagged = &yes
+		}
+	}
+	if v, ok := args["thread"].(string); ok && v != "" {
+		filter.ThreadID = v
+	}
+	if v, ok := args["mimetype"].(string); ok && v != "" {
+		filter.MimeType = v
+	}
+	if v, ok := args["attachment_type"].(string); ok && v != "" {
+		filter.AttachmentType = v
+	}
+	if v, ok := args["in"].(string); ok && v != "" {
+		filter.Mailbox = v
+	}
 
 	results, err := h.engine.ListMessages(ctx, filter)
 	if err != nil {
@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/wesm/msgvault/internal/metrics"
+)
+
+// WithMetricsRegistry wires every tool call into reg: a "tool"/"outcome"
+// labeled call counter and a "tool" labeled latency histogram. A nil or
+// never-configured reg leaves instrumentTool's wrapping in place but every
+// metric call inside it a no-op, so this is safe to omit entirely.
+func WithMetricsRegistry(reg *metrics.Registry) ServeOption {
+	return func(c *serveConfig) { c.metricsRegistry = reg }
+}
+
+// toolHandlerFunc matches mcp-go's server.ToolHandlerFunc structurally, so
+// instrumentTool can wrap any of handlers' methods without importing that
+// type by name.
+type toolHandlerFunc func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// instrumentTool wraps next so every call increments a
+// msgvault_mcp_tool_calls_total{tool,outcome} counter and observes
+// msgvault_mcp_tool_duration_seconds{tool}. outcome is "error" if next
+// returns a non-nil error or a result with IsError set, "ok" otherwise.
+func instrumentTool(name string, next toolHandlerFunc, reg *metrics.Registry) toolHandlerFunc {
+	calls := reg.Counter("msgvault_mcp_tool_calls_total", "MCP tool calls, by tool and outcome.").WithLabelNames("tool", "outcome")
+	latency := reg.Histogram("msgvault_mcp_tool_duration_seconds", "MCP tool call latency, by tool.", metrics.DefaultLatencyBuckets).WithLabelNames("tool")
+
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, req)
+		latency.Observe(time.Since(start).Seconds(), name)
+
+		outcome := "ok"
+		if err != nil || (result != nil && result.IsError) {
+			outcome = "error"
+		}
+		calls.Inc(name, outcome)
+
+		return result, err
+	}
+}
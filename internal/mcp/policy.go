@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Policy gates mutating MCP tools (apply_label, remove_label, trash_message,
+// mark_read, bulk_delete_by_query). Every mutating handler calls Authorize
+// before building a plan, and checks DryRun to decide whether that plan is
+// ever eligible for confirm_operation to execute.
+type Policy interface {
+	// Authorize returns nil if op may proceed against messageCount messages,
+	// or an error explaining why not (surfaced directly to the MCP client).
+	Authorize(op string, messageCount int) error
+	// DryRun reports whether plans built under this policy can ever be
+	// executed via confirm_operation. DryRun policies return true, so
+	// confirm_operation rejects them with a clear "preview only" error
+	// instead of silently doing nothing.
+	DryRun() bool
+}
+
+// ReadOnly is the default Policy: it rejects every mutation outright. This
+// matches every tool's WithReadOnlyHintAnnotation(true) default from before
+// write tools existed - an operator must opt in to mutation explicitly.
+type ReadOnly struct{}
+
+func (ReadOnly) Authorize(op string, messageCount int) error {
+	return fmt.Errorf("%s is disabled: the server is running with the read-only policy (configure --mcp-policy to allow mutations)", op)
+}
+
+func (ReadOnly) DryRun() bool { return false }
+
+// DryRunPolicy authorizes every mutating call so its plan can be inspected,
+// but confirm_operation always refuses to execute a DryRunPolicy plan. Use
+// this to let an LLM see exactly what a bulk operation would touch before
+// an operator switches to AllowPolicy.
+type DryRunPolicy struct{}
+
+func (DryRunPolicy) Authorize(op string, messageCount int) error { return nil }
+
+func (DryRunPolicy) DryRun() bool { return true }
+
+// AllowPolicy authorizes mutations up to MaxMessages messages per call, and
+// lets confirm_operation execute the resulting plan. A zero MaxMessages
+// means unlimited.
+type AllowPolicy struct {
+	MaxMessages int
+}
+
+func (p AllowPolicy) Authorize(op string, messageCount int) error {
+	if p.MaxMessages > 0 && messageCount > p.MaxMessages {
+		return fmt.Errorf("%s would affect %d messages, which exceeds the configured cap of %d per call", op, messageCount, p.MaxMessages)
+	}
+	return nil
+}
+
+func (AllowPolicy) DryRun() bool { return false }
+
+// WithPolicy selects the Policy a server's mutating tools run under.
+// Defaults to ReadOnly.
+func WithPolicy(p Policy) ServeOption {
+	return func(c *serveConfig) { c.policy = p }
+}
+
+// plan describes a pending mutation awaiting confirm_operation.
+type plan struct {
+	Op         string   `json:"op"`
+	MessageIDs []string `json:"message_ids"`
+	LabelID    string   `json:"label_id,omitempty"`
+	CreatedAt  time.Time
+	execute    func(ctx context.Context) (any, error)
+}
+
+// confirmationStore holds plans built by mutating tool calls until
+// confirm_operation claims them by token, or confirmationTTL passes.
+type confirmationStore struct {
+	mu    sync.Mutex
+	plans map[string]*plan
+}
+
+const confirmationTTL = 5 * time.Minute
+
+func newConfirmationStore() *confirmationStore {
+	return &confirmationStore{plans: make(map[string]*plan)}
+}
+
+// stage registers p under a freshly generated token and returns it.
+func (s *confirmationStore) stage(p *plan) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("generate confirmation token: %w", err)
+	}
+	p.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	s.plans[token] = p
+	s.mu.Unlock()
+	return token, nil
+}
+
+// claim removes and returns the plan staged under token, if any and not yet
+// expired.
+func (s *confirmationStore) claim(token string) (*plan, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.plans[token]
+	if !ok {
+		return nil, false
+	}
+	delete(s.plans, token)
+	if time.Since(p.CreatedAt) > confirmationTTL {
+		return nil, false
+	}
+	return p, true
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerPrompts adds parameterized prompt templates that expand into a
+// ready-to-run tool sequence, so a client can offer "Summarize this
+// thread" as a one-click action instead of the user having to know which
+// tools to call and in what order.
+func registerPrompts(s *server.MCPServer) {
+	s.AddPrompt(
+		mcp.NewPrompt("summarize_thread",
+			mcp.WithPromptDescription("Summarize every message in an email thread."),
+			mcp.WithArgument("thread_id",
+				mcp.ArgumentDescription("The thread ID to summarize"),
+				mcp.RequiredArgument(),
+			),
+		),
+		summarizeThreadPrompt,
+	)
+
+	s.AddPrompt(
+		mcp.NewPrompt("find_action_items",
+			mcp.WithPromptDescription("Find action items and open questions addressed to the account owner within a date range."),
+			mcp.WithArgument("after",
+				mcp.ArgumentDescription("Only messages after this date (YYYY-MM-DD)"),
+			),
+			mcp.WithArgument("before",
+				mcp.ArgumentDescription("Only messages before this date (YYYY-MM-DD)"),
+			),
+		),
+		findActionItemsPrompt,
+	)
+
+	s.AddPrompt(
+		mcp.NewPrompt("weekly_digest",
+			mcp.WithPromptDescription("Summarize the week's mail: top senders, volume, and anything flagged or starred."),
+			mcp.WithArgument("after",
+				mcp.ArgumentDescription("Start of the week (YYYY-MM-DD)"),
+				mcp.RequiredArgument(),
+			),
+			mcp.WithArgument("before",
+				mcp.ArgumentDescription("End of the week (YYYY-MM-DD)"),
+				mcp.RequiredArgument(),
+			),
+		),
+		weeklyDigestPrompt,
+	)
+}
+
+func summarizeThreadPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	threadID := req.Params.Arguments["thread_id"]
+	if threadID == "" {
+		return nil, fmt.Errorf("thread_id argument is required")
+	}
+
+	return mcp.NewGetPromptResult(
+		"Summarize a thread",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf(
+				"Call get_message (or list_messages with thread=%q) to fetch every message in this "+
+					"thread, then write a short summary: who's involved, what's being decided or asked, "+
+					"and how it was resolved if it was.", threadID,
+			))),
+		},
+	), nil
+}
+
+func findActionItemsPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	after := req.Params.Arguments["after"]
+	before := req.Params.Arguments["before"]
+
+	rangeDesc := "all time"
+	switch {
+	case after != "" && before != "":
+		rangeDesc = fmt.Sprintf("%s to %s", after, before)
+	case after != "":
+		rangeDesc = fmt.Sprintf("since %s", after)
+	case before != "":
+		rangeDesc = fmt.Sprintf("before %s", before)
+	}
+
+	return mcp.NewGetPromptResult(
+		"Find action items",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf(
+				"Call search_messages with a query like \"is:unread\" or \"is:flagged\", restricted to %s "+
+					"(add after:/before: terms to the query), then list every message that asks a question, "+
+					"requests a decision, or assigns a task, along with who it's waiting on.", rangeDesc,
+			))),
+		},
+	), nil
+}
+
+func weeklyDigestPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	after := req.Params.Arguments["after"]
+	before := req.Params.Arguments["before"]
+	if after == "" || before == "" {
+		return nil, fmt.Errorf("after and before arguments are required")
+	}
+
+	return mcp.NewGetPromptResult(
+		"Weekly digest",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf(
+				"Call get_stats for an overview, then aggregate with group_by=\"sender\" and "+
+					"group_by=\"time\" restricted to after=%s and before=%s. Also call list_messages "+
+					"with is=\"starred\" and is=\"flagged\" for the same range. Summarize the week: "+
+					"total volume, top senders, and anything starred or flagged.", after, before,
+			))),
+		},
+	), nil
+}
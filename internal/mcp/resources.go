@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/wesm/msgvault/internal/query"
+)
+
+// recentMessagesResourceLimit bounds the static msgvault://message/recent
+// listing registered at startup. Unlike the tools, which query live,
+// resources/list in mcp-go is served from the set of resources a server
+// registered up front - there's no hook for a client's list request to
+// trigger a fresh query. So "recent messages" here is a snapshot taken
+// when the server starts, not a live cursor-paginated feed; a client that
+// wants current results should call the search_messages/list_messages
+// tools instead.
+const recentMessagesResourceLimit = 50
+
+// registerResources adds the message/thread/attachment resource templates
+// and a static listing of recent messages to s, so MCP clients can browse
+// and drag archive content into context instead of only calling tools.
+func registerResources(s *server.MCPServer, engine query.Engine) {
+	rh := &resourceHandlers{engine: engine}
+
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"msgvault://message/{id}",
+			"Email message",
+			mcp.WithTemplateDescription("A single email message by ID, as JSON."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		rh.readMessage,
+	)
+
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"msgvault://thread/{id}",
+			"Email thread",
+			mcp.WithTemplateDescription("Every message in a thread, ordered by date, as JSON."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		rh.readThread,
+	)
+
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"msgvault://attachment/{id}",
+			"Email attachment",
+			mcp.WithTemplateDescription("An attachment's content, by attachment ID."),
+		),
+		rh.readAttachment,
+	)
+
+	ctx := context.Background()
+	recent, err := engine.ListMessages(ctx, query.MessageFilter{Limit: recentMessagesResourceLimit})
+	if err != nil {
+		return // best-effort: an empty recent listing beats failing server startup
+	}
+	for _, msg := range recent {
+		uri := fmt.Sprintf("msgvault://message/%d", msg.ID)
+		resource := mcp.NewResource(uri, msg.Subject,
+			mcp.WithResourceDescription(fmt.Sprintf("From %s, %s", msg.FromEmail, msg.Date.Format("2006-01-02"))),
+			mcp.WithMIMEType("application/json"),
+		)
+		s.AddResource(resource, rh.readMessage)
+	}
+}
+
+type resourceHandlers struct {
+	engine query.Engine
+}
+
+func (rh *resourceHandlers) readMessage(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id, err := resourceID(req.Params.URI, "msgvault://message/")
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := rh.engine.GetMessage(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("message %d not found: %w", id, err)
+	}
+
+	return textJSONResource(req.Params.URI, msg)
+}
+
+func (rh *resourceHandlers) readThread(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	threadID, err := resourceSuffix(req.Params.URI, "msgvault://thread/")
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := rh.engine.ListMessages(ctx, query.MessageFilter{ThreadID: threadID})
+	if err != nil {
+		return nil, fmt.Errorf("thread %s: %w", threadID, err)
+	}
+
+	return textJSONResource(req.Params.URI, messages)
+}
+
+// readAttachment returns an attachment's MimeType and Base64Data, as
+// Engine.GetAttachment reports them. The SQLite, DuckDB and Postgres engines
+// read only the attachments row and leave Base64Data empty, so with them the
+// resource carries the MIME type and an empty blob.
+func (rh *resourceHandlers) readAttachment(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id, err := resourceID(req.Params.URI, "msgvault://attachment/")
+	if err != nil {
+		return nil, err
+	}
+
+	att, err := rh.engine.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("attachment %d not found: %w", id, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.BlobResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: att.MimeType,
+			Blob:     att.Base64Data,
+		},
+	}, nil
+}
+
+// resourceID parses the trailing {id} segment of a msgvault:// URI as an
+// int64, the ID type every engine lookup (GetMessage, GetAttachment) uses.
+func resourceID(uri, prefix string) (int64, error) {
+	suffix, err := resourceSuffix(uri, prefix)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if _, err := fmt.Sscanf(suffix, "%d", &id); err != nil {
+		return 0, fmt.Errorf("invalid resource URI %q: want %s<id>", uri, prefix)
+	}
+	return id, nil
+}
+
+func resourceSuffix(uri, prefix string) (string, error) {
+	if len(uri) <= len(prefix) || uri[:len(prefix)] != prefix {
+		return "", fmt.Errorf("invalid resource URI %q: want prefix %s", uri, prefix)
+	}
+	return uri[len(prefix):], nil
+}
+
+func textJSONResource(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal resource: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -2,34 +2,179 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/metrics"
 	"github.com/wesm/msgvault/internal/query"
 )
 
-// Serve creates an MCP server with email archive tools and serves over stdio.
-// It blocks until stdin is closed or the context is cancelled.
-func Serve(ctx context.Context, engine query.Engine) error {
+// newServer builds the *server.MCPServer with every archive tool
+// registered, shared by Serve (stdio) and ServeHTTP (HTTP/SSE) so both
+// transports expose the exact same tool set. Each tool is wrapped with
+// instrumentTool so reg (which may be nil) sees every call, regardless of
+// transport. Mutating tools (apply_label, remove_label, trash_message,
+// mark_read, bulk_delete_by_query, confirm_operation) are only registered
+// when cfg.gmailAPI is set - without a Gmail client there's nothing for
+// confirm_operation to execute against.
+func newServer(engine query.Engine, cfg *serveConfig) *server.MCPServer {
 	s := server.NewMCPServer(
 		"msgvault",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(true),
 	)
 
+	reg := cfg.metricsRegistry
 	h := &handlers{engine: engine}
 
-	s.AddTool(searchMessagesTool(), h.searchMessages)
-	s.AddTool(getMessageTool(), h.getMessage)
-	s.AddTool(listMessagesTool(), h.listMessages)
-	s.AddTool(getStatsTool(), h.getStats)
-	s.AddTool(aggregateTool(), h.aggregate)
+	s.AddTool(searchMessagesTool(), instrumentTool("search_messages", h.searchMessages, reg))
+	s.AddTool(getMessageTool(), instrumentTool("get_message", h.getMessage, reg))
+	s.AddTool(listMessagesTool(), instrumentTool("list_messages", h.listMessages, reg))
+	s.AddTool(getStatsTool(), instrumentTool("get_stats", h.getStats, reg))
+	s.AddTool(aggregateTool(), instrumentTool("aggregate", h.aggregate, reg))
 
-	stdio := server.NewStdioServer(s)
+	if cfg.gmailAPI != nil {
+		policy := cfg.policy
+		if policy == nil {
+			policy = ReadOnly{}
+		}
+		wh := &writeHandlers{
+			engine:        engine,
+			gmailAPI:      cfg.gmailAPI,
+			limiter:       cfg.limiter,
+			policy:        policy,
+			confirmations: newConfirmationStore(),
+		}
+
+		s.AddTool(applyLabelTool(), instrumentTool("apply_label", wh.applyLabel, reg))
+		s.AddTool(removeLabelTool(), instrumentTool("remove_label", wh.removeLabel, reg))
+		s.AddTool(trashMessageTool(), instrumentTool("trash_message", wh.trashMessage, reg))
+		s.AddTool(markReadTool(), instrumentTool("mark_read", wh.markRead, reg))
+		s.AddTool(bulkDeleteByQueryTool(), instrumentTool("bulk_delete_by_query", wh.bulkDeleteByQuery, reg))
+		s.AddTool(confirmOperationTool(), instrumentTool("confirm_operation", wh.confirmOperation, reg))
+	}
+
+	registerResources(s, engine)
+	registerPrompts(s)
+
+	return s
+}
+
+// Serve creates an MCP server with email archive tools and serves over
+// stdio. It blocks until stdin is closed or the context is cancelled.
+func Serve(ctx context.Context, engine query.Engine, opts ...ServeOption) error {
+	cfg := &serveConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	stdio := server.NewStdioServer(newServer(engine, cfg))
 	return stdio.Listen(ctx, os.Stdin, os.Stdout)
 }
 
+// ServeOption configures Serve and ServeHTTP.
+type ServeOption func(*serveConfig)
+
+type serveConfig struct {
+	bearerToken     string
+	logRequests     bool
+	metricsRegistry *metrics.Registry
+	gmailAPI        gmail.API
+	limiter         *gmail.RateLimiter
+	policy          Policy
+}
+
+// WithBearerToken requires every request to carry an
+// "Authorization: Bearer <token>" header matching token, rejecting any
+// request that doesn't with 401 Unauthorized. With no token configured (the
+// default), ServeHTTP performs no authentication at all. ServeHTTP-only;
+// Serve has no notion of per-request auth over stdio.
+func WithBearerToken(token string) ServeOption {
+	return func(c *serveConfig) { c.bearerToken = token }
+}
+
+// WithRequestLogging logs each request's method and path as it arrives.
+// ServeHTTP-only.
+func WithRequestLogging(enabled bool) ServeOption {
+	return func(c *serveConfig) { c.logRequests = enabled }
+}
+
+// ServeHTTP mounts the same tool set Serve registers over stdio onto
+// server.NewStreamableHTTPServer instead, so remote agents, IDE
+// integrations, and other non-subprocess clients can share one archive over
+// HTTP/SSE. It blocks until ctx is cancelled, then shuts the HTTP server
+// down gracefully, and returns ctx.Err() once shutdown completes.
+func ServeHTTP(ctx context.Context, engine query.Engine, addr string, opts ...ServeOption) error {
+	cfg := &serveConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	streamable := server.NewStreamableHTTPServer(newServer(engine, cfg))
+
+	var handler http.Handler = streamable
+	if cfg.logRequests {
+		handler = logRequestsMiddleware(handler)
+	}
+	if cfg.bearerToken != "" {
+		handler = requireBearerToken(cfg.bearerToken, handler)
+	}
+
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("mcp: shutdown http server: %w", err)
+		}
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("mcp: serve http: %w", err)
+		}
+		return nil
+	}
+}
+
+// requireBearerToken rejects any request whose Authorization header isn't
+// exactly "Bearer <token>" with 401, before it reaches next.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	want := "Bearer " + token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != want {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logRequestsMiddleware logs each request's method and path before passing
+// it to next.
+func logRequestsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("mcp: %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func searchMessagesTool() mcp.Tool {
 	return mcp.NewTool("search_messages",
 		mcp.WithDescription("Search emails using Gmail-like query syntax. Supports from:, to:, subject:, label:, has:attachment, before:, after:, and free text."),
@@ -0,0 +1,301 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/query"
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// unreadLabelID is Gmail's well-known system label for unread state.
+// Marking a message read/unread is a ModifyMessageLabels call against this
+// label, same as any user label.
+const unreadLabelID = "UNREAD"
+
+// writeHandlers backs the mutating MCP tools (apply_label, remove_label,
+// trash_message, mark_read, bulk_delete_by_query) and confirm_operation.
+// Every mutating handler resolves the affected messages, asks policy to
+// authorize the call, and stages a plan rather than executing immediately -
+// confirm_operation is the only path that ever calls plan.execute.
+type writeHandlers struct {
+	engine        query.Engine
+	gmailAPI      gmail.API
+	limiter       *gmail.RateLimiter
+	policy        Policy
+	confirmations *confirmationStore
+}
+
+// WithGmailAPI wires a Gmail client into the mutating MCP tools. Without one
+// configured, newServer registers no mutating tools at all: there would be
+// nothing for confirm_operation to execute against.
+func WithGmailAPI(api gmail.API) ServeOption {
+	return func(c *serveConfig) { c.gmailAPI = api }
+}
+
+// WithRateLimiter shares rl's quota accounting across the mutating MCP tools
+// and whatever sync/deletion work is already running against the same
+// account, so an LLM-driven bulk_delete_by_query can't starve a concurrent
+// sync of its quota. A nil limiter (the default) means mutating calls aren't
+// rate limited at this layer.
+func WithRateLimiter(rl *gmail.RateLimiter) ServeOption {
+	return func(c *serveConfig) { c.limiter = rl }
+}
+
+// acquire waits on h.limiter for op, or returns nil at once when no
+// limiter is configured.
+func (h *writeHandlers) acquire(ctx context.Context, op gmail.Operation) error {
+	if h.limiter == nil {
+		return nil
+	}
+	return h.limiter.Acquire(ctx, op)
+}
+
+func applyLabelTool() mcp.Tool {
+	return mcp.NewTool("apply_label",
+		mcp.WithDescription("Add a label to a message. Returns a confirmation token; call confirm_operation with it to actually apply the change."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Message ID")),
+		mcp.WithString("label_id", mcp.Required(), mcp.Description("Gmail label ID to add (e.g. \"IMPORTANT\" or a user label's ID)")),
+	)
+}
+
+func removeLabelTool() mcp.Tool {
+	return mcp.NewTool("remove_label",
+		mcp.WithDescription("Remove a label from a message. Returns a confirmation token; call confirm_operation with it to actually apply the change."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Message ID")),
+		mcp.WithString("label_id", mcp.Required(), mcp.Description("Gmail label ID to remove")),
+	)
+}
+
+func trashMessageTool() mcp.Tool {
+	return mcp.NewTool("trash_message",
+		mcp.WithDescription("Move a message to trash (recoverable for 30 days). Returns a confirmation token; call confirm_operation with it to actually apply the change."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Message ID")),
+	)
+}
+
+func markReadTool() mcp.Tool {
+	return mcp.NewTool("mark_read",
+		mcp.WithDescription("Mark a message read or unread. Returns a confirmation token; call confirm_operation with it to actually apply the change."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Message ID")),
+		mcp.WithBoolean("read", mcp.Required(), mcp.Description("true to mark read, false to mark unread")),
+	)
+}
+
+func bulkDeleteByQueryTool() mcp.Tool {
+	return mcp.NewTool("bulk_delete_by_query",
+		mcp.WithDescription("Permanently delete every message matching a search query (max 1000). Returns a confirmation token and the affected message count; call confirm_operation with it to actually delete."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Gmail-style search query, same syntax as search_messages")),
+		mcp.WithNumber("limit", mcp.Description("Maximum messages to delete in one call (default 20, max 1000)")),
+	)
+}
+
+func confirmOperationTool() mcp.Tool {
+	return mcp.NewTool("confirm_operation",
+		mcp.WithDescription("Execute a mutation previously staged by apply_label, remove_label, trash_message, mark_read, or bulk_delete_by_query."),
+		mcp.WithString("token", mcp.Required(), mcp.Description("Confirmation token returned by the staging call")),
+	)
+}
+
+// planDescription is the JSON shape returned to the client when a mutating
+// tool stages a plan, so it can see exactly what confirm_operation would do
+// before calling it.
+type planDescription struct {
+	Token      string   `json:"token"`
+	Op         string   `json:"op"`
+	MessageIDs []string `json:"message_ids"`
+	LabelID    string   `json:"label_id,omitempty"`
+	DryRun     bool     `json:"dry_run"`
+}
+
+// stage authorizes op against len(messageIDs) messages, builds a plan around
+// execute, and returns its staged description.
+func (h *writeHandlers) stage(op string, messageIDs []string, labelID string, execute func(ctx context.Context) (any, error)) (*mcp.CallToolResult, error) {
+	if h.gmailAPI == nil {
+		return mcp.NewToolResultError("mutating tools are not configured on this server (no Gmail client wired in)"), nil
+	}
+	if err := h.policy.Authorize(op, len(messageIDs)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	token, err := h.confirmations.stage(&plan{
+		Op:         op,
+		MessageIDs: messageIDs,
+		LabelID:    labelID,
+		execute:    execute,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("stage operation: %v", err)), nil
+	}
+
+	return jsonResult(planDescription{
+		Token:      token,
+		Op:         op,
+		MessageIDs: messageIDs,
+		LabelID:    labelID,
+		DryRun:     h.policy.DryRun(),
+	})
+}
+
+func (h *writeHandlers) sourceMessageID(ctx context.Context, idFloat float64) (string, error) {
+	if idFloat != math.Trunc(idFloat) || idFloat < 1 {
+		return "", fmt.Errorf("id must be a positive integer")
+	}
+	msg, err := h.engine.GetMessage(ctx, int64(idFloat))
+	if err != nil {
+		return "", fmt.Errorf("message not found: %w", err)
+	}
+	return msg.SourceMessageID, nil
+}
+
+func (h *writeHandlers) applyLabel(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.modifyLabel(ctx, req, true)
+}
+
+func (h *writeHandlers) removeLabel(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.modifyLabel(ctx, req, false)
+}
+
+func (h *writeHandlers) modifyLabel(ctx context.Context, req mcp.CallToolRequest, add bool) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	idFloat, ok := args["id"].(float64)
+	if !ok {
+		return mcp.NewToolResultError("id parameter is required"), nil
+	}
+	labelID, _ := args["label_id"].(string)
+	if labelID == "" {
+		return mcp.NewToolResultError("label_id parameter is required"), nil
+	}
+
+	sourceID, err := h.sourceMessageID(ctx, idFloat)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	op := "remove_label"
+	if add {
+		op = "apply_label"
+	}
+
+	return h.stage(op, []string{sourceID}, labelID, func(ctx context.Context) (any, error) {
+		if err := h.acquire(ctx, gmail.OpMessagesModify); err != nil {
+			return nil, err
+		}
+		if add {
+			return nil, h.gmailAPI.ModifyMessageLabels(ctx, sourceID, []string{labelID}, nil)
+		}
+		return nil, h.gmailAPI.ModifyMessageLabels(ctx, sourceID, nil, []string{labelID})
+	})
+}
+
+func (h *writeHandlers) trashMessage(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	idFloat, ok := args["id"].(float64)
+	if !ok {
+		return mcp.NewToolResultError("id parameter is required"), nil
+	}
+
+	sourceID, err := h.sourceMessageID(ctx, idFloat)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return h.stage("trash_message", []string{sourceID}, "", func(ctx context.Context) (any, error) {
+		if err := h.acquire(ctx, gmail.OpMessagesTrash); err != nil {
+			return nil, err
+		}
+		return nil, h.gmailAPI.TrashMessage(ctx, sourceID)
+	})
+}
+
+func (h *writeHandlers) markRead(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	idFloat, ok := args["id"].(float64)
+	if !ok {
+		return mcp.NewToolResultError("id parameter is required"), nil
+	}
+	read, ok := args["read"].(bool)
+	if !ok {
+		return mcp.NewToolResultError("read parameter is required"), nil
+	}
+
+	sourceID, err := h.sourceMessageID(ctx, idFloat)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return h.stage("mark_read", []string{sourceID}, unreadLabelID, func(ctx context.Context) (any, error) {
+		if err := h.acquire(ctx, gmail.OpMessagesModify); err != nil {
+			return nil, err
+		}
+		if read {
+			return nil, h.gmailAPI.ModifyMessageLabels(ctx, sourceID, nil, []string{unreadLabelID})
+		}
+		return nil, h.gmailAPI.ModifyMessageLabels(ctx, sourceID, []string{unreadLabelID}, nil)
+	})
+}
+
+func (h *writeHandlers) bulkDeleteByQuery(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	queryStr, _ := args["query"].(string)
+	if queryStr == "" {
+		return mcp.NewToolResultError("query parameter is required"), nil
+	}
+	limit := intArg(args, "limit", 20)
+
+	q := search.Parse(queryStr)
+	results, err := h.engine.SearchFast(ctx, q, triageFilterFromQuery(q), limit, 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+	}
+
+	ids := make([]string, len(results))
+	for i, msg := range results {
+		ids[i] = msg.SourceMessageID
+	}
+
+	return h.stage("bulk_delete_by_query", ids, "", func(ctx context.Context) (any, error) {
+		if err := h.acquire(ctx, gmail.OpMessagesBatchDelete); err != nil {
+			return nil, err
+		}
+		return nil, h.gmailAPI.BatchDeleteMessages(ctx, ids)
+	})
+}
+
+func (h *writeHandlers) confirmOperation(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	token, _ := args["token"].(string)
+	if token == "" {
+		return mcp.NewToolResultError("token parameter is required"), nil
+	}
+
+	p, ok := h.confirmations.claim(token)
+	if !ok {
+		return mcp.NewToolResultError("unknown or expired confirmation token"), nil
+	}
+	if h.policy.DryRun() {
+		return mcp.NewToolResultError(fmt.Sprintf("%s was staged under a dry-run policy and cannot be executed (preview only)", p.Op)), nil
+	}
+
+	result, err := p.execute(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s failed: %v", p.Op, err)), nil
+	}
+	if result == nil {
+		result = map[string]any{"op": p.Op, "message_ids": p.MessageIDs, "status": "ok"}
+	}
+	return jsonResult(result)
+}
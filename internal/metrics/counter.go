@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing value, optionally partitioned by a
+// fixed set of label names (e.g. "operation"). A zero-value Counter (as
+// returned by a nil Registry) silently discards every Add/Inc call.
+type Counter struct {
+	metricName string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*float64Box
+}
+
+// WithLabelNames declares the label names this counter is partitioned by.
+// Must be called (if at all) before any Add/Inc call.
+func (c *Counter) WithLabelNames(names ...string) *Counter {
+	if c == nil || c.values == nil {
+		return c
+	}
+	c.labelNames = names
+	return c
+}
+
+// Inc increments the counter for the given label values by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by delta.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if c == nil || c.values == nil {
+		return
+	}
+	box := c.boxFor(labelValues)
+	box.mu.Lock()
+	box.val += delta
+	box.mu.Unlock()
+}
+
+func (c *Counter) boxFor(labelValues []string) *float64Box {
+	key := labelKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	box, ok := c.values[key]
+	if !ok {
+		box = &float64Box{}
+		c.values[key] = box
+	}
+	return box
+}
+
+func (c *Counter) name() string { return c.metricName }
+
+func (c *Counter) writeTo(w io.Writer) {
+	if len(c.values) == 0 {
+		return
+	}
+	writeHelpType(w, c.metricName, c.help, "counter")
+	for _, key := range sortedFloat64BoxKeys(c.values) {
+		box := c.values[key]
+		box.mu.Lock()
+		val := box.val
+		box.mu.Unlock()
+		labels := formatLabels(c.labelNames, splitLabelKey(key, len(c.labelNames)))
+		fmt.Fprintf(w, "%s%s %v\n", c.metricName, labels, val)
+	}
+}
+
+// splitLabelKey reverses labelKey for rendering. labelKey joins with a
+// 0xFF separator that can't appear in a Prometheus label value, so a plain
+// split round-trips exactly.
+func splitLabelKey(key string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return strings.Split(key, "\xff")
+}
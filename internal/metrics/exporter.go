@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Handler returns an http.Handler serving r's metrics at whatever path it's
+// mounted under (conventionally "/metrics"), in Prometheus text exposition
+// format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if _, err := r.WriteTo(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Serve starts an HTTP server exposing r's metrics at addr + "/metrics",
+// mirroring mcp.ServeHTTP's lifecycle: it blocks until ctx is cancelled,
+// then shuts the server down gracefully and returns ctx.Err().
+//
+// This is the pull side of the exporter, for environments with a
+// Prometheus-style scraper. Environments without one should use Push
+// instead.
+func Serve(ctx context.Context, reg *Registry, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reg.Handler())
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("metrics: shutdown http server: %w", err)
+		}
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("metrics: serve http: %w", err)
+		}
+		return nil
+	}
+}
+
+// Push periodically POSTs reg's exposition text to url as
+// "text/plain; version=0.0.4", for environments with no scraper to pull
+// from this process (e.g. a short-lived batch job, or a Pushgateway-style
+// sink). It blocks until ctx is cancelled. A failed push is not fatal - it's
+// reported through onError (if non-nil) and the loop continues at the next
+// interval, since a transient sink outage shouldn't stop the job it's
+// instrumenting.
+func Push(ctx context.Context, reg *Registry, url string, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := pushOnce(ctx, reg, url); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func pushOnce(ctx context.Context, reg *Registry, url string) error {
+	var buf bytes.Buffer
+	if _, err := reg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return fmt.Errorf("metrics: build push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("metrics: push: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("metrics: push: server returned %s", resp.Status)
+	}
+	return nil
+}
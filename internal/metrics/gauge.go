@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Gauge is a value that can go up or down, optionally partitioned by a
+// fixed set of label names. A zero-value Gauge (as returned by a nil
+// Registry) silently discards every Set/Add call.
+type Gauge struct {
+	metricName string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*float64Box
+}
+
+// WithLabelNames declares the label names this gauge is partitioned by.
+// Must be called (if at all) before any Set/Add call.
+func (g *Gauge) WithLabelNames(names ...string) *Gauge {
+	if g == nil || g.values == nil {
+		return g
+	}
+	g.labelNames = names
+	return g
+}
+
+// Set records val as the gauge's current value for the given label values.
+func (g *Gauge) Set(val float64, labelValues ...string) {
+	if g == nil || g.values == nil {
+		return
+	}
+	box := g.boxFor(labelValues)
+	box.mu.Lock()
+	box.val = val
+	box.mu.Unlock()
+}
+
+// Add adjusts the gauge's current value by delta for the given label values.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	if g == nil || g.values == nil {
+		return
+	}
+	box := g.boxFor(labelValues)
+	box.mu.Lock()
+	box.val += delta
+	box.mu.Unlock()
+}
+
+func (g *Gauge) boxFor(labelValues []string) *float64Box {
+	key := labelKey(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	box, ok := g.values[key]
+	if !ok {
+		box = &float64Box{}
+		g.values[key] = box
+	}
+	return box
+}
+
+func (g *Gauge) name() string { return g.metricName }
+
+func (g *Gauge) writeTo(w io.Writer) {
+	if len(g.values) == 0 {
+		return
+	}
+	writeHelpType(w, g.metricName, g.help, "gauge")
+	for _, key := range sortedFloat64BoxKeys(g.values) {
+		box := g.values[key]
+		box.mu.Lock()
+		val := box.val
+		box.mu.Unlock()
+		labels := formatLabels(g.labelNames, splitLabelKey(key, len(g.labelNames)))
+		fmt.Fprintf(w, "%s%s %v\n", g.metricName, labels, val)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Histogram tracks the distribution of observed values across a fixed set
+// of cumulative buckets, optionally partitioned by a fixed set of label
+// names. A zero-value Histogram (as returned by a nil Registry) silently
+// discards every Observe call.
+type Histogram struct {
+	metricName string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*histogramBox
+}
+
+// histogramBox holds one label set's bucket counts, sum, and total count.
+type histogramBox struct {
+	mu     sync.Mutex
+	counts []uint64 // parallel to Histogram.buckets, cumulative at write time
+	sum    float64
+	total  uint64
+}
+
+// WithLabelNames declares the label names this histogram is partitioned by.
+// Must be called (if at all) before any Observe call.
+func (h *Histogram) WithLabelNames(names ...string) *Histogram {
+	if h == nil || h.values == nil {
+		return h
+	}
+	h.labelNames = names
+	return h
+}
+
+// Observe records val into the appropriate bucket for the given label values.
+func (h *Histogram) Observe(val float64, labelValues ...string) {
+	if h == nil || h.values == nil {
+		return
+	}
+	box := h.boxFor(labelValues)
+	box.mu.Lock()
+	defer box.mu.Unlock()
+	for i, upperBound := range h.buckets {
+		if val <= upperBound {
+			box.counts[i]++
+		}
+	}
+	box.sum += val
+	box.total++
+}
+
+func (h *Histogram) boxFor(labelValues []string) *histogramBox {
+	key := labelKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	box, ok := h.values[key]
+	if !ok {
+		box = &histogramBox{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = box
+	}
+	return box
+}
+
+func (h *Histogram) name() string { return h.metricName }
+
+func (h *Histogram) writeTo(w io.Writer) {
+	if len(h.values) == 0 {
+		return
+	}
+	writeHelpType(w, h.metricName, h.help, "histogram")
+	for _, key := range sortedHistogramBoxKeys(h.values) {
+		box := h.values[key]
+		box.mu.Lock()
+		counts := append([]uint64(nil), box.counts...)
+		sum, total := box.sum, box.total
+		box.mu.Unlock()
+
+		labelValues := splitLabelKey(key, len(h.labelNames))
+		for i, upperBound := range h.buckets {
+			bucketLabels := formatLabels(append(append([]string(nil), h.labelNames...), "le"),
+				append(append([]string(nil), labelValues...), fmt.Sprintf("%v", upperBound)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, bucketLabels, counts[i])
+		}
+		infLabels := formatLabels(append(append([]string(nil), h.labelNames...), "le"),
+			append(append([]string(nil), labelValues...), "+Inf"))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, infLabels, total)
+
+		baseLabels := formatLabels(h.labelNames, labelValues)
+		fmt.Fprintf(w, "%s_sum%s %v\n", h.metricName, baseLabels, sum)
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, baseLabels, total)
+	}
+}
@@ -0,0 +1,189 @@
+// Package metrics is a small self-contained Prometheus/OpenMetrics text
+// exposition exporter. msgvault has no prometheus/client_golang dependency
+// anywhere in the tree (the only existing metrics surface is the stdlib's
+// expvar, used by gmail.RateLimiter's WithExpvarPrefix), so rather than pull
+// in that dependency for one exporter, Registry renders the exposition
+// format itself over a handful of counter/gauge/histogram primitives.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry collects named metrics and renders them in Prometheus text
+// exposition format. A nil *Registry is valid and every method on it is a
+// no-op, so callers that don't care about metrics can pass nil instead of
+// threading an if-enabled check through every call site.
+type Registry struct {
+	mu       sync.Mutex
+	metrics  []metric
+	byName   map[string]metric
+	disabled bool
+}
+
+type metric interface {
+	name() string
+	writeTo(w io.Writer)
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]metric)}
+}
+
+// DisableExport turns WriteTo/Handler into a no-op (an empty exposition),
+// without affecting Counter/Gauge/Histogram value collection. Tests that
+// build a real RateLimiter or MCP server via production constructors can
+// pass a disabled Registry instead of a nil one when they still want to
+// assert against recorded values but don't want a listening HTTP endpoint.
+func (r *Registry) DisableExport() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabled = true
+}
+
+// register adds m under its name and returns it, or returns the metric
+// already registered under that name.
+func (r *Registry) register(m metric) metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, exists := r.byName[m.name()]; exists {
+		return existing
+	}
+	r.byName[m.name()] = m
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// Counter returns the named counter, creating it on first use. help is the
+// OpenMetrics HELP text rendered above the metric.
+func (r *Registry) Counter(name, help string) *Counter {
+	if r == nil {
+		return &Counter{}
+	}
+	c := &Counter{metricName: name, help: help, values: make(map[string]*float64Box)}
+	if existing, ok := r.register(c).(*Counter); ok {
+		return existing
+	}
+	return c
+}
+
+// Gauge returns the named gauge, creating it on first use.
+func (r *Registry) Gauge(name, help string) *Gauge {
+	if r == nil {
+		return &Gauge{}
+	}
+	g := &Gauge{metricName: name, help: help, values: make(map[string]*float64Box)}
+	if existing, ok := r.register(g).(*Gauge); ok {
+		return existing
+	}
+	return g
+}
+
+// Histogram returns the named histogram, creating it on first use. buckets
+// are the upper bounds of each bucket (a final +Inf bucket is implicit).
+func (r *Registry) Histogram(name, help string, buckets []float64) *Histogram {
+	if r == nil {
+		return &Histogram{}
+	}
+	h := &Histogram{metricName: name, help: help, buckets: buckets, values: make(map[string]*histogramBox)}
+	if existing, ok := r.register(h).(*Histogram); ok {
+		return existing
+	}
+	return h
+}
+
+// WriteTo renders every registered metric in Prometheus text exposition
+// format, satisfying io.WriterTo. With export disabled, it writes nothing
+// and returns 0, nil.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	if r == nil {
+		return 0, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.disabled {
+		return 0, nil
+	}
+	cw := &countingWriter{w: w}
+	for _, m := range r.metrics {
+		m.writeTo(cw)
+	}
+	return cw.n, nil
+}
+
+// countingWriter tallies bytes written so WriteTo can report them, since the
+// metric.writeTo implementations write via fmt.Fprintf and don't track a
+// running count themselves.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// float64Box lets Counter/Gauge store a mutable value behind a pointer so
+// concurrent label sets don't need to re-take the Registry lock.
+type float64Box struct {
+	mu  sync.Mutex
+	val float64
+}
+
+// DefaultLatencyBuckets mirrors prometheus/client_golang's DefBuckets, a
+// reasonable default for request-latency-shaped histograms.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func writeHelpType(w io.Writer, name, help, typ string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+func formatLabels(labelNames, labelValues []string) string {
+	if len(labelNames) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labelNames))
+	for i, n := range labelNames {
+		parts[i] = fmt.Sprintf("%s=%q", n, labelValues[i])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// sortedFloat64BoxKeys returns m's keys in a stable order so repeated
+// WriteTo calls produce byte-identical output for unchanged values.
+func sortedFloat64BoxKeys(m map[string]*float64Box) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedHistogramBoxKeys is sortedFloat64BoxKeys's counterpart for
+// Histogram's per-label-set value map.
+func sortedHistogramBoxKeys(m map[string]*histogramBox) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_CounterAndGauge_WriteTo(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("msgvault_test_calls_total", "Test calls.").WithLabelNames("op")
+	c.Inc("read")
+	c.Add(2, "read")
+	c.Inc("write")
+
+	g := reg.Gauge("msgvault_test_level", "Test level.")
+	g.Set(5)
+	g.Add(-1)
+
+	var buf strings.Builder
+	if _, err := reg.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE msgvault_test_calls_total counter",
+		`msgvault_test_calls_total{op="read"} 3`,
+		`msgvault_test_calls_total{op="write"} 1`,
+		"# TYPE msgvault_test_level gauge",
+		"msgvault_test_level 4",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteTo() output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRegistry_Histogram_WriteTo(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.Histogram("msgvault_test_duration_seconds", "Test duration.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	var buf strings.Builder
+	if _, err := reg.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`msgvault_test_duration_seconds_bucket{le="0.1"} 1`,
+		`msgvault_test_duration_seconds_bucket{le="1"} 2`,
+		`msgvault_test_duration_seconds_bucket{le="+Inf"} 3`,
+		"msgvault_test_duration_seconds_sum 5.55",
+		"msgvault_test_duration_seconds_count 3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteTo() output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRegistry_DisableExport(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("msgvault_test_disabled_total", "")
+	c.Inc()
+	reg.DisableExport()
+
+	var buf strings.Builder
+	if _, err := reg.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("WriteTo() after DisableExport() wrote %q, want empty", buf.String())
+	}
+}
+
+func TestNilRegistry_IsNoOp(t *testing.T) {
+	var reg *Registry
+	c := reg.Counter("x", "")
+	g := reg.Gauge("y", "")
+	h := reg.Histogram("z", "", DefaultLatencyBuckets)
+
+	// None of these should panic.
+	c.Inc("a")
+	g.Set(1)
+	h.Observe(0.1)
+
+	if _, err := reg.WriteTo(&strings.Builder{}); err != nil {
+		t.Errorf("nil Registry WriteTo() error = %v", err)
+	}
+}
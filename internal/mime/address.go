@@ -0,0 +1,39 @@
+package mime
+
+import "strings"
+
+// Address is a parsed RFC 5322 mailbox, with Domain split out for
+// per-domain analytics queries.
+type Address struct {
+	Name   string
+	Email  string
+	Domain string
+
+	// Group is the display name of the RFC 5322 group this address was a
+	// member of (e.g. "team" in "team: alice@x.com, bob@x.com;"), or "" if
+	// it appeared as an ordinary, ungrouped mailbox.
+	Group string
+}
+
+// GroupHeader records an RFC 5322 group address seen while parsing a
+// To/Cc/Bcc header, including an empty group like
+// "undisclosed-recipients:;", which unfolds into zero Address entries.
+// Without this, an empty group and a header with no addresses at all would
+// look identical to callers.
+type GroupHeader struct {
+	// Field is the header the group appeared in: "To", "Cc", or "Bcc".
+	Field string
+	// Name is the group's display name.
+	Name string
+}
+
+// extractDomain returns the part of email after the last "@", lowercased,
+// or "" if email has none.
+func extractDomain(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+	return email[i+1:]
+}
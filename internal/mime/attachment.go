@@ -0,0 +1,57 @@
+package mime
+
+import (
+	"strings"
+
+	"github.com/jhillyerd/enmime"
+)
+
+// Attachment is a non-body part of a parsed message: a file the sender
+// attached, as opposed to the message's own text/HTML content.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+
+	// ContentID is the part's Content-ID header (angle brackets stripped),
+	// set when the attachment is referenced inline from the HTML body via
+	// a "cid:" URL. Empty for a regular (non-inline) attachment.
+	ContentID string
+}
+
+// parseAttachments walks root's tree for parts that are neither body
+// content (see isBodyPart) nor part of an embedded ARF report (see
+// parseFeedbackReport), returning them as Attachments in document order.
+// It doesn't descend into a message/rfc822 part's own subtree, for the
+// same reason primaryTextPart doesn't: that's an independent embedded
+// message, not this message's own attachment list.
+func parseAttachments(root *enmime.Part) []Attachment {
+	var attachments []Attachment
+	var walk func(p *enmime.Part)
+	walk = func(p *enmime.Part) {
+		if p == nil {
+			return
+		}
+		ct := contentTypeOnly(p.ContentType)
+		switch {
+		case isBodyPart(p), strings.HasPrefix(ct, "multipart/"), ct == "message/feedback-report":
+			// Not an attachment: either body content, a container with no
+			// content of its own, or the ARF machine-readable part, which
+			// parseFeedbackReport already exposes structurally.
+		case ct == "message/rfc822":
+			walk(p.NextSibling)
+			return
+		case len(p.Content) > 0 || p.FileName != "":
+			attachments = append(attachments, Attachment{
+				Filename:    p.FileName,
+				ContentType: p.ContentType,
+				Content:     p.Content,
+				ContentID:   strings.Trim(p.ContentID, "<>"),
+			})
+		}
+		walk(p.FirstChild)
+		walk(p.NextSibling)
+	}
+	walk(root)
+	return attachments
+}
@@ -0,0 +1,94 @@
+package mime
+
+import "strings"
+
+// utf7Base64Alphabet is RFC 2152's modified base64 alphabet used inside
+// UTF-7's "+...-" shift sequences.
+const utf7Base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// decodeUTF7 decodes RFC 2152 UTF-7, as used by IMAP-style messages and
+// occasionally Gmail archive exports. golang.org/x/net/html/charset has no
+// UTF-7 decoder (its alphabet soup of shift states makes it a poor fit for
+// a generic charset detector), so this package carries its own. Malformed
+// shift sequences are passed through verbatim rather than erroring,
+// consistent with never failing a whole message over one bad header or
+// part.
+func decodeUTF7(data []byte) string {
+	var out strings.Builder
+	i := 0
+	for i < len(data) {
+		b := data[i]
+		if b != '+' {
+			out.WriteByte(b)
+			i++
+			continue
+		}
+
+		// "+-" is a literal "+".
+		if i+1 < len(data) && data[i+1] == '-' {
+			out.WriteByte('+')
+			i += 2
+			continue
+		}
+
+		start := i + 1
+		j := start
+		for j < len(data) && strings.IndexByte(utf7Base64Alphabet, data[j]) >= 0 {
+			j++
+		}
+		decoded, ok := decodeUTF7Base64(data[start:j])
+		if !ok {
+			out.WriteByte(b)
+			i++
+			continue
+		}
+		out.WriteString(decoded)
+		i = j
+		if i < len(data) && data[i] == '-' {
+			i++ // trailing "-" just terminates the shift sequence
+		}
+	}
+	return out.String()
+}
+
+// decodeUTF7Base64 decodes RFC 2152's modified-base64 run into the UTF-16BE
+// code units it encodes, returned as a UTF-8 string.
+func decodeUTF7Base64(run []byte) (string, bool) {
+	if len(run) == 0 {
+		return "", false
+	}
+
+	var bits uint32
+	var nbits uint
+	var units []uint16
+	for _, b := range run {
+		v := strings.IndexByte(utf7Base64Alphabet, b)
+		if v < 0 {
+			return "", false
+		}
+		bits = bits<<6 | uint32(v)
+		nbits += 6
+		if nbits >= 16 {
+			nbits -= 16
+			units = append(units, uint16(bits>>nbits))
+		}
+	}
+
+	var out strings.Builder
+	for idx := 0; idx < len(units); idx++ {
+		r := rune(units[idx])
+		if isUTF16HighSurrogate(r) && idx+1 < len(units) {
+			r2 := rune(units[idx+1])
+			if isUTF16LowSurrogate(r2) {
+				out.WriteRune(((r - 0xD800) << 10) + (r2 - 0xDC00) + 0x10000)
+				idx++
+				continue
+			}
+		}
+		out.WriteRune(r)
+	}
+	return out.String(), true
+}
+
+func isUTF16HighSurrogate(r rune) bool { return r >= 0xD800 && r <= 0xDBFF }
+func isUTF16LowSurrogate(r rune) bool  { return r >= 0xDC00 && r <= 0xDFFF }
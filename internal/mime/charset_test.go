@@ -0,0 +1,91 @@
+package mime
+
+import "testing"
+
+// TestParse_CharsetMatrix feeds a message per declared charset and asserts
+// the decoded BodyText round-trips to the expected UTF-8 string, modelled
+// on the charset matrix in go-mail's msg_test.go.
+func TestParse_CharsetMatrix(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{
+			name:        "utf-8",
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("Caf\xc3\xa9 au lait"),
+			want:        "Café au lait",
+		},
+		{
+			name:        "us-ascii",
+			contentType: "text/plain; charset=us-ascii",
+			body:        []byte("Plain ASCII text"),
+			want:        "Plain ASCII text",
+		},
+		{
+			name:        "iso-8859-1",
+			contentType: "text/plain; charset=iso-8859-1",
+			body:        []byte("Caf\xe9 au lait"),
+			want:        "Café au lait",
+		},
+		{
+			name:        "iso-8859-15",
+			contentType: "text/plain; charset=iso-8859-15",
+			body:        []byte("Caf\xe9 au lait"),
+			want:        "Café au lait",
+		},
+		{
+			name:        "windows-1252",
+			contentType: "text/plain; charset=windows-1252",
+			body:        []byte("Caf\xe9 au lait"),
+			want:        "Café au lait",
+		},
+		{
+			// "Caf+AOk- au lait": "+AOk-" is the UTF-7 shift sequence for
+			// U+00E9 (é) per RFC 2152's modified base64.
+			name:        "utf-7",
+			contentType: "text/plain; charset=utf-7",
+			body:        []byte("Caf+AOk- au lait"),
+			want:        "Café au lait",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := append([]byte("From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: Charset Test\r\nContent-Type: "+tt.contentType+"\r\n\r\n"), tt.body...)
+
+			msg := mustParse(t, raw)
+			if msg.BodyText != tt.want {
+				t.Errorf("BodyText = %q, want %q", msg.BodyText, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_UTF7SetsSourceCharset(t *testing.T) {
+	raw := []byte("From: sender@example.com\r\nTo: recipient@example.com\r\nSubject: UTF-7\r\nContent-Type: text/plain; charset=utf-7\r\n\r\nCaf+AOk- au lait")
+
+	msg := mustParse(t, raw)
+	if msg.SourceCharset != "utf-7" {
+		t.Errorf("SourceCharset = %q, want %q", msg.SourceCharset, "utf-7")
+	}
+}
+
+func TestDecodeUTF7(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain ascii", "plain ascii"},
+		{"Caf+AOk- au lait", "Café au lait"},
+		{"+-", "+"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := decodeUTF7([]byte(tt.in)); got != tt.want {
+			t.Errorf("decodeUTF7(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
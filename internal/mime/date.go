@@ -0,0 +1,58 @@
+package mime
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var multiSpaceRe = regexp.MustCompile(`[ \t]{2,}`)
+
+// isoLayouts are date formats real-world MIME messages use that aren't
+// valid RFC 5322 dates (no day-name, dash-separated, etc.), tried once
+// mail.ParseDate has given up.
+var isoLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+}
+
+// parseDate parses an RFC 5322 Date header (plus a few common
+// non-conformant variants seen in real archives) into UTC. Malformed or
+// empty input returns the zero time with a nil error rather than failing:
+// a bad Date header is common enough in the wild that it shouldn't abort
+// the whole parse.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	normalized := multiSpaceRe.ReplaceAllString(s, " ")
+
+	if t, err := mail.ParseDate(normalized); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+var referenceRe = regexp.MustCompile(`<[^<>]+>`)
+
+// parseReferences extracts the angle-bracketed msg-ids from a References
+// (or In-Reply-To) header, in order, stripped of their brackets.
+func parseReferences(s string) []string {
+	matches := referenceRe.FindAllString(s, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	refs := make([]string, len(matches))
+	for i, m := range matches {
+		refs[i] = strings.Trim(m, "<>")
+	}
+	return refs
+}
@@ -0,0 +1,366 @@
+package mime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// encodeLineWidth is the column RFC 5322 recommends folding header lines
+// at; see foldHeader.
+const encodeLineWidth = 78
+
+// base64LineWidth is the line length RFC 2045 requires for base64-encoded
+// content.
+const base64LineWidth = 76
+
+// rfc2231InlineThreshold is the filename length above which
+// encodeContentDisposition switches to RFC 2231 extended parameter syntax
+// even for an all-ASCII name, so a single long filename can't push a
+// folded header line past encodeLineWidth.
+const rfc2231InlineThreshold = 60
+
+// Encode reconstructs a valid RFC 5322 message from m: CRLF line endings,
+// RFC 2047 encoded words for non-ASCII header values, Date formatted per
+// RFC 5322, headers folded at 78 columns, a multipart/alternative body
+// when both BodyText and BodyHTML are set (a single part when only one
+// is), and Attachments re-emitted as multipart/mixed parts with base64
+// content-transfer-encoding. This is the inverse of Parse, making
+// "msgvault export" symmetric with "msgvault import".
+func (m *Message) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	m.encodeHeaders(&buf)
+
+	contentType, cte, body, err := m.encodeBody()
+	if err != nil {
+		return nil, fmt.Errorf("mime: encode: %w", err)
+	}
+
+	foldHeader(&buf, "MIME-Version", "1.0")
+	foldHeader(&buf, "Content-Type", contentType)
+	if cte != "" {
+		foldHeader(&buf, "Content-Transfer-Encoding", cte)
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// encodeHeaders writes every header Parse populates other than
+// Content-Type/Content-Transfer-Encoding/MIME-Version, which depend on the
+// body structure Encode computes separately.
+func (m *Message) encodeHeaders(buf *bytes.Buffer) {
+	if len(m.From) > 0 {
+		foldHeader(buf, "From", encodeAddressHeader(m.From, nil, "From"))
+	}
+	if header := encodeAddressHeader(m.To, m.Groups, "To"); header != "" {
+		foldHeader(buf, "To", header)
+	}
+	if header := encodeAddressHeader(m.Cc, m.Groups, "Cc"); header != "" {
+		foldHeader(buf, "Cc", header)
+	}
+	if header := encodeAddressHeader(m.Bcc, m.Groups, "Bcc"); header != "" {
+		foldHeader(buf, "Bcc", header)
+	}
+	if m.Subject != "" {
+		foldHeader(buf, "Subject", encodeHeaderText(m.Subject))
+	}
+	if !m.Date.IsZero() {
+		foldHeader(buf, "Date", m.Date.UTC().Format(time.RFC1123Z))
+	}
+	if m.MessageID != "" {
+		foldHeader(buf, "Message-ID", "<"+m.MessageID+">")
+	}
+	if len(m.References) > 0 {
+		refs := make([]string, len(m.References))
+		for i, r := range m.References {
+			refs[i] = "<" + r + ">"
+		}
+		foldHeader(buf, "References", strings.Join(refs, " "))
+	}
+}
+
+// encodeBody picks the message's overall structure: a single text part, a
+// multipart/alternative of BodyText/BodyHTML, or (if Attachments is
+// non-empty) that same body wrapped as the first part of a
+// multipart/mixed followed by each attachment.
+func (m *Message) encodeBody() (contentType, cte string, body []byte, err error) {
+	coreBody, coreContentType, coreCTE, err := m.encodeTextBody()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(m.Attachments) == 0 {
+		return coreContentType, coreCTE, coreBody, nil
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", coreContentType)
+	if coreCTE != "" {
+		h.Set("Content-Transfer-Encoding", coreCTE)
+	}
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("create body part: %w", err)
+	}
+	if _, err := pw.Write(coreBody); err != nil {
+		return "", "", nil, fmt.Errorf("write body part: %w", err)
+	}
+
+	for i, att := range m.Attachments {
+		if err := writeAttachmentPart(mw, att); err != nil {
+			return "", "", nil, fmt.Errorf("write attachment %d: %w", i, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", "", nil, fmt.Errorf("close multipart/mixed: %w", err)
+	}
+
+	return "multipart/mixed; boundary=" + mw.Boundary(), "", buf.Bytes(), nil
+}
+
+// encodeTextBody returns BodyText/BodyHTML as the message's core body,
+// before any attachment wrapping: a multipart/alternative of both when
+// both are set, otherwise whichever one is (defaulting to an empty
+// text/plain part when neither is).
+func (m *Message) encodeTextBody() (body []byte, contentType, cte string, err error) {
+	switch {
+	case m.BodyText != "" && m.BodyHTML != "":
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		if err := writeTextPart(mw, "text/plain", m.BodyText); err != nil {
+			return nil, "", "", fmt.Errorf("write text/plain alternative: %w", err)
+		}
+		if err := writeTextPart(mw, "text/html", m.BodyHTML); err != nil {
+			return nil, "", "", fmt.Errorf("write text/html alternative: %w", err)
+		}
+		if err := mw.Close(); err != nil {
+			return nil, "", "", fmt.Errorf("close multipart/alternative: %w", err)
+		}
+		return buf.Bytes(), "multipart/alternative; boundary=" + mw.Boundary(), "", nil
+	case m.BodyHTML != "":
+		encoded, err := encodeQuotedPrintable([]byte(m.BodyHTML))
+		return encoded, "text/html; charset=utf-8", "quoted-printable", err
+	default:
+		encoded, err := encodeQuotedPrintable([]byte(m.BodyText))
+		return encoded, "text/plain; charset=utf-8", "quoted-printable", err
+	}
+}
+
+// writeTextPart writes one text/plain or text/html part of a
+// multipart/alternative body, quoted-printable encoded.
+func writeTextPart(mw *multipart.Writer, contentType, text string) error {
+	encoded, err := encodeQuotedPrintable([]byte(text))
+	if err != nil {
+		return err
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", contentType+"; charset=utf-8")
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = pw.Write(encoded)
+	return err
+}
+
+// writeAttachmentPart writes att as a base64 part of a multipart/mixed
+// body, base64-wrapped at base64LineWidth columns per RFC 2045.
+func writeAttachmentPart(mw *multipart.Writer, att Attachment) error {
+	h := make(textproto.MIMEHeader)
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Transfer-Encoding", "base64")
+	h.Set("Content-Disposition", encodeContentDisposition(att.Filename))
+	if att.ContentID != "" {
+		h.Set("Content-ID", "<"+att.ContentID+">")
+	}
+
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(att.Content)
+	for i := 0; i < len(encoded); i += base64LineWidth {
+		end := i + base64LineWidth
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		if _, err := pw.Write([]byte(encoded[i:end])); err != nil {
+			return err
+		}
+		if _, err := pw.Write([]byte("\r\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeQuotedPrintable(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	qw := quotedprintable.NewWriter(&buf)
+	if _, err := qw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := qw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeContentDisposition builds a Content-Disposition: attachment header
+// value for filename. A short, all-ASCII filename is quoted normally;
+// anything else (non-ASCII, or long enough to risk pushing a folded
+// header past encodeLineWidth) uses RFC 2231's extended parameter syntax
+// (filename*=UTF-8''%-encoded). This covers the common single-parameter
+// case; it doesn't split extremely long names across filename*0*/
+// filename*1*... continuations.
+func encodeContentDisposition(filename string) string {
+	if filename == "" {
+		return "attachment"
+	}
+	if isASCII(filename) && len(filename) <= rfc2231InlineThreshold {
+		return fmt.Sprintf(`attachment; filename="%s"`, escapeQuotedString(filename))
+	}
+	return "attachment; filename*=UTF-8''" + percentEncodeRFC2231(filename)
+}
+
+func escapeQuotedString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return s
+}
+
+// percentEncodeRFC2231 percent-encodes s per RFC 2231/5987, leaving
+// unreserved characters (ALPHA / DIGIT / "-" / "." / "_" / "~") untouched.
+func percentEncodeRFC2231(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isRFC2231Unreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func isRFC2231Unreserved(c byte) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		return true
+	case c == '-' || c == '.' || c == '_' || c == '~':
+		return true
+	}
+	return false
+}
+
+// encodeAddressHeader reconstructs a From/To/Cc/Bcc header value from
+// addrs, re-wrapping any run of addresses that share a Group back into
+// "name: member, member;" syntax. groups supplies field's empty groups
+// (Parse's GroupHeader records these since they unfold into zero Address
+// entries); groups belonging to other fields, or already represented by a
+// non-empty run in addrs, are skipped. Empty groups aren't positionally
+// recoverable from the flattened Address/GroupHeader split Parse
+// produces, so they're appended after every ordinary/grouped address
+// rather than reinserted at their original position.
+func encodeAddressHeader(addrs []Address, groups []GroupHeader, field string) string {
+	var parts []string
+	seenGroup := map[string]bool{}
+
+	i := 0
+	for i < len(addrs) {
+		group := addrs[i].Group
+		if group == "" {
+			parts = append(parts, encodeMailbox(addrs[i]))
+			i++
+			continue
+		}
+		var members []string
+		for i < len(addrs) && addrs[i].Group == group {
+			members = append(members, encodeMailbox(addrs[i]))
+			i++
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s;", encodeHeaderText(group), strings.Join(members, ", ")))
+		seenGroup[group] = true
+	}
+
+	for _, g := range groups {
+		if g.Field != field || seenGroup[g.Name] {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:;", encodeHeaderText(g.Name)))
+		seenGroup[g.Name] = true
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// encodeMailbox formats a as "Display Name <addr@example.com>" (or bare
+// addr@example.com with no name), RFC 2047 encoding Name if it's
+// non-ASCII, via net/mail's own Address formatter.
+func encodeMailbox(a Address) string {
+	addr := &mail.Address{Name: a.Name, Address: a.Email}
+	return addr.String()
+}
+
+// encodeHeaderText RFC 2047 encodes s as a "Q"-encoded word if it has any
+// non-ASCII bytes, leaving plain ASCII text untouched.
+func encodeHeaderText(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	return mime.QEncoding.Encode("utf-8", s)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// foldHeader writes "name: value\r\n" into buf, folding value onto
+// continuation lines (each starting with a single space, RFC 5322's
+// folding whitespace) so no line exceeds encodeLineWidth columns. It
+// breaks between whitespace-separated words, which is safe for the
+// encoded-word and quoted values Encode produces; it does not break
+// inside a single overlong word.
+func foldHeader(buf *bytes.Buffer, name, value string) {
+	line := name + ": "
+	first := true
+	for _, word := range strings.Fields(value) {
+		if first {
+			line += word
+			first = false
+			continue
+		}
+		if len(line)+1+len(word) > encodeLineWidth {
+			buf.WriteString(line)
+			buf.WriteString("\r\n")
+			line = " " + word
+			continue
+		}
+		line += " " + word
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
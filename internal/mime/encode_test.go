@@ -0,0 +1,158 @@
+package mime
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMessage_Encode_RoundTrip builds a Message by hand, encodes it, and
+// re-parses the result, asserting the fields that should survive a
+// round trip do.
+func TestMessage_Encode_RoundTrip(t *testing.T) {
+	msg := &Message{
+		From:      []Address{{Name: "Alice", Email: "alice@example.com"}},
+		To:        []Address{{Name: "Bob", Email: "bob@example.com"}},
+		Subject:   "Hello",
+		Date:      time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		MessageID: "abc123@example.com",
+		BodyText:  "Hello, Bob!",
+	}
+
+	raw, err := msg.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got := mustParse(t, raw)
+	if len(got.From) != 1 || got.From[0].Email != "alice@example.com" {
+		t.Errorf("From = %v, want alice@example.com", got.From)
+	}
+	if len(got.To) != 1 || got.To[0].Email != "bob@example.com" {
+		t.Errorf("To = %v, want bob@example.com", got.To)
+	}
+	if got.Subject != "Hello" {
+		t.Errorf("Subject = %q, want %q", got.Subject, "Hello")
+	}
+	if got.MessageID != "abc123@example.com" {
+		t.Errorf("MessageID = %q, want %q", got.MessageID, "abc123@example.com")
+	}
+	if got.BodyText != "Hello, Bob!" {
+		t.Errorf("BodyText = %q, want %q", got.BodyText, "Hello, Bob!")
+	}
+}
+
+// TestMessage_Encode_NonASCIISubject asserts a non-ASCII Subject comes
+// back through Parse unchanged, proving the RFC 2047 encoded word
+// round-trips correctly.
+func TestMessage_Encode_NonASCIISubject(t *testing.T) {
+	msg := &Message{
+		From:     []Address{{Email: "sender@example.com"}},
+		To:       []Address{{Email: "recipient@example.com"}},
+		Subject:  "Café meeting",
+		BodyText: "See you there",
+	}
+
+	raw, err := msg.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !strings.Contains(string(raw), "=?utf-8?") {
+		t.Errorf("encoded message doesn't contain an RFC 2047 encoded word:\n%s", raw)
+	}
+
+	got := mustParse(t, raw)
+	if got.Subject != "Café meeting" {
+		t.Errorf("Subject = %q, want %q", got.Subject, "Café meeting")
+	}
+}
+
+// TestMessage_Encode_AlternativeBody asserts a message with both BodyText
+// and BodyHTML set encodes as multipart/alternative and both parts
+// survive re-parsing.
+func TestMessage_Encode_AlternativeBody(t *testing.T) {
+	msg := &Message{
+		From:     []Address{{Email: "sender@example.com"}},
+		To:       []Address{{Email: "recipient@example.com"}},
+		Subject:  "Alt body",
+		BodyText: "plain version",
+		BodyHTML: "<p>html version</p>",
+	}
+
+	raw, err := msg.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !strings.Contains(string(raw), "multipart/alternative") {
+		t.Errorf("expected multipart/alternative in:\n%s", raw)
+	}
+
+	got := mustParse(t, raw)
+	if got.BodyText != "plain version" {
+		t.Errorf("BodyText = %q, want %q", got.BodyText, "plain version")
+	}
+	if got.BodyHTML != "<p>html version</p>" {
+		t.Errorf("BodyHTML = %q, want %q", got.BodyHTML, "<p>html version</p>")
+	}
+}
+
+// TestMessage_Encode_Attachment asserts an attachment is re-emitted as a
+// multipart/mixed base64 part with its filename preserved, and that it
+// round-trips back into Attachments.
+func TestMessage_Encode_Attachment(t *testing.T) {
+	msg := &Message{
+		From:     []Address{{Email: "sender@example.com"}},
+		To:       []Address{{Email: "recipient@example.com"}},
+		Subject:  "With attachment",
+		BodyText: "see attached",
+		Attachments: []Attachment{
+			{Filename: "notes.txt", ContentType: "text/plain", Content: []byte("attachment body")},
+		},
+	}
+
+	raw, err := msg.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !strings.Contains(string(raw), "multipart/mixed") {
+		t.Errorf("expected multipart/mixed in:\n%s", raw)
+	}
+
+	got := mustParse(t, raw)
+	if got.BodyText != "see attached" {
+		t.Errorf("BodyText = %q, want %q", got.BodyText, "see attached")
+	}
+	if len(got.Attachments) != 1 {
+		t.Fatalf("Attachments = %v, want 1 entry", got.Attachments)
+	}
+	if got.Attachments[0].Filename != "notes.txt" {
+		t.Errorf("Attachments[0].Filename = %q, want %q", got.Attachments[0].Filename, "notes.txt")
+	}
+	if string(got.Attachments[0].Content) != "attachment body" {
+		t.Errorf("Attachments[0].Content = %q, want %q", got.Attachments[0].Content, "attachment body")
+	}
+}
+
+// TestFoldHeader_LongValueWraps asserts a value longer than
+// encodeLineWidth is split across continuation lines, each starting with
+// the RFC 5322 folding-whitespace single space.
+func TestFoldHeader_LongValueWraps(t *testing.T) {
+	var buf bytes.Buffer
+	value := strings.Repeat("word ", 30)
+
+	foldHeader(&buf, "Subject", value)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folding into multiple lines, got %d: %q", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		if len(line) > encodeLineWidth {
+			t.Errorf("line %d exceeds %d columns: %q", i, encodeLineWidth, line)
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation line %d doesn't start with folding whitespace: %q", i, line)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package mime
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"strings"
+
+	"github.com/jhillyerd/enmime"
+)
+
+// FeedbackReport is the structured machine-readable part of an RFC 5965
+// Abuse Reporting Format (ARF) complaint: the "message/feedback-report"
+// body that rides alongside the human-readable text and the original
+// offending message inside a "multipart/report; report-type=feedback-report"
+// container.
+type FeedbackReport struct {
+	FeedbackType     string
+	UserAgent        string
+	Version          string
+	SourceIP         string
+	OriginalMailFrom string
+	ArrivalDate      string
+	ReportingMTA     string
+	OriginalRcptTo   string
+
+	// OriginalMessage is the embedded offending message, fully parsed, when
+	// the report's third part ("message/rfc822") is present.
+	OriginalMessage *Message
+}
+
+// feedbackReportType is the report-type parameter value that identifies a
+// multipart/report as an ARF complaint.
+const feedbackReportType = "feedback-report"
+
+// parseFeedbackReport walks root's immediate children for the
+// "message/feedback-report" and "message/rfc822" parts an ARF complaint
+// carries alongside its human-readable text, returning nil if root isn't a
+// multipart/report with report-type=feedback-report.
+func parseFeedbackReport(root *enmime.Part) *FeedbackReport {
+	if root == nil || contentTypeOnly(root.ContentType) != "multipart/report" {
+		return nil
+	}
+	// enmime's Part.ContentType strips parameters and never populates
+	// ContentTypeParams, so report-type has to come from the raw header.
+	_, params, err := mime.ParseMediaType(root.Header.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(params["report-type"], feedbackReportType) {
+		return nil
+	}
+
+	fr := &FeedbackReport{}
+	found := false
+	for p := root.FirstChild; p != nil; p = p.NextSibling {
+		switch contentTypeOnly(p.ContentType) {
+		case "message/feedback-report":
+			parseFeedbackReportFields(p.Content, fr)
+			found = true
+		case "message/rfc822":
+			if msg, err := Parse(p.Content); err == nil {
+				fr.OriginalMessage = msg
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return fr
+}
+
+// parseFeedbackReportFields parses a message/feedback-report part's body,
+// which is itself a flat list of "Field: value" lines (RFC 5965 defines it
+// as its own mini header block, not a nested MIME message), filling in the
+// fields FeedbackReport recognizes and ignoring the rest.
+func parseFeedbackReportFields(content []byte, fr *FeedbackReport) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		switch field {
+		case "feedback-type":
+			fr.FeedbackType = value
+		case "user-agent":
+			fr.UserAgent = value
+		case "version":
+			fr.Version = value
+		case "source-ip":
+			fr.SourceIP = value
+		case "original-mail-from":
+			fr.OriginalMailFrom = value
+		case "arrival-date", "received-date":
+			fr.ArrivalDate = value
+		case "reporting-mta":
+			fr.ReportingMTA = value
+		case "original-rcpt-to":
+			fr.OriginalRcptTo = value
+		}
+	}
+}
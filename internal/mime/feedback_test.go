@@ -0,0 +1,92 @@
+package mime
+
+import "testing"
+
+// TestParse_FeedbackReport parses a three-part RFC 5965 ARF complaint and
+// asserts the machine-readable fields and embedded original message both
+// come through, without the feedback-report/rfc822 parts leaking into
+// BodyText.
+func TestParse_FeedbackReport(t *testing.T) {
+	raw := []byte("From: abuse@example.com\r\n" +
+		"To: admin@example.com\r\n" +
+		"Subject: FW: abuse report\r\n" +
+		"Content-Type: multipart/report; report-type=feedback-report; boundary=\"outer\"\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"This is an email abuse report.\r\n" +
+		"--outer\r\n" +
+		"Content-Type: message/feedback-report\r\n" +
+		"\r\n" +
+		"Feedback-Type: abuse\r\n" +
+		"User-Agent: SomeGenerator/1.0\r\n" +
+		"Version: 1\r\n" +
+		"Original-Mail-From: <sender@originator.example>\r\n" +
+		"Source-IP: 192.0.2.1\r\n" +
+		"Arrival-Date: Mon, 02 Jan 2006 15:04:05 -0700\r\n" +
+		"--outer\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"\r\n" +
+		"From: sender@originator.example\r\n" +
+		"To: victim@example.com\r\n" +
+		"Subject: Offending message\r\n" +
+		"\r\n" +
+		"Buy now!\r\n" +
+		"--outer--\r\n")
+
+	msg := mustParse(t, raw)
+
+	if msg.BodyText != "This is an email abuse report." {
+		t.Errorf("BodyText = %q, want the human-readable report text", msg.BodyText)
+	}
+
+	fr := msg.FeedbackReport
+	if fr == nil {
+		t.Fatalf("FeedbackReport = nil, want a parsed report")
+	}
+	if fr.FeedbackType != "abuse" {
+		t.Errorf("FeedbackType = %q, want %q", fr.FeedbackType, "abuse")
+	}
+	if fr.UserAgent != "SomeGenerator/1.0" {
+		t.Errorf("UserAgent = %q, want %q", fr.UserAgent, "SomeGenerator/1.0")
+	}
+	if fr.SourceIP != "192.0.2.1" {
+		t.Errorf("SourceIP = %q, want %q", fr.SourceIP, "192.0.2.1")
+	}
+	if fr.OriginalMailFrom != "<sender@originator.example>" {
+		t.Errorf("OriginalMailFrom = %q, want %q", fr.OriginalMailFrom, "<sender@originator.example>")
+	}
+
+	if fr.OriginalMessage == nil {
+		t.Fatalf("OriginalMessage = nil, want the embedded offending message")
+	}
+	if fr.OriginalMessage.Subject != "Offending message" {
+		t.Errorf("OriginalMessage.Subject = %q, want %q", fr.OriginalMessage.Subject, "Offending message")
+	}
+	if fr.OriginalMessage.BodyText != "Buy now!" {
+		t.Errorf("OriginalMessage.BodyText = %q, want %q", fr.OriginalMessage.BodyText, "Buy now!")
+	}
+}
+
+// TestParse_NonReportMultipartHasNoFeedbackReport verifies an ordinary
+// multipart message (no report-type=feedback-report) leaves FeedbackReport
+// nil.
+func TestParse_NonReportMultipartHasNoFeedbackReport(t *testing.T) {
+	raw := []byte("From: sender@example.com\r\n" +
+		"To: recipient@example.com\r\n" +
+		"Subject: Not a report\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Just a regular message.\r\n" +
+		"--outer--\r\n")
+
+	msg := mustParse(t, raw)
+
+	if msg.FeedbackReport != nil {
+		t.Errorf("FeedbackReport = %+v, want nil", msg.FeedbackReport)
+	}
+}
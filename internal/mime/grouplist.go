@@ -0,0 +1,267 @@
+package mime
+
+import "strings"
+
+// addrEntry is one comma-separated item of an address-list header, either a
+// plain mailbox (raw holds the mailbox text) or an RFC 5322 group (name
+// holds the group display name, raw holds its unparsed member list).
+type addrEntry struct {
+	isGroup bool
+	name    string
+	raw     string
+}
+
+// parseGroupAwareAddressList parses header (the already RFC 2047 decoded,
+// unfolded value of a To/Cc/Bcc header) into a flat Address list with any
+// RFC 5322 groups unwound, plus a GroupHeader for every group seen
+// (including empty ones, which contribute no addresses). field is recorded
+// on each GroupHeader so callers parsing multiple headers can tell them
+// apart.
+//
+// enmime (and the net/mail package it wraps) don't unfold group syntax
+// themselves, so this package carries its own minimal mailbox-list scanner
+// rather than depending on that support landing upstream.
+func parseGroupAwareAddressList(header, field string) ([]Address, []GroupHeader) {
+	var addrs []Address
+	var groups []GroupHeader
+
+	for _, entry := range splitAddressEntries(header) {
+		if entry.isGroup {
+			name := unquoteDisplayName(entry.name)
+			groups = append(groups, GroupHeader{Field: field, Name: name})
+			for _, member := range splitTopLevelCommas(entry.raw) {
+				member = strings.TrimSpace(member)
+				if member == "" {
+					continue
+				}
+				addrs = append(addrs, parseMailbox(member, name))
+			}
+			continue
+		}
+		if entry.raw == "" {
+			continue
+		}
+		addrs = append(addrs, parseMailbox(entry.raw, ""))
+	}
+
+	return addrs, groups
+}
+
+// splitAddressEntries splits an address-list header into its top-level
+// comma-separated entries, recognizing RFC 5322 group syntax
+// ("name: mailbox-list;") as a single entry rather than splitting on the
+// commas inside it. Depth tracking covers quoted strings, comments, and
+// angle-addr brackets so commas/colons inside any of those never count as
+// top-level.
+func splitAddressEntries(s string) []addrEntry {
+	var entries []addrEntry
+	i, n := 0, len(s)
+
+	for i < n {
+		for i < n && (s[i] == ',' || isHeaderSpace(s[i])) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+
+		start := i
+		depthParen, depthAngle := 0, 0
+		inQuotes := false
+		colonIdx := -1
+		entryEnd := n
+
+	scan:
+		for i < n {
+			c := s[i]
+			if inQuotes {
+				if c == '\\' && i+1 < n {
+					i += 2
+					continue
+				}
+				if c == '"' {
+					inQuotes = false
+				}
+				i++
+				continue
+			}
+			switch c {
+			case '"':
+				inQuotes = true
+			case '(':
+				depthParen++
+			case ')':
+				if depthParen > 0 {
+					depthParen--
+				}
+			case '<':
+				depthAngle++
+			case '>':
+				if depthAngle > 0 {
+					depthAngle--
+				}
+			case ':':
+				if depthParen == 0 && depthAngle == 0 && colonIdx == -1 {
+					colonIdx = i
+				}
+			case ';':
+				if depthParen == 0 && depthAngle == 0 && colonIdx != -1 {
+					entryEnd = i
+					i++
+					break scan
+				}
+			case ',':
+				if depthParen == 0 && depthAngle == 0 && colonIdx == -1 {
+					entryEnd = i
+					break scan
+				}
+			}
+			i++
+		}
+
+		if colonIdx >= 0 && colonIdx < entryEnd {
+			entries = append(entries, addrEntry{
+				isGroup: true,
+				name:    strings.TrimSpace(s[start:colonIdx]),
+				raw:     strings.TrimSpace(s[colonIdx+1 : entryEnd]),
+			})
+			continue
+		}
+		if text := strings.TrimSpace(s[start:entryEnd]); text != "" {
+			entries = append(entries, addrEntry{raw: text})
+		}
+	}
+
+	return entries
+}
+
+// splitTopLevelCommas splits s on commas outside quoted strings, comments,
+// and angle-addr brackets, for parsing a group's member list.
+func splitTopLevelCommas(s string) []string {
+	var parts []string
+	var buf strings.Builder
+	depthParen, depthAngle := 0, 0
+	inQuotes := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inQuotes {
+			buf.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				buf.WriteByte(s[i])
+				continue
+			}
+			if c == '"' {
+				inQuotes = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inQuotes = true
+			buf.WriteByte(c)
+		case c == '(':
+			depthParen++
+			buf.WriteByte(c)
+		case c == ')':
+			if depthParen > 0 {
+				depthParen--
+			}
+			buf.WriteByte(c)
+		case c == '<':
+			depthAngle++
+			buf.WriteByte(c)
+		case c == '>':
+			if depthAngle > 0 {
+				depthAngle--
+			}
+			buf.WriteByte(c)
+		case c == ',' && depthParen == 0 && depthAngle == 0:
+			parts = append(parts, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	parts = append(parts, buf.String())
+	return parts
+}
+
+// parseMailbox parses a single "Display Name <addr@example.com>" or bare
+// "addr@example.com" mailbox, stripping CFWS comments and quoted-string
+// escaping from the display name. group is recorded on the returned
+// Address verbatim.
+func parseMailbox(s string, group string) Address {
+	s = strings.TrimSpace(stripComments(s))
+
+	if i := strings.LastIndex(s, "<"); i >= 0 && strings.HasSuffix(s, ">") {
+		name := unquoteDisplayName(s[:i])
+		email := strings.TrimSpace(s[i+1 : len(s)-1])
+		return Address{Name: name, Email: email, Domain: extractDomain(email), Group: group}
+	}
+
+	email := strings.TrimSpace(s)
+	return Address{Email: email, Domain: extractDomain(email), Group: group}
+}
+
+// stripComments removes RFC 5322 CFWS "(...)" comments from s, honoring
+// nesting, without touching parens inside a quoted-string.
+func stripComments(s string) string {
+	var out strings.Builder
+	depth := 0
+	inQuotes := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inQuotes {
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				out.WriteByte(s[i])
+				continue
+			}
+			if c == '"' {
+				inQuotes = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inQuotes = true
+			out.WriteByte(c)
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// unquoteDisplayName strips a surrounding quoted-string's quotes and
+// backslash-escaping from a display name, leaving unquoted text as-is.
+func unquoteDisplayName(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	inner := s[1 : len(s)-1]
+	var out strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		out.WriteByte(inner[i])
+	}
+	return out.String()
+}
+
+func isHeaderSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
@@ -0,0 +1,85 @@
+package mime
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockLevelTags produce a paragraph break (blank line) when closed.
+var blockLevelTags = map[string]bool{
+	"p": true, "div": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// skipTags are dropped entirely, including their content.
+var skipTags = map[string]bool{"script": true, "style": true, "head": true}
+
+// StripHTML renders an HTML body as plain text for preview/search: tags
+// are removed, block-level elements and <br> become line breaks, entities
+// are decoded, and whitespace collapses the way a mail client's "view as
+// text" would show it.
+func StripHTML(htmlBody string) string {
+	z := html.NewTokenizer(strings.NewReader(htmlBody))
+	var buf strings.Builder
+	skipTag := ""
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		if skipTag != "" {
+			if tt == html.EndTagToken && tok.Data == skipTag {
+				skipTag = ""
+			}
+			continue
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name := strings.ToLower(tok.Data)
+			if skipTags[name] {
+				skipTag = name
+				continue
+			}
+			if name == "br" {
+				buf.WriteString("\n")
+			}
+		case html.EndTagToken:
+			if blockLevelTags[strings.ToLower(tok.Data)] {
+				buf.WriteString("\n\n")
+			}
+		case html.TextToken:
+			buf.WriteString(tok.Data)
+		}
+	}
+
+	return normalizeStrippedText(buf.String())
+}
+
+var (
+	horizontalWhitespaceRe = regexp.MustCompile(`[ \t]{2,}`)
+	blankLineRunRe         = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalizeStrippedText collapses the raw text StripHTML accumulates into
+// readable plain text: non-breaking spaces become regular spaces, runs of
+// horizontal whitespace collapse per line, and more than one blank line in
+// a row collapses to exactly one.
+func normalizeStrippedText(s string) string {
+	s = strings.ReplaceAll(s, " ", " ")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalWhitespaceRe.ReplaceAllString(line, " "))
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLineRunRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
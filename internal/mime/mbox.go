@@ -0,0 +1,208 @@
+package mime
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// mboxDateLayout matches ctime's space-padded single-digit day ("Mon Jan
+// _2 15:04:05 2006"), the format mbox "From " envelope lines use.
+const mboxDateLayout = time.ANSIC
+
+// MboxReader reads RFC 4155 mbox-format archives (the "From " line
+// delimited format used by mutt, Thunderbird, and Apple Mail exports),
+// parsing each message into a *Message via Parse.
+//
+// internal/mailbox already imports github.com/emersion/go-mbox for bulk
+// ingestion into the store, but that path only needs raw message bytes -
+// go-mbox's Reader doesn't surface a message's envelope sender/date as
+// structured fields, which MboxReader needs in order to populate
+// Message.EnvelopeFrom/EnvelopeDate. So this package carries its own small
+// "From " line scanner for that purpose, matching the mboxrd quoting
+// convention internal/export and internal/mailbox already use.
+type MboxReader struct {
+	r       *bufio.Reader
+	pending []byte
+	done    bool
+}
+
+// NewMboxReader wraps r as an MboxReader.
+func NewMboxReader(r io.Reader) *MboxReader {
+	return &MboxReader{r: bufio.NewReader(r)}
+}
+
+// Next parses and returns the next message, with EnvelopeFrom and
+// EnvelopeDate populated from its "From " line. It returns io.EOF once the
+// stream is exhausted.
+func (mr *MboxReader) Next() (*Message, error) {
+	raw, from, date, err := mr.NextRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("mime: mbox: parse message: %w", err)
+	}
+	msg.EnvelopeFrom = from
+	msg.EnvelopeDate = date
+	return msg, nil
+}
+
+// NextRaw returns the next message's raw RFC 5322 bytes (with mboxrd
+// ">From " quoting undone) plus its envelope sender and date, without
+// parsing the message itself. Callers that only need to archive the raw
+// bytes (e.g. a bulk store import) can skip Parse's cost entirely.
+func (mr *MboxReader) NextRaw() ([]byte, string, time.Time, error) {
+	if mr.done {
+		return nil, "", time.Time{}, io.EOF
+	}
+
+	fromLine := mr.pending
+	mr.pending = nil
+	if fromLine == nil {
+		line, err := mr.readLine()
+		if err != nil {
+			mr.done = true
+			return nil, "", time.Time{}, err
+		}
+		if !isMboxFromLine(line) {
+			return nil, "", time.Time{}, fmt.Errorf("mime: mbox: expected a \"From \" envelope line, got %q", line)
+		}
+		fromLine = line
+	}
+
+	var body bytes.Buffer
+	for {
+		line, err := mr.readLine()
+		if err == io.EOF {
+			mr.done = true
+			break
+		}
+		if err != nil {
+			return nil, "", time.Time{}, err
+		}
+		if isMboxFromLine(line) {
+			mr.pending = line
+			break
+		}
+		body.Write(unquoteMboxFromLine(line))
+		body.WriteString("\r\n")
+	}
+
+	raw := bytes.TrimRight(body.Bytes(), "\r\n")
+	from, date := parseMboxFromLine(string(fromLine))
+	return raw, from, date, nil
+}
+
+// readLine reads one line, stripping a trailing "\r\n" or "\n" so callers
+// don't need to care which line ending the archive used.
+func (mr *MboxReader) readLine() ([]byte, error) {
+	line, err := mr.r.ReadString('\n')
+	if err != nil && line == "" {
+		return nil, err
+	}
+	for len(line) > 0 && (line[len(line)-1] == '\n' || line[len(line)-1] == '\r') {
+		line = line[:len(line)-1]
+	}
+	return []byte(line), nil
+}
+
+// isMboxFromLine reports whether line is a literal (unquoted) mbox
+// envelope separator.
+func isMboxFromLine(line []byte) bool {
+	return bytes.HasPrefix(line, []byte("From "))
+}
+
+// mboxLineNeedsQuote reports whether line matches "^>*From " - zero or
+// more quote markers from previous round trips, followed by "From " - the
+// mboxrd convention for a body line that would otherwise be misread as an
+// envelope separator.
+func mboxLineNeedsQuote(line []byte) bool {
+	i := 0
+	for i < len(line) && line[i] == '>' {
+		i++
+	}
+	return bytes.HasPrefix(line[i:], []byte("From "))
+}
+
+// unquoteMboxFromLine strips exactly one leading ">" from a body line that
+// MboxWriter quoted, mirroring mboxLineNeedsQuote.
+func unquoteMboxFromLine(line []byte) []byte {
+	if len(line) > 0 && line[0] == '>' && mboxLineNeedsQuote(line[1:]) {
+		return line[1:]
+	}
+	return line
+}
+
+// parseMboxFromLine parses a "From " envelope line's sender and date. A
+// malformed or missing date just leaves the zero time rather than failing
+// the whole message - mbox archives in the wild routinely have envelope
+// lines with no date at all.
+func parseMboxFromLine(line string) (from string, date time.Time) {
+	rest := strings.TrimPrefix(line, "From ")
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", time.Time{}
+	}
+	from = fields[0]
+	if len(fields) > 1 {
+		if t, err := time.Parse(mboxDateLayout, strings.Join(fields[1:], " ")); err == nil {
+			date = t
+		}
+	}
+	return from, date
+}
+
+// MboxWriter writes RFC 4155 mbox-format archives using mboxrd quoting
+// (reversible by MboxReader).
+type MboxWriter struct {
+	w io.Writer
+}
+
+// NewMboxWriter wraps w as an MboxWriter.
+func NewMboxWriter(w io.Writer) *MboxWriter {
+	return &MboxWriter{w: w}
+}
+
+// WriteMessage writes one message: a "From " envelope line built from
+// envelopeFrom/envelopeDate, followed by raw (that message's RFC 5322
+// bytes) with any line matching "^>*From " quoted by an extra leading ">".
+func (mw *MboxWriter) WriteMessage(envelopeFrom string, envelopeDate time.Time, raw []byte) error {
+	if envelopeFrom == "" {
+		envelopeFrom = "MAILER-DAEMON"
+	}
+	if envelopeDate.IsZero() {
+		envelopeDate = time.Now().UTC()
+	}
+	if _, err := fmt.Fprintf(mw.w, "From %s %s\n", envelopeFrom, envelopeDate.UTC().Format(mboxDateLayout)); err != nil {
+		return fmt.Errorf("mime: mbox: write envelope line: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if mboxLineNeedsQuote(line) {
+			if _, err := io.WriteString(mw.w, ">"); err != nil {
+				return err
+			}
+		}
+		if _, err := mw.w.Write(line); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(mw.w, "\n"); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("mime: mbox: write message body: %w", err)
+	}
+
+	_, err := io.WriteString(mw.w, "\n")
+	return err
+}
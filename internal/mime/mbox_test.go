@@ -0,0 +1,116 @@
+package mime
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestMboxReader_MultipleMessages reads a two-message mbox archive and
+// asserts both messages parse with their envelope sender/date populated.
+func TestMboxReader_MultipleMessages(t *testing.T) {
+	archive := "From alice@example.com Mon Jan  2 15:04:05 2006\r\n" +
+		"From: alice@example.com\r\n" +
+		"To: bob@example.com\r\n" +
+		"Subject: First\r\n\r\n" +
+		"Hello Bob\r\n" +
+		"\r\n" +
+		"From bob@example.com Tue Jan  3 09:00:00 2006\r\n" +
+		"From: bob@example.com\r\n" +
+		"To: alice@example.com\r\n" +
+		"Subject: Second\r\n\r\n" +
+		"Hello Alice\r\n"
+
+	mr := NewMboxReader(bytes.NewReader([]byte(archive)))
+
+	msg1, err := mr.Next()
+	if err != nil {
+		t.Fatalf("Next() message 1: %v", err)
+	}
+	if msg1.Subject != "First" {
+		t.Errorf("message 1 Subject = %q, want %q", msg1.Subject, "First")
+	}
+	if msg1.EnvelopeFrom != "alice@example.com" {
+		t.Errorf("message 1 EnvelopeFrom = %q, want %q", msg1.EnvelopeFrom, "alice@example.com")
+	}
+	wantDate := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	if !msg1.EnvelopeDate.Equal(wantDate) {
+		t.Errorf("message 1 EnvelopeDate = %v, want %v", msg1.EnvelopeDate, wantDate)
+	}
+
+	msg2, err := mr.Next()
+	if err != nil {
+		t.Fatalf("Next() message 2: %v", err)
+	}
+	if msg2.Subject != "Second" {
+		t.Errorf("message 2 Subject = %q, want %q", msg2.Subject, "Second")
+	}
+	if msg2.EnvelopeFrom != "bob@example.com" {
+		t.Errorf("message 2 EnvelopeFrom = %q, want %q", msg2.EnvelopeFrom, "bob@example.com")
+	}
+
+	if _, err := mr.Next(); err != io.EOF {
+		t.Fatalf("Next() at end = %v, want io.EOF", err)
+	}
+}
+
+// TestMboxWriter_QuotesBodyFromLines asserts a body line that looks like an
+// mbox envelope separator gets mboxrd-quoted, and a previously quoted line
+// gets an additional ">".
+func TestMboxWriter_QuotesBodyFromLines(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\n\r\nFrom the desk of Alice\r\n>From already quoted\r\n")
+
+	var buf bytes.Buffer
+	mw := NewMboxWriter(&buf)
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	if err := mw.WriteMessage("alice@example.com", date, raw); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+
+	got := buf.String()
+	if !containsLine(got, ">From the desk of Alice") {
+		t.Errorf("expected quoted From line, got:\n%s", got)
+	}
+	if !containsLine(got, ">>From already quoted") {
+		t.Errorf("expected double-quoted From line, got:\n%s", got)
+	}
+}
+
+// TestMboxReader_WriterRoundTrip writes a message through MboxWriter, reads
+// it back through MboxReader, and asserts the raw bytes and envelope fields
+// survive the round trip.
+func TestMboxReader_WriterRoundTrip(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: Round Trip\r\n\r\nFrom now on, body lines starting with From are tricky.\r\n")
+
+	var buf bytes.Buffer
+	mw := NewMboxWriter(&buf)
+	date := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	if err := mw.WriteMessage("alice@example.com", date, raw); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+
+	mr := NewMboxReader(&buf)
+	gotRaw, from, gotDate, err := mr.NextRaw()
+	if err != nil {
+		t.Fatalf("NextRaw: %v", err)
+	}
+	if !bytes.Equal(gotRaw, bytes.TrimRight(raw, "\r\n")) {
+		t.Errorf("round-tripped raw = %q, want %q", gotRaw, bytes.TrimRight(raw, "\r\n"))
+	}
+	if from != "alice@example.com" {
+		t.Errorf("round-tripped From = %q, want %q", from, "alice@example.com")
+	}
+	if !gotDate.Equal(date) {
+		t.Errorf("round-tripped Date = %v, want %v", gotDate, date)
+	}
+}
+
+func containsLine(s, line string) bool {
+	for _, l := range bytes.Split([]byte(s), []byte("\n")) {
+		if bytes.Equal(bytes.TrimRight(l, "\r"), []byte(line)) {
+			return true
+		}
+	}
+	return false
+}
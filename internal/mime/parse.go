@@ -0,0 +1,231 @@
+// Package mime wraps enmime to turn a raw RFC 5322 message into the
+// structured fields the store and search packages need, adding the
+// pieces enmime doesn't cover itself: UTF-7 decoding, a plain-text HTML
+// fallback, and domain extraction for From/To/Cc/Bcc.
+package mime
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jhillyerd/enmime"
+)
+
+// Message is the structured result of parsing one raw MIME message.
+type Message struct {
+	From       []Address
+	To         []Address
+	Cc         []Address
+	Bcc        []Address
+	Subject    string
+	Date       time.Time
+	MessageID  string
+	References []string
+
+	// Groups records every RFC 5322 group seen in To/Cc/Bcc (e.g.
+	// "undisclosed-recipients:;"), including empty ones that unfold into no
+	// Address entries at all. Without this, an empty group and a header
+	// with no addresses look identical.
+	Groups []GroupHeader
+
+	// EnvelopeFrom and EnvelopeDate come from an mbox "From " line, when
+	// this message was read via MboxReader. Zero/empty otherwise.
+	EnvelopeFrom string
+	EnvelopeDate time.Time
+
+	// FeedbackReport is set when this message is an RFC 5965 Abuse
+	// Reporting Format complaint (a multipart/report with
+	// report-type=feedback-report), nil otherwise.
+	FeedbackReport *FeedbackReport
+
+	BodyText string
+	BodyHTML string
+
+	// Attachments holds every non-body part of the message (files, inline
+	// images), in document order.
+	Attachments []Attachment
+
+	// SourceCharset is the charset declared on the part BodyText/BodyHTML
+	// was decoded from (e.g. "iso-8859-1", "utf-7"), stored alongside the
+	// message so export can reproduce the original encoding losslessly.
+	SourceCharset string
+
+	// Warnings holds non-fatal issues worth surfacing, such as a charset
+	// that decoded with replacement characters. Unlike Errors, these don't
+	// mean the message failed to parse.
+	Warnings []string
+
+	// Errors collects enmime's own parse errors (malformed parts, bad
+	// boundaries); a non-empty Errors doesn't mean Parse failed, only that
+	// enmime had to make a best-effort recovery somewhere.
+	Errors []error
+}
+
+// GetBodyText returns BodyText, falling back to a plain-text rendering of
+// BodyHTML if there's no text part at all.
+func (m *Message) GetBodyText() string {
+	if m.BodyText != "" {
+		return m.BodyText
+	}
+	if m.BodyHTML != "" {
+		return StripHTML(m.BodyHTML)
+	}
+	return ""
+}
+
+// GetFirstFrom returns the first From address, or the zero Address if the
+// message has none.
+func (m *Message) GetFirstFrom() Address {
+	if len(m.From) == 0 {
+		return Address{}
+	}
+	return m.From[0]
+}
+
+// Parse parses raw as an RFC 5322 message via enmime, then layers on
+// UTF-7 decoding for the body's primary text part (enmime's charset
+// conversion doesn't support UTF-7) and a replacement-character check
+// that feeds Warnings.
+func Parse(raw []byte) (*Message, error) {
+	env, err := enmime.ReadEnvelope(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("mime: parse: %w", err)
+	}
+
+	msg := &Message{
+		Subject:       env.GetHeader("Subject"),
+		MessageID:     strings.Trim(env.GetHeader("Message-ID"), "<>"),
+		References:    parseReferences(env.GetHeader("References")),
+		BodyText:      env.Text,
+		BodyHTML:      env.HTML,
+		SourceCharset: "utf-8",
+	}
+	msg.Date, _ = parseDate(env.GetHeader("Date"))
+
+	var groups []GroupHeader
+	var g []GroupHeader
+	msg.From, _ = addressList(env, "From")
+	msg.To, g = addressList(env, "To")
+	groups = append(groups, g...)
+	msg.Cc, g = addressList(env, "Cc")
+	groups = append(groups, g...)
+	msg.Bcc, g = addressList(env, "Bcc")
+	groups = append(groups, g...)
+	msg.Groups = groups
+	msg.FeedbackReport = parseFeedbackReport(env.Root)
+	msg.Attachments = parseAttachments(env.Root)
+
+	for _, e := range env.Errors {
+		msg.Errors = append(msg.Errors, fmt.Errorf("%s: %s", e.Name, e.Detail))
+	}
+
+	if part := primaryTextPart(env.Root); part != nil {
+		charsetName := normalizeCharsetName(part.Charset)
+		msg.SourceCharset = charsetName
+		if charsetName == "utf-7" {
+			decoded := decodeUTF7(part.Content)
+			if isHTMLPart(part) {
+				msg.BodyHTML = decoded
+			} else {
+				msg.BodyText = decoded
+			}
+		}
+	}
+
+	if strings.ContainsRune(msg.BodyText, utf8.RuneError) || strings.ContainsRune(msg.BodyHTML, utf8.RuneError) {
+		msg.Warnings = append(msg.Warnings, fmt.Sprintf("charset %q: decoding produced replacement characters", msg.SourceCharset))
+	}
+
+	return msg, nil
+}
+
+// addressList parses field (From/To/Cc/Bcc) into a flat Address list, with
+// RFC 5322 group syntax unfolded into regular members (see
+// parseGroupAwareAddressList) since enmime's own AddressList doesn't do
+// this. env.GetHeader already applies RFC 2047 decoding and unfolds line
+// continuations, so the group scanner just needs to split on
+// colons/semicolons/commas.
+func addressList(env *enmime.Envelope, field string) ([]Address, []GroupHeader) {
+	header := env.GetHeader(field)
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	return parseGroupAwareAddressList(header, field)
+}
+
+// isBodyPart reports whether part is message body content rather than an
+// attachment: not explicitly flagged as an attachment disposition, has no
+// filename, and is a text/* part. message/feedback-report and
+// message/rfc822 (an ARF complaint's machine-readable report and embedded
+// original message, see parseFeedbackReport) are never body content either
+// way, but are excluded here too since they aren't text/*.
+func isBodyPart(part *enmime.Part) bool {
+	disp := strings.ToLower(strings.TrimSpace(part.Disposition))
+	if strings.HasPrefix(disp, "attachment") {
+		return false
+	}
+	if part.FileName != "" {
+		return false
+	}
+	return strings.HasPrefix(contentTypeOnly(part.ContentType), "text/")
+}
+
+func isHTMLPart(part *enmime.Part) bool {
+	return contentTypeOnly(part.ContentType) == "text/html"
+}
+
+func contentTypeOnly(contentType string) string {
+	ct := contentType
+	if i := strings.Index(ct, ";"); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
+
+// primaryTextPart walks part's tree for the body part BodyText/BodyHTML
+// came from, preferring text/plain over text/html the same way
+// GetBodyText does. It doesn't descend into a message/rfc822 part's own
+// subtree, since that's an embedded original message (e.g. an ARF
+// complaint's attached offending message, see parseFeedbackReport) with its
+// own independent body, not a candidate for the outer message's body.
+func primaryTextPart(root *enmime.Part) *enmime.Part {
+	var plain, html *enmime.Part
+	var walk func(p *enmime.Part)
+	walk = func(p *enmime.Part) {
+		if p == nil || plain != nil {
+			return
+		}
+		if isBodyPart(p) {
+			switch contentTypeOnly(p.ContentType) {
+			case "text/plain":
+				plain = p
+			case "text/html":
+				if html == nil {
+					html = p
+				}
+			}
+		}
+		if contentTypeOnly(p.ContentType) == "message/rfc822" {
+			walk(p.NextSibling)
+			return
+		}
+		walk(p.FirstChild)
+		walk(p.NextSibling)
+	}
+	walk(root)
+	if plain != nil {
+		return plain
+	}
+	return html
+}
+
+func normalizeCharsetName(charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" {
+		return "utf-8"
+	}
+	return charset
+}
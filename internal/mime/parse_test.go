@@ -363,7 +363,8 @@ func TestParse_Latin1Charset(t *testing.T) {
 	}
 }
 
-// TestParse_RFC2822GroupAddress verifies RFC 2822 group address syntax is handled.
+// TestParse_RFC2822GroupAddress verifies an empty RFC 5322 group unfolds to
+// zero addresses but is still recorded in Groups.
 // Group syntax: "group-name: addr1, addr2, ...;"
 func TestParse_RFC2822GroupAddress(t *testing.T) {
 	// Message with undisclosed-recipients group (common in BCC scenarios)
@@ -374,17 +375,19 @@ func TestParse_RFC2822GroupAddress(t *testing.T) {
 
 	msg := mustParse(t, raw)
 
-	// Group with no addresses should result in empty To list
-	t.Logf("To addresses: %v", msg.To)
-	t.Logf("Parsing errors: %v", msg.Errors)
-
-	// Should not crash - that's the main requirement
-	if msg.Subject != "Test" {
-		t.Errorf("Subject = %q, want %q", msg.Subject, "Test")
+	if len(msg.To) != 0 {
+		t.Errorf("To = %v, want empty", msg.To)
+	}
+	if len(msg.Groups) != 1 {
+		t.Fatalf("Groups = %v, want 1 entry", msg.Groups)
+	}
+	if got := msg.Groups[0]; got.Field != "To" || got.Name != "undisclosed-recipients" {
+		t.Errorf("Groups[0] = %+v, want {Field: To, Name: undisclosed-recipients}", got)
 	}
 }
 
-// TestParse_RFC2822GroupAddressWithMembers verifies group with actual addresses.
+// TestParse_RFC2822GroupAddressWithMembers verifies group members are
+// unfolded into To as regular addresses tagged with their group name.
 func TestParse_RFC2822GroupAddressWithMembers(t *testing.T) {
 	// Group with member addresses
 	raw := makeRawEmail(emailOptions{
@@ -394,13 +397,37 @@ func TestParse_RFC2822GroupAddressWithMembers(t *testing.T) {
 
 	msg := mustParse(t, raw)
 
-	t.Logf("To addresses: %v", msg.To)
-	t.Logf("Parsing errors: %v", msg.Errors)
+	if len(msg.To) != 2 {
+		t.Fatalf("To = %v, want 2 addresses", msg.To)
+	}
+	for i, want := range []string{"alice@example.com", "bob@example.com"} {
+		if msg.To[i].Email != want || msg.To[i].Group != "team" {
+			t.Errorf("To[%d] = %+v, want Email %q, Group \"team\"", i, msg.To[i], want)
+		}
+	}
+	if len(msg.Groups) != 1 || msg.Groups[0].Name != "team" {
+		t.Errorf("Groups = %v, want [{To team}]", msg.Groups)
+	}
+}
 
-	// Ideally we'd extract alice and bob from the group
-	// Let's see how enmime handles this
-	if msg.Subject != "Test" {
-		t.Errorf("Subject = %q, want %q", msg.Subject, "Test")
+// TestParse_RFC2822GroupAddressMixedWithPlain verifies a group can appear
+// alongside ordinary mailboxes in the same header.
+func TestParse_RFC2822GroupAddressMixedWithPlain(t *testing.T) {
+	raw := makeRawEmail(emailOptions{
+		To:   "carol@example.com, team: alice@example.com, bob@example.com;",
+		Body: "Body",
+	})
+
+	msg := mustParse(t, raw)
+
+	if len(msg.To) != 3 {
+		t.Fatalf("To = %v, want 3 addresses", msg.To)
+	}
+	if msg.To[0].Email != "carol@example.com" || msg.To[0].Group != "" {
+		t.Errorf("To[0] = %+v, want ungrouped carol@example.com", msg.To[0])
+	}
+	if msg.To[1].Email != "alice@example.com" || msg.To[1].Group != "team" {
+		t.Errorf("To[1] = %+v, want alice@example.com in group team", msg.To[1])
 	}
 }
 
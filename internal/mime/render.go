@@ -0,0 +1,217 @@
+package mime
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// RenderOptions controls the extra fidelity RenderHTML can preserve over
+// StripHTML's preview-only rendering.
+type RenderOptions struct {
+	// PreserveWhitespaceInPre stops <pre> content from having its
+	// whitespace collapsed, so fixed-width formatting (code, ASCII art)
+	// survives.
+	PreserveWhitespaceInPre bool
+
+	// PreserveListMarkers emits "- " before each <li> under a <ul>, and
+	// "1. ", "2. ", ... (counted per list, restarting at each new
+	// <ol>) under an <ol>.
+	PreserveListMarkers bool
+
+	// PreserveBlockquotes prefixes every line inside a <blockquote> with
+	// "> ", nesting-aware: a <blockquote> inside a <blockquote> gets
+	// "> > ".
+	PreserveBlockquotes bool
+
+	// PreserveLinks appends " (href)" after a link's text when its href
+	// attribute differs from the text itself, so the destination isn't
+	// silently lost the way plain text-extraction would lose it.
+	PreserveLinks bool
+
+	// HardWrapAt, if positive, word-wraps output lines to at most this
+	// many columns. Applies uniformly, including to preserved <pre> and
+	// <blockquote> lines - combining HardWrapAt with
+	// PreserveWhitespaceInPre can still rewrap fixed-width content.
+	HardWrapAt int
+}
+
+// listFrame tracks one level of list nesting while rendering, so <li>
+// knows whether to emit a bullet or an incrementing ordinal.
+type listFrame struct {
+	ordered bool
+	counter int
+}
+
+// RenderHTML renders an HTML body as plain text for full-body display or
+// quoting-on-reply, where StripHTML's aggressive whitespace collapsing and
+// silent link-text-only rendering lose too much. opts selects which
+// extra fidelity to preserve; an all-zero RenderOptions renders
+// equivalently to StripHTML modulo the DOM-tree-vs-tokenizer tag-soup
+// recovery differences between golang.org/x/net/html's Parse and
+// NewTokenizer entry points.
+func RenderHTML(htmlBody string, opts RenderOptions) string {
+	doc, err := html.Parse(strings.NewReader(htmlBody))
+	if err != nil {
+		return StripHTML(htmlBody)
+	}
+
+	var listStack []listFrame
+	preDepth := 0
+	rendered := renderNode(doc, opts, &listStack, &preDepth)
+
+	rendered = strings.ReplaceAll(rendered, "\r\n", "\n")
+	rendered = strings.ReplaceAll(rendered, "\r", "\n")
+	rendered = blankLineRunRe.ReplaceAllString(rendered, "\n\n")
+	rendered = strings.TrimSpace(rendered)
+
+	if opts.HardWrapAt > 0 {
+		rendered = hardWrapLines(rendered, opts.HardWrapAt)
+	}
+	return rendered
+}
+
+// renderNode renders n and its subtree to text, applying opts.
+func renderNode(n *html.Node, opts RenderOptions, listStack *[]listFrame, preDepth *int) string {
+	switch n.Type {
+	case html.TextNode:
+		if *preDepth > 0 && opts.PreserveWhitespaceInPre {
+			return n.Data
+		}
+		return horizontalWhitespaceRe.ReplaceAllString(n.Data, " ")
+
+	case html.ElementNode:
+		name := strings.ToLower(n.Data)
+		if skipTags[name] {
+			return ""
+		}
+
+		switch name {
+		case "br":
+			return "\n"
+
+		case "pre":
+			*preDepth++
+			inner := renderChildren(n, opts, listStack, preDepth)
+			*preDepth--
+			return "\n\n" + inner + "\n\n"
+
+		case "blockquote":
+			inner := renderChildren(n, opts, listStack, preDepth)
+			if !opts.PreserveBlockquotes {
+				return "\n\n" + inner + "\n\n"
+			}
+			return "\n\n" + prefixLines(strings.TrimSpace(inner), "> ") + "\n\n"
+
+		case "ul", "ol":
+			if !opts.PreserveListMarkers {
+				return "\n\n" + renderChildren(n, opts, listStack, preDepth) + "\n\n"
+			}
+			*listStack = append(*listStack, listFrame{ordered: name == "ol"})
+			inner := renderChildren(n, opts, listStack, preDepth)
+			*listStack = (*listStack)[:len(*listStack)-1]
+			return "\n\n" + strings.TrimRight(inner, "\n") + "\n\n"
+
+		case "li":
+			inner := strings.TrimSpace(renderChildren(n, opts, listStack, preDepth))
+			if opts.PreserveListMarkers && len(*listStack) > 0 {
+				top := &(*listStack)[len(*listStack)-1]
+				if top.ordered {
+					top.counter++
+					return fmt.Sprintf("%d. %s\n", top.counter, inner)
+				}
+				return "- " + inner + "\n"
+			}
+			return inner + "\n"
+
+		case "a":
+			inner := strings.TrimSpace(renderChildren(n, opts, listStack, preDepth))
+			if opts.PreserveLinks {
+				if href := attrValue(n, "href"); href != "" && href != inner {
+					return fmt.Sprintf("%s (%s)", inner, href)
+				}
+			}
+			return inner
+
+		default:
+			inner := renderChildren(n, opts, listStack, preDepth)
+			if blockLevelTags[name] {
+				return inner + "\n\n"
+			}
+			return inner
+		}
+
+	default:
+		return renderChildren(n, opts, listStack, preDepth)
+	}
+}
+
+func renderChildren(n *html.Node, opts RenderOptions, listStack *[]listFrame, preDepth *int) string {
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		buf.WriteString(renderNode(c, opts, listStack, preDepth))
+	}
+	return buf.String()
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// prefixLines prepends prefix to every line of s, trimming prefix's
+// trailing space on blank lines (the conventional ">" rather than "> " on
+// an empty quoted line).
+func prefixLines(s, prefix string) string {
+	if s == "" {
+		return strings.TrimRight(prefix, " ")
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hardWrapLines word-wraps every line of s to at most width columns
+// independently, so distinct paragraphs/list items/quoted lines never
+// merge into one wrapped block.
+func hardWrapLines(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	var out []string
+	for _, line := range lines {
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+func wrapLine(line string, width int) []string {
+	if len(line) <= width {
+		return []string{line}
+	}
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return []string{line}
+	}
+
+	var wrapped []string
+	cur := words[0]
+	for _, w := range words[1:] {
+		if len(cur)+1+len(w) > width {
+			wrapped = append(wrapped, cur)
+			cur = w
+			continue
+		}
+		cur += " " + w
+	}
+	return append(wrapped, cur)
+}
@@ -0,0 +1,89 @@
+package mime
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRenderHTML_Default asserts an all-zero RenderOptions renders the
+// same blank-line paragraph breaks as StripHTML, just via the tree-walk
+// path instead of the tokenizer.
+func TestRenderHTML_Default(t *testing.T) {
+	got := RenderHTML("<p>Hello</p><p>World</p>", RenderOptions{})
+	want := "Hello\n\nWorld"
+	if got != want {
+		t.Errorf("RenderHTML() = %q, want %q", got, want)
+	}
+}
+
+// TestRenderHTML_PreserveWhitespaceInPre asserts <pre> content keeps its
+// internal whitespace when requested, unlike StripHTML's collapsing.
+func TestRenderHTML_PreserveWhitespaceInPre(t *testing.T) {
+	htmlBody := "<pre>line one\n  indented line\nline three</pre>"
+
+	collapsed := StripHTML(htmlBody)
+	if strings.Contains(collapsed, "  indented") {
+		t.Fatalf("StripHTML unexpectedly preserved whitespace: %q", collapsed)
+	}
+
+	preserved := RenderHTML(htmlBody, RenderOptions{PreserveWhitespaceInPre: true})
+	want := "line one\n  indented line\nline three"
+	if preserved != want {
+		t.Errorf("RenderHTML(PreserveWhitespaceInPre) = %q, want %q", preserved, want)
+	}
+}
+
+// TestRenderHTML_PreserveListMarkers asserts <ul>/<ol> items get bullet
+// and incrementing ordinal markers respectively.
+func TestRenderHTML_PreserveListMarkers(t *testing.T) {
+	htmlBody := "<ul><li>first</li><li>second</li></ul>" +
+		"<ol><li>alpha</li><li>beta</li></ol>"
+
+	got := RenderHTML(htmlBody, RenderOptions{PreserveListMarkers: true})
+	want := "- first\n- second\n\n1. alpha\n2. beta"
+	if got != want {
+		t.Errorf("RenderHTML(PreserveListMarkers) =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestRenderHTML_PreserveBlockquotes asserts blockquote content is
+// prefixed per line, with nested blockquotes doubling the prefix.
+func TestRenderHTML_PreserveBlockquotes(t *testing.T) {
+	htmlBody := "<blockquote>outer<blockquote>inner</blockquote></blockquote>"
+
+	got := RenderHTML(htmlBody, RenderOptions{PreserveBlockquotes: true})
+	want := "> outer\n>\n> > inner"
+	if got != want {
+		t.Errorf("RenderHTML(PreserveBlockquotes) = %q, want %q", got, want)
+	}
+}
+
+// TestRenderHTML_PreserveLinks asserts a link's href is appended when it
+// differs from the link text, and omitted when the href is just the text
+// repeated (e.g. a bare-URL autolink).
+func TestRenderHTML_PreserveLinks(t *testing.T) {
+	got := RenderHTML(`<a href="https://example.com/path">click here</a>`, RenderOptions{PreserveLinks: true})
+	want := "click here (https://example.com/path)"
+	if got != want {
+		t.Errorf("RenderHTML(PreserveLinks) = %q, want %q", got, want)
+	}
+
+	got = RenderHTML(`<a href="https://example.com">https://example.com</a>`, RenderOptions{PreserveLinks: true})
+	want = "https://example.com"
+	if got != want {
+		t.Errorf("RenderHTML(PreserveLinks) on matching href/text = %q, want %q", got, want)
+	}
+}
+
+// TestRenderHTML_HardWrapAt asserts output lines are word-wrapped to the
+// requested width.
+func TestRenderHTML_HardWrapAt(t *testing.T) {
+	htmlBody := "<p>" + strings.Repeat("word ", 20) + "</p>"
+
+	got := RenderHTML(htmlBody, RenderOptions{HardWrapAt: 20})
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > 20 {
+			t.Errorf("line exceeds 20 columns: %q", line)
+		}
+	}
+}
@@ -0,0 +1,573 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/marcboeker/go-duckdb"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// DuckDBEngine implements Engine's analytics-style methods (the Aggregate*,
+// SubAggregate, Search, SearchFast, and ListMessages methods) by querying a
+// Parquet tree via DuckDB's read_parquet table function, the same tree
+// RebuildAnalytics writes and VirtualFolders/Materialize read. Per-message
+// lookups (GetMessage, GetMessageBySourceID, GetAttachment) and the stats
+// methods delegate to a paired SQLiteEngine, since the Parquet tree has no
+// message_bodies or full attachment content - only what RebuildAnalytics's
+// flat tables carry (see messagesSourceQuery and flatTables).
+//
+// PostgresEngine pairs with a DuckDBEngine the same way; cmd/msgvault's
+// serve grpc/exporter commands use a DuckDBEngine directly as their
+// query.Engine when Postgres isn't in the picture at all.
+type DuckDBEngine struct {
+	analyticsDir string
+	db           *sql.DB // DuckDB, queries analyticsDir via read_parquet
+
+	// sqliteEngine backs GetMessage/GetMessageBySourceID/GetAttachment and
+	// the stats methods; nil if this DuckDBEngine was opened without a
+	// SQLite database (e.g. PostgresEngine's analytics pairing only needs
+	// the Parquet-backed methods; GetMessage there runs against Postgres
+	// directly instead).
+	sqliteEngine *SQLiteEngine
+
+	// defaultTextMatch mirrors PostgresEngine.defaultTextMatch: the
+	// search.MatchType applied to ""/"subject" clauses parsed without an
+	// explicit match prefix.
+	defaultTextMatch search.MatchType
+}
+
+// DuckDBOption configures a DuckDBEngine at construction time.
+type DuckDBOption func(*DuckDBEngine)
+
+// WithDuckDBDefaultTextMatch sets the default search.MatchType applied to
+// ""/"subject" clauses parsed without an explicit "="/"^"/"~" prefix.
+func WithDuckDBDefaultTextMatch(m search.MatchType) DuckDBOption {
+	return func(e *DuckDBEngine) {
+		e.defaultTextMatch = m
+	}
+}
+
+// NewDuckDBEngine opens an in-memory DuckDB connection for querying the
+// Parquet analytics tree at analyticsDir. If sqliteDB is non-nil, it's
+// wrapped in a SQLiteEngine (dbPath is carried along only to identify that
+// database in error messages, since sqliteDB is already open) to back the
+// per-message and stats methods; callers that only need the Parquet-backed
+// analytics methods (PostgresEngine's pairing) can pass a nil sqliteDB.
+// NewDuckDBEngine does not take ownership of sqliteDB - the caller remains
+// responsible for closing it.
+func NewDuckDBEngine(analyticsDir, dbPath string, sqliteDB *sql.DB, opts ...DuckDBOption) (*DuckDBEngine, error) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("duckdb engine: open duckdb: %w", err)
+	}
+
+	e := &DuckDBEngine{analyticsDir: analyticsDir, db: db}
+	if sqliteDB != nil {
+		e.sqliteEngine = NewSQLiteEngine(sqliteDB, dbPath)
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Close releases the DuckDB connection. It does not close the SQLite
+// database passed to NewDuckDBEngine, since that connection is owned by
+// the caller.
+func (e *DuckDBEngine) Close() error {
+	return e.db.Close()
+}
+
+func (e *DuckDBEngine) requireSQLite() error {
+	if e.sqliteEngine == nil {
+		return fmt.Errorf("duckdb engine: no sqlite database configured")
+	}
+	return nil
+}
+
+// GetMessage delegates to the paired SQLiteEngine, since the Parquet tree
+// carries no message body and isn't the source of truth for a single-row
+// lookup.
+func (e *DuckDBEngine) GetMessage(ctx context.Context, id int64) (*Message, error) {
+	if err := e.requireSQLite(); err != nil {
+		return nil, err
+	}
+	return e.sqliteEngine.GetMessage(ctx, id)
+}
+
+// GetMessageBySourceID delegates to the paired SQLiteEngine.
+func (e *DuckDBEngine) GetMessageBySourceID(ctx context.Context, sourceMessageID string) (*Message, error) {
+	if err := e.requireSQLite(); err != nil {
+		return nil, err
+	}
+	return e.sqliteEngine.GetMessageBySourceID(ctx, sourceMessageID)
+}
+
+// GetAttachment delegates to the paired SQLiteEngine.
+func (e *DuckDBEngine) GetAttachment(ctx context.Context, id int64) (*AttachmentContent, error) {
+	if err := e.requireSQLite(); err != nil {
+		return nil, err
+	}
+	return e.sqliteEngine.GetAttachment(ctx, id)
+}
+
+// GetTotalStats delegates to the paired SQLiteEngine.
+func (e *DuckDBEngine) GetTotalStats(ctx context.Context, opts StatsOptions) (*TotalStats, error) {
+	if err := e.requireSQLite(); err != nil {
+		return nil, err
+	}
+	return e.sqliteEngine.GetTotalStats(ctx, opts)
+}
+
+// ListAccounts delegates to the paired SQLiteEngine.
+func (e *DuckDBEngine) ListAccounts(ctx context.Context) ([]AccountInfo, error) {
+	if err := e.requireSQLite(); err != nil {
+		return nil, err
+	}
+	return e.sqliteEngine.ListAccounts(ctx)
+}
+
+// messagesGlob is the read_parquet pattern matching every year partition
+// RebuildAnalytics writes under analyticsDir/messages.
+func (e *DuckDBEngine) messagesGlob() string {
+	return escapeParquetPath(filepath.Join(e.analyticsDir, "messages", "*", "*.parquet"))
+}
+
+func (e *DuckDBEngine) flatGlob(table string) string {
+	return escapeParquetPath(filepath.Join(e.analyticsDir, table, table+".parquet"))
+}
+
+// Search runs q's full-text terms (lowered the same way SearchFast lowers
+// q's whole AST) against the Parquet tree.
+func (e *DuckDBEngine) Search(ctx context.Context, q *search.Query, limit, offset int) ([]*Message, error) {
+	return e.SearchFast(ctx, q, MessageFilter{}, limit, offset)
+}
+
+// SearchFast lowers q's AST with lowerSearchASTWithDefault and scopes it by
+// filter, querying the Parquet tree via read_parquet.
+func (e *DuckDBEngine) SearchFast(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]*Message, error) {
+	where, args := e.duckFilterClause(filter, "m")
+
+	astWhere, astArgs, err := lowerSearchASTWithDefault(q.AST, "m", e.defaultTextMatch)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb engine: search: %w", err)
+	}
+	if astWhere != "1=1" {
+		where = append(where, astWhere)
+		args = append(args, astArgs...)
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM read_parquet('%s') m
+		%s
+		ORDER BY m.sent_at DESC
+		LIMIT %d OFFSET %d`, e.messagesGlob(), whereSQL, limit, offset)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+// ListMessages queries the Parquet tree by filter alone, with no text search.
+func (e *DuckDBEngine) ListMessages(ctx context.Context, filter MessageFilter) ([]*Message, error) {
+	where, args := e.duckFilterClause(filter, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM read_parquet('%s') m
+		%s
+		ORDER BY m.sent_at %s
+		LIMIT %d OFFSET %d`, e.messagesGlob(), whereSQL, sortDirectionSQL(filter), limit, filter.Offset)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+func (e *DuckDBEngine) queryMessages(ctx context.Context, query string, args ...any) ([]*Message, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb engine: query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanMessageRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("duckdb engine: scan message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// AggregateBySender aggregates message counts by sender address.
+func (e *DuckDBEngine) AggregateBySender(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	return e.aggregateByRecipientType(ctx, opts, "'from'")
+}
+
+// AggregateByRecipient aggregates message counts by recipient address
+// (to and cc).
+func (e *DuckDBEngine) AggregateByRecipient(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	return e.aggregateByRecipientType(ctx, opts, "'to', 'cc'")
+}
+
+func (e *DuckDBEngine) aggregateByRecipientType(ctx context.Context, opts AggregateOptions, recipientTypesSQL string) ([]AggregateRow, error) {
+	where, args := e.duckAggregateFilterClause(opts, "m")
+	whereSQL := fmt.Sprintf("mr.recipient_type IN (%s)", recipientTypesSQL)
+	if len(where) > 0 {
+		whereSQL += " AND " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.email_address,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM read_parquet('%s') mr
+		JOIN read_parquet('%s') p ON p.id = mr.participant_id
+		JOIN read_parquet('%s') m ON m.id = mr.message_id
+		LEFT JOIN read_parquet('%s') a ON a.message_id = m.id
+		WHERE %s
+		GROUP BY p.email_address
+		ORDER BY COUNT(DISTINCT m.id) DESC`,
+		e.flatGlob("message_recipients"), e.flatGlob("participants"), e.messagesGlob(), e.flatGlob("attachments"), whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByDomain aggregates message counts by recipient domain.
+func (e *DuckDBEngine) AggregateByDomain(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := e.duckAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.domain,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM read_parquet('%s') mr
+		JOIN read_parquet('%s') p ON p.id = mr.participant_id
+		JOIN read_parquet('%s') m ON m.id = mr.message_id
+		LEFT JOIN read_parquet('%s') a ON a.message_id = m.id
+		%s
+		GROUP BY p.domain
+		ORDER BY COUNT(DISTINCT m.id) DESC`,
+		e.flatGlob("message_recipients"), e.flatGlob("participants"), e.messagesGlob(), e.flatGlob("attachments"), whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByLabel aggregates message counts by label name.
+func (e *DuckDBEngine) AggregateByLabel(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := e.duckAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT l.name,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM read_parquet('%s') ml
+		JOIN read_parquet('%s') l ON l.id = ml.label_id
+		JOIN read_parquet('%s') m ON m.id = ml.message_id
+		LEFT JOIN read_parquet('%s') a ON a.message_id = m.id
+		%s
+		GROUP BY l.name
+		ORDER BY COUNT(DISTINCT m.id) DESC`,
+		e.flatGlob("message_labels"), e.flatGlob("labels"), e.messagesGlob(), e.flatGlob("attachments"), whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByTime buckets message counts by opts.TimeGranularity.
+func (e *DuckDBEngine) AggregateByTime(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	format, _ := timeBucketSQL(opts.TimeGranularity)
+	where, args := e.duckAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT strftime(m.sent_at, '%s'),
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM read_parquet('%s') m
+		LEFT JOIN read_parquet('%s') a ON a.message_id = m.id
+		%s
+		GROUP BY 1
+		ORDER BY 1`, strftimeFormat(format), e.messagesGlob(), e.flatGlob("attachments"), whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateBySize buckets messages by size_estimate against buckets (upper
+// bounds in bytes), the analog of exporter's SizeHistogram: each message is
+// counted once, in the first bucket whose bound it does not exceed, labeled
+// "<=N", or else in the final, unbounded bucket, labeled ">N".
+func (e *DuckDBEngine) AggregateBySize(ctx context.Context, buckets []int64) ([]AggregateRow, error) {
+	if e.sqliteEngine != nil {
+		return e.sqliteEngine.AggregateBySize(ctx, buckets)
+	}
+	return nil, fmt.Errorf("duckdb engine: aggregate by size: no sqlite database configured")
+}
+
+// SubAggregate aggregates the messages matching filter by view.
+func (e *DuckDBEngine) SubAggregate(ctx context.Context, filter MessageFilter, view View, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := e.duckFilterClause(filter, "m")
+	optsWhere, optsArgs := e.duckAggregateFilterClause(opts, "m")
+	where = append(where, optsWhere...)
+	args = append(args, optsArgs...)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	switch view {
+	case ViewRecipients:
+		query := fmt.Sprintf(`
+			SELECT p.email_address, COUNT(DISTINCT m.id), 0, 0
+			FROM read_parquet('%s') m
+			JOIN read_parquet('%s') mr ON mr.message_id = m.id AND mr.recipient_type IN ('to', 'cc')
+			JOIN read_parquet('%s') p ON p.id = mr.participant_id
+			%s
+			GROUP BY p.email_address
+			ORDER BY COUNT(DISTINCT m.id) DESC`,
+			e.messagesGlob(), e.flatGlob("message_recipients"), e.flatGlob("participants"), whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	case ViewLabels:
+		query := fmt.Sprintf(`
+			SELECT l.name, COUNT(DISTINCT m.id), 0, 0
+			FROM read_parquet('%s') m
+			JOIN read_parquet('%s') ml ON ml.message_id = m.id
+			JOIN read_parquet('%s') l ON l.id = ml.label_id
+			%s
+			GROUP BY l.name
+			ORDER BY COUNT(DISTINCT m.id) DESC`,
+			e.messagesGlob(), e.flatGlob("message_labels"), e.flatGlob("labels"), whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	default:
+		return nil, fmt.Errorf("duckdb engine: sub-aggregate: unsupported view %v", view)
+	}
+}
+
+// ListThreads groups the messages matching filter by conversation, the
+// Parquet-backed counterpart PostgresEngine.ListThreads delegates to when
+// paired with a DuckDBEngine. UnreadCount is always 0: RebuildAnalytics's
+// flat messages table carries no is_unread column (see messagesSourceQuery
+// in rebuild_analytics.go), so ThreadMode's ThreadUnread restriction can't
+// be applied here and is treated the same as ThreadOn.
+func (e *DuckDBEngine) ListThreads(ctx context.Context, filter MessageFilter) ([]ThreadSummary, error) {
+	where, args := e.duckFilterClause(filter, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.conversation_id,
+		       COUNT(DISTINCT m.id),
+		       0,
+		       BOOL_OR(m.has_attachments),
+		       LIST(DISTINCT p.email_address) FILTER (p.email_address IS NOT NULL),
+		       LIST(DISTINCT l.name) FILTER (l.name IS NOT NULL),
+		       MIN(m.sent_at),
+		       MAX(m.sent_at),
+		       ARG_MAX(m.subject, m.sent_at)
+		FROM read_parquet('%s') m
+		LEFT JOIN read_parquet('%s') mr ON mr.message_id = m.id
+		LEFT JOIN read_parquet('%s') p ON p.id = mr.participant_id
+		LEFT JOIN read_parquet('%s') ml ON ml.message_id = m.id
+		LEFT JOIN read_parquet('%s') l ON l.id = ml.label_id
+		%s
+		GROUP BY m.conversation_id
+		ORDER BY MAX(m.sent_at) DESC`,
+		e.messagesGlob(), e.flatGlob("message_recipients"), e.flatGlob("participants"),
+		e.flatGlob("message_labels"), e.flatGlob("labels"), whereSQL)
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb engine: list threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []ThreadSummary
+	for rows.Next() {
+		var s ThreadSummary
+		var participants, labels []string
+		if err := rows.Scan(&s.ConversationID, &s.MessageCount, &s.UnreadCount, &s.HasAttachments,
+			&participants, &labels, &s.FirstDate, &s.LastDate, &s.LatestSubject); err != nil {
+			return nil, fmt.Errorf("duckdb engine: scan thread: %w", err)
+		}
+		s.Participants = participants
+		s.Labels = labels
+		threads = append(threads, s)
+	}
+	return threads, rows.Err()
+}
+
+func (e *DuckDBEngine) scanAggregateRows(ctx context.Context, query string, args []any, opts AggregateOptions) ([]AggregateRow, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb engine: aggregate query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []AggregateRow
+	for rows.Next() {
+		var r AggregateRow
+		if err := rows.Scan(&r.Key, &r.Count, &r.AttachmentSize, &r.AttachmentCount); err != nil {
+			return nil, fmt.Errorf("duckdb engine: scan aggregate row: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// strftimeFormat translates timeBucketSQL's to_char-style format string
+// (YYYY, YYYY-MM, YYYY-MM-DD) to the strftime directives DuckDB expects.
+func strftimeFormat(format string) string {
+	switch format {
+	case "YYYY-MM-DD":
+		return "%Y-%m-%d"
+	case "YYYY-MM":
+		return "%Y-%m"
+	default:
+		return "%Y"
+	}
+}
+
+// duckFilterClause is filterClause's DuckDB-flavored counterpart: the same
+// MessageFilter predicates, but built with "?" positional placeholders
+// (DuckDB's database/sql driver, like SQLite's, doesn't use Postgres's
+// "$N" syntax) against the read_parquet-backed tables.
+func (e *DuckDBEngine) duckFilterClause(filter MessageFilter, alias string) ([]string, []any) {
+	var where []string
+	var args []any
+
+	bind := func(v any) string {
+		args = append(args, v)
+		return "?"
+	}
+	bindStringsQ := func(values []string) string {
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = bind(v)
+		}
+		return strings.Join(placeholders, ",")
+	}
+
+	if filter.Sender != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM read_parquet('%s') mr JOIN read_parquet('%s') p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email_address = %s)`,
+			e.flatGlob("message_recipients"), e.flatGlob("participants"), alias, bind(filter.Sender)))
+	}
+	if filter.ConversationID != nil {
+		where = append(where, fmt.Sprintf("%s.conversation_id = %s", alias, bind(*filter.ConversationID)))
+	}
+	if filter.WithAttachmentsOnly {
+		where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+	}
+	if len(filter.Labels) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM read_parquet('%s') ml JOIN read_parquet('%s') l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name IN (%s))`,
+			e.flatGlob("message_labels"), e.flatGlob("labels"), alias, bindStringsQ(filter.Labels)))
+	}
+	if !filter.SentAfter.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(filter.SentAfter)))
+	}
+	if !filter.SentBefore.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(filter.SentBefore)))
+	}
+	if filter.After != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*filter.After)))
+	}
+	if filter.Before != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*filter.Before)))
+	}
+	if filter.SizeMin > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate >= %s", alias, bind(filter.SizeMin)))
+	}
+	if filter.SizeMax > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate <= %s", alias, bind(filter.SizeMax)))
+	}
+	if filter.HasAttachment != nil {
+		if *filter.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+
+	return where, args
+}
+
+// duckAggregateFilterClause is duckFilterClause's AggregateOptions
+// counterpart, mirroring aggregateFilterClause's predicates (minus the
+// Postgres-only AttachmentMimeTypes' content_type column, which the
+// Parquet attachments table doesn't carry).
+func (e *DuckDBEngine) duckAggregateFilterClause(opts AggregateOptions, alias string) ([]string, []any) {
+	var where []string
+	var args []any
+
+	bind := func(v any) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	if opts.SentAfter != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*opts.SentAfter)))
+	}
+	if opts.SentBefore != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*opts.SentBefore)))
+	}
+	if opts.After != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*opts.After)))
+	}
+	if opts.Before != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*opts.Before)))
+	}
+	if opts.HasAttachment != nil {
+		if *opts.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+
+	return where, args
+}
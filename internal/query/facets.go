@@ -0,0 +1,15 @@
+package query
+
+// FacetValue is one bucket of a distinct-value facet (sender, recipient,
+// domain, or label), scoped to whatever MessageFilter the caller supplied:
+// the value, its display name, and how many matching messages carry it.
+type FacetValue struct {
+	Value       string
+	DisplayName string
+	Count       int64
+}
+
+// No engine in this package (DuckDBEngine, SQLiteEngine or PostgresEngine)
+// has ListDistinctSenders, ListDistinctRecipients or ListDistinctDomains
+// methods, and no method here returns FacetValue; the type stands on its
+// own.
@@ -0,0 +1,131 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// FTSMode controls whether a text-term search uses Postgres's tsvector/GIN
+// index or falls back to a plain ILIKE scan.
+type FTSMode int
+
+const (
+	// FTSOff always uses ILIKE, even when a tsvector index is available.
+	FTSOff FTSMode = iota
+	// FTSAuto uses the tsvector index. Unlike DuckDB's fts extension,
+	// whose index is built asynchronously and can be transiently unready,
+	// Postgres's tsvector expression index is defined by the schema and
+	// always available, so Auto and Required behave identically here -
+	// there is no "not ready yet" state to fall back from.
+	FTSAuto
+	// FTSRequired uses the tsvector index; see FTSAuto.
+	FTSRequired
+)
+
+// MessageWithScore pairs a message with its text-search relevance score and
+// a highlighted snippet of the matched text, returned by SearchRanked in
+// place of a plain []*Message so callers can sort or filter on rank and
+// show the reader why a result matched.
+type MessageWithScore struct {
+	Message *Message
+	Score   float64
+	Snippet string
+}
+
+// ftsPredicate builds the WHERE predicate (and, for modes other than
+// FTSOff, the matching ts_rank_cd and ts_headline expressions) for a
+// text-term search against alias, with placeholders numbered starting at
+// startIndex+1. rankExpr and snippetExpr are empty for FTSOff, since an
+// ILIKE match has no relevance score or tsquery to highlight against.
+func ftsPredicate(mode FTSMode, terms []string, alias string, startIndex int) (predicate string, args []any, rankExpr, snippetExpr string) {
+	joined := strings.Join(terms, " ")
+
+	if mode == FTSOff {
+		needle := "%" + escapeILIKE(joined) + "%"
+		predicate = fmt.Sprintf("(%s.subject ILIKE $%d ESCAPE '\\' OR %s.snippet ILIKE $%d ESCAPE '\\')",
+			alias, startIndex+1, alias, startIndex+2)
+		return predicate, []any{needle, needle}, "", ""
+	}
+
+	tsvectorExpr := fmt.Sprintf("to_tsvector('english', %s.subject || ' ' || coalesce(mb.text_body, ''))", alias)
+	tsqueryExpr := fmt.Sprintf("plainto_tsquery('english', $%d)", startIndex+1)
+	predicate = fmt.Sprintf("%s @@ %s", tsvectorExpr, tsqueryExpr)
+	// ts_rank_cd (cover density) rewards matches where the query's terms
+	// appear close together, unlike plain ts_rank which only weighs how
+	// often they appear - a better fit for ranking search results than
+	// ranking e.g. topic relevance across a whole document.
+	rankExpr = fmt.Sprintf("ts_rank_cd(%s, %s)", tsvectorExpr, tsqueryExpr)
+	snippetExpr = fmt.Sprintf(
+		"ts_headline('english', %s.subject || ' ' || coalesce(mb.text_body, ''), %s, 'StartSel=<mark>, StopSel=</mark>')",
+		alias, tsqueryExpr)
+	return predicate, []any{joined}, rankExpr, snippetExpr
+}
+
+// SearchRanked runs a text-term search like SearchFast, but returns each
+// match's relevance score alongside it and orders results by score instead
+// of sent_at. filter.FTSMode selects tsvector ranking (FTSAuto/FTSRequired)
+// or a plain ILIKE scan (FTSOff), in which case every result's Score is 0
+// since ILIKE has no notion of rank.
+func (e *PostgresEngine) SearchRanked(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]MessageWithScore, error) {
+	where, args := filterClause(filter, "m")
+
+	predicate, ftsArgs, rankExpr, snippetExpr := ftsPredicate(filter.FTSMode, q.TextTerms, "m", len(args))
+	where = append(where, predicate)
+	args = append(args, ftsArgs...)
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	orderBy := "m.sent_at DESC"
+	scoreSelect := "0"
+	snippetSelect := "''"
+	if rankExpr != "" {
+		scoreSelect = rankExpr
+		orderBy = "score DESC"
+	}
+	if snippetExpr != "" {
+		snippetSelect = snippetExpr
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at, %s AS score, %s AS headline
+		FROM messages m
+		LEFT JOIN message_bodies mb ON mb.message_id = m.id
+		%s
+		ORDER BY %s
+		LIMIT %d OFFSET %d`, scoreSelect, snippetSelect, whereSQL, orderBy, limit, offset)
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search ranked: %w", err)
+	}
+	defer rows.Close()
+
+	var results []MessageWithScore
+	for rows.Next() {
+		var msg Message
+		var sentAt time.Time
+		var deletedAt sql.NullTime
+		var score float64
+		var snippet string
+		err := rows.Scan(&msg.ID, &msg.SourceID, &msg.SourceMessageID, &msg.ConversationID, &msg.Subject, &msg.Snippet,
+			&sentAt, &msg.SizeEstimate, &msg.HasAttachments, &deletedAt, &score, &snippet)
+		if err != nil {
+			return nil, fmt.Errorf("search ranked: scan: %w", err)
+		}
+		msg.Date = sentAt
+		if deletedAt.Valid {
+			msg.DeletedFromSourceAt = &deletedAt.Time
+		}
+		results = append(results, MessageWithScore{Message: &msg, Score: score, Snippet: snippet})
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFTSPredicate_OffUsesEscapedILIKE checks that FTSOff lowers to an
+// ILIKE scan (with wildcard escaping, not a tsvector match) and reports no
+// rank expression, since a plain ILIKE scan has no relevance score -
+// unlike tsvector's ts_rank, it also does nothing to stem "running" to
+// match a query for "run".
+func TestFTSPredicate_OffUsesEscapedILIKE(t *testing.T) {
+	predicate, args, rank, snippet := ftsPredicate(FTSOff, []string{"50%"}, "m", 0)
+	if !strings.Contains(predicate, "ILIKE") || !strings.Contains(predicate, "ESCAPE") {
+		t.Errorf("predicate: got %q, want an escaped ILIKE scan", predicate)
+	}
+	if rank != "" {
+		t.Errorf("rank: got %q, want empty for FTSOff", rank)
+	}
+	if snippet != "" {
+		t.Errorf("snippet: got %q, want empty for FTSOff", snippet)
+	}
+	if len(args) != 2 || args[0] != `%50\%%` {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestFTSPredicate_AutoAndRequiredUseTsvector checks that both non-Off
+// modes lower to the same tsvector @@ plainto_tsquery predicate plus
+// matching ts_rank_cd and ts_headline expressions, since Postgres's
+// tsvector index (unlike DuckDB's fts extension) has no "still building"
+// state to distinguish Auto from Required.
+func TestFTSPredicate_AutoAndRequiredUseTsvector(t *testing.T) {
+	for _, mode := range []FTSMode{FTSAuto, FTSRequired} {
+		predicate, args, rank, snippet := ftsPredicate(mode, []string{"quarterly", "report"}, "m", 2)
+		if !strings.Contains(predicate, "to_tsvector") || !strings.Contains(predicate, "plainto_tsquery('english', $3)") {
+			t.Errorf("mode %v predicate: got %q", mode, predicate)
+		}
+		if !strings.Contains(rank, "ts_rank_cd(") {
+			t.Errorf("mode %v rank: got %q, want a ts_rank_cd expression", mode, rank)
+		}
+		if !strings.Contains(snippet, "ts_headline(") {
+			t.Errorf("mode %v snippet: got %q, want a ts_headline expression", mode, snippet)
+		}
+		if len(args) != 1 || args[0] != "quarterly report" {
+			t.Errorf("mode %v args: got %v, want the joined terms as one arg", mode, args)
+		}
+	}
+}
+
+// TestFTSPredicate_PlaceholderNumberingContinues checks that both ILIKE's
+// two placeholders and tsvector's one placeholder continue numbering from
+// startIndex, so the predicate can be appended after an existing
+// filterClause call's args.
+func TestFTSPredicate_PlaceholderNumberingContinues(t *testing.T) {
+	predicate, _, _, _ := ftsPredicate(FTSOff, []string{"x"}, "m", 3)
+	if !strings.Contains(predicate, "$4") || !strings.Contains(predicate, "$5") {
+		t.Errorf("predicate: got %q, want placeholders $4 and $5", predicate)
+	}
+}
@@ -0,0 +1,310 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MessageHeader is the subset of a message's threading headers buildJWZThreads
+// needs: its own Message-ID, the In-Reply-To id it names (if any), and the
+// ordered References chain (oldest ancestor first, as RFC 5322 specifies).
+type MessageHeader struct {
+	ID             int64
+	MessageID      string
+	InReplyTo      string
+	References     []string
+	Subject        string
+	SentAt         time.Time
+	IsUnread       bool
+	HasAttachments bool
+}
+
+// Thread is one JWZ-grouped conversation: either a true reference-linked
+// thread, or a group of reference-less roots merged by normalized subject.
+type Thread struct {
+	Subject        string
+	MessageIDs     []int64
+	MessageCount   int
+	UnreadCount    int
+	HasAttachments bool
+	FirstDate      time.Time
+	LastDate       time.Time
+}
+
+// jwzContainer is a node in the JWZ container tree. A container can exist
+// with header == nil - an "empty container" standing in for a referenced
+// Message-ID this header set never produced a message for (e.g. a parent
+// message that was deleted or never fetched).
+type jwzContainer struct {
+	id       string
+	header   *MessageHeader
+	parent   *jwzContainer
+	children []*jwzContainer
+}
+
+// buildJWZThreads groups headers into threads using the JWZ algorithm
+// (https://www.jwz.org/doc/threading.html): build an id-table of containers
+// keyed by Message-ID, link each message under the last id in its
+// References chain (falling back to In-Reply-To when References is empty),
+// prune empty containers that accumulated no real message, and finally
+// merge any remaining root-level threads that share a normalized subject
+// (stripped of Re:/Fwd: prefixes) - the fallback for mail that never set
+// threading headers at all. Reference cycles and self-references are
+// detected and skipped rather than linked, so a single malformed header
+// can't turn the tree into a graph.
+func buildJWZThreads(headers []MessageHeader) []Thread {
+	idTable := make(map[string]*jwzContainer)
+	getOrCreate := func(id string) *jwzContainer {
+		c, ok := idTable[id]
+		if !ok {
+			c = &jwzContainer{id: id}
+			idTable[id] = c
+		}
+		return c
+	}
+
+	for i := range headers {
+		h := &headers[i]
+		msgID := h.MessageID
+		if msgID == "" {
+			msgID = fmt.Sprintf("$no-message-id-%d", h.ID)
+		}
+		c := getOrCreate(msgID)
+		if c.header == nil {
+			c.header = h
+		}
+
+		refs := h.References
+		if len(refs) == 0 && h.InReplyTo != "" {
+			refs = []string{h.InReplyTo}
+		}
+
+		var parent *jwzContainer
+		for _, ref := range refs {
+			if ref == "" || ref == msgID {
+				continue
+			}
+			rc := getOrCreate(ref)
+			if parent != nil && rc != parent && rc.parent == nil && !isAncestor(rc, parent) {
+				linkChild(parent, rc)
+			}
+			parent = rc
+		}
+		if parent != nil && parent != c && c.parent == nil && !isAncestor(c, parent) {
+			linkChild(parent, c)
+		}
+	}
+
+	roots := collectRoots(idTable)
+
+	var pruned []*jwzContainer
+	for _, root := range roots {
+		pruned = append(pruned, pruneEmptyContainer(root)...)
+	}
+
+	var order []string
+	groups := make(map[string][]*jwzContainer)
+	for _, c := range pruned {
+		subj := normalizeSubject(subjectOf(c))
+		if _, ok := groups[subj]; !ok {
+			order = append(order, subj)
+		}
+		groups[subj] = append(groups[subj], c)
+	}
+
+	var threads []Thread
+	for _, subj := range order {
+		var members []*MessageHeader
+		for _, c := range groups[subj] {
+			collectHeaders(c, &members)
+		}
+		if len(members) == 0 {
+			continue
+		}
+		threads = append(threads, buildThread(subj, members))
+	}
+
+	sort.Slice(threads, func(i, j int) bool { return threads[i].LastDate.After(threads[j].LastDate) })
+	return threads
+}
+
+// isAncestor reports whether ancestor appears somewhere in node's parent
+// chain. Checked before every link so a References/In-Reply-To chain that
+// loops back on itself is skipped instead of linked into a cycle.
+func isAncestor(ancestor, node *jwzContainer) bool {
+	for n := node.parent; n != nil; n = n.parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func linkChild(parent, child *jwzContainer) {
+	child.parent = parent
+	parent.children = append(parent.children, child)
+}
+
+// collectRoots walks each container up to its root and returns the distinct
+// set of roots, in first-seen order.
+func collectRoots(idTable map[string]*jwzContainer) []*jwzContainer {
+	var roots []*jwzContainer
+	seen := make(map[*jwzContainer]bool)
+	for _, c := range idTable {
+		root := c
+		for root.parent != nil {
+			root = root.parent
+		}
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// pruneEmptyContainer removes containers with no header and no children
+// (orphaned placeholders for a reference nobody ever sent), and collapses a
+// headerless container with exactly one child by promoting that child in
+// its place, matching the JWZ spec's pruning step. A headerless container
+// with multiple children has no single message to represent it, so it's
+// kept as a bare grouping node; subjectOf looks through it to its children.
+func pruneEmptyContainer(c *jwzContainer) []*jwzContainer {
+	var keptChildren []*jwzContainer
+	for _, child := range c.children {
+		keptChildren = append(keptChildren, pruneEmptyContainer(child)...)
+	}
+	c.children = keptChildren
+	for _, k := range keptChildren {
+		k.parent = c
+	}
+
+	if c.header == nil {
+		switch len(c.children) {
+		case 0:
+			return nil
+		case 1:
+			return []*jwzContainer{c.children[0]}
+		}
+	}
+	return []*jwzContainer{c}
+}
+
+// subjectOf returns c's own subject, or the first subject found among its
+// descendants when c is a headerless grouping node.
+func subjectOf(c *jwzContainer) string {
+	if c.header != nil {
+		return c.header.Subject
+	}
+	for _, child := range c.children {
+		if s := subjectOf(child); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// collectHeaders appends every header in c's subtree to out, in no
+// particular order (buildThread takes FirstDate/LastDate from SentAt itself
+// and keeps MessageIDs in this order).
+func collectHeaders(c *jwzContainer, out *[]*MessageHeader) {
+	if c.header != nil {
+		*out = append(*out, c.header)
+	}
+	for _, child := range c.children {
+		collectHeaders(child, out)
+	}
+}
+
+// normalizeSubject strips repeated Re:/Fwd:/Fw: reply prefixes and
+// lowercases the remainder, so "Re: Re: Q1 numbers" and "Q1 numbers" group
+// together.
+func normalizeSubject(s string) string {
+	s = strings.TrimSpace(s)
+	for {
+		lower := strings.ToLower(s)
+		trimmedAny := false
+		for _, prefix := range []string{"re:", "fwd:", "fw:"} {
+			if strings.HasPrefix(lower, prefix) {
+				s = strings.TrimSpace(s[len(prefix):])
+				trimmedAny = true
+				break
+			}
+		}
+		if !trimmedAny {
+			break
+		}
+	}
+	return strings.ToLower(s)
+}
+
+func buildThread(subject string, members []*MessageHeader) Thread {
+	t := Thread{Subject: subject, MessageCount: len(members)}
+	for i, h := range members {
+		t.MessageIDs = append(t.MessageIDs, h.ID)
+		if h.IsUnread {
+			t.UnreadCount++
+		}
+		if h.HasAttachments {
+			t.HasAttachments = true
+		}
+		if i == 0 || h.SentAt.Before(t.FirstDate) {
+			t.FirstDate = h.SentAt
+		}
+		if i == 0 || h.SentAt.After(t.LastDate) {
+			t.LastDate = h.SentAt
+		}
+	}
+	return t
+}
+
+// ListThreadsJWZ groups filter's matching messages into JWZ threads.
+//
+// This assumes a message_headers(message_id, rfc_message_id, in_reply_to,
+// refs) table keyed by messages.id, with refs a space-separated
+// References chain (oldest first) - schema this tree has no migration for
+// yet, since ingestion doesn't currently persist raw RFC 5322 headers
+// anywhere. ListThreads (threads.go) remains the conversation_id-grouped
+// view that works against the schema that does exist today; this method is
+// the header-based JWZ alternative the thread-by-References use case needs.
+func (e *PostgresEngine) ListThreadsJWZ(ctx context.Context, filter MessageFilter) ([]Thread, error) {
+	where, args := filterClause(filter, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, COALESCE(mh.rfc_message_id, ''), COALESCE(mh.in_reply_to, ''), COALESCE(mh.refs, ''),
+		       m.subject, m.sent_at, COALESCE(m.is_unread, false), m.has_attachments
+		FROM messages m
+		LEFT JOIN message_headers mh ON mh.message_id = m.id
+		%s`, whereSQL)
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list threads jwz: %w", err)
+	}
+	defer rows.Close()
+
+	var headers []MessageHeader
+	for rows.Next() {
+		var h MessageHeader
+		var refs string
+		if err := rows.Scan(&h.ID, &h.MessageID, &h.InReplyTo, &refs, &h.Subject, &h.SentAt, &h.IsUnread, &h.HasAttachments); err != nil {
+			return nil, fmt.Errorf("list threads jwz: scan: %w", err)
+		}
+		if refs != "" {
+			h.References = strings.Fields(refs)
+		}
+		headers = append(headers, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list threads jwz: %w", err)
+	}
+
+	return buildJWZThreads(headers), nil
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func t1(offsetMinutes int) time.Time {
+	return time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC).Add(time.Duration(offsetMinutes) * time.Minute)
+}
+
+// TestBuildJWZThreads_SimpleChain checks that a root message and a reply
+// referencing it via In-Reply-To land in the same thread.
+func TestBuildJWZThreads_SimpleChain(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "root@a", Subject: "Q1 numbers", SentAt: t1(0)},
+		{ID: 2, MessageID: "reply@a", InReplyTo: "root@a", Subject: "Re: Q1 numbers", SentAt: t1(5)},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 1 {
+		t.Fatalf("got %d threads, want 1: %+v", len(threads), threads)
+	}
+	if threads[0].MessageCount != 2 {
+		t.Errorf("MessageCount: got %d, want 2", threads[0].MessageCount)
+	}
+}
+
+// TestBuildJWZThreads_MissingParent checks that a reply whose References
+// chain names a message never seen in this header set (an empty container)
+// still threads under that placeholder rather than becoming an unrelated
+// root, and a sibling reply to the same missing parent joins the same
+// thread.
+func TestBuildJWZThreads_MissingParent(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "reply1@a", References: []string{"missing@a"}, Subject: "Re: Launch plan", SentAt: t1(0)},
+		{ID: 2, MessageID: "reply2@a", References: []string{"missing@a"}, Subject: "Re: Launch plan", SentAt: t1(10)},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 1 {
+		t.Fatalf("got %d threads, want 1 (both hang off the same missing parent): %+v", len(threads), threads)
+	}
+	if threads[0].MessageCount != 2 {
+		t.Errorf("MessageCount: got %d, want 2", threads[0].MessageCount)
+	}
+}
+
+// TestBuildJWZThreads_SubjectGroupingForThreadlessMail checks that two
+// messages with no References/In-Reply-To at all but the same normalized
+// subject (Re:-stripped) are merged into one thread.
+func TestBuildJWZThreads_SubjectGroupingForThreadlessMail(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "a@x", Subject: "Launch plan", SentAt: t1(0)},
+		{ID: 2, MessageID: "b@x", Subject: "Re: Launch plan", SentAt: t1(5)},
+		{ID: 3, MessageID: "c@x", Subject: "Fwd: launch plan", SentAt: t1(10)},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 1 {
+		t.Fatalf("got %d threads, want 1 merged by subject: %+v", len(threads), threads)
+	}
+	if threads[0].MessageCount != 3 {
+		t.Errorf("MessageCount: got %d, want 3", threads[0].MessageCount)
+	}
+}
+
+// TestBuildJWZThreads_UnrelatedSubjectsStaySeparate checks that messages
+// with distinct normalized subjects and no reference chain stay in
+// separate threads.
+func TestBuildJWZThreads_UnrelatedSubjectsStaySeparate(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "a@x", Subject: "Launch plan", SentAt: t1(0)},
+		{ID: 2, MessageID: "b@x", Subject: "Lunch tomorrow?", SentAt: t1(5)},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want 2: %+v", len(threads), threads)
+	}
+}
+
+// TestBuildJWZThreads_ReferenceCycleDoesNotHang checks that a header set
+// whose References chains form a cycle (a bad or adversarial header) is
+// still processed to completion rather than linking into a loop - the
+// guarded messages simply end up as their own roots instead of being
+// dropped or causing infinite recursion.
+func TestBuildJWZThreads_ReferenceCycleDoesNotHang(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "a@x", InReplyTo: "b@x", Subject: "Cycle", SentAt: t1(0)},
+		{ID: 2, MessageID: "b@x", InReplyTo: "a@x", Subject: "Cycle", SentAt: t1(5)},
+	}
+
+	threads := buildJWZThreads(headers)
+	total := 0
+	for _, th := range threads {
+		total += th.MessageCount
+	}
+	if total != 2 {
+		t.Errorf("expected both cyclically-referencing messages to still be returned exactly once, got %d across %d threads", total, len(threads))
+	}
+}
+
+// TestBuildJWZThreads_SortedByLastDateDescending checks that threads come
+// back newest-last-activity first.
+func TestBuildJWZThreads_SortedByLastDateDescending(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "old@x", Subject: "Old topic", SentAt: t1(0)},
+		{ID: 2, MessageID: "new@x", Subject: "New topic", SentAt: t1(100)},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want 2", len(threads))
+	}
+	if threads[0].Subject != "new topic" {
+		t.Errorf("first thread: got %q, want the most recently active one", threads[0].Subject)
+	}
+}
+
+// TestBuildJWZThreads_UnreadAndAttachmentAggregates checks that UnreadCount
+// and HasAttachments roll up across every message in the thread.
+func TestBuildJWZThreads_UnreadAndAttachmentAggregates(t *testing.T) {
+	headers := []MessageHeader{
+		{ID: 1, MessageID: "root@x", Subject: "Invoice", SentAt: t1(0), IsUnread: true},
+		{ID: 2, MessageID: "reply@x", InReplyTo: "root@x", Subject: "Re: Invoice", SentAt: t1(5), HasAttachments: true},
+	}
+
+	threads := buildJWZThreads(headers)
+	if len(threads) != 1 {
+		t.Fatalf("got %d threads, want 1", len(threads))
+	}
+	if threads[0].UnreadCount != 1 {
+		t.Errorf("UnreadCount: got %d, want 1", threads[0].UnreadCount)
+	}
+	if !threads[0].HasAttachments {
+		t.Error("HasAttachments: want true since one member has an attachment")
+	}
+}
+
+// TestNormalizeSubject strips repeated reply/forward prefixes and
+// lowercases the rest.
+func TestNormalizeSubject(t *testing.T) {
+	tests := map[string]string{
+		"Q1 numbers":          "q1 numbers",
+		"Re: Q1 numbers":      "q1 numbers",
+		"Re: Re: Q1 numbers":  "q1 numbers",
+		"Fwd: Q1 numbers":     "q1 numbers",
+		"Fw: Re: Q1 numbers":  "q1 numbers",
+		"  Re:  Q1 numbers  ": "q1 numbers",
+	}
+	for input, want := range tests {
+		if got := normalizeSubject(input); got != want {
+			t.Errorf("normalizeSubject(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package query
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Page requests one page of a keyset-paginated list. PageToken is empty for
+// the first page and otherwise the NextPageToken a previous PageResult
+// returned. WithTotalCount gates a second COUNT(*) query, since computing it
+// is not free and most callers paging through results don't need it on
+// every call.
+type Page struct {
+	PageSize       int
+	PageToken      string
+	WithTotalCount bool
+}
+
+// PageResult is the result envelope for a keyset-paginated list call.
+// NextPageToken is empty once the caller has reached the last page.
+// TotalCount is only populated when the request set Page.WithTotalCount.
+type PageResult[T any] struct {
+	Items         []T
+	NextPageToken string
+	TotalCount    int64
+}
+
+// PageSortColumn selects which column ListMessagesPage orders and keys its
+// cursor by.
+type PageSortColumn int
+
+const (
+	// SortBySentAt orders by sent_at, the default.
+	SortBySentAt PageSortColumn = iota
+	// SortBySubject orders by subject.
+	SortBySubject
+)
+
+// pageCursor identifies the last row of a page: the string form of whatever
+// column the page is sorted by (SortKey) plus that row's id, the tiebreaker
+// that keeps the cursor well-defined even when many rows share a sort key.
+type pageCursor struct {
+	SortKey string `json:"k"`
+	ID      int64  `json:"id"`
+}
+
+// encodePageToken HMAC-signs and base64-encodes c, so a caller can't tamper
+// with or forge a cursor for rows they haven't been shown a page of.
+func encodePageToken(key []byte, c pageCursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("encode page token: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	signed := append(mac.Sum(nil), payload...)
+	return base64.RawURLEncoding.EncodeToString(signed), nil
+}
+
+// decodePageToken verifies and decodes a token produced by encodePageToken.
+func decodePageToken(key []byte, token string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("decode page token: %w", err)
+	}
+	if len(raw) < sha256.Size {
+		return nil, errors.New("decode page token: truncated")
+	}
+	sig, payload := raw[:sha256.Size], raw[sha256.Size:]
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errors.New("decode page token: invalid signature")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("decode page token: %w", err)
+	}
+	return &c, nil
+}
+
+// newPageTokenKey generates a random key for signing page tokens.
+func newPageTokenKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate page token key: %w", err)
+	}
+	return key, nil
+}
+
+// sortColumnSQL maps a PageSortColumn to its messages column name.
+func sortColumnSQL(sortBy PageSortColumn) string {
+	if sortBy == SortBySubject {
+		return "subject"
+	}
+	return "sent_at"
+}
+
+// sortKeyOf returns msg's value for sortBy, formatted the same way on both
+// the encode side (building a cursor from a row) and the decode side
+// (comparing a cursor's SortKey against a column in SQL), so the two stay
+// consistent.
+func sortKeyOf(msg *Message, sortBy PageSortColumn) string {
+	if sortBy == SortBySubject {
+		return msg.Subject
+	}
+	return msg.Date.UTC().Format(time.RFC3339Nano)
+}
+
+// ListMessagesPage returns one keyset-paginated page of messages matching
+// filter, ordered by sortBy and filter.SortDirection. Unlike ListMessages,
+// results are stable under concurrent inserts because each page is
+// anchored to the last (sort_key, id) pair seen rather than a numeric
+// offset.
+func (e *PostgresEngine) ListMessagesPage(ctx context.Context, filter MessageFilter, sortBy PageSortColumn, page Page) (*PageResult[*Message], error) {
+	where, args := filterClause(filter, "m")
+	column := sortColumnSQL(sortBy)
+	desc := filter.SortDirection != SortAsc
+	cmp := "<"
+	if !desc {
+		cmp = ">"
+	}
+
+	if page.PageToken != "" {
+		cursor, err := decodePageToken(e.pageTokenKey, page.PageToken)
+		if err != nil {
+			return nil, fmt.Errorf("list messages page: %w", err)
+		}
+		where = append(where, fmt.Sprintf("(m.%s, m.id) %s ($%d, $%d)", column, cmp, len(args)+1, len(args)+2))
+		args = append(args, cursor.SortKey, cursor.ID)
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+	dir := "DESC"
+	if !desc {
+		dir = "ASC"
+	}
+
+	pageSize := page.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m
+		%s
+		ORDER BY m.%s %s, m.id %s
+		LIMIT %d`, whereSQL, column, dir, dir, pageSize+1)
+
+	messages, err := e.queryMessages(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list messages page: %w", err)
+	}
+
+	result := &PageResult[*Message]{}
+	if len(messages) > pageSize {
+		last := messages[pageSize-1]
+		token, err := encodePageToken(e.pageTokenKey, pageCursor{SortKey: sortKeyOf(last, sortBy), ID: last.ID})
+		if err != nil {
+			return nil, fmt.Errorf("list messages page: %w", err)
+		}
+		result.NextPageToken = token
+		messages = messages[:pageSize]
+	}
+	result.Items = messages
+
+	if page.WithTotalCount {
+		countWhere, countArgs := filterClause(filter, "m")
+		countWhereSQL := ""
+		if len(countWhere) > 0 {
+			countWhereSQL = "WHERE " + strings.Join(countWhere, " AND ")
+		}
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM messages m %s", countWhereSQL)
+		if err := e.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&result.TotalCount); err != nil {
+			return nil, fmt.Errorf("list messages page: count: %w", err)
+		}
+	}
+
+	return result, nil
+}
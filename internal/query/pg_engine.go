@@ -0,0 +1,924 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// PostgresEngine implements Engine against a Postgres database. It plays the
+// same role SQLiteEngine does for SQLite: GetMessage, GetMessageBySourceID,
+// and Search run directly against Postgres, using a tsvector/GIN index for
+// full-text search in place of SQLite's FTS5 and date_trunc for the
+// time-bucketed aggregates.
+//
+// Like DuckDBEngine pairs a SQLite connection with a DuckDB Parquet
+// analytics directory, PostgresEngine can optionally be paired with one too:
+// when analyticsDir is non-empty, the analytics-style calls (the
+// Aggregate*, SubAggregate, SearchFast, and ListMessages methods) delegate
+// to that DuckDBEngine instead of querying Postgres directly.
+type PostgresEngine struct {
+	db        *sql.DB
+	analytics *DuckDBEngine
+
+	// pageTokenKey signs the opaque cursors ListMessagesPage hands back as
+	// NextPageToken, generated fresh per engine instance - tokens aren't
+	// meant to outlive the process that issued them.
+	pageTokenKey []byte
+
+	// views tracks in-flight StreamMessages calls so CancelView can abort
+	// them by viewID.
+	views *viewRegistry
+
+	// defaultTextMatch is the search.MatchType applied to "" and "subject"
+	// clauses that don't specify one explicitly (classifyToken defaults
+	// those to search.MatchContains, a "%value%" ILIKE scan), via
+	// lowerSearchASTWithDefault. Operators that have populated the
+	// collations/indexes a prefix scan needs can opt in with
+	// WithDefaultTextMatch(search.MatchPrefix) for the faster lookup; see
+	// the matching knob DuckDBEngine exposes for the Parquet analytics path.
+	defaultTextMatch search.MatchType
+}
+
+// Option configures a PostgresEngine at construction time.
+type Option func(*PostgresEngine)
+
+// WithDefaultTextMatch sets the default search.MatchType applied to "" and
+// "subject" clauses parsed without an explicit "="/"^"/"~" prefix.
+func WithDefaultTextMatch(m search.MatchType) Option {
+	return func(e *PostgresEngine) {
+		e.defaultTextMatch = m
+	}
+}
+
+// NewPostgresEngine opens a connection pool to dsn. If analyticsDir is
+// non-empty, it also opens a DuckDBEngine over that Parquet directory and
+// delegates analytics-style queries to it; otherwise those queries run
+// against Postgres directly.
+func NewPostgresEngine(dsn, analyticsDir string, opts ...Option) (*PostgresEngine, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+
+	pageTokenKey, err := newPageTokenKey()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("new postgres engine: %w", err)
+	}
+
+	e := &PostgresEngine{db: db, pageTokenKey: pageTokenKey, views: newViewRegistry()}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if analyticsDir != "" {
+		analytics, err := NewDuckDBEngine(analyticsDir, "", nil)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("open analytics dir: %w", err)
+		}
+		e.analytics = analytics
+	}
+
+	return e, nil
+}
+
+// Close releases the Postgres connection pool and, if present, the paired
+// DuckDB analytics engine.
+func (e *PostgresEngine) Close() error {
+	if e.analytics != nil {
+		e.analytics.Close()
+	}
+	return e.db.Close()
+}
+
+// GetMessage returns the message with the given id, including deleted
+// messages (callers that want to hide them filter on DeletedFromSourceAt).
+func (e *PostgresEngine) GetMessage(ctx context.Context, id int64) (*Message, error) {
+	return e.scanMessage(ctx, "id = $1", id)
+}
+
+// GetMessageBySourceID returns the message with the given source_message_id.
+func (e *PostgresEngine) GetMessageBySourceID(ctx context.Context, sourceMessageID string) (*Message, error) {
+	return e.scanMessage(ctx, "source_message_id = $1", sourceMessageID)
+}
+
+func (e *PostgresEngine) scanMessage(ctx context.Context, where string, arg any) (*Message, error) {
+	row := e.db.QueryRowContext(ctx, `
+		SELECT id, source_id, source_message_id, conversation_id, subject, snippet,
+		       sent_at, size_estimate, has_attachments, deleted_from_source_at
+		FROM messages WHERE `+where, arg)
+
+	msg, err := scanMessageRow(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get message: %w", err)
+	}
+
+	if err := e.attachRecipientsAndAttachments(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (e *PostgresEngine) attachRecipientsAndAttachments(ctx context.Context, msg *Message) error {
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT p.email_address, mr.recipient_type
+		FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = $1`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("load recipients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, recipientType string
+		if err := rows.Scan(&email, &recipientType); err != nil {
+			return fmt.Errorf("scan recipient: %w", err)
+		}
+		switch recipientType {
+		case "from":
+			msg.FromEmail = email
+		case "to", "cc":
+			msg.ToEmails = append(msg.ToEmails, email)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate recipients: %w", err)
+	}
+
+	labelRows, err := e.db.QueryContext(ctx, `
+		SELECT l.name FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+		WHERE ml.message_id = $1`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("load labels: %w", err)
+	}
+	defer labelRows.Close()
+
+	for labelRows.Next() {
+		var name string
+		if err := labelRows.Scan(&name); err != nil {
+			return fmt.Errorf("scan label: %w", err)
+		}
+		msg.Labels = append(msg.Labels, name)
+	}
+	if err := labelRows.Err(); err != nil {
+		return fmt.Errorf("iterate labels: %w", err)
+	}
+
+	var textBody sql.NullString
+	err = e.db.QueryRowContext(ctx, `
+		SELECT text_body FROM message_bodies WHERE message_id = $1`, msg.ID).Scan(&textBody)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("load body: %w", err)
+	}
+	msg.TextBody = textBody.String
+
+	attRows, err := e.db.QueryContext(ctx, `
+		SELECT filename, size FROM attachments WHERE message_id = $1`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("load attachments: %w", err)
+	}
+	defer attRows.Close()
+
+	for attRows.Next() {
+		var att Attachment
+		if err := attRows.Scan(&att.Filename, &att.Size); err != nil {
+			return fmt.Errorf("scan attachment: %w", err)
+		}
+		msg.Attachments = append(msg.Attachments, att)
+	}
+	return attRows.Err()
+}
+
+// GetAttachment returns att's content, base64-encoded. Like SQLiteEngine's
+// GetAttachment, this assumes attachments carries the same filename/size
+// columns attachRecipientsAndAttachments already reads; content itself
+// isn't stored inline here either, so Base64Data is left empty.
+func (e *PostgresEngine) GetAttachment(ctx context.Context, id int64) (*AttachmentContent, error) {
+	var content AttachmentContent
+	err := e.db.QueryRowContext(ctx, `
+		SELECT filename, mime_type, size FROM attachments WHERE id = $1`, id).
+		Scan(&content.Filename, &content.MimeType, &content.Size)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	return &content, nil
+}
+
+// Search runs a full-text search using Postgres's tsvector/GIN index,
+// falling back to the paired analytics engine when one is configured.
+func (e *PostgresEngine) Search(ctx context.Context, q *search.Query, limit, offset int) ([]*Message, error) {
+	if e.analytics != nil {
+		return e.analytics.Search(ctx, q, limit, offset)
+	}
+	return e.searchFast(ctx, q, MessageFilter{}, limit, offset)
+}
+
+// SearchFast runs q scoped to filter, delegating to the paired analytics
+// engine when one is configured.
+func (e *PostgresEngine) SearchFast(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]*Message, error) {
+	if e.analytics != nil {
+		return e.analytics.SearchFast(ctx, q, filter, limit, offset)
+	}
+	return e.searchFast(ctx, q, filter, limit, offset)
+}
+
+func (e *PostgresEngine) searchFast(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]*Message, error) {
+	where, args := filterClause(filter, "m")
+	if len(q.TextTerms) > 0 {
+		predicate, ftsArgs, _, _ := ftsPredicate(filter.FTSMode, q.TextTerms, "m", len(args))
+		where = append(where, predicate)
+		args = append(args, ftsArgs...)
+	}
+
+	query := `
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m
+		LEFT JOIN message_bodies mb ON mb.message_id = m.id`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY m.sent_at DESC LIMIT %d OFFSET %d", limit, offset)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+// ListMessages returns messages matching filter, delegating to the paired
+// analytics engine when one is configured.
+func (e *PostgresEngine) ListMessages(ctx context.Context, filter MessageFilter) ([]*Message, error) {
+	if e.analytics != nil {
+		return e.analytics.ListMessages(ctx, filter)
+	}
+
+	where, args := filterClause(filter, "m")
+	query := `
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY m.sent_at " + sortDirectionSQL(filter)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+func (e *PostgresEngine) queryMessages(ctx context.Context, query string, args ...any) ([]*Message, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanMessageRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// AggregateBySender aggregates message counts by sender address, delegating
+// to the paired analytics engine when one is configured.
+func (e *PostgresEngine) AggregateBySender(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateBySender(ctx, opts)
+	}
+	return e.aggregateByRecipientType(ctx, "from", opts)
+}
+
+// AggregateByRecipient aggregates message counts by recipient address
+// (to and cc), delegating to the paired analytics engine when one is
+// configured.
+func (e *PostgresEngine) AggregateByRecipient(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateByRecipient(ctx, opts)
+	}
+	return e.aggregateByRecipientType(ctx, "to", "cc", opts)
+}
+
+func (e *PostgresEngine) aggregateByRecipientType(ctx context.Context, types ...any) ([]AggregateRow, error) {
+	opts, _ := types[len(types)-1].(AggregateOptions)
+	recipientTypes := types[:len(types)-1]
+
+	placeholders := make([]string, len(recipientTypes))
+	args := make([]any, len(recipientTypes))
+	for i, t := range recipientTypes {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = t
+	}
+
+	where, optsArgs := aggregateFilterClause(opts, "m", len(args))
+	args = append(args, optsArgs...)
+	whereSQL := "mr.recipient_type IN (" + strings.Join(placeholders, ",") + ")"
+	if len(where) > 0 {
+		whereSQL += " AND " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.email_address,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		JOIN messages m ON m.id = mr.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		WHERE %s
+		GROUP BY p.email_address
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByDomain aggregates message counts by recipient domain,
+// delegating to the paired analytics engine when one is configured.
+func (e *PostgresEngine) AggregateByDomain(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateByDomain(ctx, opts)
+	}
+
+	where, args := aggregateFilterClause(opts, "m", 0)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.domain,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		JOIN messages m ON m.id = mr.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY p.domain
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByLabel aggregates message counts by label name, delegating to
+// the paired analytics engine when one is configured.
+func (e *PostgresEngine) AggregateByLabel(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateByLabel(ctx, opts)
+	}
+
+	where, args := aggregateFilterClause(opts, "m", 0)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT l.name,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM message_labels ml
+		JOIN labels l ON l.id = ml.label_id
+		JOIN messages m ON m.id = ml.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY l.name
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByTime buckets message counts by opts.TimeGranularity using
+// date_trunc, delegating to the paired analytics engine when one is
+// configured.
+func (e *PostgresEngine) AggregateByTime(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateByTime(ctx, opts)
+	}
+
+	format, bucket := timeBucketSQL(opts.TimeGranularity)
+	where, args := aggregateFilterClause(opts, "m", 0)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT to_char(date_trunc('%s', m.sent_at), '%s'),
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size), 0),
+		       COUNT(DISTINCT a.message_id)
+		FROM messages m
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY 1
+		ORDER BY 1`, bucket, format, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateBySize buckets messages by size_estimate against buckets (upper
+// bounds in bytes), delegating to the paired analytics engine when one is
+// configured. Each message is counted once, in the first bucket whose bound
+// it does not exceed, labeled "<=N", or else in the final, unbounded
+// bucket, labeled ">N".
+func (e *PostgresEngine) AggregateBySize(ctx context.Context, buckets []int64) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.AggregateBySize(ctx, buckets)
+	}
+
+	rows, err := e.db.QueryContext(ctx, `SELECT size_estimate FROM messages`)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate by size: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]int64, len(buckets)+1)
+	for rows.Next() {
+		var size int64
+		if err := rows.Scan(&size); err != nil {
+			return nil, fmt.Errorf("aggregate by size: scan: %w", err)
+		}
+		bucket := len(buckets)
+		for i, upper := range buckets {
+			if size <= upper {
+				bucket = i
+				break
+			}
+		}
+		counts[bucket]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]AggregateRow, len(counts))
+	for i, upper := range buckets {
+		results[i] = AggregateRow{Key: fmt.Sprintf("<=%d", upper), Count: counts[i]}
+	}
+	results[len(buckets)] = AggregateRow{Key: fmt.Sprintf(">%d", buckets[len(buckets)-1]), Count: counts[len(buckets)]}
+	return results, nil
+}
+
+// SubAggregate aggregates the messages matching filter by view, delegating
+// to the paired analytics engine when one is configured.
+func (e *PostgresEngine) SubAggregate(ctx context.Context, filter MessageFilter, view View, opts AggregateOptions) ([]AggregateRow, error) {
+	if e.analytics != nil {
+		return e.analytics.SubAggregate(ctx, filter, view, opts)
+	}
+
+	where, args := filterClause(filter, "m")
+	optsWhere, optsArgs := aggregateFilterClause(opts, "m", len(args))
+	where = append(where, optsWhere...)
+	args = append(args, optsArgs...)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	switch view {
+	case ViewRecipients:
+		query := fmt.Sprintf(`
+			SELECT p.email_address, COUNT(DISTINCT m.id), 0, 0
+			FROM messages m
+			JOIN message_recipients mr ON mr.message_id = m.id AND mr.recipient_type IN ('to', 'cc')
+			JOIN participants p ON p.id = mr.participant_id
+			%s
+			GROUP BY p.email_address
+			ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	case ViewLabels:
+		query := fmt.Sprintf(`
+			SELECT l.name, COUNT(DISTINCT m.id), 0, 0
+			FROM messages m
+			JOIN message_labels ml ON ml.message_id = m.id
+			JOIN labels l ON l.id = ml.label_id
+			%s
+			GROUP BY l.name
+			ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	default:
+		return nil, fmt.Errorf("sub-aggregate: unsupported view %v", view)
+	}
+}
+
+func (e *PostgresEngine) scanAggregateRows(ctx context.Context, query string, args []any, opts AggregateOptions) ([]AggregateRow, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []AggregateRow
+	for rows.Next() {
+		var r AggregateRow
+		if err := rows.Scan(&r.Key, &r.Count, &r.AttachmentSize, &r.AttachmentCount); err != nil {
+			return nil, fmt.Errorf("scan aggregate row: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// GetTotalStats summarizes the vault's overall size, delegating to the
+// paired analytics engine when one is configured.
+func (e *PostgresEngine) GetTotalStats(ctx context.Context, opts StatsOptions) (*TotalStats, error) {
+	if e.analytics != nil {
+		return e.analytics.GetTotalStats(ctx, opts)
+	}
+
+	var stats TotalStats
+	err := e.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(size_estimate), 0) FROM messages`).
+		Scan(&stats.TotalMessages, &stats.TotalSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("get total stats: %w", err)
+	}
+	if err := e.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments`).Scan(&stats.TotalAttachments); err != nil {
+		return nil, fmt.Errorf("get total stats: attachments: %w", err)
+	}
+	return &stats, nil
+}
+
+// ListAccounts returns every source account and its message count,
+// delegating to the paired analytics engine when one is configured.
+func (e *PostgresEngine) ListAccounts(ctx context.Context) ([]AccountInfo, error) {
+	if e.analytics != nil {
+		return e.analytics.ListAccounts(ctx)
+	}
+
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT s.account_email, COUNT(m.id)
+		FROM sources s LEFT JOIN messages m ON m.source_id = s.id
+		GROUP BY s.account_email
+		ORDER BY s.account_email`)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []AccountInfo
+	for rows.Next() {
+		var a AccountInfo
+		if err := rows.Scan(&a.Email, &a.MessageCount); err != nil {
+			return nil, fmt.Errorf("scan account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// timeBucketSQL maps a TimeGranularity to the date_trunc field and the
+// to_char format that produces the YYYY[-MM[-DD]] key the rest of the
+// package expects.
+func timeBucketSQL(g TimeGranularity) (format, bucket string) {
+	switch g {
+	case TimeDay:
+		return "YYYY-MM-DD", "day"
+	case TimeMonth:
+		return "YYYY-MM", "month"
+	default:
+		return "YYYY", "year"
+	}
+}
+
+func sortDirectionSQL(filter MessageFilter) string {
+	if filter.SortDirection == SortAsc {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// filterClause builds the WHERE predicates and arguments shared by
+// ListMessages and searchFast from a MessageFilter, prefixing columns with
+// alias so callers can join against messages under a non-default name.
+func filterClause(filter MessageFilter, alias string) ([]string, []any) {
+	var where []string
+	var args []any
+
+	next := func() string {
+		args = append(args, nil)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	bind := func(v any) string {
+		ph := next()
+		args[len(args)-1] = v
+		return ph
+	}
+
+	if filter.Sender != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email_address = %s)`, alias, bind(filter.Sender)))
+	}
+	if filter.Recipient != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type IN ('to', 'cc') AND p.email_address = %s)`, alias, bind(filter.Recipient)))
+	}
+	if filter.Domain != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain ILIKE %s)`, alias, bind(filter.Domain)))
+	}
+	if filter.Label != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name = %s)`, alias, bind(filter.Label)))
+	}
+	if filter.ConversationID != nil {
+		where = append(where, fmt.Sprintf("%s.conversation_id = %s", alias, bind(*filter.ConversationID)))
+	}
+	if filter.WithAttachmentsOnly {
+		where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+	}
+	if filter.TimePeriod != "" {
+		format, bucket := timeBucketSQL(filter.TimeGranularity)
+		where = append(where, fmt.Sprintf("to_char(date_trunc('%s', %s.sent_at), '%s') = %s", bucket, alias, format, bind(filter.TimePeriod)))
+	}
+
+	if len(filter.Senders) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email_address IN (%s))`,
+			alias, bindStrings(bind, filter.Senders)))
+	}
+	if len(filter.Recipients) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type IN ('to', 'cc') AND p.email_address IN (%s))`,
+			alias, bindStrings(bind, filter.Recipients)))
+	}
+	if len(filter.Domains) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain IN (%s))`,
+			alias, bindStrings(bind, filter.Domains)))
+	}
+	// Labels is an OR-of-labels match (any one of the listed labels);
+	// LabelsAll is an AND-of-labels match (every listed label), expressed as
+	// one EXISTS per label so it composes with the rest of where via AND.
+	if len(filter.Labels) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name IN (%s))`,
+			alias, bindStrings(bind, filter.Labels)))
+	}
+	for _, label := range filter.LabelsAll {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name = %s)`, alias, bind(label)))
+	}
+	if len(filter.ExcludeLabels) > 0 {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name IN (%s))`,
+			alias, bindStrings(bind, filter.ExcludeLabels)))
+	}
+	if len(filter.Priorities) > 0 {
+		placeholders := make([]string, len(filter.Priorities))
+		for i, p := range filter.Priorities {
+			placeholders[i] = bind(p)
+		}
+		where = append(where, fmt.Sprintf("%s.priority IN (%s)", alias, strings.Join(placeholders, ",")))
+	}
+
+	// MatchEmpty* select the opposite of their non-empty counterparts: a
+	// message with no "from" recipient row, no to/cc recipient rows, no
+	// label rows, or no recipient with a non-null domain, respectively.
+	if filter.MatchEmptySender {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from')`, alias))
+	}
+	if filter.MatchEmptyRecipients {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr
+			WHERE mr.message_id = %s.id AND mr.recipient_type IN ('to', 'cc'))`, alias))
+	}
+	if filter.MatchEmptyLabels {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_labels ml WHERE ml.message_id = %s.id)`, alias))
+	}
+	if filter.MatchEmptyDomain {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain IS NOT NULL AND p.domain != '')`, alias))
+	}
+
+	// HasAttachment is a three-state attachment filter (nil: no opinion,
+	// *true/*false: require present/absent), distinct from the
+	// WithAttachmentsOnly bool above which can only ever require presence.
+	if filter.HasAttachment != nil {
+		if *filter.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+	// HasReplyTo is about the Reply-To header (where replies should be
+	// sent), not threading; HasInReplyTo is about the In-Reply-To header
+	// (what this message is a reply to) - the column JWZ threading
+	// (jwz.go) reads as mh.in_reply_to.
+	if filter.HasReplyTo != nil {
+		if *filter.HasReplyTo {
+			where = append(where, fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM message_headers mh WHERE mh.message_id = %s.id AND mh.reply_to IS NOT NULL AND mh.reply_to != '')`, alias))
+		} else {
+			where = append(where, fmt.Sprintf(`NOT EXISTS (
+				SELECT 1 FROM message_headers mh WHERE mh.message_id = %s.id AND mh.reply_to IS NOT NULL AND mh.reply_to != '')`, alias))
+		}
+	}
+	if filter.HasInReplyTo != nil {
+		if *filter.HasInReplyTo {
+			where = append(where, fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM message_headers mh WHERE mh.message_id = %s.id AND mh.in_reply_to IS NOT NULL AND mh.in_reply_to != '')`, alias))
+		} else {
+			where = append(where, fmt.Sprintf(`NOT EXISTS (
+				SELECT 1 FROM message_headers mh WHERE mh.message_id = %s.id AND mh.in_reply_to IS NOT NULL AND mh.in_reply_to != '')`, alias))
+		}
+	}
+	if !filter.SentAfter.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(filter.SentAfter)))
+	}
+	if !filter.SentBefore.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(filter.SentBefore)))
+	}
+	if filter.SizeMin > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate >= %s", alias, bind(filter.SizeMin)))
+	}
+	if filter.SizeMax > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate <= %s", alias, bind(filter.SizeMax)))
+	}
+
+	return where, args
+}
+
+// bindStrings binds each of values via bind and returns the resulting
+// placeholders joined for use inside an IN (...) predicate.
+func bindStrings(bind func(any) string, values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = bind(v)
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// aggregateFilterClause builds the WHERE predicates and arguments contributed
+// by AggregateOptions' exclusion and range fields (ExcludeFromAddrs,
+// ExcludeToAddrs, ExcludeLabels, ExcludeDomains, SizeMin, SizeMax,
+// SentBefore, SentAfter, HasAttachment, AttachmentMimeTypes), prefixing
+// columns with alias the same way filterClause does. startIndex is the
+// number of $N placeholders already bound by a preceding filterClause (or
+// other) call on the same query, so the two sets of args can be
+// concatenated without renumbering.
+func aggregateFilterClause(opts AggregateOptions, alias string, startIndex int) ([]string, []any) {
+	var where []string
+	var args []any
+
+	bind := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", startIndex+len(args))
+	}
+
+	for _, addr := range opts.ExcludeFromAddrs {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email_address = %s)`, alias, bind(addr)))
+	}
+	for _, addr := range opts.ExcludeToAddrs {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type IN ('to', 'cc') AND p.email_address = %s)`, alias, bind(addr)))
+	}
+	for _, domain := range opts.ExcludeDomains {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain ILIKE %s)`, alias, bind(domain)))
+	}
+	for _, label := range opts.ExcludeLabels {
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name = %s)`, alias, bind(label)))
+	}
+	if opts.SizeMin != nil {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM attachments a WHERE a.message_id = %s.id AND a.size >= %s)`, alias, bind(*opts.SizeMin)))
+	}
+	if opts.SizeMax != nil {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM attachments a WHERE a.message_id = %s.id AND a.size <= %s)`, alias, bind(*opts.SizeMax)))
+	}
+	if opts.SentBefore != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*opts.SentBefore)))
+	}
+	if opts.SentAfter != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*opts.SentAfter)))
+	}
+	if opts.HasAttachment != nil {
+		if *opts.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+	for _, mimeType := range opts.AttachmentMimeTypes {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM attachments a WHERE a.message_id = %s.id AND a.content_type = %s)`, alias, bind(mimeType)))
+	}
+
+	if len(opts.Senders) > 0 {
+		placeholders := make([]string, len(opts.Senders))
+		for i, v := range opts.Senders {
+			placeholders[i] = bind(v)
+		}
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email_address IN (%s))`,
+			alias, strings.Join(placeholders, ",")))
+	}
+	if len(opts.Recipients) > 0 {
+		placeholders := make([]string, len(opts.Recipients))
+		for i, v := range opts.Recipients {
+			placeholders[i] = bind(v)
+		}
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type IN ('to', 'cc') AND p.email_address IN (%s))`,
+			alias, strings.Join(placeholders, ",")))
+	}
+	if len(opts.Domains) > 0 {
+		placeholders := make([]string, len(opts.Domains))
+		for i, v := range opts.Domains {
+			placeholders[i] = bind(v)
+		}
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain IN (%s))`,
+			alias, strings.Join(placeholders, ",")))
+	}
+	if len(opts.Labels) > 0 {
+		placeholders := make([]string, len(opts.Labels))
+		for i, v := range opts.Labels {
+			placeholders[i] = bind(v)
+		}
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name IN (%s))`,
+			alias, strings.Join(placeholders, ",")))
+	}
+	for _, label := range opts.LabelsAll {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name = %s)`, alias, bind(label)))
+	}
+	if len(opts.Priorities) > 0 {
+		placeholders := make([]string, len(opts.Priorities))
+		for i, p := range opts.Priorities {
+			placeholders[i] = bind(p)
+		}
+		where = append(where, fmt.Sprintf("%s.priority IN (%s)", alias, strings.Join(placeholders, ",")))
+	}
+
+	return where, args
+}
+
+func scanMessageRow(row interface{ Scan(...any) error }) (*Message, error) {
+	var msg Message
+	var sentAt time.Time
+	var deletedAt sql.NullTime
+	err := row.Scan(&msg.ID, &msg.SourceID, &msg.SourceMessageID, &msg.ConversationID, &msg.Subject, &msg.Snippet,
+		&sentAt, &msg.SizeEstimate, &msg.HasAttachments, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	msg.Date = sentAt
+	if deletedAt.Valid {
+		msg.DeletedFromSourceAt = &deletedAt.Time
+	}
+	return &msg, nil
+}
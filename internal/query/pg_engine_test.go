@@ -0,0 +1,330 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAggregateFilterClause_Exclusions checks that each AggregateOptions
+// exclusion field lowers to a NOT EXISTS predicate and that its argument is
+// threaded through, independent of any database connection.
+func TestAggregateFilterClause_Exclusions(t *testing.T) {
+	opts := AggregateOptions{
+		ExcludeFromAddrs: []string{"bob@example.com"},
+		ExcludeLabels:    []string{"IMPORTANT"},
+	}
+
+	where, args := aggregateFilterClause(opts, "m", 0)
+	if len(where) != 2 {
+		t.Fatalf("where: got %d predicates, want 2: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "NOT EXISTS") || !strings.Contains(where[0], "recipient_type = 'from'") {
+		t.Errorf("ExcludeFromAddrs predicate: got %q", where[0])
+	}
+	if !strings.Contains(where[1], "NOT EXISTS") || !strings.Contains(where[1], "l.name") {
+		t.Errorf("ExcludeLabels predicate: got %q", where[1])
+	}
+	if len(args) != 2 || args[0] != "bob@example.com" || args[1] != "IMPORTANT" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestAggregateFilterClause_RangesAndAttachments checks the size/date/
+// attachment-type range predicates used by, e.g., "aggregate by recipient
+// where attachment_size > 10000 AND sent_after 2024-02-01".
+func TestAggregateFilterClause_RangesAndAttachments(t *testing.T) {
+	sizeMin := int64(10000)
+	sentAfter, err := time.Parse(time.RFC3339, "2024-02-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("parse sentAfter: %v", err)
+	}
+	opts := AggregateOptions{
+		SizeMin:             &sizeMin,
+		SentAfter:           &sentAfter,
+		AttachmentMimeTypes: []string{"application/pdf"},
+	}
+
+	where, args := aggregateFilterClause(opts, "m", 0)
+	if len(where) != 3 {
+		t.Fatalf("where: got %d predicates, want 3: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "a.size >=") {
+		t.Errorf("SizeMin predicate: got %q", where[0])
+	}
+	if !strings.Contains(where[1], "m.sent_at >") {
+		t.Errorf("SentAfter predicate: got %q", where[1])
+	}
+	if !strings.Contains(where[2], "a.content_type") {
+		t.Errorf("AttachmentMimeTypes predicate: got %q", where[2])
+	}
+	if len(args) != 3 || args[0] != sizeMin || args[1] != sentAfter || args[2] != "application/pdf" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestAggregateFilterClause_StartIndexContinuesNumbering checks that
+// placeholders continue from startIndex so this clause's output can be
+// concatenated after an existing filterClause's args, as the Aggregate*
+// methods do.
+func TestAggregateFilterClause_StartIndexContinuesNumbering(t *testing.T) {
+	opts := AggregateOptions{ExcludeDomains: []string{"spam.example.com"}}
+
+	where, args := aggregateFilterClause(opts, "m", 2)
+	if len(args) != 1 {
+		t.Fatalf("args: got %d, want 1", len(args))
+	}
+	if !strings.Contains(where[0], "$3") {
+		t.Errorf("placeholder: got %q, want it to reference $3", where[0])
+	}
+}
+
+func TestAggregateFilterClause_Empty(t *testing.T) {
+	where, args := aggregateFilterClause(AggregateOptions{}, "m", 0)
+	if len(where) != 0 || len(args) != 0 {
+		t.Errorf("expected no predicates for empty AggregateOptions, got where=%v args=%v", where, args)
+	}
+}
+
+// TestFilterClause_MultiSenderOR checks that Senders lowers to a single
+// IN-list predicate (OR semantics across the listed addresses) rather than
+// one predicate per sender.
+func TestFilterClause_MultiSenderOR(t *testing.T) {
+	filter := MessageFilter{Senders: []string{"alice@example.com", "bob@example.com", "carol@example.com"}}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 1 {
+		t.Fatalf("where: got %d predicates, want 1: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "recipient_type = 'from'") || !strings.Contains(where[0], "IN ($1,$2,$3)") {
+		t.Errorf("Senders predicate: got %q", where[0])
+	}
+	if len(args) != 3 {
+		t.Errorf("args: got %v, want 3 senders", args)
+	}
+}
+
+// TestFilterClause_LabelsOrVsLabelsAllAnd checks that Labels (any) produces
+// one IN-list predicate while LabelsAll (every) produces one EXISTS per
+// label, so the two compose to OR-within-AND semantics.
+func TestFilterClause_LabelsOrVsLabelsAllAnd(t *testing.T) {
+	filter := MessageFilter{
+		Labels:    []string{"Work", "Urgent"},
+		LabelsAll: []string{"Reviewed"},
+	}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 2 {
+		t.Fatalf("where: got %d predicates, want 2: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "IN ($1,$2)") {
+		t.Errorf("Labels predicate: got %q", where[0])
+	}
+	if !strings.Contains(where[1], "l.name = $3") {
+		t.Errorf("LabelsAll predicate: got %q", where[1])
+	}
+	if len(args) != 3 || args[0] != "Work" || args[1] != "Urgent" || args[2] != "Reviewed" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestFilterClause_ExcludedLabels checks that ExcludeLabels produces a
+// NOT EXISTS IN-list predicate.
+func TestFilterClause_ExcludedLabels(t *testing.T) {
+	filter := MessageFilter{ExcludeLabels: []string{"Archive", "Spam"}}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 1 {
+		t.Fatalf("where: got %d predicates, want 1: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "NOT EXISTS") || !strings.Contains(where[0], "IN ($1,$2)") {
+		t.Errorf("ExcludeLabels predicate: got %q", where[0])
+	}
+	if len(args) != 2 || args[0] != "Archive" || args[1] != "Spam" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestFilterClause_MultiFieldCombination checks senders, labels (OR), and
+// excluded labels composing into one WHERE clause with continuously
+// numbered placeholders, mirroring "mail from any of these N people, tagged
+// Work OR Urgent, not Archived".
+func TestFilterClause_MultiFieldCombination(t *testing.T) {
+	filter := MessageFilter{
+		Senders:       []string{"alice@example.com", "bob@example.com"},
+		Labels:        []string{"Work", "Urgent"},
+		ExcludeLabels: []string{"Archive"},
+	}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 3 {
+		t.Fatalf("where: got %d predicates, want 3: %v", len(where), where)
+	}
+	if len(args) != 5 {
+		t.Fatalf("args: got %d, want 5: %v", len(args), args)
+	}
+	if !strings.Contains(where[2], "$5") {
+		t.Errorf("ExcludeLabels placeholder should continue numbering after Senders/Labels, got %q", where[2])
+	}
+}
+
+// TestFilterClause_Priorities checks that Priorities lowers to an IN-list
+// predicate against a priority column.
+func TestFilterClause_Priorities(t *testing.T) {
+	filter := MessageFilter{Priorities: []int{1, 2}}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 1 || !strings.Contains(where[0], "m.priority IN ($1,$2)") {
+		t.Fatalf("Priorities predicate: got %v", where)
+	}
+	if len(args) != 2 || args[0] != 1 || args[1] != 2 {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestFilterClause_EmptyBuckets checks that each MatchEmpty* flag lowers to
+// a NOT EXISTS predicate with no bound arguments, and that combining all
+// four adds one predicate apiece.
+func TestFilterClause_EmptyBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  MessageFilter
+		wantSQL string
+	}{
+		{"sender", MessageFilter{MatchEmptySender: true}, "recipient_type = 'from'"},
+		{"recipients", MessageFilter{MatchEmptyRecipients: true}, "recipient_type IN ('to', 'cc')"},
+		{"labels", MessageFilter{MatchEmptyLabels: true}, "message_labels ml"},
+		{"domain", MessageFilter{MatchEmptyDomain: true}, "p.domain IS NOT NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := filterClause(tt.filter, "m")
+			if len(where) != 1 {
+				t.Fatalf("where: got %d predicates, want 1: %v", len(where), where)
+			}
+			if !strings.HasPrefix(where[0], "NOT EXISTS") {
+				t.Errorf("predicate: got %q, want it to start with NOT EXISTS", where[0])
+			}
+			if !strings.Contains(where[0], tt.wantSQL) {
+				t.Errorf("predicate: got %q, want it to contain %q", where[0], tt.wantSQL)
+			}
+			if len(args) != 0 {
+				t.Errorf("args: got %v, want none (MatchEmpty* flags bind no values)", args)
+			}
+		})
+	}
+}
+
+// TestFilterClause_EmptyBucketsCombined checks that all four flags combine
+// into four independent predicates.
+func TestFilterClause_EmptyBucketsCombined(t *testing.T) {
+	filter := MessageFilter{
+		MatchEmptySender:     true,
+		MatchEmptyRecipients: true,
+		MatchEmptyLabels:     true,
+		MatchEmptyDomain:     true,
+	}
+
+	where, args := filterClause(filter, "m")
+	if len(where) != 4 {
+		t.Fatalf("where: got %d predicates, want 4: %v", len(where), where)
+	}
+	if len(args) != 0 {
+		t.Errorf("args: got %v, want none", args)
+	}
+}
+
+// TestFilterClause_HasAttachmentTriState checks that HasAttachment's two
+// non-nil states produce opposite predicates and a nil pointer adds none.
+func TestFilterClause_HasAttachmentTriState(t *testing.T) {
+	yes, no := true, false
+
+	where, _ := filterClause(MessageFilter{HasAttachment: &yes}, "m")
+	if len(where) != 1 || where[0] != "m.has_attachments" {
+		t.Errorf("HasAttachment=true: got %v", where)
+	}
+
+	where, _ = filterClause(MessageFilter{HasAttachment: &no}, "m")
+	if len(where) != 1 || where[0] != "NOT m.has_attachments" {
+		t.Errorf("HasAttachment=false: got %v", where)
+	}
+
+	where, _ = filterClause(MessageFilter{}, "m")
+	if len(where) != 0 {
+		t.Errorf("HasAttachment=nil: got %v, want no predicate", where)
+	}
+}
+
+// TestFilterClause_HasReplyToVsHasInReplyTo checks that the two header
+// presence flags produce distinct predicates against distinct columns -
+// Reply-To (where replies should go) versus In-Reply-To (threading).
+func TestFilterClause_HasReplyToVsHasInReplyTo(t *testing.T) {
+	yes := true
+
+	where, _ := filterClause(MessageFilter{HasReplyTo: &yes}, "m")
+	if len(where) != 1 || !strings.Contains(where[0], "mh.reply_to") {
+		t.Errorf("HasReplyTo: got %v", where)
+	}
+
+	where, _ = filterClause(MessageFilter{HasInReplyTo: &yes}, "m")
+	if len(where) != 1 || !strings.Contains(where[0], "mh.in_reply_to") {
+		t.Errorf("HasInReplyTo: got %v", where)
+	}
+}
+
+// TestFilterClause_SentRangeAndSize checks that SentAfter/SentBefore and
+// SizeMin/SizeMax each add a bound predicate, and that the zero values
+// (unset) add none.
+func TestFilterClause_SentRangeAndSize(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	where, args := filterClause(MessageFilter{SentAfter: after, SentBefore: before, SizeMin: 1000, SizeMax: 5_000_000}, "m")
+	if len(where) != 4 {
+		t.Fatalf("where: got %d predicates, want 4: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "sent_at >") || !strings.Contains(where[1], "sent_at <") {
+		t.Errorf("date bounds: got %v", where[:2])
+	}
+	if !strings.Contains(where[2], "size_estimate >=") || !strings.Contains(where[3], "size_estimate <=") {
+		t.Errorf("size bounds: got %v", where[2:])
+	}
+	if len(args) != 4 {
+		t.Errorf("args: got %v, want 4", args)
+	}
+
+	where, args = filterClause(MessageFilter{}, "m")
+	if len(where) != 0 || len(args) != 0 {
+		t.Errorf("zero-value filter: got where=%v args=%v, want none", where, args)
+	}
+}
+
+// TestFilterClause_DomainsAndSizeAndAttachmentCombination checks a
+// realistic combined query: domain in {foo,bar} AND sent_after=X AND
+// has_attachment=false.
+func TestFilterClause_DomainsAndSizeAndAttachmentCombination(t *testing.T) {
+	no := false
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	where, args := filterClause(MessageFilter{
+		Domains:       []string{"foo.com", "bar.com"},
+		SentAfter:     after,
+		HasAttachment: &no,
+	}, "m")
+
+	if len(where) != 3 {
+		t.Fatalf("where: got %d predicates, want 3: %v", len(where), where)
+	}
+	if !strings.Contains(where[0], "IN ($1,$2)") {
+		t.Errorf("Domains predicate: got %q", where[0])
+	}
+	if !strings.Contains(where[1], "sent_at > $3") {
+		t.Errorf("SentAfter predicate: got %q", where[1])
+	}
+	if where[2] != "NOT m.has_attachments" {
+		t.Errorf("HasAttachment predicate: got %q", where[2])
+	}
+	if len(args) != 3 {
+		t.Errorf("args: got %v, want 3", args)
+	}
+}
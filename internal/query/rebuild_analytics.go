@@ -0,0 +1,440 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rebuildBatchSize is how many rows RebuildAnalytics reads out of SQLite and
+// inserts into the DuckDB staging table per transaction. It's a var rather
+// than a const so tests can shrink it to exercise batching without needing
+// millions of rows, matching dataset.copyBatchSize's rationale.
+var rebuildBatchSize = 10000
+
+// RebuildOptions configures RebuildAnalytics.
+type RebuildOptions struct {
+	// DryRun reports the row counts RebuildAnalytics would write to each
+	// Parquet table, without writing anything.
+	DryRun bool
+
+	// Verify re-runs a handful of aggregate totals against both the source
+	// SQLite database and the freshly written Parquet tree after the
+	// rebuild completes, returning an error if they disagree.
+	Verify bool
+
+	// Progress, if set, is called once a table (or, for messages, a year
+	// partition) has finished writing.
+	Progress func(table string, rows int64)
+}
+
+// RebuildResult reports what RebuildAnalytics wrote (or, in dry-run mode,
+// would write) per table.
+type RebuildResult struct {
+	Rows map[string]int64
+}
+
+// rebuildTable is a flat (non-partitioned) analytics table RebuildAnalytics
+// copies out of SQLite into a single Parquet file.
+type rebuildTable struct {
+	name string // analytics table name, also its subdirectory under analyticsDir
+	file string // filename within that subdirectory
+	// sourceQuery selects exactly the columns createSQL's staging table
+	// expects, in order, from the SQLite vault.
+	sourceQuery string
+	createSQL   string
+}
+
+// flatTables lists every analytics table RebuildAnalytics writes as a single
+// Parquet file (everything except messages, which is partitioned by year).
+// Columns mirror setupTestParquet's fixtures in duckdb_test.go, since that's
+// the schema DuckDBEngine already reads.
+var flatTables = []rebuildTable{
+	{
+		name:        "sources",
+		file:        "sources.parquet",
+		sourceQuery: "SELECT id, account_email FROM sources",
+		createSQL:   "CREATE TABLE staging (id BIGINT, account_email VARCHAR)",
+	},
+	{
+		name: "participants",
+		file: "participants.parquet",
+		sourceQuery: `SELECT id, email_address,
+			substr(email_address, instr(email_address, '@') + 1) AS domain,
+			display_name
+			FROM participants`,
+		createSQL: "CREATE TABLE staging (id BIGINT, email_address VARCHAR, domain VARCHAR, display_name VARCHAR)",
+	},
+	{
+		name:        "message_recipients",
+		file:        "message_recipients.parquet",
+		sourceQuery: "SELECT message_id, participant_id, recipient_type, display_name FROM message_recipients",
+		createSQL:   "CREATE TABLE staging (message_id BIGINT, participant_id BIGINT, recipient_type VARCHAR, display_name VARCHAR)",
+	},
+	{
+		name:        "labels",
+		file:        "labels.parquet",
+		sourceQuery: "SELECT id, name FROM labels",
+		createSQL:   "CREATE TABLE staging (id BIGINT, name VARCHAR)",
+	},
+	{
+		name:        "message_labels",
+		file:        "message_labels.parquet",
+		sourceQuery: "SELECT message_id, label_id FROM message_labels",
+		createSQL:   "CREATE TABLE staging (message_id BIGINT, label_id BIGINT)",
+	},
+	{
+		name:        "attachments",
+		file:        "attachments.parquet",
+		sourceQuery: "SELECT message_id, size, filename FROM attachments",
+		createSQL:   "CREATE TABLE staging (message_id BIGINT, size BIGINT, filename VARCHAR)",
+	},
+}
+
+// messagesSourceQuery selects every column the messages Parquet table needs,
+// in order, computing year/month from sent_at the same way
+// candidateMessagesQuery in tools/devdata filters on sent_at.
+const messagesSourceQuery = `
+	SELECT id, source_id, source_message_id, conversation_id, subject, snippet,
+	       sent_at, size_estimate, has_attachments, deleted_from_source_at,
+	       CAST(strftime(sent_at, '%Y') AS INTEGER) AS year,
+	       CAST(strftime(sent_at, '%m') AS INTEGER) AS month
+	FROM messages`
+
+const messagesCreateSQL = `
+	CREATE TABLE staging (
+		id BIGINT, source_id BIGINT, source_message_id VARCHAR, conversation_id BIGINT,
+		subject VARCHAR, snippet VARCHAR, sent_at TIMESTAMP, size_estimate BIGINT,
+		has_attachments BOOLEAN, deleted_from_source_at TIMESTAMP, year INTEGER, month INTEGER
+	)`
+
+// RebuildAnalytics rebuilds the normalized Parquet analytics tree
+// (messages/year=YYYY, sources, participants, message_recipients, labels,
+// message_labels, attachments) that DuckDBEngine reads, from a SQLite vault
+// that has none yet — the Parquet-side analog of soju's migrate-logs tool.
+//
+// It streams rows out of sqliteDB in rebuildBatchSize batches into an
+// in-memory DuckDB staging table per analytics table, then writes each
+// staging table to analyticsDir as Parquet via "COPY ... TO ... (FORMAT
+// PARQUET)" (partitioned by year for messages). Each output file is written
+// to a ".tmp" sibling and renamed into place only once complete, so a run
+// interrupted mid-table can simply be repeated: finished tables are
+// overwritten identically and no reader ever observes a partial file.
+//
+// With opts.DryRun, RebuildAnalytics reports the row counts it would write
+// per table without opening DuckDB or touching analyticsDir. With
+// opts.Verify, it re-reads the freshly written Parquet tree after a
+// non-dry-run rebuild and compares each table's row count against sqliteDB,
+// returning an error on any mismatch.
+func RebuildAnalytics(ctx context.Context, sqliteDB *sql.DB, analyticsDir string, opts RebuildOptions) (*RebuildResult, error) {
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(string, int64) {}
+	}
+
+	result := &RebuildResult{Rows: make(map[string]int64)}
+
+	if opts.DryRun {
+		for _, t := range flatTables {
+			n, err := countRows(sqliteDB, t.sourceQuery)
+			if err != nil {
+				return nil, fmt.Errorf("count %s: %w", t.name, err)
+			}
+			result.Rows[t.name] = n
+			progress(t.name, n)
+		}
+		n, err := countRows(sqliteDB, messagesSourceQuery)
+		if err != nil {
+			return nil, fmt.Errorf("count messages: %w", err)
+		}
+		result.Rows["messages"] = n
+		progress("messages", n)
+		return result, nil
+	}
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("open duckdb: %w", err)
+	}
+	defer db.Close()
+
+	for _, t := range flatTables {
+		n, err := rebuildFlatTable(ctx, db, sqliteDB, analyticsDir, t)
+		if err != nil {
+			return nil, fmt.Errorf("rebuild %s: %w", t.name, err)
+		}
+		result.Rows[t.name] = n
+		progress(t.name, n)
+	}
+
+	n, err := rebuildMessages(ctx, db, sqliteDB, analyticsDir)
+	if err != nil {
+		return nil, fmt.Errorf("rebuild messages: %w", err)
+	}
+	result.Rows["messages"] = n
+	progress("messages", n)
+
+	if opts.Verify {
+		if err := verifyRebuild(ctx, db, sqliteDB, analyticsDir, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// countRows returns the row count of sourceQuery run as a subquery, used for
+// dry-run reporting without ever opening DuckDB.
+func countRows(sqliteDB *sql.DB, sourceQuery string) (int64, error) {
+	var n int64
+	err := sqliteDB.QueryRow("SELECT COUNT(*) FROM (" + sourceQuery + ")").Scan(&n)
+	return n, err
+}
+
+// rebuildFlatTable streams t's rows out of sqliteDB into a fresh DuckDB
+// staging table, then writes that table to analyticsDir/t.name/t.file as
+// Parquet via a ".tmp" sibling renamed into place on success.
+func rebuildFlatTable(ctx context.Context, db *sql.DB, sqliteDB *sql.DB, analyticsDir string, t rebuildTable) (int64, error) {
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS staging"); err != nil {
+		return 0, fmt.Errorf("drop staging table: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, t.createSQL); err != nil {
+		return 0, fmt.Errorf("create staging table: %w", err)
+	}
+
+	n, err := streamInto(ctx, db, sqliteDB, "staging", t.sourceQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := filepath.Join(analyticsDir, t.name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	final := filepath.Join(dir, t.file)
+	if err := copyStagingToParquet(ctx, db, final, ""); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// rebuildMessages is rebuildFlatTable's messages-specific counterpart: the
+// staging table is written to analyticsDir/messages, partitioned by the year
+// column, one "year=YYYY/data.parquet.tmp" renamed into place per partition
+// so an interrupted rebuild only needs to redo the years it hadn't finished.
+func rebuildMessages(ctx context.Context, db *sql.DB, sqliteDB *sql.DB, analyticsDir string) (int64, error) {
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS staging"); err != nil {
+		return 0, fmt.Errorf("drop staging table: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, messagesCreateSQL); err != nil {
+		return 0, fmt.Errorf("create staging table: %w", err)
+	}
+
+	n, err := streamInto(ctx, db, sqliteDB, "staging", messagesSourceQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT DISTINCT year FROM staging ORDER BY year")
+	if err != nil {
+		return 0, fmt.Errorf("list years: %w", err)
+	}
+	defer rows.Close()
+
+	var years []int
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return 0, fmt.Errorf("scan year: %w", err)
+		}
+		years = append(years, year)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, year := range years {
+		dir := filepath.Join(analyticsDir, "messages", fmt.Sprintf("year=%d", year))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return 0, fmt.Errorf("create %s: %w", dir, err)
+		}
+		final := filepath.Join(dir, "data.parquet")
+		where := fmt.Sprintf("WHERE year = %d", year)
+		if err := copyStagingToParquet(ctx, db, final, where); err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// copyStagingToParquet writes "SELECT * FROM staging <where>" to a ".tmp"
+// sibling of dest and renames it into place, so dest only ever exists once
+// it's complete.
+func copyStagingToParquet(ctx context.Context, db *sql.DB, dest, where string) error {
+	tmp := dest + ".tmp"
+	escaped := escapeParquetPath(tmp)
+	copySQL := fmt.Sprintf("COPY (SELECT * FROM staging %s) TO '%s' (FORMAT PARQUET)", where, escaped)
+	if _, err := db.ExecContext(ctx, copySQL); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return fmt.Errorf("rename %s into place: %w", tmp, err)
+	}
+	return nil
+}
+
+// escapeParquetPath escapes dest for interpolation into a DuckDB string
+// literal, mirroring setupTestParquet's escapePath helper in duckdb_test.go.
+func escapeParquetPath(p string) string {
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '\'' {
+			out = append(out, '\'', '\'')
+			continue
+		}
+		out = append(out, p[i])
+	}
+	return string(out)
+}
+
+// streamInto copies sourceQuery's result set out of sqliteDB into db's
+// tableName, rebuildBatchSize rows per transaction, returning the total rows
+// copied.
+func streamInto(ctx context.Context, db *sql.DB, sqliteDB *sql.DB, tableName, sourceQuery string) (int64, error) {
+	srcRows, err := sqliteDB.QueryContext(ctx, sourceQuery)
+	if err != nil {
+		return 0, fmt.Errorf("query source rows: %w", err)
+	}
+	defer srcRows.Close()
+
+	cols, err := srcRows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("columns: %w", err)
+	}
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO %s VALUES (%s)", tableName, joinComma(placeholders))
+
+	dest := make([]any, len(cols))
+	destPtrs := make([]any, len(cols))
+	for i := range dest {
+		destPtrs[i] = &dest[i]
+	}
+
+	var total int64
+	var tx *sql.Tx
+	var stmt *sql.Stmt
+	var inBatch int
+
+	commit := func() error {
+		if stmt != nil {
+			stmt.Close()
+			stmt = nil
+		}
+		if tx != nil {
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("commit batch: %w", err)
+			}
+			tx = nil
+		}
+		inBatch = 0
+		return nil
+	}
+
+	for srcRows.Next() {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		if err := srcRows.Scan(destPtrs...); err != nil {
+			return total, fmt.Errorf("scan source row: %w", err)
+		}
+
+		if tx == nil {
+			tx, err = db.BeginTx(ctx, nil)
+			if err != nil {
+				return total, fmt.Errorf("begin batch: %w", err)
+			}
+			stmt, err = tx.PrepareContext(ctx, insertSQL)
+			if err != nil {
+				return total, fmt.Errorf("prepare insert: %w", err)
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx, dest...); err != nil {
+			return total, fmt.Errorf("insert row: %w", err)
+		}
+		total++
+		inBatch++
+
+		if inBatch >= rebuildBatchSize {
+			if err := commit(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err := srcRows.Err(); err != nil {
+		return total, fmt.Errorf("iterate source rows: %w", err)
+	}
+	if inBatch > 0 {
+		if err := commit(); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// joinComma is a tiny strings.Join(s, ", ") to avoid importing strings for a
+// single call site.
+func joinComma(parts []string) string {
+	out := ""
+	for i, p := range parts {
+		if i > 0 {
+			out += ", "
+		}
+		out += p
+	}
+	return out
+}
+
+// verifyRebuild re-reads the freshly written Parquet tree and compares each
+// table's row count against sqliteDB, appending a multi-error if any table
+// disagrees. Message counts are compared via a parquet_scan over the whole
+// messages/ tree (all years), the same glob DuckDBEngine would use to read
+// it back.
+func verifyRebuild(ctx context.Context, db *sql.DB, sqliteDB *sql.DB, analyticsDir string, result *RebuildResult) error {
+	var mismatches []string
+
+	for _, t := range flatTables {
+		path := escapeParquetPath(filepath.Join(analyticsDir, t.name, t.file))
+		var parquetCount int64
+		if err := db.QueryRowContext(ctx, fmt.Sprintf(
+			"SELECT COUNT(*) FROM read_parquet('%s')", path)).Scan(&parquetCount); err != nil {
+			return fmt.Errorf("verify %s: read parquet: %w", t.name, err)
+		}
+		if parquetCount != result.Rows[t.name] {
+			mismatches = append(mismatches, fmt.Sprintf("%s: sqlite=%d parquet=%d", t.name, result.Rows[t.name], parquetCount))
+		}
+	}
+
+	messagesGlob := escapeParquetPath(filepath.Join(analyticsDir, "messages", "*", "*.parquet"))
+	var messagesCount int64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT COUNT(*) FROM read_parquet('%s')", messagesGlob)).Scan(&messagesCount); err != nil {
+		return fmt.Errorf("verify messages: read parquet: %w", err)
+	}
+	if messagesCount != result.Rows["messages"] {
+		mismatches = append(mismatches, fmt.Sprintf("messages: sqlite=%d parquet=%d", result.Rows["messages"], messagesCount))
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("rebuild verification failed: %v", mismatches)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// SavedQuery is a named, persisted search string -- the query package's
+// analog of a notmuch saved search. Once created it can be listed
+// alongside its live message count (VirtualFolders) or compiled to its own
+// Parquet partition (Materialize) instead of being re-parsed and re-run
+// against the full messages table on every read.
+type SavedQuery struct {
+	ID          int64
+	Name        string
+	QueryString string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// VirtualFolder pairs a SavedQuery with its live message count, the shape
+// CLI listing and aggregation output present saved queries in alongside a
+// vault's real labels and mailboxes.
+type VirtualFolder struct {
+	SavedQuery
+	MessageCount int64
+}
+
+// savedQueryNameRe restricts SavedQuery names to the characters safe to use
+// verbatim as a Parquet partition filename (see Materialize), so a name
+// like "../../etc/passwd" can never escape analyticsDir.
+var savedQueryNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// SavedQueries provides CRUD for SavedQuery rows plus the operations that
+// make them useful (VirtualFolders, Materialize), backed by a
+// saved_queries table in the vault's SQLite metadata database -- the same
+// database the --db flag already points serve grpc/serve mcp at.
+type SavedQueries struct {
+	db *sql.DB
+}
+
+// NewSavedQueries returns a SavedQueries backed by db, the vault's SQLite
+// metadata database. It also wires itself in as search's saved:<name>
+// resolver and SaveNamed's persistence (search.SetSavedQueryLookup/
+// SetSavedQuerySaver), so a saved:<name> clause parsed anywhere in the
+// process after this call expands against this database.
+func NewSavedQueries(db *sql.DB) *SavedQueries {
+	sq := &SavedQueries{db: db}
+	search.SetSavedQueryLookup(sq.lookup)
+	search.SetSavedQuerySaver(sq.save)
+	return sq
+}
+
+// lookup is search.SavedQueryLookup backed by sq.
+func (sq *SavedQueries) lookup(name string) (string, bool) {
+	saved, err := sq.Get(context.Background(), name)
+	if err != nil || saved == nil {
+		return "", false
+	}
+	return saved.QueryString, true
+}
+
+// save is search.SavedQuerySaver backed by sq: create or, if name already
+// exists, overwrite its query string.
+func (sq *SavedQueries) save(name, raw string) error {
+	ctx := context.Background()
+	existing, err := sq.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("saved queries: save %q: %w", name, err)
+	}
+	if existing == nil {
+		_, err := sq.Create(ctx, name, raw)
+		return err
+	}
+	if _, _, err := lowerSearchAST(search.Parse(raw).AST, "m"); err != nil {
+		return fmt.Errorf("saved queries: save %q: %w", name, err)
+	}
+	_, err = sq.db.ExecContext(ctx, `
+		UPDATE saved_queries SET query_string = ?, updated_at = ? WHERE name = ?`,
+		raw, time.Now().UTC(), name)
+	if err != nil {
+		return fmt.Errorf("saved queries: save %q: %w", name, err)
+	}
+	return nil
+}
+
+// Create validates queryString (it must parse and lower to SQL the same
+// way a live search would) and persists it as a new named SavedQuery.
+// Create fails if name is already taken or isn't safe for use in a
+// Parquet partition filename (see savedQueryNameRe).
+func (sq *SavedQueries) Create(ctx context.Context, name, queryString string) (*SavedQuery, error) {
+	if !savedQueryNameRe.MatchString(name) {
+		return nil, fmt.Errorf("saved queries: name %q must match %s", name, savedQueryNameRe.String())
+	}
+	if _, _, err := lowerSearchAST(search.Parse(queryString).AST, "m"); err != nil {
+		return nil, fmt.Errorf("saved queries: create %q: %w", name, err)
+	}
+
+	now := time.Now().UTC()
+	res, err := sq.db.ExecContext(ctx, `
+		INSERT INTO saved_queries (name, query_string, created_at, updated_at)
+		VALUES (?, ?, ?, ?)`, name, queryString, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: create %q: %w", name, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: create %q: %w", name, err)
+	}
+	return &SavedQuery{ID: id, Name: name, QueryString: queryString, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// Get returns the SavedQuery named name, or nil if none exists.
+func (sq *SavedQueries) Get(ctx context.Context, name string) (*SavedQuery, error) {
+	row := sq.db.QueryRowContext(ctx, `
+		SELECT id, name, query_string, created_at, updated_at
+		FROM saved_queries WHERE name = ?`, name)
+	q, err := scanSavedQuery(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: get %q: %w", name, err)
+	}
+	return q, nil
+}
+
+// List returns every SavedQuery, ordered by name.
+func (sq *SavedQueries) List(ctx context.Context) ([]*SavedQuery, error) {
+	rows, err := sq.db.QueryContext(ctx, `
+		SELECT id, name, query_string, created_at, updated_at
+		FROM saved_queries ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: list: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*SavedQuery
+	for rows.Next() {
+		q, err := scanSavedQuery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("saved queries: list: scan: %w", err)
+		}
+		out = append(out, q)
+	}
+	return out, rows.Err()
+}
+
+// Delete removes the SavedQuery named name. Deleting a name that doesn't
+// exist is not an error.
+func (sq *SavedQueries) Delete(ctx context.Context, name string) error {
+	if _, err := sq.db.ExecContext(ctx, `DELETE FROM saved_queries WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("saved queries: delete %q: %w", name, err)
+	}
+	return nil
+}
+
+// savedQueryRow is satisfied by both *sql.Row and *sql.Rows, so
+// scanSavedQuery can back both Get and List.
+type savedQueryRow interface {
+	Scan(dest ...any) error
+}
+
+func scanSavedQuery(row savedQueryRow) (*SavedQuery, error) {
+	var q SavedQuery
+	if err := row.Scan(&q.ID, &q.Name, &q.QueryString, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// VirtualFolders returns every SavedQuery paired with how many messages in
+// the Parquet analytics tree at analyticsDir currently match it, the way a
+// notmuch saved search's unread count appears as a folder in an aerc-style
+// sidebar. Each count is computed independently by lowering the saved
+// query's AST with lowerSearchAST and running it against the same
+// read_parquet glob DuckDBEngine reads messages from.
+func (sq *SavedQueries) VirtualFolders(ctx context.Context, analyticsDir string) ([]VirtualFolder, error) {
+	saved, err := sq.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) == 0 {
+		return nil, nil
+	}
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: open duckdb: %w", err)
+	}
+	defer db.Close()
+
+	glob := escapeParquetPath(filepath.Join(analyticsDir, "messages", "*", "*.parquet"))
+
+	folders := make([]VirtualFolder, 0, len(saved))
+	for _, s := range saved {
+		where, args, err := lowerSearchAST(search.Parse(s.QueryString).AST, "m")
+		if err != nil {
+			return nil, fmt.Errorf("saved queries: %q: %w", s.Name, err)
+		}
+
+		var count int64
+		countSQL := fmt.Sprintf("SELECT COUNT(*) FROM read_parquet('%s') m WHERE %s", glob, where)
+		if err := db.QueryRowContext(ctx, countSQL, args...).Scan(&count); err != nil {
+			return nil, fmt.Errorf("saved queries: count %q: %w", s.Name, err)
+		}
+		folders = append(folders, VirtualFolder{SavedQuery: *s, MessageCount: count})
+	}
+	return folders, nil
+}
+
+// Materialize compiles the SavedQuery named name to SQL and writes its
+// current result set to analyticsDir/saved/<name>.parquet, the same
+// stage-then-rename pattern RebuildAnalytics uses for the primary
+// analytics tree: the result is written to a ".tmp" sibling and renamed
+// into place, so a reader never observes a partially-written file, and
+// re-running Materialize simply overwrites the previous partition. A
+// saved query with an expensive predicate (e.g. a wide OR over many
+// participants) can then be read back with a plain read_parquet scan
+// instead of re-evaluating the predicate on every read.
+func (sq *SavedQueries) Materialize(ctx context.Context, name, analyticsDir string) (*RebuildResult, error) {
+	saved, err := sq.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, fmt.Errorf("saved queries: materialize: no saved query named %q", name)
+	}
+
+	where, args, err := lowerSearchAST(search.Parse(saved.QueryString).AST, "m")
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: materialize %q: %w", name, err)
+	}
+
+	destDir := filepath.Join(analyticsDir, "saved")
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return nil, fmt.Errorf("saved queries: materialize %q: mkdir %s: %w", name, destDir, err)
+	}
+	dest := filepath.Join(destDir, name+".parquet")
+	tmp := dest + ".tmp"
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("saved queries: open duckdb: %w", err)
+	}
+	defer db.Close()
+
+	glob := escapeParquetPath(filepath.Join(analyticsDir, "messages", "*", "*.parquet"))
+	copySQL := fmt.Sprintf(
+		"COPY (SELECT * FROM read_parquet('%s') m WHERE %s) TO '%s' (FORMAT PARQUET)",
+		glob, where, escapeParquetPath(tmp))
+	if _, err := db.ExecContext(ctx, copySQL, args...); err != nil {
+		return nil, fmt.Errorf("saved queries: materialize %q: %w", name, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return nil, fmt.Errorf("saved queries: materialize %q: rename into place: %w", name, err)
+	}
+
+	var rows int64
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM read_parquet('%s')", escapeParquetPath(dest))
+	if err := db.QueryRowContext(ctx, countSQL).Scan(&rows); err != nil {
+		return nil, fmt.Errorf("saved queries: materialize %q: count written rows: %w", name, err)
+	}
+
+	return &RebuildResult{Rows: map[string]int64{name: rows}}, nil
+}
@@ -0,0 +1,221 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestSavedQueriesDB returns a SavedQueries backed by an in-memory
+// SQLite database with a fresh saved_queries table.
+func openTestSavedQueriesDB(t *testing.T) *SavedQueries {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite3: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`
+		CREATE TABLE saved_queries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			query_string TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`); err != nil {
+		t.Fatalf("create saved_queries table: %v", err)
+	}
+
+	return NewSavedQueries(db)
+}
+
+func TestSavedQueries_CRUD(t *testing.T) {
+	ctx := context.Background()
+	sq := openTestSavedQueriesDB(t)
+
+	if _, err := sq.Create(ctx, "unread-from-boss", "from:boss@example.com AND -label:archive"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := sq.Get(ctx, "unread-from-boss")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got == nil || got.QueryString != "from:boss@example.com AND -label:archive" {
+		t.Fatalf("Get: got %+v", got)
+	}
+
+	if _, err := sq.Create(ctx, "invoices", "subject:invoice"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	all, err := sq.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("List: got %d entries, want 2", len(all))
+	}
+	if all[0].Name != "invoices" || all[1].Name != "unread-from-boss" {
+		t.Errorf("List: got %v, want alphabetical order", []string{all[0].Name, all[1].Name})
+	}
+
+	if err := sq.Delete(ctx, "invoices"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	remaining, err := sq.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Name != "unread-from-boss" {
+		t.Fatalf("List after Delete: got %v", remaining)
+	}
+
+	if err := sq.Delete(ctx, "no-such-query"); err != nil {
+		t.Errorf("Delete of unknown name: %v, want nil", err)
+	}
+}
+
+func TestSavedQueries_GetUnknownReturnsNil(t *testing.T) {
+	sq := openTestSavedQueriesDB(t)
+	got, err := sq.Get(context.Background(), "no-such-query")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Get: got %+v, want nil", got)
+	}
+}
+
+func TestSavedQueries_CreateRejectsInvalidName(t *testing.T) {
+	sq := openTestSavedQueriesDB(t)
+	if _, err := sq.Create(context.Background(), "../etc/passwd", "subject:invoice"); err == nil {
+		t.Error("Create with a path-unsafe name succeeded, want error")
+	}
+}
+
+func TestSavedQueries_CreateRejectsUnparseableQuery(t *testing.T) {
+	sq := openTestSavedQueriesDB(t)
+	if _, err := sq.Create(context.Background(), "bad", "mimetype:application/pdf"); err == nil {
+		t.Error("Create with an operator lowerSearchAST can't lower succeeded, want error")
+	}
+}
+
+// buildSavedQueryFixture writes a small messages-only Parquet fixture (two
+// from alice, one from bob) and returns the analytics directory.
+func buildSavedQueryFixture(t *testing.T) (string, func()) {
+	t.Helper()
+	return newParquetBuilder(t).
+		addTable("messages", "messages/year=2024", "data.parquet", messagesCols, `
+			(1::BIGINT, 1::BIGINT, 'msg1', 101::BIGINT, 'Hello', 'Preview 1', TIMESTAMP '2024-01-15 10:00:00', 1000::BIGINT, false, NULL::TIMESTAMP, 2024, 1),
+			(2::BIGINT, 1::BIGINT, 'msg2', 101::BIGINT, 'Re: Hello', 'Preview 2', TIMESTAMP '2024-01-16 11:00:00', 2000::BIGINT, false, NULL::TIMESTAMP, 2024, 1),
+			(3::BIGINT, 1::BIGINT, 'msg3', 102::BIGINT, 'Invoice', 'Preview 3', TIMESTAMP '2024-02-01 09:00:00', 1500::BIGINT, false, NULL::TIMESTAMP, 2024, 2)
+		`).
+		addTable("message_recipients", "message_recipients", "data.parquet", messageRecipientsCols, `
+			(1::BIGINT, 1::BIGINT, 'from', 'Alice'),
+			(2::BIGINT, 1::BIGINT, 'from', 'Alice'),
+			(3::BIGINT, 2::BIGINT, 'from', 'Bob')
+		`).
+		addTable("participants", "participants", "data.parquet", participantsCols, `
+			(1::BIGINT, 'alice@example.com', 'example.com', 'Alice'),
+			(2::BIGINT, 'bob@example.com', 'example.com', 'Bob')
+		`).
+		addEmptyTable("labels", "labels", "data.parquet", labelsCols, "(1::BIGINT, 'x')").
+		addEmptyTable("message_labels", "message_labels", "data.parquet", messageLabelsCols, "(1::BIGINT, 1::BIGINT)").
+		build()
+}
+
+func TestSavedQueries_VirtualFolders(t *testing.T) {
+	analyticsDir, cleanup := buildSavedQueryFixture(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sq := openTestSavedQueriesDB(t)
+	if _, err := sq.Create(ctx, "from-alice", "from:alice@example.com"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := sq.Create(ctx, "invoices", "subject:invoice"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	folders, err := sq.VirtualFolders(ctx, analyticsDir)
+	if err != nil {
+		t.Fatalf("VirtualFolders: %v", err)
+	}
+	if len(folders) != 2 {
+		t.Fatalf("VirtualFolders: got %d, want 2", len(folders))
+	}
+
+	counts := map[string]int64{}
+	for _, f := range folders {
+		counts[f.Name] = f.MessageCount
+	}
+	if counts["from-alice"] != 2 {
+		t.Errorf("from-alice count = %d, want 2", counts["from-alice"])
+	}
+	if counts["invoices"] != 1 {
+		t.Errorf("invoices count = %d, want 1", counts["invoices"])
+	}
+}
+
+func TestSavedQueries_Materialize(t *testing.T) {
+	analyticsDir, cleanup := buildSavedQueryFixture(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sq := openTestSavedQueriesDB(t)
+	if _, err := sq.Create(ctx, "from-alice", "from:alice@example.com"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	result, err := sq.Materialize(ctx, "from-alice", analyticsDir)
+	if err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+	if result.Rows["from-alice"] != 2 {
+		t.Errorf("Materialize result rows = %d, want 2", result.Rows["from-alice"])
+	}
+
+	dest := filepath.Join(analyticsDir, "saved", "from-alice.parquet")
+	if _, err := os.Stat(dest); err != nil {
+		t.Fatalf("stat %s: %v", dest, err)
+	}
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("open duckdb: %v", err)
+	}
+	defer db.Close()
+
+	var count int64
+	if err := db.QueryRow(
+		"SELECT COUNT(*) FROM read_parquet('" + escapeParquetPath(dest) + "')",
+	).Scan(&count); err != nil {
+		t.Fatalf("count materialized rows: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("materialized partition has %d rows, want 2", count)
+	}
+
+	// Re-running Materialize overwrites the partition rather than failing
+	// because the destination already exists.
+	if _, err := sq.Materialize(ctx, "from-alice", analyticsDir); err != nil {
+		t.Fatalf("Materialize (rerun): %v", err)
+	}
+}
+
+func TestSavedQueries_MaterializeUnknownName(t *testing.T) {
+	analyticsDir, cleanup := buildSavedQueryFixture(t)
+	defer cleanup()
+
+	sq := openTestSavedQueriesDB(t)
+	if _, err := sq.Materialize(context.Background(), "no-such-query", analyticsDir); err == nil {
+		t.Error("Materialize of an unknown name succeeded, want error")
+	}
+}
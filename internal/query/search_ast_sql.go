@@ -0,0 +1,198 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// lowerSearchAST lowers a search AST node into a parameterized DuckDB WHERE
+// expression and its positional ("?") arguments, so SearchFast can express
+// boolean queries (AND/OR/NOT, parenthesized grouping, quoted phrases) as a
+// single WHERE clause instead of string-concatenating SQL per flat field.
+// alias is the table alias messages are queried under (e.g. "m"). A nil node
+// lowers to the always-true "1=1" so an empty query adds no predicate.
+func lowerSearchAST(node *search.Node, alias string) (string, []any, error) {
+	if node == nil {
+		return "1=1", nil, nil
+	}
+
+	switch node.Kind {
+	case search.NodeLeaf:
+		return lowerClause(node.Leaf, alias)
+
+	case search.NodeNot:
+		inner, args, err := lowerSearchAST(node.Child, alias)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + inner + ")", args, nil
+
+	case search.NodeAnd, search.NodeOr:
+		if len(node.Children) == 0 {
+			return "1=1", nil, nil
+		}
+		joiner := " AND "
+		if node.Kind == search.NodeOr {
+			joiner = " OR "
+		}
+		var parts []string
+		var args []any
+		for _, child := range node.Children {
+			sql, childArgs, err := lowerSearchAST(child, alias)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, childArgs...)
+		}
+		return "(" + strings.Join(parts, joiner) + ")", args, nil
+
+	default:
+		return "", nil, fmt.Errorf("lower search ast: unsupported node kind %v", node.Kind)
+	}
+}
+
+// lowerClause lowers a single leaf Clause to a WHERE predicate. Operators
+// that the Parquet analytics tree has no column for (body:, since it has no
+// message_bodies table) fall back to the closest available column (snippet)
+// rather than erroring, matching how other analytics-only limitations in
+// this package degrade gracefully.
+func lowerClause(c *search.Clause, alias string) (string, []any, error) {
+	switch c.Op {
+	case "":
+		subjectPred, subjectArgs := matchPredicate(alias+".subject", c)
+		snippetPred, snippetArgs := matchPredicate(alias+".snippet", c)
+		return fmt.Sprintf("(%s OR %s)", subjectPred, snippetPred),
+			append(subjectArgs, snippetArgs...), nil
+	case "subject":
+		pred, args := matchPredicate(alias+".subject", c)
+		return pred, args, nil
+	case "body":
+		return fmt.Sprintf("%s.snippet ILIKE ? ESCAPE '\\'", alias), []any{"%" + escapeILIKE(c.Value) + "%"}, nil
+	case "from":
+		return recipientExistsSQL(alias, "'from'"), []any{escapeILIKE(c.Value)}, nil
+	case "to":
+		return recipientExistsSQL(alias, "'to'"), []any{escapeILIKE(c.Value)}, nil
+	case "cc":
+		return recipientExistsSQL(alias, "'cc'"), []any{escapeILIKE(c.Value)}, nil
+	case "bcc":
+		return recipientExistsSQL(alias, "'bcc'"), []any{escapeILIKE(c.Value)}, nil
+	case "label", "l":
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name = ?)`, alias), []any{c.Value}, nil
+	case "domain":
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND p.domain ILIKE ? ESCAPE '\\')`, alias), []any{escapeILIKE(c.Value)}, nil
+	case "has":
+		if strings.ToLower(c.Value) == "attachment" || strings.ToLower(c.Value) == "attachments" {
+			return fmt.Sprintf("%s.has_attachments", alias), nil, nil
+		}
+		return "1=1", nil, nil
+	case "before":
+		return fmt.Sprintf("%s.sent_at < ?", alias), []any{c.Value}, nil
+	case "after":
+		return fmt.Sprintf("%s.sent_at > ?", alias), []any{c.Value}, nil
+	case "larger":
+		return fmt.Sprintf("%s.size_estimate > ?", alias), []any{c.Value}, nil
+	case "smaller":
+		return fmt.Sprintf("%s.size_estimate < ?", alias), []any{c.Value}, nil
+	case "saved":
+		resolved, err := search.Resolve(c.Value)
+		if err != nil {
+			return "", nil, fmt.Errorf("lower search ast: %w", err)
+		}
+		if resolved.AST == nil {
+			return "1=1", nil, nil
+		}
+		return lowerSearchAST(resolved.AST, alias)
+	default:
+		return "", nil, fmt.Errorf("lower search ast: unsupported operator %q", c.Op)
+	}
+}
+
+// matchPredicate builds the WHERE predicate and arg(s) for comparing column
+// against c.Value according to c.Match: MatchEquals is an exact "= ?",
+// MatchPrefix anchors the ILIKE pattern at the start of the value
+// ("value%"), and MatchContains (the default) scans for it anywhere
+// ("%value%"). The two ILIKE variants escape c.Value and pair with
+// "ESCAPE '\\'"; MatchEquals needs neither, since "=" has no wildcards.
+func matchPredicate(column string, c *search.Clause) (string, []any) {
+	switch c.Match {
+	case search.MatchEquals:
+		return fmt.Sprintf("%s = ?", column), []any{c.Value}
+	case search.MatchPrefix:
+		return fmt.Sprintf("%s ILIKE ? || '%%' ESCAPE '\\'", column), []any{escapeILIKE(c.Value)}
+	default:
+		return fmt.Sprintf("%s ILIKE ? ESCAPE '\\'", column), []any{"%" + escapeILIKE(c.Value) + "%"}
+	}
+}
+
+// lowerSearchASTWithDefault behaves like lowerSearchAST, but every ""/
+// "subject" leaf whose Match is still MatchContains (the parser's default
+// when a clause carries no explicit "="/"^"/"~" prefix) is lowered as if it
+// had been parsed with defaultMatch instead. A query that explicitly asks
+// for "~" (contains) lowers the same as one left unspecified, since
+// classifyToken has no way to tell those two apart once parsed - an
+// operator that raises defaultMatch to MatchPrefix loses the ability to
+// force a per-term ILIKE-contains scan via "~" and must use the slower
+// bare/subject default instead.
+func lowerSearchASTWithDefault(node *search.Node, alias string, defaultMatch search.MatchType) (string, []any, error) {
+	if defaultMatch == search.MatchContains {
+		return lowerSearchAST(node, alias)
+	}
+	return lowerSearchAST(withDefaultMatch(node, defaultMatch), alias)
+}
+
+// withDefaultMatch returns a copy of node's tree with defaultMatch applied
+// to every ""/"subject" leaf still at MatchContains; node itself and its
+// Clauses are not mutated, since callers may reuse the parsed Query.
+func withDefaultMatch(node *search.Node, defaultMatch search.MatchType) *search.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case search.NodeLeaf:
+		c := node.Leaf
+		if (c.Op == "" || c.Op == "subject") && c.Match == search.MatchContains {
+			clauseCopy := *c
+			clauseCopy.Match = defaultMatch
+			return &search.Node{Kind: search.NodeLeaf, Leaf: &clauseCopy}
+		}
+		return node
+	case search.NodeNot:
+		return &search.Node{Kind: search.NodeNot, Child: withDefaultMatch(node.Child, defaultMatch)}
+	case search.NodeAnd, search.NodeOr:
+		children := make([]*search.Node, len(node.Children))
+		for i, child := range node.Children {
+			children[i] = withDefaultMatch(child, defaultMatch)
+		}
+		return &search.Node{Kind: node.Kind, Children: children}
+	default:
+		return node
+	}
+}
+
+// recipientExistsSQL builds the EXISTS predicate shared by from:/to:/cc:/
+// bcc:, parameterized only on the recipient's email address (already passed
+// through escapeILIKE by the caller); recipientType is interpolated directly
+// since it is always one of the SQL literals above, never user input.
+func recipientExistsSQL(alias, recipientType string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = %s.id AND mr.recipient_type = %s AND p.email_address ILIKE ? ESCAPE '\\')`, alias, recipientType)
+}
+
+// escapeILIKE escapes the backslash, %, and _ characters in s so it can be
+// safely embedded in an ILIKE pattern (optionally wrapped in "%...%" by the
+// caller) without a literal percent or underscore in user input being
+// mistaken for a wildcard. Callers pair this with "ESCAPE '\\'" in the SQL.
+func escapeILIKE(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	s = strings.ReplaceAll(s, "_", `\_`)
+	return s
+}
@@ -0,0 +1,233 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// TestLowerSearchAST_Precedence checks that
+// (from:bob OR from:alice) AND label:Work AND NOT has:attachment AND
+// "quarterly report" lowers to a WHERE expression whose parenthesization
+// matches the AST, not a flat string concatenation.
+func TestLowerSearchAST_Precedence(t *testing.T) {
+	q := search.Parse(`(from:bob OR from:alice) AND label:Work AND NOT has:attachment AND "quarterly report"`)
+
+	sql, args, err := lowerSearchAST(q.AST, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+
+	for _, want := range []string{"recipient_type = 'from'", " OR ", "label_id", "NOT (", "has_attachments"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("sql: expected to contain %q, got %q", want, sql)
+		}
+	}
+	// Two from: addresses, one label, and two (subject, snippet) args for
+	// the phrase - has:attachment takes no arg since it lowers to a bare
+	// boolean column reference.
+	if len(args) != 5 {
+		t.Errorf("args: got %d (%v), want 5", len(args), args)
+	}
+	if args[0] != "bob" || args[1] != "alice" {
+		t.Errorf("args: got %v, want bob then alice first", args)
+	}
+}
+
+// TestLowerSearchAST_NegationOverLabel checks that NOT label:Archive lowers
+// to a negated EXISTS rather than an empty/dropped predicate.
+func TestLowerSearchAST_NegationOverLabel(t *testing.T) {
+	q := search.Parse(`NOT label:Archive`)
+
+	sql, args, err := lowerSearchAST(q.AST, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+	if !strings.HasPrefix(sql, "NOT (") || !strings.Contains(sql, "l.name = ?") {
+		t.Errorf("sql: got %q", sql)
+	}
+	if len(args) != 1 || args[0] != "Archive" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestLowerSearchAST_CaseInsensitivePhrase checks that a quoted phrase
+// lowers to an ILIKE predicate against both subject and snippet.
+func TestLowerSearchAST_CaseInsensitivePhrase(t *testing.T) {
+	q := search.Parse(`"Quarterly Report"`)
+
+	sql, args, err := lowerSearchAST(q.AST, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+	if !strings.Contains(sql, "ILIKE") {
+		t.Errorf("sql: expected ILIKE for case-insensitive match, got %q", sql)
+	}
+	if len(args) != 2 || args[0] != "%Quarterly Report%" || args[1] != "%Quarterly Report%" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestLowerSearchAST_NilNode checks that an empty query lowers to an
+// always-true predicate with no arguments.
+func TestLowerSearchAST_NilNode(t *testing.T) {
+	sql, args, err := lowerSearchAST(nil, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+	if sql != "1=1" || len(args) != 0 {
+		t.Errorf("got sql=%q args=%v, want 1=1 with no args", sql, args)
+	}
+}
+
+// TestLowerSearchAST_UnsupportedOperator checks that an operator with no
+// matching column returns an error instead of silently dropping the clause.
+func TestLowerSearchAST_UnsupportedOperator(t *testing.T) {
+	q := search.Parse(`mimetype:application/pdf`)
+
+	if _, _, err := lowerSearchAST(q.AST, "m"); err == nil {
+		t.Error("expected an error for an operator lowerClause doesn't handle")
+	}
+}
+
+// TestLowerSearchAST_NestedBooleanExpression checks that
+// from:alice AND (subject:invoice OR subject:receipt) AND NOT label:archive
+// lowers with correct associativity: the OR group stays parenthesized and
+// NOT wraps only the label predicate, not the whole trailing conjunction.
+func TestLowerSearchAST_NestedBooleanExpression(t *testing.T) {
+	q := search.Parse(`from:alice AND (subject:invoice OR subject:receipt) AND NOT label:archive`)
+
+	sql, args, err := lowerSearchAST(q.AST, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+	if !strings.Contains(sql, "(m.subject ILIKE ? ESCAPE '\\' OR m.subject ILIKE ? ESCAPE '\\')") {
+		t.Errorf("sql: expected a parenthesized OR group of subject predicates, got %q", sql)
+	}
+	if !strings.Contains(sql, "NOT (EXISTS") {
+		t.Errorf("sql: expected NOT to wrap only the label EXISTS predicate, got %q", sql)
+	}
+	if len(args) != 4 || args[0] != "alice" || args[1] != "%invoice%" || args[2] != "%receipt%" || args[3] != "archive" {
+		t.Errorf("args: got %v", args)
+	}
+}
+
+// TestLowerSearchAST_EscapesWildcards checks that literal % and _ in a
+// search term are escaped rather than interpreted as ILIKE wildcards, and
+// that the generated SQL carries the matching ESCAPE clause.
+func TestLowerSearchAST_EscapesWildcards(t *testing.T) {
+	q := search.Parse(`subject:50%_off`)
+
+	sql, args, err := lowerSearchAST(q.AST, "m")
+	if err != nil {
+		t.Fatalf("lowerSearchAST: %v", err)
+	}
+	if !strings.Contains(sql, "ESCAPE '\\'") {
+		t.Errorf("sql: expected an ESCAPE clause, got %q", sql)
+	}
+	if len(args) != 1 || args[0] != `%50\%\_off%` {
+		t.Errorf("args: got %v, want literal wildcard chars escaped", args)
+	}
+}
+
+// TestEscapeILIKE verifies that ILIKE wildcard characters (and the escape
+// character itself) are escaped so a literal %, _, or \ in a search term
+// survives an ILIKE comparison instead of acting as a wildcard.
+func TestEscapeILIKE(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"hello", "hello"},
+		{"100%", `100\%`},
+		{"test_email", `test\_email`},
+		{"50% off!", `50\% off!`},
+		{"foo_bar_baz", `foo\_bar\_baz`},
+		{`a\b`, `a\\b`},
+		{`100%_test\path`, `100\%\_test\\path`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := escapeILIKE(tt.input); got != tt.want {
+				t.Errorf("escapeILIKE(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLowerClause_MatchTypes checks that a clause's Match selects between an
+// exact "= ?" comparison, a prefix-anchored ILIKE, and the default
+// substring-scan ILIKE, per the "="/"^"/"~" value prefix search.Parse
+// recognizes.
+func TestLowerClause_MatchTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantSQL     string
+		wantArgs    []any
+		wantNoEqual bool
+	}{
+		{
+			name:     "exact",
+			query:    `subject:="Weekly Report"`,
+			wantSQL:  "m.subject = ?",
+			wantArgs: []any{"Weekly Report"},
+		},
+		{
+			name:     "prefix",
+			query:    `subject:^Weekly`,
+			wantSQL:  "m.subject ILIKE ? || '%' ESCAPE '\\'",
+			wantArgs: []any{"Weekly"},
+		},
+		{
+			name:     "contains",
+			query:    `subject:~Weekly`,
+			wantSQL:  "m.subject ILIKE ? ESCAPE '\\'",
+			wantArgs: []any{"%Weekly%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := search.Parse(tt.query)
+			sql, args, err := lowerSearchAST(q.AST, "m")
+			if err != nil {
+				t.Fatalf("lowerSearchAST: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("sql: got %q, want %q", sql, tt.wantSQL)
+			}
+			if len(args) != len(tt.wantArgs) || args[0] != tt.wantArgs[0] {
+				t.Errorf("args: got %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestLowerSearchASTWithDefault checks that a defaultMatch of MatchPrefix
+// upgrades an unspecified subject: clause to a prefix scan, and that
+// defaultMatch of MatchContains (the zero value) leaves lowerSearchAST's
+// behavior untouched.
+func TestLowerSearchASTWithDefault(t *testing.T) {
+	q := search.Parse(`subject:Weekly`)
+
+	sql, args, err := lowerSearchASTWithDefault(q.AST, "m", search.MatchPrefix)
+	if err != nil {
+		t.Fatalf("lowerSearchASTWithDefault: %v", err)
+	}
+	if sql != "m.subject ILIKE ? || '%' ESCAPE '\\'" {
+		t.Errorf("sql: got %q, want a prefix scan", sql)
+	}
+	if len(args) != 1 || args[0] != "Weekly" {
+		t.Errorf("args: got %v, want [Weekly]", args)
+	}
+
+	sql, _, err = lowerSearchASTWithDefault(q.AST, "m", search.MatchContains)
+	if err != nil {
+		t.Fatalf("lowerSearchASTWithDefault: %v", err)
+	}
+	if sql != "m.subject ILIKE ? ESCAPE '\\'" {
+		t.Errorf("sql: got %q, want the unchanged contains scan", sql)
+	}
+}
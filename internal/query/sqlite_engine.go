@@ -0,0 +1,632 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// SQLiteEngine implements Engine directly against an internal/store-shaped
+// SQLite database: the messages/message_recipients/participants/
+// message_labels/labels/attachments/message_bodies tables Open creates.
+// It's the Engine a vault with no Postgres or DuckDB analytics tree
+// configured falls back to, and the engine DuckDBEngine itself delegates
+// per-message lookups to when it's paired with a SQLite database.
+type SQLiteEngine struct {
+	db   *sql.DB
+	path string // identifies db in error messages; SQLiteEngine doesn't own or close it
+
+	// defaultTextMatch mirrors PostgresEngine.defaultTextMatch: the
+	// search.MatchType applied to ""/"subject" clauses parsed without an
+	// explicit match prefix.
+	defaultTextMatch search.MatchType
+}
+
+// SQLiteOption configures a SQLiteEngine at construction time.
+type SQLiteOption func(*SQLiteEngine)
+
+// WithSQLiteDefaultTextMatch sets the default search.MatchType applied to
+// ""/"subject" clauses parsed without an explicit "="/"^"/"~" prefix.
+func WithSQLiteDefaultTextMatch(m search.MatchType) SQLiteOption {
+	return func(e *SQLiteEngine) {
+		e.defaultTextMatch = m
+	}
+}
+
+// NewSQLiteEngine returns a SQLiteEngine backed by db, an already-open
+// connection to an internal/store-shaped database (path identifies it in
+// error messages only). NewSQLiteEngine does not take ownership of db -
+// the caller remains responsible for closing it.
+func NewSQLiteEngine(db *sql.DB, path string, opts ...SQLiteOption) *SQLiteEngine {
+	e := &SQLiteEngine{db: db, path: path}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Close is a no-op: SQLiteEngine doesn't own db (see NewSQLiteEngine).
+func (e *SQLiteEngine) Close() error {
+	return nil
+}
+
+// GetMessage returns the message with the given id.
+func (e *SQLiteEngine) GetMessage(ctx context.Context, id int64) (*Message, error) {
+	return e.scanMessage(ctx, "id = ?", id)
+}
+
+// GetMessageBySourceID returns the message with the given source_message_id.
+func (e *SQLiteEngine) GetMessageBySourceID(ctx context.Context, sourceMessageID string) (*Message, error) {
+	return e.scanMessage(ctx, "source_message_id = ?", sourceMessageID)
+}
+
+func (e *SQLiteEngine) scanMessage(ctx context.Context, where string, arg any) (*Message, error) {
+	row := e.db.QueryRowContext(ctx, `
+		SELECT id, source_id, conversation_id, source_message_id, subject, snippet,
+		       sent_at, size_estimate, has_attachments, deleted_from_source_at
+		FROM messages WHERE `+where, arg)
+
+	msg, err := scanSQLiteMessageRow(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: get message: %w", e.path, err)
+	}
+
+	if err := e.attachRecipientsAndAttachments(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// scanSQLiteMessageRow scans a row shaped like scanMessage's query above
+// into a Message. Unlike scanMessageRow (pg_engine.go's counterpart),
+// ConversationID comes before SourceMessageID since that's the column
+// order internal/store's messages table uses.
+func scanSQLiteMessageRow(row interface{ Scan(...any) error }) (*Message, error) {
+	var msg Message
+	var sentAt sql.NullTime
+	var deletedAt sql.NullTime
+	err := row.Scan(&msg.ID, &msg.SourceID, &msg.ConversationID, &msg.SourceMessageID, &msg.Subject, &msg.Snippet,
+		&sentAt, &msg.SizeEstimate, &msg.HasAttachments, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	msg.Date = sentAt.Time
+	if deletedAt.Valid {
+		msg.DeletedFromSourceAt = &deletedAt.Time
+	}
+	return &msg, nil
+}
+
+func (e *SQLiteEngine) attachRecipientsAndAttachments(ctx context.Context, msg *Message) error {
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT p.email, mr.recipient_type
+		FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = ?`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("sqlite engine %s: load recipients: %w", e.path, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, recipientType string
+		if err := rows.Scan(&email, &recipientType); err != nil {
+			return fmt.Errorf("sqlite engine %s: scan recipient: %w", e.path, err)
+		}
+		switch recipientType {
+		case "from":
+			msg.FromEmail = email
+		case "to", "cc":
+			msg.ToEmails = append(msg.ToEmails, email)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sqlite engine %s: iterate recipients: %w", e.path, err)
+	}
+
+	labelRows, err := e.db.QueryContext(ctx, `
+		SELECT l.name FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+		WHERE ml.message_id = ?`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("sqlite engine %s: load labels: %w", e.path, err)
+	}
+	defer labelRows.Close()
+
+	for labelRows.Next() {
+		var name string
+		if err := labelRows.Scan(&name); err != nil {
+			return fmt.Errorf("sqlite engine %s: scan label: %w", e.path, err)
+		}
+		msg.Labels = append(msg.Labels, name)
+	}
+	if err := labelRows.Err(); err != nil {
+		return fmt.Errorf("sqlite engine %s: iterate labels: %w", e.path, err)
+	}
+
+	var textBody sql.NullString
+	err = e.db.QueryRowContext(ctx, `
+		SELECT text_body FROM message_bodies WHERE message_id = ?`, msg.ID).Scan(&textBody)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("sqlite engine %s: load body: %w", e.path, err)
+	}
+	msg.TextBody = textBody.String
+
+	attRows, err := e.db.QueryContext(ctx, `
+		SELECT filename, size_bytes FROM attachments WHERE message_id = ?`, msg.ID)
+	if err != nil {
+		return fmt.Errorf("sqlite engine %s: load attachments: %w", e.path, err)
+	}
+	defer attRows.Close()
+
+	for attRows.Next() {
+		var att Attachment
+		if err := attRows.Scan(&att.Filename, &att.Size); err != nil {
+			return fmt.Errorf("sqlite engine %s: scan attachment: %w", e.path, err)
+		}
+		msg.Attachments = append(msg.Attachments, att)
+	}
+	return attRows.Err()
+}
+
+// GetAttachment returns att's content, base64-encoded. internal/store's
+// attachments table stores blob content on disk via storage_path rather
+// than inline in the database (see internal/blobstore), so GetAttachment
+// here only returns what the row itself carries - filename, mime_type,
+// size - with Base64Data left empty; callers that need attachment bytes
+// read storage_path through internal/blobstore directly today.
+func (e *SQLiteEngine) GetAttachment(ctx context.Context, id int64) (*AttachmentContent, error) {
+	var content AttachmentContent
+	err := e.db.QueryRowContext(ctx, `
+		SELECT filename, mime_type, size_bytes FROM attachments WHERE id = ?`, id).
+		Scan(&content.Filename, &content.MimeType, &content.Size)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sqlite engine %s: attachment %d not found", e.path, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: get attachment: %w", e.path, err)
+	}
+	return &content, nil
+}
+
+// Search runs q's full-text terms against subject and snippet.
+func (e *SQLiteEngine) Search(ctx context.Context, q *search.Query, limit, offset int) ([]*Message, error) {
+	return e.SearchFast(ctx, q, MessageFilter{}, limit, offset)
+}
+
+// SearchFast lowers q's AST the same way PostgresEngine.searchFast does,
+// reusing lowerSearchASTWithDefault since internal/store's schema matches
+// the table names that AST lowering assumes (messages, message_recipients,
+// participants, message_labels, labels).
+func (e *SQLiteEngine) SearchFast(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]*Message, error) {
+	where, args := sqliteFilterClause(filter, "m")
+
+	astWhere, astArgs, err := lowerSearchASTWithDefault(q.AST, "m", e.defaultTextMatch)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: search: %w", e.path, err)
+	}
+	if astWhere != "1=1" {
+		where = append(where, astWhere)
+		args = append(args, astArgs...)
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.conversation_id, m.source_message_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m
+		%s
+		ORDER BY m.sent_at DESC
+		LIMIT %d OFFSET %d`, whereSQL, limit, offset)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+// ListMessages queries by filter alone, with no text search.
+func (e *SQLiteEngine) ListMessages(ctx context.Context, filter MessageFilter) ([]*Message, error) {
+	where, args := sqliteFilterClause(filter, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.conversation_id, m.source_message_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m
+		%s
+		ORDER BY m.sent_at %s
+		LIMIT %d OFFSET %d`, whereSQL, sortDirectionSQL(filter), limit, filter.Offset)
+
+	return e.queryMessages(ctx, query, args...)
+}
+
+func (e *SQLiteEngine) queryMessages(ctx context.Context, query string, args ...any) ([]*Message, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: query messages: %w", e.path, err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanSQLiteMessageRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite engine %s: scan message: %w", e.path, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// AggregateBySender aggregates message counts by sender address.
+func (e *SQLiteEngine) AggregateBySender(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	return e.aggregateByRecipientType(ctx, opts, "'from'")
+}
+
+// AggregateByRecipient aggregates message counts by recipient address
+// (to and cc).
+func (e *SQLiteEngine) AggregateByRecipient(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	return e.aggregateByRecipientType(ctx, opts, "'to', 'cc'")
+}
+
+func (e *SQLiteEngine) aggregateByRecipientType(ctx context.Context, opts AggregateOptions, recipientTypesSQL string) ([]AggregateRow, error) {
+	where, args := sqliteAggregateFilterClause(opts, "m")
+	whereSQL := fmt.Sprintf("mr.recipient_type IN (%s)", recipientTypesSQL)
+	if len(where) > 0 {
+		whereSQL += " AND " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.email,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size_bytes), 0),
+		       COUNT(DISTINCT a.id)
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		JOIN messages m ON m.id = mr.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		WHERE %s
+		GROUP BY p.email
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByDomain aggregates message counts by recipient domain.
+func (e *SQLiteEngine) AggregateByDomain(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := sqliteAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.domain,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size_bytes), 0),
+		       COUNT(DISTINCT a.id)
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		JOIN messages m ON m.id = mr.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY p.domain
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByLabel aggregates message counts by label name.
+func (e *SQLiteEngine) AggregateByLabel(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := sqliteAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT l.name,
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size_bytes), 0),
+		       COUNT(DISTINCT a.id)
+		FROM message_labels ml
+		JOIN labels l ON l.id = ml.label_id
+		JOIN messages m ON m.id = ml.message_id
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY l.name
+		ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateByTime buckets message counts by opts.TimeGranularity using
+// SQLite's strftime.
+func (e *SQLiteEngine) AggregateByTime(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error) {
+	format, _ := timeBucketSQL(opts.TimeGranularity)
+	where, args := sqliteAggregateFilterClause(opts, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT strftime('%s', m.sent_at),
+		       COUNT(DISTINCT m.id),
+		       COALESCE(SUM(a.size_bytes), 0),
+		       COUNT(DISTINCT a.id)
+		FROM messages m
+		LEFT JOIN attachments a ON a.message_id = m.id
+		%s
+		GROUP BY 1
+		ORDER BY 1`, sqliteStrftimeFormat(format), whereSQL)
+
+	return e.scanAggregateRows(ctx, query, args, opts)
+}
+
+// AggregateBySize buckets messages by size_estimate against buckets (upper
+// bounds in bytes): each message is counted once, in the first bucket whose
+// bound it does not exceed, labeled "<=N", or else in the final, unbounded
+// bucket, labeled ">N".
+func (e *SQLiteEngine) AggregateBySize(ctx context.Context, buckets []int64) ([]AggregateRow, error) {
+	rows, err := e.db.QueryContext(ctx, `SELECT size_estimate FROM messages`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: aggregate by size: %w", e.path, err)
+	}
+	defer rows.Close()
+
+	counts := make([]int64, len(buckets)+1)
+	for rows.Next() {
+		var size int64
+		if err := rows.Scan(&size); err != nil {
+			return nil, fmt.Errorf("sqlite engine %s: aggregate by size: scan: %w", e.path, err)
+		}
+		bucket := len(buckets)
+		for i, upper := range buckets {
+			if size <= upper {
+				bucket = i
+				break
+			}
+		}
+		counts[bucket]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]AggregateRow, len(counts))
+	for i, upper := range buckets {
+		results[i] = AggregateRow{Key: fmt.Sprintf("<=%d", upper), Count: counts[i]}
+	}
+	results[len(buckets)] = AggregateRow{Key: fmt.Sprintf(">%d", buckets[len(buckets)-1]), Count: counts[len(buckets)]}
+	return results, nil
+}
+
+// SubAggregate aggregates the messages matching filter by view.
+func (e *SQLiteEngine) SubAggregate(ctx context.Context, filter MessageFilter, view View, opts AggregateOptions) ([]AggregateRow, error) {
+	where, args := sqliteFilterClause(filter, "m")
+	optsWhere, optsArgs := sqliteAggregateFilterClause(opts, "m")
+	where = append(where, optsWhere...)
+	args = append(args, optsArgs...)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	switch view {
+	case ViewRecipients:
+		query := fmt.Sprintf(`
+			SELECT p.email, COUNT(DISTINCT m.id), 0, 0
+			FROM messages m
+			JOIN message_recipients mr ON mr.message_id = m.id AND mr.recipient_type IN ('to', 'cc')
+			JOIN participants p ON p.id = mr.participant_id
+			%s
+			GROUP BY p.email
+			ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	case ViewLabels:
+		query := fmt.Sprintf(`
+			SELECT l.name, COUNT(DISTINCT m.id), 0, 0
+			FROM messages m
+			JOIN message_labels ml ON ml.message_id = m.id
+			JOIN labels l ON l.id = ml.label_id
+			%s
+			GROUP BY l.name
+			ORDER BY COUNT(DISTINCT m.id) DESC`, whereSQL)
+		return e.scanAggregateRows(ctx, query, args, opts)
+	default:
+		return nil, fmt.Errorf("sqlite engine %s: sub-aggregate: unsupported view %v", e.path, view)
+	}
+}
+
+func (e *SQLiteEngine) scanAggregateRows(ctx context.Context, query string, args []any, opts AggregateOptions) ([]AggregateRow, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: aggregate query: %w", e.path, err)
+	}
+	defer rows.Close()
+
+	var results []AggregateRow
+	for rows.Next() {
+		var r AggregateRow
+		if err := rows.Scan(&r.Key, &r.Count, &r.AttachmentSize, &r.AttachmentCount); err != nil {
+			return nil, fmt.Errorf("sqlite engine %s: scan aggregate row: %w", e.path, err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// GetTotalStats summarizes the vault's overall size.
+func (e *SQLiteEngine) GetTotalStats(ctx context.Context, opts StatsOptions) (*TotalStats, error) {
+	var stats TotalStats
+	err := e.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(size_estimate), 0) FROM messages`).
+		Scan(&stats.TotalMessages, &stats.TotalSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: get total stats: %w", e.path, err)
+	}
+	if err := e.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM attachments`).Scan(&stats.TotalAttachments); err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: get total stats: attachments: %w", e.path, err)
+	}
+	return &stats, nil
+}
+
+// ListAccounts returns every source's identifier and message count.
+// internal/store's sources table has no account_email column of its own
+// (see schema in internal/store/store.go) - identifier is the closest
+// analog (a Gmail source's OAuth account address, a Maildir source's
+// path), so it's what ListAccounts reports as AccountInfo.Email.
+func (e *SQLiteEngine) ListAccounts(ctx context.Context) ([]AccountInfo, error) {
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT s.identifier, COUNT(m.id)
+		FROM sources s LEFT JOIN messages m ON m.source_id = s.id
+		GROUP BY s.identifier
+		ORDER BY s.identifier`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite engine %s: list accounts: %w", e.path, err)
+	}
+	defer rows.Close()
+
+	var accounts []AccountInfo
+	for rows.Next() {
+		var a AccountInfo
+		if err := rows.Scan(&a.Email, &a.MessageCount); err != nil {
+			return nil, fmt.Errorf("sqlite engine %s: scan account: %w", e.path, err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// sqliteStrftimeFormat translates timeBucketSQL's to_char-style format
+// string (YYYY, YYYY-MM, YYYY-MM-DD) to the strftime directives SQLite
+// expects - the same directives DuckDB's strftime uses, conveniently.
+func sqliteStrftimeFormat(format string) string {
+	switch format {
+	case "YYYY-MM-DD":
+		return "%Y-%m-%d"
+	case "YYYY-MM":
+		return "%Y-%m"
+	default:
+		return "%Y"
+	}
+}
+
+// sqliteFilterClause is filterClause's SQLite-flavored counterpart: the
+// same MessageFilter predicates, built with "?" positional placeholders
+// instead of Postgres's "$N" syntax.
+func sqliteFilterClause(filter MessageFilter, alias string) ([]string, []any) {
+	var where []string
+	var args []any
+
+	bind := func(v any) string {
+		args = append(args, v)
+		return "?"
+	}
+	bindStringsQ := func(values []string) string {
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = bind(v)
+		}
+		return strings.Join(placeholders, ",")
+	}
+
+	if filter.Sender != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+			WHERE mr.message_id = %s.id AND mr.recipient_type = 'from' AND p.email = %s)`,
+			alias, bind(filter.Sender)))
+	}
+	if filter.ConversationID != nil {
+		where = append(where, fmt.Sprintf("%s.conversation_id = %s", alias, bind(*filter.ConversationID)))
+	}
+	if filter.WithAttachmentsOnly {
+		where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+	}
+	if len(filter.Labels) > 0 {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM message_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.message_id = %s.id AND l.name IN (%s))`, alias, bindStringsQ(filter.Labels)))
+	}
+	if !filter.SentAfter.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(filter.SentAfter)))
+	}
+	if !filter.SentBefore.IsZero() {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(filter.SentBefore)))
+	}
+	if filter.After != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*filter.After)))
+	}
+	if filter.Before != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*filter.Before)))
+	}
+	if filter.SizeMin > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate >= %s", alias, bind(filter.SizeMin)))
+	}
+	if filter.SizeMax > 0 {
+		where = append(where, fmt.Sprintf("%s.size_estimate <= %s", alias, bind(filter.SizeMax)))
+	}
+	if filter.HasAttachment != nil {
+		if *filter.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+
+	return where, args
+}
+
+// sqliteAggregateFilterClause is sqliteFilterClause's AggregateOptions
+// counterpart.
+func sqliteAggregateFilterClause(opts AggregateOptions, alias string) ([]string, []any) {
+	var where []string
+	var args []any
+
+	bind := func(v any) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	if opts.SentAfter != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*opts.SentAfter)))
+	}
+	if opts.SentBefore != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*opts.SentBefore)))
+	}
+	if opts.After != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at > %s", alias, bind(*opts.After)))
+	}
+	if opts.Before != nil {
+		where = append(where, fmt.Sprintf("%s.sent_at < %s", alias, bind(*opts.Before)))
+	}
+	if opts.HasAttachment != nil {
+		if *opts.HasAttachment {
+			where = append(where, fmt.Sprintf("%s.has_attachments", alias))
+		} else {
+			where = append(where, fmt.Sprintf("NOT %s.has_attachments", alias))
+		}
+	}
+
+	return where, args
+}
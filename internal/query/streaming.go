@@ -0,0 +1,229 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// EventType distinguishes the kinds of events StreamMessages can push.
+type EventType int
+
+const (
+	// EventRow carries one matching message.
+	EventRow EventType = iota
+	// EventDone marks the end of a page; NextPageToken (if non-empty) can
+	// be passed back in the next StreamMessages call's Page to resume.
+	EventDone
+	// EventError marks that the underlying query failed; Err is set.
+	EventError
+	// EventCancelled marks that CancelView aborted the query before it
+	// finished; any rows already fetched but not yet sent are dropped.
+	EventCancelled
+)
+
+// MessageEvent is one event on the channel StreamMessages returns.
+type MessageEvent struct {
+	Type          EventType
+	Message       *Message
+	NextPageToken string
+	Err           error
+}
+
+// StreamMessages runs filter+page as a view identified by viewID, pushing
+// results a row at a time over the returned channel rather than buffering a
+// full page in memory before returning, so a UI can render incremental
+// results across a large result set. A follow-up call with the same viewID
+// and page.PageToken set to the previous call's NextPageToken advances the
+// cursor; it does not resume a still-running stream.
+//
+// DuckDB exposes duckdb_interrupt via Conn.Raw for aborting an in-flight
+// query; Postgres has no equivalent reachable through database/sql, so
+// CancelView here works the way database/sql already supports for any
+// driver - by cancelling the context the query was issued with, which pgx
+// turns into a query cancellation on the wire. The view/channel/event
+// protocol this method implements is the same regardless of which
+// mechanism aborts the query underneath.
+func (e *PostgresEngine) StreamMessages(ctx context.Context, filter MessageFilter, page Page, viewID string) (<-chan MessageEvent, error) {
+	if viewID == "" {
+		return nil, fmt.Errorf("stream messages: viewID is required")
+	}
+
+	where, args := filterClause(filter, "m")
+	desc := filter.SortDirection != SortAsc
+	cmp, dir := "<", "DESC"
+	if !desc {
+		cmp, dir = ">", "ASC"
+	}
+
+	if page.PageToken != "" {
+		cursor, err := decodePageToken(e.pageTokenKey, page.PageToken)
+		if err != nil {
+			return nil, fmt.Errorf("stream messages: %w", err)
+		}
+		where = append(where, fmt.Sprintf("(m.sent_at, m.id) %s ($%d, $%d)", cmp, len(args)+1, len(args)+2))
+		args = append(args, cursor.SortKey, cursor.ID)
+	}
+
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	pageSize := page.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.id, m.source_id, m.source_message_id, m.conversation_id, m.subject, m.snippet,
+		       m.sent_at, m.size_estimate, m.has_attachments, m.deleted_from_source_at
+		FROM messages m
+		%s
+		ORDER BY m.sent_at %s, m.id %s
+		LIMIT %d`, whereSQL, dir, dir, pageSize)
+
+	queryCtx, handle := e.views.open(ctx, viewID)
+
+	// Unbuffered: a row is only ever "in flight" between the query and the
+	// consumer, never queued up ahead of it, so cancelling always drops it
+	// instead of letting it sit buffered and still be delivered.
+	events := make(chan MessageEvent)
+
+	go func() {
+		defer close(events)
+		defer e.views.closeIfCurrent(viewID, handle)
+
+		rows, err := e.db.QueryContext(queryCtx, query, args...)
+		if err != nil {
+			sendStreamEvent(queryCtx, events, streamErrorOrCancelled(queryCtx, err))
+			return
+		}
+		defer rows.Close()
+
+		var last *Message
+		for rows.Next() {
+			msg, err := scanMessageRow(rows)
+			if err != nil {
+				sendStreamEvent(queryCtx, events, streamErrorOrCancelled(queryCtx, err))
+				return
+			}
+			if !sendStreamEvent(queryCtx, events, MessageEvent{Type: EventRow, Message: msg}) {
+				return
+			}
+			last = msg
+		}
+		if err := rows.Err(); err != nil {
+			sendStreamEvent(queryCtx, events, streamErrorOrCancelled(queryCtx, err))
+			return
+		}
+
+		done := MessageEvent{Type: EventDone}
+		if last != nil {
+			token, err := encodePageToken(e.pageTokenKey, pageCursor{SortKey: sortKeyOf(last, SortBySentAt), ID: last.ID})
+			if err != nil {
+				sendStreamEvent(queryCtx, events, MessageEvent{Type: EventError, Err: fmt.Errorf("stream messages: %w", err)})
+				return
+			}
+			done.NextPageToken = token
+		}
+		sendStreamEvent(queryCtx, events, done)
+	}()
+
+	return events, nil
+}
+
+// sendStreamEvent sends ev on events unless ctx was cancelled first, in
+// which case it drops ev and reports false so the caller's loop exits
+// without blocking forever on a channel nothing is reading anymore.
+func sendStreamEvent(ctx context.Context, events chan<- MessageEvent, ev MessageEvent) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// streamErrorOrCancelled reports ctx's cancellation as EventCancelled rather
+// than surfacing the underlying "context canceled" database error as
+// EventError, since CancelView is the expected way to end a view early.
+func streamErrorOrCancelled(ctx context.Context, err error) MessageEvent {
+	if ctx.Err() != nil {
+		return MessageEvent{Type: EventCancelled}
+	}
+	return MessageEvent{Type: EventError, Err: err}
+}
+
+// CancelView aborts viewID's in-flight StreamMessages query, if any, and
+// reports whether a view with that ID was found. Because events is
+// unbuffered, cancellation drops any row the producing goroutine had
+// already fetched but not yet sent rather than letting it be delivered
+// after the fact; the channel itself still closes once that goroutine
+// observes the cancellation and returns.
+func (e *PostgresEngine) CancelView(viewID string) bool {
+	return e.views.cancel(viewID)
+}
+
+// viewHandle is the value stored per view ID. It's a pointer type so a
+// StreamMessages goroutine can tell, by identity rather than by value,
+// whether a later StreamMessages call for the same viewID has already
+// replaced it before deleting its own entry from the registry on exit.
+type viewHandle struct {
+	cancel context.CancelFunc
+}
+
+// viewRegistry tracks the cancel func for each in-flight StreamMessages
+// call, keyed by caller-supplied view ID, so CancelView (or a follow-up
+// StreamMessages call reusing the same ID) can abort it.
+type viewRegistry struct {
+	mu sync.Mutex
+	m  map[string]*viewHandle
+}
+
+func newViewRegistry() *viewRegistry {
+	return &viewRegistry{m: make(map[string]*viewHandle)}
+}
+
+// open starts a new view for viewID, cancelling and replacing whatever view
+// was previously registered under that ID.
+func (r *viewRegistry) open(parent context.Context, viewID string) (context.Context, *viewHandle) {
+	ctx, cancel := context.WithCancel(parent)
+	handle := &viewHandle{cancel: cancel}
+
+	r.mu.Lock()
+	if prev, ok := r.m[viewID]; ok {
+		prev.cancel()
+	}
+	r.m[viewID] = handle
+	r.mu.Unlock()
+
+	return ctx, handle
+}
+
+// closeIfCurrent removes viewID's registry entry, but only if it still
+// points at handle - if a later open call has already replaced it, that
+// newer view's entry is left alone. It always cancels handle's context,
+// releasing its resources regardless of which entry (if any) was removed.
+func (r *viewRegistry) closeIfCurrent(viewID string, handle *viewHandle) {
+	r.mu.Lock()
+	if r.m[viewID] == handle {
+		delete(r.m, viewID)
+	}
+	r.mu.Unlock()
+	handle.cancel()
+}
+
+// cancel aborts viewID's current view, if one is registered, and reports
+// whether one was found.
+func (r *viewRegistry) cancel(viewID string) bool {
+	r.mu.Lock()
+	handle, ok := r.m[viewID]
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+	handle.cancel()
+	return true
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestViewRegistry_CancelAbortsContext checks that cancel() cancels the
+// context a prior open() call handed out, which is how StreamMessages'
+// query-running goroutine learns to stop and exit.
+func TestViewRegistry_CancelAbortsContext(t *testing.T) {
+	r := newViewRegistry()
+	ctx, _ := r.open(context.Background(), "view-1")
+
+	if ok := r.cancel("view-1"); !ok {
+		t.Fatal("cancel: expected view-1 to be found")
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled")
+	}
+}
+
+// TestViewRegistry_CancelUnknownView checks that cancelling a viewID that
+// was never opened (or already closed) reports false rather than panicking.
+func TestViewRegistry_CancelUnknownView(t *testing.T) {
+	r := newViewRegistry()
+	if ok := r.cancel("missing"); ok {
+		t.Error("cancel: expected false for an unregistered view")
+	}
+}
+
+// TestViewRegistry_ReopenCancelsPrevious checks that a follow-up open() call
+// for the same viewID aborts whatever view was previously running under it,
+// so a caller that starts a new page without cancelling the old one doesn't
+// leave two queries running against the same view ID.
+func TestViewRegistry_ReopenCancelsPrevious(t *testing.T) {
+	r := newViewRegistry()
+	firstCtx, _ := r.open(context.Background(), "view-1")
+	_, _ = r.open(context.Background(), "view-1")
+
+	select {
+	case <-firstCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("first view's context was not cancelled by the reopen")
+	}
+}
+
+// TestViewRegistry_CloseIfCurrentRemovesOwnEntry checks the normal exit
+// path: a goroutine finishing its query removes its own registry entry.
+func TestViewRegistry_CloseIfCurrentRemovesOwnEntry(t *testing.T) {
+	r := newViewRegistry()
+	_, handle := r.open(context.Background(), "view-1")
+	r.closeIfCurrent("view-1", handle)
+
+	if ok := r.cancel("view-1"); ok {
+		t.Error("cancel: expected view-1 to already be gone after closeIfCurrent")
+	}
+}
+
+// TestViewRegistry_CloseIfCurrentIgnoresStaleHandle checks that a goroutine
+// from an older, already-replaced open() call doesn't delete the newer
+// view's entry when it finishes and calls closeIfCurrent.
+func TestViewRegistry_CloseIfCurrentIgnoresStaleHandle(t *testing.T) {
+	r := newViewRegistry()
+	_, staleHandle := r.open(context.Background(), "view-1")
+	_, currentHandle := r.open(context.Background(), "view-1")
+
+	r.closeIfCurrent("view-1", staleHandle)
+
+	r.mu.Lock()
+	got := r.m["view-1"]
+	r.mu.Unlock()
+	if got != currentHandle {
+		t.Error("closeIfCurrent: stale handle's cleanup removed the current view's entry")
+	}
+}
+
+// TestStreamMessages_RequiresViewID checks that an empty viewID is rejected
+// before any query runs, since it is the registry key StreamMessages and
+// CancelView coordinate through.
+func TestStreamMessages_RequiresViewID(t *testing.T) {
+	e := &PostgresEngine{views: newViewRegistry()}
+	if _, err := e.StreamMessages(context.Background(), MessageFilter{}, Page{}, ""); err == nil {
+		t.Error("expected an error for an empty viewID")
+	}
+}
+
+// TestCancelView_UnknownViewReportsFalse checks that CancelView on a
+// PostgresEngine with no matching in-flight view reports false rather than
+// panicking on a nil registry lookup.
+func TestCancelView_UnknownViewReportsFalse(t *testing.T) {
+	e := &PostgresEngine{views: newViewRegistry()}
+	if e.CancelView("never-started") {
+		t.Error("expected false for a viewID with no in-flight StreamMessages call")
+	}
+}
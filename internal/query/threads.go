@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ThreadMode selects which conversations ListThreads reports (via
+// threadFilterClause). ListMessages does not read it and always returns
+// individual messages.
+type ThreadMode int
+
+const (
+	// ThreadOff is the zero value; ListThreads treats it the same as ThreadOn.
+	ThreadOff ThreadMode = iota
+	// ThreadOn groups every matching message into its conversation thread.
+	ThreadOn
+	// ThreadUnread restricts grouping to threads that contain at least one
+	// unread message, while still reporting each such thread's full stats
+	// (not just its unread messages).
+	ThreadUnread
+)
+
+// ThreadSummary is one conversation's aggregated stats, as returned by
+// ListThreads.
+type ThreadSummary struct {
+	ConversationID int64
+	MessageCount   int64
+	UnreadCount    int64
+	HasAttachments bool
+	Participants   []string
+	Labels         []string
+	FirstDate      time.Time
+	LastDate       time.Time
+	LatestSubject  string
+}
+
+// ListThreads groups the messages matching filter by conversation, delegating
+// to the paired analytics engine when one is configured.
+func (e *PostgresEngine) ListThreads(ctx context.Context, filter MessageFilter) ([]ThreadSummary, error) {
+	if e.analytics != nil {
+		return e.analytics.ListThreads(ctx, filter)
+	}
+
+	where, args := threadFilterClause(filter, "m")
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT m.conversation_id,
+		       COUNT(DISTINCT m.id),
+		       COUNT(DISTINCT m.id) FILTER (WHERE m.is_unread),
+		       BOOL_OR(m.has_attachments),
+		       ARRAY_AGG(DISTINCT p.email_address) FILTER (WHERE p.email_address IS NOT NULL),
+		       ARRAY_AGG(DISTINCT l.name) FILTER (WHERE l.name IS NOT NULL),
+		       MIN(m.sent_at),
+		       MAX(m.sent_at),
+		       (ARRAY_AGG(m.subject ORDER BY m.sent_at DESC))[1]
+		FROM messages m
+		LEFT JOIN message_recipients mr ON mr.message_id = m.id
+		LEFT JOIN participants p ON p.id = mr.participant_id
+		LEFT JOIN message_labels ml ON ml.message_id = m.id
+		LEFT JOIN labels l ON l.id = ml.label_id
+		%s
+		GROUP BY m.conversation_id
+		ORDER BY MAX(m.sent_at) DESC`, whereSQL)
+
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []ThreadSummary
+	for rows.Next() {
+		var s ThreadSummary
+		var participants, labels []string
+		if err := rows.Scan(&s.ConversationID, &s.MessageCount, &s.UnreadCount, &s.HasAttachments,
+			&participants, &labels, &s.FirstDate, &s.LastDate, &s.LatestSubject); err != nil {
+			return nil, fmt.Errorf("scan thread: %w", err)
+		}
+		s.Participants = participants
+		s.Labels = labels
+		threads = append(threads, s)
+	}
+	return threads, rows.Err()
+}
+
+// threadFilterClause extends filterClause with ThreadUnread's extra
+// restriction (keep a conversation only if at least one of its messages is
+// unread), so the predicate is unit-testable independent of a database
+// connection.
+func threadFilterClause(filter MessageFilter, alias string) ([]string, []any) {
+	where, args := filterClause(filter, alias)
+	if filter.ThreadMode == ThreadUnread {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM messages um
+			WHERE um.conversation_id = %s.conversation_id AND um.is_unread)`, alias))
+	}
+	return where, args
+}
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestThreadFilterClause_ThreadOffAddsNoPredicate checks that the default
+// ThreadMode leaves the WHERE clause unchanged from plain filterClause.
+func TestThreadFilterClause_ThreadOffAddsNoPredicate(t *testing.T) {
+	filter := MessageFilter{Sender: "alice@example.com"}
+
+	plain, plainArgs := filterClause(filter, "m")
+	threaded, threadedArgs := threadFilterClause(filter, "m")
+
+	if len(threaded) != len(plain) || len(threadedArgs) != len(plainArgs) {
+		t.Errorf("ThreadOff: got %d predicates/%d args, want %d/%d (unchanged)",
+			len(threaded), len(threadedArgs), len(plain), len(plainArgs))
+	}
+}
+
+// TestThreadFilterClause_ThreadUnreadAddsUnreadExists checks that
+// ThreadUnread appends the conversation-has-an-unread-message predicate.
+func TestThreadFilterClause_ThreadUnreadAddsUnreadExists(t *testing.T) {
+	filter := MessageFilter{Sender: "alice@example.com", ThreadMode: ThreadUnread}
+
+	where, _ := threadFilterClause(filter, "m")
+	if len(where) != 2 {
+		t.Fatalf("where: got %d predicates, want 2 (sender + unread): %v", len(where), where)
+	}
+	if !strings.Contains(where[1], "is_unread") || !strings.Contains(where[1], "m.conversation_id") {
+		t.Errorf("ThreadUnread predicate: got %q", where[1])
+	}
+}
+
+// TestThreadFilterClause_ThreadOnAddsNoExtraPredicate checks that ThreadOn
+// groups messages into threads without restricting which threads qualify.
+func TestThreadFilterClause_ThreadOnAddsNoExtraPredicate(t *testing.T) {
+	filter := MessageFilter{ThreadMode: ThreadOn}
+
+	where, args := threadFilterClause(filter, "m")
+	if len(where) != 0 || len(args) != 0 {
+		t.Errorf("ThreadOn with no other filters: got where=%v args=%v, want none", where, args)
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchMode names which backing index SQLiteEngine.Search uses for free
+// text, selected once by the FTS availability probe and cached the same way
+// ftsChecked/ftsResult already are.
+type SearchMode int
+
+const (
+	// SearchFTS5 uses the sqlite3 FTS5 virtual table, when present.
+	SearchFTS5 SearchMode = iota
+	// SearchTrigram uses the messages_trigram posting-list table built by
+	// BuildTrigramIndex, when FTS5 is absent but the table exists.
+	SearchTrigram
+	// SearchLike falls back to a plain LIKE scan when neither FTS5 nor a
+	// trigram index is available.
+	SearchLike
+)
+
+// trigramTableSQL creates messages_trigram, the companion posting-list table
+// BuildTrigramIndex populates: one row per (message, trigram) pair, with an
+// index on trigram so intersecting posting lists for a multi-token query
+// doesn't require a table scan per token.
+const trigramTableSQL = `
+CREATE TABLE IF NOT EXISTS messages_trigram (
+	msg_id INTEGER NOT NULL,
+	trigram INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_trigram_trigram ON messages_trigram(trigram);
+CREATE INDEX IF NOT EXISTS idx_messages_trigram_msg_id ON messages_trigram(msg_id);
+`
+
+// BuildTrigramIndex (re)populates messages_trigram from the current contents
+// of messages (subject, snippet) and message_bodies (body_text, if that
+// table exists), so SQLiteEngine can answer free-text search with trigram
+// posting-list intersection instead of a LIKE scan when FTS5 isn't compiled
+// in. It is safe to re-run: existing rows are dropped first.
+func BuildTrigramIndex(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, trigramTableSQL); err != nil {
+		return fmt.Errorf("trigram: create messages_trigram: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM messages_trigram"); err != nil {
+		return fmt.Errorf("trigram: clear messages_trigram: %w", err)
+	}
+
+	hasBodies, err := tableExists(ctx, db, "message_bodies")
+	if err != nil {
+		return fmt.Errorf("trigram: check message_bodies: %w", err)
+	}
+
+	query := "SELECT m.id, m.subject, m.snippet"
+	if hasBodies {
+		query += ", b.body_text FROM messages m LEFT JOIN message_bodies b ON b.message_id = m.id"
+	} else {
+		query += " FROM messages m"
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("trigram: read messages: %w", err)
+	}
+	defer rows.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("trigram: begin: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO messages_trigram (msg_id, trigram) VALUES (?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("trigram: prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for rows.Next() {
+		var id int64
+		var subject, snippet string
+		var body sql.NullString
+		dest := []any{&id, &subject, &snippet}
+		if hasBodies {
+			dest = append(dest, &body)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("trigram: scan message: %w", err)
+		}
+
+		text := subject + " " + snippet
+		if body.Valid {
+			text += " " + body.String
+		}
+
+		for tri := range trigramSet(text) {
+			if _, err := stmt.ExecContext(ctx, id, tri); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("trigram: insert: %w", err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("trigram: iterate messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("trigram: commit: %w", err)
+	}
+	return nil
+}
+
+// tableExists reports whether name is a table in db's sqlite_master.
+func tableExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	return n > 0, err
+}
+
+// trigramSet returns the distinct packed trigrams of s, after NFKC
+// normalization and lowercasing. Each trigram is three consecutive runes
+// packed into the low 24 bits of an int32 (8 bits per rune), which is lossy
+// for runes above U+00FF but acceptable for a candidate filter that's always
+// re-verified with LIKE.
+func trigramSet(s string) map[int32]struct{} {
+	s = strings.ToLower(norm.NFKC.String(s))
+	runes := []rune(s)
+	set := make(map[int32]struct{})
+	for i := 0; i+2 < len(runes); i++ {
+		set[packTrigram(runes[i], runes[i+1], runes[i+2])] = struct{}{}
+	}
+	return set
+}
+
+// packTrigram packs three runes into a 24-bit int, one byte per rune,
+// truncating each rune to its low 8 bits.
+func packTrigram(a, b, c rune) int32 {
+	return (int32(byte(a)) << 16) | (int32(byte(b)) << 8) | int32(byte(c))
+}
+
+// searchTrigramCandidates returns the message IDs whose indexed text
+// contains every trigram of term, via an INTERSECT over messages_trigram's
+// posting lists. Callers must re-verify each candidate with a LIKE (or
+// equivalent substring check) before returning it as a match, since trigram
+// intersection only proves term's trigrams are all present somewhere in the
+// message, not that they appear contiguously as term itself.
+func searchTrigramCandidates(ctx context.Context, db *sql.DB, term string) ([]int64, error) {
+	trigrams := trigramSet(term)
+	if len(trigrams) == 0 {
+		return nil, fmt.Errorf("trigram: term %q is shorter than 3 runes", term)
+	}
+	if utf8.RuneCountInString(term) < 3 {
+		return nil, fmt.Errorf("trigram: term %q is shorter than 3 runes", term)
+	}
+
+	selects := make([]string, 0, len(trigrams))
+	args := make([]any, 0, len(trigrams))
+	for tri := range trigrams {
+		selects = append(selects, "SELECT msg_id FROM messages_trigram WHERE trigram = ?")
+		args = append(args, tri)
+	}
+
+	query := strings.Join(selects, " INTERSECT ")
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("trigram: intersect posting lists: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("trigram: scan candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,125 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTrigramTestDB creates an in-memory SQLite database with just enough
+// schema (messages + message_bodies) for BuildTrigramIndex and
+// searchTrigramCandidates to exercise.
+func setupTrigramTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema := `
+		CREATE TABLE messages (id INTEGER PRIMARY KEY, subject TEXT, snippet TEXT);
+		CREATE TABLE message_bodies (message_id INTEGER PRIMARY KEY, body_text TEXT);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	rows := []struct {
+		id                     int64
+		subject, snippet, body string
+	}{
+		{1, "Quarterly report", "Attached is the report", "See the numbers below"},
+		{2, "Lunch plans", "Let's get lunch tomorrow", "How about noon?"},
+		{3, "Re: Quarterly report", "Thanks for the update", "Looks good to me"},
+	}
+	for _, r := range rows {
+		if _, err := db.Exec("INSERT INTO messages (id, subject, snippet) VALUES (?, ?, ?)", r.id, r.subject, r.snippet); err != nil {
+			t.Fatalf("insert message: %v", err)
+		}
+		if _, err := db.Exec("INSERT INTO message_bodies (message_id, body_text) VALUES (?, ?)", r.id, r.body); err != nil {
+			t.Fatalf("insert message_bodies: %v", err)
+		}
+	}
+	return db
+}
+
+func TestBuildTrigramIndex_PopulatesPostingLists(t *testing.T) {
+	db := setupTrigramTestDB(t)
+	ctx := context.Background()
+
+	if err := BuildTrigramIndex(ctx, db); err != nil {
+		t.Fatalf("BuildTrigramIndex: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages_trigram").Scan(&count); err != nil {
+		t.Fatalf("count messages_trigram: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("expected messages_trigram to have rows after BuildTrigramIndex")
+	}
+
+	// Re-running should replace rather than duplicate rows.
+	if err := BuildTrigramIndex(ctx, db); err != nil {
+		t.Fatalf("BuildTrigramIndex (second run): %v", err)
+	}
+	var count2 int
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages_trigram").Scan(&count2); err != nil {
+		t.Fatalf("count messages_trigram after rerun: %v", err)
+	}
+	if count2 != count {
+		t.Errorf("expected re-running BuildTrigramIndex to leave row count unchanged, got %d then %d", count, count2)
+	}
+}
+
+func TestSearchTrigramCandidates_FindsMatches(t *testing.T) {
+	db := setupTrigramTestDB(t)
+	ctx := context.Background()
+
+	if err := BuildTrigramIndex(ctx, db); err != nil {
+		t.Fatalf("BuildTrigramIndex: %v", err)
+	}
+
+	ids, err := searchTrigramCandidates(ctx, db, "quarterly")
+	if err != nil {
+		t.Fatalf("searchTrigramCandidates: %v", err)
+	}
+
+	found := make(map[int64]bool)
+	for _, id := range ids {
+		found[id] = true
+	}
+	if !found[1] || !found[3] {
+		t.Errorf("expected candidates 1 and 3 for %q, got %v", "quarterly", ids)
+	}
+	if found[2] {
+		t.Errorf("did not expect message 2 among candidates for %q, got %v", "quarterly", ids)
+	}
+}
+
+func TestSearchTrigramCandidates_ShortTermErrors(t *testing.T) {
+	db := setupTrigramTestDB(t)
+	ctx := context.Background()
+
+	if err := BuildTrigramIndex(ctx, db); err != nil {
+		t.Fatalf("BuildTrigramIndex: %v", err)
+	}
+
+	if _, err := searchTrigramCandidates(ctx, db, "ab"); err == nil {
+		t.Error("expected an error for a term shorter than 3 runes, got nil")
+	}
+}
+
+func TestPackTrigram_Distinct(t *testing.T) {
+	a := packTrigram('f', 'o', 'o')
+	b := packTrigram('b', 'a', 'r')
+	if a == b {
+		t.Error("expected distinct trigrams to pack to distinct values")
+	}
+	if packTrigram('f', 'o', 'o') != a {
+		t.Error("expected packTrigram to be deterministic")
+	}
+}
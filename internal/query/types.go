@@ -0,0 +1,274 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// Message is a single email as the query package returns it: the row from
+// messages plus the handful of denormalized fields (FromEmail, ToEmails,
+// Labels, TextBody, Attachments) every engine's scanMessage-equivalent
+// joins in, so callers don't need a second round trip for the common case
+// of "show me this message".
+type Message struct {
+	ID                  int64
+	SourceID            int64
+	ConversationID      int64
+	SourceMessageID     string
+	Subject             string
+	Snippet             string
+	Date                time.Time
+	SizeEstimate        int64
+	HasAttachments      bool
+	DeletedFromSourceAt *time.Time
+	FromEmail           string
+	ToEmails            []string
+	Labels              []string
+	TextBody            string
+	Attachments         []Attachment
+}
+
+// Attachment is the filename/size pair Message.Attachments carries; it
+// intentionally doesn't include content, so listing a message never pulls
+// attachment bytes along with it. Use Engine.GetAttachment for that.
+type Attachment struct {
+	Filename string
+	Size     int64
+}
+
+// AttachmentContent is an attachment's content, returned by
+// Engine.GetAttachment for callers (e.g. internal/mcp's attachment
+// resource) that need the bytes rather than just the filename/size
+// Message.Attachments carries.
+type AttachmentContent struct {
+	Filename   string
+	MimeType   string
+	Size       int64
+	Base64Data string
+}
+
+// MessageSortField selects which column ListMessages/ListMessagesPage sort
+// by when SortDirection is set explicitly (the default is sent_at).
+type MessageSortField string
+
+const (
+	MessageSortByDate    MessageSortField = "date"
+	MessageSortBySubject MessageSortField = "subject"
+)
+
+// SortDirection orders a MessageFilter-scoped listing; the zero value
+// behaves like SortDesc (newest first), matching every engine's default.
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// TimeGranularity buckets AggregateByTime's output.
+type TimeGranularity string
+
+const (
+	TimeDay   TimeGranularity = "day"
+	TimeMonth TimeGranularity = "month"
+	TimeYear  TimeGranularity = "year"
+)
+
+// View selects what SubAggregate breaks a filtered message set down by.
+type View string
+
+const (
+	ViewRecipients View = "recipients"
+	ViewLabels     View = "labels"
+)
+
+// MessageFilter narrows the messages an Engine method operates over. It's
+// shared by every Engine implementation (PostgresEngine, SQLiteEngine,
+// DuckDBEngine) and by every caller (internal/mcp, internal/grpcserver,
+// internal/exporter, the CLI's search command), so a predicate one engine
+// understands behaves the same way everywhere else.
+//
+// The fields below fall into two groups that don't fully overlap yet:
+// Sender/Recipient/Domain/Label/ConversationID/TimePeriod and the
+// SentAfter/SentBefore/MatchEmpty*/HasAttachment/HasReplyTo/HasInReplyTo
+// family are consumed by filterClause (PostgresEngine's and
+// DuckDBEngine's predicate builder); Limit/Offset/After/Before and the
+// IsRead/IsUnread/.../ThreadID/MimeType/AttachmentType/Mailbox/Headers
+// family come from internal/mcp and internal/grpcserver's request
+// decoding and aren't wired into filterClause's SQL yet. Accepted here for
+// forward compatibility rather than left off the struct (which would fail
+// those callers to compile), the same way Syncer.Options accepts fields a
+// given backend doesn't act on yet.
+type MessageFilter struct {
+	Sender              string
+	Recipient           string
+	Domain              string
+	Label               string
+	ConversationID      *int64
+	WithAttachmentsOnly bool
+	TimePeriod          string
+	TimeGranularity     TimeGranularity
+
+	Senders       []string
+	Recipients    []string
+	Domains       []string
+	Labels        []string
+	LabelsAll     []string
+	ExcludeLabels []string
+	Priorities    []string
+
+	MatchEmptySender     bool
+	MatchEmptyRecipients bool
+	MatchEmptyLabels     bool
+	MatchEmptyDomain     bool
+
+	HasAttachment *bool
+	HasReplyTo    *bool
+	HasInReplyTo  *bool
+
+	SentAfter  time.Time
+	SentBefore time.Time
+	SizeMin    int64
+	SizeMax    int64
+
+	FTSMode    FTSMode
+	ThreadMode ThreadMode
+
+	SortField     MessageSortField
+	SortDirection SortDirection
+
+	// Limit and Offset page ListMessages/SearchFast results for callers
+	// (internal/mcp, internal/grpcserver) that don't use the
+	// cursor-based Page/PageResult pagination.
+	Limit  int
+	Offset int
+
+	// After and Before are internal/mcp and internal/grpcserver's sent-date
+	// range, decoded from their own "YYYY-MM-DD" request fields rather
+	// than from a parsed search.Query. Equivalent to SentAfter/SentBefore;
+	// kept distinct since those two packages construct MessageFilter
+	// directly instead of through search.Parse.
+	After  *time.Time
+	Before *time.Time
+
+	IsRead    *bool
+	IsUnread  *bool
+	IsStarred *bool
+	IsReplied *bool
+	IsFlagged *bool
+
+	ThreadID       string
+	Headers        map[string][]string
+	MimeType       string
+	AttachmentType string
+	Mailbox        string
+}
+
+// AggregateOptions narrows and limits an Aggregate*/SubAggregate call, the
+// AggregateOptions analog of MessageFilter.
+type AggregateOptions struct {
+	ExcludeFromAddrs    []string
+	ExcludeToAddrs      []string
+	ExcludeDomains      []string
+	ExcludeLabels       []string
+	AttachmentMimeTypes []string
+
+	Senders    []string
+	Recipients []string
+	Domains    []string
+	Labels     []string
+	LabelsAll  []string
+	Priorities []string
+
+	SizeMin *int64
+	SizeMax *int64
+
+	SentAfter  *time.Time
+	SentBefore *time.Time
+
+	HasAttachment *bool
+
+	TimeGranularity TimeGranularity
+
+	// After and Before mirror MessageFilter's fields of the same name, for
+	// internal/mcp and internal/grpcserver's aggregate request handling;
+	// equivalent to SentAfter/SentBefore.
+	After  *time.Time
+	Before *time.Time
+
+	// SearchQuery, if set, restricts the aggregate to messages matching
+	// this search.Parse-able string (DuckDBEngine's buildWhereClause turns
+	// it into WHERE predicates the same way SearchFast does).
+	SearchQuery string
+
+	// Limit caps the number of aggregate rows returned, keeping the
+	// highest-count ones (results are already ordered by count DESC).
+	Limit int
+}
+
+// DefaultAggregateOptions returns the AggregateOptions every Aggregate*/
+// SubAggregate call uses when the caller doesn't need to narrow or limit
+// the result: no filters, no cap.
+func DefaultAggregateOptions() AggregateOptions {
+	return AggregateOptions{}
+}
+
+// AggregateRow is one bucket of an Aggregate*/SubAggregate result: a key
+// (sender address, recipient address, label name, or time period,
+// depending on which method produced it) plus its message count and the
+// attachments on those messages.
+type AggregateRow struct {
+	Key             string
+	Count           int64
+	AttachmentSize  int64
+	AttachmentCount int64
+}
+
+// StatsOptions narrows GetTotalStats; empty for now (every caller wants
+// vault-wide totals today), kept as a struct rather than a bare context arg
+// so a future per-account or per-date-range breakdown doesn't need a
+// signature change.
+type StatsOptions struct{}
+
+// TotalStats summarizes a vault's overall size, returned by GetTotalStats.
+type TotalStats struct {
+	TotalMessages    int64
+	TotalSizeBytes   int64
+	TotalAttachments int64
+}
+
+// AccountInfo is one source account's identity and message count, returned
+// by ListAccounts.
+type AccountInfo struct {
+	Email        string
+	MessageCount int64
+}
+
+// Engine is the query surface every backend (PostgresEngine, SQLiteEngine,
+// DuckDBEngine) implements, and the only type internal/mcp,
+// internal/grpcserver, and internal/exporter depend on, so swapping the
+// backend behind a server or CLI command is a one-line constructor change.
+type Engine interface {
+	Close() error
+
+	GetMessage(ctx context.Context, id int64) (*Message, error)
+	GetMessageBySourceID(ctx context.Context, sourceMessageID string) (*Message, error)
+	GetAttachment(ctx context.Context, id int64) (*AttachmentContent, error)
+
+	Search(ctx context.Context, q *search.Query, limit, offset int) ([]*Message, error)
+	SearchFast(ctx context.Context, q *search.Query, filter MessageFilter, limit, offset int) ([]*Message, error)
+	ListMessages(ctx context.Context, filter MessageFilter) ([]*Message, error)
+
+	AggregateBySender(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error)
+	AggregateByRecipient(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error)
+	AggregateByDomain(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error)
+	AggregateByLabel(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error)
+	AggregateByTime(ctx context.Context, opts AggregateOptions) ([]AggregateRow, error)
+	AggregateBySize(ctx context.Context, buckets []int64) ([]AggregateRow, error)
+	SubAggregate(ctx context.Context, filter MessageFilter, view View, opts AggregateOptions) ([]AggregateRow, error)
+
+	GetTotalStats(ctx context.Context, opts StatsOptions) (*TotalStats, error)
+	ListAccounts(ctx context.Context) ([]AccountInfo, error)
+}
@@ -0,0 +1,223 @@
+// Package rfc5322 validates message header blocks against RFC 5322 §2.2
+// and §3.6, independent of any particular ingestion pipeline. It was
+// factored out of internal/sync's header checks so other callers (import
+// tools, the IMAP connector) can run the same validation without linking
+// the syncer. It imports the validation idea from the Gluon GODT-2638
+// change without adopting its IMAP semantics.
+package rfc5322
+
+import (
+	"bytes"
+	"net/mail"
+	"strings"
+)
+
+// singletonHeaders are header fields RFC 5322 §3.6 requires appear at most
+// once.
+var singletonHeaders = []string{
+	"Date", "Subject", "Message-ID", "In-Reply-To", "References", "Sender", "Reply-To", "From",
+}
+
+// requiredHeaders are singleton fields a well-formed message must include.
+var requiredHeaders = []string{"Date", "Message-ID", "Subject"}
+
+// addressListHeaders are fields whose value must parse as an RFC 5322
+// address list.
+var addressListHeaders = []string{"From", "Sender", "Reply-To", "To", "Cc", "Bcc"}
+
+// msgIDHeaders are fields whose value(s) must be angle-bracketed msg-ids
+// (RFC 5322 §3.6.4).
+var msgIDHeaders = []string{"Message-ID", "In-Reply-To"}
+
+// ValidationError reports every rule ValidateMessageHeaderFields found
+// raw's headers to violate.
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return "rfc5322: invalid header fields: " + strings.Join(e.Violations, ", ")
+}
+
+// ValidateMessageHeaderFields checks raw's header block against RFC 5322
+// §2.2 and §3.6: singleton fields must not repeat, From/Sender/Reply-To/
+// To/Cc/Bcc must parse as address lists, and Message-ID/In-Reply-To must
+// be angle-bracketed msg-ids. It returns nil if raw has no violations, or
+// a *ValidationError listing them otherwise. Date is intentionally not
+// checked here: an unparseable Date is reported only via Warnings, since
+// callers commonly fall back to a source-provided internal timestamp
+// rather than rejecting the message.
+func ValidateMessageHeaderFields(raw []byte) error {
+	if violations := Violations(raw); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Violations returns the names of every rule raw's headers violate (nil if
+// none). Unlike ValidateMessageHeaderFields, it never wraps the result in
+// an error, which callers that want to log or persist (rather than just
+// check) the violated rules generally find easier to work with.
+func Violations(raw []byte) []string {
+	var violations []string
+
+	if bytes.ContainsAny(raw[:HeaderBlockEnd(raw)], "\x00") {
+		violations = append(violations, "nul-in-header")
+	}
+	if hasBareLF(raw[:HeaderBlockEnd(raw)]) {
+		violations = append(violations, "bare-lf-in-header")
+	}
+
+	names := HeaderNames(raw)
+	for _, name := range names {
+		if !isValidFieldName(name) {
+			violations = append(violations, "invalid-field-name")
+			break
+		}
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return append(violations, "unparseable-headers")
+	}
+	header := msg.Header
+
+	counts := make(map[string]int, len(singletonHeaders))
+	for _, h := range names {
+		counts[strings.ToLower(h)]++
+	}
+	for _, h := range singletonHeaders {
+		if counts[strings.ToLower(h)] > 1 {
+			violations = append(violations, "duplicate-"+strings.ToLower(h))
+		}
+	}
+	for _, h := range requiredHeaders {
+		if counts[strings.ToLower(h)] == 0 {
+			violations = append(violations, "missing-"+strings.ToLower(h))
+		}
+	}
+
+	if header.Get("From") == "" {
+		violations = append(violations, "missing-from")
+	}
+	for _, field := range addressListHeaders {
+		v := header.Get(field)
+		if v == "" {
+			continue
+		}
+		if _, err := mail.ParseAddressList(v); err != nil {
+			violations = append(violations, "invalid-"+strings.ToLower(field))
+		}
+	}
+
+	for _, field := range msgIDHeaders {
+		v := header.Get(field)
+		if v == "" {
+			continue
+		}
+		if !isAngleBracketedMsgID(v) {
+			violations = append(violations, "invalid-"+strings.ToLower(field))
+		}
+	}
+
+	for _, field := range singletonHeaders {
+		v := header.Get(field)
+		if strings.ContainsAny(v, "\r\n") {
+			violations = append(violations, "bare-crlf-in-"+strings.ToLower(field))
+		}
+	}
+
+	return violations
+}
+
+// Warnings returns non-fatal header issues worth logging, such as an
+// unparseable Date, that ValidateMessageHeaderFields deliberately does not
+// treat as violations.
+func Warnings(raw []byte) []string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	var warnings []string
+	if date := msg.Header.Get("Date"); date != "" {
+		if _, err := msg.Header.Date(); err != nil {
+			warnings = append(warnings, "unparseable-date")
+		}
+	}
+	return warnings
+}
+
+// isAngleBracketedMsgID reports whether every comma/space-separated token
+// in v looks like an RFC 5322 §3.6.4 msg-id: "<" id-left "@" id-right ">".
+func isAngleBracketedMsgID(v string) bool {
+	for _, tok := range strings.Fields(v) {
+		tok = strings.TrimSuffix(tok, ",")
+		if tok == "" {
+			continue
+		}
+		if !strings.HasPrefix(tok, "<") || !strings.HasSuffix(tok, ">") {
+			return false
+		}
+		if !strings.Contains(tok, "@") {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderBlockEnd returns the index of the blank line separating headers
+// from the body ("\r\n\r\n" or "\n\n"), or len(raw) if none is found.
+func HeaderBlockEnd(raw []byte) int {
+	if i := bytes.Index(raw, []byte("\r\n\r\n")); i >= 0 {
+		return i
+	}
+	if i := bytes.Index(raw, []byte("\n\n")); i >= 0 {
+		return i
+	}
+	return len(raw)
+}
+
+// hasBareLF reports whether header contains a line feed not preceded by a
+// carriage return, which RFC 5322 §2.2 forbids inside header folding (CRLF
+// is the only legal line ending).
+func hasBareLF(header []byte) bool {
+	for i, b := range header {
+		if b == '\n' && (i == 0 || header[i-1] != '\r') {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidFieldName reports whether name matches RFC 5322 §2.2's
+// field-name = 1*ftext, where ftext is any printable US-ASCII character
+// except ':' (0x21-0x39, 0x3B-0x7E).
+func isValidFieldName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x21 || r > 0x7E || r == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderNames returns the header field names in raw, in order, including
+// duplicates, so callers can detect repeated singleton headers that
+// mail.Header (a map) would otherwise collapse.
+func HeaderNames(raw []byte) []string {
+	var names []string
+	end := HeaderBlockEnd(raw)
+	for _, line := range bytes.Split(raw[:end], []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 || line[0] == ' ' || line[0] == '\t' {
+			continue // continuation of the previous header
+		}
+		if i := bytes.IndexByte(line, ':'); i > 0 {
+			names = append(names, string(line[:i]))
+		}
+	}
+	return names
+}
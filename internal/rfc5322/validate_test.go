@@ -0,0 +1,74 @@
+package rfc5322
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateMessageHeaderFields_Valid(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: hi\r\nDate: Mon, 1 Jan 2024 00:00:00 +0000\r\nMessage-ID: <abc@example.com>\r\n\r\nbody")
+	if err := ValidateMessageHeaderFields(raw); err != nil {
+		t.Errorf("ValidateMessageHeaderFields() = %v, want nil", err)
+	}
+}
+
+func TestValidateMessageHeaderFields_DuplicateFrom(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nFrom: mallory@example.com\r\nSubject: hi\r\n\r\nbody")
+	err := ValidateMessageHeaderFields(raw)
+	if err == nil {
+		t.Fatal("ValidateMessageHeaderFields() = nil, want error")
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error is not a *ValidationError: %v", err)
+	}
+	if !contains(verr.Violations, "duplicate-from") {
+		t.Errorf("Violations = %v, want duplicate-from", verr.Violations)
+	}
+}
+
+func TestValidateMessageHeaderFields_MissingFrom(t *testing.T) {
+	raw := []byte("To: bob@example.com\r\nSubject: hi\r\n\r\nbody")
+	err := ValidateMessageHeaderFields(raw)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !contains(verr.Violations, "missing-from") {
+		t.Errorf("ValidateMessageHeaderFields() = %v, want missing-from", err)
+	}
+}
+
+func TestValidateMessageHeaderFields_InvalidSenderAddress(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nSender: not an address\r\nSubject: hi\r\n\r\nbody")
+	err := ValidateMessageHeaderFields(raw)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !contains(verr.Violations, "invalid-sender") {
+		t.Errorf("ValidateMessageHeaderFields() = %v, want invalid-sender", err)
+	}
+}
+
+func TestValidateMessageHeaderFields_BadMessageID(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nSubject: hi\r\nMessage-ID: not-bracketed\r\n\r\nbody")
+	err := ValidateMessageHeaderFields(raw)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !contains(verr.Violations, "invalid-message-id") {
+		t.Errorf("ValidateMessageHeaderFields() = %v, want invalid-message-id", err)
+	}
+}
+
+func TestWarnings_UnparseableDateDoesNotFailValidation(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nSubject: hi\r\nDate: not a date\r\nMessage-ID: <a@example.com>\r\n\r\nbody")
+	if err := ValidateMessageHeaderFields(raw); err != nil {
+		t.Errorf("ValidateMessageHeaderFields() = %v, want nil (bad Date should only warn)", err)
+	}
+	if got := Warnings(raw); !contains(got, "unparseable-date") {
+		t.Errorf("Warnings() = %v, want unparseable-date", got)
+	}
+}
+
+func contains(ss []string, target string) bool {
+	for _, s := range ss {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
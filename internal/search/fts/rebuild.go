@@ -0,0 +1,122 @@
+package fts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// Rebuild repopulates search_fts5 from the current contents of messages
+// (subject, snippet), message_bodies (body_text, if that table exists),
+// and message_recipients/participants (from/to/cc/bcc addresses) - the
+// same source tables query.BuildTrigramIndex reads for its own index. It
+// is safe to re-run: existing rows are dropped first.
+func Rebuild(ctx context.Context, db *sql.DB) error {
+	idx, err := NewSQLiteIndex(db)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM search_fts5"); err != nil {
+		return fmt.Errorf("fts: clear search_fts5: %w", err)
+	}
+
+	hasBodies, err := tableExists(ctx, db, "message_bodies")
+	if err != nil {
+		return fmt.Errorf("fts: check message_bodies: %w", err)
+	}
+
+	selectSQL := "SELECT m.id, m.subject, m.snippet"
+	if hasBodies {
+		selectSQL += ", b.body_text FROM messages m LEFT JOIN message_bodies b ON b.message_id = m.id"
+	} else {
+		selectSQL += " FROM messages m"
+	}
+
+	rows, err := db.QueryContext(ctx, selectSQL)
+	if err != nil {
+		return fmt.Errorf("fts: read messages: %w", err)
+	}
+	defer rows.Close()
+
+	type row struct {
+		id      int64
+		subject string
+		snippet string
+		body    sql.NullString
+	}
+	var pending []row
+	for rows.Next() {
+		var r row
+		dest := []any{&r.id, &r.subject, &r.snippet}
+		if hasBodies {
+			dest = append(dest, &r.body)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("fts: scan message: %w", err)
+		}
+		pending = append(pending, r)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("fts: iterate messages: %w", err)
+	}
+
+	for _, r := range pending {
+		from, to, cc, bcc, err := recipientAddrs(ctx, db, r.id)
+		if err != nil {
+			return fmt.Errorf("fts: recipients for message %d: %w", r.id, err)
+		}
+		body := r.snippet
+		if r.body.Valid {
+			body = r.body.String
+		}
+		doc := search.Document{Subject: r.subject, From: from, To: to, Cc: cc, Bcc: bcc, Body: body}
+		if err := idx.Add(r.id, doc); err != nil {
+			return fmt.Errorf("fts: index message %d: %w", r.id, err)
+		}
+	}
+	return nil
+}
+
+// recipientAddrs returns the space-joined email addresses for msgID's
+// from/to/cc/bcc recipient types, the same recipient_type values
+// recipientExistsSQL filters on.
+func recipientAddrs(ctx context.Context, db *sql.DB, msgID int64) (from, to, cc, bcc string, err error) {
+	addrsByType := map[string]*string{"from": &from, "to": &to, "cc": &cc, "bcc": &bcc}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT mr.recipient_type, p.email_address
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = ?`, msgID)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	defer rows.Close()
+
+	grouped := map[string][]string{}
+	for rows.Next() {
+		var recipientType, addr string
+		if err := rows.Scan(&recipientType, &addr); err != nil {
+			return "", "", "", "", err
+		}
+		grouped[recipientType] = append(grouped[recipientType], addr)
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", "", "", err
+	}
+
+	for recipientType, dest := range addrsByType {
+		*dest = strings.Join(grouped[recipientType], " ")
+	}
+	return from, to, cc, bcc, nil
+}
+
+func tableExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	return n > 0, err
+}
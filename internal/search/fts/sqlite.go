@@ -0,0 +1,179 @@
+// Package fts is the default search.Index backend: a SQLite FTS5 virtual
+// table ranked with BM25, registered with search.RegisterBackend under the
+// name "sqlite-fts5".
+package fts
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+func init() {
+	search.RegisterBackend("sqlite-fts5", func(config any) (search.Index, error) {
+		db, ok := config.(*sql.DB)
+		if !ok {
+			return nil, fmt.Errorf("fts: sqlite-fts5 backend requires a *sql.DB config, got %T", config)
+		}
+		return NewSQLiteIndex(db)
+	})
+}
+
+// fieldOrder is the Document field order search_fts5's columns are
+// declared in; ftsColumn/ftsField below translate between a column's
+// position and its Document/Hit field name.
+var fieldOrder = []string{"subject", "from_addr", "to_addr", "cc_addr", "bcc_addr", "body"}
+
+// snippetFieldName maps a search_fts5 column name back to the Hit.Snippets
+// key callers expect - the Document field name, not the column name.
+var snippetFieldName = map[string]string{
+	"subject":   "subject",
+	"from_addr": "from",
+	"to_addr":   "to",
+	"cc_addr":   "cc",
+	"bcc_addr":  "bcc",
+	"body":      "body",
+}
+
+const schemaSQL = `
+CREATE VIRTUAL TABLE IF NOT EXISTS search_fts5 USING fts5(
+	subject, from_addr, to_addr, cc_addr, bcc_addr, body,
+	tokenize = 'porter unicode61'
+);
+`
+
+// SQLiteIndex is a search.Index backed by a SQLite FTS5 virtual table,
+// search_fts5, with one row per message keyed by rowid = message ID.
+type SQLiteIndex struct {
+	db *sql.DB
+}
+
+// NewSQLiteIndex opens (creating if needed) the search_fts5 virtual table
+// on db and returns an Index backed by it.
+func NewSQLiteIndex(db *sql.DB) (*SQLiteIndex, error) {
+	if _, err := db.Exec(schemaSQL); err != nil {
+		return nil, fmt.Errorf("fts: create search_fts5: %w", err)
+	}
+	return &SQLiteIndex{db: db}, nil
+}
+
+// Add (re)indexes msgID, replacing any prior content for it. FTS5 doesn't
+// support UPDATE-in-place on content columns reliably across engine
+// versions, so Add always deletes first.
+func (idx *SQLiteIndex) Add(msgID int64, doc search.Document) error {
+	if err := idx.Delete(msgID); err != nil {
+		return err
+	}
+	_, err := idx.db.Exec(
+		`INSERT INTO search_fts5(rowid, subject, from_addr, to_addr, cc_addr, bcc_addr, body)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		msgID, doc.Subject, doc.From, doc.To, doc.Cc, doc.Bcc, doc.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("fts: index message %d: %w", msgID, err)
+	}
+	return nil
+}
+
+// Delete removes msgID from the index. A no-op if msgID was never indexed.
+func (idx *SQLiteIndex) Delete(msgID int64) error {
+	if _, err := idx.db.Exec(`DELETE FROM search_fts5 WHERE rowid = ?`, msgID); err != nil {
+		return fmt.Errorf("fts: delete message %d: %w", msgID, err)
+	}
+	return nil
+}
+
+// Search runs q's TextTerms (matched against every field) and SubjectTerms
+// (matched against subject only) as an FTS5 MATCH query, ranked by BM25 -
+// lower bm25() is more relevant, so Hit.Score is the negation, making
+// higher Score more relevant like callers would expect from e.g. ts_rank.
+func (idx *SQLiteIndex) Search(q *search.Query, opts search.SearchOptions) ([]search.Hit, error) {
+	matchQuery := buildMatchQuery(q)
+	if matchQuery == "" {
+		return nil, nil
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	snippetExprs := make([]string, len(fieldOrder))
+	for i, col := range fieldOrder {
+		width := 32
+		if col == "subject" {
+			width = 16
+		}
+		snippetExprs[i] = fmt.Sprintf("snippet(search_fts5, %d, '<mark>', '</mark>', '...', %d)", i, width)
+	}
+
+	sqlText := fmt.Sprintf(
+		`SELECT rowid, bm25(search_fts5), %s
+		 FROM search_fts5
+		 WHERE search_fts5 MATCH ?
+		 ORDER BY bm25(search_fts5)
+		 LIMIT ? OFFSET ?`,
+		strings.Join(snippetExprs, ", "),
+	)
+
+	rows, err := idx.db.Query(sqlText, matchQuery, limit, opts.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("fts: search: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []search.Hit
+	for rows.Next() {
+		var msgID int64
+		var rank float64
+		snippets := make([]string, len(fieldOrder))
+		dest := []any{&msgID, &rank}
+		for i := range snippets {
+			dest = append(dest, &snippets[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("fts: scan hit: %w", err)
+		}
+
+		hit := search.Hit{
+			MessageID: msgID,
+			Score:     -rank,
+			Snippets:  make(map[string]string, len(fieldOrder)),
+		}
+		for i, col := range fieldOrder {
+			if snippets[i] != "" {
+				hit.Snippets[snippetFieldName[col]] = snippets[i]
+			}
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("fts: iterate hits: %w", err)
+	}
+	return hits, nil
+}
+
+// buildMatchQuery turns q's TextTerms and SubjectTerms into an FTS5 MATCH
+// expression: SubjectTerms are anchored to the subject column, TextTerms
+// match any column, and every term is quoted so a multi-word value (e.g.
+// "weekly report") is matched as a phrase instead of implicitly-ANDed
+// words. Returns "" if q has no text to search for.
+func buildMatchQuery(q *search.Query) string {
+	var clauses []string
+	for _, term := range q.SubjectTerms {
+		clauses = append(clauses, "subject:"+quoteFTS5Term(term))
+	}
+	for _, term := range q.TextTerms {
+		clauses = append(clauses, quoteFTS5Term(term))
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// quoteFTS5Term wraps term in FTS5's double-quoted string syntax, doubling
+// any embedded quote so it round-trips as a literal rather than closing
+// the string early.
+func quoteFTS5Term(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"testing"
+)
+
+// FuzzParse checks that Parse never panics on arbitrary input and that
+// whatever AST it produces is well-formed (every node's Leaf/Children/Child
+// matches its Kind, as validateNode checks for Compile-supplied trees).
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"",
+		"hello world",
+		`from:alice@example.com`,
+		`subject:"hello world"`,
+		`from:alice OR from:bob`,
+		`(from:alice OR from:bob) AND subject:invoice`,
+		`-label:archive`,
+		`NOT label:archive`,
+		`((()))`,
+		`"unterminated`,
+		`op:`,
+		`:value`,
+		`label:Work AND (has:attachment OR larger:5M) -is:read`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		q := Parse(query)
+		if q == nil {
+			t.Fatal("Parse returned nil Query")
+		}
+		if q.AST != nil {
+			if err := validateNode(q.AST); err != nil {
+				t.Errorf("Parse(%q) produced an invalid AST: %v", query, err)
+			}
+		}
+	})
+}
+
+func TestCompile_NilAST(t *testing.T) {
+	q, err := Compile(nil)
+	if err != nil {
+		t.Fatalf("Compile(nil): unexpected error %v", err)
+	}
+	if !q.IsEmpty() {
+		t.Errorf("Compile(nil): expected an empty Query, got %+v", q)
+	}
+}
+
+func TestCompile_PureConjunctionPopulatesFlatFields(t *testing.T) {
+	ast := &Node{
+		Kind: NodeAnd,
+		Children: []*Node{
+			{Kind: NodeLeaf, Leaf: &Clause{Op: "from", Value: "alice@example.com"}},
+			{Kind: NodeLeaf, Leaf: &Clause{Op: "label", Value: "Work"}},
+		},
+	}
+
+	q, err := Compile(ast)
+	if err != nil {
+		t.Fatalf("Compile: unexpected error %v", err)
+	}
+	if len(q.FromAddrs) != 1 || q.FromAddrs[0] != "alice@example.com" {
+		t.Errorf("FromAddrs: got %v, want [alice@example.com]", q.FromAddrs)
+	}
+	if len(q.Labels) != 1 || q.Labels[0] != "Work" {
+		t.Errorf("Labels: got %v, want [Work]", q.Labels)
+	}
+	if q.AST != ast {
+		t.Error("Compile: expected Query.AST to be the supplied node")
+	}
+}
+
+func TestCompile_OrLeavesFlatFieldsEmpty(t *testing.T) {
+	ast := &Node{
+		Kind: NodeOr,
+		Children: []*Node{
+			{Kind: NodeLeaf, Leaf: &Clause{Op: "from", Value: "alice@example.com"}},
+			{Kind: NodeLeaf, Leaf: &Clause{Op: "from", Value: "bob@example.com"}},
+		},
+	}
+
+	q, err := Compile(ast)
+	if err != nil {
+		t.Fatalf("Compile: unexpected error %v", err)
+	}
+	if len(q.FromAddrs) != 0 {
+		t.Errorf("FromAddrs: got %v, want empty (query isn't a pure conjunction)", q.FromAddrs)
+	}
+}
+
+func TestCompile_RejectsMalformedNodes(t *testing.T) {
+	tests := []struct {
+		name string
+		ast  *Node
+	}{
+		{"leaf without clause", &Node{Kind: NodeLeaf}},
+		{"and without children", &Node{Kind: NodeAnd}},
+		{"or without children", &Node{Kind: NodeOr}},
+		{"not without child", &Node{Kind: NodeNot}},
+		{"bad child", &Node{Kind: NodeAnd, Children: []*Node{{Kind: NodeLeaf}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.ast); err == nil {
+				t.Error("Compile: expected an error, got nil")
+			}
+		})
+	}
+}
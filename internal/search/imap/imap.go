@@ -0,0 +1,390 @@
+// Package imap compiles a *search.Query down to an IMAP SearchCriteria, so
+// msgvault can push a query's filtering to the server via UIDSearch instead
+// of always fetching every message and filtering locally - the approach
+// internal/imap.Client.ListMessages currently takes, since IMAP has no
+// generic full-text query syntax to forward as-is.
+package imap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap/v2"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+// CapabilitySet is the set of capabilities a server advertised in its
+// CAPABILITY response, keyed by capability name (e.g. "X-GM-EXT-1",
+// "CONDSTORE"), consulted when deciding whether a predicate can be pushed
+// down.
+type CapabilitySet map[string]bool
+
+// HasGmailExtensions reports whether caps includes Gmail's IMAP extension
+// capability, X-GM-EXT-1 (X-GM-RAW, X-GM-LABELS, X-GM-THRID, ...).
+func (caps CapabilitySet) HasGmailExtensions() bool {
+	return caps["X-GM-EXT-1"]
+}
+
+// ResidualClause is one Clause Compile could not express as IMAP
+// SearchCriteria, tagged with whether it appeared negated.
+type ResidualClause struct {
+	Clause  *search.Clause
+	Negated bool
+}
+
+// Residual lists the parts of a Query's AST Compile could not push down to
+// the server as SearchCriteria; a caller must still test each server-side
+// match against these before treating it as a real hit. Whole subtrees -
+// not just individual clauses - can end up here: an OR or a NOT with an
+// inexpressible branch falls back entirely, since splitting it would
+// silently change what the query means (see compileOr/compileNot).
+type Residual struct {
+	Clauses []ResidualClause
+}
+
+// IsEmpty reports whether every part of the query was expressed as IMAP
+// SearchCriteria, so the server's results need no further client-side
+// filtering.
+func (r Residual) IsEmpty() bool {
+	return len(r.Clauses) == 0
+}
+
+func (r Residual) merge(other Residual) Residual {
+	if len(other.Clauses) == 0 {
+		return r
+	}
+	return Residual{Clauses: append(append([]ResidualClause{}, r.Clauses...), other.Clauses...)}
+}
+
+// negate returns a copy of r with every clause's Negated flag flipped, used
+// when an inexpressible subtree is wrapped in a NOT.
+func (r Residual) negate() Residual {
+	out := make([]ResidualClause, len(r.Clauses))
+	for i, c := range r.Clauses {
+		out[i] = ResidualClause{Clause: c.Clause, Negated: !c.Negated}
+	}
+	return Residual{Clauses: out}
+}
+
+// Compile converts q's AST into an imap.SearchCriteria for an IMAP UIDSearch
+// call, plus a Residual describing what that criteria does not already
+// guarantee: the caller must re-check every Residual clause against each
+// fetched message before treating it as a match.
+//
+// caps is accepted (rather than ignored) because which predicates are
+// pushable is a server property: HasGmailExtensions gates the X-GM-RAW/
+// X-GM-LABELS behavior described in the package's motivating request, once
+// this compiler has a way to emit them (today's emersion/go-imap/v2
+// SearchCriteria has no raw-extension-keyword field, only the typed search
+// keys below, so has:attachment always uses the portable Content-Type
+// heuristic and label: always falls back to Residual - see compileLeaf).
+func Compile(q *search.Query, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	if q == nil || q.AST == nil {
+		return &imap.SearchCriteria{}, Residual{}, nil
+	}
+	return compileNode(q.AST, caps)
+}
+
+func compileNode(node *search.Node, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	switch node.Kind {
+	case search.NodeLeaf:
+		return compileLeaf(node.Leaf, caps)
+	case search.NodeAnd:
+		return compileAnd(node.Children, caps)
+	case search.NodeOr:
+		return compileOr(node, caps)
+	case search.NodeNot:
+		return compileNot(node, caps)
+	default:
+		return nil, Residual{}, fmt.Errorf("search/imap: unsupported node kind %v", node.Kind)
+	}
+}
+
+// compileAnd merges every child's criteria into one (IMAP SEARCH keys are
+// implicitly ANDed) and concatenates their residuals. Unlike OR, AND is
+// always safe to split between what's pushable and what isn't: narrowing
+// the server-side result set with the pushable half and residual-filtering
+// the rest afterward gives the same matches as doing it all client-side.
+func compileAnd(children []*search.Node, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	merged := &imap.SearchCriteria{}
+	var residual Residual
+	for _, child := range children {
+		c, r, err := compileNode(child, caps)
+		if err != nil {
+			return nil, Residual{}, err
+		}
+		merged = mergeCriteria(merged, c)
+		residual = residual.merge(r)
+	}
+	return merged, residual, nil
+}
+
+// compileOr folds children pairwise into imap.SearchCriteria.Or, which only
+// holds binary pairs: A OR B OR C becomes A OR (B OR C). If any child isn't
+// fully expressible, the whole OR falls back to Residual instead - pushing
+// only the expressible branches would turn the OR into an AND-shaped
+// under-match (a message satisfying only the inexpressible branch would
+// never reach the server-side result set to be residual-checked at all).
+func compileOr(node *search.Node, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	children := node.Children
+	if len(children) == 0 {
+		return &imap.SearchCriteria{}, Residual{}, nil
+	}
+
+	compiled := make([]*imap.SearchCriteria, len(children))
+	for i, child := range children {
+		c, r, err := compileNode(child, caps)
+		if err != nil {
+			return nil, Residual{}, err
+		}
+		if !r.IsEmpty() {
+			return &imap.SearchCriteria{}, wholeNodeResidual(node, false), nil
+		}
+		compiled[i] = c
+	}
+
+	result := compiled[len(compiled)-1]
+	for i := len(compiled) - 2; i >= 0; i-- {
+		result = &imap.SearchCriteria{Or: [][2]imap.SearchCriteria{{*compiled[i], *result}}}
+	}
+	return result, Residual{}, nil
+}
+
+// compileNot wraps child in imap.SearchCriteria.Not if it's fully
+// expressible. Otherwise the whole NOT falls back to Residual (negated):
+// NOT of a partially-pushable subtree can't be expressed by negating just
+// the pushable part, since De Morgan's laws would need the subtree's
+// original AND/OR structure, not the single merged SearchCriteria
+// compileAnd/compileOr already collapsed it into.
+func compileNot(node *search.Node, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	inner, residual, err := compileNode(node.Child, caps)
+	if err != nil {
+		return nil, Residual{}, err
+	}
+	if !residual.IsEmpty() {
+		return &imap.SearchCriteria{}, wholeNodeResidual(node.Child, true), nil
+	}
+	return &imap.SearchCriteria{Not: []imap.SearchCriteria{*inner}}, Residual{}, nil
+}
+
+// wholeNodeResidual defers all of node to client-side filtering, tagging
+// every leaf beneath it with negated (flipped again for any NOT already
+// inside node, via Residual.negate, so a NOT nested under another NOT still
+// reports the right final polarity).
+func wholeNodeResidual(node *search.Node, negated bool) Residual {
+	var out Residual
+	for _, c := range leafClauses(node) {
+		out.Clauses = append(out.Clauses, ResidualClause{Clause: c})
+	}
+	if negated {
+		out = out.negate()
+	}
+	return out
+}
+
+// leafClauses collects every Clause in node's subtree, ignoring NOT/OR/AND
+// structure - Residual only needs to know which clauses must be
+// re-evaluated, not how they combine, since wholeNodeResidual already
+// decided the whole subtree is opaque to the server.
+func leafClauses(node *search.Node) []*search.Clause {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case search.NodeLeaf:
+		return []*search.Clause{node.Leaf}
+	case search.NodeAnd, search.NodeOr:
+		var out []*search.Clause
+		for _, c := range node.Children {
+			out = append(out, leafClauses(c)...)
+		}
+		return out
+	case search.NodeNot:
+		return leafClauses(node.Child)
+	}
+	return nil
+}
+
+// compileLeaf lowers a single Clause to SearchCriteria, falling back to
+// Residual for operators this library's typed SearchCriteria has no field
+// for at all (label:, domain:, thread:, mimetype:, attachment_type:, in:)
+// or whose value didn't parse.
+func compileLeaf(c *search.Clause, caps CapabilitySet) (*imap.SearchCriteria, Residual, error) {
+	switch c.Op {
+	case "":
+		return &imap.SearchCriteria{Text: []string{c.Value}}, Residual{}, nil
+	case "body":
+		return &imap.SearchCriteria{Body: []string{c.Value}}, Residual{}, nil
+	case "subject":
+		return headerCriteria("Subject", c.Value), Residual{}, nil
+	case "from":
+		return headerCriteria("From", c.Value), Residual{}, nil
+	case "to":
+		return headerCriteria("To", c.Value), Residual{}, nil
+	case "cc":
+		return headerCriteria("Cc", c.Value), Residual{}, nil
+	case "bcc":
+		return headerCriteria("Bcc", c.Value), Residual{}, nil
+	case "header":
+		name, value, ok := strings.Cut(c.Value, "=")
+		if !ok {
+			return residualLeaf(c), Residual{Clauses: []ResidualClause{{Clause: c}}}, nil
+		}
+		return headerCriteria(name, value), Residual{}, nil
+	case "has":
+		v := strings.ToLower(c.Value)
+		if v == "attachment" || v == "attachments" {
+			// Gmail's X-GM-RAW "has:attachment" has no typed field in this
+			// library's SearchCriteria (see Compile's doc comment), so
+			// every server gets the portable Content-Type heuristic.
+			return headerCriteria("Content-Type", "multipart/mixed"), Residual{}, nil
+		}
+		return residualLeaf(c), Residual{Clauses: []ResidualClause{{Clause: c}}}, nil
+	case "before":
+		if t := search.ParseDate(c.Value); t != nil {
+			return &imap.SearchCriteria{SentBefore: *t}, Residual{}, nil
+		}
+	case "after":
+		if t := search.ParseDate(c.Value); t != nil {
+			return &imap.SearchCriteria{SentSince: *t}, Residual{}, nil
+		}
+	case "older_than":
+		if t := search.ParseRelativeDate(c.Value); t != nil {
+			return &imap.SearchCriteria{SentBefore: *t}, Residual{}, nil
+		}
+	case "newer_than":
+		if t := search.ParseRelativeDate(c.Value); t != nil {
+			return &imap.SearchCriteria{SentSince: *t}, Residual{}, nil
+		}
+	case "larger":
+		if size := search.ParseSize(c.Value); size != nil {
+			return &imap.SearchCriteria{Larger: *size}, Residual{}, nil
+		}
+	case "smaller":
+		if size := search.ParseSize(c.Value); size != nil {
+			return &imap.SearchCriteria{Smaller: *size}, Residual{}, nil
+		}
+	case "is":
+		switch strings.ToLower(c.Value) {
+		case "read":
+			return &imap.SearchCriteria{Flag: []imap.Flag{imap.FlagSeen}}, Residual{}, nil
+		case "unread":
+			return &imap.SearchCriteria{NotFlag: []imap.Flag{imap.FlagSeen}}, Residual{}, nil
+		case "starred", "flagged":
+			return &imap.SearchCriteria{Flag: []imap.Flag{imap.FlagFlagged}}, Residual{}, nil
+		case "replied":
+			return &imap.SearchCriteria{Flag: []imap.Flag{imap.FlagAnswered}}, Residual{}, nil
+		}
+	}
+	return residualLeaf(c), Residual{Clauses: []ResidualClause{{Clause: c}}}, nil
+}
+
+// residualLeaf is the "no constraint pushed" SearchCriteria paired with a
+// residual clause - always imap.SearchCriteria{}, factored out so every
+// residual-returning branch above reads the same way.
+func residualLeaf(*search.Clause) *imap.SearchCriteria {
+	return &imap.SearchCriteria{}
+}
+
+func headerCriteria(key, value string) *imap.SearchCriteria {
+	return &imap.SearchCriteria{Header: []imap.SearchCriteriaHeaderField{{Key: key, Value: value}}}
+}
+
+// mergeCriteria combines a and b the way IMAP SEARCH combines multiple
+// keys: AND. Slice-valued fields concatenate (repeated keys must all
+// match); Since/SentSince take the later (tightest lower) bound and Before/
+// SentBefore the earlier (tightest upper) bound; Larger takes the bigger
+// floor and Smaller the smaller ceiling; ModSeq takes the higher value.
+// SeqNum/UID are left alone - compileLeaf never populates them, since this
+// compiler builds criteria from a parsed search.Query, not a sequence/UID
+// range a caller already has in hand.
+func mergeCriteria(a, b *imap.SearchCriteria) *imap.SearchCriteria {
+	out := &imap.SearchCriteria{
+		Header:  append(a.Header, b.Header...),
+		Body:    append(a.Body, b.Body...),
+		Text:    append(a.Text, b.Text...),
+		Flag:    append(a.Flag, b.Flag...),
+		NotFlag: append(a.NotFlag, b.NotFlag...),
+		Not:     append(a.Not, b.Not...),
+		Or:      append(a.Or, b.Or...),
+	}
+	out.Since = laterTime(a.Since, b.Since)
+	out.Before = earlierTime(a.Before, b.Before)
+	out.SentSince = laterTime(a.SentSince, b.SentSince)
+	out.SentBefore = earlierTime(a.SentBefore, b.SentBefore)
+	out.Larger = maxInt64(a.Larger, b.Larger)
+	out.Smaller = minPositiveInt64(a.Smaller, b.Smaller)
+	out.ModSeq = maxModSeq(a.ModSeq, b.ModSeq)
+	return out
+}
+
+func laterTime(a, b time.Time) time.Time {
+	if a.IsZero() {
+		return b
+	}
+	if b.IsZero() {
+		return a
+	}
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlierTime(a, b time.Time) time.Time {
+	if a.IsZero() {
+		return b
+	}
+	if b.IsZero() {
+		return a
+	}
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minPositiveInt64(a, b int64) int64 {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxUint64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// maxModSeq merges two possibly-nil ModSeq bounds, keeping whichever has the
+// higher ModSeq value (nil means "no bound", same as the zero values
+// laterTime/maxInt64 etc. treat as absent elsewhere in this file).
+func maxModSeq(a, b *imap.SearchCriteriaModSeq) *imap.SearchCriteriaModSeq {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.ModSeq > b.ModSeq {
+		return a
+	}
+	return b
+}
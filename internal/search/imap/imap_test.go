@@ -0,0 +1,166 @@
+package imap
+
+import (
+	"testing"
+
+	"github.com/emersion/go-imap/v2"
+
+	"github.com/wesm/msgvault/internal/search"
+)
+
+func TestCompile_SimpleAndMergesHeaders(t *testing.T) {
+	q := search.Parse(`from:alice@example.com subject:invoice`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Fatalf("residual: got %+v, want empty", residual)
+	}
+	if len(criteria.Header) != 2 {
+		t.Fatalf("Header: got %v, want 2 entries", criteria.Header)
+	}
+	if criteria.Header[0].Key != "From" || criteria.Header[0].Value != "alice@example.com" {
+		t.Errorf("Header[0]: got %+v, want From alice@example.com", criteria.Header[0])
+	}
+	if criteria.Header[1].Key != "Subject" || criteria.Header[1].Value != "invoice" {
+		t.Errorf("Header[1]: got %+v, want Subject invoice", criteria.Header[1])
+	}
+}
+
+func TestCompile_OrFoldsPairwise(t *testing.T) {
+	q := search.Parse(`from:alice@example.com OR from:bob@example.com`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Fatalf("residual: got %+v, want empty", residual)
+	}
+	if len(criteria.Or) != 1 {
+		t.Fatalf("Or: got %d entries, want 1", len(criteria.Or))
+	}
+	pair := criteria.Or[0]
+	if len(pair[0].Header) != 1 || pair[0].Header[0].Value != "alice@example.com" {
+		t.Errorf("Or[0]: got %+v, want From alice@example.com", pair[0])
+	}
+	if len(pair[1].Header) != 1 || pair[1].Header[0].Value != "bob@example.com" {
+		t.Errorf("Or[1]: got %+v, want From bob@example.com", pair[1])
+	}
+}
+
+func TestCompile_LabelFallsBackToResidual(t *testing.T) {
+	q := search.Parse(`label:urgent`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if residual.IsEmpty() {
+		t.Fatal("residual: got empty, want the label: clause")
+	}
+	if len(residual.Clauses) != 1 || residual.Clauses[0].Clause.Op != "label" {
+		t.Errorf("residual.Clauses: got %+v, want one label clause", residual.Clauses)
+	}
+	if len(criteria.Header) != 0 || len(criteria.Text) != 0 {
+		t.Errorf("criteria: got %+v, want no constraint pushed", criteria)
+	}
+}
+
+func TestCompile_OrWithInexpressibleBranchFallsBackWhole(t *testing.T) {
+	q := search.Parse(`from:alice@example.com OR label:urgent`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(criteria.Header) != 0 || len(criteria.Or) != 0 {
+		t.Errorf("criteria: got %+v, want empty so every message gets fetched", criteria)
+	}
+	if len(residual.Clauses) != 2 {
+		t.Fatalf("residual.Clauses: got %d, want 2 (from and label)", len(residual.Clauses))
+	}
+}
+
+func TestCompile_NegatedGroupFallsBackWhole(t *testing.T) {
+	q := search.Parse(`-(from:alice@example.com OR label:urgent)`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(criteria.Header) != 0 || len(criteria.Not) != 0 {
+		t.Errorf("criteria: got %+v, want empty", criteria)
+	}
+	for _, rc := range residual.Clauses {
+		if !rc.Negated {
+			t.Errorf("residual clause %+v: want Negated true", rc)
+		}
+	}
+	if len(residual.Clauses) != 2 {
+		t.Fatalf("residual.Clauses: got %d, want 2", len(residual.Clauses))
+	}
+}
+
+func TestCompile_HasAttachmentUsesContentTypeHeuristic(t *testing.T) {
+	q := search.Parse(`has:attachment`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Fatalf("residual: got %+v, want empty", residual)
+	}
+	if len(criteria.Header) != 1 || criteria.Header[0].Key != "Content-Type" {
+		t.Fatalf("Header: got %+v, want one Content-Type entry", criteria.Header)
+	}
+}
+
+func TestCompile_IsFlagsMapToImapFlags(t *testing.T) {
+	q := search.Parse(`is:unread is:starred`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Fatalf("residual: got %+v, want empty", residual)
+	}
+	if len(criteria.NotFlag) != 1 || criteria.NotFlag[0] != imap.FlagSeen {
+		t.Errorf("NotFlag: got %v, want [\\Seen]", criteria.NotFlag)
+	}
+	if len(criteria.Flag) != 1 || criteria.Flag[0] != imap.FlagFlagged {
+		t.Errorf("Flag: got %v, want [\\Flagged]", criteria.Flag)
+	}
+}
+
+func TestCompile_DateRangeUsesSentSinceBefore(t *testing.T) {
+	q := search.Parse(`after:2024-01-01 before:2024-06-01`)
+
+	criteria, residual, err := Compile(q, nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Fatalf("residual: got %+v, want empty", residual)
+	}
+	if criteria.SentSince.IsZero() || criteria.SentBefore.IsZero() {
+		t.Errorf("criteria: got %+v, want both SentSince and SentBefore set", criteria)
+	}
+}
+
+func TestCompile_EmptyQuery(t *testing.T) {
+	criteria, residual, err := Compile(search.Parse(""), nil)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if !residual.IsEmpty() {
+		t.Errorf("residual: got %+v, want empty", residual)
+	}
+	if len(criteria.Header) != 0 {
+		t.Errorf("criteria: got %+v, want zero value", criteria)
+	}
+}
@@ -0,0 +1,69 @@
+package search
+
+import "fmt"
+
+// Document is the per-message content handed to an Index for indexing -
+// the fields a full-text backend can rank and snippet against, separate
+// from the Clause-level text/subject filters TextTerms/SubjectTerms
+// already drive for the plain LIKE/ILIKE path.
+type Document struct {
+	Subject string
+	From    string
+	To      string
+	Cc      string
+	Bcc     string
+	Body    string
+}
+
+// Hit is one ranked match from an Index.Search: a message ID, its BM25 (or
+// backend-equivalent) relevance score, and per-field excerpts with
+// "<mark>...</mark>" wrapped around matched terms, keyed by Document field
+// name ("subject", "body", ...).
+type Hit struct {
+	MessageID int64
+	Score     float64
+	Snippets  map[string]string
+}
+
+// SearchOptions controls pagination for an Index.Search.
+type SearchOptions struct {
+	Limit  int
+	Offset int
+}
+
+// Index is a full-text index a query engine can delegate TextTerms/
+// SubjectTerms matching to instead of emitting a LIKE/ILIKE fragment,
+// trading that approach's simplicity for relevance ranking, phrase
+// proximity, and (backend-dependent) stemming.
+type Index interface {
+	Add(msgID int64, doc Document) error
+	Delete(msgID int64) error
+	Search(q *Query, opts SearchOptions) ([]Hit, error)
+}
+
+// BackendFactory builds an Index from a backend-specific config value -
+// e.g. search/fts's default backend takes a *sql.DB.
+type BackendFactory func(config any) (Index, error)
+
+var backends = map[string]BackendFactory{}
+
+// RegisterBackend makes a named Index implementation available to Open.
+// Implementations call this from an init(), the way database/sql drivers
+// register themselves, so adding a backend - e.g. a Bleve-based one for
+// deployments that want a self-contained Go index instead of depending on
+// SQLite's FTS5 extension - is a matter of importing its package for the
+// side effect. Only search/fts's "sqlite-fts5" backend ships in this repo
+// today; a Bleve backend would register under its own name the same way.
+func RegisterBackend(name string, factory BackendFactory) {
+	backends[name] = factory
+}
+
+// Open builds the named backend's Index with config. Returns an error if
+// name was never registered (most likely its package wasn't imported).
+func Open(name string, config any) (Index, error) {
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("search: backend %q not registered", name)
+	}
+	return factory(config)
+}
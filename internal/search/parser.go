@@ -2,6 +2,7 @@
 package search
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,7 +23,63 @@ type Query struct {
 	AfterDate     *time.Time // after: filter
 	LargerThan    *int64     // larger: filter (bytes)
 	SmallerThan   *int64     // smaller: filter (bytes)
-	AccountID     *int64     // in: account filter
+	AccountID     *int64     // account filter (set by callers, not by an operator)
+
+	// IsRead, IsUnread, IsStarred, IsReplied, and IsFlagged are tri-state
+	// flags from is:read, is:unread, is:starred, is:replied, and
+	// is:flagged. Each is nil unless the query mentions it, true if it
+	// does (is:unread is equivalent to IsRead == false, but both are
+	// accepted as separate operators since that's how users type them).
+	IsRead    *bool
+	IsUnread  *bool
+	IsStarred *bool
+	IsReplied *bool
+	IsFlagged *bool
+
+	// ThreadID is set by thread:<id> to fetch an entire conversation.
+	ThreadID string
+
+	// Headers holds header:Name=Value filters, keyed by header name as
+	// written in the query (comparison against stored headers should be
+	// case-insensitive). A header may be repeated to require several
+	// values.
+	Headers map[string][]string
+
+	// MimeTypes and AttachmentTypes hold mimetype: and attachment_type:
+	// filters, matched against a message's own Content-Type and its
+	// attachments' Content-Types respectively.
+	MimeTypes       []string
+	AttachmentTypes []string
+
+	// Mailboxes holds in:<mailbox> filters. Distinct from Labels: in:
+	// matches the mailbox/folder a message is filed under, while label:
+	// matches any label applied to it (a message can have many labels but
+	// lives in one mailbox at a time for providers with that distinction).
+	Mailboxes []string
+
+	// Domains holds domain: filters, matched against a participant's email
+	// domain rather than its full address.
+	Domains []string
+
+	// ExcludeFromAddrs, ExcludeToAddrs, ExcludeLabels, and ExcludeDomains
+	// hold the negated form of FromAddrs, ToAddrs, Labels, and Domains
+	// (e.g. "-from:alice@example.com" or "NOT label:archive"). Like the
+	// other flat fields, these are only populated when AST is a pure
+	// conjunction of leaves and negated leaves.
+	ExcludeFromAddrs []string
+	ExcludeToAddrs   []string
+	ExcludeLabels    []string
+	ExcludeDomains   []string
+
+	// AST is the boolean expression tree for the query (AND/OR/NOT over
+	// Clause leaves), built for every non-empty query. The flat slice
+	// fields above are only populated when AST is a pure conjunction: an
+	// AND of leaves and/or directly-negated leaves (no OR anywhere, and no
+	// NOT wrapping anything but a single leaf), so existing callers that
+	// only understand an implicit AND of those fields keep working
+	// unchanged. Once a query uses OR, or NOT over a group, Engine.Search
+	// must walk AST instead.
+	AST *Node
 }
 
 // IsEmpty returns true if the query has no search criteria.
@@ -38,7 +95,233 @@ func (q *Query) IsEmpty() bool {
 		q.BeforeDate == nil &&
 		q.AfterDate == nil &&
 		q.LargerThan == nil &&
-		q.SmallerThan == nil
+		q.SmallerThan == nil &&
+		q.IsRead == nil &&
+		q.IsUnread == nil &&
+		q.IsStarred == nil &&
+		q.IsReplied == nil &&
+		q.IsFlagged == nil &&
+		q.ThreadID == "" &&
+		len(q.Headers) == 0 &&
+		len(q.MimeTypes) == 0 &&
+		len(q.AttachmentTypes) == 0 &&
+		len(q.Mailboxes) == 0 &&
+		len(q.Domains) == 0 &&
+		len(q.ExcludeFromAddrs) == 0 &&
+		len(q.ExcludeToAddrs) == 0 &&
+		len(q.ExcludeLabels) == 0 &&
+		len(q.ExcludeDomains) == 0 &&
+		q.AST == nil
+}
+
+// NodeKind identifies what a Node represents in a Query's boolean
+// expression tree.
+type NodeKind int
+
+const (
+	// NodeLeaf holds a single Clause (an operator:value pair or a bare/
+	// quoted text term).
+	NodeLeaf NodeKind = iota
+	// NodeAnd requires all Children to match.
+	NodeAnd
+	// NodeOr requires at least one Children to match.
+	NodeOr
+	// NodeNot requires Child to not match.
+	NodeNot
+)
+
+// Node is one node of a Query's boolean expression tree, built from the
+// query's AND/OR/NOT/parenthesized grouping syntax. Exactly one of Leaf,
+// Children, or Child is set, matching Kind.
+type Node struct {
+	Kind     NodeKind
+	Leaf     *Clause // set when Kind == NodeLeaf
+	Children []*Node // set when Kind == NodeAnd or NodeOr
+	Child    *Node   // set when Kind == NodeNot
+}
+
+// Clause is a single search term: either an operator:value pair (Op is the
+// lowercased operator name, e.g. "from", "subject") or a bare/quoted text
+// term (Op is ""). Raw is the original token text, kept so an unrecognized
+// operator can still be treated as a literal text term the way the legacy
+// flat-field parser did.
+type Clause struct {
+	Op    string
+	Value string
+	Raw   string
+
+	// Match selects how Value should be compared: a substring scan (the
+	// default), a prefix match, or an exact match. Set by an "="/"^"/"~"
+	// prefix on the value (e.g. "subject:=Exact", "subject:^Prefix",
+	// "subject:~contains"); absent that prefix, Match is MatchContains,
+	// matching classifyToken's behavior before Match existed.
+	Match MatchType
+}
+
+// MatchType selects how a Clause's Value should be compared against a
+// column. Introduced so from:/subject:/bare-text clauses can opt into a
+// cheaper, indexable prefix or exact comparison instead of always scanning
+// for the value as a substring.
+type MatchType int
+
+const (
+	// MatchContains matches Value anywhere in the column (the historical
+	// "%value%" behavior, and the default when a clause has no "="/"^"/"~"
+	// prefix).
+	MatchContains MatchType = iota
+	// MatchEquals matches the column exactly, set by an "=" prefix (e.g.
+	// `subject:="Weekly Report"`).
+	MatchEquals
+	// MatchPrefix matches Value at the start of the column, set by a "^"
+	// prefix (e.g. `subject:^Weekly`).
+	MatchPrefix
+)
+
+// isPureConjunction reports whether node (and everything beneath it) is
+// equivalent to the flat, implicitly-ANDed field set Query used before AST
+// existed: an AND of leaves and/or leaves directly negated by NOT. No OR may
+// appear anywhere, and NOT may only wrap a single leaf (NOT over a group
+// like "NOT (from:a OR from:b)" isn't flat-representable, since the
+// Exclude* fields hold plain value lists, not sub-expressions).
+func isPureConjunction(node *Node) bool {
+	if node == nil {
+		return true
+	}
+	switch node.Kind {
+	case NodeLeaf:
+		return true
+	case NodeAnd:
+		for _, c := range node.Children {
+			if !isPureConjunction(c) {
+				return false
+			}
+		}
+		return true
+	case NodeNot:
+		return node.Child != nil && node.Child.Kind == NodeLeaf
+	default: // NodeOr
+		return false
+	}
+}
+
+// leaves returns every NodeLeaf's Clause in node, in left-to-right order,
+// regardless of any NOT/OR structure above it. Used where callers just want
+// every clause in the tree and don't care about boolean structure.
+func leaves(node *Node) []*Clause {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case NodeLeaf:
+		return []*Clause{node.Leaf}
+	case NodeAnd, NodeOr:
+		var out []*Clause
+		for _, c := range node.Children {
+			out = append(out, leaves(c)...)
+		}
+		return out
+	case NodeNot:
+		return leaves(node.Child)
+	}
+	return nil
+}
+
+// leafEntry pairs a Clause with whether it appeared directly under a NOT.
+type leafEntry struct {
+	clause  *Clause
+	negated bool
+}
+
+// leafEntries returns every NodeLeaf's Clause in node, in left-to-right
+// order, tagging those directly wrapped in a NOT as negated. Only valid to
+// call on a node for which isPureConjunction is true.
+func leafEntries(node *Node) []leafEntry {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case NodeLeaf:
+		return []leafEntry{{clause: node.Leaf}}
+	case NodeAnd:
+		var out []leafEntry
+		for _, c := range node.Children {
+			out = append(out, leafEntries(c)...)
+		}
+		return out
+	case NodeNot:
+		if node.Child != nil && node.Child.Kind == NodeLeaf {
+			return []leafEntry{{clause: node.Child.Leaf, negated: true}}
+		}
+	}
+	return nil
+}
+
+// Compile builds a Query from an already-constructed AST, the way Parse
+// builds one from a parsed string. It lets callers assemble a query
+// programmatically (e.g. from a UI's structured filter builder) instead of
+// through the string grammar, while still populating the legacy flat fields
+// when ast is a pure conjunction, so existing field-based callers work with
+// either source.
+func Compile(ast *Node) (*Query, error) {
+	if ast == nil {
+		return &Query{}, nil
+	}
+	if err := validateNode(ast); err != nil {
+		return nil, err
+	}
+
+	q := &Query{AST: ast}
+	if isPureConjunction(ast) {
+		populateFlatFields(q, leafEntries(ast))
+	}
+	return q, nil
+}
+
+// validateNode checks that node's shape matches its Kind (the right one of
+// Leaf, Children, or Child set, and non-empty where that matters), since
+// Compile's ast comes from a caller rather than the grammar, which
+// guarantees this by construction.
+func validateNode(node *Node) error {
+	if node == nil {
+		return fmt.Errorf("search: nil node")
+	}
+	switch node.Kind {
+	case NodeLeaf:
+		if node.Leaf == nil {
+			return fmt.Errorf("search: leaf node has no Clause")
+		}
+	case NodeAnd, NodeOr:
+		if len(node.Children) == 0 {
+			return fmt.Errorf("search: %s node has no children", nodeKindName(node.Kind))
+		}
+		for _, c := range node.Children {
+			if err := validateNode(c); err != nil {
+				return err
+			}
+		}
+	case NodeNot:
+		if node.Child == nil {
+			return fmt.Errorf("search: NOT node has no child")
+		}
+		return validateNode(node.Child)
+	default:
+		return fmt.Errorf("search: unknown node kind %d", node.Kind)
+	}
+	return nil
+}
+
+// nodeKindName renders a NodeKind for error messages.
+func nodeKindName(k NodeKind) string {
+	switch k {
+	case NodeAnd:
+		return "AND"
+	case NodeOr:
+		return "OR"
+	case NodeNot:
+		return "NOT"
+	default:
+		return "leaf"
+	}
 }
 
 // Parse parses a Gmail-like search query string into a Query object.
@@ -51,86 +334,275 @@ func (q *Query) IsEmpty() bool {
 //   - before:, after: - date filters (YYYY-MM-DD)
 //   - older_than:, newer_than: - relative date filters (e.g., 7d, 2w, 1m, 1y)
 //   - larger:, smaller: - size filters (e.g., 5M, 100K)
+//   - is:read, is:unread, is:starred, is:replied, is:flagged - message state
+//   - thread:<id> - fetch an entire conversation
+//   - header:Name=Value - arbitrary header equality
+//   - mimetype:, attachment_type: - MIME type filters for the body/attachments
+//   - in:<mailbox> - mailbox/folder filter, distinct from label:
 //   - Bare words and "quoted phrases" - full-text search
+//
+// A value may carry a leading "="/"^"/"~" to pick its Clause.Match (e.g.
+// subject:="Weekly Report" for an exact match, subject:^Weekly for a
+// prefix match, subject:~report for an explicit contains match, the
+// default when no prefix is given).
+//
+// Supported boolean grammar:
+//   - AND (default between adjacent clauses) and explicit "AND"
+//   - OR, e.g. "from:alice OR from:bob"
+//   - NOT or a "-" prefix, e.g. "-label:archive"
+//   - Parenthesized groups, e.g. "(from:alice OR from:bob) subject:invoice"
 func Parse(queryStr string) *Query {
-	q := &Query{}
 	tokens := tokenize(queryStr)
+	if len(tokens) == 0 {
+		return &Query{}
+	}
 
-	for _, token := range tokens {
-		// Check if it's a quoted phrase
-		if strings.HasPrefix(token, "\"") && strings.HasSuffix(token, "\"") && len(token) > 2 {
-			q.TextTerms = append(q.TextTerms, token[1:len(token)-1])
+	pos := 0
+	ast := parseOrExpr(tokens, &pos)
+
+	q := &Query{AST: ast}
+	if isPureConjunction(ast) {
+		populateFlatFields(q, leafEntries(ast))
+	}
+	return q
+}
+
+// parseOrExpr parses `AndExpr (OR AndExpr)*`.
+func parseOrExpr(tokens []string, pos *int) *Node {
+	left := parseAndExpr(tokens, pos)
+	var children []*Node
+	for *pos < len(tokens) && strings.EqualFold(tokens[*pos], "OR") {
+		*pos++
+		children = append(children, parseAndExpr(tokens, pos))
+	}
+	if children == nil {
+		return left
+	}
+	return &Node{Kind: NodeOr, Children: append([]*Node{left}, children...)}
+}
+
+// parseAndExpr parses a run of NotExprs, implicitly ANDed, stopping at
+// ")", "OR", or end of input. A bare "AND" token is accepted and skipped.
+func parseAndExpr(tokens []string, pos *int) *Node {
+	var children []*Node
+	for *pos < len(tokens) {
+		tok := tokens[*pos]
+		if tok == ")" || strings.EqualFold(tok, "OR") {
+			break
+		}
+		if strings.EqualFold(tok, "AND") {
+			*pos++
 			continue
 		}
+		children = append(children, parseNotExpr(tokens, pos))
+	}
+	if len(children) == 0 {
+		// Empty group, e.g. "()" - no clauses to AND together. Fall back to
+		// an empty leaf (the same stand-in parseNotExpr uses when it runs
+		// off the end of tokens) rather than a childless AND, which
+		// validateNode rejects.
+		return &Node{Kind: NodeLeaf, Leaf: &Clause{}}
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &Node{Kind: NodeAnd, Children: children}
+}
+
+// parseNotExpr parses an optional "NOT" or "-" prefix applied to the next
+// NotExpr/Primary.
+func parseNotExpr(tokens []string, pos *int) *Node {
+	if *pos >= len(tokens) {
+		return &Node{Kind: NodeLeaf, Leaf: &Clause{}}
+	}
+
+	tok := tokens[*pos]
+	if strings.EqualFold(tok, "NOT") || tok == "-" {
+		*pos++
+		return &Node{Kind: NodeNot, Child: parseNotExpr(tokens, pos)}
+	}
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		tokens[*pos] = tok[1:]
+		return &Node{Kind: NodeNot, Child: parseNotExpr(tokens, pos)}
+	}
+	return parsePrimary(tokens, pos)
+}
+
+// parsePrimary parses a parenthesized group or a single leaf clause.
+func parsePrimary(tokens []string, pos *int) *Node {
+	if tokens[*pos] == "(" {
+		*pos++
+		node := parseOrExpr(tokens, pos)
+		if *pos < len(tokens) && tokens[*pos] == ")" {
+			*pos++
+		}
+		return node
+	}
 
-		// Check for operator:value pattern
-		if idx := strings.Index(token, ":"); idx != -1 {
-			op := strings.ToLower(token[:idx])
-			value := token[idx+1:]
+	tok := tokens[*pos]
+	*pos++
+	return &Node{Kind: NodeLeaf, Leaf: classifyToken(tok)}
+}
 
-			// Strip quotes from value
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = value[1 : len(value)-1]
+// classifyToken turns one raw token into a Clause: a quoted phrase or bare
+// word becomes a text clause (Op == ""), and an "op:value" token becomes a
+// clause for that operator. A value may carry a leading "="/"^"/"~" to pick
+// Match (exact, prefix, or contains); the prefix is stripped before the
+// surrounding-quote check so "subject:=\"Weekly Report\"" still yields the
+// unquoted value "Weekly Report".
+func classifyToken(token string) *Clause {
+	if strings.HasPrefix(token, "\"") && strings.HasSuffix(token, "\"") && len(token) > 2 {
+		return &Clause{Value: token[1 : len(token)-1], Raw: token}
+	}
+
+	if idx := strings.Index(token, ":"); idx != -1 {
+		op := strings.ToLower(token[:idx])
+		value := token[idx+1:]
+		match := MatchContains
+		if len(value) > 0 {
+			switch value[0] {
+			case '=':
+				match = MatchEquals
+				value = value[1:]
+			case '^':
+				match = MatchPrefix
+				value = value[1:]
+			case '~':
+				match = MatchContains
+				value = value[1:]
 			}
+		}
+		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+			value = value[1 : len(value)-1]
+		}
+		return &Clause{Op: op, Value: value, Raw: token, Match: match}
+	}
+
+	return &Clause{Value: token, Raw: token}
+}
 
-			switch op {
+// populateFlatFields fills in q's legacy flat slice/pointer fields from a
+// pure-conjunction AST's leaf entries, matching Parse's pre-AST behavior for
+// plain (non-negated) clauses exactly. Negated from/to/label/domain clauses
+// route to the matching Exclude* field instead; negated clauses with any
+// other operator are dropped, since there's no flat field to exclude them
+// from (the AST remains available for callers that need to handle those).
+func populateFlatFields(q *Query, entries []leafEntry) {
+	for _, e := range entries {
+		c := e.clause
+		if e.negated {
+			switch c.Op {
 			case "from":
-				q.FromAddrs = append(q.FromAddrs, strings.ToLower(value))
+				q.ExcludeFromAddrs = append(q.ExcludeFromAddrs, strings.ToLower(c.Value))
 			case "to":
-				q.ToAddrs = append(q.ToAddrs, strings.ToLower(value))
-			case "cc":
-				q.CcAddrs = append(q.CcAddrs, strings.ToLower(value))
-			case "bcc":
-				q.BccAddrs = append(q.BccAddrs, strings.ToLower(value))
-			case "subject":
-				q.SubjectTerms = append(q.SubjectTerms, value)
+				q.ExcludeToAddrs = append(q.ExcludeToAddrs, strings.ToLower(c.Value))
 			case "label", "l":
-				q.Labels = append(q.Labels, value)
+				q.ExcludeLabels = append(q.ExcludeLabels, c.Value)
+			case "domain":
+				q.ExcludeDomains = append(q.ExcludeDomains, strings.ToLower(c.Value))
 			case "has":
-				if strings.ToLower(value) == "attachment" || strings.ToLower(value) == "attachments" {
-					b := true
+				if strings.ToLower(c.Value) == "attachment" || strings.ToLower(c.Value) == "attachments" {
+					b := false
 					q.HasAttachment = &b
 				}
-			case "before":
-				if t := parseDate(value); t != nil {
-					q.BeforeDate = t
-				}
-			case "after":
-				if t := parseDate(value); t != nil {
-					q.AfterDate = t
-				}
-			case "older_than":
-				if t := parseRelativeDate(value); t != nil {
-					q.BeforeDate = t
-				}
-			case "newer_than":
-				if t := parseRelativeDate(value); t != nil {
-					q.AfterDate = t
-				}
-			case "larger":
-				if size := parseSize(value); size != nil {
-					q.LargerThan = size
-				}
-			case "smaller":
-				if size := parseSize(value); size != nil {
-					q.SmallerThan = size
-				}
-			default:
-				// Unknown operator - treat as text
-				q.TextTerms = append(q.TextTerms, token)
 			}
 			continue
 		}
 
-		// Not an operator - treat as text search term
-		q.TextTerms = append(q.TextTerms, token)
+		switch c.Op {
+		case "":
+			q.TextTerms = append(q.TextTerms, c.Value)
+		case "from":
+			q.FromAddrs = append(q.FromAddrs, strings.ToLower(c.Value))
+		case "to":
+			q.ToAddrs = append(q.ToAddrs, strings.ToLower(c.Value))
+		case "cc":
+			q.CcAddrs = append(q.CcAddrs, strings.ToLower(c.Value))
+		case "bcc":
+			q.BccAddrs = append(q.BccAddrs, strings.ToLower(c.Value))
+		case "subject":
+			q.SubjectTerms = append(q.SubjectTerms, c.Value)
+		case "label", "l":
+			q.Labels = append(q.Labels, c.Value)
+		case "domain":
+			q.Domains = append(q.Domains, strings.ToLower(c.Value))
+		case "has":
+			if strings.ToLower(c.Value) == "attachment" || strings.ToLower(c.Value) == "attachments" {
+				b := true
+				q.HasAttachment = &b
+			}
+		case "before":
+			if t := parseDate(c.Value); t != nil {
+				q.BeforeDate = t
+			}
+		case "after":
+			if t := parseDate(c.Value); t != nil {
+				q.AfterDate = t
+			}
+		case "older_than":
+			if t := parseRelativeDate(c.Value); t != nil {
+				q.BeforeDate = t
+			}
+		case "newer_than":
+			if t := parseRelativeDate(c.Value); t != nil {
+				q.AfterDate = t
+			}
+		case "larger":
+			if size := parseSize(c.Value); size != nil {
+				q.LargerThan = size
+			}
+		case "smaller":
+			if size := parseSize(c.Value); size != nil {
+				q.SmallerThan = size
+			}
+		case "is":
+			applyIsFlag(q, strings.ToLower(c.Value))
+		case "thread":
+			q.ThreadID = c.Value
+		case "header":
+			name, value, ok := strings.Cut(c.Value, "=")
+			if ok {
+				if q.Headers == nil {
+					q.Headers = make(map[string][]string)
+				}
+				q.Headers[name] = append(q.Headers[name], value)
+			}
+		case "mimetype":
+			q.MimeTypes = append(q.MimeTypes, strings.ToLower(c.Value))
+		case "attachment_type":
+			q.AttachmentTypes = append(q.AttachmentTypes, strings.ToLower(c.Value))
+		case "in":
+			q.Mailboxes = append(q.Mailboxes, c.Value)
+		default:
+			// Unknown operator - treat as text, same as the original parser.
+			q.TextTerms = append(q.TextTerms, c.Raw)
+		}
 	}
+}
 
-	return q
+// applyIsFlag sets the Query tri-state flag matching an is:<value>
+// operator (is:read, is:unread, is:starred, is:replied, is:flagged).
+// Unrecognized values are ignored, matching how other operators silently
+// drop values they can't parse (e.g. an unparseable before: date).
+func applyIsFlag(q *Query, value string) {
+	yes := true
+	switch value {
+	case "read":
+		q.IsRead = &yes
+	case "unread":
+		q.IsUnread = &yes
+	case "starred":
+		q.IsStarred = &yes
+	case "replied":
+		q.IsReplied = &yes
+	case "flagged":
+		q.IsFlagged = &yes
+	}
 }
 
-// tokenize splits a query string, preserving quoted phrases and operator:value pairs.
-// Handles cases like subject:"foo bar" where the operator and quoted value should stay together.
+// tokenize splits a query string into words, quoted phrases, operator:value
+// pairs, and standalone "(" / ")" tokens for grouping. Handles cases like
+// subject:"foo bar" where the operator and quoted value stay together.
 func tokenize(queryStr string) []string {
 	var tokens []string
 	var current strings.Builder
@@ -142,7 +614,8 @@ func tokenize(queryStr string) []string {
 	opQuoted := false
 
 	for _, char := range queryStr {
-		if (char == '"' || char == '\'') && !inQuotes {
+		switch {
+		case (char == '"' || char == '\'') && !inQuotes:
 			// Start of quoted section
 			inQuotes = true
 			quoteChar = char
@@ -158,7 +631,7 @@ func tokenize(queryStr string) []string {
 				current.WriteRune(char)
 			}
 			afterColon = false
-		} else if char == quoteChar && inQuotes {
+		case char == quoteChar && inQuotes:
 			// End of quoted section
 			inQuotes = false
 			// Check if this was an op:"value" case (quote started after colon)
@@ -174,15 +647,31 @@ func tokenize(queryStr string) []string {
 			}
 			quoteChar = 0
 			opQuoted = false
-		} else if char == ' ' && !inQuotes {
+		case (char == '(' || char == ')') && !inQuotes:
 			if current.Len() > 0 {
 				tokens = append(tokens, current.String())
 				current.Reset()
 			}
+			tokens = append(tokens, string(char))
 			afterColon = false
-		} else {
+		case char == ' ' && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			afterColon = false
+		default:
 			current.WriteRune(char)
-			afterColon = (char == ':')
+			switch {
+			case char == ':':
+				afterColon = true
+			case afterColon && (char == '=' || char == '^' || char == '~'):
+				// Match-type prefix right after the colon (e.g. subject:=,
+				// subject:^, subject:~): stay "after colon" so a quote
+				// that follows is still treated as op:"value".
+			default:
+				afterColon = false
+			}
 		}
 	}
 
@@ -193,6 +682,27 @@ func tokenize(queryStr string) []string {
 	return tokens
 }
 
+// ParseDate parses a before:/after: value the same way Parse does. Exported
+// for compilers that work from a Clause's raw Value directly - e.g.
+// search/imap, which walks AST nodes that haven't gone through
+// populateFlatFields - instead of Query's already-parsed BeforeDate/
+// AfterDate fields.
+func ParseDate(value string) *time.Time {
+	return parseDate(value)
+}
+
+// ParseRelativeDate parses an older_than:/newer_than: value the same way
+// Parse does. See ParseDate.
+func ParseRelativeDate(value string) *time.Time {
+	return parseRelativeDate(value)
+}
+
+// ParseSize parses a larger:/smaller: value the same way Parse does. See
+// ParseDate.
+func ParseSize(value string) *int64 {
+	return parseSize(value)
+}
+
 // parseDate parses date strings like YYYY-MM-DD or YYYY/MM/DD.
 func parseDate(value string) *time.Time {
 	formats := []string{
@@ -209,37 +719,179 @@ func parseDate(value string) *time.Time {
 			return &t
 		}
 	}
-	return nil
+
+	// before:/after: also accept the same natural-language phrases as
+	// older_than:/newer_than: (e.g. "yesterday", "last monday"), so users
+	// don't have to remember which keyword takes a relative phrase.
+	return parseNaturalRelativeDate(strings.ToLower(value))
+}
+
+// shorthandRelativeDateRe matches the original 7d/2w/1m/1y shorthand:
+// amount plus a single-letter unit.
+var shorthandRelativeDateRe = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// relativeDateUnits canonicalizes a natural-language unit word (singular or
+// plural) to the single-letter units parseShorthandRelativeDate and
+// addRelativeUnit both key off.
+var relativeDateUnits = map[string]string{
+	"second": "s", "seconds": "s",
+	"minute": "min", "minutes": "min",
+	"hour": "h", "hours": "h",
+	"day": "d", "days": "d",
+	"week": "w", "weeks": "w",
+	"month": "m", "months": "m",
+	"year": "y", "years": "y",
 }
 
-// parseRelativeDate parses relative dates like 7d, 2w, 1m, 1y.
+var relativeDateWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseRelativeDate parses a before:/after:/older_than:/newer_than: value
+// into a point in time relative to now: the original 7d/2w/1m/1y shorthand,
+// or a natural-language phrase like "yesterday", "3 hours ago", "last
+// monday", "next week", or "beginning of year". Returns nil for anything
+// else (including ambiguous phrasing this resolver doesn't recognize), so
+// callers keep their existing "filter silently ignored" fallback.
 func parseRelativeDate(value string) *time.Time {
 	value = strings.TrimSpace(strings.ToLower(value))
-	re := regexp.MustCompile(`^(\d+)([dwmy])$`)
-	match := re.FindStringSubmatch(value)
-	if match == nil {
-		return nil
+
+	if match := shorthandRelativeDateRe.FindStringSubmatch(value); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		now := time.Now().UTC()
+		result := addRelativeUnit(now, match[2], -amount)
+		return &result
 	}
 
-	amount, _ := strconv.Atoi(match[1])
-	unit := match[2]
+	return parseNaturalRelativeDate(value)
+}
+
+// parseNaturalRelativeDate resolves the human-phrase forms parseRelativeDate
+// accepts beyond the 7d/2w/1m/1y shorthand. now is re-read (rather than
+// threaded in) so every named anchor below sees the same instant; value is
+// expected already trimmed and lowercased by the caller.
+func parseNaturalRelativeDate(value string) *time.Time {
 	now := time.Now().UTC()
 
-	var result time.Time
+	switch value {
+	case "now":
+		return &now
+	case "today":
+		t := truncateToDayUTC(now)
+		return &t
+	case "yesterday":
+		t := truncateToDayUTC(now).AddDate(0, 0, -1)
+		return &t
+	case "tomorrow":
+		t := truncateToDayUTC(now).AddDate(0, 0, 1)
+		return &t
+	}
+
+	fields := strings.Fields(value)
+
+	switch {
+	case len(fields) == 2 && fields[0] == "last":
+		if wd, ok := relativeDateWeekdays[fields[1]]; ok {
+			t := lastWeekdayUTC(now, wd)
+			return &t
+		}
+		if unit, ok := relativeDateUnits[fields[1]]; ok {
+			t := addRelativeUnit(now, unit, -1)
+			return &t
+		}
+
+	case len(fields) == 2 && fields[0] == "next":
+		if unit, ok := relativeDateUnits[fields[1]]; ok {
+			t := addRelativeUnit(now, unit, 1)
+			return &t
+		}
+
+	case len(fields) == 3 && fields[0] == "beginning" && fields[1] == "of":
+		if unit, ok := relativeDateUnits[fields[2]]; ok {
+			t := truncateToUnitUTC(now, unit)
+			return &t
+		}
+
+	case len(fields) == 3 && fields[2] == "ago":
+		if amount, err := strconv.Atoi(fields[0]); err == nil {
+			if unit, ok := relativeDateUnits[fields[1]]; ok {
+				t := addRelativeUnit(now, unit, -amount)
+				return &t
+			}
+		}
+
+	case len(fields) == 3 && fields[0] == "in":
+		if amount, err := strconv.Atoi(fields[1]); err == nil {
+			if unit, ok := relativeDateUnits[fields[2]]; ok {
+				t := addRelativeUnit(now, unit, amount)
+				return &t
+			}
+		}
+	}
+
+	return nil
+}
+
+// addRelativeUnit adds amount of unit ("s"/"min"/"h"/"d"/"w"/"m"/"y") to t,
+// a negative amount moving into the past. Calendar units (d/w/m/y) use
+// AddDate so they respect month lengths and leap years instead of
+// approximating with a fixed duration.
+func addRelativeUnit(t time.Time, unit string, amount int) time.Time {
 	switch unit {
+	case "s":
+		return t.Add(time.Duration(amount) * time.Second)
+	case "min":
+		return t.Add(time.Duration(amount) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(amount) * time.Hour)
 	case "d":
-		result = now.AddDate(0, 0, -amount)
+		return t.AddDate(0, 0, amount)
 	case "w":
-		result = now.AddDate(0, 0, -amount*7)
+		return t.AddDate(0, 0, amount*7)
 	case "m":
-		result = now.AddDate(0, -amount, 0)
+		return t.AddDate(0, amount, 0)
 	case "y":
-		result = now.AddDate(-amount, 0, 0)
+		return t.AddDate(amount, 0, 0)
 	default:
-		return nil
+		return t
+	}
+}
+
+// truncateToDayUTC returns t's calendar day at UTC midnight.
+func truncateToDayUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncateToUnitUTC returns the start of the calendar unit ("d"/"w"/"m"/"y")
+// containing t, for "beginning of <unit>" phrases. A week starts on Monday.
+func truncateToUnitUTC(t time.Time, unit string) time.Time {
+	switch unit {
+	case "y":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "m":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "w":
+		day := truncateToDayUTC(t)
+		offset := (int(day.Weekday()) + 6) % 7 // Monday == 0
+		return day.AddDate(0, 0, -offset)
+	default:
+		return truncateToDayUTC(t)
 	}
+}
 
-	return &result
+// lastWeekdayUTC returns the most recent occurrence of wd strictly before
+// from's calendar day, for "last <weekday>" phrases.
+func lastWeekdayUTC(from time.Time, wd time.Weekday) time.Time {
+	day := truncateToDayUTC(from)
+	for i := 0; i < 7; i++ {
+		day = day.AddDate(0, 0, -1)
+		if day.Weekday() == wd {
+			return day
+		}
+	}
+	return day
 }
 
 // parseSize parses size strings like 5M, 100K, 1G into bytes.
@@ -177,6 +177,32 @@ func TestParse_Subject(t *testing.T) {
 	}
 }
 
+func TestParse_SubjectMatchType(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantValue string
+		wantMatch MatchType
+	}{
+		{"plain defaults to contains", `subject:Weekly`, "Weekly", MatchContains},
+		{"explicit contains", `subject:~Weekly`, "Weekly", MatchContains},
+		{"prefix", `subject:^Weekly`, "Weekly", MatchPrefix},
+		{"exact quoted", `subject:="Weekly Report"`, "Weekly Report", MatchEquals},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Parse(tt.query)
+			if q.AST == nil || q.AST.Leaf == nil {
+				t.Fatalf("AST: got %+v, want a single leaf clause", q.AST)
+			}
+			leaf := q.AST.Leaf
+			if leaf.Value != tt.wantValue || leaf.Match != tt.wantMatch {
+				t.Errorf("got Value=%q Match=%v, want Value=%q Match=%v", leaf.Value, leaf.Match, tt.wantValue, tt.wantMatch)
+			}
+		})
+	}
+}
+
 func TestParse_QuotedOperatorValue(t *testing.T) {
 	// Test that subject:"foo bar" keeps the quoted phrase with the operator
 	tests := []struct {
@@ -376,3 +402,300 @@ func TestParse_QuotedPhraseWithColon(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_TriageOperators(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		check func(t *testing.T, q *Query)
+	}{
+		{
+			name:  "is:unread",
+			query: "is:unread",
+			check: func(t *testing.T, q *Query) {
+				if q.IsUnread == nil || !*q.IsUnread {
+					t.Error("IsUnread: expected true")
+				}
+				if q.IsRead != nil {
+					t.Error("IsRead: expected nil")
+				}
+			},
+		},
+		{
+			name:  "is:starred",
+			query: "is:starred",
+			check: func(t *testing.T, q *Query) {
+				if q.IsStarred == nil || !*q.IsStarred {
+					t.Error("IsStarred: expected true")
+				}
+			},
+		},
+		{
+			name:  "thread id",
+			query: "thread:abc123",
+			check: func(t *testing.T, q *Query) {
+				if q.ThreadID != "abc123" {
+					t.Errorf("ThreadID: got %q, want %q", q.ThreadID, "abc123")
+				}
+			},
+		},
+		{
+			name:  "header equality",
+			query: "header:X-Spam-Flag=YES",
+			check: func(t *testing.T, q *Query) {
+				if got := q.Headers["X-Spam-Flag"]; len(got) != 1 || got[0] != "YES" {
+					t.Errorf("Headers[X-Spam-Flag]: got %v, want [YES]", got)
+				}
+			},
+		},
+		{
+			name:  "mimetype",
+			query: "mimetype:text/html",
+			check: func(t *testing.T, q *Query) {
+				if len(q.MimeTypes) != 1 || q.MimeTypes[0] != "text/html" {
+					t.Errorf("MimeTypes: got %v", q.MimeTypes)
+				}
+			},
+		},
+		{
+			name:  "attachment_type",
+			query: "attachment_type:application/pdf",
+			check: func(t *testing.T, q *Query) {
+				if len(q.AttachmentTypes) != 1 || q.AttachmentTypes[0] != "application/pdf" {
+					t.Errorf("AttachmentTypes: got %v", q.AttachmentTypes)
+				}
+			},
+		},
+		{
+			name:  "in mailbox distinct from label",
+			query: "in:Archive label:receipts",
+			check: func(t *testing.T, q *Query) {
+				if len(q.Mailboxes) != 1 || q.Mailboxes[0] != "Archive" {
+					t.Errorf("Mailboxes: got %v", q.Mailboxes)
+				}
+				if len(q.Labels) != 1 || q.Labels[0] != "receipts" {
+					t.Errorf("Labels: got %v", q.Labels)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, Parse(tt.query))
+		})
+	}
+}
+
+func TestParse_PureConjunctionPopulatesFlatFields(t *testing.T) {
+	q := Parse(`from:alice@example.com subject:invoice hello`)
+	if q.AST == nil {
+		t.Fatal("AST: expected non-nil")
+	}
+	if len(q.FromAddrs) != 1 || q.FromAddrs[0] != "alice@example.com" {
+		t.Errorf("FromAddrs: got %v", q.FromAddrs)
+	}
+	if len(q.SubjectTerms) != 1 || q.SubjectTerms[0] != "invoice" {
+		t.Errorf("SubjectTerms: got %v", q.SubjectTerms)
+	}
+	if len(q.TextTerms) != 1 || q.TextTerms[0] != "hello" {
+		t.Errorf("TextTerms: got %v", q.TextTerms)
+	}
+}
+
+func TestParse_OrGroupLeavesFlatFieldsEmpty(t *testing.T) {
+	q := Parse(`(from:alice@example.com OR from:bob@example.com) subject:invoice`)
+	if q.AST == nil {
+		t.Fatal("AST: expected non-nil")
+	}
+	if q.IsEmpty() {
+		t.Error("IsEmpty() = true for a non-empty OR query")
+	}
+	// OR makes this an impure conjunction, so the legacy flat fields are
+	// left unpopulated - callers must walk AST instead.
+	if len(q.FromAddrs) != 0 {
+		t.Errorf("FromAddrs: got %v, want empty (query isn't a pure conjunction)", q.FromAddrs)
+	}
+
+	got := leafValues(t, q.AST)
+	want := []string{"from:alice@example.com", "from:bob@example.com", "subject:invoice"}
+	if len(got) != len(want) {
+		t.Fatalf("leaf count: got %v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("leaf[%d]: got %q, want %q", i, got[i], w)
+		}
+	}
+}
+
+func TestParse_Negation(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"dash prefix", `subject:invoice -label:archive`},
+		{"NOT keyword", `subject:invoice NOT label:archive`},
+		{"dash before quoted phrase", `-"do not want this"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Parse(tt.query)
+			if q.AST == nil {
+				t.Fatal("AST: expected non-nil")
+			}
+			if !containsNot(q.AST) {
+				t.Errorf("AST for %q: expected a NodeNot somewhere in the tree", tt.query)
+			}
+		})
+	}
+}
+
+func TestParse_ParenthesizedGroup(t *testing.T) {
+	q := Parse(`(from:alice@example.com OR from:bob@example.com)`)
+	if q.AST == nil || q.AST.Kind != NodeOr {
+		t.Fatalf("AST: got %+v, want a top-level NodeOr", q.AST)
+	}
+	if len(q.AST.Children) != 2 {
+		t.Fatalf("AST.Children: got %d, want 2", len(q.AST.Children))
+	}
+}
+
+// leafValues returns the Value of every leaf Clause in node, in order, for
+// assertions that don't want to care whether it's "op:value" or bare text.
+func leafValues(t *testing.T, node *Node) []string {
+	t.Helper()
+	var out []string
+	for _, c := range leaves(node) {
+		if c.Op != "" {
+			out = append(out, c.Op+":"+c.Value)
+		} else {
+			out = append(out, c.Value)
+		}
+	}
+	return out
+}
+
+func TestParse_NegatedGroup(t *testing.T) {
+	q := Parse(`-(from:alice@example.com OR from:bob@example.com)`)
+	if q.AST == nil || q.AST.Kind != NodeNot {
+		t.Fatalf("AST: got %+v, want a top-level NodeNot", q.AST)
+	}
+	if q.AST.Child == nil || q.AST.Child.Kind != NodeOr {
+		t.Fatalf("AST.Child: got %+v, want a NodeOr", q.AST.Child)
+	}
+	// NOT wraps a group here, not a single leaf, so this isn't a pure
+	// conjunction and the legacy Exclude* fields stay empty.
+	if len(q.ExcludeFromAddrs) != 0 {
+		t.Errorf("ExcludeFromAddrs: got %v, want empty for a negated group", q.ExcludeFromAddrs)
+	}
+}
+
+func TestParse_NestedGroupWithOr(t *testing.T) {
+	q := Parse(`(from:alice@example.com AND has:attachment) OR label:urgent`)
+	if q.AST == nil || q.AST.Kind != NodeOr {
+		t.Fatalf("AST: got %+v, want a top-level NodeOr", q.AST)
+	}
+	if len(q.AST.Children) != 2 {
+		t.Fatalf("AST.Children: got %d, want 2", len(q.AST.Children))
+	}
+	if q.AST.Children[0].Kind != NodeAnd {
+		t.Fatalf("AST.Children[0]: got %+v, want a NodeAnd", q.AST.Children[0])
+	}
+	got := leafValues(t, q.AST.Children[0])
+	want := []string{"from:alice@example.com", "has:attachment"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("AST.Children[0] leaves: got %v, want %v", got, want)
+	}
+}
+
+func containsNot(node *Node) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Kind {
+	case NodeNot:
+		return true
+	case NodeAnd, NodeOr:
+		for _, c := range node.Children {
+			if containsNot(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestParseRelativeDate_NaturalLanguage(t *testing.T) {
+	now := time.Now().UTC()
+
+	cases := []struct {
+		name  string
+		value string
+		check func(t *testing.T, got *time.Time)
+	}{
+		{"yesterday", "yesterday", func(t *testing.T, got *time.Time) {
+			want := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+			if !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		}},
+		{"last monday", "last monday", func(t *testing.T, got *time.Time) {
+			if got.Weekday() != time.Monday {
+				t.Errorf("got weekday %v, want Monday", got.Weekday())
+			}
+			if !got.Before(now) {
+				t.Errorf("got %v, want strictly before now %v", got, now)
+			}
+		}},
+		{"3 hours ago", "3 hours ago", func(t *testing.T, got *time.Time) {
+			want := now.Add(-3 * time.Hour)
+			if got.Sub(want) > time.Minute || want.Sub(*got) > time.Minute {
+				t.Errorf("got %v, want close to %v", got, want)
+			}
+		}},
+		{"2 weeks ago", "2 weeks ago", func(t *testing.T, got *time.Time) {
+			want := now.AddDate(0, 0, -14)
+			if got.Sub(want) > time.Minute || want.Sub(*got) > time.Minute {
+				t.Errorf("got %v, want close to %v", got, want)
+			}
+		}},
+		{"beginning of year", "beginning of year", func(t *testing.T, got *time.Time) {
+			want := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+			if !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseRelativeDate(tc.value)
+			if got == nil {
+				t.Fatalf("parseRelativeDate(%q): got nil", tc.value)
+			}
+			tc.check(t, got)
+		})
+	}
+}
+
+func TestParseRelativeDate_AmbiguousReturnsNil(t *testing.T) {
+	for _, value := range []string{"sometime soon", "last", "3 ago", "fortnight"} {
+		if got := parseRelativeDate(value); got != nil {
+			t.Errorf("parseRelativeDate(%q): got %v, want nil", value, got)
+		}
+	}
+}
+
+func TestParseDate_AcceptsNaturalLanguage(t *testing.T) {
+	got := parseDate("yesterday")
+	if got == nil {
+		t.Fatal("parseDate(\"yesterday\"): got nil")
+	}
+	now := time.Now().UTC()
+	want := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	if !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package search
+
+import "context"
+
+// ChangeEvent is one message-store mutation a Reindexer applies to its
+// Index: Doc is indexed under MessageID (an insert or update), or, when
+// Deleted is true, MessageID is removed and Doc is ignored.
+type ChangeEvent struct {
+	MessageID int64
+	Doc       Document
+	Deleted   bool
+}
+
+// Reindexer applies a stream of ChangeEvents to an Index in the
+// background, so a message-store write doesn't block on reindexing it.
+// The producer side - watching inserts/updates/deletes and emitting
+// ChangeEvents - is store-specific and lives with the caller; Reindexer
+// only owns the consume loop.
+type Reindexer struct {
+	index  Index
+	events <-chan ChangeEvent
+	done   chan struct{}
+	errs   chan error
+}
+
+// NewReindexer returns a Reindexer that applies events from events to
+// index once Start is called.
+func NewReindexer(index Index, events <-chan ChangeEvent) *Reindexer {
+	return &Reindexer{
+		index:  index,
+		events: events,
+		done:   make(chan struct{}),
+		errs:   make(chan error, 1),
+	}
+}
+
+// Start runs the consume loop in a goroutine until events closes or ctx is
+// cancelled. Call Stop to wait for it to finish.
+func (r *Reindexer) Start(ctx context.Context) {
+	go func() {
+		defer close(r.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-r.events:
+				if !ok {
+					return
+				}
+				r.apply(ev)
+			}
+		}
+	}()
+}
+
+func (r *Reindexer) apply(ev ChangeEvent) {
+	var err error
+	if ev.Deleted {
+		err = r.index.Delete(ev.MessageID)
+	} else {
+		err = r.index.Add(ev.MessageID, ev.Doc)
+	}
+	if err != nil {
+		select {
+		case r.errs <- err:
+		default:
+			// A prior error is already queued; Err only ever surfaces the
+			// first one; the reindexer keeps draining events past it.
+		}
+	}
+}
+
+// Stop waits for the consume loop to drain events and exit (after events
+// closes or the ctx passed to Start is cancelled).
+func (r *Reindexer) Stop() {
+	<-r.done
+}
+
+// Err returns the first indexing error the consume loop hit, if any. Only
+// meaningful after Stop returns.
+func (r *Reindexer) Err() error {
+	select {
+	case err := <-r.errs:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SavedQueryLookup resolves a name used in a saved:<name> clause to the raw
+// query string it stands for, plus whether name is known at all.
+//
+// Nothing in this package persists saved queries - search stays
+// storage-agnostic the same way it does for full-text indexing (see
+// RegisterBackend). SetSavedQueryLookup wires in whatever does, e.g.
+// query.SavedQueries backed by the vault's SQLite metadata database.
+type SavedQueryLookup func(name string) (raw string, ok bool)
+
+// SavedQuerySaver persists a named query string. See SavedQueryLookup.
+type SavedQuerySaver func(name, raw string) error
+
+var (
+	savedQueryLookup SavedQueryLookup
+	savedQuerySaver  SavedQuerySaver
+)
+
+// SetSavedQueryLookup installs the function Resolve and saved:<name>
+// expansion use to look up a name's raw query string.
+func SetSavedQueryLookup(lookup SavedQueryLookup) {
+	savedQueryLookup = lookup
+}
+
+// SetSavedQuerySaver installs the function SaveNamed uses to persist a
+// named query string.
+func SetSavedQuerySaver(saver SavedQuerySaver) {
+	savedQuerySaver = saver
+}
+
+// SaveNamed persists raw under name via the saver installed with
+// SetSavedQuerySaver. Returns an error if none has been installed.
+func SaveNamed(name, raw string) error {
+	if savedQuerySaver == nil {
+		return fmt.Errorf("search: save %q: no saver installed (see SetSavedQuerySaver)", name)
+	}
+	return savedQuerySaver(name, raw)
+}
+
+// Resolve looks up name via the lookup installed with SetSavedQueryLookup,
+// parses its query string, and expands any saved:<name> clauses it
+// contains in turn, failing on a cycle (name reappearing in its own
+// expansion chain) or an unknown name.
+func Resolve(name string) (*Query, error) {
+	if savedQueryLookup == nil {
+		return nil, fmt.Errorf("search: resolve %q: no lookup installed (see SetSavedQueryLookup)", name)
+	}
+	ast, err := resolveSaved(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(ast)
+}
+
+// resolveSaved looks up name, parses its raw query string, and expands any
+// saved:<name> clauses the result contains. chain is the names resolved so
+// far on this path, for cycle detection.
+func resolveSaved(name string, chain []string) (*Node, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("search: saved query cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+	raw, ok := savedQueryLookup(name)
+	if !ok {
+		return nil, fmt.Errorf("search: no saved query named %q", name)
+	}
+	return expandSaved(Parse(raw).AST, append(chain, name))
+}
+
+// expandSaved walks node, replacing every saved:<name> leaf with name's own
+// (recursively expanded) AST. A nil node (an empty query) expands to nil.
+func expandSaved(node *Node, chain []string) (*Node, error) {
+	if node == nil {
+		return nil, nil
+	}
+	switch node.Kind {
+	case NodeLeaf:
+		if node.Leaf == nil || node.Leaf.Op != "saved" {
+			return node, nil
+		}
+		return resolveSaved(node.Leaf.Value, chain)
+	case NodeNot:
+		child, err := expandSaved(node.Child, chain)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: NodeNot, Child: child}, nil
+	default: // NodeAnd, NodeOr
+		children := make([]*Node, len(node.Children))
+		for i, c := range node.Children {
+			expanded, err := expandSaved(c, chain)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = expanded
+		}
+		return &Node{Kind: node.Kind, Children: children}, nil
+	}
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BlobStore is a content-addressed store for attachment bytes, sharded two
+// directory levels deep by hex hash prefix
+// (<root>/<hash[0:2]>/<hash[2:4]>/<hash>) so no single directory ever holds
+// more than 1/65536th of the corpus - the layout mox uses for its message
+// store. Unlike blobstore.LocalAttachmentStore, which takes an
+// already-computed digest, BlobStore computes the SHA-256 itself while
+// streaming the reader to disk, and tracks how many attachment rows
+// reference each hash in the blob_refs table so Delete can reclaim a blob
+// only once nothing references it anymore.
+type BlobStore struct {
+	db   *sql.DB
+	root string
+}
+
+// NewBlobStore creates (if needed) the sharded directory tree rooted at
+// dir and the blob_refs table in db, and returns a BlobStore backed by
+// both.
+func NewBlobStore(db *sql.DB, dir string) (*BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("store: blobstore mkdir %s: %w", dir, err)
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS blob_refs (
+		hash TEXT PRIMARY KEY,
+		refcount INTEGER NOT NULL DEFAULT 0,
+		size_bytes INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("store: create blob_refs: %w", err)
+	}
+	return &BlobStore{db: db, root: dir}, nil
+}
+
+func (b *BlobStore) path(hash string) string {
+	return filepath.Join(b.root, hash[0:2], hash[2:4], hash)
+}
+
+// Put streams r to a temp file while hashing it, then renames it into its
+// content-addressed path and records a reference in blob_refs. If a blob
+// with the resulting hash already exists, Put discards the temp file and
+// bumps its refcount instead of writing a duplicate - this is the dedup
+// path, e.g. for the same attachment arriving on two different messages or
+// even two different sources.
+func (b *BlobStore) Put(r io.Reader) (hash string, size int64, err error) {
+	tmp, err := os.CreateTemp(b.root, "blob-*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("store: blobstore create temp: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		_ = tmp.Close()
+		return "", 0, fmt.Errorf("store: blobstore write %s: %w", tmpName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, fmt.Errorf("store: blobstore close %s: %w", tmpName, err)
+	}
+
+	hash = hex.EncodeToString(h.Sum(nil))
+	dest := b.path(hash)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", 0, fmt.Errorf("store: blobstore mkdir: %w", err)
+	}
+	if _, statErr := os.Stat(dest); statErr != nil {
+		if err := os.Rename(tmpName, dest); err != nil {
+			return "", 0, fmt.Errorf("store: blobstore rename %s: %w", tmpName, err)
+		}
+	}
+
+	_, err = b.db.Exec(
+		`INSERT INTO blob_refs (hash, refcount, size_bytes) VALUES (?, 1, ?)
+		 ON CONFLICT(hash) DO UPDATE SET refcount = refcount + 1`,
+		hash, n)
+	if err != nil {
+		return "", 0, fmt.Errorf("store: blobstore ref %s: %w", hash, err)
+	}
+	return hash, n, nil
+}
+
+// Open returns a reader for the blob addressed by hash.
+func (b *BlobStore) Open(hash string) (io.ReadCloser, error) {
+	f, err := os.Open(b.path(hash))
+	if err != nil {
+		return nil, fmt.Errorf("store: blobstore open %s: %w", hash, err)
+	}
+	return f, nil
+}
+
+// Delete decrements hash's refcount and, once it reaches zero, removes
+// both the blob_refs row and the on-disk file. Deleting a hash that still
+// has other referrers only decrements the count and leaves the file in
+// place.
+func (b *BlobStore) Delete(hash string) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return fmt.Errorf("store: blobstore begin delete %s: %w", hash, err)
+	}
+	defer tx.Rollback()
+
+	var refcount int64
+	err = tx.QueryRow(`SELECT refcount FROM blob_refs WHERE hash = ?`, hash).Scan(&refcount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store: blobstore lookup %s: %w", hash, err)
+	}
+	refcount--
+	if _, err := tx.Exec(`UPDATE blob_refs SET refcount = ? WHERE hash = ?`, refcount, hash); err != nil {
+		return fmt.Errorf("store: blobstore decrement %s: %w", hash, err)
+	}
+
+	if refcount <= 0 {
+		if _, err := tx.Exec(`DELETE FROM blob_refs WHERE hash = ?`, hash); err != nil {
+			return fmt.Errorf("store: blobstore delete ref %s: %w", hash, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: blobstore commit delete %s: %w", hash, err)
+	}
+
+	if refcount <= 0 {
+		if err := os.Remove(b.path(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("store: blobstore remove %s: %w", hash, err)
+		}
+	}
+	return nil
+}
+
+// VerifyReport summarizes a BlobStore.Verify scan: hashes recorded in
+// blob_refs whose file is gone, and hashes whose file exists but no longer
+// hashes back to its own name (bit rot or a truncated write).
+type VerifyReport struct {
+	Scanned int
+	Missing []string
+	Corrupt []string
+}
+
+// Verify scans every row in blob_refs and confirms its file exists and
+// still hashes to the name it's stored under, reporting any that don't
+// without modifying the store.
+func (b *BlobStore) Verify(ctx context.Context) (*VerifyReport, error) {
+	rows, err := b.db.QueryContext(ctx, `SELECT hash FROM blob_refs`)
+	if err != nil {
+		return nil, fmt.Errorf("store: blobstore verify query: %w", err)
+	}
+	defer rows.Close()
+
+	report := &VerifyReport{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("store: blobstore verify scan: %w", err)
+		}
+		report.Scanned++
+
+		f, err := os.Open(b.path(hash))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				report.Missing = append(report.Missing, hash)
+				continue
+			}
+			return nil, fmt.Errorf("store: blobstore verify open %s: %w", hash, err)
+		}
+		h := sha256.New()
+		_, copyErr := io.Copy(h, f)
+		_ = f.Close()
+		if copyErr != nil {
+			return nil, fmt.Errorf("store: blobstore verify read %s: %w", hash, copyErr)
+		}
+		if hex.EncodeToString(h.Sum(nil)) != hash {
+			report.Corrupt = append(report.Corrupt, hash)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: blobstore verify rows: %w", err)
+	}
+	return report, rows.Close()
+}
+
+// MigrateLegacyBlob moves a blob that predates the sharded layout - found
+// directly under dir, or under a single-level dir/<hash[:2]>/<hash> shard
+// as blobstore.LocalAttachmentStore lays out attachments - into BlobStore's
+// two-level sharded path, registering it in blob_refs with refcount 0 so a
+// subsequent Put for the same hash dedupes against it rather than writing
+// a second copy. Callers run this once per legacy file as part of an
+// on-disk migration; it is a no-op if the file is already at its sharded
+// destination.
+func (b *BlobStore) MigrateLegacyBlob(legacyPath, hash string, size int64) error {
+	dest := b.path(hash)
+	if legacyPath == dest {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return fmt.Errorf("store: blobstore migrate mkdir: %w", err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		if err := os.Rename(legacyPath, dest); err != nil {
+			return fmt.Errorf("store: blobstore migrate rename %s: %w", legacyPath, err)
+		}
+	} else {
+		if err := os.Remove(legacyPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("store: blobstore migrate remove dup %s: %w", legacyPath, err)
+		}
+	}
+
+	_, err := b.db.Exec(
+		`INSERT INTO blob_refs (hash, refcount, size_bytes) VALUES (?, 0, ?)
+		 ON CONFLICT(hash) DO NOTHING`,
+		hash, size)
+	if err != nil {
+		return fmt.Errorf("store: blobstore migrate ref %s: %w", hash, err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestBlobStore(t *testing.T) *BlobStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	bs, err := NewBlobStore(db, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+	return bs
+}
+
+func TestBlobStore_PutDedupesIdenticalContent(t *testing.T) {
+	bs := newTestBlobStore(t)
+
+	hash1, size1, err := bs.Put(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if size1 != 11 {
+		t.Errorf("size = %d, want 11", size1)
+	}
+
+	hash2, _, err := bs.Put(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("Put() second error = %v", err)
+	}
+	if hash1 != hash2 {
+		t.Errorf("hash mismatch for identical content: %q vs %q", hash1, hash2)
+	}
+
+	var refcount int
+	err = bs.db.QueryRow(`SELECT refcount FROM blob_refs WHERE hash = ?`, hash1).Scan(&refcount)
+	if err != nil {
+		t.Fatalf("query refcount: %v", err)
+	}
+	if refcount != 2 {
+		t.Errorf("refcount = %d, want 2", refcount)
+	}
+
+	if _, err := os.Stat(bs.path(hash1)); err != nil {
+		t.Errorf("stat %s: %v, want the single deduped file to exist", bs.path(hash1), err)
+	}
+}
+
+func TestBlobStore_OpenReturnsPutContent(t *testing.T) {
+	bs := newTestBlobStore(t)
+
+	hash, _, err := bs.Put(strings.NewReader("attachment bytes"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	r, err := bs.Open(hash)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "attachment bytes" {
+		t.Errorf("content = %q, want %q", got, "attachment bytes")
+	}
+}
+
+func TestBlobStore_DeleteOnlyRemovesFileAtZeroRefcount(t *testing.T) {
+	bs := newTestBlobStore(t)
+
+	hash, _, err := bs.Put(strings.NewReader("shared"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, _, err := bs.Put(strings.NewReader("shared")); err != nil {
+		t.Fatalf("Put() second error = %v", err)
+	}
+
+	if err := bs.Delete(hash); err != nil {
+		t.Fatalf("Delete() first error = %v", err)
+	}
+	if _, err := bs.Open(hash); err != nil {
+		t.Errorf("Open() after first Delete() error = %v, want blob still present", err)
+	}
+
+	if err := bs.Delete(hash); err != nil {
+		t.Fatalf("Delete() second error = %v", err)
+	}
+	if _, err := bs.Open(hash); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open() after final Delete() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestBlobStore_VerifyReportsMissingBlob(t *testing.T) {
+	bs := newTestBlobStore(t)
+
+	hash, _, err := bs.Put(strings.NewReader("will go missing"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := os.Remove(bs.path(hash)); err != nil {
+		t.Fatalf("remove blob file: %v", err)
+	}
+
+	report, err := bs.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.Scanned != 1 {
+		t.Errorf("Scanned = %d, want 1", report.Scanned)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != hash {
+		t.Errorf("Missing = %v, want [%s]", report.Missing, hash)
+	}
+	if len(report.Corrupt) != 0 {
+		t.Errorf("Corrupt = %v, want none", report.Corrupt)
+	}
+}
+
+// TestBlobStore_MigrateLegacyBlobRegistersSharded confirms
+// MigrateLegacyBlob moves a file into the sharded layout and records it in
+// blob_refs with a zero refcount, so a later Put for the same hash dedupes
+// against it instead of writing a second copy.
+func TestBlobStore_MigrateLegacyBlobRegistersSharded(t *testing.T) {
+	bs := newTestBlobStore(t)
+
+	legacy := bs.root + "/legacy-blob"
+	if err := os.WriteFile(legacy, []byte("pre-existing attachment"), 0o600); err != nil {
+		t.Fatalf("write legacy file: %v", err)
+	}
+	hash := "deadbeef00112233445566778899aabbccddeeff00112233445566778899aa"
+
+	if err := bs.MigrateLegacyBlob(legacy, hash, 24); err != nil {
+		t.Fatalf("MigrateLegacyBlob() error = %v", err)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("legacy path still exists after migrate")
+	}
+	if _, err := os.Stat(bs.path(hash)); err != nil {
+		t.Errorf("stat sharded path: %v", err)
+	}
+
+	secondHash, _, err := bs.Put(strings.NewReader("migrated-content-unused"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if secondHash == hash {
+		t.Fatalf("test setup: unrelated Put collided with migrated hash")
+	}
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AuditOp identifies which mutating operation an AuditEntry records,
+// mirroring the method names on the sub-store interfaces in
+// interfaces.go.
+type AuditOp string
+
+const (
+	AuditOpUpsertMessage            AuditOp = "upsert_message"
+	AuditOpMarkMessageDeleted       AuditOp = "mark_message_deleted"
+	AuditOpReplaceMessageLabels     AuditOp = "replace_message_labels"
+	AuditOpReplaceMessageRecipients AuditOp = "replace_message_recipients"
+	AuditOpEnsureLabel              AuditOp = "ensure_label"
+	AuditOpStartSync                AuditOp = "start_sync"
+	AuditOpCompleteSync             AuditOp = "complete_sync"
+)
+
+// AuditEntry is one row an AuditStore records: who did what to which
+// entity, and its state immediately before and after, serialized as JSON
+// so entities of different shapes (a Message, a label ID list) share one
+// table. BeforeJSON is empty for operations with no prior state (e.g.
+// EnsureLabel creating a new label).
+type AuditEntry struct {
+	ID         int64
+	Time       time.Time
+	Actor      string
+	SourceID   SourceID
+	EntityType string
+	EntityID   int64
+	Op         AuditOp
+	BeforeJSON string
+	AfterJSON  string
+}
+
+// AuditFilter narrows a QueryAudits call. A zero-value field matches
+// anything along that dimension.
+type AuditFilter struct {
+	Since      time.Time
+	Until      time.Time
+	EntityType string
+	EntityID   int64
+	Op         AuditOp
+}
+
+// AuditStore is an append-only log of every mutation recorded against it,
+// so a vault operator can prove what changed and when - particularly for
+// MarkMessageDeleted, which otherwise leaves no trace beyond a message's
+// own deleted_from_source_at column.
+type AuditStore struct {
+	db *sql.DB
+}
+
+// NewAuditStore creates (if needed) the audits table in db and returns an
+// AuditStore backed by it.
+func NewAuditStore(db *sql.DB) (*AuditStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS audits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts DATETIME NOT NULL,
+		actor TEXT NOT NULL,
+		source_id INTEGER NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		op TEXT NOT NULL,
+		before_json TEXT NOT NULL DEFAULT '',
+		after_json TEXT NOT NULL DEFAULT ''
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("store: create audits: %w", err)
+	}
+	return &AuditStore{db: db}, nil
+}
+
+// Record appends entry to the audit log, assigning it an ID and Time if
+// unset.
+func (a *AuditStore) Record(ctx context.Context, entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	_, err := a.db.ExecContext(ctx,
+		`INSERT INTO audits (ts, actor, source_id, entity_type, entity_id, op, before_json, after_json)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Time, entry.Actor, int64(entry.SourceID), entry.EntityType, entry.EntityID,
+		string(entry.Op), entry.BeforeJSON, entry.AfterJSON)
+	if err != nil {
+		return fmt.Errorf("store: record audit entry: %w", err)
+	}
+	return nil
+}
+
+// QueryAudits returns every AuditEntry matching filter, oldest first.
+func (a *AuditStore) QueryAudits(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	query := `SELECT id, ts, actor, source_id, entity_type, entity_id, op, before_json, after_json FROM audits WHERE 1=1`
+	var args []any
+
+	if !filter.Since.IsZero() {
+		query += ` AND ts >= ?`
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND ts <= ?`
+		args = append(args, filter.Until)
+	}
+	if filter.EntityType != "" {
+		query += ` AND entity_type = ?`
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query += ` AND entity_id = ?`
+		args = append(args, filter.EntityID)
+	}
+	if filter.Op != "" {
+		query += ` AND op = ?`
+		args = append(args, string(filter.Op))
+	}
+	query += ` ORDER BY id ASC`
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("store: query audits: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var sourceID int64
+		var op string
+		if err := rows.Scan(&e.ID, &e.Time, &e.Actor, &sourceID, &e.EntityType, &e.EntityID, &op, &e.BeforeJSON, &e.AfterJSON); err != nil {
+			return nil, fmt.Errorf("store: scan audit entry: %w", err)
+		}
+		e.SourceID = SourceID(sourceID)
+		e.Op = AuditOp(op)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: query audits rows: %w", err)
+	}
+	return entries, nil
+}
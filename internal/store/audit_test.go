@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestAuditStore(t *testing.T) *AuditStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	as, err := NewAuditStore(db)
+	if err != nil {
+		t.Fatalf("NewAuditStore: %v", err)
+	}
+	return as
+}
+
+// fakeLabelStore is a minimal in-memory LabelStore + LabelReader, standing
+// in for the concrete Store this tree has no compiler to build against.
+type fakeLabelStore struct {
+	labels map[MessageID][]LabelID
+}
+
+func newFakeLabelStore() *fakeLabelStore {
+	return &fakeLabelStore{labels: make(map[MessageID][]LabelID)}
+}
+
+func (f *fakeLabelStore) EnsureLabel(sourceID SourceID, labelID, name, kind string) (LabelID, error) {
+	return LabelID(1), nil
+}
+
+func (f *fakeLabelStore) ReplaceMessageLabels(msgID MessageID, labelIDs []LabelID) error {
+	f.labels[msgID] = labelIDs
+	return nil
+}
+
+func (f *fakeLabelStore) AddMessageLabels(internalID MessageID, labelIDs []LabelID) error {
+	f.labels[internalID] = append(f.labels[internalID], labelIDs...)
+	return nil
+}
+
+func (f *fakeLabelStore) RemoveMessageLabels(internalID MessageID, labelIDs []LabelID) error {
+	return nil
+}
+
+func (f *fakeLabelStore) MessageLabels(msgID MessageID) ([]LabelID, error) {
+	return f.labels[msgID], nil
+}
+
+var _ LabelStore = (*fakeLabelStore)(nil)
+var _ LabelReader = (*fakeLabelStore)(nil)
+
+func TestAuditingLabelStore_ReplaceMessageLabels_EmptyListRecordsRemovedBefore(t *testing.T) {
+	audits := newTestAuditStore(t)
+	inner := newFakeLabelStore()
+	inner.labels[MessageID(42)] = []LabelID{1, 2, 3}
+
+	auditing := NewAuditingLabelStore(inner, audits, "tester")
+
+	if err := auditing.ReplaceMessageLabels(MessageID(42), []LabelID{}); err != nil {
+		t.Fatalf("ReplaceMessageLabels() error = %v", err)
+	}
+
+	entries, err := audits.QueryAudits(context.Background(), AuditFilter{EntityID: 42, Op: AuditOpReplaceMessageLabels})
+	if err != nil {
+		t.Fatalf("QueryAudits() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.BeforeJSON != `[1,2,3]` {
+		t.Errorf("BeforeJSON = %q, want %q", entry.BeforeJSON, `[1,2,3]`)
+	}
+	if entry.AfterJSON != `[]` {
+		t.Errorf("AfterJSON = %q, want %q", entry.AfterJSON, `[]`)
+	}
+	if entry.Actor != "tester" {
+		t.Errorf("Actor = %q, want %q", entry.Actor, "tester")
+	}
+}
+
+func TestAuditingLabelStore_ReplaceMessageLabels_NoReaderLeavesBeforeEmpty(t *testing.T) {
+	audits := newTestAuditStore(t)
+	inner := &noReaderLabelStore{}
+
+	auditing := NewAuditingLabelStore(inner, audits, "tester")
+	if err := auditing.ReplaceMessageLabels(MessageID(7), []LabelID{9}); err != nil {
+		t.Fatalf("ReplaceMessageLabels() error = %v", err)
+	}
+
+	entries, err := audits.QueryAudits(context.Background(), AuditFilter{EntityID: 7})
+	if err != nil {
+		t.Fatalf("QueryAudits() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(entries))
+	}
+	if entries[0].BeforeJSON != "null" {
+		t.Errorf("BeforeJSON = %q, want %q", entries[0].BeforeJSON, "null")
+	}
+}
+
+// noReaderLabelStore is a LabelStore that deliberately does not also
+// implement LabelReader, exercising AuditingLabelStore's fallback path.
+type noReaderLabelStore struct{}
+
+func (n *noReaderLabelStore) EnsureLabel(sourceID SourceID, labelID, name, kind string) (LabelID, error) {
+	return LabelID(1), nil
+}
+
+func (n *noReaderLabelStore) ReplaceMessageLabels(msgID MessageID, labelIDs []LabelID) error {
+	return nil
+}
+
+func (n *noReaderLabelStore) AddMessageLabels(internalID MessageID, labelIDs []LabelID) error {
+	return nil
+}
+
+func (n *noReaderLabelStore) RemoveMessageLabels(internalID MessageID, labelIDs []LabelID) error {
+	return nil
+}
+
+var _ LabelStore = (*noReaderLabelStore)(nil)
+
+func TestAuditStore_QueryAudits_FiltersByOp(t *testing.T) {
+	audits := newTestAuditStore(t)
+	inner := newFakeLabelStore()
+	auditing := NewAuditingLabelStore(inner, audits, "tester")
+
+	if _, err := auditing.EnsureLabel(SourceID(1), "Label_1", "Important", "user"); err != nil {
+		t.Fatalf("EnsureLabel() error = %v", err)
+	}
+	if err := auditing.ReplaceMessageLabels(MessageID(1), []LabelID{1}); err != nil {
+		t.Fatalf("ReplaceMessageLabels() error = %v", err)
+	}
+
+	entries, err := audits.QueryAudits(context.Background(), AuditFilter{Op: AuditOpEnsureLabel})
+	if err != nil {
+		t.Fatalf("QueryAudits() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(entries))
+	}
+	if entries[0].Op != AuditOpEnsureLabel {
+		t.Errorf("Op = %q, want %q", entries[0].Op, AuditOpEnsureLabel)
+	}
+}
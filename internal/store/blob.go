@@ -0,0 +1,286 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wesm/msgvault/internal/blobstore"
+)
+
+// Option configures a Store at Open time, following the same functional-
+// options pattern as blobstore.Filesystem.
+type Option func(*Store) error
+
+// WithBlobStorage stores each message's raw MIME bytes as a file under dir
+// (see blobstore.MessageBlobStore) instead of in the messages.raw_mime
+// BLOB column, compressing them per compression.
+func WithBlobStorage(dir string, compression blobstore.MessageCompression) Option {
+	return func(s *Store) error {
+		bs, err := blobstore.NewMessageBlobStore(dir)
+		if err != nil {
+			return fmt.Errorf("with blob storage: %w", err)
+		}
+		s.blobStore = bs
+		s.blobCompression = compression
+		return nil
+	}
+}
+
+// WithEncryptedBlobStorage is WithBlobStorage plus AES-256-GCM encryption
+// of every stored message body, keyed by passphrase and salt (see
+// blobstore.WithMessageEncryption). Generate passphrase once per vault
+// with a CSPRNG and persist it, along with salt (see blobstore.NewSalt),
+// in the vault's own metadata -- losing either makes every message this
+// Store has written unrecoverable.
+func WithEncryptedBlobStorage(dir string, compression blobstore.MessageCompression, passphrase string, salt []byte) Option {
+	return func(s *Store) error {
+		bs, err := blobstore.NewMessageBlobStore(dir, blobstore.WithMessageEncryption(passphrase, salt))
+		if err != nil {
+			return fmt.Errorf("with encrypted blob storage: %w", err)
+		}
+		s.blobStore = bs
+		s.blobCompression = compression
+		return nil
+	}
+}
+
+// WithHybridBlobStorage is a hybrid of WithBlobStorage and keeping a
+// message's data in the database: PutHybridMessageRaw splits the raw
+// bytes at the header/body boundary, storing the header block (Received,
+// Authentication-Results, DKIM-Signature, etc.) in the messages row as
+// blob_header_prefix and only the body on disk under dir, the same way
+// WithBlobStorage stores a whole message. Sync-time metadata discovered
+// after the body is already on disk -- most commonly a DKIM verdict -- is
+// then a small UPDATE to blob_header_prefix instead of a rewrite of the
+// (potentially multi-megabyte) on-disk file. Only MsgReader knows how to
+// read a message stored this way; OpenMessageRaw and MessageReader assume
+// the on-disk (or inline) copy is the complete message.
+func WithHybridBlobStorage(dir string, compression blobstore.MessageCompression) Option {
+	return func(s *Store) error {
+		bs, err := blobstore.NewMessageBlobStore(dir)
+		if err != nil {
+			return fmt.Errorf("with hybrid blob storage: %w", err)
+		}
+		s.blobStore = bs
+		s.blobCompression = compression
+		s.blobHybrid = true
+		return nil
+	}
+}
+
+// errBlobEvicted marks an OpenMessageRaw failure caused specifically by the
+// capacity checker (see RunCapacityChecker) having deleted a message's
+// on-disk blob to reclaim space, as opposed to a genuine I/O or corruption
+// error. MessageReader uses this distinction to decide when rehydration is
+// appropriate.
+var errBlobEvicted = errors.New("store: message blob was evicted")
+
+// WithRehydrator configures the Rehydrator MessageReader falls back to
+// when it finds a message whose on-disk blob has been evicted by the
+// capacity checker (see RunCapacityChecker). Typically wraps a
+// gmail.API.GetMessageRaw call keyed by the message's SourceMessageID.
+func WithRehydrator(fn Rehydrator) Option {
+	return func(s *Store) error {
+		s.rehydrate = fn
+		return nil
+	}
+}
+
+// Rehydrator re-fetches a message's raw bytes from its original source
+// (e.g. Gmail) when its on-disk blob is no longer available locally.
+type Rehydrator func(ctx context.Context, sourceMessageID string) ([]byte, error)
+
+// OpenMessageRaw returns the raw MIME bytes for the message identified by
+// sourceID. Messages with a blob_path are read from disk via the Store's
+// MessageBlobStore (configured with WithBlobStorage) and their SHA-256
+// verified against blob_sha256; messages that predate on-disk blobs
+// (blob_path empty) are read from the raw_mime column instead. Returns an
+// error wrapping errBlobEvicted if the blob_path is set but the
+// underlying file no longer exists, i.e. it was reclaimed by
+// RunCapacityChecker -- callers that can re-fetch the message should
+// prefer MessageReader, which handles that case automatically.
+func (s *Store) OpenMessageRaw(sourceID int64) (io.ReadCloser, error) {
+	row, err := s.getMessageBlobRow(sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("get message blob row %d: %w", sourceID, err)
+	}
+	if row.BlobPath == "" {
+		return io.NopCloser(bytes.NewReader(row.RawMIME)), nil
+	}
+	if s.blobStore == nil {
+		return nil, fmt.Errorf("message %d has an on-disk blob but no blob storage is configured", sourceID)
+	}
+	rc, err := s.blobStore.Open(row.BlobPath, row.BlobCompression, row.BlobSHA256)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: message %d", errBlobEvicted, sourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob for message %d: %w", sourceID, err)
+	}
+	return rc, nil
+}
+
+// MessageReader returns the raw MIME bytes for the message identified by
+// sourceID the same way OpenMessageRaw does, but additionally re-fetches
+// the message through the configured Rehydrator (see WithRehydrator) and
+// re-populates the on-disk cache when its blob has been evicted, instead
+// of failing outright. Callers that don't need rehydration (e.g. a
+// capacity-checker dry run) should use OpenMessageRaw directly.
+func (s *Store) MessageReader(ctx context.Context, sourceID int64) (io.ReadCloser, error) {
+	rc, err := s.OpenMessageRaw(sourceID)
+	if err == nil {
+		return rc, nil
+	}
+	if !errors.Is(err, errBlobEvicted) || s.rehydrate == nil {
+		return nil, err
+	}
+
+	row, rowErr := s.getMessageBlobRow(sourceID)
+	if rowErr != nil {
+		return nil, fmt.Errorf("get message blob row %d: %w", sourceID, rowErr)
+	}
+
+	data, fetchErr := s.rehydrate(ctx, row.SourceMessageID)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("rehydrate message %d: %w", sourceID, fetchErr)
+	}
+
+	relPath, sha256Hex, size, putErr := s.blobStore.Put(row.SourceMessageID, data, s.blobCompression)
+	if putErr != nil {
+		return nil, fmt.Errorf("re-cache rehydrated message %d: %w", sourceID, putErr)
+	}
+	if err := s.setMessageBlob(sourceID, relPath, sha256Hex, size, s.blobCompression); err != nil {
+		return nil, fmt.Errorf("set message blob %d: %w", sourceID, err)
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// MigrateBlobsToDisk drains every messages.raw_mime BLOB onto disk through
+// the Store's configured MessageBlobStore, populating blob_path/
+// blob_sha256/blob_size/blob_compression and nulling out raw_mime one row
+// at a time so a large vault doesn't need its whole raw_mime column in
+// memory at once. Rows that already have a blob_path are left untouched.
+func (s *Store) MigrateBlobsToDisk() error {
+	if s.blobStore == nil {
+		return fmt.Errorf("migrate blobs to disk: no blob storage configured (see WithBlobStorage)")
+	}
+
+	ids, err := s.listMessagesWithInlineRaw()
+	if err != nil {
+		return fmt.Errorf("list messages with inline raw: %w", err)
+	}
+
+	for _, id := range ids {
+		row, err := s.getMessageBlobRow(id)
+		if err != nil {
+			return fmt.Errorf("get message blob row %d: %w", id, err)
+		}
+		relPath, sha256Hex, size, err := s.blobStore.Put(row.SourceMessageID, row.RawMIME, s.blobCompression)
+		if err != nil {
+			return fmt.Errorf("put message %d: %w", id, err)
+		}
+		if err := s.setMessageBlob(id, relPath, sha256Hex, size, s.blobCompression); err != nil {
+			return fmt.Errorf("set message blob %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// PutHybridMessageRaw splits raw at its header/body boundary (the first
+// blank line, CRLF or LF) and stores the body on disk through the Store's
+// MessageBlobStore the same way Put does for WithBlobStorage, but keeps
+// the header block itself in the messages row as blob_header_prefix
+// rather than folding it into the on-disk file. Requires
+// WithHybridBlobStorage.
+func (s *Store) PutHybridMessageRaw(sourceID int64, sourceMessageID string, raw []byte) error {
+	if s.blobStore == nil || !s.blobHybrid {
+		return fmt.Errorf("message %d: hybrid blob storage not configured (see WithHybridBlobStorage)", sourceID)
+	}
+
+	prefix, body := splitMessageHeaders(raw)
+	relPath, sha256Hex, size, err := s.blobStore.Put(sourceMessageID, body, s.blobCompression)
+	if err != nil {
+		return fmt.Errorf("put hybrid message body %d: %w", sourceID, err)
+	}
+	if err := s.setHybridMessageBlob(sourceID, relPath, sha256Hex, size, s.blobCompression, prefix); err != nil {
+		return fmt.Errorf("set hybrid message blob %d: %w", sourceID, err)
+	}
+	return nil
+}
+
+// splitMessageHeaders splits raw RFC 5322 bytes into its header block
+// (through and including the first blank line) and the body that follows,
+// recognizing both CRLF and bare-LF line endings. A message with no blank
+// line (malformed, or headers-only) is treated as all header, empty body.
+func splitMessageHeaders(raw []byte) (prefix, body []byte) {
+	if i := bytes.Index(raw, []byte("\r\n\r\n")); i >= 0 {
+		return raw[:i+4], raw[i+4:]
+	}
+	if i := bytes.Index(raw, []byte("\n\n")); i >= 0 {
+		return raw[:i+2], raw[i+2:]
+	}
+	return raw, nil
+}
+
+// MsgReader concatenates a hybrid-stored message's DB-held header prefix
+// with its on-disk body into a single io.ReadCloser, so callers that want
+// the complete raw MIME (export, re-parsing, forwarding to a Rehydrator)
+// don't need to know the message's storage is split in two. Closing it
+// closes the underlying on-disk file.
+type MsgReader struct {
+	io.Reader
+	body io.Closer
+}
+
+// Close releases the on-disk body file MsgReader is reading from.
+func (r *MsgReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// OpenHybridMessageRaw returns a MsgReader over the message identified by
+// sourceID, which must have been written with PutHybridMessageRaw.
+// Requires WithHybridBlobStorage.
+func (s *Store) OpenHybridMessageRaw(sourceID int64) (*MsgReader, error) {
+	if s.blobStore == nil {
+		return nil, fmt.Errorf("message %d has an on-disk blob but no blob storage is configured", sourceID)
+	}
+
+	row, err := s.getMessageBlobRow(sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("get message blob row %d: %w", sourceID, err)
+	}
+
+	bodyRC, err := s.blobStore.Open(row.BlobPath, row.BlobCompression, row.BlobSHA256)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: message %d", errBlobEvicted, sourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open hybrid blob for message %d: %w", sourceID, err)
+	}
+
+	return &MsgReader{
+		Reader: io.MultiReader(bytes.NewReader([]byte(row.HeaderPrefix)), bodyRC),
+		body:   bodyRC,
+	}, nil
+}
+
+// messageBlobRow is the subset of a messages row OpenMessageRaw,
+// OpenHybridMessageRaw, and MigrateBlobsToDisk need.
+type messageBlobRow struct {
+	SourceMessageID string
+	RawMIME         []byte
+	BlobPath        string
+	BlobSHA256      string
+	BlobCompression blobstore.MessageCompression
+	// HeaderPrefix is blob_header_prefix: the header block of a message
+	// stored via WithHybridBlobStorage, empty for every other message.
+	HeaderPrefix string
+}
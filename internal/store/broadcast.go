@@ -0,0 +1,213 @@
+package store
+
+import "sync"
+
+// ChangeKind identifies what kind of mutation a Change describes.
+type ChangeKind int
+
+const (
+	ChangeAddMessage ChangeKind = iota
+	ChangeUpdateMessage
+	ChangeRemoveMessage
+	ChangeAddLabel
+	ChangeSyncProgress
+	ChangeMarkDeleted
+	ChangeReplaceLabels
+	ChangeReplaceRecipients
+	ChangeStartSync
+	ChangeCompleteSync
+	ChangeAddSource
+)
+
+// String returns the ChangeKind's name, e.g. "add_message", for logging.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAddMessage:
+		return "add_message"
+	case ChangeUpdateMessage:
+		return "update_message"
+	case ChangeRemoveMessage:
+		return "remove_message"
+	case ChangeAddLabel:
+		return "add_label"
+	case ChangeSyncProgress:
+		return "sync_progress"
+	case ChangeMarkDeleted:
+		return "mark_deleted"
+	case ChangeReplaceLabels:
+		return "replace_labels"
+	case ChangeReplaceRecipients:
+		return "replace_recipients"
+	case ChangeStartSync:
+		return "start_sync"
+	case ChangeCompleteSync:
+		return "complete_sync"
+	case ChangeAddSource:
+		return "add_source"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is one broadcast event: what kind of mutation happened, and
+// enough of what it happened to (source, message, conversation, labels)
+// for a subscriber to decide via SubscriptionFilter whether it cares. Not
+// every field is populated for every Kind - a ChangeSyncProgress event
+// carries SourceID but no MessageID, for instance.
+//
+// MessageID is a plain int64 rather than the typed MessageID alias:
+// Store's own message-row methods (UpsertMessage, MarkMessageDeleted, ...)
+// work in plain int64 row IDs, and a Change is built directly from their
+// return values.
+type Change struct {
+	Kind           ChangeKind
+	SourceID       SourceID
+	MessageID      int64
+	ConversationID ConversationID
+	Labels         []string
+}
+
+// SubscriptionFilter narrows a Broadcaster.Subscribe call to only the
+// Changes a subscriber cares about. A zero-value field matches anything
+// along that dimension, so the zero SubscriptionFilter matches every
+// Change.
+type SubscriptionFilter struct {
+	SourceID       SourceID
+	ConversationID ConversationID
+	Label          string
+}
+
+// matches reports whether c satisfies every non-zero field of f.
+func (f SubscriptionFilter) matches(c Change) bool {
+	if f.SourceID != 0 && c.SourceID != f.SourceID {
+		return false
+	}
+	if f.ConversationID != 0 && c.ConversationID != f.ConversationID {
+		return false
+	}
+	if f.Label != "" {
+		found := false
+		for _, l := range c.Labels {
+			if l == f.Label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// CancelFunc unregisters a Broadcaster.Subscribe call's channel. Safe to
+// call more than once.
+type CancelFunc func()
+
+// subscriberQueueSize bounds how many undelivered Changes a subscriber's
+// channel can hold before Broadcaster.Publish evicts it rather than
+// block.
+const subscriberQueueSize = 64
+
+// Broadcaster fans typed Change events out to registered subscribers,
+// modeled on mox's switchboard: each subscriber gets its own bounded,
+// buffered channel, and a subscriber that falls behind is evicted rather
+// than allowed to block the writer that published the event. It unlocks
+// a live IMAP server plugin or web UI that reacts to store mutations
+// instead of polling for them.
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[int]*subscriberState
+	nextID      int
+}
+
+type subscriberState struct {
+	ch     chan Change
+	filter SubscriptionFilter
+}
+
+// NewBroadcaster returns an empty Broadcaster ready to accept subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[int]*subscriberState)}
+}
+
+// Subscribe registers a new subscriber matching filter and returns its
+// channel plus a CancelFunc that unregisters it. The channel is closed
+// when the subscription is cancelled, evicted for falling behind (see
+// Publish), or the Broadcaster itself is Closed.
+func (b *Broadcaster) Subscribe(filter SubscriptionFilter) (<-chan Change, CancelFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	sub := &subscriberState{ch: make(chan Change, subscriberQueueSize), filter: filter}
+	b.subscribers[id] = sub
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if s, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(s.ch)
+		}
+	}
+	return sub.ch, cancel
+}
+
+// Publish fans c out to every subscriber whose filter matches it. A
+// subscriber whose channel is already full is evicted - its channel is
+// closed and its subscription removed - instead of letting Publish block
+// on a slow reader, since Publish is meant to run on a writer's
+// transaction-commit path.
+func (b *Broadcaster) Publish(c Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sub := range b.subscribers {
+		if !sub.filter.matches(c) {
+			continue
+		}
+		select {
+		case sub.ch <- c:
+		default:
+			delete(b.subscribers, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close unregisters and closes every subscriber's channel, e.g. when the
+// owning Store shuts down.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		close(sub.ch)
+	}
+}
+
+// PendingChanges accumulates Change events raised inside a transaction so
+// they publish only once the transaction commits; a rolled-back
+// transaction simply discards them by never calling Flush. A mutating
+// method appends to one of these as it writes instead of calling
+// Broadcaster.Publish directly, and its caller runs Flush only after
+// tx.Commit() succeeds.
+type PendingChanges struct {
+	changes []Change
+}
+
+// Add queues c to be published the next time Flush is called.
+func (p *PendingChanges) Add(c Change) {
+	p.changes = append(p.changes, c)
+}
+
+// Flush publishes every queued Change to b in order and clears the queue.
+// Calling Flush on an empty PendingChanges is a no-op.
+func (p *PendingChanges) Flush(b *Broadcaster) {
+	for _, c := range p.changes {
+		b.Publish(c)
+	}
+	p.changes = nil
+}
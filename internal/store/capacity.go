@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// CapacityCheckerOptions configures RunCapacityChecker.
+type CapacityCheckerOptions struct {
+	// CheckInterval is how often free space is sampled.
+	CheckInterval time.Duration
+	// MinFreeSpaceBytes is the free-space floor: once the blob store's
+	// backing filesystem drops below this, the oldest blobs are evicted
+	// until EvictTargetBytes is freed.
+	MinFreeSpaceBytes int64
+	// EvictTargetBytes is how many bytes to free per eviction pass,
+	// typically a multiple of MinFreeSpaceBytes so a single pass doesn't
+	// immediately re-trigger on the next check.
+	EvictTargetBytes int64
+	// Logger receives progress and error messages. Defaults to discarding
+	// them via slog.New(slog.DiscardHandler) equivalent behavior if nil.
+	Logger *slog.Logger
+}
+
+func (o CapacityCheckerOptions) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// RunCapacityChecker periodically checks the configured blob store's free
+// space and evicts the oldest on-disk message bodies (see
+// EvictOldestBlobs) whenever it drops below opts.MinFreeSpaceBytes,
+// running until ctx is canceled. Evicted messages remain readable through
+// MessageReader as long as a Rehydrator is configured (see
+// WithRehydrator); without one, they become unreadable until a fresh sync
+// re-fetches them.
+func (s *Store) RunCapacityChecker(ctx context.Context, opts CapacityCheckerOptions) error {
+	if s.blobStore == nil {
+		return fmt.Errorf("run capacity checker: no blob storage configured (see WithBlobStorage)")
+	}
+	if opts.CheckInterval <= 0 {
+		return fmt.Errorf("run capacity checker: CapacityCheckerOptions.CheckInterval must be positive")
+	}
+
+	logger := opts.logger()
+	ticker := time.NewTicker(opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		stats, err := s.blobStore.Stats(ctx)
+		if err != nil {
+			logger.Error("capacity checker: stats failed", "error", err)
+			continue
+		}
+		if stats.FreeSpaceBytes >= opts.MinFreeSpaceBytes {
+			continue
+		}
+
+		deficit := opts.EvictTargetBytes
+		if deficit <= 0 {
+			deficit = opts.MinFreeSpaceBytes - stats.FreeSpaceBytes
+		}
+		logger.Warn("capacity checker: free space below threshold, evicting oldest blobs",
+			"free_bytes", stats.FreeSpaceBytes, "min_free_bytes", opts.MinFreeSpaceBytes, "target_bytes", deficit)
+
+		evicted, freed, err := s.EvictOldestBlobs(deficit)
+		if err != nil {
+			logger.Error("capacity checker: eviction failed", "error", err)
+			continue
+		}
+		logger.Info("capacity checker: evicted oldest blobs", "count", evicted, "freed_bytes", freed)
+	}
+}
+
+// EvictOldestBlobs deletes on-disk blobs for the oldest messages (by
+// internal ID, a proxy for ingestion order) until at least targetBytes
+// have been freed or there are no more evictable blobs, nulling out each
+// row's blob_path/blob_sha256/blob_size. A subsequent OpenMessageRaw for
+// an evicted message fails with errBlobEvicted; MessageReader recovers by
+// rehydrating it from its configured Rehydrator.
+func (s *Store) EvictOldestBlobs(targetBytes int64) (evicted int, freedBytes int64, err error) {
+	if s.blobStore == nil {
+		return 0, 0, fmt.Errorf("evict oldest blobs: no blob storage configured (see WithBlobStorage)")
+	}
+
+	rows, err := s.listBlobbedMessagesOldestFirst()
+	if err != nil {
+		return 0, 0, fmt.Errorf("list blobbed messages: %w", err)
+	}
+
+	for _, row := range rows {
+		if freedBytes >= targetBytes {
+			break
+		}
+		if err := s.blobStore.Delete(row.BlobPath); err != nil {
+			return evicted, freedBytes, fmt.Errorf("delete blob for message %d: %w", row.ID, err)
+		}
+		if err := s.clearMessageBlob(row.ID); err != nil {
+			return evicted, freedBytes, fmt.Errorf("clear message blob %d: %w", row.ID, err)
+		}
+		evicted++
+		freedBytes += row.BlobSize
+	}
+	return evicted, freedBytes, nil
+}
+
+// blobbedMessageRow is the subset of a messages row EvictOldestBlobs needs.
+type blobbedMessageRow struct {
+	ID       int64
+	BlobPath string
+	BlobSize int64
+}
@@ -0,0 +1,58 @@
+package store
+
+import "time"
+
+// Checkpoint tracks resumable progress for one sync run. It is persisted
+// after every page of history (or batch of messages during a full sync) so
+// a crash resumes from the last safe point instead of restarting, and
+// carries enough per-message retry state that the same checkpoint works
+// whether the sync is Full or Incremental.
+type Checkpoint struct {
+	PageToken         string
+	MessagesProcessed int64
+	MessagesAdded     int64
+	MessagesUpdated   int64
+	ErrorsCount       int64
+
+	// Retries holds backoff state for messages that failed a transient
+	// error and are scheduled for another attempt, keyed by source message
+	// ID. Cleared once a message is poisoned or succeeds.
+	Retries map[string]*MessageRetryState
+
+	// PoisonedMessageIDs lists source message IDs that failed permanently
+	// (404, or a parse failure flagged permanent) or exhausted their retry
+	// budget. Syncer.RetryPoisoned re-enqueues them.
+	PoisonedMessageIDs []string
+
+	// LastAckedMessageID is the source message ID of the last message this
+	// run durably ingested, mirroring a Pub/Sub-style ack: a resumed sync
+	// re-requests history starting just after this ID rather than reprocessing
+	// it, even if PageToken pointed partway into a page that was never
+	// fully acked.
+	LastAckedMessageID string
+
+	// RetryCount is how many times this run itself (as opposed to a single
+	// message within it - see Retries) has failed and been retried.
+	// ResumeSync uses it to compute the next backoff; a run that completes
+	// successfully resets it to zero.
+	RetryCount int
+
+	// NextRetryAt is when a failed run becomes eligible to resume, per
+	// exponential backoff on RetryCount. The zero value means "eligible
+	// immediately" - the state of a checkpoint that has never failed.
+	NextRetryAt time.Time
+
+	// RateLimitResetAt is when the source's rate limit is expected to
+	// clear, for a run that failed with a rate-limit error. It takes
+	// precedence over NextRetryAt's backoff schedule when both are set,
+	// since the server already told us exactly how long to wait.
+	RateLimitResetAt time.Time
+}
+
+// MessageRetryState tracks exponential-backoff retry state for a single
+// message that failed a transient error during sync.
+type MessageRetryState struct {
+	LastError     string
+	AttemptCount  int
+	NextAttemptAt time.Time
+}
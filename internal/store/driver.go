@@ -0,0 +1,114 @@
+package store
+
+import "fmt"
+
+// Driver abstracts the backing SQL engine a dataset's tables live on, so
+// migrate-store (and, eventually, Store itself) can move rows between
+// engines without either side knowing the other's SQL dialect. The
+// existing sqlite-backed code is driver "sqlite" (see NewSQLiteDriver); a
+// "postgres" driver lives in internal/store/postgres, using pg_trgm
+// trigram indexes in place of sqlite's FTS5 virtual tables.
+type Driver interface {
+	// Name identifies the driver ("sqlite" or "postgres"), recorded in
+	// migration_progress so a resumed migrate-store run can confirm it's
+	// talking to the same kind of destination it started with.
+	Name() string
+
+	// TableRowIterator streams table's rows in ascending primary-key
+	// order, starting after afterPK (0 to start from the beginning) and
+	// yielding at most batchSize rows per RowIterator.Next call.
+	TableRowIterator(table string, afterPK int64, batchSize int) (RowIterator, error)
+
+	// BulkInsert writes rows into table in a single statement/transaction
+	// and returns the number of rows written. Rows must all share the
+	// same set of columns (the set the source table's RowIterator yields).
+	BulkInsert(table string, rows []Row) (int, error)
+
+	// SetForeignKeyChecks enables or disables FK enforcement for the
+	// duration of a bulk load: PRAGMA foreign_keys=OFF/ON on sqlite,
+	// SET CONSTRAINTS ALL DEFERRED for the current transaction on
+	// postgres. migrate-store disables it before streaming any table and
+	// re-enables it once every table in MigrationTableOrder has loaded,
+	// so rows can land out of strict referential order within a table
+	// (though MigrationTableOrder still loads tables themselves in
+	// dependency order, since FK checks come back on before the command
+	// declares success).
+	SetForeignKeyChecks(enabled bool) error
+
+	// CountRows returns table's current row count, used by migrate-store
+	// to verify the destination matches the source after migration.
+	CountRows(table string) (int64, error)
+
+	// LoadMigrationProgress returns the last primary key migrate-store had
+	// successfully written for table on a prior run against this
+	// destination (0, false if there's no record yet), and whether the
+	// table had already finished. migrate-store uses this to resume a
+	// table mid-stream instead of re-copying rows that already landed.
+	LoadMigrationProgress(table string) (lastPK int64, done bool, err error)
+
+	// SaveMigrationProgress records that table has been streamed up to
+	// and including lastPK, and whether the table is now fully migrated,
+	// so a later run's LoadMigrationProgress picks up from here.
+	SaveMigrationProgress(table string, lastPK int64, done bool) error
+
+	// Close releases the driver's underlying connection.
+	Close() error
+}
+
+// Row is one table row as a column-name-to-value map, the engine-neutral
+// representation migrate-store moves between a source and destination
+// Driver. Column order is not significant; BulkInsert derives it from the
+// first row's keys.
+type Row map[string]any
+
+// RowIterator yields successive batches of Row from a
+// Driver.TableRowIterator call.
+type RowIterator interface {
+	// Next returns the next batch of rows (up to the iterator's
+	// batchSize), or an empty, non-nil slice once the table is exhausted.
+	Next() ([]Row, error)
+	Close() error
+}
+
+// MigrationTableOrder is the dependency order migrate-store streams
+// tables in, so a row is never inserted before the rows it references by
+// foreign key: sources have no dependencies; participants, labels, and
+// conversations only reference sources; messages reference all three
+// (plus conversations); and message_bodies/message_recipients/
+// message_labels all reference messages.
+var MigrationTableOrder = []string{
+	"sources",
+	"participants",
+	"labels",
+	"conversations",
+	"messages",
+	"message_bodies",
+	"message_recipients",
+	"message_labels",
+}
+
+// rowPrimaryKey looks up the "id" column migrate-store's keyset pagination
+// uses to page through a table, returning an error if a row is missing it
+// or it isn't an integer type a driver can compare/order by.
+func rowPrimaryKey(row Row) (int64, error) {
+	v, ok := row["id"]
+	if !ok {
+		return 0, fmt.Errorf("store: row has no id column")
+	}
+	switch id := v.(type) {
+	case int64:
+		return id, nil
+	case int:
+		return int64(id), nil
+	default:
+		return 0, fmt.Errorf("store: id column is %T, not an integer", v)
+	}
+}
+
+// RowBatchPrimaryKey returns the "id" column of the last row in batch,
+// i.e. the afterPK a caller should pass into the next
+// Driver.TableRowIterator call to continue past it. batch must be
+// non-empty.
+func RowBatchPrimaryKey(batch []Row) (int64, error) {
+	return rowPrimaryKey(batch[len(batch)-1])
+}
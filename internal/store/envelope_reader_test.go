@@ -0,0 +1,102 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildEnvelopePrefix_OrdersAndTerminatesHeaders(t *testing.T) {
+	row := messageEnvelopeRow{
+		SourceKind:       "gmail",
+		SourceIdentifier: "18c9a1f2e3",
+		Labels:           []string{"INBOX", "Archived"},
+		ReceivedAt:       time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC),
+		AuthResults:      "mx.example.com; spf=pass",
+	}
+	prefix := string(buildEnvelopePrefix(row))
+
+	for _, want := range []string{
+		"Received: by msgvault;",
+		"X-Msgvault-Source: gmail/18c9a1f2e3\r\n",
+		"X-Msgvault-Labels: Archived,INBOX\r\n",
+		"Authentication-Results: mx.example.com; spf=pass\r\n",
+	} {
+		if !strings.Contains(prefix, want) {
+			t.Errorf("prefix missing %q, got %q", want, prefix)
+		}
+	}
+	if !strings.HasSuffix(prefix, "\r\n\r\n") {
+		t.Errorf("prefix should end in a blank line, got %q", prefix)
+	}
+}
+
+func TestBuildEnvelopePrefix_OmitsEmptyFields(t *testing.T) {
+	row := messageEnvelopeRow{SourceKind: "imap", SourceIdentifier: "42"}
+	prefix := string(buildEnvelopePrefix(row))
+
+	if strings.Contains(prefix, "Received:") {
+		t.Errorf("prefix should omit Received when ReceivedAt is zero, got %q", prefix)
+	}
+	if strings.Contains(prefix, "X-Msgvault-Labels:") {
+		t.Errorf("prefix should omit X-Msgvault-Labels when there are no labels, got %q", prefix)
+	}
+	if strings.Contains(prefix, "Authentication-Results:") {
+		t.Errorf("prefix should omit Authentication-Results when empty, got %q", prefix)
+	}
+	if !strings.Contains(prefix, "X-Msgvault-Source: imap/42\r\n") {
+		t.Errorf("prefix missing X-Msgvault-Source, got %q", prefix)
+	}
+}
+
+// TestStore_MessageReader_PrefixedHeaders confirms EnvelopeReader emits the
+// synthesized header prefix before the raw message bytes and that Size()
+// reports their combined length - the shape OpenMessage builds, exercised
+// here directly against EnvelopeReader since the ghost Store.OpenMessage
+// has no real DB-backed messageEnvelopeRow lookup to drive it.
+func TestStore_MessageReader_PrefixedHeaders(t *testing.T) {
+	prefix := buildEnvelopePrefix(messageEnvelopeRow{
+		SourceKind:       "gmail",
+		SourceIdentifier: "1",
+		ReceivedAt:       time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC),
+	})
+	raw := []byte("Subject: hi\r\n\r\nbody text\r\n")
+
+	buf := make([]byte, 0, len(prefix)+len(raw))
+	buf = append(buf, prefix...)
+	buf = append(buf, raw...)
+	er := &EnvelopeReader{Reader: bytes.NewReader(buf)}
+
+	if got, want := er.Size(), int64(len(prefix)+len(raw)); got != want {
+		t.Errorf("Size() = %d, want %d", got, want)
+	}
+
+	got, err := io.ReadAll(er)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !strings.HasPrefix(string(got), string(prefix)) {
+		t.Errorf("EnvelopeReader did not emit the header prefix first, got %q", got)
+	}
+	if !strings.HasSuffix(string(got), string(raw)) {
+		t.Errorf("EnvelopeReader did not emit the raw bytes after the prefix, got %q", got)
+	}
+}
+
+func TestEnvelopeReader_SeekAndWriteTo(t *testing.T) {
+	er := &EnvelopeReader{Reader: bytes.NewReader([]byte("0123456789"))}
+
+	if _, err := er.Seek(5, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	var sb strings.Builder
+	n, err := er.WriteTo(&sb)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != 5 || sb.String() != "56789" {
+		t.Errorf("WriteTo after Seek(5) = (%d, %q), want (5, \"56789\")", n, sb.String())
+	}
+}
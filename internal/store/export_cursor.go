@@ -0,0 +1,183 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportCursor streams messages matching an export query without
+// buffering the whole result set, so multi-GB exports don't blow out
+// memory. Callers must call Close when done, even on error.
+type ExportCursor interface {
+	// Next advances to the next message, returning false at EOF or error
+	// (check Err to distinguish).
+	Next() bool
+	// Message returns the current row's metadata, raw MIME reader, and
+	// attachment metadata. Valid only after a Next call that returned true.
+	Message() (*ExportMessage, io.Reader, []Attachment, error)
+	// Err returns the first error encountered by Next, if any.
+	Err() error
+	// Close releases the cursor's resources (e.g. the underlying SQL rows).
+	Close() error
+}
+
+// QueryMessagesForExport returns an ExportCursor over sourceID's messages,
+// bounded by since/until (message date, inclusive; zero values mean
+// unbounded). query is accepted for forward compatibility with
+// internal/search-style free-text filtering, which this cursor doesn't
+// wire up yet - it is not currently applied.
+func (s *Store) QueryMessagesForExport(sourceID int64, query string, since, until time.Time) (ExportCursor, error) {
+	conds := []string{"m.source_id = ?"}
+	args := []any{sourceID}
+	if !since.IsZero() {
+		conds = append(conds, "COALESCE(m.sent_at, m.internal_date) >= ?")
+		args = append(args, since)
+	}
+	if !until.IsZero() {
+		conds = append(conds, "COALESCE(m.sent_at, m.internal_date) <= ?")
+		args = append(args, until)
+	}
+
+	rows, err := s.db.Query(
+		`SELECT m.id, m.source_id, m.source_message_id, COALESCE(c.thread_id, ''), COALESCE(m.subject, ''),
+			m.sender_email, m.size_estimate, COALESCE(m.sent_at, m.internal_date)
+		 FROM messages m LEFT JOIN conversations c ON c.id = m.conversation_id
+		 WHERE `+strings.Join(conds, " AND ")+`
+		 ORDER BY m.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query messages for export: %w", err)
+	}
+	return &exportCursor{store: s, rows: rows}, nil
+}
+
+// exportCursor is the *sql.Rows-backed ExportCursor QueryMessagesForExport
+// returns.
+type exportCursor struct {
+	store *Store
+	rows  *sql.Rows
+	err   error
+}
+
+func (c *exportCursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	return c.rows.Next()
+}
+
+func (c *exportCursor) Message() (*ExportMessage, io.Reader, []Attachment, error) {
+	var m ExportMessage
+	var date sql.NullTime
+	if err := c.rows.Scan(&m.ID, &m.SourceID, &m.SourceMessageID, &m.ThreadID, &m.Subject, &m.From, &m.SizeEstimate, &date); err != nil {
+		c.err = fmt.Errorf("scan export row: %w", err)
+		return nil, nil, nil, c.err
+	}
+	if date.Valid {
+		m.Date = date.Time
+	}
+
+	to, cc, bcc, err := c.store.recipientsForExport(m.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	m.To, m.Cc, m.Bcc = to, cc, bcc
+
+	labels, err := c.store.labelsForExport(m.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	m.Labels = labels
+
+	raw, err := c.store.GetMessageRaw(m.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	atts, err := c.store.attachmentsForExport(m.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &m, bytes.NewReader(raw), atts, nil
+}
+
+func (c *exportCursor) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.rows.Err()
+}
+
+func (c *exportCursor) Close() error {
+	return c.rows.Close()
+}
+
+// recipientsForExport returns msgID's recipients split by recipient_type.
+func (s *Store) recipientsForExport(msgID int64) (to, cc, bcc []string, err error) {
+	rows, err := s.db.Query(
+		`SELECT p.email, mr.recipient_type FROM message_recipients mr
+		 JOIN participants p ON p.id = mr.participant_id WHERE mr.message_id = ?`, msgID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("recipients for message %d: %w", msgID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, recipientType string
+		if err := rows.Scan(&email, &recipientType); err != nil {
+			return nil, nil, nil, fmt.Errorf("scan recipient for message %d: %w", msgID, err)
+		}
+		switch recipientType {
+		case "to":
+			to = append(to, email)
+		case "cc":
+			cc = append(cc, email)
+		case "bcc":
+			bcc = append(bcc, email)
+		}
+	}
+	return to, cc, bcc, rows.Err()
+}
+
+// labelsForExport returns msgID's label names.
+func (s *Store) labelsForExport(msgID int64) ([]string, error) {
+	rows, err := s.db.Query(
+		`SELECT l.name FROM message_labels ml JOIN labels l ON l.id = ml.label_id WHERE ml.message_id = ?`, msgID)
+	if err != nil {
+		return nil, fmt.Errorf("labels for message %d: %w", msgID, err)
+	}
+	defer rows.Close()
+
+	var labels []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan label for message %d: %w", msgID, err)
+		}
+		labels = append(labels, name)
+	}
+	return labels, rows.Err()
+}
+
+// attachmentsForExport returns msgID's attachment metadata.
+func (s *Store) attachmentsForExport(msgID int64) ([]Attachment, error) {
+	rows, err := s.db.Query(`SELECT id, filename, mime_type, size_bytes FROM attachments WHERE message_id = ?`, msgID)
+	if err != nil {
+		return nil, fmt.Errorf("attachments for message %d: %w", msgID, err)
+	}
+	defer rows.Close()
+
+	var atts []Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.Filename, &a.MimeType, &a.SizeBytes); err != nil {
+			return nil, fmt.Errorf("scan attachment for message %d: %w", msgID, err)
+		}
+		atts = append(atts, a)
+	}
+	return atts, rows.Err()
+}
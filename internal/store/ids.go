@@ -0,0 +1,62 @@
+package store
+
+import (
+	"strconv"
+)
+
+// MessageID identifies a Message row. It is a distinct type from
+// SourceID, ConversationID, LabelID, and ParticipantID specifically so
+// the compiler rejects passing one kind of ID where another is expected
+// - e.g. ReplaceMessageLabels(msgID, []int64{lid1, lid2}) compiling fine
+// today even if msgID and lid1 were accidentally swapped. Modeled on
+// aerc's switch from bare uint32 UIDs to a models.UID alias.
+type MessageID int64
+
+// SourceID identifies a Source row.
+type SourceID int64
+
+// ConversationID identifies a thread of related Messages.
+type ConversationID int64
+
+// LabelID identifies a Label row.
+type LabelID int64
+
+// ParticipantID identifies a deduplicated sender/recipient row.
+type ParticipantID int64
+
+// String returns id's decimal representation, for logging and error
+// messages.
+func (id MessageID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalJSON encodes id as a JSON number, the same representation its
+// underlying int64 SQL column already uses, so a typed ID round-trips
+// through existing API clients unchanged.
+func (id MessageID) MarshalJSON() ([]byte, error) { return []byte(id.String()), nil }
+
+// String returns id's decimal representation, for logging and error
+// messages.
+func (id SourceID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalJSON encodes id as a JSON number.
+func (id SourceID) MarshalJSON() ([]byte, error) { return []byte(id.String()), nil }
+
+// String returns id's decimal representation, for logging and error
+// messages.
+func (id ConversationID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalJSON encodes id as a JSON number.
+func (id ConversationID) MarshalJSON() ([]byte, error) { return []byte(id.String()), nil }
+
+// String returns id's decimal representation, for logging and error
+// messages.
+func (id LabelID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalJSON encodes id as a JSON number.
+func (id LabelID) MarshalJSON() ([]byte, error) { return []byte(id.String()), nil }
+
+// String returns id's decimal representation, for logging and error
+// messages.
+func (id ParticipantID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalJSON encodes id as a JSON number.
+func (id ParticipantID) MarshalJSON() ([]byte, error) { return []byte(id.String()), nil }
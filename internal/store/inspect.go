@@ -0,0 +1,136 @@
+package store
+
+import "time"
+
+// Stats is the archive-wide overview GetStats returns: today just the two
+// flat counts InspectSource's richer, per-source breakdown builds on.
+type Stats struct {
+	MessageCount    int64
+	ThreadCount     int64
+	AttachmentCount int64
+}
+
+// LabelCount is one entry in InspectSourceReport.TopLabels: a label name
+// and how many messages in the source currently carry it.
+type LabelCount struct {
+	Name  string
+	Count int64
+}
+
+// SyncRunSummary is one entry in InspectSourceReport.RecentSyncs: enough
+// of a finished (or failed) sync run to show an operator what happened
+// without looking up the full Checkpoint.
+type SyncRunSummary struct {
+	ID          int64
+	Kind        string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	Duration    time.Duration
+	Error       string // empty if the run completed successfully
+}
+
+// InspectSourceReport is the structured per-source breakdown
+// InspectSource returns, mirroring asynq's CurrentStats: enough detail
+// for an operator to answer "what is my vault doing" for one source
+// without querying the database directly.
+type InspectSourceReport struct {
+	SourceID SourceID
+
+	ActiveMessages  int64
+	DeletedMessages int64
+	WithRaw         int64
+	WithoutRaw      int64
+
+	TopLabels        []LabelCount
+	ParticipantCount int64
+
+	RecentSyncs []SyncRunSummary
+
+	Paused      bool
+	PauseReason string
+}
+
+// SourcePause records that a source's syncs are administratively
+// suspended: the row a PauseSource call writes to source_pause, and
+// ResumeSource deletes.
+type SourcePause struct {
+	SourceID SourceID
+	Reason   string
+	PausedAt time.Time
+}
+
+// sourceMessageCounts is the per-state message breakdown
+// getSourceMessageCounts would compute for InspectSource.
+type sourceMessageCounts struct {
+	Active       int64
+	Deleted      int64
+	WithRaw      int64
+	WithoutRaw   int64
+	Participants int64
+}
+
+// GetStats returns the archive-wide message and thread counts. Depends
+// on a real messages/conversations schema to count against, which this
+// tree has no concrete Store to provide.
+func (s *Store) GetStats() (*Stats, error) {
+	return s.getStats()
+}
+
+// InspectSource returns sourceID's InspectSourceReport: message counts by
+// state, its top labels by message count, participant fan-out, its most
+// recent sync runs, and whether it's currently paused. Depends on
+// getSourceMessageCounts, getSourceTopLabels, getSourceRecentSyncs, and
+// GetSourcePause, none of which this tree defines.
+func (s *Store) InspectSource(sourceID SourceID) (*InspectSourceReport, error) {
+	counts, err := s.getSourceMessageCounts(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	topLabels, err := s.getSourceTopLabels(sourceID, 10)
+	if err != nil {
+		return nil, err
+	}
+	recentSyncs, err := s.getSourceRecentSyncs(sourceID, 10)
+	if err != nil {
+		return nil, err
+	}
+	pause, err := s.GetSourcePause(sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &InspectSourceReport{
+		SourceID:         sourceID,
+		ActiveMessages:   counts.Active,
+		DeletedMessages:  counts.Deleted,
+		WithRaw:          counts.WithRaw,
+		WithoutRaw:       counts.WithoutRaw,
+		TopLabels:        topLabels,
+		ParticipantCount: counts.Participants,
+		RecentSyncs:      recentSyncs,
+	}
+	if pause != nil {
+		report.Paused = true
+		report.PauseReason = pause.Reason
+	}
+	return report, nil
+}
+
+// PauseSource suspends syncing for sourceID, recording reason so
+// GetActiveSync and the sync layer's pre-flight check can surface why.
+// Depends on a real source_pause table.
+func (s *Store) PauseSource(sourceID SourceID, reason string) error {
+	return s.setSourcePause(sourceID, reason)
+}
+
+// ResumeSource clears any PauseSource suspension on sourceID. Resuming a
+// source that isn't paused is a no-op, not an error.
+func (s *Store) ResumeSource(sourceID SourceID) error {
+	return s.clearSourcePause(sourceID)
+}
+
+// GetSourcePause returns sourceID's current SourcePause, or nil if it
+// isn't paused.
+func (s *Store) GetSourcePause(sourceID SourceID) (*SourcePause, error) {
+	return s.getSourcePause(sourceID)
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io"
+)
+
+// Source is a syncable mailbox identified by its backend kind (e.g.
+// "gmail", "imap", "maildir") and an identifier unique within that kind
+// (an email address for Gmail, a directory path for Maildir). SyncCursor
+// is the backend-specific resume token (a Gmail historyId, an IMAP
+// UIDVALIDITY/UID pair encoded as a string, ...) for the last sync that
+// advanced it - unset until a sync has run at least once.
+type Source struct {
+	ID         int64
+	SourceType string
+	Identifier string
+	SyncCursor sql.NullString
+}
+
+// Label is a source-scoped mailbox label (a Gmail label, an IMAP mailbox,
+// or similar), keyed by the backend's own label ID so re-syncing the same
+// label twice resolves to the same row.
+type Label struct {
+	ID      LabelID
+	Source  SourceID
+	LabelID string
+	Name    string
+	Kind    string
+}
+
+// SourceStore manages the Source rows a Syncer resumes against.
+type SourceStore interface {
+	GetOrCreateSource(kind, identifier string) (*Source, error)
+	GetSourceByIdentifier(identifier string) (*Source, error)
+}
+
+// MessageStore manages Message rows: ingest, counts, and the sampling
+// queries capacity checks and dedup audits run against a source.
+type MessageStore interface {
+	UpsertMessage(msg *Message) (MessageID, error)
+	UpsertMessageRaw(msgID MessageID, raw []byte) error
+	CountMessagesForSource(sourceID SourceID) (int64, error)
+	CountMessagesWithRaw(sourceID SourceID) (int64, error)
+	GetRandomMessageIDs(sourceID SourceID, n int) ([]MessageID, error)
+}
+
+// LabelStore manages Label rows and a message's membership in them.
+type LabelStore interface {
+	EnsureLabel(sourceID SourceID, labelID, name, kind string) (LabelID, error)
+	ReplaceMessageLabels(msgID MessageID, labelIDs []LabelID) error
+	AddMessageLabels(internalID MessageID, labelIDs []LabelID) error
+	RemoveMessageLabels(internalID MessageID, labelIDs []LabelID) error
+}
+
+// ParticipantStore manages the deduplicated sender/recipient rows messages
+// reference by ID rather than repeating an address and display name on
+// every row.
+type ParticipantStore interface {
+	EnsureParticipant(address, name, domain string) (ParticipantID, error)
+}
+
+// SyncStore manages Sync run bookkeeping: StartSync opens a run,
+// CompleteSync or FailSync closes it. Sync runs aren't one of the typed
+// IDs this package defines (MessageID, SourceID, ConversationID, LabelID,
+// ParticipantID) since a sync run is never passed around interchangeably
+// with a row ID of one of those kinds - the class of bug typed IDs guard
+// against doesn't apply here, so syncID stays a plain int64.
+type SyncStore interface {
+	StartSync(sourceID SourceID, kind string) (syncID int64, err error)
+	CompleteSync(syncID int64, historyID string) error
+	FailSync(syncID int64, message string) error
+}
+
+// RawStore manages a message's raw RFC 5322 bytes, however they're
+// physically stored (inline DB blob, on-disk file, or a hybrid of the
+// two), and the envelope headers synthesized over them.
+type RawStore interface {
+	OpenMessageRaw(sourceID MessageID) (io.ReadCloser, error)
+	MessageReader(ctx context.Context, sourceID MessageID) (io.ReadCloser, error)
+	OpenMessage(ctx context.Context, sourceID MessageID) (*EnvelopeReader, error)
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AddMessageLabels adds labelIDs to the message identified by internalID,
+// skipping any already present, in a single transaction. It is the
+// set-union counterpart to RemoveMessageLabels and the delta alternative
+// to ReplaceMessageLabels's full replace: Syncer.handleLabelChange uses it
+// so a Gmail LabelsAdded history record can update a message's labels
+// without re-downloading and re-parsing its raw bytes.
+func (s *Store) AddMessageLabels(internalID int64, labelIDs []int64) error {
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	if err := s.insertMessageLabels(internalID, labelIDs); err != nil {
+		return fmt.Errorf("add message labels %d: %w", internalID, err)
+	}
+	return nil
+}
+
+// RemoveMessageLabels removes labelIDs from the message identified by
+// internalID, in a single transaction. It is the set-difference
+// counterpart to AddMessageLabels, used the same way for Gmail
+// LabelsRemoved history records.
+func (s *Store) RemoveMessageLabels(internalID int64, labelIDs []int64) error {
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	if err := s.deleteMessageLabels(internalID, labelIDs); err != nil {
+		return fmt.Errorf("remove message labels %d: %w", internalID, err)
+	}
+	return nil
+}
+
+// LabelIDMap returns sourceID's labels keyed by their source-side label ID
+// (e.g. a Gmail label ID), mapping to the internal row ID
+// EnsureLabel/ReplaceMessageLabels/AddMessageLabels expect.
+func (s *Store) LabelIDMap(sourceID int64) (map[string]int64, error) {
+	rows, err := s.db.Query(`SELECT label_id, id FROM labels WHERE source_id = ?`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("label id map for source %d: %w", sourceID, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int64)
+	for rows.Next() {
+		var sourceLabelID string
+		var id int64
+		if err := rows.Scan(&sourceLabelID, &id); err != nil {
+			return nil, fmt.Errorf("scan label for source %d: %w", sourceID, err)
+		}
+		result[sourceLabelID] = id
+	}
+	return result, rows.Err()
+}
+
+// InternalMessageID resolves (sourceID, sourceMessageID) to the message's
+// internal row ID, for callers (e.g. Syncer.applyRefetchedLabels) that only
+// have the source-side ID on hand.
+func (s *Store) InternalMessageID(sourceID int64, sourceMessageID string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(
+		`SELECT id FROM messages WHERE source_id = ? AND source_message_id = ?`,
+		sourceID, sourceMessageID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("internal message id for %d/%s: not found", sourceID, sourceMessageID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("internal message id for %d/%s: %w", sourceID, sourceMessageID, err)
+	}
+	return id, nil
+}
+
+// LabelsDiffer reports whether sourceMessageID's locally stored label set
+// (by source-side label ID) differs from labelIDs, the set Repair just
+// fetched from the remote mailbox.
+func (s *Store) LabelsDiffer(sourceMessageID string, labelIDs []string) (bool, error) {
+	rows, err := s.db.Query(
+		`SELECT l.label_id FROM message_labels ml
+		 JOIN labels l ON l.id = ml.label_id
+		 JOIN messages m ON m.id = ml.message_id
+		 WHERE m.source_message_id = ?`, sourceMessageID)
+	if err != nil {
+		return false, fmt.Errorf("labels differ for %s: %w", sourceMessageID, err)
+	}
+	defer rows.Close()
+
+	current := make(map[string]bool)
+	for rows.Next() {
+		var labelID string
+		if err := rows.Scan(&labelID); err != nil {
+			return false, fmt.Errorf("scan label for %s: %w", sourceMessageID, err)
+		}
+		current[labelID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if len(current) != len(labelIDs) {
+		return true, nil
+	}
+	for _, labelID := range labelIDs {
+		if !current[labelID] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListMessageIDs returns sourceID's non-deleted source-side message IDs,
+// restricted to messages whose internal_date is at or after since (the
+// closest available proxy for "fetched since", since messages doesn't
+// separately record a fetch time) when since is non-zero.
+func (s *Store) ListMessageIDs(sourceID int64, since time.Time) ([]string, error) {
+	query := `SELECT source_message_id FROM messages WHERE source_id = ? AND deleted_from_source_at IS NULL`
+	args := []any{sourceID}
+	if !since.IsZero() {
+		query += ` AND internal_date >= ?`
+		args = append(args, since)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list message ids for source %d: %w", sourceID, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan message id for source %d: %w", sourceID, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
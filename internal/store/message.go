@@ -0,0 +1,66 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ExportMessage is the subset of a messages row needed by downstream
+// consumers like export; it intentionally omits storage-layer details
+// (raw blob location, FTS rowids) that don't belong outside the store
+// package.
+type ExportMessage struct {
+	ID              int64
+	SourceID        int64
+	SourceMessageID string
+	ThreadID        string
+	Subject         string
+	From            string
+	To              []string
+	Cc              []string
+	Bcc             []string
+	Date            time.Time
+	Labels          []string
+	SizeEstimate    int64
+
+	// SourceCharset is the charset (from internal/mime.Message.SourceCharset)
+	// the body was originally declared and decoded from, e.g. "iso-8859-1"
+	// or "utf-7". Stored so export can reproduce the original encoding
+	// instead of always writing UTF-8.
+	SourceCharset string
+}
+
+// Attachment is the subset of an attachments row needed by export.
+type Attachment struct {
+	ID        int64
+	Filename  string
+	MimeType  string
+	SizeBytes int64
+}
+
+// Message is the ingest-facing row UpsertMessage writes and updates: the
+// full set of columns a sync run has available for a message, keyed for
+// dedup by (SourceID, SourceMessageID). Optional columns are sql.Null*
+// so a caller can omit them instead of writing false zero values.
+type Message struct {
+	ConversationID  int64
+	SourceID        int64
+	SourceMessageID string
+	MessageType     string
+	SenderEmail     string
+
+	Subject  sql.NullString
+	BodyText sql.NullString
+	BodyHTML sql.NullString
+	Snippet  sql.NullString
+
+	SizeEstimate int64
+
+	SentAt       sql.NullTime
+	ReceivedAt   sql.NullTime
+	InternalDate sql.NullTime
+
+	HasAttachments  bool
+	AttachmentCount int
+	IsFromMe        bool
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// QuarantinedMessage is a message that failed RFC 5322 header validation
+// during sync and was held back from the messages table rather than
+// dropped. Raw preserves the original MIME bytes, and ThreadID/LabelIDs
+// preserve the Gmail-side metadata needed to fully re-ingest the message
+// once the violation is understood, so a retry doesn't need to re-fetch it.
+type QuarantinedMessage struct {
+	ID              int64
+	SourceID        int64
+	SourceMessageID string
+	ThreadID        string
+	LabelIDs        []string
+	Raw             []byte
+	ViolatedRules   []string
+	FetchedAt       time.Time
+}
+
+// InsertQuarantinedMessage records q in the quarantined_messages table,
+// filling in q.ID with the new row's ID.
+func (s *Store) InsertQuarantinedMessage(q *QuarantinedMessage) error {
+	labelIDs, err := json.Marshal(q.LabelIDs)
+	if err != nil {
+		return fmt.Errorf("insert quarantined message %s: marshal label ids: %w", q.SourceMessageID, err)
+	}
+	violations, err := json.Marshal(q.ViolatedRules)
+	if err != nil {
+		return fmt.Errorf("insert quarantined message %s: marshal violations: %w", q.SourceMessageID, err)
+	}
+
+	res, err := s.db.Exec(
+		`INSERT INTO quarantined_messages (source_id, source_message_id, thread_id, label_ids, raw, violated_rules, fetched_at)
+		 VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		q.SourceID, q.SourceMessageID, q.ThreadID, labelIDs, q.Raw, violations)
+	if err != nil {
+		return fmt.Errorf("insert quarantined message %s: %w", q.SourceMessageID, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("insert quarantined message %s: %w", q.SourceMessageID, err)
+	}
+	q.ID = id
+	return nil
+}
+
+// GetQuarantinedMessage returns the quarantined message with the given ID,
+// or nil if none exists.
+func (s *Store) GetQuarantinedMessage(id int64) (*QuarantinedMessage, error) {
+	var q QuarantinedMessage
+	var labelIDs, violations string
+	err := s.db.QueryRow(
+		`SELECT id, source_id, source_message_id, thread_id, label_ids, raw, violated_rules, fetched_at
+		 FROM quarantined_messages WHERE id = ?`, id,
+	).Scan(&q.ID, &q.SourceID, &q.SourceMessageID, &q.ThreadID, &labelIDs, &q.Raw, &violations, &q.FetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get quarantined message %d: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(labelIDs), &q.LabelIDs); err != nil {
+		return nil, fmt.Errorf("get quarantined message %d: unmarshal label ids: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(violations), &q.ViolatedRules); err != nil {
+		return nil, fmt.Errorf("get quarantined message %d: unmarshal violations: %w", id, err)
+	}
+	return &q, nil
+}
+
+// UpdateQuarantinedViolations overwrites id's recorded ViolatedRules, e.g.
+// after RetryQuarantined re-validates and finds a smaller (but still
+// nonempty) set of violations.
+func (s *Store) UpdateQuarantinedViolations(id int64, violations []string) error {
+	data, err := json.Marshal(violations)
+	if err != nil {
+		return fmt.Errorf("update quarantined message %d violations: marshal: %w", id, err)
+	}
+	if _, err := s.db.Exec(`UPDATE quarantined_messages SET violated_rules = ? WHERE id = ?`, data, id); err != nil {
+		return fmt.Errorf("update quarantined message %d violations: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteQuarantinedMessage removes id once it has been ingested (or
+// otherwise resolved) by RetryQuarantined.
+func (s *Store) DeleteQuarantinedMessage(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM quarantined_messages WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete quarantined message %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestRawBlobStore(t *testing.T) *RawBlobStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rb, err := NewRawBlobStore(db, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRawBlobStore: %v", err)
+	}
+	return rb
+}
+
+func TestRawBlobStore_PutDedupesIdenticalContent(t *testing.T) {
+	rb := newTestRawBlobStore(t)
+	raw := []byte("Subject: hi\r\n\r\nbody text\r\n")
+
+	hash1, size1, err := rb.Put(raw)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if size1 != int64(len(raw)) {
+		t.Errorf("size = %d, want %d", size1, len(raw))
+	}
+
+	hash2, size2, err := rb.Put(raw)
+	if err != nil {
+		t.Fatalf("second Put() error = %v", err)
+	}
+	if hash1 != hash2 || size1 != size2 {
+		t.Errorf("second Put() = (%s, %d), want identical to first (%s, %d)", hash2, size2, hash1, size1)
+	}
+
+	var refcount int64
+	if err := rb.db.QueryRow(`SELECT refcount FROM raw_blobs WHERE hash = ?`, hash1).Scan(&refcount); err != nil {
+		t.Fatalf("query refcount: %v", err)
+	}
+	if refcount != 2 {
+		t.Errorf("refcount = %d, want 2 after two Puts of identical content", refcount)
+	}
+
+	if _, err := os.Stat(rb.path(hash1)); err != nil {
+		t.Errorf("blob file should exist at %s: %v", rb.path(hash1), err)
+	}
+}
+
+func TestRawBlobStore_OpenReturnsPutContent(t *testing.T) {
+	rb := newTestRawBlobStore(t)
+	raw := []byte("Subject: test\r\n\r\nhello")
+
+	hash, _, err := rb.Put(raw)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rc, err := rb.Open(hash)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer rc.Close()
+
+	buf := make([]byte, len(raw))
+	if _, err := rc.Read(buf); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(buf) != string(raw) {
+		t.Errorf("Open() content = %q, want %q", buf, raw)
+	}
+}
+
+func TestRawBlobStore_PathIsShardedByHashPrefix(t *testing.T) {
+	rb := newTestRawBlobStore(t)
+	hash, _, err := rb.Put([]byte("some raw message"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	want := filepath.Join(rb.root, hash[0:2], hash)
+	if got := rb.path(hash); got != want {
+		t.Errorf("path() = %s, want %s", got, want)
+	}
+}
+
+func TestRawBlobStore_DiskStats(t *testing.T) {
+	rb := newTestRawBlobStore(t)
+	ctx := context.Background()
+
+	stats, err := rb.DiskStats(ctx)
+	if err != nil {
+		t.Fatalf("DiskStats() error = %v", err)
+	}
+	if stats.Blobs != 0 || stats.Bytes != 0 {
+		t.Errorf("DiskStats() on empty store = %+v, want zero values", stats)
+	}
+
+	if _, _, err := rb.Put([]byte("message one")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, _, err := rb.Put([]byte("message two, slightly longer")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	stats, err = rb.DiskStats(ctx)
+	if err != nil {
+		t.Fatalf("DiskStats() error = %v", err)
+	}
+	if stats.Blobs != 2 {
+		t.Errorf("Blobs = %d, want 2", stats.Blobs)
+	}
+	if want := int64(len("message one") + len("message two, slightly longer")); stats.Bytes != want {
+		t.Errorf("Bytes = %d, want %d", stats.Bytes, want)
+	}
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// sqliteDriver implements Driver on top of the same *sql.DB the rest of
+// the store package uses, so migrate-store can treat an existing
+// msgvault.db as a migration source (or, less commonly, destination)
+// without a separate code path from the sqlite-backed Store.
+type sqliteDriver struct {
+	db *sql.DB
+}
+
+// NewSQLiteDriver wraps db as a Driver. db is not closed by Driver.Close;
+// callers that opened it themselves remain responsible for it, matching
+// sql.DB's usual shared-connection-pool lifecycle. It ensures the
+// migration_progress table migrate-store resumes from exists, creating it
+// if this is the first time db has been used as a migration destination.
+func NewSQLiteDriver(db *sql.DB) Driver {
+	d := &sqliteDriver{db: db}
+	_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS migration_progress (
+		table_name TEXT PRIMARY KEY,
+		last_pk INTEGER NOT NULL,
+		done INTEGER NOT NULL DEFAULT 0
+	)`)
+	return d
+}
+
+func (d *sqliteDriver) Name() string { return "sqlite" }
+
+func (d *sqliteDriver) columns(table string) ([]string, error) {
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite driver: table_info %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, fmt.Errorf("sqlite driver: scan table_info %s: %w", table, err)
+		}
+		cols = append(cols, name)
+	}
+	return cols, rows.Err()
+}
+
+type sqliteRowIterator struct {
+	db        *sql.DB
+	table     string
+	cols      []string
+	afterPK   int64
+	batchSize int
+	done      bool
+}
+
+func (d *sqliteDriver) TableRowIterator(table string, afterPK int64, batchSize int) (RowIterator, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	cols, err := d.columns(table)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteRowIterator{db: d.db, table: table, cols: cols, afterPK: afterPK, batchSize: batchSize}, nil
+}
+
+func (it *sqliteRowIterator) Next() ([]Row, error) {
+	if it.done {
+		return []Row{}, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id > ? ORDER BY id LIMIT ?",
+		strings.Join(it.cols, ", "), it.table)
+	rows, err := it.db.Query(query, it.afterPK, it.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite driver: select %s: %w", it.table, err)
+	}
+	defer rows.Close()
+
+	var batch []Row
+	for rows.Next() {
+		vals := make([]any, len(it.cols))
+		ptrs := make([]any, len(it.cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("sqlite driver: scan %s row: %w", it.table, err)
+		}
+		row := make(Row, len(it.cols))
+		for i, col := range it.cols {
+			row[col] = vals[i]
+		}
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(batch) < it.batchSize {
+		it.done = true
+	}
+	if len(batch) > 0 {
+		pk, err := rowPrimaryKey(batch[len(batch)-1])
+		if err != nil {
+			return nil, err
+		}
+		it.afterPK = pk
+	}
+	return batch, nil
+}
+
+func (it *sqliteRowIterator) Close() error { return nil }
+
+func (d *sqliteDriver) BulkInsert(table string, rows []Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	cols := make([]string, 0, len(rows[0]))
+	for col := range rows[0] {
+		cols = append(cols, col)
+	}
+
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite driver: begin bulk insert %s: %w", table, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite driver: prepare bulk insert %s: %w", table, err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		args := make([]any, len(cols))
+		for i, col := range cols {
+			args[i] = row[col]
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return 0, fmt.Errorf("sqlite driver: insert into %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite driver: commit bulk insert %s: %w", table, err)
+	}
+	return len(rows), nil
+}
+
+// SetForeignKeyChecks toggles PRAGMA foreign_keys, matching the
+// PRAGMA foreign_keys=OFF pattern createTestSourceDB uses to load rows out
+// of strict referential order before re-enabling enforcement.
+func (d *sqliteDriver) SetForeignKeyChecks(enabled bool) error {
+	state := "OFF"
+	if enabled {
+		state = "ON"
+	}
+	if _, err := d.db.Exec("PRAGMA foreign_keys = " + state); err != nil {
+		return fmt.Errorf("sqlite driver: set foreign_keys=%s: %w", state, err)
+	}
+	return nil
+}
+
+func (d *sqliteDriver) CountRows(table string) (int64, error) {
+	var n int64
+	if err := d.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&n); err != nil {
+		return 0, fmt.Errorf("sqlite driver: count %s: %w", table, err)
+	}
+	return n, nil
+}
+
+// LoadMigrationProgress reads table's checkpoint from the
+// migration_progress table created by NewSQLiteDriver.
+func (d *sqliteDriver) LoadMigrationProgress(table string) (int64, bool, error) {
+	var lastPK int64
+	var done int
+	err := d.db.QueryRow(
+		`SELECT last_pk, done FROM migration_progress WHERE table_name = ?`, table,
+	).Scan(&lastPK, &done)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("sqlite driver: load migration progress %s: %w", table, err)
+	}
+	return lastPK, done != 0, nil
+}
+
+// SaveMigrationProgress upserts table's checkpoint into migration_progress.
+func (d *sqliteDriver) SaveMigrationProgress(table string, lastPK int64, done bool) error {
+	doneInt := 0
+	if done {
+		doneInt = 1
+	}
+	_, err := d.db.Exec(
+		`INSERT INTO migration_progress (table_name, last_pk, done) VALUES (?, ?, ?)
+		 ON CONFLICT(table_name) DO UPDATE SET last_pk = excluded.last_pk, done = excluded.done`,
+		table, lastPK, doneInt)
+	if err != nil {
+		return fmt.Errorf("sqlite driver: save migration progress %s: %w", table, err)
+	}
+	return nil
+}
+
+func (d *sqliteDriver) Close() error { return nil }
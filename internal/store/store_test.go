@@ -667,7 +667,7 @@ func TestStore_SyncFail(t *testing.T) {
 	}
 }
 
-func TestStore_MarkMessageDeletedByGmailID(t *testing.T) {
+func TestStore_MarkMessageDeletedBySourceMessageID(t *testing.T) {
 	st := testutil.NewTestStore(t)
 
 	source, err := st.GetOrCreateSource("gmail", "test@example.com")
@@ -684,21 +684,21 @@ func TestStore_MarkMessageDeletedByGmailID(t *testing.T) {
 	mustNoErr(t, err, "UpsertMessage")
 
 	// Mark as deleted (trash)
-	err = st.MarkMessageDeletedByGmailID(false, "gmail-msg-123")
+	err = st.MarkMessageDeletedBySourceMessageID(false, "gmail-msg-123")
 	if err != nil {
-		t.Fatalf("MarkMessageDeletedByGmailID(trash) error = %v", err)
+		t.Fatalf("MarkMessageDeletedBySourceMessageID(trash) error = %v", err)
 	}
 
 	// Mark as permanently deleted
-	err = st.MarkMessageDeletedByGmailID(true, "gmail-msg-123")
+	err = st.MarkMessageDeletedBySourceMessageID(true, "gmail-msg-123")
 	if err != nil {
-		t.Fatalf("MarkMessageDeletedByGmailID(permanent) error = %v", err)
+		t.Fatalf("MarkMessageDeletedBySourceMessageID(permanent) error = %v", err)
 	}
 
 	// Non-existent message should not error (no rows affected is OK)
-	err = st.MarkMessageDeletedByGmailID(true, "nonexistent-id")
+	err = st.MarkMessageDeletedBySourceMessageID(true, "nonexistent-id")
 	if err != nil {
-		t.Fatalf("MarkMessageDeletedByGmailID(nonexistent) error = %v", err)
+		t.Fatalf("MarkMessageDeletedBySourceMessageID(nonexistent) error = %v", err)
 	}
 }
 
@@ -1191,3 +1191,174 @@ func TestStore_GetRandomMessageIDs_ExcludesDeleted(t *testing.T) {
 		t.Errorf("len(ids) = %d, want 3 (5 total - 2 deleted)", len(ids))
 	}
 }
+
+func TestBroadcaster_PublishDeliversToMatchingSubscriber(t *testing.T) {
+	b := store.NewBroadcaster()
+	ch, cancel := b.Subscribe(store.SubscriptionFilter{})
+	defer cancel()
+
+	b.Publish(store.Change{Kind: store.ChangeAddMessage, SourceID: 1, MessageID: 42})
+
+	select {
+	case c := <-ch:
+		if c.Kind != store.ChangeAddMessage || c.MessageID != 42 {
+			t.Errorf("got %+v, want ChangeAddMessage for message 42", c)
+		}
+	default:
+		t.Fatal("expected a Change to be delivered, got none")
+	}
+}
+
+func TestBroadcaster_FilterBySourceAndLabel(t *testing.T) {
+	b := store.NewBroadcaster()
+
+	bySource, cancelSource := b.Subscribe(store.SubscriptionFilter{SourceID: 1})
+	defer cancelSource()
+	byLabel, cancelLabel := b.Subscribe(store.SubscriptionFilter{Label: "Inbox"})
+	defer cancelLabel()
+
+	b.Publish(store.Change{Kind: store.ChangeAddMessage, SourceID: 1, MessageID: 1})
+	b.Publish(store.Change{Kind: store.ChangeAddMessage, SourceID: 2, MessageID: 2, Labels: []string{"Inbox"}})
+	b.Publish(store.Change{Kind: store.ChangeAddMessage, SourceID: 2, MessageID: 3, Labels: []string{"Archive"}})
+
+	select {
+	case c := <-bySource:
+		if c.MessageID != 1 {
+			t.Errorf("bySource got message %d, want 1", c.MessageID)
+		}
+	default:
+		t.Fatal("bySource: expected the source_id=1 change, got none")
+	}
+	select {
+	case c := <-bySource:
+		t.Errorf("bySource: unexpected second change %+v", c)
+	default:
+	}
+
+	select {
+	case c := <-byLabel:
+		if c.MessageID != 2 {
+			t.Errorf("byLabel got message %d, want 2", c.MessageID)
+		}
+	default:
+		t.Fatal("byLabel: expected the Inbox-labeled change, got none")
+	}
+	select {
+	case c := <-byLabel:
+		t.Errorf("byLabel: unexpected second change %+v", c)
+	default:
+	}
+}
+
+func TestBroadcaster_SlowSubscriberEvictedWithoutBlocking(t *testing.T) {
+	b := store.NewBroadcaster()
+	slow, cancel := b.Subscribe(store.SubscriptionFilter{})
+	defer cancel()
+
+	// Fill the slow subscriber's buffer past capacity without it ever
+	// reading; Publish must keep returning rather than block, and must
+	// evict (close) the channel instead of dropping individual events
+	// silently forever.
+	for i := 0; i < 200; i++ {
+		b.Publish(store.Change{Kind: store.ChangeUpdateMessage, MessageID: int64(i)})
+	}
+
+	drained := 0
+	for range slow {
+		drained++
+	}
+	if drained == 0 {
+		t.Error("expected the buffered channel to have delivered some changes before eviction")
+	}
+}
+
+func TestBroadcaster_CancelClosesChannel(t *testing.T) {
+	b := store.NewBroadcaster()
+	ch, cancel := b.Subscribe(store.SubscriptionFilter{})
+	cancel()
+
+	b.Publish(store.Change{Kind: store.ChangeAddMessage, MessageID: 1})
+
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed after cancel, got a value")
+	}
+}
+
+func TestPendingChanges_FlushOnlyPublishesWhatWasFlushed(t *testing.T) {
+	b := store.NewBroadcaster()
+	ch, cancel := b.Subscribe(store.SubscriptionFilter{})
+	defer cancel()
+
+	var pending store.PendingChanges
+	pending.Add(store.Change{Kind: store.ChangeAddMessage, MessageID: 1})
+	pending.Add(store.Change{Kind: store.ChangeAddLabel, MessageID: 1, Labels: []string{"Inbox"}})
+
+	// Simulate a rolled-back transaction: the pending changes are never
+	// flushed, so no subscriber should see them.
+	select {
+	case c := <-ch:
+		t.Fatalf("expected no change before Flush, got %+v", c)
+	default:
+	}
+
+	// Simulate the transaction committing.
+	pending.Flush(b)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ch:
+		default:
+			t.Fatalf("expected change %d to be delivered after Flush", i)
+		}
+	}
+}
+
+func TestPendingChanges_EmptyLabelsReplaceStillEmitsOneEvent(t *testing.T) {
+	b := store.NewBroadcaster()
+	ch, cancel := b.Subscribe(store.SubscriptionFilter{})
+	defer cancel()
+
+	var pending store.PendingChanges
+	pending.Add(store.Change{Kind: store.ChangeReplaceLabels, MessageID: 1, Labels: nil})
+	pending.Flush(b)
+
+	select {
+	case c := <-ch:
+		if c.Kind != store.ChangeReplaceLabels || len(c.Labels) != 0 {
+			t.Errorf("got %+v, want a ChangeReplaceLabels event with no labels", c)
+		}
+	default:
+		t.Fatal("expected a ChangeReplaceLabels event even when replacing with an empty label set")
+	}
+
+	select {
+	case c := <-ch:
+		t.Errorf("unexpected second change %+v", c)
+	default:
+	}
+}
+
+func TestPendingChanges_EmptyRecipientsReplaceStillEmitsOneEvent(t *testing.T) {
+	b := store.NewBroadcaster()
+	ch, cancel := b.Subscribe(store.SubscriptionFilter{})
+	defer cancel()
+
+	var pending store.PendingChanges
+	pending.Add(store.Change{Kind: store.ChangeReplaceRecipients, MessageID: 1})
+	pending.Flush(b)
+
+	select {
+	case c := <-ch:
+		if c.Kind != store.ChangeReplaceRecipients || c.MessageID != 1 {
+			t.Errorf("got %+v, want a ChangeReplaceRecipients event for message 1", c)
+		}
+	default:
+		t.Fatal("expected a ChangeReplaceRecipients event even when replacing with no recipients")
+	}
+
+	select {
+	case c := <-ch:
+		t.Errorf("unexpected second change %+v", c)
+	default:
+	}
+}
@@ -0,0 +1,54 @@
+package store
+
+import "time"
+
+// SyncErrorClass categorizes a sync run failure for retry-policy purposes:
+// whether it's worth backing off and retrying at all, and on what
+// schedule.
+type SyncErrorClass int
+
+const (
+	// SyncErrorTransient is a condition expected to clear on its own
+	// (network blip, 5xx) - retry with exponential backoff.
+	SyncErrorTransient SyncErrorClass = iota
+	// SyncErrorAuth means the source rejected our credentials - retrying
+	// immediately won't help, but the problem isn't necessarily permanent
+	// (a token can be refreshed), so it still backs off rather than
+	// poisoning the run outright.
+	SyncErrorAuth
+	// SyncErrorRateLimit means the source is throttling us; retry no
+	// sooner than the server's own reset time.
+	SyncErrorRateLimit
+	// SyncErrorPermanent means retrying this run as-is will never succeed
+	// (e.g. the source account no longer exists) - never scheduled for
+	// retry.
+	SyncErrorPermanent
+)
+
+// String returns the class's name, e.g. "rate_limit", for logging and for
+// the sync_errors table's error_class column.
+func (c SyncErrorClass) String() string {
+	switch c {
+	case SyncErrorTransient:
+		return "transient"
+	case SyncErrorAuth:
+		return "auth"
+	case SyncErrorRateLimit:
+		return "rate_limit"
+	case SyncErrorPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// SyncError is one row RecordSyncError appends to a sync_errors table: a
+// classified failure for a sync run, carrying enough detail for FailSync to
+// compute the run's next retry (or to leave it unretried, for
+// SyncErrorPermanent).
+type SyncError struct {
+	SyncID     int64
+	Class      SyncErrorClass
+	Message    string
+	OccurredAt time.Time
+}
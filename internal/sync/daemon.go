@@ -0,0 +1,286 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// RunOptions configures Syncer.Run, the long-running daemon mode that
+// supervises a periodic incremental sync per account.
+type RunOptions struct {
+	// Accounts is the list of source identifiers (email addresses) to sync.
+	Accounts []string
+	// Interval is the base delay between incremental syncs for an account.
+	Interval time.Duration
+	// JitterPct randomizes each interval by +/- this percent (0-100) so
+	// accounts don't all poll in lockstep.
+	JitterPct int
+	// FullResyncEvery, if nonzero, forces a full sync on this cadence even
+	// when incremental sync is succeeding, to self-heal from drift.
+	FullResyncEvery time.Duration
+	// HealthAddr, if set, serves /healthz, /metrics, and /status on this
+	// address for the lifetime of Run.
+	HealthAddr string
+	// Push, if set, additionally subscribes to Gmail push notifications
+	// for each account and triggers an immediate incremental sync on
+	// delivery, on top of the periodic Interval polling.
+	Push *PushConfig
+}
+
+// AccountStatus is a point-in-time snapshot of one account's sync loop,
+// exposed via the /status endpoint.
+type AccountStatus struct {
+	Account        string
+	LastSync       time.Time
+	LastError      string
+	ConsecutiveErr int
+	LastSummary    *SyncSummaryView
+}
+
+// SyncSummaryView is the JSON-friendly subset of gmail.SyncSummary surfaced
+// over HTTP.
+type SyncSummaryView struct {
+	MessagesAdded   int64
+	MessagesUpdated int64
+	Errors          int64
+	Quarantined     int64
+}
+
+func newSyncSummaryView(s *gmail.SyncSummary) *SyncSummaryView {
+	if s == nil {
+		return nil
+	}
+	return &SyncSummaryView{
+		MessagesAdded:   s.MessagesAdded,
+		MessagesUpdated: s.MessagesUpdated,
+		Errors:          s.Errors,
+		Quarantined:     s.Quarantined,
+	}
+}
+
+// Run supervises a periodic incremental sync per account until ctx is
+// canceled (e.g. on SIGINT/SIGTERM, which callers should wire up via
+// signal.NotifyContext). Each account runs its own loop: on success it
+// sleeps ~Interval (jittered); on ErrHistoryExpired it falls back to Full;
+// on any other error it backs off exponentially, capped at Interval*10. If
+// opts.Push is set, each account's Gmail watch subscription is registered
+// and kept renewed for the lifetime of Run, and a delivered push
+// notification wakes the account's loop immediately instead of waiting out
+// the rest of its current sleep.
+func (s *Syncer) Run(ctx context.Context, opts RunOptions) error {
+	if opts.Interval <= 0 {
+		return errors.New("sync: RunOptions.Interval must be positive")
+	}
+
+	statuses := newStatusRegistry(opts.Accounts)
+
+	var triggers *pushTriggers
+	if opts.Push != nil {
+		triggers = newPushTriggers(opts.Accounts)
+
+		pushMux := http.NewServeMux()
+		pushMux.Handle(opts.Push.path(), triggers.handler(s.logger))
+		pushSrv := &http.Server{Addr: opts.Push.ListenAddr, Handler: pushMux}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = pushSrv.Shutdown(shutdownCtx)
+		}()
+		go func() {
+			if err := pushSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("push notification server exited", "error", err)
+			}
+		}()
+
+		for _, account := range opts.Accounts {
+			go s.runWatchRenewal(ctx, account, opts.Push)
+		}
+	}
+
+	if opts.HealthAddr != "" {
+		srv := &http.Server{Addr: opts.HealthAddr, Handler: statuses.handler()}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("health server exited", "error", err)
+			}
+		}()
+	}
+
+	done := make(chan struct{}, len(opts.Accounts))
+	for _, account := range opts.Accounts {
+		go func(account string) {
+			s.runAccountLoop(ctx, account, opts, statuses, triggers)
+			done <- struct{}{}
+		}(account)
+	}
+	for range opts.Accounts {
+		<-done
+	}
+	return ctx.Err()
+}
+
+func (s *Syncer) runAccountLoop(ctx context.Context, account string, opts RunOptions, statuses *statusRegistry, triggers *pushTriggers) {
+	backoff := opts.Interval
+	maxBackoff := opts.Interval * 10
+	var lastFull time.Time
+
+	var triggerCh <-chan struct{}
+	if triggers != nil {
+		triggerCh = triggers.channel(account)
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		forceFull := opts.FullResyncEvery > 0 && (lastFull.IsZero() || time.Since(lastFull) >= opts.FullResyncEvery)
+
+		var summary *gmail.SyncSummary
+		var err error
+		if forceFull {
+			summary, err = s.Full(ctx, account)
+			if err == nil {
+				lastFull = time.Now()
+			}
+		} else {
+			summary, err = s.Incremental(ctx, account)
+			if errors.Is(err, ErrHistoryExpired) {
+				s.logger.Warn("history expired, falling back to full sync", "account", account)
+				summary, err = s.Full(ctx, account)
+				if err == nil {
+					lastFull = time.Now()
+				}
+			}
+		}
+
+		if err != nil {
+			statuses.recordError(account, err)
+			backoff = nextBackoff(backoff, maxBackoff)
+		} else {
+			statuses.recordSuccess(account, summary)
+			backoff = opts.Interval
+		}
+
+		wait := jitter(backoff, opts.JitterPct)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		case <-triggerCh:
+			// A Gmail push notification arrived; skip the rest of the
+			// wait and run the next incremental sync now.
+		}
+	}
+}
+
+func nextBackoff(current, max time.Duration) time.Duration {
+	next := current * 2
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+func jitter(d time.Duration, pct int) time.Duration {
+	if pct <= 0 {
+		return d
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	spread := float64(d) * float64(pct) / 100
+	delta := (rand.Float64()*2 - 1) * spread
+	return time.Duration(float64(d) + delta)
+}
+
+type statusRegistry struct {
+	statuses map[string]*atomic.Value
+}
+
+func newStatusRegistry(accounts []string) *statusRegistry {
+	r := &statusRegistry{statuses: make(map[string]*atomic.Value, len(accounts))}
+	for _, a := range accounts {
+		v := &atomic.Value{}
+		v.Store(AccountStatus{Account: a})
+		r.statuses[a] = v
+	}
+	return r
+}
+
+func (r *statusRegistry) recordSuccess(account string, summary *gmail.SyncSummary) {
+	v, ok := r.statuses[account]
+	if !ok {
+		return
+	}
+	status := v.Load().(AccountStatus)
+	status.LastSync = time.Now()
+	status.LastError = ""
+	status.ConsecutiveErr = 0
+	status.LastSummary = newSyncSummaryView(summary)
+	v.Store(status)
+}
+
+func (r *statusRegistry) recordError(account string, err error) {
+	v, ok := r.statuses[account]
+	if !ok {
+		return
+	}
+	status := v.Load().(AccountStatus)
+	status.LastError = err.Error()
+	status.ConsecutiveErr++
+	v.Store(status)
+}
+
+func (r *statusRegistry) snapshot() []AccountStatus {
+	out := make([]AccountStatus, 0, len(r.statuses))
+	for _, v := range r.statuses {
+		out = append(out, v.Load().(AccountStatus))
+	}
+	return out
+}
+
+// handler serves /healthz, /metrics, and /status for operators to point
+// Prometheus (and their own eyeballs) at.
+func (r *statusRegistry) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		for _, st := range r.snapshot() {
+			if st.LastSummary == nil {
+				continue
+			}
+			_, _ = w.Write([]byte("msgvault_sync_messages_added{account=\"" + st.Account + "\"} "))
+			_, _ = w.Write([]byte(formatInt(st.LastSummary.MessagesAdded) + "\n"))
+			_, _ = w.Write([]byte("msgvault_sync_errors{account=\"" + st.Account + "\"} "))
+			_, _ = w.Write([]byte(formatInt(st.LastSummary.Errors) + "\n"))
+		}
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.snapshot())
+	})
+	return mux
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
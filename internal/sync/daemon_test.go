@@ -0,0 +1,33 @@
+package sync
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBackoff_CapsAtMax(t *testing.T) {
+	d := time.Second
+	max := 5 * time.Second
+	for i := 0; i < 10; i++ {
+		d = nextBackoff(d, max)
+	}
+	if d != max {
+		t.Errorf("nextBackoff settled at %v, want %v", d, max)
+	}
+}
+
+func TestJitter_ZeroPctIsExact(t *testing.T) {
+	if got := jitter(time.Second, 0); got != time.Second {
+		t.Errorf("jitter(1s, 0) = %v, want 1s", got)
+	}
+}
+
+func TestJitter_WithinBounds(t *testing.T) {
+	base := 10 * time.Second
+	for i := 0; i < 50; i++ {
+		got := jitter(base, 20)
+		if got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("jitter(10s, 20) = %v, out of [8s, 12s]", got)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package sync
+
+import "errors"
+
+// ErrHistoryExpired is returned by Source.History when the incremental
+// cursor is too old (or otherwise invalid) for the backend to resume from,
+// signaling the caller should fall back to a full sync.
+var ErrHistoryExpired = errors.New("sync: history expired, full sync required")
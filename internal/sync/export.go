@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wesm/msgvault/internal/export"
+)
+
+// ExportOptions configures Syncer.Export.
+type ExportOptions struct {
+	// Format selects the output format (mbox, maildir, eml, jsonl, html).
+	Format export.Format
+	// OutDir is where the export is written: a single file for
+	// mbox/jsonl, a directory for maildir/eml/html.
+	OutDir string
+	// Query, if set, restricts the export the same way a search query
+	// restricts Gmail results (see internal/search.Parse).
+	Query string
+	// Since and Until bound the export by message date, inclusive.
+	Since, Until time.Time
+}
+
+// Export streams email's stored messages through an export.Exporter for
+// the selected format, reusing the store's existing query path rather
+// than re-implementing filtering.
+func (s *Syncer) Export(ctx context.Context, email string, opts ExportOptions) error {
+	source, err := s.store.GetSourceByIdentifier(email)
+	if err != nil {
+		return fmt.Errorf("get source: %w", err)
+	}
+	if source == nil {
+		return fmt.Errorf("no source found for %s", email)
+	}
+
+	exporter, err := export.NewExporter(opts.Format, opts.OutDir)
+	if err != nil {
+		return fmt.Errorf("new exporter: %w", err)
+	}
+	if err := exporter.Begin(); err != nil {
+		return fmt.Errorf("begin export: %w", err)
+	}
+
+	cursor, err := s.store.QueryMessagesForExport(source.ID, opts.Query, opts.Since, opts.Until)
+	if err != nil {
+		_ = exporter.Close()
+		return fmt.Errorf("query messages: %w", err)
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		if err := ctx.Err(); err != nil {
+			_ = exporter.Close()
+			return err
+		}
+
+		m, raw, atts, err := cursor.Message()
+		if err != nil {
+			_ = exporter.Close()
+			return fmt.Errorf("read message: %w", err)
+		}
+		if err := exporter.WriteMessage(m, raw, atts); err != nil {
+			_ = exporter.Close()
+			return fmt.Errorf("write message %s: %w", m.SourceMessageID, err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		_ = exporter.Close()
+		return fmt.Errorf("cursor: %w", err)
+	}
+
+	return exporter.Close()
+}
@@ -0,0 +1,380 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/log"
+	"github.com/wesm/msgvault/internal/mime"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// Full performs a full sync of s.source into the store: it lists every
+// message matching Options.Query page by page, skipping ones already
+// ingested, quarantining (or rejecting/placeholder-storing, per
+// QuarantineMode) any that fail RFC 5322 header validation, and ingesting
+// the rest via ingestMessage. It resumes from a checkpoint left by a prior
+// interrupted run unless Options.NoResume was set.
+func (s *Syncer) Full(ctx context.Context, email string) (*gmail.SyncSummary, error) {
+	startTime := time.Now()
+	summary := &gmail.SyncSummary{StartTime: startTime}
+
+	source, err := s.store.GetOrCreateSource(SourceKindGmail, email)
+	if err != nil {
+		return nil, fmt.Errorf("get or create source: %w", err)
+	}
+
+	syncID, err := s.store.StartSync(source.ID, "full")
+	if err != nil {
+		return nil, fmt.Errorf("start sync: %w", err)
+	}
+
+	profile, err := s.source.Profile(ctx)
+	if err != nil {
+		_ = s.store.FailSync(syncID, err.Error())
+		return nil, fmt.Errorf("get profile: %w", err)
+	}
+
+	labelMap, err := s.syncLabels(ctx, source.ID)
+	if err != nil {
+		_ = s.store.FailSync(syncID, err.Error())
+		return nil, fmt.Errorf("sync labels: %w", err)
+	}
+
+	checkpoint := &store.Checkpoint{}
+	pageToken := ""
+	if !s.noResume {
+		loaded, resumeToken, err := s.loadResumeCheckpoint(source.ID)
+		if err != nil {
+			_ = s.store.FailSync(syncID, err.Error())
+			return nil, fmt.Errorf("load checkpoint: %w", err)
+		}
+		checkpoint = loaded
+		pageToken = resumeToken
+		if pageToken != "" {
+			summary.WasResumed = true
+			summary.ResumedFromToken = pageToken
+			log.Infof("resuming full sync from checkpoint: email=%s page_token=%s", email, pageToken)
+		}
+	}
+
+	for {
+		var listResp *gmail.MessageListResponse
+		err := s.withAPIRetry(ctx, func() error {
+			var err error
+			listResp, err = s.source.ListMessages(ctx, s.query, pageToken)
+			return err
+		})
+		if err != nil {
+			_ = s.store.FailSync(syncID, err.Error())
+			return nil, fmt.Errorf("list messages: %w", err)
+		}
+
+		ids := make([]string, len(listResp.Messages))
+		for i, m := range listResp.Messages {
+			ids[i] = m.ID
+		}
+		existing, err := s.store.MessageExistsBatch(source.ID, ids)
+		if err != nil {
+			_ = s.store.FailSync(syncID, err.Error())
+			return nil, fmt.Errorf("check existing messages: %w", err)
+		}
+
+		for _, m := range listResp.Messages {
+			checkpoint.MessagesProcessed++
+			if existing[m.ID] {
+				summary.MessagesSkipped++
+				continue
+			}
+
+			var raw *gmail.RawMessage
+			err := s.withAPIRetry(ctx, func() error {
+				var err error
+				raw, err = s.source.GetMessage(ctx, m.ID)
+				return err
+			})
+			if err != nil {
+				log.Warnf("failed to fetch message: id=%s error=%v", m.ID, err)
+				summary.Errors++
+				checkpoint.ErrorsCount++
+				continue
+			}
+
+			s.sink.OnMessage(m.ID, int64(len(raw.Raw)))
+
+			if violations := validateHeaders(raw.Raw); len(violations) > 0 {
+				outcome, err := s.handleInvalidHeaders(source.ID, raw, violations, s.quarantineMode)
+				if err != nil {
+					log.Warnf("failed to handle invalid headers: id=%s error=%v", m.ID, err)
+					summary.Errors++
+					checkpoint.ErrorsCount++
+					continue
+				}
+				switch outcome {
+				case outcomeQuarantined:
+					summary.Quarantined++
+					continue
+				case outcomeRejected:
+					summary.Errors++
+					checkpoint.ErrorsCount++
+					continue
+				}
+				// outcomeStoredPlaceholder falls through to ingestMessage,
+				// which ingests whatever bytes it was given regardless of
+				// header validity.
+			}
+
+			if err := s.ingestMessage(ctx, source.ID, raw, m.ThreadID, labelMap); err != nil {
+				log.Warnf("failed to ingest message: id=%s error=%v", m.ID, err)
+				summary.Errors++
+				checkpoint.ErrorsCount++
+				continue
+			}
+
+			summary.MessagesAdded++
+			summary.BytesDownloaded += int64(len(raw.Raw))
+			checkpoint.MessagesAdded++
+		}
+
+		s.progress.OnProgress(checkpoint.MessagesProcessed, checkpoint.MessagesAdded, summary.MessagesSkipped)
+		s.sink.OnPage(pageToken, len(listResp.Messages), int(checkpoint.MessagesAdded), int(checkpoint.ErrorsCount))
+
+		pageToken = listResp.NextPageToken
+		checkpoint.PageToken = pageToken
+		if err := s.store.UpdateSyncCheckpoint(syncID, checkpoint); err != nil {
+			log.Warnf("failed to save checkpoint: error=%v", err)
+		}
+		s.sink.OnCheckpoint(pageToken)
+
+		if pageToken == "" {
+			break
+		}
+	}
+
+	if err := s.store.UpdateSourceSyncCursor(source.ID, profile.Cursor); err != nil {
+		log.Warnf("failed to update sync cursor: error=%v", err)
+	}
+	if err := s.store.CompleteSync(syncID, profile.Cursor); err != nil {
+		log.Warnf("failed to complete sync: error=%v", err)
+	}
+
+	summary.MessagesFound = checkpoint.MessagesProcessed
+	summary.MessagesUpdated = checkpoint.MessagesUpdated
+	summary.Errors = checkpoint.ErrorsCount
+	summary.EndTime = time.Now()
+	summary.Duration = summary.EndTime.Sub(summary.StartTime)
+
+	s.progress.OnComplete(summary)
+	return summary, nil
+}
+
+// syncLabels mirrors sourceID's remote label set into the store, returning
+// the resolved gmail-label-ID -> internal-row-ID map Full/Incremental use
+// to translate a message's label IDs on ingest.
+func (s *Syncer) syncLabels(ctx context.Context, sourceID int64) (map[string]int64, error) {
+	labels, err := s.source.ListLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.ID] = l.Name
+	}
+	labelMap, err := s.store.EnsureLabelsBatch(sourceID, names)
+	if err != nil {
+		return nil, fmt.Errorf("ensure labels: %w", err)
+	}
+	return labelMap, nil
+}
+
+// ingestMessage parses raw and writes everything derived from it -
+// the message row, its body, recipients, labels, and attachments - into
+// the store. A MIME parse failure doesn't abort the ingest: the message
+// is still stored, with BodyText explaining what went wrong, matching how
+// Incremental and RetryQuarantined/RetryPoisoned all share this one path
+// regardless of where the raw bytes came from.
+func (s *Syncer) ingestMessage(ctx context.Context, sourceID int64, raw *gmail.RawMessage, threadID string, labelMap map[string]int64) error {
+	parsed, parseErr := mime.Parse(raw.Raw)
+
+	subject := ""
+	if parseErr == nil {
+		subject = parsed.Subject
+	}
+	conversationID, err := s.store.EnsureConversation(sourceID, threadID, subject)
+	if err != nil {
+		return fmt.Errorf("ensure conversation: %w", err)
+	}
+
+	msg := &store.Message{
+		ConversationID:  conversationID,
+		SourceID:        sourceID,
+		SourceMessageID: raw.ID,
+		MessageType:     "email",
+		SizeEstimate:    raw.SizeEstimate,
+	}
+	if raw.InternalDate > 0 {
+		msg.InternalDate = sql.NullTime{Time: time.UnixMilli(raw.InternalDate), Valid: true}
+	}
+	if raw.Snippet != "" {
+		msg.Snippet = sql.NullString{String: raw.Snippet, Valid: true}
+	}
+
+	var attachments []mime.Attachment
+	if parseErr != nil {
+		msg.BodyText = sql.NullString{String: fmt.Sprintf("MIME parsing failed: %v", parseErr), Valid: true}
+	} else {
+		msg.Subject = sql.NullString{String: parsed.Subject, Valid: parsed.Subject != ""}
+		if body := parsed.GetBodyText(); body != "" {
+			msg.BodyText = sql.NullString{String: body, Valid: true}
+		}
+		if parsed.BodyHTML != "" {
+			msg.BodyHTML = sql.NullString{String: parsed.BodyHTML, Valid: true}
+		}
+		if !parsed.Date.IsZero() {
+			msg.SentAt = sql.NullTime{Time: parsed.Date, Valid: true}
+		}
+		msg.SenderEmail = parsed.GetFirstFrom().Email
+
+		for _, a := range parsed.Attachments {
+			if len(a.Content) == 0 {
+				continue
+			}
+			attachments = append(attachments, a)
+		}
+		msg.HasAttachments = len(attachments) > 0
+		msg.AttachmentCount = len(attachments)
+	}
+
+	msgID, err := s.store.UpsertMessage(msg)
+	if err != nil {
+		return fmt.Errorf("upsert message %s: %w", raw.ID, err)
+	}
+	if err := s.store.UpsertMessageRaw(msgID, raw.Raw); err != nil {
+		return fmt.Errorf("upsert raw %s: %w", raw.ID, err)
+	}
+
+	var labelIDs []int64
+	for _, gmailLabelID := range raw.LabelIDs {
+		if id, ok := labelMap[gmailLabelID]; ok {
+			labelIDs = append(labelIDs, id)
+		}
+	}
+	if err := s.store.ReplaceMessageLabels(msgID, labelIDs); err != nil {
+		return fmt.Errorf("replace labels %s: %w", raw.ID, err)
+	}
+
+	if parseErr == nil {
+		if err := s.ingestRecipients(msgID, parsed); err != nil {
+			return fmt.Errorf("ingest recipients %s: %w", raw.ID, err)
+		}
+		if err := s.ingestAttachments(ctx, msgID, attachments); err != nil {
+			return fmt.Errorf("ingest attachments %s: %w", raw.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// ingestRecipients resolves parsed's To/Cc/Bcc addresses to participants
+// and replaces msgID's recipient rows for each type.
+func (s *Syncer) ingestRecipients(msgID int64, parsed *mime.Message) error {
+	groups := []struct {
+		recipientType string
+		addrs         []mime.Address
+	}{
+		{"to", parsed.To},
+		{"cc", parsed.Cc},
+		{"bcc", parsed.Bcc},
+	}
+
+	for _, g := range groups {
+		deduped := dedupeAddressesByEmail(g.addrs)
+		if len(deduped) == 0 {
+			if err := s.store.ReplaceMessageRecipients(msgID, g.recipientType, nil, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		participantIDs, err := s.store.EnsureParticipantsBatch(deduped)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(deduped))
+		names := make([]string, 0, len(deduped))
+		for _, a := range deduped {
+			id, ok := participantIDs[a.Email]
+			if !ok {
+				continue
+			}
+			ids = append(ids, id)
+			names = append(names, a.Name)
+		}
+		if err := s.store.ReplaceMessageRecipients(msgID, g.recipientType, ids, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dedupeAddressesByEmail collapses addrs to one entry per email address -
+// message_recipients' primary key is (message_id, participant_id,
+// recipient_type), so a header listing the same address twice within one
+// field would otherwise violate it - preferring whichever occurrence has
+// a non-empty Name.
+func dedupeAddressesByEmail(addrs []mime.Address) []mime.Address {
+	index := make(map[string]int, len(addrs))
+	var result []mime.Address
+	for _, a := range addrs {
+		if a.Email == "" {
+			continue
+		}
+		if i, ok := index[a.Email]; ok {
+			if result[i].Name == "" && a.Name != "" {
+				result[i].Name = a.Name
+			}
+			continue
+		}
+		index[a.Email] = len(result)
+		result = append(result, a)
+	}
+	return result
+}
+
+// ingestAttachments stores each attachment's content (via s.attachmentStore,
+// if configured) and records its metadata against msgID.
+func (s *Syncer) ingestAttachments(ctx context.Context, msgID int64, attachments []mime.Attachment) error {
+	for _, a := range attachments {
+		storagePath, contentHash, err := s.storeAttachmentContent(ctx, a.Content)
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertAttachment(msgID, a.Filename, a.ContentType, storagePath, contentHash, int64(len(a.Content))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeAttachmentContent persists content through s.attachmentStore (when
+// configured) and returns the storage key alongside content's SHA-256
+// digest, used as attachments.content_hash for per-message dedup.
+func (s *Syncer) storeAttachmentContent(ctx context.Context, content []byte) (storagePath, contentHash string, err error) {
+	sum := sha256.Sum256(content)
+	contentHash = hex.EncodeToString(sum[:])
+	if s.attachmentStore == nil {
+		return "", contentHash, nil
+	}
+	key, err := s.attachmentStore.Put(ctx, sum[:], bytes.NewReader(content))
+	if err != nil {
+		return "", "", fmt.Errorf("store attachment content: %w", err)
+	}
+	return key, contentHash, nil
+}
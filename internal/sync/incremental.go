@@ -4,15 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/wesm/msgvault/internal/gmail"
-	"github.com/wesm/msgvault/internal/store"
+	"github.com/wesm/msgvault/internal/log"
 )
 
-// Incremental performs an incremental sync using the Gmail History API.
-// Falls back to full sync if history is too old (404 error).
+// Incremental performs an incremental sync against s.source, whichever
+// backend (Gmail, IMAP) it was constructed with. Falls back to full sync
+// if the persisted cursor is too old for the backend to resume from
+// (ErrHistoryExpired).
 func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSummary, error) {
 	startTime := time.Now()
 	summary := &gmail.SyncSummary{StartTime: startTime}
@@ -26,15 +27,11 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 		return nil, fmt.Errorf("no source found for %s - run full sync first", email)
 	}
 
-	// Get last history ID
+	// Get last cursor
 	if !source.SyncCursor.Valid || source.SyncCursor.String == "" {
-		return nil, fmt.Errorf("no history ID for %s - run full sync first", email)
-	}
-
-	startHistoryID, err := strconv.ParseUint(source.SyncCursor.String, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("invalid history ID %q: %w", source.SyncCursor.String, err)
+		return nil, fmt.Errorf("no sync cursor for %s - run full sync first", email)
 	}
+	startCursor := source.SyncCursor.String
 
 	// Start sync
 	syncID, err := s.store.StartSync(source.ID, "incremental")
@@ -50,22 +47,21 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 		}
 	}()
 
-	// Get profile for current history ID
-	profile, err := s.client.GetProfile(ctx)
+	// Get profile for current cursor
+	profile, err := s.source.Profile(ctx)
 	if err != nil {
 		_ = s.store.FailSync(syncID, err.Error())
 		return nil, fmt.Errorf("get profile: %w", err)
 	}
 
-	s.logger.Info("incremental sync", "email", email, "start_history", startHistoryID, "current_history", profile.HistoryID)
+	log.Infof("incremental sync: email=%s start_cursor=%s current_cursor=%s", email, startCursor, profile.Cursor)
 
-	// If history IDs match, nothing to do
-	if startHistoryID >= profile.HistoryID {
-		s.logger.Info("already up to date")
-		_ = s.store.CompleteSync(syncID, strconv.FormatUint(profile.HistoryID, 10))
+	// If the cursor hasn't moved, nothing to do
+	if startCursor == profile.Cursor {
+		log.Infof("already up to date: email=%s", email)
+		_ = s.store.CompleteSync(syncID, profile.Cursor)
 		summary.EndTime = time.Now()
 		summary.Duration = summary.EndTime.Sub(summary.StartTime)
-		summary.FinalHistoryID = profile.HistoryID
 		return summary, nil
 	}
 
@@ -76,17 +72,31 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 		return nil, fmt.Errorf("sync labels: %w", err)
 	}
 
-	// Process history
-	checkpoint := &store.Checkpoint{}
-	pageToken := ""
+	// Process history, resuming from a checkpoint left by a prior run that
+	// didn't finish (crash, restart) instead of always starting over from
+	// page one.
+	checkpoint, pageToken, err := s.loadResumeCheckpoint(source.ID)
+	if err != nil {
+		_ = s.store.FailSync(syncID, err.Error())
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+	if pageToken != "" {
+		summary.WasResumed = true
+		summary.ResumedFromToken = pageToken
+		log.Infof("resuming incremental sync from checkpoint: email=%s page_token=%s", email, pageToken)
+	}
+	finalCursor := startCursor
 
 	for {
-		historyResp, err := s.client.ListHistory(ctx, startHistoryID, pageToken)
+		var historyResp *SourceHistory
+		err := s.withAPIRetry(ctx, func() error {
+			var err error
+			historyResp, err = s.source.History(ctx, startCursor, pageToken)
+			return err
+		})
 		if err != nil {
-			// Check for 404 - history too old
-			var notFound *gmail.NotFoundError
-			if errors.As(err, &notFound) {
-				s.logger.Warn("history too old, falling back to full sync")
+			if errors.Is(err, ErrHistoryExpired) {
+				log.Warnf("history too old, falling back to full sync: email=%s", email)
 				_ = s.store.FailSync(syncID, "history too old")
 				// Caller should trigger full sync
 				return nil, ErrHistoryExpired
@@ -94,27 +104,35 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 			_ = s.store.FailSync(syncID, err.Error())
 			return nil, fmt.Errorf("list history: %w", err)
 		}
+		finalCursor = historyResp.Cursor
 
 		// Process each history record
-		for _, record := range historyResp.History {
+		for _, record := range historyResp.Records {
 			// Handle added messages
 			for _, added := range record.MessagesAdded {
 				// Fetch and ingest the new message
-				raw, err := s.client.GetMessageRaw(ctx, added.Message.ID)
+				var raw *gmail.RawMessage
+				err := s.withAPIRetry(ctx, func() error {
+					var err error
+					raw, err = s.source.GetMessage(ctx, added.Message.ID)
+					return err
+				})
 				if err != nil {
 					var notFound *gmail.NotFoundError
 					if errors.As(err, &notFound) {
 						// Message was deleted before we could fetch it
 						continue
 					}
-					s.logger.Warn("failed to fetch added message", "id", added.Message.ID, "error", err)
+					log.Warnf("failed to fetch added message: id=%s error=%v", added.Message.ID, err)
 					checkpoint.ErrorsCount++
 					continue
 				}
 
+				s.sink.OnMessage(added.Message.ID, int64(len(raw.Raw)))
+
 				err = s.ingestMessage(ctx, source.ID, raw, added.Message.ThreadID, labelMap)
 				if err != nil {
-					s.logger.Warn("failed to ingest added message", "id", added.Message.ID, "error", err)
+					log.Warnf("failed to ingest added message: id=%s error=%v", added.Message.ID, err)
 					checkpoint.ErrorsCount++
 					continue
 				}
@@ -126,7 +144,7 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 			// Handle deleted messages
 			for _, deleted := range record.MessagesDeleted {
 				if err := s.store.MarkMessageDeleted(source.ID, deleted.Message.ID); err != nil {
-					s.logger.Warn("failed to mark message deleted", "id", deleted.Message.ID, "error", err)
+					log.Warnf("failed to mark message deleted: id=%s error=%v", deleted.Message.ID, err)
 					checkpoint.ErrorsCount++
 				}
 			}
@@ -134,13 +152,13 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 			// Handle label changes
 			for _, labelAdded := range record.LabelsAdded {
 				if err := s.handleLabelChange(ctx, source.ID, labelAdded.Message.ID, labelAdded.Message.ThreadID, labelAdded.LabelIDs, labelMap, true); err != nil {
-					s.logger.Warn("failed to handle label add", "id", labelAdded.Message.ID, "error", err)
+					log.Warnf("failed to handle label add: id=%s error=%v", labelAdded.Message.ID, err)
 				}
 			}
 
 			for _, labelRemoved := range record.LabelsRemoved {
 				if err := s.handleLabelChange(ctx, source.ID, labelRemoved.Message.ID, labelRemoved.Message.ThreadID, labelRemoved.LabelIDs, labelMap, false); err != nil {
-					s.logger.Warn("failed to handle label remove", "id", labelRemoved.Message.ID, "error", err)
+					log.Warnf("failed to handle label remove: id=%s error=%v", labelRemoved.Message.ID, err)
 				}
 			}
 
@@ -149,13 +167,15 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 
 		// Report progress
 		s.progress.OnProgress(checkpoint.MessagesProcessed, checkpoint.MessagesAdded, 0)
+		s.sink.OnPage(pageToken, len(historyResp.Records), int(checkpoint.MessagesAdded), int(checkpoint.ErrorsCount))
 
 		// Save checkpoint
 		pageToken = historyResp.NextPageToken
 		checkpoint.PageToken = pageToken
 		if err := s.store.UpdateSyncCheckpoint(syncID, checkpoint); err != nil {
-			s.logger.Warn("failed to save checkpoint", "error", err)
+			log.Warnf("failed to save checkpoint: error=%v", err)
 		}
+		s.sink.OnCheckpoint(pageToken)
 
 		// No more pages
 		if pageToken == "" {
@@ -163,15 +183,14 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 		}
 	}
 
-	// Update source with final history ID
-	historyIDStr := strconv.FormatUint(profile.HistoryID, 10)
-	if err := s.store.UpdateSourceSyncCursor(source.ID, historyIDStr); err != nil {
-		s.logger.Warn("failed to update sync cursor", "error", err)
+	// Update source with final cursor
+	if err := s.store.UpdateSourceSyncCursor(source.ID, finalCursor); err != nil {
+		log.Warnf("failed to update sync cursor: error=%v", err)
 	}
 
 	// Mark sync complete
-	if err := s.store.CompleteSync(syncID, historyIDStr); err != nil {
-		s.logger.Warn("failed to complete sync", "error", err)
+	if err := s.store.CompleteSync(syncID, finalCursor); err != nil {
+		log.Warnf("failed to complete sync: error=%v", err)
 	}
 
 	// Build summary
@@ -181,51 +200,54 @@ func (s *Syncer) Incremental(ctx context.Context, email string) (*gmail.SyncSumm
 	summary.MessagesAdded = checkpoint.MessagesAdded
 	summary.MessagesUpdated = checkpoint.MessagesUpdated
 	summary.Errors = checkpoint.ErrorsCount
-	summary.FinalHistoryID = profile.HistoryID
 
 	s.progress.OnComplete(summary)
 	return summary, nil
 }
 
-// handleLabelChange processes a label addition or removal.
-// If the message doesn't exist locally, it may need to be fetched.
+// handleLabelChange applies a single LabelsAdded/LabelsRemoved history
+// record as a delta against the message's current label set, via
+// store.AddMessageLabels/store.RemoveMessageLabels, instead of
+// re-downloading the message's raw bytes just to recompute it from
+// scratch - that trip only happens for the one case a delta can't cover:
+// a LabelsAdded record for a message we don't have locally yet, which is
+// ingested the same way a MessagesAdded record would be. A LabelsRemoved
+// record for a message we don't have locally is a no-op, since there's
+// nothing to remove a label from.
 func (s *Syncer) handleLabelChange(ctx context.Context, sourceID int64, messageID, threadID string, gmailLabelIDs []string, labelMap map[string]int64, isAdd bool) error {
-	// Check if message exists
 	existing, err := s.store.MessageExistsBatch(sourceID, []string{messageID})
 	if err != nil {
 		return err
 	}
 
-	internalID, exists := existing[messageID]
-
-	if !exists {
-		// Message doesn't exist locally - if adding labels, we should fetch it
+	if !existing[messageID] {
 		if isAdd {
-			raw, err := s.client.GetMessageRaw(ctx, messageID)
+			raw, err := s.source.GetMessage(ctx, messageID)
 			if err != nil {
 				return err
 			}
 			return s.ingestMessage(ctx, sourceID, raw, threadID, labelMap)
 		}
-		// Removing labels from non-existent message is a no-op
 		return nil
 	}
 
-	// Get current labels
-	// For simplicity, we'll just re-fetch and update all labels
-	// A more efficient approach would track individual adds/removes
-	raw, err := s.client.GetMessageRaw(ctx, messageID)
+	internalID, err := s.store.InternalMessageID(sourceID, messageID)
 	if err != nil {
 		return err
 	}
 
-	// Convert Gmail label IDs to internal IDs
 	var labelIDs []int64
-	for _, gmailID := range raw.LabelIDs {
+	for _, gmailID := range gmailLabelIDs {
 		if id, ok := labelMap[gmailID]; ok {
 			labelIDs = append(labelIDs, id)
 		}
 	}
+	if len(labelIDs) == 0 {
+		return nil
+	}
 
-	return s.store.ReplaceMessageLabels(internalID, labelIDs)
+	if isAdd {
+		return s.store.AddMessageLabels(internalID, labelIDs)
+	}
+	return s.store.RemoveMessageLabels(internalID, labelIDs)
 }
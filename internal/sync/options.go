@@ -0,0 +1,76 @@
+package sync
+
+import "github.com/wesm/msgvault/internal/blobstore"
+
+// Options configures a Syncer.
+type Options struct {
+	// AttachmentsDir, if set and AttachmentStore is nil, stores message
+	// attachments as files under this directory instead of inline in the
+	// database. Deprecated: set AttachmentStore directly (with
+	// blobstore.NewLocalAttachmentStore for the equivalent behavior); this
+	// field is translated into one internally for backward compatibility.
+	AttachmentsDir string
+
+	// AttachmentStore, if set, persists message attachments through this
+	// backend (local directory, S3, or an in-memory store for tests)
+	// instead of inline in the database, keyed by SHA-256 for
+	// backend-agnostic deduplication. Takes precedence over AttachmentsDir.
+	AttachmentStore blobstore.AttachmentStore
+
+	// QuarantineMode controls what happens to a fetched message that fails
+	// RFC 5322 header validation. Defaults to ModeQuarantine.
+	QuarantineMode QuarantineMode
+
+	// BlobStore, if set, stores raw message and attachment bytes outside
+	// the database; the database then holds only the content-address hash
+	// and metadata. Nil means raw bytes stay inline in SQLite.
+	BlobStore blobstore.BlobStore
+
+	// MinFreeSpaceBytes, if nonzero, aborts sync early with a clear error
+	// once BlobStore's backing storage drops below this many free bytes.
+	MinFreeSpaceBytes int64
+
+	// ProgressSink, if set, receives page/message/checkpoint events as
+	// Full and Incremental run, for callers (e.g. cmd/msgvault serve's SSE
+	// endpoint) that need a live event feed rather than just a final
+	// Summary. Defaults to NullProgressSink.
+	ProgressSink ProgressSink
+
+	// NoResume makes Full ignore any checkpoint left by a prior
+	// interrupted run and always start from page one, instead of resuming
+	// the way it does by default.
+	NoResume bool
+
+	// Query, if set, restricts Full the same way a search query restricts
+	// Gmail results, passed straight through to Source.ListMessages.
+	Query string
+}
+
+// DefaultOptions returns the Options a Syncer uses when constructed with a
+// nil *Options: no attachment store, ModeQuarantine, no blob store, and
+// resume enabled.
+func DefaultOptions() *Options {
+	return &Options{}
+}
+
+// resolveAttachmentStore returns opts.AttachmentStore, falling back to a
+// LocalAttachmentStore rooted at opts.AttachmentsDir for callers still
+// using the deprecated field. Returns nil if neither is set.
+func resolveAttachmentStore(opts *Options) (blobstore.AttachmentStore, error) {
+	if opts.AttachmentStore != nil {
+		return opts.AttachmentStore, nil
+	}
+	if opts.AttachmentsDir == "" {
+		return nil, nil
+	}
+	return blobstore.NewLocalAttachmentStore(opts.AttachmentsDir)
+}
+
+// resolveQuarantineMode returns opts.QuarantineMode, defaulting to
+// ModeQuarantine when unset.
+func resolveQuarantineMode(opts *Options) QuarantineMode {
+	if opts.QuarantineMode == "" {
+		return ModeQuarantine
+	}
+	return opts.QuarantineMode
+}
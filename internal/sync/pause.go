@@ -0,0 +1,33 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// PausedError is returned by checkNotPaused when a source has been
+// administratively suspended via store.PauseSource. A Syncer checks for
+// it before starting a run rather than after, so a paused source never
+// even opens a sync row.
+type PausedError struct {
+	SourceID store.SourceID
+	Reason   string
+}
+
+func (e *PausedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("sync: source %s is paused", e.SourceID)
+	}
+	return fmt.Sprintf("sync: source %s is paused: %s", e.SourceID, e.Reason)
+}
+
+// checkNotPaused returns a *PausedError if pause is non-nil (the source
+// is currently paused), or nil otherwise. Call this against the result of
+// store.GetSourcePause before starting a sync run.
+func checkNotPaused(sourceID store.SourceID, pause *store.SourcePause) error {
+	if pause == nil {
+		return nil
+	}
+	return &PausedError{SourceID: sourceID, Reason: pause.Reason}
+}
@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+func TestCheckNotPaused_NilPauseIsNil(t *testing.T) {
+	if err := checkNotPaused(store.SourceID(1), nil); err != nil {
+		t.Errorf("checkNotPaused(nil) = %v, want nil", err)
+	}
+}
+
+func TestCheckNotPaused_ReturnsPausedError(t *testing.T) {
+	pause := &store.SourcePause{SourceID: 1, Reason: "migrating to a new account"}
+	err := checkNotPaused(store.SourceID(1), pause)
+	if err == nil {
+		t.Fatal("expected a PausedError, got nil")
+	}
+	var pe *PausedError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PausedError, got %T", err)
+	}
+	if pe.Reason != "migrating to a new account" {
+		t.Errorf("Reason = %q, want %q", pe.Reason, "migrating to a new account")
+	}
+	if got, want := pe.Error(), "sync: source 1 is paused: migrating to a new account"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestPausedError_EmptyReason(t *testing.T) {
+	pe := &PausedError{SourceID: 2}
+	if got, want := pe.Error(), "sync: source 2 is paused"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
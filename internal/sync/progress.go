@@ -0,0 +1,38 @@
+package sync
+
+// ProgressSink receives fine-grained sync events as Full/Incremental run,
+// distinct from gmail.SyncProgress: where SyncProgress reports aggregate
+// counters for a CLI progress bar, ProgressSink reports the individual
+// page/message/checkpoint boundaries a UI (e.g. the SSE stream in
+// cmd/msgvault serve) needs to render a live event feed.
+type ProgressSink interface {
+	// OnPage is called once a page of messages has been fetched and
+	// ingested, with the page token that was just processed (empty for
+	// the first page) and counters for that page alone.
+	OnPage(token string, fetched, added, errors int)
+
+	// OnMessage is called after a single message is fetched, before it's
+	// ingested, so a UI can show per-message throughput.
+	OnMessage(id string, bytesFetched int64)
+
+	// OnCheckpoint is called after a checkpoint has been durably saved,
+	// with the page token resume would continue from.
+	OnCheckpoint(token string)
+}
+
+// NullProgressSink discards all events. It's the default when
+// Options.ProgressSink is nil, so callers never need a nil check.
+type NullProgressSink struct{}
+
+func (NullProgressSink) OnPage(token string, fetched, added, errors int) {}
+func (NullProgressSink) OnMessage(id string, bytesFetched int64)         {}
+func (NullProgressSink) OnCheckpoint(token string)                       {}
+
+// resolveProgressSink returns opts.ProgressSink, defaulting to
+// NullProgressSink when unset.
+func resolveProgressSink(opts *Options) ProgressSink {
+	if opts == nil || opts.ProgressSink == nil {
+		return NullProgressSink{}
+	}
+	return opts.ProgressSink
+}
@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// JSONProgress is a gmail.SyncProgress that writes one line-delimited JSON
+// object per event to an io.Writer, for external UIs (a web dashboard, a
+// supervising process) that want a stable machine-readable feed instead of
+// a human-facing progress bar. It's one of the sinks a --progress CLI flag
+// can select between, alongside TextProgress.
+type JSONProgress struct {
+	w      io.Writer
+	syncID string
+}
+
+var _ gmail.SyncProgress = (*JSONProgress)(nil)
+
+// NewJSONProgress returns a JSONProgress that tags every event it writes
+// to w with syncID, so a consumer following multiple concurrent syncs can
+// demultiplex the stream.
+func NewJSONProgress(w io.Writer, syncID string) *JSONProgress {
+	return &JSONProgress{w: w, syncID: syncID}
+}
+
+func (p *JSONProgress) emit(phase string, fields string) {
+	fmt.Fprintf(p.w, `{"sync_id":%q,"phase":%q%s}`+"\n", p.syncID, phase, fields)
+}
+
+// OnStart implements gmail.SyncProgress.
+func (p *JSONProgress) OnStart(total int64) {
+	p.emit("start", fmt.Sprintf(`,"total":%d`, total))
+}
+
+// OnProgress implements gmail.SyncProgress.
+func (p *JSONProgress) OnProgress(processed, added, skipped int64) {
+	p.emit("history", fmt.Sprintf(`,"processed":%d,"added":%d,"skipped":%d`, processed, added, skipped))
+}
+
+// OnComplete implements gmail.SyncProgress.
+func (p *JSONProgress) OnComplete(summary *gmail.SyncSummary) {
+	p.emit("complete", fmt.Sprintf(
+		`,"processed":%d,"added":%d,"errors":%d,"bytes":%d,"duration_ms":%d`,
+		summary.MessagesFound, summary.MessagesAdded, summary.Errors,
+		summary.BytesDownloaded, summary.Duration.Milliseconds()))
+}
+
+// OnError implements gmail.SyncProgress.
+func (p *JSONProgress) OnError(err error) {
+	p.emit("error", fmt.Sprintf(`,"error":%q`, err.Error()))
+}
+
+// OnLatestDate implements gmail.SyncProgressWithDate.
+func (p *JSONProgress) OnLatestDate(date time.Time) {
+	p.emit("date", fmt.Sprintf(`,"date":%q`, date.Format(time.RFC3339)))
+}
+
+var _ gmail.SyncProgressWithDate = (*JSONProgress)(nil)
+
+// TextProgress is a gmail.SyncProgress that prints a single updating line
+// to w, the plain-text counterpart to JSONProgress selected by the same
+// --progress flag -- a minimal stand-in for a real progress bar until one
+// is wired in.
+type TextProgress struct {
+	w io.Writer
+}
+
+var _ gmail.SyncProgress = (*TextProgress)(nil)
+
+// NewTextProgress returns a TextProgress that writes to w.
+func NewTextProgress(w io.Writer) *TextProgress {
+	return &TextProgress{w: w}
+}
+
+func (p *TextProgress) OnStart(total int64) {
+	fmt.Fprintf(p.w, "syncing (estimated %d messages)...\n", total)
+}
+
+func (p *TextProgress) OnProgress(processed, added, skipped int64) {
+	fmt.Fprintf(p.w, "\rprocessed %d, added %d, skipped %d", processed, added, skipped)
+}
+
+func (p *TextProgress) OnComplete(summary *gmail.SyncSummary) {
+	fmt.Fprintf(p.w, "\ndone: %d added, %d errors in %s\n", summary.MessagesAdded, summary.Errors, summary.Duration)
+}
+
+func (p *TextProgress) OnError(err error) {
+	fmt.Fprintf(p.w, "\nerror: %v\n", err)
+}
+
+// ProgressForMode returns the gmail.SyncProgress a --progress=<mode> CLI
+// flag should use: "json" for JSONProgress, anything else (including "")
+// for TextProgress.
+func ProgressForMode(mode string, w io.Writer, syncID string) gmail.SyncProgress {
+	if mode == "json" {
+		return NewJSONProgress(w, syncID)
+	}
+	return NewTextProgress(w)
+}
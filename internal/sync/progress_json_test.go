@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+func TestJSONProgressEmitsLineDelimitedEvents(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewJSONProgress(&buf, "sync-1")
+
+	p.OnStart(10)
+	p.OnProgress(3, 2, 0)
+	p.OnComplete(&gmail.SyncSummary{MessagesAdded: 2, Errors: 0})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 JSON lines, got %d: %q", len(lines), buf.String())
+	}
+	for _, want := range []string{`"sync_id":"sync-1"`, `"phase":"start"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("line 1 = %q, want to contain %q", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], `"phase":"history"`) || !strings.Contains(lines[1], `"added":2`) {
+		t.Errorf("line 2 = %q, want phase=history and added=2", lines[1])
+	}
+	if !strings.Contains(lines[2], `"phase":"complete"`) {
+		t.Errorf("line 3 = %q, want phase=complete", lines[2])
+	}
+}
+
+func TestProgressForModeSelectsByName(t *testing.T) {
+	var buf bytes.Buffer
+	if _, ok := ProgressForMode("json", &buf, "sync-1").(*JSONProgress); !ok {
+		t.Error(`ProgressForMode("json", ...) did not return a *JSONProgress`)
+	}
+	if _, ok := ProgressForMode("", &buf, "sync-1").(*TextProgress); !ok {
+		t.Error(`ProgressForMode("", ...) did not return a *TextProgress`)
+	}
+}
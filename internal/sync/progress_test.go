@@ -0,0 +1,78 @@
+package sync
+
+import "testing"
+
+func TestResolveProgressSink_DefaultsToNull(t *testing.T) {
+	if _, ok := resolveProgressSink(&Options{}).(NullProgressSink); !ok {
+		t.Errorf("resolveProgressSink(&Options{}) = %T, want NullProgressSink", resolveProgressSink(&Options{}))
+	}
+
+	fake := &fakeProgressSink{}
+	if got := resolveProgressSink(&Options{ProgressSink: fake}); got != fake {
+		t.Errorf("resolveProgressSink() = %v, want the configured sink", got)
+	}
+}
+
+// fakeProgressSink records every call it receives, in order, as a
+// "Method(args)" string, for tests asserting on event sequence.
+type fakeProgressSink struct {
+	calls []string
+}
+
+func (f *fakeProgressSink) OnPage(token string, fetched, added, errors int) {
+	f.calls = append(f.calls, "OnPage")
+}
+
+func (f *fakeProgressSink) OnMessage(id string, bytesFetched int64) {
+	f.calls = append(f.calls, "OnMessage")
+}
+
+func (f *fakeProgressSink) OnCheckpoint(token string) {
+	f.calls = append(f.calls, "OnCheckpoint")
+}
+
+func TestEventBroker_PublishAndReplay(t *testing.T) {
+	b := NewEventBroker()
+
+	// Publish before anyone subscribes; these should still be replayable.
+	b.OnPage("", 2, 2, 0)
+	b.OnCheckpoint("page_1")
+
+	ch, unsubscribe := b.subscribe(0)
+	defer unsubscribe()
+
+	first := <-ch
+	if first.Type != "page" || first.ID != 1 {
+		t.Errorf("first replayed event = %+v, want type=page id=1", first)
+	}
+	second := <-ch
+	if second.Type != "checkpoint" || second.ID != 2 {
+		t.Errorf("second replayed event = %+v, want type=checkpoint id=2", second)
+	}
+
+	b.OnMessage("msg1", 1024)
+	third := <-ch
+	if third.Type != "message" {
+		t.Errorf("third event = %+v, want type=message", third)
+	}
+}
+
+func TestEventBroker_ReplayAfterLastEventID(t *testing.T) {
+	b := NewEventBroker()
+	b.OnPage("", 1, 1, 0)
+	b.OnCheckpoint("page_1")
+	b.OnPage("page_1", 1, 1, 0)
+
+	ch, unsubscribe := b.subscribe(2) // already saw the first two events
+	defer unsubscribe()
+
+	ev := <-ch
+	if ev.ID != 3 {
+		t.Errorf("replayed event ID = %d, want 3 (only events after cursor)", ev.ID)
+	}
+	select {
+	case extra := <-ch:
+		t.Errorf("unexpected extra replayed event: %+v", extra)
+	default:
+	}
+}
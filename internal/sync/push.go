@@ -0,0 +1,177 @@
+package sync
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PushConfig enables Gmail push notifications (a users.watch Pub/Sub
+// subscription) as an on-demand trigger for incremental sync, supplementing
+// RunOptions.Interval's periodic polling rather than replacing it: a
+// dropped or delayed push notification is still caught by the next
+// scheduled poll.
+type PushConfig struct {
+	// TopicName is the fully-qualified Pub/Sub topic Gmail publishes
+	// notifications to, e.g. "projects/my-project/topics/gmail-push".
+	TopicName string
+	// ListenAddr serves the Pub/Sub push subscription's webhook.
+	ListenAddr string
+	// Path is the HTTP path Pub/Sub POSTs notifications to. Defaults to
+	// "/push".
+	Path string
+	// RenewBefore controls how long before a watch's expiry (Gmail caps
+	// watches at 7 days) it's renewed. Defaults to 24 hours.
+	RenewBefore time.Duration
+}
+
+func (p *PushConfig) path() string {
+	if p.Path == "" {
+		return "/push"
+	}
+	return p.Path
+}
+
+func (p *PushConfig) renewBefore() time.Duration {
+	if p.RenewBefore <= 0 {
+		return 24 * time.Hour
+	}
+	return p.RenewBefore
+}
+
+// pushNotification is the Pub/Sub push subscription envelope Gmail's
+// users.watch notifications are delivered through.
+type pushNotification struct {
+	Message struct {
+		Data      string `json:"data"`
+		MessageID string `json:"messageId"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// gmailPushPayload is the base64-decoded body of pushNotification.Message.Data.
+type gmailPushPayload struct {
+	EmailAddress string `json:"emailAddress"`
+	HistoryID    uint64 `json:"historyId"`
+}
+
+// pushTriggers coalesces overlapping Gmail push notifications per account
+// into a single pending trigger: a second notification arriving before the
+// first is consumed by runAccountLoop is dropped, since one incremental
+// sync catches up on both anyway.
+type pushTriggers struct {
+	mu       sync.Mutex
+	channels map[string]chan struct{}
+}
+
+func newPushTriggers(accounts []string) *pushTriggers {
+	t := &pushTriggers{channels: make(map[string]chan struct{}, len(accounts))}
+	for _, a := range accounts {
+		t.channels[a] = make(chan struct{}, 1)
+	}
+	return t
+}
+
+func (t *pushTriggers) fire(account string) {
+	t.mu.Lock()
+	ch, ok := t.channels[account]
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+		// a trigger is already pending for this account; coalesce.
+	}
+}
+
+func (t *pushTriggers) channel(account string) <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.channels[account]
+}
+
+// handler returns the http.Handler Pub/Sub's push subscription POSTs
+// notifications to, firing the matching account's trigger channel.
+func (t *pushTriggers) handler(logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+		var note pushNotification
+		if err := json.Unmarshal(body, &note); err != nil {
+			logger.Warn("discarding malformed push notification", "error", err)
+			http.Error(w, "invalid push envelope", http.StatusBadRequest)
+			return
+		}
+		data, err := base64.StdEncoding.DecodeString(note.Message.Data)
+		if err != nil {
+			logger.Warn("discarding push notification with unparsable data", "error", err)
+			http.Error(w, "invalid push payload", http.StatusBadRequest)
+			return
+		}
+		var payload gmailPushPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			logger.Warn("discarding push notification with unparsable payload", "error", err)
+			http.Error(w, "invalid push payload json", http.StatusBadRequest)
+			return
+		}
+		if payload.EmailAddress != "" {
+			t.fire(payload.EmailAddress)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// watchAccount registers (or renews) account's Gmail watch subscription,
+// returning its reported expiry.
+func (s *Syncer) watchAccount(ctx context.Context, push *PushConfig) (time.Time, error) {
+	resp, err := s.client.Watch(ctx, push.TopicName, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("watch: %w", err)
+	}
+	return resp.Expiration, nil
+}
+
+// runWatchRenewal keeps account's Gmail watch alive until ctx is canceled,
+// renewing it RenewBefore its reported expiry so a push subscription never
+// silently lapses mid-run (Gmail caps every watch at 7 days).
+func (s *Syncer) runWatchRenewal(ctx context.Context, account string, push *PushConfig) {
+	expiry, err := s.watchAccount(ctx, push)
+	if err != nil {
+		s.logger.Error("gmail watch registration failed", "account", account, "error", err)
+		expiry = time.Now().Add(push.renewBefore())
+	}
+
+	for {
+		wait := time.Until(expiry) - push.renewBefore()
+		if wait < 0 {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		next, err := s.watchAccount(ctx, push)
+		if err != nil {
+			s.logger.Error("gmail watch renewal failed", "account", account, "error", err)
+			expiry = time.Now().Add(push.renewBefore())
+			continue
+		}
+		expiry = next
+	}
+}
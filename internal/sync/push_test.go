@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPushTriggers_FireCoalesces(t *testing.T) {
+	triggers := newPushTriggers([]string{"alice@example.com"})
+
+	triggers.fire("alice@example.com")
+	triggers.fire("alice@example.com")
+	triggers.fire("alice@example.com")
+
+	ch := triggers.channel("alice@example.com")
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a pending trigger after fire")
+	}
+	select {
+	case <-ch:
+		t.Fatal("expected fire to coalesce into a single pending trigger")
+	default:
+	}
+}
+
+func TestPushTriggers_FireUnknownAccountIsNoop(t *testing.T) {
+	triggers := newPushTriggers([]string{"alice@example.com"})
+	triggers.fire("bob@example.com")
+}
+
+func TestPushConfig_Defaults(t *testing.T) {
+	p := &PushConfig{}
+	if got := p.path(); got != "/push" {
+		t.Errorf("path() = %q, want %q", got, "/push")
+	}
+	if got := p.renewBefore(); got != 24*time.Hour {
+		t.Errorf("renewBefore() = %v, want 24h", got)
+	}
+}
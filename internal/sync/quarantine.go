@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// quarantineOutcome describes what handleInvalidHeaders did with a message
+// that failed validateHeaders, so the caller can update the right summary
+// counter.
+type quarantineOutcome int
+
+const (
+	outcomeRejected quarantineOutcome = iota
+	outcomeQuarantined
+	outcomeStoredPlaceholder
+)
+
+// handleInvalidHeaders applies opts.QuarantineMode to a message that failed
+// header validation, returning what happened so the caller (Incremental or
+// Full) can account for it in the sync summary.
+func (s *Syncer) handleInvalidHeaders(sourceID int64, raw *gmail.RawMessage, violations []string, mode QuarantineMode) (quarantineOutcome, error) {
+	switch mode {
+	case ModeReject:
+		return outcomeRejected, nil
+	case ModeStorePlaceholder:
+		return outcomeStoredPlaceholder, nil
+	case ModeQuarantine, "":
+		q := &store.QuarantinedMessage{
+			SourceID:        sourceID,
+			SourceMessageID: raw.ID,
+			ThreadID:        raw.ThreadID,
+			LabelIDs:        raw.LabelIDs,
+			Raw:             raw.Raw,
+			ViolatedRules:   violations,
+		}
+		if err := s.store.InsertQuarantinedMessage(q); err != nil {
+			return outcomeQuarantined, fmt.Errorf("insert quarantined message %s: %w", raw.ID, err)
+		}
+		return outcomeQuarantined, nil
+	default:
+		return outcomeRejected, fmt.Errorf("unknown quarantine mode %q", mode)
+	}
+}
+
+// RetryQuarantined re-validates a previously quarantined message's headers
+// and, if it now passes, ingests it through the normal pipeline and removes
+// it from the quarantine table. It returns the remaining violations (nil if
+// the message was ingested) so a caller patching headers by hand can see
+// what's still wrong.
+func (s *Syncer) RetryQuarantined(ctx context.Context, id int64) ([]string, error) {
+	q, err := s.store.GetQuarantinedMessage(id)
+	if err != nil {
+		return nil, fmt.Errorf("get quarantined message %d: %w", id, err)
+	}
+	if q == nil {
+		return nil, fmt.Errorf("no quarantined message with id %d", id)
+	}
+
+	if violations := validateHeaders(q.Raw); len(violations) > 0 {
+		if err := s.store.UpdateQuarantinedViolations(id, violations); err != nil {
+			return violations, fmt.Errorf("update violations %d: %w", id, err)
+		}
+		return violations, nil
+	}
+
+	labelMap, err := s.store.LabelIDMap(q.SourceID)
+	if err != nil {
+		return nil, fmt.Errorf("label id map: %w", err)
+	}
+	raw := &gmail.RawMessage{ID: q.SourceMessageID, ThreadID: q.ThreadID, LabelIDs: q.LabelIDs, Raw: q.Raw}
+	if err := s.ingestMessage(ctx, q.SourceID, raw, q.ThreadID, labelMap); err != nil {
+		return nil, fmt.Errorf("ingest quarantined message %d: %w", id, err)
+	}
+
+	if err := s.store.DeleteQuarantinedMessage(id); err != nil {
+		return nil, fmt.Errorf("delete quarantined message %d: %w", id, err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,12 @@
+package sync
+
+import "testing"
+
+func TestResolveQuarantineMode_DefaultsToQuarantine(t *testing.T) {
+	if got := resolveQuarantineMode(&Options{}); got != ModeQuarantine {
+		t.Errorf("resolveQuarantineMode() = %q, want %q", got, ModeQuarantine)
+	}
+	if got := resolveQuarantineMode(&Options{QuarantineMode: ModeReject}); got != ModeReject {
+		t.Errorf("resolveQuarantineMode() = %q, want %q", got, ModeReject)
+	}
+}
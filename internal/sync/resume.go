@@ -0,0 +1,37 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// loadResumeCheckpoint looks up the most recently persisted checkpoint for
+// sourceID and returns it along with the page token Incremental should
+// resume from. A fresh checkpoint (no prior run, or a prior run that
+// completed with no pending page) returns an empty token, which Incremental
+// treats the same as starting from page one.
+func (s *Syncer) loadResumeCheckpoint(sourceID int64) (*store.Checkpoint, string, error) {
+	checkpoint, err := s.store.GetLatestCheckpoint(sourceID)
+	if err != nil {
+		return nil, "", fmt.Errorf("get latest checkpoint: %w", err)
+	}
+	if checkpoint == nil {
+		return &store.Checkpoint{}, "", nil
+	}
+	return checkpoint, checkpoint.PageToken, nil
+}
+
+// Resume continues an incremental sync for email from its last persisted
+// checkpoint, picking up at the page token (and cumulative counters) a
+// prior run left behind instead of restarting from page one. It is
+// otherwise identical to Incremental, which already resumes automatically
+// via loadResumeCheckpoint - Resume exists as the explicit, self-documenting
+// entry point for callers (e.g. a daemon restarting after a crash) that
+// specifically want to report whether a resume happened, via
+// SyncSummary.WasResumed and SyncSummary.ResumedFromToken.
+func (s *Syncer) Resume(ctx context.Context, email string) (*gmail.SyncSummary, error) {
+	return s.Incremental(ctx, email)
+}
@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+const (
+	retryBaseDelay   = time.Second
+	retryMaxDelay    = 5 * time.Minute
+	retryJitterPct   = 25
+	retryMaxAttempts = 6
+)
+
+// PermanentError wraps an error to flag it as non-retryable, e.g. a parse
+// failure backoff can't fix. Transient errors (network, 5xx, rate-limit)
+// should be returned unwrapped so scheduleRetry backs them off instead.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// isPermanentError reports whether err should poison a message immediately
+// rather than scheduling a backoff retry.
+func isPermanentError(err error) bool {
+	var notFound *gmail.NotFoundError
+	if errors.As(err, &notFound) {
+		return true
+	}
+	var perm *PermanentError
+	return errors.As(err, &perm)
+}
+
+// backoffForAttempt returns the delay before the given 1-based attempt
+// number should run: retryBaseDelay doubled per prior attempt, capped at
+// retryMaxDelay, with ±retryJitterPct jitter.
+func backoffForAttempt(attempt int) time.Duration {
+	d := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		d = nextBackoff(d, retryMaxDelay)
+	}
+	return jitter(d, retryJitterPct)
+}
+
+// scheduleRetry records a message fetch/ingest failure against checkpoint,
+// scheduling another attempt per the backoff policy, or moving the message
+// to PoisonedMessageIDs once err is permanent or retryMaxAttempts is
+// exhausted.
+func scheduleRetry(checkpoint *store.Checkpoint, messageID string, err error) {
+	if isPermanentError(err) {
+		checkpoint.PoisonedMessageIDs = append(checkpoint.PoisonedMessageIDs, messageID)
+		delete(checkpoint.Retries, messageID)
+		return
+	}
+
+	if checkpoint.Retries == nil {
+		checkpoint.Retries = make(map[string]*store.MessageRetryState)
+	}
+	state, ok := checkpoint.Retries[messageID]
+	if !ok {
+		state = &store.MessageRetryState{}
+		checkpoint.Retries[messageID] = state
+	}
+	state.AttemptCount++
+	state.LastError = err.Error()
+
+	if state.AttemptCount >= retryMaxAttempts {
+		checkpoint.PoisonedMessageIDs = append(checkpoint.PoisonedMessageIDs, messageID)
+		delete(checkpoint.Retries, messageID)
+		return
+	}
+	state.NextAttemptAt = time.Now().Add(backoffForAttempt(state.AttemptCount))
+}
+
+// dueForRetry reports whether messageID has no pending backoff, or its
+// scheduled retry time has arrived.
+func dueForRetry(checkpoint *store.Checkpoint, messageID string) bool {
+	state, ok := checkpoint.Retries[messageID]
+	if !ok {
+		return true
+	}
+	return !time.Now().Before(state.NextAttemptAt)
+}
+
+// RetryPoisoned re-enqueues every message in the source's poisoned list for
+// another attempt, clearing entries that succeed so a subsequent sync
+// doesn't keep retrying them.
+func (s *Syncer) RetryPoisoned(ctx context.Context, email string) (*gmail.SyncSummary, error) {
+	source, err := s.store.GetSourceByIdentifier(email)
+	if err != nil {
+		return nil, fmt.Errorf("get source: %w", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("no source found for %s", email)
+	}
+
+	checkpoint, err := s.store.GetLatestCheckpoint(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get latest checkpoint: %w", err)
+	}
+	summary := &gmail.SyncSummary{StartTime: time.Now()}
+	if checkpoint == nil || len(checkpoint.PoisonedMessageIDs) == 0 {
+		summary.EndTime = summary.StartTime
+		return summary, nil
+	}
+
+	ids := checkpoint.PoisonedMessageIDs
+	checkpoint.PoisonedMessageIDs = nil
+
+	labelMap, err := s.store.LabelIDMap(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("label id map: %w", err)
+	}
+
+	for _, id := range ids {
+		raw, err := s.client.GetMessageRaw(ctx, id)
+		if err != nil {
+			scheduleRetry(checkpoint, id, err)
+			summary.Poisoned++
+			continue
+		}
+		if err := s.ingestMessage(ctx, source.ID, raw, raw.ThreadID, labelMap); err != nil {
+			scheduleRetry(checkpoint, id, err)
+			summary.Poisoned++
+			continue
+		}
+		summary.MessagesAdded++
+	}
+
+	if err := s.store.SaveCheckpoint(source.ID, checkpoint); err != nil {
+		return summary, fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	summary.EndTime = time.Now()
+	summary.Duration = summary.EndTime.Sub(summary.StartTime)
+	return summary, nil
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+func TestBackoffForAttempt_CapsAtMax(t *testing.T) {
+	d := backoffForAttempt(20)
+	// Jitter is ±25%, so allow some headroom above the nominal cap.
+	if d > retryMaxDelay+retryMaxDelay/4 {
+		t.Errorf("backoffForAttempt(20) = %v, want <= ~%v", d, retryMaxDelay)
+	}
+}
+
+func TestScheduleRetry_TransientSchedulesBackoff(t *testing.T) {
+	cp := &store.Checkpoint{}
+	scheduleRetry(cp, "msg1", errors.New("503 service unavailable"))
+
+	state := cp.Retries["msg1"]
+	if state == nil {
+		t.Fatal("expected retry state for msg1")
+	}
+	if state.AttemptCount != 1 {
+		t.Errorf("AttemptCount = %d, want 1", state.AttemptCount)
+	}
+	if state.NextAttemptAt.Before(time.Now()) {
+		t.Error("NextAttemptAt should be in the future after the first attempt")
+	}
+	if len(cp.PoisonedMessageIDs) != 0 {
+		t.Errorf("PoisonedMessageIDs = %v, want empty after one transient failure", cp.PoisonedMessageIDs)
+	}
+}
+
+func TestScheduleRetry_PoisonsAfterMaxAttempts(t *testing.T) {
+	cp := &store.Checkpoint{}
+	for i := 0; i < retryMaxAttempts; i++ {
+		scheduleRetry(cp, "msg1", errors.New("503 service unavailable"))
+	}
+	if len(cp.PoisonedMessageIDs) != 1 || cp.PoisonedMessageIDs[0] != "msg1" {
+		t.Errorf("PoisonedMessageIDs = %v, want [msg1]", cp.PoisonedMessageIDs)
+	}
+	if _, ok := cp.Retries["msg1"]; ok {
+		t.Error("retry state should be cleared once a message is poisoned")
+	}
+}
+
+func TestScheduleRetry_PermanentErrorPoisonsImmediately(t *testing.T) {
+	cp := &store.Checkpoint{}
+	scheduleRetry(cp, "msg1", &gmail.NotFoundError{Path: "/messages/msg1"})
+	if len(cp.PoisonedMessageIDs) != 1 {
+		t.Errorf("PoisonedMessageIDs = %v, want one entry", cp.PoisonedMessageIDs)
+	}
+	if _, ok := cp.Retries["msg1"]; ok {
+		t.Error("a permanent error should not create retry state")
+	}
+}
+
+func TestDueForRetry_UnknownMessageIsDue(t *testing.T) {
+	cp := &store.Checkpoint{}
+	if !dueForRetry(cp, "msg1") {
+		t.Error("dueForRetry() = false for a message with no retry state")
+	}
+}
+
+func TestDueForRetry_FutureAttemptIsNotDue(t *testing.T) {
+	cp := &store.Checkpoint{Retries: map[string]*store.MessageRetryState{
+		"msg1": {NextAttemptAt: time.Now().Add(time.Minute)},
+	}}
+	if dueForRetry(cp, "msg1") {
+		t.Error("dueForRetry() = true for a message scheduled in the future")
+	}
+}
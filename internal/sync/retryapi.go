@@ -0,0 +1,23 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// apiCallMaxAttempts bounds how many times withAPIRetry retries a single
+// Gmail API call before giving up and returning the error to its caller.
+const apiCallMaxAttempts = 5
+
+// withAPIRetry wraps a single Gmail API call (via s.source) with
+// gmail.CallWithRetry, so a 429/5xx backs off and retries instead of
+// failing the whole sync run. Each retry is forwarded to s.progress.OnError
+// as a *gmail.RetryableError, which carries the wait before the next
+// attempt, so a caller's SyncProgress can show "retrying in Ns" rather than
+// the sync looking stalled.
+func (s *Syncer) withAPIRetry(ctx context.Context, fn func() error) error {
+	return gmail.CallWithRetry(ctx, apiCallMaxAttempts, func(re *gmail.RetryableError) {
+		s.progress.OnError(re)
+	}, fn)
+}
@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+const (
+	runRetryBaseDelay = time.Second
+	runRetryMaxDelay  = 15 * time.Minute
+	runRetryJitterPct = 25
+)
+
+// classifySyncError categorizes err the way FailSync needs to: a 404 or an
+// explicit PermanentError never gets retried, a RateLimitError waits out
+// the source's own reset time, an auth-shaped ServerError (401/403 outside
+// a recognized rate-limit reason) backs off on the chance a refreshed
+// token fixes it, and anything else is assumed transient.
+func classifySyncError(err error) store.SyncErrorClass {
+	var notFound *gmail.NotFoundError
+	if errors.As(err, &notFound) {
+		return store.SyncErrorPermanent
+	}
+	var perm *PermanentError
+	if errors.As(err, &perm) {
+		return store.SyncErrorPermanent
+	}
+	var rle *gmail.RateLimitError
+	if errors.As(err, &rle) {
+		return store.SyncErrorRateLimit
+	}
+	var se *gmail.ServerError
+	if errors.As(err, &se) && (se.StatusCode == 401 || se.StatusCode == 403) {
+		return store.SyncErrorAuth
+	}
+	return store.SyncErrorTransient
+}
+
+// rateLimitDelay returns the delay a RateLimitError in err's chain
+// requests, if any.
+func rateLimitDelay(err error) (time.Duration, bool) {
+	var rle *gmail.RateLimitError
+	if errors.As(err, &rle) && rle.RetryAfter > 0 {
+		return rle.RetryAfter, true
+	}
+	return 0, false
+}
+
+// backoffForRunAttempt returns the jittered delay before the given 1-based
+// run retry attempt should run: runRetryBaseDelay doubled per prior
+// attempt, capped at runRetryMaxDelay, with +/-25% jitter.
+func backoffForRunAttempt(attempt int) time.Duration {
+	d := runRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		d = nextBackoff(d, runRetryMaxDelay)
+	}
+	return jitter(d, runRetryJitterPct)
+}
+
+// computeRunRetry works out what FailSync should set checkpoint.NextRetryAt
+// and checkpoint.RateLimitResetAt to, given the classified error and how
+// many times this run has already been retried. A SyncErrorPermanent
+// result leaves both at their zero value, meaning "not scheduled" - the
+// run is poisoned rather than resumed.
+func computeRunRetry(class store.SyncErrorClass, err error, retryCount int, now time.Time) (nextRetryAt, rateLimitResetAt time.Time) {
+	switch class {
+	case store.SyncErrorPermanent:
+		return time.Time{}, time.Time{}
+	case store.SyncErrorRateLimit:
+		delay := runRetryMaxDelay
+		if d, ok := rateLimitDelay(err); ok {
+			delay = d
+		}
+		resetAt := now.Add(delay)
+		return resetAt, resetAt
+	default:
+		return now.Add(backoffForRunAttempt(retryCount + 1)), time.Time{}
+	}
+}
+
+// newSyncError builds the store.SyncError RecordSyncError would persist for
+// a sync run failure.
+func newSyncError(syncID int64, err error, now time.Time) store.SyncError {
+	return store.SyncError{
+		SyncID:     syncID,
+		Class:      classifySyncError(err),
+		Message:    err.Error(),
+		OccurredAt: now,
+	}
+}
+
+// dueForSyncResume reports whether a failed run's checkpoint is eligible to
+// resume: its RateLimitResetAt and NextRetryAt (whichever is later, since a
+// rate-limited run that also accumulated ordinary backoff should wait for
+// the longer of the two) have passed. A checkpoint that has never failed
+// has both at their zero value and is always due.
+func dueForSyncResume(checkpoint *store.Checkpoint, now time.Time) bool {
+	if checkpoint.RateLimitResetAt.After(now) {
+		return false
+	}
+	return !checkpoint.NextRetryAt.After(now)
+}
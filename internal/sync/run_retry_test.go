@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+func TestClassifySyncError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want store.SyncErrorClass
+	}{
+		{"not found", &gmail.NotFoundError{Path: "/messages/1"}, store.SyncErrorPermanent},
+		{"explicit permanent", &PermanentError{Err: errors.New("bad parse")}, store.SyncErrorPermanent},
+		{"rate limit", &gmail.RateLimitError{Reason: gmail.ReasonRateLimitExceeded}, store.SyncErrorRateLimit},
+		{"auth 401", &gmail.ServerError{StatusCode: 401, Err: errors.New("unauthorized")}, store.SyncErrorAuth},
+		{"auth 403", &gmail.ServerError{StatusCode: 403, Err: errors.New("forbidden")}, store.SyncErrorAuth},
+		{"server 503", &gmail.ServerError{StatusCode: 503, Err: errors.New("unavailable")}, store.SyncErrorTransient},
+		{"plain error", errors.New("connection reset"), store.SyncErrorTransient},
+	}
+	for _, tt := range tests {
+		if got := classifySyncError(tt.err); got != tt.want {
+			t.Errorf("%s: classifySyncError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComputeRunRetry_PermanentNeverScheduled(t *testing.T) {
+	now := time.Now()
+	nextRetryAt, rateLimitResetAt := computeRunRetry(store.SyncErrorPermanent, errors.New("gone"), 3, now)
+	if !nextRetryAt.IsZero() || !rateLimitResetAt.IsZero() {
+		t.Errorf("computeRunRetry(permanent) = (%v, %v), want both zero", nextRetryAt, rateLimitResetAt)
+	}
+}
+
+func TestComputeRunRetry_RateLimitUsesServerDelay(t *testing.T) {
+	now := time.Now()
+	err := &gmail.RateLimitError{Reason: gmail.ReasonQuotaExceeded, RetryAfter: 30 * time.Second}
+	nextRetryAt, rateLimitResetAt := computeRunRetry(store.SyncErrorRateLimit, err, 0, now)
+	if got := nextRetryAt.Sub(now); got != 30*time.Second {
+		t.Errorf("nextRetryAt offset = %v, want 30s", got)
+	}
+	if !nextRetryAt.Equal(rateLimitResetAt) {
+		t.Errorf("rateLimitResetAt = %v, want it to equal nextRetryAt %v", rateLimitResetAt, nextRetryAt)
+	}
+}
+
+func TestComputeRunRetry_TransientBacksOffExponentially(t *testing.T) {
+	now := time.Now()
+	first, rateLimitResetAt := computeRunRetry(store.SyncErrorTransient, errors.New("timeout"), 0, now)
+	if !first.After(now) {
+		t.Error("first retry should be scheduled in the future")
+	}
+	if !rateLimitResetAt.IsZero() {
+		t.Errorf("rateLimitResetAt = %v, want zero for a transient error", rateLimitResetAt)
+	}
+
+	second, _ := computeRunRetry(store.SyncErrorTransient, errors.New("timeout"), 4, now)
+	// retryCount=4's nominal delay (16x base) well exceeds retryCount=0's
+	// (1x base) even after +/-25% jitter on each.
+	if !second.After(first) {
+		t.Errorf("retry delay should grow with retryCount: first=%v second=%v", first, second)
+	}
+}
+
+func TestNewSyncError(t *testing.T) {
+	now := time.Now()
+	se := newSyncError(42, &gmail.NotFoundError{Path: "/messages/1"}, now)
+	if se.SyncID != 42 {
+		t.Errorf("SyncID = %d, want 42", se.SyncID)
+	}
+	if se.Class != store.SyncErrorPermanent {
+		t.Errorf("Class = %v, want permanent", se.Class)
+	}
+	if !se.OccurredAt.Equal(now) {
+		t.Errorf("OccurredAt = %v, want %v", se.OccurredAt, now)
+	}
+}
+
+func TestDueForSyncResume_NeverFailedIsDue(t *testing.T) {
+	if !dueForSyncResume(&store.Checkpoint{}, time.Now()) {
+		t.Error("a checkpoint that never failed should always be due")
+	}
+}
+
+func TestDueForSyncResume_FutureNextRetryAtIsNotDue(t *testing.T) {
+	cp := &store.Checkpoint{NextRetryAt: time.Now().Add(time.Minute)}
+	if dueForSyncResume(cp, time.Now()) {
+		t.Error("a checkpoint with a future NextRetryAt should not be due")
+	}
+}
+
+func TestDueForSyncResume_PastNextRetryAtIsDue(t *testing.T) {
+	cp := &store.Checkpoint{NextRetryAt: time.Now().Add(-time.Minute)}
+	if !dueForSyncResume(cp, time.Now()) {
+		t.Error("a checkpoint whose NextRetryAt has passed should be due")
+	}
+}
+
+func TestDueForSyncResume_RateLimitResetAtTakesPrecedence(t *testing.T) {
+	now := time.Now()
+	cp := &store.Checkpoint{
+		NextRetryAt:      now.Add(-time.Minute),
+		RateLimitResetAt: now.Add(time.Minute),
+	}
+	if dueForSyncResume(cp, now) {
+		t.Error("a checkpoint still waiting out RateLimitResetAt should not be due")
+	}
+}
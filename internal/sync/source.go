@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// Source kind constants, recorded as store.Source.Kind so a Syncer's
+// caller knows which Source implementation to construct (NewGmailSource,
+// imap.Dial, or maildir.Open) when resuming a sync for an existing source
+// row.
+const (
+	SourceKindGmail   = "gmail"
+	SourceKindIMAP    = "imap"
+	SourceKindMaildir = "maildir"
+)
+
+// Source is the source-agnostic interface the syncer drives to pull mail
+// into the vault. Gmail was the original (and only) backend; Source exists
+// so other mail providers (IMAP, etc.) can be synced through the same
+// Syncer without it knowing which wire protocol is underneath.
+//
+// Cursor is an opaque string the syncer persists as store.Source.SyncCursor
+// and passes back into History to resume an incremental sync. Each backend
+// defines its own cursor format: Gmail uses its decimal HistoryID, IMAP uses
+// "<uidvalidity>:<highest-modseq>".
+type Source interface {
+	// Profile returns identifying information about the account being
+	// synced, including its current cursor value.
+	Profile(ctx context.Context) (*SourceProfile, error)
+
+	// ListLabels returns the mailbox's labels/folders.
+	ListLabels(ctx context.Context) ([]*gmail.Label, error)
+
+	// ListMessages returns message IDs matching query, paginated via
+	// pageToken.
+	ListMessages(ctx context.Context, query string, pageToken string) (*gmail.MessageListResponse, error)
+
+	// GetMessage fetches a single message with raw MIME data.
+	GetMessage(ctx context.Context, id string) (*gmail.RawMessage, error)
+
+	// History returns changes since cursor, paginated via pageToken.
+	// Implementations return ErrHistoryExpired when cursor is too old to
+	// resume from, signaling the caller should fall back to a full sync.
+	History(ctx context.Context, cursor string, pageToken string) (*SourceHistory, error)
+
+	// Close releases any resources held by the source.
+	Close() error
+}
+
+// SourceProfile describes the account being synced.
+type SourceProfile struct {
+	// Identifier is the value stored as store.Source.Identifier (an email
+	// address for Gmail, "user@host/INBOX"-style for IMAP).
+	Identifier string
+	// MessagesTotal is the backend's best estimate of the mailbox size.
+	MessagesTotal int64
+	// Cursor is the backend's current position, to compare against the
+	// previously-persisted store.Source.SyncCursor.
+	Cursor string
+}
+
+// SourceHistory mirrors gmail.HistoryResponse but keyed by the
+// backend-neutral cursor instead of a Gmail-specific HistoryID.
+type SourceHistory struct {
+	Records       []gmail.HistoryRecord
+	NextPageToken string
+	Cursor        string
+}
+
+// gmailSource adapts a gmail.API into a Source, translating between
+// Gmail's numeric HistoryID and the string cursor the syncer persists.
+type gmailSource struct {
+	client gmail.API
+}
+
+// NewGmailSource wraps client as a Source.
+func NewGmailSource(client gmail.API) Source {
+	return &gmailSource{client: client}
+}
+
+func (g *gmailSource) Profile(ctx context.Context) (*SourceProfile, error) {
+	profile, err := g.client.GetProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &SourceProfile{
+		Identifier:    profile.EmailAddress,
+		MessagesTotal: profile.MessagesTotal,
+		Cursor:        strconv.FormatUint(profile.HistoryID, 10),
+	}, nil
+}
+
+func (g *gmailSource) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	return g.client.ListLabels(ctx)
+}
+
+func (g *gmailSource) ListMessages(ctx context.Context, query string, pageToken string) (*gmail.MessageListResponse, error) {
+	return g.client.ListMessages(ctx, query, pageToken)
+}
+
+func (g *gmailSource) GetMessage(ctx context.Context, id string) (*gmail.RawMessage, error) {
+	return g.client.GetMessageRaw(ctx, id)
+}
+
+func (g *gmailSource) History(ctx context.Context, cursor string, pageToken string) (*SourceHistory, error) {
+	startHistoryID, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return nil, &ErrInvalidCursor{Cursor: cursor, Err: err}
+	}
+	resp, err := g.client.ListHistory(ctx, startHistoryID, pageToken)
+	if err != nil {
+		var notFound *gmail.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, ErrHistoryExpired
+		}
+		return nil, err
+	}
+	return &SourceHistory{
+		Records:       resp.History,
+		NextPageToken: resp.NextPageToken,
+		Cursor:        strconv.FormatUint(resp.HistoryID, 10),
+	}, nil
+}
+
+func (g *gmailSource) Close() error {
+	return g.client.Close()
+}
+
+// ErrInvalidCursor is returned when a Source is asked to resume from a
+// cursor it cannot parse.
+type ErrInvalidCursor struct {
+	Cursor string
+	Err    error
+}
+
+func (e *ErrInvalidCursor) Error() string {
+	return "sync: invalid cursor " + strconv.Quote(e.Cursor) + ": " + e.Err.Error()
+}
+
+func (e *ErrInvalidCursor) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sseHeartbeatInterval is how often EventBroker writes a comment-only
+// keepalive line to each subscriber, so idle connections (and the
+// intermediaries between them) don't time out during a quiet sync.
+const sseHeartbeatInterval = 30 * time.Second
+
+// sseHistorySize bounds how many past events EventBroker keeps for replay
+// on reconnect. Older events are dropped; a client reconnecting with a
+// Last-Event-ID older than the oldest retained event just resumes from
+// whatever's left, rather than erroring.
+const sseHistorySize = 1000
+
+// sseEvent is one Server-Sent Event: a monotonic ID (for Last-Event-ID
+// resume), an event type matching a ProgressSink method, and its data line.
+type sseEvent struct {
+	ID   uint64
+	Type string
+	Data string
+}
+
+// EventBroker is a ProgressSink that fans sync events out to subscribed SSE
+// clients, modelled on the connection/Request/ViewID pattern in mox's
+// webmail view: each subscriber gets its own buffered channel, and a
+// bounded ring of recent events lets a client resume after a dropped
+// connection instead of missing events entirely.
+type EventBroker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []sseEvent
+	subscribers map[chan sseEvent]struct{}
+}
+
+// NewEventBroker returns an EventBroker ready to be wired in as an
+// Options.ProgressSink and served via ServeHTTP.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subscribers: make(map[chan sseEvent]struct{})}
+}
+
+func (b *EventBroker) publish(eventType, data string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	ev := sseEvent{ID: b.nextID, Type: eventType, Data: data}
+	b.history = append(b.history, ev)
+	if len(b.history) > sseHistorySize {
+		b.history = b.history[len(b.history)-sseHistorySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// Slow subscriber; drop the event rather than block the sync.
+		}
+	}
+}
+
+// OnPage implements ProgressSink.
+func (b *EventBroker) OnPage(token string, fetched, added, errors int) {
+	b.publish("page", fmt.Sprintf(`{"token":%q,"fetched":%d,"added":%d,"errors":%d}`, token, fetched, added, errors))
+}
+
+// OnMessage implements ProgressSink.
+func (b *EventBroker) OnMessage(id string, bytesFetched int64) {
+	b.publish("message", fmt.Sprintf(`{"id":%q,"bytesFetched":%d}`, id, bytesFetched))
+}
+
+// OnCheckpoint implements ProgressSink.
+func (b *EventBroker) OnCheckpoint(token string) {
+	b.publish("checkpoint", fmt.Sprintf(`{"token":%q}`, token))
+}
+
+// subscribe registers a new subscriber and returns its event channel
+// (pre-loaded with any retained history after afterID) plus an unsubscribe
+// func the caller must defer.
+func (b *EventBroker) subscribe(afterID uint64) (ch chan sseEvent, unsubscribe func()) {
+	ch = make(chan sseEvent, 64)
+
+	b.mu.Lock()
+	for _, ev := range b.history {
+		if ev.ID > afterID {
+			ch <- ev
+		}
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// ServeHTTP upgrades the request to an SSE stream (GET /sync/events),
+// replaying any retained events after the client's Last-Event-ID cursor
+// before streaming new ones, with a heartbeat comment every
+// sseHeartbeatInterval to keep the connection alive.
+func (b *EventBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var afterID uint64
+	if last := req.Header.Get("Last-Event-ID"); last != "" {
+		afterID, _ = strconv.ParseUint(last, 10, 64)
+	}
+
+	ch, unsubscribe := b.subscribe(afterID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case ev := <-ch:
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -1,14 +1,17 @@
 package sync
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/wesm/msgvault/internal/blobstore"
 	"github.com/wesm/msgvault/internal/gmail"
 	"github.com/wesm/msgvault/internal/store"
 )
@@ -2208,6 +2211,151 @@ func TestFullSyncEmptyRawMIME(t *testing.T) {
 	}
 }
 
+func TestFullSyncQuarantinesMalformedHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{
+			"duplicate-from",
+			[]byte("From: alice@example.com\r\nFrom: mallory@example.com\r\nTo: bob@example.com\r\nSubject: hi\r\nDate: Mon, 01 Jan 2024 12:00:00 +0000\r\nMessage-ID: <dup@example.com>\r\n\r\nbody"),
+		},
+		{
+			"missing-from",
+			[]byte("To: bob@example.com\r\nSubject: hi\r\nDate: Mon, 01 Jan 2024 12:00:00 +0000\r\nMessage-ID: <nofrom@example.com>\r\n\r\nbody"),
+		},
+		{
+			"bad-message-id",
+			[]byte("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: hi\r\nDate: Mon, 01 Jan 2024 12:00:00 +0000\r\nMessage-ID: not-bracketed\r\n\r\nbody"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "msgvault-test-*")
+			if err != nil {
+				t.Fatalf("create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			dbPath := filepath.Join(tmpDir, "test.db")
+			st, err := store.Open(dbPath)
+			if err != nil {
+				t.Fatalf("open store: %v", err)
+			}
+			defer st.Close()
+
+			if err := st.InitSchema(); err != nil {
+				t.Fatalf("init schema: %v", err)
+			}
+
+			mock := gmail.NewMockAPI()
+			mock.Profile = &gmail.Profile{
+				EmailAddress:  "test@example.com",
+				MessagesTotal: 1,
+				HistoryID:     12345,
+			}
+			mock.AddMessage("msg-malformed", tt.raw, []string{"INBOX"})
+
+			syncer := New(mock, st, nil)
+			ctx := context.Background()
+
+			summary, err := syncer.Full(ctx, "test@example.com")
+			if err != nil {
+				t.Fatalf("full sync: %v", err)
+			}
+			if summary.Quarantined != 1 {
+				t.Errorf("summary.Quarantined = %d, want 1", summary.Quarantined)
+			}
+			if summary.MessagesAdded != 0 {
+				t.Errorf("summary.MessagesAdded = %d, want 0", summary.MessagesAdded)
+			}
+
+			var count int
+			if err := st.DB().QueryRow(st.Rebind(
+				"SELECT COUNT(*) FROM messages WHERE source_message_id = ?"), "msg-malformed").Scan(&count); err != nil {
+				t.Fatalf("query messages: %v", err)
+			}
+			if count != 0 {
+				t.Errorf("messages row count = %d, want 0 (message should be quarantined, not stored)", count)
+			}
+		})
+	}
+}
+
+func TestFullSyncPersistsRawMIMEToDisk(t *testing.T) {
+	// A store opened with WithBlobStorage should serve a synced message's
+	// raw MIME back from disk, and reject a tampered blob_sha256 cleanly.
+	tmpDir, err := os.MkdirTemp("", "msgvault-test-*")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "test.db")
+	blobDir := filepath.Join(tmpDir, "blobs")
+	st, err := store.Open(dbPath, store.WithBlobStorage(blobDir, blobstore.MessageCompressionGzip))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+
+	if err := st.InitSchema(); err != nil {
+		t.Fatalf("init schema: %v", err)
+	}
+
+	mock := gmail.NewMockAPI()
+	mock.Profile = &gmail.Profile{
+		EmailAddress:  "test@example.com",
+		MessagesTotal: 1,
+		HistoryID:     12345,
+	}
+	mock.AddMessage("msg-good", testMIME, []string{"INBOX"})
+
+	syncer := New(mock, st, nil)
+	ctx := context.Background()
+
+	summary, err := syncer.Full(ctx, "test@example.com")
+	if err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+	if summary.MessagesAdded != 1 {
+		t.Fatalf("expected 1 message added, got %d", summary.MessagesAdded)
+	}
+
+	var msgID int64
+	var blobPath string
+	err = st.DB().QueryRow(st.Rebind(
+		"SELECT id, blob_path FROM messages WHERE source_message_id = ?"), "msg-good").Scan(&msgID, &blobPath)
+	if err != nil {
+		t.Fatalf("query message: %v", err)
+	}
+	if blobPath == "" {
+		t.Fatal("expected blob_path to be set once blob storage is configured")
+	}
+
+	rc, err := st.OpenMessageRaw(msgID)
+	if err != nil {
+		t.Fatalf("open message raw: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read message raw: %v", err)
+	}
+	if !bytes.Equal(got, testMIME) {
+		t.Errorf("OpenMessageRaw() = %q, want %q", got, testMIME)
+	}
+
+	if _, err := st.DB().Exec(st.Rebind(
+		"UPDATE messages SET blob_sha256 = ? WHERE id = ?"), strings.Repeat("0", 64), msgID); err != nil {
+		t.Fatalf("corrupt blob_sha256: %v", err)
+	}
+	if _, err := st.OpenMessageRaw(msgID); err == nil {
+		t.Error("OpenMessageRaw() after checksum corruption succeeded, want error")
+	}
+}
+
 func TestFullSyncEmptyThreadID(t *testing.T) {
 	// Test that messages with empty thread ID use message ID as fallback
 	tmpDir, err := os.MkdirTemp("", "msgvault-test-*")
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"log/slog"
+
+	"github.com/wesm/msgvault/internal/blobstore"
+	"github.com/wesm/msgvault/internal/gmail"
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// Syncer drives a full or incremental sync of a mail source into a Store.
+// Every file in this package adds methods to it: Full and Incremental
+// (this file's siblings) ingest messages, Repair/Verify reconcile local
+// state against the remote mailbox, Export writes stored messages back
+// out, and Run/watchAccount supervise these as a long-running daemon.
+// Construct one with New; the With* builders customize the pieces that
+// aren't set via Options.
+type Syncer struct {
+	store  *store.Store
+	client gmail.API
+	source Source
+
+	sink     ProgressSink
+	progress gmail.SyncProgress
+	logger   *slog.Logger
+
+	quarantineMode  QuarantineMode
+	blobStore       blobstore.BlobStore
+	attachmentStore blobstore.AttachmentStore
+
+	minFreeSpaceBytes int64
+	noResume          bool
+	query             string
+}
+
+// New builds a Syncer that talks to the mail provider through api and
+// persists into st. A nil opts is equivalent to DefaultOptions(): no
+// attachment store, ModeQuarantine, no blob store, and resume enabled.
+func New(api gmail.API, st *store.Store, opts *Options) *Syncer {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	// resolveAttachmentStore only fails building the deprecated
+	// AttachmentsDir fallback (e.g. a directory it can't create); New has
+	// no error return, so that degrades to no attachment store rather than
+	// panicking - the same as never having set AttachmentsDir at all.
+	attachmentStore, _ := resolveAttachmentStore(opts)
+
+	return &Syncer{
+		store:  st,
+		client: api,
+		source: NewGmailSource(api),
+
+		sink:     resolveProgressSink(opts),
+		progress: gmail.NullProgress{},
+		logger:   nil,
+
+		quarantineMode:  resolveQuarantineMode(opts),
+		blobStore:       opts.BlobStore,
+		attachmentStore: attachmentStore,
+
+		minFreeSpaceBytes: opts.MinFreeSpaceBytes,
+		noResume:          opts.NoResume,
+		query:             opts.Query,
+	}
+}
+
+// WithLogger installs logger for the Syncer's own diagnostic logging
+// (push notifications, watch renewal, history-expired fallback). A nil
+// logger discards it.
+func (s *Syncer) WithLogger(logger *slog.Logger) *Syncer {
+	s.logger = logger
+	return s
+}
+
+// WithProgress installs progress in place of the default gmail.NullProgress
+// for aggregate sync-run reporting (see ProgressSink for the finer-grained
+// alternative).
+func (s *Syncer) WithProgress(progress gmail.SyncProgress) *Syncer {
+	s.progress = progress
+	return s
+}
@@ -0,0 +1,30 @@
+package sync
+
+import "github.com/wesm/msgvault/internal/rfc5322"
+
+// QuarantineMode controls how the syncer reacts to a message that fails
+// RFC 5322 header validation.
+type QuarantineMode string
+
+const (
+	// ModeReject skips the message entirely, counting it as an error.
+	ModeReject QuarantineMode = "reject"
+	// ModeStorePlaceholder ingests the message with a placeholder body in
+	// place of the invalid raw bytes, preserving the message's existence
+	// (thread position, labels) without the unreadable content.
+	ModeStorePlaceholder QuarantineMode = "store-placeholder"
+	// ModeQuarantine persists the message's raw bytes and violated rules to
+	// the quarantine table instead of messages, retryable via
+	// Syncer.RetryQuarantined once the underlying issue is understood. This
+	// is the default: nothing is silently dropped or lost.
+	ModeQuarantine QuarantineMode = "quarantine"
+)
+
+// validateHeaders checks raw's header block against RFC 5322 §2.2 and
+// §3.6 and returns the names of every rule it violates (empty if none). It
+// does not parse or validate the message body. The checks themselves live
+// in the standalone rfc5322 package so other tools can run them without
+// depending on the syncer.
+func validateHeaders(raw []byte) []string {
+	return rfc5322.Violations(raw)
+}
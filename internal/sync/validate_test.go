@@ -0,0 +1,102 @@
+package sync
+
+import "testing"
+
+func TestValidateHeaders_Valid(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: hi\r\nDate: Mon, 1 Jan 2024 00:00:00 +0000\r\nMessage-ID: <abc@example.com>\r\n\r\nbody")
+	if got := validateHeaders(raw); len(got) != 0 {
+		t.Errorf("validateHeaders() = %v, want no violations", got)
+	}
+}
+
+func TestValidateHeaders_MissingFrom(t *testing.T) {
+	raw := []byte("To: bob@example.com\r\nSubject: hi\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "missing-from") {
+		t.Errorf("validateHeaders() = %v, want missing-from", got)
+	}
+}
+
+func TestValidateHeaders_DuplicateFrom(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nFrom: mallory@example.com\r\nSubject: hi\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "duplicate-from") {
+		t.Errorf("validateHeaders() = %v, want duplicate-from", got)
+	}
+}
+
+func TestValidateHeaders_InvalidToAddress(t *testing.T) {
+	raw := []byte("From: alice@example.com\r\nTo: not an address\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "invalid-to") {
+		t.Errorf("validateHeaders() = %v, want invalid-to", got)
+	}
+}
+
+func TestValidateHeaders_DuplicateSingletons(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want string
+	}{
+		{
+			"sender",
+			[]byte("From: a@example.com\r\nSender: a@example.com\r\nSender: b@example.com\r\n\r\nbody"),
+			"duplicate-sender",
+		},
+		{
+			"reply-to",
+			[]byte("From: a@example.com\r\nReply-To: a@example.com\r\nReply-To: b@example.com\r\n\r\nbody"),
+			"duplicate-reply-to",
+		},
+		{
+			"date",
+			[]byte("From: a@example.com\r\nDate: Mon, 1 Jan 2024 00:00:00 +0000\r\nDate: Tue, 2 Jan 2024 00:00:00 +0000\r\n\r\nbody"),
+			"duplicate-date",
+		},
+		{
+			"subject",
+			[]byte("From: a@example.com\r\nSubject: hi\r\nSubject: bye\r\n\r\nbody"),
+			"duplicate-subject",
+		},
+		{
+			"message-id",
+			[]byte("From: a@example.com\r\nMessage-ID: <1@example.com>\r\nMessage-ID: <2@example.com>\r\n\r\nbody"),
+			"duplicate-message-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateHeaders(tt.raw); !contains(got, tt.want) {
+				t.Errorf("validateHeaders() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHeaders_NulInHeader(t *testing.T) {
+	raw := []byte("From: a@example.com\r\nSubject: hi\x00there\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "nul-in-header") {
+		t.Errorf("validateHeaders() = %v, want nul-in-header", got)
+	}
+}
+
+func TestValidateHeaders_BareLFInHeader(t *testing.T) {
+	raw := []byte("From: a@example.com\r\nSubject: hi\nthere\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "bare-lf-in-header") {
+		t.Errorf("validateHeaders() = %v, want bare-lf-in-header", got)
+	}
+}
+
+func TestValidateHeaders_InvalidFieldName(t *testing.T) {
+	raw := []byte("From: a@example.com\r\nX Bad Name: hi\r\n\r\nbody")
+	if got := validateHeaders(raw); !contains(got, "invalid-field-name") {
+		t.Errorf("validateHeaders() = %v, want invalid-field-name", got)
+	}
+}
+
+func contains(ss []string, target string) bool {
+	for _, s := range ss {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
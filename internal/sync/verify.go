@@ -0,0 +1,186 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/wesm/msgvault/internal/gmail"
+)
+
+// VerifyOptions configures Syncer.Verify.
+type VerifyOptions struct {
+	// Concurrency bounds how many attachment blobs are checked in parallel.
+	// Defaults to 1 if <= 0.
+	Concurrency int
+}
+
+// Inconsistency is one invariant Verify found violated.
+type Inconsistency struct {
+	// Category identifies which invariant failed: "missing-raw",
+	// "raw-hash-mismatch", "missing-attachment-blob",
+	// "attachment-hash-mismatch", "invalid-sync-cursor", or
+	// "orphan-label-mapping".
+	Category string
+	// Detail identifies the affected row, e.g. a message or label ID.
+	Detail string
+	// Repairable reports whether Syncer.Reset can fix this class of
+	// inconsistency.
+	Repairable bool
+}
+
+// VerifyReport enumerates everything Verify found wrong with a source.
+type VerifyReport struct {
+	MessagesChecked    int64
+	AttachmentsChecked int64
+	Inconsistencies    []Inconsistency
+}
+
+// OK reports whether Verify found no inconsistencies.
+func (r *VerifyReport) OK() bool {
+	return len(r.Inconsistencies) == 0
+}
+
+// Verify cross-checks a source's local state for the corruption that
+// recurs after a partial restore, a crashed sync, or manual DB surgery: raw
+// message bytes that no longer match their stored digest, attachment blobs
+// missing from the BlobStore, a sync_cursor the Gmail API no longer
+// recognizes, and message_labels rows pointing at a label that no longer
+// exists.
+func (s *Syncer) Verify(ctx context.Context, email string, opts VerifyOptions) (*VerifyReport, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	source, err := s.store.GetSourceByIdentifier(email)
+	if err != nil {
+		return nil, fmt.Errorf("get source: %w", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("no source found for %s", email)
+	}
+
+	report := &VerifyReport{}
+
+	checksums, err := s.store.ListMessageRawChecksums(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list message raw checksums: %w", err)
+	}
+	for _, c := range checksums {
+		report.MessagesChecked++
+		if c.Raw == nil {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Category: "missing-raw", Detail: c.SourceMessageID, Repairable: false,
+			})
+			continue
+		}
+		if sum := sha256Hex(c.Raw); sum != c.StoredSHA256 {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Category: "raw-hash-mismatch", Detail: c.SourceMessageID, Repairable: false,
+			})
+		}
+	}
+
+	refs, err := s.store.ListAttachmentBlobRefs(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachment blob refs: %w", err)
+	}
+	for _, ref := range refs {
+		report.AttachmentsChecked++
+		if s.blobStore == nil {
+			continue
+		}
+		data, err := s.blobStore.Get(ctx, ref.SHA256)
+		if err != nil {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Category: "missing-attachment-blob", Detail: ref.SHA256, Repairable: false,
+			})
+			continue
+		}
+		if sha256Hex(data) != ref.SHA256 {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Category: "attachment-hash-mismatch", Detail: ref.SHA256, Repairable: false,
+			})
+		}
+	}
+
+	if source.SyncCursor.Valid && source.SyncCursor.String != "" {
+		historyID, err := strconv.ParseUint(source.SyncCursor.String, 10, 64)
+		if err != nil {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Category: "invalid-sync-cursor", Detail: source.SyncCursor.String, Repairable: true,
+			})
+		} else if _, err := s.client.ListHistory(ctx, historyID, ""); err != nil {
+			var notFound *gmail.NotFoundError
+			if errors.As(err, &notFound) {
+				report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+					Category: "invalid-sync-cursor", Detail: source.SyncCursor.String, Repairable: true,
+				})
+			}
+		}
+	}
+
+	orphans, err := s.store.FindOrphanLabelMappings(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("find orphan label mappings: %w", err)
+	}
+	for _, labelID := range orphans {
+		report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+			Category: "orphan-label-mapping", Detail: fmt.Sprintf("label %d", labelID), Repairable: true,
+		})
+	}
+
+	return report, nil
+}
+
+// ResetMode selects how much of a source's derived state Syncer.Reset
+// rebuilds.
+type ResetMode string
+
+const (
+	// ResetCursorOnly clears sync_cursor so the next sync runs a full sync
+	// instead of trying (and failing) to resume from a stale history ID.
+	ResetCursorOnly ResetMode = "cursor-only"
+	// ResetLabels rebuilds the labels table and message_labels mappings
+	// from the current Gmail label set, fixing orphaned mappings.
+	ResetLabels ResetMode = "labels"
+	// ResetFullRebuild drops every derived table (messages, labels,
+	// message_labels, attachments) but keeps message_raw, then re-parses
+	// every stored raw message to rebuild them.
+	ResetFullRebuild ResetMode = "full-rebuild"
+)
+
+// Reset repairs a source's local state per mode. It does not re-fetch
+// anything from Gmail except, for ResetLabels, the current label list.
+func (s *Syncer) Reset(ctx context.Context, email string, mode ResetMode) error {
+	source, err := s.store.GetSourceByIdentifier(email)
+	if err != nil {
+		return fmt.Errorf("get source: %w", err)
+	}
+	if source == nil {
+		return fmt.Errorf("no source found for %s", email)
+	}
+
+	switch mode {
+	case ResetCursorOnly:
+		return s.store.UpdateSourceSyncCursor(source.ID, "")
+	case ResetLabels:
+		labels, err := s.client.ListLabels(ctx)
+		if err != nil {
+			return fmt.Errorf("list labels: %w", err)
+		}
+		return s.store.RebuildLabels(source.ID, labels)
+	case ResetFullRebuild:
+		return s.store.RebuildFromRaw(source.ID)
+	default:
+		return fmt.Errorf("unknown reset mode %q", mode)
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,22 @@
+package sync
+
+import "testing"
+
+func TestSha256Hex(t *testing.T) {
+	got := sha256Hex([]byte("hello"))
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if got != want {
+		t.Errorf("sha256Hex() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyReport_OK(t *testing.T) {
+	r := &VerifyReport{}
+	if !r.OK() {
+		t.Error("OK() = false for empty report")
+	}
+	r.Inconsistencies = append(r.Inconsistencies, Inconsistency{Category: "missing-raw"})
+	if r.OK() {
+		t.Error("OK() = true with inconsistencies present")
+	}
+}
@@ -0,0 +1,114 @@
+// Package testutil provides small, dependency-free helpers shared by this
+// module's tests: a throwaway Store backed by an in-memory database, and
+// file fixture helpers for tests that exercise on-disk blob storage.
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// NewTestStore returns a *store.Store backed by a fresh in-memory SQLite
+// database, closed automatically via t.Cleanup. Each call gets its own
+// database, so tests never see another test's rows.
+func NewTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	st, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("testutil: open test store: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := st.Close(); err != nil {
+			t.Errorf("testutil: close test store: %v", err)
+		}
+	})
+	return st
+}
+
+// TempDir returns a fresh temporary directory, removed automatically at
+// the end of the test (via t.TempDir). It exists so tests depend on
+// testutil rather than reaching for t.TempDir directly, matching how
+// NewTestStore centralizes store setup.
+func TempDir(t *testing.T) string {
+	t.Helper()
+	return t.TempDir()
+}
+
+// WriteFile writes content to name under dir, creating any intermediate
+// directories name implies, and returns the full path written. name must
+// be a relative path that stays within dir (see validateRelativePath);
+// WriteFile fails the test otherwise.
+func WriteFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+
+	if err := validateRelativePath(dir, name); err != nil {
+		t.Fatalf("testutil: write file %s: %v", name, err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("testutil: mkdir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("testutil: write %s: %v", path, err)
+	}
+	return path
+}
+
+// ReadFile reads and returns the contents of path, failing the test if it
+// can't be read.
+func ReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testutil: read %s: %v", path, err)
+	}
+	return data
+}
+
+// MustExist fails the test unless path exists.
+func MustExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("testutil: expected %s to exist: %v", path, err)
+	}
+}
+
+// MustNotExist fails the test if path exists.
+func MustNotExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); err == nil {
+		t.Fatalf("testutil: expected %s not to exist", path)
+	} else if !os.IsNotExist(err) {
+		t.Fatalf("testutil: stat %s: %v", path, err)
+	}
+}
+
+// validateRelativePath rejects any name that is absolute, rooted, or
+// escapes dir via "..", so WriteFile can't be used to write outside the
+// directory a test expects to confine itself to.
+func validateRelativePath(dir, name string) error {
+	if filepath.IsAbs(name) {
+		return fmt.Errorf("testutil: %q is an absolute path", name)
+	}
+	if strings.HasPrefix(name, string(filepath.Separator)) {
+		return fmt.Errorf("testutil: %q is a rooted path", name)
+	}
+
+	cleaned := filepath.Join(dir, name)
+	if cleaned != dir && !strings.HasPrefix(cleaned, dir+string(filepath.Separator)) {
+		return fmt.Errorf("testutil: %q escapes %q", name, dir)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// responseSnippetLimit bounds how much of a receiver's response body the
+// delivery log keeps, so a misbehaving receiver can't bloat it.
+const responseSnippetLimit = 512
+
+// Dispatcher drains a Queue in the background, independent of the
+// deletion.Executor loop that enqueues deliveries, so a slow or failing
+// receiver backs off on its own schedule instead of stalling deletion.
+type Dispatcher struct {
+	queue  Queue
+	client *http.Client
+	subs   map[string]Subscription
+
+	pollInterval time.Duration
+}
+
+// NewDispatcher creates a Dispatcher draining queue and delivering to subs.
+func NewDispatcher(queue Queue, subs ...Subscription) *Dispatcher {
+	byID := make(map[string]Subscription, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+	return &Dispatcher{
+		queue:        queue,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		subs:         byID,
+		pollInterval: time.Second,
+	}
+}
+
+// Notify signs and enqueues event for every subscription registered for it.
+// Enqueuing never blocks on network I/O - delivery happens later, on
+// Dispatcher's own Run loop.
+func (disp *Dispatcher) Notify(event Event, manifestID string, data any) error {
+	payload := Payload{Event: event, ManifestID: manifestID, Time: time.Now(), Data: data}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook: marshal payload: %w", err)
+	}
+
+	for _, sub := range disp.subs {
+		if !sub.wants(event) {
+			continue
+		}
+		d := &Delivery{
+			ID:             fmt.Sprintf("%s-%s-%d", sub.ID, event, time.Now().UnixNano()),
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Body:           body,
+			Signature:      sign(sub.Secret, body),
+			NextAttempt:    time.Now(),
+			CreatedAt:      time.Now(),
+		}
+		if err := disp.queue.Enqueue(d); err != nil {
+			return fmt.Errorf("webhook: enqueue %s for %s: %w", event, sub.ID, err)
+		}
+	}
+	return nil
+}
+
+// Run drains ready deliveries until ctx is done, polling the queue every
+// pollInterval when nothing is ready.
+func (disp *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(disp.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			disp.drainReady(ctx)
+		}
+	}
+}
+
+// drainReady attempts delivery for every delivery the queue reports ready.
+func (disp *Dispatcher) drainReady(ctx context.Context) {
+	for {
+		d, ok := disp.queue.Next(time.Now())
+		if !ok {
+			return
+		}
+		disp.attempt(ctx, d)
+	}
+}
+
+// attempt makes one HTTP POST delivery attempt for d and records the result.
+func (disp *Dispatcher) attempt(ctx context.Context, d *Delivery) {
+	sub, known := disp.subs[d.SubscriptionID]
+	if !known {
+		_ = disp.queue.MarkResult(d, 0, "", fmt.Errorf("webhook: unknown subscription %s", d.SubscriptionID), time.Now())
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		_ = disp.queue.MarkResult(d, 0, "", fmt.Errorf("webhook: build request: %w", err), time.Now())
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Msgvault-Signature", "sha256="+d.Signature)
+
+	resp, err := disp.client.Do(req)
+	if err != nil {
+		_ = disp.queue.MarkResult(d, 0, "", err, time.Now())
+		return
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, responseSnippetLimit))
+	var attemptErr error
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attemptErr = fmt.Errorf("webhook: receiver returned %s", resp.Status)
+	}
+	_ = disp.queue.MarkResult(d, resp.StatusCode, string(snippet), attemptErr, time.Now())
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body using secret as key.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	var mu sync.Mutex
+	var gotBody []byte
+	var gotSig string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSig = r.Header.Get("X-Msgvault-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sub := Subscription{ID: "sub1", URL: server.URL, Secret: "s3cr3t", Events: []Event{EventMessageTrashed}}
+	queue := NewMemoryQueue()
+	disp := NewDispatcher(queue, sub)
+
+	if err := disp.Notify(EventMessageTrashed, "manifest1", map[string]string{"gmail_id": "msg1"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	disp.drainReady(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	var payload Payload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("unmarshal delivered body: %v", err)
+	}
+	if payload.Event != EventMessageTrashed {
+		t.Errorf("Event = %q, want %q", payload.Event, EventMessageTrashed)
+	}
+	if payload.ManifestID != "manifest1" {
+		t.Errorf("ManifestID = %q, want %q", payload.ManifestID, "manifest1")
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(gotBody)
+	wantSig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if gotSig != wantSig {
+		t.Errorf("signature header = %q, want %q", gotSig, wantSig)
+	}
+
+	log := queue.Log("sub1")
+	if len(log) != 1 || log[0].LastStatus != http.StatusOK {
+		t.Errorf("Log = %+v, want one delivered entry with status 200", log)
+	}
+}
+
+func TestDispatcher_SkipsUnsubscribedEvents(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sub := Subscription{ID: "sub1", URL: server.URL, Secret: "x", Events: []Event{EventManifestCompleted}}
+	queue := NewMemoryQueue()
+	disp := NewDispatcher(queue, sub)
+
+	if err := disp.Notify(EventMessageFailed, "manifest1", nil); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	disp.drainReady(context.Background())
+
+	if called {
+		t.Error("receiver was called for an event the subscription did not request")
+	}
+}
+
+func TestDispatcher_RetriesOnFailureThenSucceeds(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sub := Subscription{ID: "sub1", URL: server.URL, Secret: "x", Events: []Event{EventManifestCompleted}}
+	queue := NewMemoryQueue()
+	disp := NewDispatcher(queue, sub)
+
+	if err := disp.Notify(EventManifestCompleted, "manifest1", nil); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// First attempt fails and is rescheduled into the future - draining now
+	// should not deliver it again immediately.
+	disp.drainReady(context.Background())
+	log := queue.Log("sub1")
+	if len(log) != 1 || log[0].LastStatus != http.StatusInternalServerError {
+		t.Fatalf("after first attempt, log = %+v, want one failed entry", log)
+	}
+
+	// Force the retry to be ready by attempting it directly, as Dispatcher's
+	// own Run loop would once the backoff elapses.
+	d, ok := queue.Next(time.Now().Add(24 * time.Hour))
+	if !ok {
+		t.Fatal("expected a rescheduled delivery in the queue")
+	}
+	disp.attempt(context.Background(), d)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	log = queue.Log("sub1")
+	if len(log) != 2 || log[1].LastStatus != http.StatusOK {
+		t.Fatalf("after retry, log = %+v, want second entry delivered", log)
+	}
+}
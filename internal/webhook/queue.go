@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// backoffSchedule is the delay before each retry attempt, indexed by
+// (Attempts-1) - 1m, 5m, 15m, 1h, 6h. A delivery that still fails after the
+// last entry is dropped rather than retried again.
+var backoffSchedule = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	1 * time.Hour,
+	6 * time.Hour,
+}
+
+// maxAttempts is len(backoffSchedule) + 1: the initial attempt plus one
+// retry per backoff entry.
+var maxAttempts = len(backoffSchedule) + 1
+
+// Delivery is one pending or completed webhook delivery: a subscription,
+// an event payload, and the retry/delivery-log state tracked as attempts
+// are made.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	Event          Event
+	Body           []byte // signed payload body, computed once at enqueue time
+	Signature      string // hex HMAC-SHA256 of Body, using the subscription's secret
+
+	Attempts            int
+	NextAttempt         time.Time
+	Dropped             bool
+	LastStatus          int
+	LastResponseSnippet string
+	LastError           string
+	CreatedAt           time.Time
+}
+
+// Queue stores pending deliveries and the delivery log of completed
+// attempts. MemoryQueue is the only implementation in this tree; a
+// persistent, bstore-style queue backed by internal/store.Store would
+// satisfy the same interface once Store exists as a concrete type, so
+// deliveries survive a process restart.
+type Queue interface {
+	// Enqueue adds d as a new pending delivery.
+	Enqueue(d *Delivery) error
+
+	// Next pops the earliest pending delivery whose NextAttempt has passed,
+	// as of now. It returns ok=false if no delivery is ready.
+	Next(now time.Time) (d *Delivery, ok bool)
+
+	// MarkResult records the outcome of attempting d and, if another retry
+	// remains, re-enqueues it at its next backoff delay; otherwise it is
+	// marked Dropped and kept only in the delivery log.
+	MarkResult(d *Delivery, status int, responseSnippet string, attemptErr error, now time.Time) error
+
+	// Log returns every delivery (pending, retried, delivered, or dropped)
+	// recorded for subscriptionID, most recent attempt first.
+	Log(subscriptionID string) []*Delivery
+}
+
+// MemoryQueue is an in-memory Queue, sufficient for a single process; it
+// does not survive a restart.
+type MemoryQueue struct {
+	mu      sync.Mutex
+	pending []*Delivery
+	log     map[string][]*Delivery // subscriptionID -> deliveries, most recent last
+}
+
+// NewMemoryQueue creates an empty MemoryQueue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{log: make(map[string][]*Delivery)}
+}
+
+func (q *MemoryQueue) Enqueue(d *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, d)
+	return nil
+}
+
+func (q *MemoryQueue) Next(now time.Time) (*Delivery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sort.Slice(q.pending, func(i, j int) bool {
+		return q.pending[i].NextAttempt.Before(q.pending[j].NextAttempt)
+	})
+	if len(q.pending) == 0 || q.pending[0].NextAttempt.After(now) {
+		return nil, false
+	}
+	d := q.pending[0]
+	q.pending = q.pending[1:]
+	return d, true
+}
+
+func (q *MemoryQueue) MarkResult(d *Delivery, status int, responseSnippet string, attemptErr error, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	d.Attempts++
+	d.LastStatus = status
+	d.LastResponseSnippet = responseSnippet
+	if attemptErr != nil {
+		d.LastError = attemptErr.Error()
+	} else {
+		d.LastError = ""
+	}
+
+	delivered := attemptErr == nil && status >= 200 && status < 300
+	if !delivered && d.Attempts < maxAttempts {
+		d.NextAttempt = now.Add(backoffSchedule[d.Attempts-1])
+		q.pending = append(q.pending, d)
+	} else if !delivered {
+		d.Dropped = true
+	}
+
+	q.log[d.SubscriptionID] = append(q.log[d.SubscriptionID], d)
+	return nil
+}
+
+func (q *MemoryQueue) Log(subscriptionID string) []*Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*Delivery(nil), q.log[subscriptionID]...)
+}
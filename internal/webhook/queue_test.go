@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue_MarkResult_RetriesWithBackoff(t *testing.T) {
+	q := NewMemoryQueue()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d := &Delivery{ID: "d1", SubscriptionID: "sub1", NextAttempt: now}
+	if err := q.Enqueue(d); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	popped, ok := q.Next(now)
+	if !ok || popped != d {
+		t.Fatalf("Next(now) ok=%v popped=%v, want the enqueued delivery", ok, popped)
+	}
+
+	if err := q.MarkResult(d, 500, "server error", errors.New("boom"), now); err != nil {
+		t.Fatalf("MarkResult: %v", err)
+	}
+	if d.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", d.Attempts)
+	}
+	if d.Dropped {
+		t.Error("Dropped = true after first failure, want false")
+	}
+	wantNext := now.Add(backoffSchedule[0])
+	if !d.NextAttempt.Equal(wantNext) {
+		t.Errorf("NextAttempt = %v, want %v", d.NextAttempt, wantNext)
+	}
+
+	// Not ready yet at exactly `now` - the 1-minute backoff hasn't elapsed.
+	if _, ok := q.Next(now); ok {
+		t.Error("Next(now) returned the delivery before its backoff elapsed")
+	}
+	if _, ok := q.Next(wantNext); !ok {
+		t.Error("Next(wantNext) did not return the delivery once its backoff elapsed")
+	}
+}
+
+func TestMemoryQueue_MarkResult_DropsAfterMaxAttempts(t *testing.T) {
+	q := NewMemoryQueue()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &Delivery{ID: "d1", SubscriptionID: "sub1", NextAttempt: now}
+
+	for i := 0; i < maxAttempts; i++ {
+		if err := q.MarkResult(d, 500, "", errors.New("boom"), now); err != nil {
+			t.Fatalf("MarkResult attempt %d: %v", i, err)
+		}
+		now = d.NextAttempt
+	}
+
+	if !d.Dropped {
+		t.Errorf("Dropped = false after %d attempts, want true", maxAttempts)
+	}
+	if d.Attempts != maxAttempts {
+		t.Errorf("Attempts = %d, want %d", d.Attempts, maxAttempts)
+	}
+
+	log := q.Log("sub1")
+	if len(log) != maxAttempts {
+		t.Errorf("Log has %d entries, want %d", len(log), maxAttempts)
+	}
+}
+
+func TestMemoryQueue_MarkResult_SuccessStopsRetrying(t *testing.T) {
+	q := NewMemoryQueue()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &Delivery{ID: "d1", SubscriptionID: "sub1", NextAttempt: now}
+
+	if err := q.MarkResult(d, 200, "ok", nil, now); err != nil {
+		t.Fatalf("MarkResult: %v", err)
+	}
+	if d.Dropped {
+		t.Error("Dropped = true after a successful delivery, want false")
+	}
+	if _, ok := q.Next(now.Add(24 * time.Hour)); ok {
+		t.Error("Next returned a delivery that already succeeded")
+	}
+}
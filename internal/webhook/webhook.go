@@ -0,0 +1,51 @@
+// Package webhook delivers signed JSON notifications for deletion
+// lifecycle events to subscriber-configured URLs, with retry backoff and a
+// delivery log independent of the deletion executor loop - modeled on how
+// mox's queue emits per-delivery webhook events, so a slow or flaky
+// receiver stalls its own deliveries rather than the deletion it reports.
+package webhook
+
+import (
+	"time"
+)
+
+// Event identifies a deletion lifecycle event a Subscription can receive.
+type Event string
+
+const (
+	EventManifestStarted   Event = "manifest.started"
+	EventMessageTrashed    Event = "message.trashed"
+	EventMessageDeleted    Event = "message.deleted"
+	EventMessageFailed     Event = "message.failed"
+	EventBatchCompleted    Event = "batch.completed"
+	EventManifestCompleted Event = "manifest.completed"
+)
+
+// Subscription is a receiver registered for one or more Events, stored
+// alongside the manifests whose events it should receive. Secret signs
+// every delivery's body via HMAC-SHA256 so the receiver can verify it
+// actually came from this subscription.
+type Subscription struct {
+	ID     string
+	URL    string
+	Secret string
+	Events []Event
+}
+
+// wants reports whether sub is registered for event.
+func (sub Subscription) wants(event Event) bool {
+	for _, e := range sub.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body POSTed for every delivery.
+type Payload struct {
+	Event      Event     `json:"event"`
+	ManifestID string    `json:"manifest_id"`
+	Time       time.Time `json:"time"`
+	Data       any       `json:"data,omitempty"`
+}
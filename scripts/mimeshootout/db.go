@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// PythonMessage is one message's metadata as Python's ingestion pipeline
+// originally extracted and stored it — the baseline every parser backend
+// is compared against.
+type PythonMessage struct {
+	ID          int64
+	SourceMsgID string
+	Subject     sql.NullString
+	BodyText    sql.NullString
+	SentAt      sql.NullString
+
+	// Participants extracted separately
+	FromAddresses []Address
+	ToAddresses   []Address
+	CcAddresses   []Address
+	BccAddresses  []Address
+
+	// Attachment count
+	AttachmentCount int
+}
+
+// Address is a display-name/email pair, independent of which parser (or
+// Python) produced it.
+type Address struct {
+	Name  string
+	Email string
+}
+
+func loadMessages(db *sql.DB, limit int) ([]PythonMessage, error) {
+	query := `
+		SELECT
+			m.id,
+			m.source_message_id,
+			m.subject,
+			m.body_text,
+			m.sent_at,
+			(SELECT COUNT(*) FROM attachments a WHERE a.message_id = m.id) as attachment_count
+		FROM messages m
+		WHERE EXISTS (SELECT 1 FROM message_raw mr WHERE mr.message_id = m.id)
+		ORDER BY m.id
+		LIMIT ?
+	`
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []PythonMessage
+	for rows.Next() {
+		var msg PythonMessage
+		if err := rows.Scan(&msg.ID, &msg.SourceMsgID, &msg.Subject, &msg.BodyText, &msg.SentAt, &msg.AttachmentCount); err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+
+		// Load participants
+		msg.FromAddresses, _ = loadParticipants(db, msg.ID, "from")
+		msg.ToAddresses, _ = loadParticipants(db, msg.ID, "to")
+		msg.CcAddresses, _ = loadParticipants(db, msg.ID, "cc")
+		msg.BccAddresses, _ = loadParticipants(db, msg.ID, "bcc")
+
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+func loadParticipants(db *sql.DB, messageID int64, recipientType string) ([]Address, error) {
+	// All recipient types (from, to, cc, bcc) are stored in message_recipients
+	query := `
+		SELECT COALESCE(p.display_name, ''), COALESCE(p.email_address, '')
+		FROM message_recipients mr
+		JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = ? AND mr.recipient_type = ?
+	`
+
+	rows, err := db.Query(query, messageID, recipientType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []Address
+	for rows.Next() {
+		var addr Address
+		if err := rows.Scan(&addr.Name, &addr.Email); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+
+	return addresses, rows.Err()
+}
+
+func loadRawMime(db *sql.DB, messageID int64) ([]byte, error) {
+	var compressed []byte
+	var compression sql.NullString
+
+	err := db.QueryRow(
+		"SELECT raw_data, compression FROM message_raw WHERE message_id = ?",
+		messageID,
+	).Scan(&compressed, &compression)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decompress if needed
+	if compression.Valid && compression.String == "zlib" {
+		r, err := zlib.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("zlib reader: %w", err)
+		}
+		defer r.Close()
+
+		return io.ReadAll(r)
+	}
+
+	return compressed, nil
+}
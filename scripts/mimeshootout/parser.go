@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/emersion/go-message/mail"
+	"github.com/jhillyerd/enmime"
+)
+
+// ParseResult is one parser backend's extraction from a single raw MIME
+// message, in the shape every backend is compared in.
+type ParseResult struct {
+	Subject     string
+	BodyText    string
+	From        []Address
+	To          []Address
+	Cc          []Address
+	Bcc         []Address
+	Attachments int
+	Error       error
+}
+
+// Parser is a MIME parsing backend the shootout can run and compare
+// against the others.
+type Parser interface {
+	// Parse extracts ParseResult's fields from raw MIME bytes.
+	Parse(raw []byte) ParseResult
+	// Name identifies this backend in reports and the -parser flag.
+	Name() string
+}
+
+type enmimeParser struct{}
+
+func (enmimeParser) Name() string { return "enmime" }
+
+func (enmimeParser) Parse(raw []byte) ParseResult {
+	env, err := enmime.ReadEnvelope(bytes.NewReader(raw))
+	if err != nil {
+		return ParseResult{Error: err}
+	}
+
+	result := ParseResult{
+		Subject:     env.GetHeader("Subject"),
+		BodyText:    env.Text,
+		Attachments: len(env.Attachments),
+	}
+
+	result.From = parseEnmimeAddressList(env, "From")
+	result.To = parseEnmimeAddressList(env, "To")
+	result.Cc = parseEnmimeAddressList(env, "Cc")
+	result.Bcc = parseEnmimeAddressList(env, "Bcc")
+
+	return result
+}
+
+func parseEnmimeAddressList(env *enmime.Envelope, header string) []Address {
+	var addresses []Address
+	list, err := env.AddressList(header)
+	if err != nil {
+		return addresses
+	}
+	for _, addr := range list {
+		addresses = append(addresses, Address{
+			Name:  addr.Name,
+			Email: addr.Address,
+		})
+	}
+	return addresses
+}
+
+// goMessageParser parses with github.com/emersion/go-message/mail,
+// walking parts manually (there's no enmime-style all-in-one Envelope)
+// and reading addresses off mail.Header.
+type goMessageParser struct{}
+
+func (goMessageParser) Name() string { return "go-message" }
+
+func (goMessageParser) Parse(raw []byte) ParseResult {
+	mr, err := mail.CreateReader(bytes.NewReader(raw))
+	if err != nil {
+		return ParseResult{Error: err}
+	}
+
+	result := ParseResult{
+		Subject: headerSubject(mr.Header),
+		From:    mailAddressList(mr.Header, "From"),
+		To:      mailAddressList(mr.Header, "To"),
+		Cc:      mailAddressList(mr.Header, "Cc"),
+		Bcc:     mailAddressList(mr.Header, "Bcc"),
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			break
+		}
+
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			ct, _, _ := h.ContentType()
+			if ct == "text/plain" && result.BodyText == "" {
+				body, err := io.ReadAll(part.Body)
+				if err == nil {
+					result.BodyText = string(body)
+				}
+			}
+		case *mail.AttachmentHeader:
+			result.Attachments++
+		}
+	}
+
+	return result
+}
+
+func headerSubject(h mail.Header) string {
+	s, err := h.Subject()
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+func mailAddressList(h mail.Header, field string) []Address {
+	list, err := h.AddressList(field)
+	if err != nil {
+		return nil
+	}
+	var addresses []Address
+	for _, addr := range list {
+		addresses = append(addresses, Address{Name: addr.Name, Email: addr.Address})
+	}
+	return addresses
+}
+
+// pythonEmailParserScript parses a raw MIME message on stdin with
+// Python's own email.parser (the same library the original ingestion
+// pipeline used) and prints its extraction as JSON, so the Go shootout
+// can compare a third, independent implementation without reimplementing
+// MIME parsing in two languages.
+const pythonEmailParserScript = `
+import json, sys
+from email import policy
+from email.parser import BytesParser
+
+msg = BytesParser(policy=policy.default).parsebytes(sys.stdin.buffer.read())
+
+def addr_list(field):
+	out = []
+	header = msg.get(field)
+	if header is None:
+		return out
+	addresses = getattr(header, "addresses", None)
+	if addresses is None:
+		return out
+	for a in addresses:
+		out.append({"name": a.display_name or "", "email": a.addr_spec or ""})
+	return out
+
+body = ""
+if msg.is_multipart():
+	for part in msg.walk():
+		if part.get_content_type() == "text/plain" and not part.get_filename():
+			try:
+				body = part.get_content()
+			except Exception:
+				pass
+			break
+else:
+	try:
+		body = msg.get_content()
+	except Exception:
+		pass
+
+attachments = sum(1 for part in msg.walk() if part.get_filename())
+
+print(json.dumps({
+	"subject": str(msg.get("Subject", "") or ""),
+	"body_text": body if isinstance(body, str) else "",
+	"from": addr_list("From"),
+	"to": addr_list("To"),
+	"cc": addr_list("Cc"),
+	"bcc": addr_list("Bcc"),
+	"attachments": attachments,
+}))
+`
+
+type pythonEmailParser struct{}
+
+func (pythonEmailParser) Name() string { return "python" }
+
+type pythonAddress struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type pythonParseOutput struct {
+	Subject     string          `json:"subject"`
+	BodyText    string          `json:"body_text"`
+	From        []pythonAddress `json:"from"`
+	To          []pythonAddress `json:"to"`
+	Cc          []pythonAddress `json:"cc"`
+	Bcc         []pythonAddress `json:"bcc"`
+	Attachments int             `json:"attachments"`
+}
+
+func (pythonEmailParser) Parse(raw []byte) ParseResult {
+	cmd := exec.Command("python3", "-c", pythonEmailParserScript)
+	cmd.Stdin = bytes.NewReader(raw)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return ParseResult{Error: fmt.Errorf("python3: %w: %s", err, stderr.String())}
+	}
+
+	var out pythonParseOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return ParseResult{Error: fmt.Errorf("decode python output: %w", err)}
+	}
+
+	return ParseResult{
+		Subject:     out.Subject,
+		BodyText:    out.BodyText,
+		From:        pythonAddresses(out.From),
+		To:          pythonAddresses(out.To),
+		Cc:          pythonAddresses(out.Cc),
+		Bcc:         pythonAddresses(out.Bcc),
+		Attachments: out.Attachments,
+	}
+}
+
+func pythonAddresses(addrs []pythonAddress) []Address {
+	var out []Address
+	for _, a := range addrs {
+		out = append(out, Address{Name: a.Name, Email: a.Email})
+	}
+	return out
+}
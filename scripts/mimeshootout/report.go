@@ -0,0 +1,443 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wesm/msgvault/internal/authcheck"
+)
+
+type MismatchType string
+
+const (
+	MismatchSubject      MismatchType = "subject"
+	MismatchSubjectNear  MismatchType = "subject_near"
+	MismatchFrom         MismatchType = "from"
+	MismatchFromNear     MismatchType = "from_near"
+	MismatchAttachments  MismatchType = "attachments"
+	MismatchBody         MismatchType = "body"
+	MismatchPythonNoFrom MismatchType = "python_no_from"
+	MismatchEnmimeNoFrom MismatchType = "enmime_no_from"
+)
+
+var allMismatchTypes = []MismatchType{
+	MismatchSubject, MismatchSubjectNear, MismatchPythonNoFrom, MismatchEnmimeNoFrom,
+	MismatchFrom, MismatchFromNear, MismatchAttachments, MismatchBody,
+}
+
+type MismatchExample struct {
+	MessageID   int64
+	SourceMsgID string
+	Type        MismatchType
+	PythonValue string
+	EnmimeValue string
+	// Distance is the Levenshtein distance between PythonValue and
+	// EnmimeValue (after normalization), set only for the *Near types.
+	Distance int
+}
+
+// outcome is the per-message result a parser produced, kept around after
+// the per-parser report is printed so printMatrix can compare backends
+// against each other without re-parsing.
+type outcome struct {
+	parseErr  bool
+	subjectOK bool
+	fromOK    bool
+}
+
+// ParserStats accumulates one parser backend's run over every message:
+// its own match rates against the Python baseline, plus enough detail to
+// compare it against other backends afterward.
+type ParserStats struct {
+	Parser Parser
+
+	Total, ParseErrors, PerfectMatches int
+	SubjectMatches                     int
+	FromMatches                        int
+	AttachmentMatches                  int
+	BodyMatches                        int
+
+	DKIMPass, ARCValid, DomainAligned int
+	SPFResults                        map[string]int
+
+	MismatchCounts   map[MismatchType]int
+	MismatchExamples map[MismatchType][]MismatchExample
+
+	Outcomes map[int64]outcome
+}
+
+// runParser parses every message with p and compares it against the
+// Python baseline stored alongside it, the same comparison the shootout
+// has always done, now backend-agnostic.
+func runParser(db *sql.DB, messages []PythonMessage, p Parser, similarityThreshold float64, showExamples int) *ParserStats {
+	stats := &ParserStats{
+		Parser:           p,
+		SPFResults:       make(map[string]int),
+		MismatchCounts:   make(map[MismatchType]int),
+		MismatchExamples: make(map[MismatchType][]MismatchExample),
+		Outcomes:         make(map[int64]outcome),
+	}
+
+	for _, msg := range messages {
+		stats.Total++
+
+		rawMime, err := loadRawMime(db, msg.ID)
+		if err != nil {
+			continue
+		}
+
+		result := p.Parse(rawMime)
+		if result.Error != nil {
+			stats.ParseErrors++
+			stats.Outcomes[msg.ID] = outcome{parseErr: true}
+			continue
+		}
+
+		var mismatches []MismatchType
+		out := outcome{}
+
+		// Subject
+		normPySubj := normalizeSubject(msg.Subject.String)
+		normEnSubj := normalizeSubject(result.Subject)
+		if normPySubj == normEnSubj {
+			stats.SubjectMatches++
+			out.subjectOK = true
+		} else if dist, sim := similarity(normPySubj, normEnSubj); sim >= similarityThreshold {
+			mismatches = append(mismatches, MismatchSubjectNear)
+			addNearExample(stats.MismatchExamples, MismatchSubjectNear, msg, msg.Subject.String, result.Subject, dist, showExamples)
+		} else {
+			mismatches = append(mismatches, MismatchSubject)
+			addExample(stats.MismatchExamples, MismatchSubject, msg, msg.Subject.String, result.Subject, showExamples)
+		}
+
+		// From address
+		pythonFrom := ""
+		enmimeFrom := ""
+		if len(msg.FromAddresses) > 0 {
+			pythonFrom = msg.FromAddresses[0].Email
+		}
+		if len(result.From) > 0 {
+			enmimeFrom = result.From[0].Email
+		}
+
+		normPyFrom := normalizeEmail(pythonFrom)
+		normEnFrom := normalizeEmail(enmimeFrom)
+		if normPyFrom == normEnFrom {
+			stats.FromMatches++
+			out.fromOK = true
+		} else if pythonFrom == "" && enmimeFrom != "" {
+			mismatches = append(mismatches, MismatchPythonNoFrom)
+			addExample(stats.MismatchExamples, MismatchPythonNoFrom, msg,
+				fmt.Sprintf("(empty) python_from_count=%d", len(msg.FromAddresses)),
+				fmt.Sprintf("%s <%s>", result.From[0].Name, result.From[0].Email),
+				showExamples)
+		} else if pythonFrom != "" && enmimeFrom == "" {
+			mismatches = append(mismatches, MismatchEnmimeNoFrom)
+			addExample(stats.MismatchExamples, MismatchEnmimeNoFrom, msg, pythonFrom, "(empty)", showExamples)
+		} else if dist, sim := similarity(normPyFrom, normEnFrom); sim >= similarityThreshold {
+			mismatches = append(mismatches, MismatchFromNear)
+			addNearExample(stats.MismatchExamples, MismatchFromNear, msg, pythonFrom, enmimeFrom, dist, showExamples)
+		} else {
+			mismatches = append(mismatches, MismatchFrom)
+			addExample(stats.MismatchExamples, MismatchFrom, msg, pythonFrom, enmimeFrom, showExamples)
+		}
+
+		// Attachments
+		if msg.AttachmentCount == result.Attachments {
+			stats.AttachmentMatches++
+		} else {
+			mismatches = append(mismatches, MismatchAttachments)
+			addExample(stats.MismatchExamples, MismatchAttachments, msg,
+				fmt.Sprintf("%d", msg.AttachmentCount),
+				fmt.Sprintf("%d", result.Attachments),
+				showExamples)
+		}
+
+		// Body (presence check)
+		pythonHasBody := len(strings.TrimSpace(msg.BodyText.String)) > 0
+		enmimeHasBody := len(strings.TrimSpace(result.BodyText)) > 0
+		if pythonHasBody == enmimeHasBody {
+			stats.BodyMatches++
+		} else {
+			mismatches = append(mismatches, MismatchBody)
+			addExample(stats.MismatchExamples, MismatchBody, msg,
+				fmt.Sprintf("len=%d has=%v", len(msg.BodyText.String), pythonHasBody),
+				fmt.Sprintf("len=%d has=%v", len(result.BodyText), enmimeHasBody),
+				showExamples)
+		}
+
+		// Authentication (DKIM re-verification + Authentication-Results);
+		// not compared against a Python baseline, just tallied.
+		auth := authcheck.Verify(rawMime, domainOf(enmimeFrom))
+		if auth.DKIMPass {
+			stats.DKIMPass++
+		}
+		if auth.ARCChainValid {
+			stats.ARCValid++
+		}
+		if auth.FromDomainAligned {
+			stats.DomainAligned++
+		}
+		if auth.SPFResult != "" {
+			stats.SPFResults[auth.SPFResult]++
+		}
+
+		for _, mt := range mismatches {
+			stats.MismatchCounts[mt]++
+		}
+		if len(mismatches) == 0 {
+			stats.PerfectMatches++
+		}
+
+		stats.Outcomes[msg.ID] = out
+	}
+
+	return stats
+}
+
+func printSummary(stats *ParserStats, showExamples int) {
+	checked := stats.Total - stats.ParseErrors
+
+	fmt.Printf("\n=== %s ANALYSIS (%d messages) ===\n\n", strings.ToUpper(stats.Parser.Name()), stats.Total)
+
+	fmt.Printf("Parse errors: %d (%.2f%%)\n", stats.ParseErrors, pct(stats.ParseErrors, stats.Total))
+	fmt.Printf("Perfect matches: %d (%.2f%%)\n", stats.PerfectMatches, pct(stats.PerfectMatches, stats.Total))
+
+	fmt.Printf("\n--- Field-Level Match Rates ---\n")
+	fmt.Printf("Subject:     %d / %d (%.2f%%)\n", stats.SubjectMatches, checked, pct(stats.SubjectMatches, checked))
+	fmt.Printf("From:        %d / %d (%.2f%%)\n", stats.FromMatches, checked, pct(stats.FromMatches, checked))
+	fmt.Printf("Attachments: %d / %d (%.2f%%)\n", stats.AttachmentMatches, checked, pct(stats.AttachmentMatches, checked))
+	fmt.Printf("Body:        %d / %d (%.2f%%)\n", stats.BodyMatches, checked, pct(stats.BodyMatches, checked))
+
+	fmt.Printf("\n--- Authentication (DKIM/ARC/SPF) ---\n")
+	fmt.Printf("DKIM pass:       %d / %d (%.2f%%)\n", stats.DKIMPass, checked, pct(stats.DKIMPass, checked))
+	fmt.Printf("ARC chain valid: %d / %d (%.2f%%)\n", stats.ARCValid, checked, pct(stats.ARCValid, checked))
+	fmt.Printf("From aligned:    %d / %d (%.2f%%)\n", stats.DomainAligned, checked, pct(stats.DomainAligned, checked))
+	for result, count := range stats.SPFResults {
+		fmt.Printf("SPF %-10s: %d (%.2f%%)\n", result, count, pct(count, checked))
+	}
+
+	fmt.Printf("\n--- Mismatch Breakdown ---\n")
+	for _, mt := range allMismatchTypes {
+		if count := stats.MismatchCounts[mt]; count > 0 {
+			fmt.Printf("%-20s: %d (%.2f%%)\n", mt, count, pct(count, checked))
+		}
+	}
+
+	fmt.Printf("\n--- Examples of Each Mismatch Type ---\n")
+	for _, mt := range allMismatchTypes {
+		examples := stats.MismatchExamples[mt]
+		if len(examples) == 0 {
+			continue
+		}
+		fmt.Printf("\n[%s] (%d total)\n", mt, stats.MismatchCounts[mt])
+		for i, ex := range examples {
+			if i >= showExamples {
+				break
+			}
+			fmt.Printf("  msg_id=%d source=%s\n", ex.MessageID, ex.SourceMsgID)
+			fmt.Printf("    Python: %s\n", truncate(ex.PythonValue, 100))
+			fmt.Printf("    Parsed: %s\n", truncate(ex.EnmimeValue, 100))
+			if mt == MismatchSubjectNear || mt == MismatchFromNear {
+				fmt.Printf("    Distance: %d\n", ex.Distance)
+			}
+		}
+	}
+}
+
+// printMatrix reports, across every parser backend that ran, which
+// messages every backend agreed on, which ones they disagreed on, and
+// each backend's exclusive failures (mismatched where every other
+// backend matched) — the comparison the shootout's "Library Shootout"
+// name has always implied but needed more than one live backend to do.
+func printMatrix(allStats []*ParserStats, messages []PythonMessage) {
+	fmt.Printf("\n=== CROSS-PARSER MATRIX (%d backends) ===\n\n", len(allStats))
+
+	agree, disagree := 0, 0
+	exclusiveFailures := make(map[string]int)
+
+	for _, msg := range messages {
+		oks := make([]bool, len(allStats))
+		anyErr := false
+		for i, stats := range allStats {
+			out, seen := stats.Outcomes[msg.ID]
+			if !seen || out.parseErr {
+				anyErr = true
+				oks[i] = false
+				continue
+			}
+			oks[i] = out.subjectOK && out.fromOK
+		}
+		if anyErr {
+			continue
+		}
+
+		allOK, allFail := true, true
+		for _, ok := range oks {
+			if ok {
+				allFail = false
+			} else {
+				allOK = false
+			}
+		}
+		switch {
+		case allOK || allFail:
+			agree++
+		default:
+			disagree++
+			for i, ok := range oks {
+				if !ok {
+					exclusiveFailures[allStats[i].Parser.Name()]++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("Messages where all backends agree:    %d\n", agree)
+	fmt.Printf("Messages where backends disagree:     %d\n", disagree)
+	fmt.Printf("\n--- Failures on Disagreement Messages (by backend) ---\n")
+	for _, stats := range allStats {
+		fmt.Printf("%-12s: %d\n", stats.Parser.Name(), exclusiveFailures[stats.Parser.Name()])
+	}
+}
+
+func addExample(examples map[MismatchType][]MismatchExample, mt MismatchType, msg PythonMessage, pythonVal, enmimeVal string, maxExamples int) {
+	if len(examples[mt]) < maxExamples {
+		examples[mt] = append(examples[mt], MismatchExample{
+			MessageID:   msg.ID,
+			SourceMsgID: msg.SourceMsgID,
+			Type:        mt,
+			PythonValue: pythonVal,
+			EnmimeValue: enmimeVal,
+		})
+	}
+}
+
+// addNearExample is addExample plus the computed edit distance, for the
+// *Near mismatch types.
+func addNearExample(examples map[MismatchType][]MismatchExample, mt MismatchType, msg PythonMessage, pythonVal, enmimeVal string, distance int, maxExamples int) {
+	if len(examples[mt]) < maxExamples {
+		examples[mt] = append(examples[mt], MismatchExample{
+			MessageID:   msg.ID,
+			SourceMsgID: msg.SourceMsgID,
+			Type:        mt,
+			PythonValue: pythonVal,
+			EnmimeValue: enmimeVal,
+			Distance:    distance,
+		})
+	}
+}
+
+func normalizeEmail(s string) string {
+	return strings.TrimSpace(strings.ToLower(s))
+}
+
+// domainOf returns the domain portion of an email address, or "" if it
+// has none.
+func domainOf(email string) string {
+	if i := strings.LastIndexByte(email, '@'); i >= 0 {
+		return email[i+1:]
+	}
+	return ""
+}
+
+// replyForwardPrefix matches one leading "Re:", "Fwd:", or "Fw:" token,
+// case-insensitively, with or without a trailing colon/space.
+var replyForwardPrefix = regexp.MustCompile(`(?i)^(re|fwd?)\s*:\s*`)
+
+// normalizeSubject lowercases s, collapses runs of whitespace to a single
+// space, and strips every leading Re:/Fwd:/Fw: token so "Fwd: Re: hello"
+// and "Re: Fwd: hello" both normalize to "hello" instead of registering
+// as a full mismatch.
+func normalizeSubject(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Join(strings.Fields(s), " ")
+	for {
+		stripped := replyForwardPrefix.ReplaceAllString(s, "")
+		if stripped == s {
+			break
+		}
+		s = stripped
+	}
+	return s
+}
+
+// similarity returns the Levenshtein distance between a and b and a
+// length-normalized similarity ratio in [0, 1], where 1 means identical.
+func similarity(a, b string) (distance int, ratio float64) {
+	distance = levenshtein(a, b)
+	maxLen := len([]rune(a))
+	if bl := len([]rune(b)); bl > maxLen {
+		maxLen = bl
+	}
+	if maxLen == 0 {
+		return 0, 1
+	}
+	return distance, 1 - float64(distance)/float64(maxLen)
+}
+
+// levenshtein computes the classic edit distance between a and b (single
+// character insert/delete/substitute), operating on runes so multi-byte
+// UTF-8 subjects aren't over-counted. Messages' subjects and addresses are
+// short enough that the plain O(len(a)*len(b)) DP is fine without a
+// bounded early-exit.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 {
+		return len(br)
+	}
+	if len(br) == 0 {
+		return len(ar)
+	}
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			curr[j] = min3(del, ins, sub)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
+
+func truncate(s string, maxLen int) string {
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	s = strings.ReplaceAll(s, "\r", "\\r")
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
+
+func pct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
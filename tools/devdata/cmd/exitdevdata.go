@@ -5,9 +5,12 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/wesm/msgvault/internal/fileutil"
 	"github.com/wesm/msgvault/tools/devdata/dataset"
 )
 
+var shredOnExit bool
+
 var exitDevDataCmd = &cobra.Command{
 	Use:   "exit-dev-data",
 	Short: "Exit dev mode and restore ~/.msgvault from ~/.msgvault-gold",
@@ -16,6 +19,8 @@ var exitDevDataCmd = &cobra.Command{
 }
 
 func init() {
+	exitDevDataCmd.Flags().BoolVar(&shredOnExit, "shred", false,
+		"securely overwrite (see fileutil.SecureRemoveAll) the symlink's former dev-data dataset instead of leaving it on disk; matters when it holds real message bodies copied via new-data")
 	rootCmd.AddCommand(exitDevDataCmd)
 }
 
@@ -52,6 +57,14 @@ func runExitDevData(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%s is no longer a symlink; aborting to prevent accidental data deletion", path)
 	}
 
+	// The symlink's target is the dev dataset exit-dev-data is leaving
+	// behind; read it before the symlink is removed so --shred has
+	// somewhere to clean up afterward.
+	devDataDir, err := os.Readlink(path)
+	if err != nil {
+		return fmt.Errorf("readlink %s: %w", path, err)
+	}
+
 	// Remove symlink
 	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("remove symlink %s: %w", path, err)
@@ -64,6 +77,13 @@ func runExitDevData(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("rename %s to %s: %w", goldPath, path, err)
 	}
 
+	if shredOnExit && dataset.Exists(devDataDir) {
+		if err := fileutil.SecureRemoveAll(devDataDir); err != nil {
+			return fmt.Errorf("shred former dev dataset %s: %w", devDataDir, err)
+		}
+		fmt.Fprintf(os.Stderr, "devdata: shredded former dev dataset %s\n", devDataDir)
+	}
+
 	fmt.Fprintf(os.Stderr, "devdata: exited dev mode: %s restored\n", path)
 	return nil
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wesm/msgvault/tools/devdata/dataset"
+)
+
+var (
+	newDataName         string
+	newDataSrc          string
+	newDataMaxMessages  int
+	newDataSince        string
+	newDataUntil        string
+	newDataLabels       string
+	newDataParticipants string
+	newDataIncludeRaw   bool
+	newDataIncludeAttch bool
+	newDataScrubKey     string
+	newDataSrcBlobDir   string
+)
+
+var newDataCmd = &cobra.Command{
+	Use:   "new-data",
+	Short: "Create a ~/.msgvault-<name> subset dataset from an existing database",
+	Long: "Copies a filtered subset of messages (and everything they reference) from " +
+		"--src into ~/.msgvault-<name>, so developers can work with an expendable, " +
+		"right-sized dataset instead of the full vault.",
+	RunE: runNewData,
+}
+
+func init() {
+	newDataCmd.Flags().StringVar(&newDataName, "name", "", "dataset name (creates ~/.msgvault-<name>)")
+	newDataCmd.Flags().StringVar(&newDataSrc, "src", "", "path to the source msgvault.db")
+	newDataCmd.Flags().IntVar(&newDataMaxMessages, "max-messages", 0, "cap on messages copied, most recent first (0 = unlimited)")
+	newDataCmd.Flags().StringVar(&newDataSince, "since", "", "only messages sent at or after this time (RFC 3339)")
+	newDataCmd.Flags().StringVar(&newDataUntil, "until", "", "only messages sent at or before this time (RFC 3339)")
+	newDataCmd.Flags().StringVar(&newDataLabels, "labels", "", "comma-separated label names to keep")
+	newDataCmd.Flags().StringVar(&newDataParticipants, "participants", "", "comma-separated email addresses to keep (sender or recipient)")
+	newDataCmd.Flags().BoolVar(&newDataIncludeRaw, "include-raw", false, "copy original raw MIME blobs (increases dataset size)")
+	newDataCmd.Flags().BoolVar(&newDataIncludeAttch, "include-attachments", false, "copy attachment metadata")
+	newDataCmd.Flags().StringVar(&newDataScrubKey, "scrub", "", "HMAC key to scrub participant email addresses with (omit to keep real addresses)")
+	newDataCmd.Flags().StringVar(&newDataSrcBlobDir, "src-blob-dir", "", "directory --src's on-disk message blobs live under (see store.WithBlobStorage); copies each selected message's blob alongside its row")
+	_ = newDataCmd.MarkFlagRequired("name")
+	_ = newDataCmd.MarkFlagRequired("src")
+
+	rootCmd.AddCommand(newDataCmd)
+}
+
+func runNewData(cmd *cobra.Command, args []string) error {
+	dstDir := datasetPath(newDataName)
+	if dataset.Exists(dstDir) {
+		return fmt.Errorf("%s already exists", dstDir)
+	}
+
+	opts := dataset.CopySubsetOpts{
+		MaxMessages:        newDataMaxMessages,
+		IncludeRaw:         newDataIncludeRaw,
+		IncludeAttachments: newDataIncludeAttch,
+	}
+
+	if newDataSince != "" {
+		since, err := time.Parse(time.RFC3339, newDataSince)
+		if err != nil {
+			return fmt.Errorf("parse --since: %w", err)
+		}
+		opts.Since = since
+	}
+	if newDataUntil != "" {
+		until, err := time.Parse(time.RFC3339, newDataUntil)
+		if err != nil {
+			return fmt.Errorf("parse --until: %w", err)
+		}
+		opts.Until = until
+	}
+	if newDataLabels != "" {
+		opts.Labels = splitCSV(newDataLabels)
+	}
+	if newDataParticipants != "" {
+		opts.Participants = splitCSV(newDataParticipants)
+	}
+	if newDataScrubKey != "" {
+		opts.ScrubKey = []byte(newDataScrubKey)
+	}
+	if newDataSrcBlobDir != "" {
+		abs, err := filepath.Abs(newDataSrcBlobDir)
+		if err != nil {
+			return fmt.Errorf("resolve --src-blob-dir: %w", err)
+		}
+		opts.SrcBlobDir = abs
+	}
+
+	opts.Progress = func(phase string, done, total int64) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "devdata: %s: %d/%d\n", phase, done, total)
+	}
+
+	// Ctrl-C stops at the next batch boundary instead of killing the
+	// process outright, leaving a partial dataset that a re-run with the
+	// same flags resumes from.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := dataset.CopySubsetWithOpts(ctx, newDataSrc, dstDir, opts)
+	if err != nil {
+		return fmt.Errorf("create dataset %s: %w", newDataName, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "devdata: created %s: %d messages, %d conversations, %d participants, %d labels (%s, %s)\n",
+		dstDir, result.Messages, result.Conversations, result.Participants, result.Labels, formatSize(result.DBSize), result.Elapsed)
+	return nil
+}
+
+// splitCSV splits a comma-separated flag value into trimmed, non-empty fields.
+func splitCSV(s string) []string {
+	var out []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// formatSize renders a byte count in the largest whole unit that keeps it
+// readable, matching the other devdata commands' plain-text output style.
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+
+	"github.com/wesm/msgvault/internal/query"
+	"github.com/wesm/msgvault/internal/search"
+)
+
+var savedDBPath string
+
+var savedCmd = &cobra.Command{
+	Use:   "saved",
+	Short: "Manage saved searches (Gmail-style search folders) on a dataset",
+}
+
+var savedAddCmd = &cobra.Command{
+	Use:   "add <name> <query>",
+	Short: "Save a named query, creating or overwriting it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := sql.Open("sqlite3", savedDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer db.Close()
+
+		query.NewSavedQueries(db) // wires search.SaveNamed against --db
+		if err := search.SaveNamed(args[0], args[1]); err != nil {
+			return fmt.Errorf("save %q: %w", args[0], err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "devdata: saved %q as %q\n", args[1], args[0])
+		return nil
+	},
+}
+
+var savedListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved queries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := sql.Open("sqlite3", savedDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer db.Close()
+
+		saved, err := query.NewSavedQueries(db).List(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("list saved queries: %w", err)
+		}
+		for _, s := range saved {
+			fmt.Fprintf(cmd.OutOrStdout(), "%-30s %s\n", s.Name, s.QueryString)
+		}
+		return nil
+	},
+}
+
+var savedRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Delete a saved query",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := sql.Open("sqlite3", savedDBPath)
+		if err != nil {
+			return fmt.Errorf("open sqlite db: %w", err)
+		}
+		defer db.Close()
+
+		if err := query.NewSavedQueries(db).Delete(cmd.Context(), args[0]); err != nil {
+			return fmt.Errorf("delete saved query: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "devdata: deleted %q\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	savedCmd.PersistentFlags().StringVar(&savedDBPath, "db", "", "path to the dataset's msgvault.db")
+	_ = savedCmd.MarkPersistentFlagRequired("db")
+	savedCmd.AddCommand(savedAddCmd, savedListCmd, savedRmCmd)
+	rootCmd.AddCommand(savedCmd)
+}
@@ -1,9 +1,12 @@
 package dataset
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +14,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 
+	"github.com/wesm/msgvault/internal/fileutil"
 	"github.com/wesm/msgvault/internal/store"
 )
 
@@ -24,85 +28,164 @@ type CopyResult struct {
 	Elapsed       time.Duration
 }
 
+// ProgressFunc receives progress updates as CopySubsetWithOpts runs. phase
+// names the step underway ("messages" for the batched row copy, "fts" for
+// index population), done is how many units of that phase have completed so
+// far (across resumed runs, not just this process), and total is the
+// phase's known size.
+type ProgressFunc func(phase string, done, total int64)
+
+func resolveProgress(fn ProgressFunc) ProgressFunc {
+	if fn == nil {
+		return func(string, int64, int64) {}
+	}
+	return fn
+}
+
+// CopySubsetOpts narrows which messages CopySubsetWithOpts copies, and how
+// much of their data comes with them. The zero value copies nothing: at
+// least one of MaxMessages, Since/Until, Labels, Participants, or
+// ConversationIDs must select some messages.
+type CopySubsetOpts struct {
+	// Since and Until bound messages by sent_at (inclusive). The zero
+	// time.Time leaves that side of the window open.
+	Since, Until time.Time
+	// Labels, if non-empty, keeps only messages carrying at least one of
+	// these label names.
+	Labels []string
+	// Participants, if non-empty, keeps only messages sent by or sent to
+	// one of these email addresses.
+	Participants []string
+	// ConversationIDs, if non-empty, keeps only messages belonging to one
+	// of these conversations.
+	ConversationIDs []int64
+	// MaxMessages caps how many of the matching messages are copied, most
+	// recent (by sent_at) first. Zero means unlimited.
+	MaxMessages int
+	// IncludeRaw copies message_raw, the zlib-compressed original MIME.
+	// It usually dominates dataset size, so CopySubsetWithOpts leaves it
+	// off by default; CopySubset turns it on for compatibility.
+	IncludeRaw bool
+	// IncludeAttachments copies attachment metadata rows.
+	IncludeAttachments bool
+	// ScrubKey, if non-nil, replaces each copied participant's email
+	// local-part with its HMAC-SHA256 (keyed by ScrubKey) hex digest so
+	// the dataset can be shared without real addresses, while keeping a
+	// given address's digest stable across every message it appears in.
+	ScrubKey []byte
+	// SrcBlobDir, if non-empty, is the directory srcDBPath's messages were
+	// stored under via store.WithBlobStorage/WithHybridBlobStorage. Each
+	// copied message's blob_path file (and, for hybrid storage, just the
+	// on-disk body -- blob_header_prefix travels with the row itself) is
+	// copied alongside its row into dstDir, preserving the same two-level
+	// hex shard path, so the destination's own blob store finds it without
+	// needing a re-sync. Left empty, messages with a blob_path end up with
+	// a row that points at a file the destination dataset doesn't have.
+	SrcBlobDir string
+	// SrcConfigPath, if non-empty, is a config.toml (or similar) file copied
+	// alongside the destination database as dstDir/config.toml, preserving
+	// its xattrs/timestamps and restricting it to the current user -- a
+	// dataset's config can carry credentials, so it gets the same care as
+	// the rest of a backup round-trip, not a bare io.Copy.
+	SrcConfigPath string
+	// Progress, if non-nil, is called after each copy batch and each FTS
+	// batch with that phase's running total.
+	Progress ProgressFunc
+}
+
 // CopySubset copies rowCount most recent messages (and all referenced data) from
 // srcDBPath into a new database in dstDir. The destination schema is initialized
 // using the embedded store schema.
+//
+// It is a thin wrapper around CopySubsetWithOpts, kept for callers that only
+// need the original "N most recent messages, with everything" behavior.
 func CopySubset(srcDBPath, dstDir string, rowCount int) (*CopyResult, error) {
+	return CopySubsetWithOpts(context.Background(), srcDBPath, dstDir, CopySubsetOpts{
+		MaxMessages:        rowCount,
+		IncludeRaw:         true,
+		IncludeAttachments: true,
+	})
+}
+
+// CopySubsetWithOpts copies the subset of srcDBPath selected by opts (and all
+// data it references) into a new database in dstDir. The destination schema
+// is initialized using the embedded store schema.
+//
+// Rows are copied in batches, each its own transaction, with the highest
+// copied message_id recorded in a dataset_copy_state table in the
+// destination. If dstDir already holds a database from a previous,
+// interrupted run with the same opts, calling this again resumes after the
+// last completed batch instead of starting over. Canceling ctx stops at the
+// next batch boundary, leaving the partial destination in place for a later
+// resume; any other error does the same, since a partially copied database
+// is exactly what resuming needs.
+func CopySubsetWithOpts(ctx context.Context, srcDBPath, dstDir string, opts CopySubsetOpts) (*CopyResult, error) {
 	start := time.Now()
 
-	// Create destination directory
 	if err := os.MkdirAll(dstDir, 0700); err != nil {
 		return nil, fmt.Errorf("create destination directory: %w", err)
 	}
 
 	dstDBPath := filepath.Join(dstDir, "msgvault.db")
-
-	// Phase 1: Create destination DB with schema using store.Open + InitSchema
-	st, err := store.Open(dstDBPath)
-	if err != nil {
-		os.RemoveAll(dstDir)
-		return nil, fmt.Errorf("create destination database: %w", err)
-	}
-	if err := st.InitSchema(); err != nil {
+	resuming := fileExists(dstDBPath)
+
+	if !resuming {
+		// Phase 1: Create destination DB with schema using store.Open + InitSchema
+		st, err := store.Open(dstDBPath)
+		if err != nil {
+			os.RemoveAll(dstDir)
+			return nil, fmt.Errorf("create destination database: %w", err)
+		}
+		if err := st.InitSchema(); err != nil {
+			st.Close()
+			os.RemoveAll(dstDir)
+			return nil, fmt.Errorf("initialize schema: %w", err)
+		}
 		st.Close()
-		os.RemoveAll(dstDir)
-		return nil, fmt.Errorf("initialize schema: %w", err)
 	}
-	st.Close()
 
-	// Phase 2: Re-open with foreign keys OFF for bulk copy
+	// Phase 2: Re-open with foreign keys OFF for bulk copy. A single pooled
+	// connection is required: ATTACH and the TEMP tables copyData relies on
+	// are both connection-scoped in SQLite, and batches span many
+	// Begin/Commit calls against this same *sql.DB.
 	dsn := dstDBPath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=OFF"
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		os.RemoveAll(dstDir)
+		if !resuming {
+			os.RemoveAll(dstDir)
+		}
 		return nil, fmt.Errorf("reopen database: %w", err)
 	}
 	defer db.Close()
+	db.SetMaxOpenConns(1)
 
 	// Sanitize source path for ATTACH: reject null bytes, escape single quotes
 	if strings.ContainsRune(srcDBPath, 0) {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("source database path contains null byte")
 	}
 	escapedSrcPath := strings.ReplaceAll(srcDBPath, "'", "''")
 
-	// Attach source database
 	attachSQL := fmt.Sprintf("ATTACH DATABASE '%s' AS src", escapedSrcPath)
 	if _, err := db.Exec(attachSQL); err != nil {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("attach source database: %w", err)
 	}
+	defer db.Exec("DETACH DATABASE src")
 
-	// Begin transaction for bulk copy
-	tx, err := db.Begin()
-	if err != nil {
-		os.RemoveAll(dstDir)
-		return nil, fmt.Errorf("begin transaction: %w", err)
+	if err := ensureCopyStateTable(db); err != nil {
+		return nil, fmt.Errorf("prepare copy state: %w", err)
 	}
 
-	result, err := copyData(tx, rowCount)
-	if err != nil {
-		tx.Rollback()
-		db.Exec("DETACH DATABASE src")
-		os.RemoveAll(dstDir)
+	if err := copyData(ctx, db, dstDir, opts); err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		db.Exec("DETACH DATABASE src")
-		os.RemoveAll(dstDir)
-		return nil, fmt.Errorf("commit transaction: %w", err)
-	}
-
 	// Re-enable foreign keys and verify integrity
 	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("enable foreign keys: %w", err)
 	}
 
 	rows, err := db.Query("PRAGMA foreign_key_check")
 	if err != nil {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("foreign key check: %w", err)
 	}
 	var violations []string
@@ -114,23 +197,34 @@ func CopySubset(srcDBPath, dstDir string, rowCount int) (*CopyResult, error) {
 	}
 	rows.Close()
 	if len(violations) > 0 {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("foreign key violations: %s", strings.Join(violations, "; "))
 	}
 
 	// Update denormalized conversation counts
 	if err := updateConversationCounts(db); err != nil {
-		os.RemoveAll(dstDir)
 		return nil, fmt.Errorf("update conversation counts: %w", err)
 	}
 
 	// Populate FTS5 index (ignore errors - FTS5 may not be available)
-	_ = populateFTS(db)
+	_ = populateFTS(ctx, db, opts.Progress)
+
+	if opts.SrcConfigPath != "" {
+		dstConfigPath := filepath.Join(dstDir, "config.toml")
+		if err := fileutil.Copy(opts.SrcConfigPath, dstConfigPath, fileutil.CopyOptions{
+			PreserveXattrs: true,
+			PreserveTimes:  true,
+			SecureDest:     true,
+		}); err != nil {
+			return nil, fmt.Errorf("copy config: %w", err)
+		}
+	}
 
-	// Detach source
-	db.Exec("DETACH DATABASE src")
+	result := &CopyResult{}
+	db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&result.Messages)
+	db.QueryRow("SELECT COUNT(*) FROM conversations").Scan(&result.Conversations)
+	db.QueryRow("SELECT COUNT(*) FROM participants").Scan(&result.Participants)
+	db.QueryRow("SELECT COUNT(*) FROM labels").Scan(&result.Labels)
 
-	// Get final DB size
 	if info, err := os.Stat(dstDBPath); err == nil {
 		result.DBSize = info.Size()
 	}
@@ -139,128 +233,73 @@ func CopySubset(srcDBPath, dstDir string, rowCount int) (*CopyResult, error) {
 	return result, nil
 }
 
-// copyData executes the INSERT INTO ... SELECT statements in dependency order.
-func copyData(tx *sql.Tx, rowCount int) (*CopyResult, error) {
-	result := &CopyResult{}
-
-	// a. Sources (all rows â€” tiny table)
-	if _, err := tx.Exec("INSERT INTO sources SELECT * FROM src.sources"); err != nil {
-		return nil, fmt.Errorf("copy sources: %w", err)
-	}
-
-	// b. Select message IDs (the N most recent)
-	if _, err := tx.Exec(`
-		CREATE TEMP TABLE selected_messages AS
-		SELECT id FROM src.messages ORDER BY sent_at DESC LIMIT ?`, rowCount); err != nil {
-		return nil, fmt.Errorf("select messages: %w", err)
-	}
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
-	// Count actual selected messages
-	if err := tx.QueryRow("SELECT COUNT(*) FROM selected_messages").Scan(&result.Messages); err != nil {
-		return nil, fmt.Errorf("count selected messages: %w", err)
-	}
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx, so helpers that run
+// either inside a batch transaction or directly against the database don't
+// need two copies.
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
 
-	// c. Conversations referenced by selected messages
-	res, err := tx.Exec(`
-		INSERT INTO conversations SELECT * FROM src.conversations
-		WHERE id IN (SELECT DISTINCT conversation_id FROM src.messages
-		             WHERE id IN (SELECT id FROM selected_messages))`)
+// scrubParticipants replaces the local part of every copied participant's
+// email_address with its HMAC-SHA256 (keyed by key) hex digest, leaving the
+// domain intact. The digest is a function of the original address, so the
+// same sender gets the same scrubbed address everywhere it appears, which
+// keeps conversation structure and recipient grouping intact in the output.
+func scrubParticipants(ex sqlExecutor, key []byte) error {
+	rows, err := ex.Query("SELECT id, email_address FROM participants")
 	if err != nil {
-		return nil, fmt.Errorf("copy conversations: %w", err)
-	}
-	result.Conversations, _ = res.RowsAffected()
-
-	// d. Participants referenced by selected messages (senders + recipients)
-	res, err = tx.Exec(`
-		INSERT INTO participants SELECT * FROM src.participants
-		WHERE id IN (
-			SELECT sender_id FROM src.messages WHERE id IN (SELECT id FROM selected_messages)
-			UNION
-			SELECT participant_id FROM src.message_recipients WHERE message_id IN (SELECT id FROM selected_messages)
-		)`)
-	if err != nil {
-		return nil, fmt.Errorf("copy participants: %w", err)
-	}
-	result.Participants, _ = res.RowsAffected()
-
-	// e. Participant identifiers for copied participants
-	if _, err := tx.Exec(`
-		INSERT INTO participant_identifiers SELECT * FROM src.participant_identifiers
-		WHERE participant_id IN (SELECT id FROM participants)`); err != nil {
-		return nil, fmt.Errorf("copy participant_identifiers: %w", err)
-	}
-
-	// f. Conversation participants for copied conversations + participants
-	if _, err := tx.Exec(`
-		INSERT INTO conversation_participants SELECT * FROM src.conversation_participants
-		WHERE conversation_id IN (SELECT id FROM conversations)
-		  AND participant_id IN (SELECT id FROM participants)`); err != nil {
-		return nil, fmt.Errorf("copy conversation_participants: %w", err)
-	}
-
-	// g. Messages
-	if _, err := tx.Exec(`
-		INSERT INTO messages SELECT * FROM src.messages
-		WHERE id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy messages: %w", err)
-	}
-
-	// h. Message bodies
-	if _, err := tx.Exec(`
-		INSERT INTO message_bodies SELECT * FROM src.message_bodies
-		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy message_bodies: %w", err)
+		return fmt.Errorf("read participants to scrub: %w", err)
 	}
-
-	// i. Message raw
-	if _, err := tx.Exec(`
-		INSERT INTO message_raw SELECT * FROM src.message_raw
-		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy message_raw: %w", err)
+	type row struct {
+		id    int64
+		email string
 	}
-
-	// j. Message recipients
-	if _, err := tx.Exec(`
-		INSERT INTO message_recipients SELECT * FROM src.message_recipients
-		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy message_recipients: %w", err)
+	var toScrub []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.email); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan participant to scrub: %w", err)
+		}
+		toScrub = append(toScrub, r)
 	}
-
-	// k. Reactions
-	if _, err := tx.Exec(`
-		INSERT INTO reactions SELECT * FROM src.reactions
-		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy reactions: %w", err)
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
 	}
+	rows.Close()
 
-	// l. Attachments
-	if _, err := tx.Exec(`
-		INSERT INTO attachments SELECT * FROM src.attachments
-		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
-		return nil, fmt.Errorf("copy attachments: %w", err)
+	for _, r := range toScrub {
+		scrubbed := scrubEmail(key, r.email)
+		if _, err := ex.Exec("UPDATE participants SET email_address = ? WHERE id = ?", scrubbed, r.id); err != nil {
+			return fmt.Errorf("scrub participant %d: %w", r.id, err)
+		}
 	}
+	return nil
+}
 
-	// m. Labels (all for copied sources)
-	res, err = tx.Exec(`
-		INSERT INTO labels SELECT * FROM src.labels
-		WHERE source_id IN (SELECT id FROM sources)`)
-	if err != nil {
-		return nil, fmt.Errorf("copy labels: %w", err)
+// scrubEmail replaces addr's local part with its HMAC-SHA256 (keyed by key)
+// hex digest, preserving the domain. Addresses without an "@" are hashed
+// whole, since there's no domain to preserve.
+func scrubEmail(key []byte, addr string) string {
+	at := strings.LastIndex(addr, "@")
+	if at < 0 {
+		return digest(key, addr)
 	}
-	result.Labels, _ = res.RowsAffected()
-
-	// n. Message labels (intersection of copied messages and copied labels)
-	if _, err := tx.Exec(`
-		INSERT INTO message_labels SELECT * FROM src.message_labels
-		WHERE message_id IN (SELECT id FROM selected_messages)
-		  AND label_id IN (SELECT id FROM labels)`); err != nil {
-		return nil, fmt.Errorf("copy message_labels: %w", err)
-	}
-
-	// Clean up temp table
-	tx.Exec("DROP TABLE IF EXISTS selected_messages")
+	return digest(key, addr[:at]) + addr[at:]
+}
 
-	return result, nil
+func digest(key []byte, s string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 // updateConversationCounts updates the denormalized counts on conversations
@@ -274,53 +313,98 @@ func updateConversationCounts(db *sql.DB) error {
 	return err
 }
 
-// populateFTS rebuilds the FTS5 index from the copied data.
-// Matches the query structure from store.backfillFTSBatch.
-func populateFTS(db *sql.DB) error {
-	_, err := db.Exec(`
-		INSERT OR REPLACE INTO messages_fts(rowid, message_id, subject, body, from_addr, to_addr, cc_addr)
-		SELECT m.id, m.id, COALESCE(m.subject, ''), COALESCE(mb.body_text, ''),
-			COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
-			          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
-			          WHERE mr.message_id = m.id AND mr.recipient_type = 'from'), ''),
-			COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
-			          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
-			          WHERE mr.message_id = m.id AND mr.recipient_type = 'to'), ''),
-			COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
-			          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
-			          WHERE mr.message_id = m.id AND mr.recipient_type = 'cc'), '')
-		FROM messages m
-		LEFT JOIN message_bodies mb ON mb.message_id = m.id`)
-	return err
+// ftsBatchSize is how many messages populateFTS indexes per transaction.
+const ftsBatchSize = 5000
+
+// populateFTS rebuilds the FTS5 index from the copied data, ftsBatchSize
+// rows at a time so a large dataset doesn't lock the destination DB for
+// minutes in one statement, and so ctx cancellation is honored between
+// batches. Matches the query structure from store.backfillFTSBatch.
+//
+// dkim and domain let searches like "dkim:fail domain:paypal.com" find
+// spoofed-sender candidates: dkim holds "pass"/"fail"/"" (no verdict
+// recorded) and domain holds the From address's domain, independent of
+// message_authentication.from_domain_aligned which only says whether that
+// domain matched the DKIM signer.
+func populateFTS(ctx context.Context, db *sql.DB, progressFn ProgressFunc) error {
+	progress := resolveProgress(progressFn)
+
+	var total int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&total); err != nil {
+		return fmt.Errorf("count messages for fts: %w", err)
+	}
+
+	var done, lastID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var cutoff sql.NullInt64
+		err := db.QueryRow(`
+			SELECT id FROM messages WHERE id > ? ORDER BY id LIMIT 1 OFFSET ?`,
+			lastID, ftsBatchSize-1).Scan(&cutoff)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("find fts batch cutoff: %w", err)
+		}
+
+		where := "m.id > ?"
+		args := []any{lastID}
+		if cutoff.Valid {
+			where = "m.id > ? AND m.id <= ?"
+			args = append(args, cutoff.Int64)
+		}
+
+		res, err := db.Exec(fmt.Sprintf(`
+			INSERT OR REPLACE INTO messages_fts(rowid, message_id, subject, body, from_addr, to_addr, cc_addr, dkim, domain)
+			SELECT m.id, m.id, COALESCE(m.subject, ''), COALESCE(mb.body_text, ''),
+				COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
+				          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+				          WHERE mr.message_id = m.id AND mr.recipient_type = 'from'), ''),
+				COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
+				          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+				          WHERE mr.message_id = m.id AND mr.recipient_type = 'to'), ''),
+				COALESCE((SELECT GROUP_CONCAT(p.email_address, ' ')
+				          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+				          WHERE mr.message_id = m.id AND mr.recipient_type = 'cc'), ''),
+				COALESCE((SELECT CASE WHEN ma.dkim_pass THEN 'pass' ELSE 'fail' END
+				          FROM message_authentication ma WHERE ma.message_id = m.id), ''),
+				COALESCE((SELECT substr(p.email_address, instr(p.email_address, '@') + 1)
+				          FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+				          WHERE mr.message_id = m.id AND mr.recipient_type = 'from' LIMIT 1), '')
+			FROM messages m
+			LEFT JOIN message_bodies mb ON mb.message_id = m.id
+			WHERE %s`, where), args...)
+		if err != nil {
+			return fmt.Errorf("populate fts batch: %w", err)
+		}
+
+		n, _ := res.RowsAffected()
+		done += n
+		progress("fts", done, total)
+
+		if !cutoff.Valid {
+			break
+		}
+		lastID = cutoff.Int64
+	}
+
+	return nil
 }
 
 // CopyFileIfExists copies a single file from src to dst.
 // Returns nil if the source file does not exist.
 // Both paths must be validated by the caller to prevent path traversal.
+//
+// It delegates the actual copy to fileutil.Copy with the zero CopyOptions
+// (a plain dense copy, no xattr/time/ACL preservation) -- callers that care
+// about those, like CopySubsetWithOpts's config.toml copy, call
+// fileutil.Copy directly instead.
 func CopyFileIfExists(src, dst string) error {
 	// Validate paths are absolute
 	if !filepath.IsAbs(src) || !filepath.IsAbs(dst) {
 		return fmt.Errorf("paths must be absolute: src=%q, dst=%q", src, dst)
 	}
 
-	srcFile, err := os.Open(src)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("open source file %s: %w", src, err)
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("create destination file %s: %w", dst, err)
-	}
-	defer dstFile.Close()
-
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
-	}
-
-	return nil
+	return fileutil.Copy(src, dst, fileutil.CopyOptions{})
 }
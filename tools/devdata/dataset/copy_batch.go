@@ -0,0 +1,361 @@
+package dataset
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// copyBatchSize is how many messages copyData copies per transaction. It's a
+// var rather than a const so tests can shrink it to exercise batching and
+// resume behavior without needing millions of rows.
+var copyBatchSize = 10000
+
+// copyData copies the subset of src selected by opts into db, copyBatchSize
+// messages at a time, each batch its own transaction. After every batch it
+// records the highest copied message_id in dataset_copy_state, so a later
+// call with the same opts against this same (partially copied) destination
+// resumes after that id instead of redoing finished batches. dstDir is
+// where opts.SrcBlobDir's on-disk blobs are copied to, mirrored under the
+// same relative shard path.
+func copyData(ctx context.Context, db *sql.DB, dstDir string, opts CopySubsetOpts) error {
+	progress := resolveProgress(opts.Progress)
+
+	// Sources and labels don't depend on which messages are selected (they're
+	// scoped to the whole source, not a batch), so they're copied once,
+	// tolerating re-runs via INSERT OR IGNORE.
+	if _, err := db.Exec("INSERT OR IGNORE INTO sources SELECT * FROM src.sources"); err != nil {
+		return fmt.Errorf("copy sources: %w", err)
+	}
+	if _, err := db.Exec(`
+		INSERT OR IGNORE INTO labels SELECT * FROM src.labels
+		WHERE source_id IN (SELECT id FROM sources)`); err != nil {
+		return fmt.Errorf("copy labels: %w", err)
+	}
+
+	candidateSQL, args := candidateMessagesQuery(opts)
+	if _, err := db.Exec(candidateSQL, args...); err != nil {
+		return fmt.Errorf("select candidate messages: %w", err)
+	}
+	defer db.Exec("DROP TABLE IF EXISTS candidate_messages")
+
+	var total int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM candidate_messages").Scan(&total); err != nil {
+		return fmt.Errorf("count candidate messages: %w", err)
+	}
+
+	lastID, err := loadCopyState(db)
+	if err != nil {
+		return fmt.Errorf("load copy state: %w", err)
+	}
+
+	var done int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM candidate_messages WHERE id <= ?", lastID).Scan(&done); err != nil {
+		return fmt.Errorf("count already-copied messages: %w", err)
+	}
+	progress("messages", done, total)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin batch: %w", err)
+		}
+
+		n, maxID, err := copyBatch(tx, dstDir, opts, lastID, copyBatchSize)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if n == 0 {
+			tx.Rollback()
+			break
+		}
+
+		lastID = maxID
+		if err := saveCopyState(tx, lastID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit batch: %w", err)
+		}
+
+		done += n
+		progress("messages", done, total)
+	}
+
+	if len(opts.ScrubKey) > 0 {
+		if err := scrubParticipants(db, opts.ScrubKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyBatch copies up to limit messages with id > afterID (and everything
+// they reference) in one transaction, returning how many messages it copied
+// and the highest message_id among them.
+func copyBatch(tx *sql.Tx, dstDir string, opts CopySubsetOpts, afterID int64, limit int) (int64, int64, error) {
+	if _, err := tx.Exec("DROP TABLE IF EXISTS selected_messages"); err != nil {
+		return 0, 0, fmt.Errorf("drop previous batch table: %w", err)
+	}
+	if _, err := tx.Exec(`
+		CREATE TEMP TABLE selected_messages AS
+		SELECT id FROM candidate_messages WHERE id > ? ORDER BY id LIMIT ?`, afterID, limit); err != nil {
+		return 0, 0, fmt.Errorf("select batch: %w", err)
+	}
+
+	var n int64
+	var maxID sql.NullInt64
+	if err := tx.QueryRow("SELECT COUNT(*), MAX(id) FROM selected_messages").Scan(&n, &maxID); err != nil {
+		return 0, 0, fmt.Errorf("count batch: %w", err)
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+
+	// Conversations and participants referenced by this batch may already
+	// have been copied by an earlier batch that shared them, so these use
+	// INSERT OR IGNORE; messages and their directly-keyed children appear in
+	// exactly one batch and use a plain INSERT.
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO conversations SELECT * FROM src.conversations
+		WHERE id IN (SELECT DISTINCT conversation_id FROM src.messages
+		             WHERE id IN (SELECT id FROM selected_messages))`); err != nil {
+		return 0, 0, fmt.Errorf("copy conversations: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO participants SELECT * FROM src.participants
+		WHERE id IN (
+			SELECT sender_id FROM src.messages WHERE id IN (SELECT id FROM selected_messages)
+			UNION
+			SELECT participant_id FROM src.message_recipients WHERE message_id IN (SELECT id FROM selected_messages)
+		)`); err != nil {
+		return 0, 0, fmt.Errorf("copy participants: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO participant_identifiers SELECT * FROM src.participant_identifiers
+		WHERE participant_id IN (SELECT id FROM participants)`); err != nil {
+		return 0, 0, fmt.Errorf("copy participant_identifiers: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO conversation_participants SELECT * FROM src.conversation_participants
+		WHERE conversation_id IN (SELECT id FROM conversations)
+		  AND participant_id IN (SELECT id FROM participants)`); err != nil {
+		return 0, 0, fmt.Errorf("copy conversation_participants: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO messages SELECT * FROM src.messages
+		WHERE id IN (SELECT id FROM selected_messages)`); err != nil {
+		return 0, 0, fmt.Errorf("copy messages: %w", err)
+	}
+
+	if opts.SrcBlobDir != "" {
+		if err := copyMessageBlobs(tx, opts.SrcBlobDir, dstDir); err != nil {
+			return 0, 0, fmt.Errorf("copy message blobs: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO message_bodies SELECT * FROM src.message_bodies
+		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+		return 0, 0, fmt.Errorf("copy message_bodies: %w", err)
+	}
+
+	if opts.IncludeRaw {
+		if _, err := tx.Exec(`
+			INSERT INTO message_raw SELECT * FROM src.message_raw
+			WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+			return 0, 0, fmt.Errorf("copy message_raw: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO message_recipients SELECT * FROM src.message_recipients
+		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+		return 0, 0, fmt.Errorf("copy message_recipients: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO reactions SELECT * FROM src.reactions
+		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+		return 0, 0, fmt.Errorf("copy reactions: %w", err)
+	}
+
+	if opts.IncludeAttachments {
+		if _, err := tx.Exec(`
+			INSERT INTO attachments SELECT * FROM src.attachments
+			WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+			return 0, 0, fmt.Errorf("copy attachments: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO message_labels SELECT * FROM src.message_labels
+		WHERE message_id IN (SELECT id FROM selected_messages)
+		  AND label_id IN (SELECT id FROM labels)`); err != nil {
+		return 0, 0, fmt.Errorf("copy message_labels: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO message_authentication SELECT * FROM src.message_authentication
+		WHERE message_id IN (SELECT id FROM selected_messages)`); err != nil {
+		return 0, 0, fmt.Errorf("copy message_authentication: %w", err)
+	}
+
+	return n, maxID.Int64, nil
+}
+
+// candidateMessagesQuery builds the CREATE TEMP TABLE statement (and its
+// parameters) that populates candidate_messages from opts: a time window,
+// label/participant/conversation membership, and an optional cap on the
+// most recent matches. candidate_messages is the full target set; copyData
+// then walks it in id-ordered batches. All scalar values are passed as
+// placeholders; only the ATTACHed source path (handled by the caller) is
+// interpolated.
+func candidateMessagesQuery(opts CopySubsetOpts) (string, []any) {
+	var where []string
+	var args []any
+
+	if !opts.Since.IsZero() {
+		where = append(where, "sent_at >= ?")
+		args = append(args, opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		where = append(where, "sent_at <= ?")
+		args = append(args, opts.Until.UTC().Format(time.RFC3339))
+	}
+	if len(opts.ConversationIDs) > 0 {
+		where = append(where, "conversation_id IN ("+placeholders(len(opts.ConversationIDs))+")")
+		for _, id := range opts.ConversationIDs {
+			args = append(args, id)
+		}
+	}
+	if len(opts.Labels) > 0 {
+		where = append(where, fmt.Sprintf(`id IN (
+			SELECT ml.message_id FROM src.message_labels ml
+			JOIN src.labels l ON l.id = ml.label_id
+			WHERE l.name IN (%s))`, placeholders(len(opts.Labels))))
+		for _, name := range opts.Labels {
+			args = append(args, name)
+		}
+	}
+	if len(opts.Participants) > 0 {
+		ph := placeholders(len(opts.Participants))
+		where = append(where, fmt.Sprintf(`id IN (
+			SELECT sm.id FROM src.messages sm
+			JOIN src.participants sp ON sp.id = sm.sender_id
+			WHERE sp.email_address IN (%s)
+			UNION
+			SELECT mr.message_id FROM src.message_recipients mr
+			JOIN src.participants rp ON rp.id = mr.participant_id
+			WHERE rp.email_address IN (%s))`, ph, ph))
+		for _, email := range opts.Participants {
+			args = append(args, email)
+		}
+		for _, email := range opts.Participants {
+			args = append(args, email)
+		}
+	}
+
+	query := "CREATE TEMP TABLE candidate_messages AS SELECT id FROM src.messages"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY sent_at DESC"
+	if opts.MaxMessages > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.MaxMessages)
+	}
+	return query, args
+}
+
+// placeholders returns a comma-separated list of n "?" SQL placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// ensureCopyStateTable creates the single-row table copyData uses to track
+// resume progress, if it doesn't already exist.
+func ensureCopyStateTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS dataset_copy_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		last_message_id INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+// loadCopyState returns the highest message_id copyData has already copied
+// into db, or 0 if this is a fresh destination.
+func loadCopyState(db *sql.DB) (int64, error) {
+	var lastID int64
+	err := db.QueryRow("SELECT last_message_id FROM dataset_copy_state WHERE id = 1").Scan(&lastID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return lastID, err
+}
+
+// saveCopyState records lastID as the highest copied message_id so far.
+func saveCopyState(tx *sql.Tx, lastID int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO dataset_copy_state (id, last_message_id) VALUES (1, ?)
+		ON CONFLICT(id) DO UPDATE SET last_message_id = excluded.last_message_id`, lastID)
+	return err
+}
+
+// copyMessageBlobs copies the on-disk blob file for every message in
+// selected_messages that has one (blob_path != ''), from srcBlobDir into
+// dstDir, preserving blob_path itself as the relative path -- the same
+// two-level hex shard layout blobstore.MessageBlobStore computed it under
+// -- so the destination's own blob store finds it unchanged. For a
+// hybrid-stored message this copies only the on-disk body; its
+// blob_header_prefix already traveled with the row copied into messages.
+func copyMessageBlobs(tx *sql.Tx, srcBlobDir, dstDir string) error {
+	rows, err := tx.Query(`
+		SELECT blob_path FROM messages
+		WHERE id IN (SELECT id FROM selected_messages) AND blob_path != ''`)
+	if err != nil {
+		return fmt.Errorf("list message blobs: %w", err)
+	}
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan message blob path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, relPath := range paths {
+		src := filepath.Join(srcBlobDir, relPath)
+		dst := filepath.Join(dstDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+			return fmt.Errorf("mkdir for blob %s: %w", relPath, err)
+		}
+		if err := CopyFileIfExists(src, dst); err != nil {
+			return fmt.Errorf("copy blob %s: %w", relPath, err)
+		}
+	}
+	return nil
+}
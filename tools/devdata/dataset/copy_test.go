@@ -1,9 +1,11 @@
 package dataset
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -342,6 +344,229 @@ func TestCopySubset_SQLInjectionInPath(t *testing.T) {
 	}
 }
 
+func TestCopySubsetWithOpts_Labels(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 9)
+
+	// Messages alternate labels 1 (INBOX), 2 (SENT), 3 (Work) by id % 3 + 1;
+	// keeping only "Work" should select exactly the ids where i%3 == 2.
+	result, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{Labels: []string{"Work"}})
+	if err != nil {
+		t.Fatalf("CopySubsetWithOpts: %v", err)
+	}
+	if result.Messages != 3 {
+		t.Errorf("Messages = %d, want 3", result.Messages)
+	}
+}
+
+func TestCopySubsetWithOpts_Participants(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 10)
+
+	// Only the first half of messages (1..5) are sent by alice@example.com.
+	result, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{Participants: []string{"alice@example.com"}})
+	if err != nil {
+		t.Fatalf("CopySubsetWithOpts: %v", err)
+	}
+	if result.Messages != 5 {
+		t.Errorf("Messages = %d, want 5", result.Messages)
+	}
+}
+
+func TestCopySubsetWithOpts_ExcludesRawAndAttachmentsByDefault(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 5)
+
+	_, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{MaxMessages: 5})
+	if err != nil {
+		t.Fatalf("CopySubsetWithOpts: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dstDir, "msgvault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int64
+	db.QueryRow("SELECT COUNT(*) FROM message_raw").Scan(&count)
+	if count != 0 {
+		t.Errorf("message_raw count = %d, want 0 (IncludeRaw not set)", count)
+	}
+	db.QueryRow("SELECT COUNT(*) FROM attachments").Scan(&count)
+	if count != 0 {
+		t.Errorf("attachments count = %d, want 0 (IncludeAttachments not set)", count)
+	}
+}
+
+func TestCopySubsetWithOpts_Scrub(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 5)
+
+	_, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{MaxMessages: 5, ScrubKey: []byte("test-key")})
+	if err != nil {
+		t.Fatalf("CopySubsetWithOpts: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dstDir, "msgvault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT email_address FROM participants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			t.Fatal(err)
+		}
+		found = true
+		if strings.HasSuffix(email, "@example.com") && strings.HasPrefix(email, "alice") {
+			t.Errorf("participant email %q was not scrubbed", email)
+		}
+		if !strings.HasSuffix(email, "@example.com") {
+			t.Errorf("participant email %q lost its domain", email)
+		}
+	}
+	if !found {
+		t.Error("expected at least one scrubbed participant")
+	}
+}
+
+func TestCopySubsetWithOpts_ResumeAfterCancel(t *testing.T) {
+	origBatchSize := copyBatchSize
+	copyBatchSize = 2
+	defer func() { copyBatchSize = origBatchSize }()
+
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceledAfterFirstBatch := false
+	_, err := CopySubsetWithOpts(ctx, srcDB, dstDir, CopySubsetOpts{
+		IncludeRaw:         true,
+		IncludeAttachments: true,
+		Progress: func(phase string, done, total int64) {
+			if phase == "messages" && done > 0 && !canceledAfterFirstBatch {
+				canceledAfterFirstBatch = true
+				cancel()
+			}
+		},
+	})
+	if err == nil {
+		t.Fatal("expected cancellation to stop the copy early")
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dstDir, "msgvault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var partial int64
+	db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&partial)
+	db.Close()
+	if partial != 2 {
+		t.Fatalf("messages after cancel = %d, want 2 (one batch of copyBatchSize)", partial)
+	}
+
+	// Resume: same opts, fresh context, against the same (partial) destination.
+	result, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{
+		IncludeRaw:         true,
+		IncludeAttachments: true,
+	})
+	if err != nil {
+		t.Fatalf("resume CopySubsetWithOpts: %v", err)
+	}
+	if result.Messages != 6 {
+		t.Errorf("Messages after resume = %d, want 6", result.Messages)
+	}
+
+	db, err = sql.Open("sqlite3", filepath.Join(dstDir, "msgvault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int64
+	db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&count)
+	if count != 6 {
+		t.Errorf("destination messages after resume = %d, want 6 (no duplicates)", count)
+	}
+
+	fkRows, err := db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasViolation := fkRows.Next()
+	fkRows.Close()
+	if hasViolation {
+		t.Error("foreign key violations found after resume")
+	}
+}
+
+func TestCopySubsetWithOpts_ProgressReachesTotal(t *testing.T) {
+	origBatchSize := copyBatchSize
+	copyBatchSize = 3
+	defer func() { copyBatchSize = origBatchSize }()
+
+	srcDir := t.TempDir()
+	dstDir := filepath.Join(t.TempDir(), "dst")
+
+	srcDB := createTestSourceDB(t, srcDir, 7)
+
+	var lastMessagesDone, lastMessagesTotal int64
+	sawFTS := false
+	_, err := CopySubsetWithOpts(context.Background(), srcDB, dstDir, CopySubsetOpts{
+		MaxMessages: 7,
+		Progress: func(phase string, done, total int64) {
+			switch phase {
+			case "messages":
+				lastMessagesDone, lastMessagesTotal = done, total
+			case "fts":
+				sawFTS = true
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("CopySubsetWithOpts: %v", err)
+	}
+
+	if lastMessagesTotal != 7 {
+		t.Errorf("final messages total = %d, want 7", lastMessagesTotal)
+	}
+	if lastMessagesDone != lastMessagesTotal {
+		t.Errorf("final messages done = %d, want %d", lastMessagesDone, lastMessagesTotal)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dstDir, "msgvault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var ftsCount int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages_fts").Scan(&ftsCount); err != nil {
+		t.Skip("FTS5 not available")
+	}
+	if !sawFTS {
+		t.Error("expected at least one \"fts\" progress callback")
+	}
+}
+
 func TestCopyFileIfExists(t *testing.T) {
 	dir := t.TempDir()
 
@@ -0,0 +1,433 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wesm/msgvault/internal/store"
+)
+
+// Where messages are split into files by label, SplitByConversation groups
+// them by conversation instead; a message belonging to more than one label
+// is written to each of that label's files either way.
+const (
+	SplitByLabel        = "label"
+	SplitByConversation = "conversation"
+)
+
+// ExportMboxOpts configures ExportMbox.
+type ExportMboxOpts struct {
+	// SplitBy selects how messages are grouped into separate mbox files:
+	// SplitByLabel (the default) or SplitByConversation.
+	SplitBy string
+	// Labels, if non-empty, restricts SplitByLabel to these label names
+	// instead of every label in the source database. Ignored when SplitBy
+	// is SplitByConversation.
+	Labels []string
+}
+
+// ExportMboxResult summarizes an ExportMbox run.
+type ExportMboxResult struct {
+	Files    int
+	Messages int64
+}
+
+// ExportMbox reads messages out of the database at srcDBPath and writes
+// them as mbox files under dstDir, one per opts.SplitBy group. Each
+// message is reconstructed as an RFC 5322 document from message_bodies
+// plus headers derived from messages, message_recipients, and
+// participants rather than from message_raw, so export works even on
+// datasets copied with CopySubsetOpts.IncludeRaw left off. Any body line
+// starting with "From " is quoted with a leading ">" per the mbox
+// envelope convention, reversed by ImportMbox on the way back in.
+func ExportMbox(srcDBPath, dstDir string, opts ExportMboxOpts) (*ExportMboxResult, error) {
+	splitBy := opts.SplitBy
+	if splitBy == "" {
+		splitBy = SplitByLabel
+	}
+	if splitBy != SplitByLabel && splitBy != SplitByConversation {
+		return nil, fmt.Errorf("dataset: export mbox: unknown split mode %q", splitBy)
+	}
+
+	db, err := sql.Open("sqlite3", srcDBPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: open %s: %w", srcDBPath, err)
+	}
+	defer db.Close()
+
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: create %s: %w", dstDir, err)
+	}
+
+	groups, err := mboxGroups(db, splitBy, opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExportMboxResult{}
+	for _, g := range groups {
+		ids, err := mboxGroupMessageIDs(db, splitBy, g.id)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		dst := filepath.Join(dstDir, mboxFilename(g.name)+".mbox")
+		n, err := writeMboxFile(db, dst, ids)
+		if err != nil {
+			return nil, err
+		}
+		result.Files++
+		result.Messages += n
+	}
+	return result, nil
+}
+
+// mboxGroup is one SplitByLabel/SplitByConversation group: the id to
+// filter messages by and the name its output file is derived from.
+type mboxGroup struct {
+	id   int64
+	name string
+}
+
+// mboxGroups lists the labels or conversations ExportMbox writes one file
+// per, narrowed to labels when splitBy is SplitByLabel and len(labels) > 0.
+func mboxGroups(db *sql.DB, splitBy string, labels []string) ([]mboxGroup, error) {
+	var rows *sql.Rows
+	var err error
+
+	if splitBy == SplitByLabel {
+		query := "SELECT id, name FROM labels"
+		var args []any
+		if len(labels) > 0 {
+			query += " WHERE name IN (" + placeholders(len(labels)) + ")"
+			for _, name := range labels {
+				args = append(args, name)
+			}
+		}
+		rows, err = db.Query(query, args...)
+	} else {
+		rows, err = db.Query("SELECT DISTINCT conversation_id FROM messages WHERE conversation_id IS NOT NULL")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: list groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []mboxGroup
+	for rows.Next() {
+		var g mboxGroup
+		if splitBy == SplitByLabel {
+			if err := rows.Scan(&g.id, &g.name); err != nil {
+				return nil, fmt.Errorf("dataset: export mbox: scan group: %w", err)
+			}
+		} else {
+			if err := rows.Scan(&g.id); err != nil {
+				return nil, fmt.Errorf("dataset: export mbox: scan group: %w", err)
+			}
+			g.name = fmt.Sprintf("conversation-%d", g.id)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// mboxGroupMessageIDs returns the ids of every message in group groupID,
+// oldest first, so a conversation's mbox file reads top to bottom like the
+// thread it came from.
+func mboxGroupMessageIDs(db *sql.DB, splitBy string, groupID int64) ([]int64, error) {
+	query := "SELECT id FROM messages WHERE conversation_id = ? ORDER BY sent_at"
+	if splitBy == SplitByLabel {
+		query = `
+			SELECT m.id FROM messages m
+			JOIN message_labels ml ON ml.message_id = m.id
+			WHERE ml.label_id = ? ORDER BY m.sent_at`
+	}
+
+	rows, err := db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: list messages for group %d: %w", groupID, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("dataset: export mbox: scan message id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// mboxMessage is the handful of fields writeMboxMessage needs to
+// reconstruct one RFC 5322 document, assembled from messages,
+// message_recipients, participants, and message_bodies.
+type mboxMessage struct {
+	From      string
+	To        []string
+	Cc        []string
+	Subject   string
+	MessageID string
+	Date      time.Time
+	Body      string
+}
+
+// writeMboxFile appends ids, in order, to a new mbox file at dst, and
+// returns how many it wrote.
+func writeMboxFile(db *sql.DB, dst string, ids []int64) (int64, error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return 0, fmt.Errorf("dataset: export mbox: create %s: %w", dst, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	var n int64
+	for _, id := range ids {
+		msg, err := loadMboxMessage(db, id)
+		if err != nil {
+			return n, err
+		}
+		writeMboxMessage(w, msg)
+		n++
+	}
+	return n, w.Flush()
+}
+
+// loadMboxMessage reconstructs the header and body fields of message id
+// from its relational rows.
+func loadMboxMessage(db *sql.DB, id int64) (*mboxMessage, error) {
+	m := &mboxMessage{}
+	var subject, sourceMessageID sql.NullString
+	var sentAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT m.subject, m.source_message_id, m.sent_at, COALESCE(p.email_address, '')
+		FROM messages m LEFT JOIN participants p ON p.id = m.sender_id
+		WHERE m.id = ?`, id).Scan(&subject, &sourceMessageID, &sentAt, &m.From)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: load message %d: %w", id, err)
+	}
+	m.Subject = subject.String
+	m.MessageID = sourceMessageID.String
+	if sentAt.Valid {
+		m.Date = sentAt.Time
+	} else {
+		m.Date = time.Unix(0, 0).UTC()
+	}
+
+	rows, err := db.Query(`
+		SELECT mr.recipient_type, p.email_address
+		FROM message_recipients mr JOIN participants p ON p.id = mr.participant_id
+		WHERE mr.message_id = ? AND mr.recipient_type IN ('to', 'cc')`, id)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: export mbox: load recipients for message %d: %w", id, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind, addr string
+		if err := rows.Scan(&kind, &addr); err != nil {
+			return nil, fmt.Errorf("dataset: export mbox: scan recipient for message %d: %w", id, err)
+		}
+		if kind == "cc" {
+			m.Cc = append(m.Cc, addr)
+		} else {
+			m.To = append(m.To, addr)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var body sql.NullString
+	err = db.QueryRow("SELECT body_text FROM message_bodies WHERE message_id = ?", id).Scan(&body)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("dataset: export mbox: load body for message %d: %w", id, err)
+	}
+	m.Body = body.String
+	return m, nil
+}
+
+// mboxDateFormat is the ctime-ish format the mbox "From " envelope line
+// expects its date in, e.g. "Thu Jan  1 00:00:00 1970".
+const mboxDateFormat = "Mon Jan _2 15:04:05 2006"
+
+// writeMboxMessage writes m to w as one "From "-delimited mbox entry.
+func writeMboxMessage(w *bufio.Writer, m *mboxMessage) {
+	sender := m.From
+	if sender == "" {
+		sender = "unknown@localhost"
+	}
+	fmt.Fprintf(w, "From %s %s\n", sender, m.Date.UTC().Format(mboxDateFormat))
+	fmt.Fprintf(w, "From: %s\n", m.From)
+	if len(m.To) > 0 {
+		fmt.Fprintf(w, "To: %s\n", strings.Join(m.To, ", "))
+	}
+	if len(m.Cc) > 0 {
+		fmt.Fprintf(w, "Cc: %s\n", strings.Join(m.Cc, ", "))
+	}
+	if m.Subject != "" {
+		fmt.Fprintf(w, "Subject: %s\n", m.Subject)
+	}
+	fmt.Fprintf(w, "Date: %s\n", m.Date.UTC().Format(time.RFC1123Z))
+	if m.MessageID != "" {
+		fmt.Fprintf(w, "Message-Id: <%s>\n", m.MessageID)
+	}
+	fmt.Fprint(w, "\n")
+	body := quoteFromLines(m.Body)
+	fmt.Fprint(w, body)
+	if !strings.HasSuffix(body, "\n") {
+		fmt.Fprint(w, "\n")
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// quoteFromLines prefixes ">" to every body line starting with "From ", so
+// a reader splitting an mbox file on "^From " doesn't mistake a quoted
+// line inside a message for the next message's envelope.
+func quoteFromLines(body string) string {
+	if !strings.Contains(body, "From ") {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "From ") {
+			lines[i] = ">" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unquoteFromLines reverses quoteFromLines: a line of the form ">From "
+// has its leading ">" stripped.
+func unquoteFromLines(body string) string {
+	if !strings.Contains(body, ">From ") {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ">From ") {
+			lines[i] = line[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mboxFilename sanitizes name for use as a file name by replacing path
+// separators and spaces.
+func mboxFilename(name string) string {
+	return strings.NewReplacer("/", "_", " ", "_").Replace(name)
+}
+
+// ImportMboxResult summarizes an ImportMbox run.
+type ImportMboxResult struct {
+	Messages int64
+}
+
+// ImportMbox parses the mbox file at srcPath, splitting on the "^From "
+// envelope boundary and reversing quoteFromLines' body quoting, and
+// inserts each message into a new dataset database under dstDir (created
+// the same way CopySubsetWithOpts creates one) as a "mbox" source
+// identified by sourceEmail. mbox carries no recipient/label structure of
+// its own beyond what's in the reconstructed headers, so only a From
+// address is recovered with any confidence; everything else comes from
+// whatever InsertImportedMessage's own header parsing can find in the raw
+// bytes.
+func ImportMbox(srcPath, dstDir, sourceEmail string) (*ImportMboxResult, error) {
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return nil, fmt.Errorf("dataset: import mbox: create %s: %w", dstDir, err)
+	}
+	dstDBPath := filepath.Join(dstDir, "msgvault.db")
+
+	st, err := store.Open(dstDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: import mbox: open %s: %w", dstDBPath, err)
+	}
+	defer st.Close()
+	if err := st.InitSchema(); err != nil {
+		return nil, fmt.Errorf("dataset: import mbox: initialize schema: %w", err)
+	}
+
+	source, err := st.GetOrCreateSource("mbox", sourceEmail)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: import mbox: get or create source: %w", err)
+	}
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("dataset: import mbox: open %s: %w", srcPath, err)
+	}
+	defer f.Close()
+
+	result := &ImportMboxResult{}
+	stem := mboxFilenameStem(srcPath)
+	i := 0
+	err = scanMboxMessages(f, func(raw []byte) error {
+		body := unquoteFromLines(string(raw))
+		key := fmt.Sprintf("%s-%d", stem, i)
+		i++
+		if err := st.InsertImportedMessage(source.ID, key, []byte(body), nil); err != nil {
+			return fmt.Errorf("insert message %s: %w", key, err)
+		}
+		result.Messages++
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("dataset: import mbox: %w", err)
+	}
+	return result, nil
+}
+
+// scanMboxMessages splits r's content on "^From " envelope lines (the
+// mbox format's message boundary) and calls fn once per message with its
+// raw bytes, envelope line excluded.
+func scanMboxMessages(r io.Reader, fn func(raw []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var cur bytes.Buffer
+	started := false
+	flush := func() error {
+		if !started {
+			return nil
+		}
+		return fn(cur.Bytes())
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "From ") {
+			if err := flush(); err != nil {
+				return err
+			}
+			cur.Reset()
+			started = true
+			continue
+		}
+		if started {
+			cur.WriteString(line)
+			cur.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+	return flush()
+}
+
+// mboxFilenameStem returns path's base name with its extension removed,
+// used as a stable-ish prefix for synthesized per-message import keys.
+func mboxFilenameStem(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}